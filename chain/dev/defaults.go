@@ -87,6 +87,8 @@
 	}
 	// DefaultRPCWSPort rpc websocket port
 	DefaultRPCWSPort = uint32(8546)
+	// DefaultRPCMaxBatchSize is the maximum number of requests in a JSON-RPC batch
+	DefaultRPCMaxBatchSize = uint32(100)
 	// DefaultRPCEnabled enables the RPC server
 	DefaultRPCEnabled = true
 	// DefaultWSEnabled enables the WS server
@@ -83,6 +83,8 @@
 	}
 	// DefaultRPCWSPort rpc websocket port
 	DefaultRPCWSPort = uint32(8546)
+	// DefaultRPCMaxBatchSize is the maximum number of requests in a JSON-RPC batch
+	DefaultRPCMaxBatchSize = uint32(100)
 )
 
 const (
@@ -0,0 +1,115 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/ChainSafe/gossamer/lib/utils"
+	"github.com/urfave/cli"
+)
+
+// exportBlocksAction is the action for the "export-blocks" subcommand. It writes
+// the block data of a range of blocks from the node database to a file.
+func exportBlocksAction(ctx *cli.Context) (err error) {
+	outputPath := ctx.String(BlocksOutputFlag.Name)
+	if outputPath == "" {
+		return errors.New("must provide argument to --output")
+	}
+
+	format := dot.BlocksFormat(ctx.String(BlocksFormatFlag.Name))
+	if !format.IsValid() {
+		return fmt.Errorf("--%s must be either %s or %s",
+			BlocksFormatFlag.Name, dot.BinaryBlocksFormat, dot.JSONBlocksFormat)
+	}
+
+	_, err = setupLogger(ctx)
+	if err != nil {
+		logger.Errorf("failed to setup logger: %s", err)
+		return err
+	}
+
+	cfg, err := createImportStateConfig(ctx)
+	if err != nil {
+		logger.Errorf("failed to create node configuration: %s", err)
+		return err
+	}
+	cfg.Global.BasePath = utils.ExpandDir(cfg.Global.BasePath)
+
+	file, err := os.Create(filepath.Clean(outputPath))
+	if err != nil {
+		return fmt.Errorf("creating output file: %w", err)
+	}
+	defer func() {
+		closeErr := file.Close()
+		if err == nil && closeErr != nil {
+			err = fmt.Errorf("closing output file: %w", closeErr)
+		}
+	}()
+
+	from := ctx.Uint(BlocksFromFlag.Name)
+	to := blocksToNumber(ctx)
+	return dot.ExportBlocks(cfg.Global.BasePath, from, to, format, file)
+}
+
+// blocksToNumber returns the number of the last block to export given
+// with the --to flag, or nil if the flag is not set, such that `--to 0`
+// only exports up to the genesis block.
+func blocksToNumber(ctx *cli.Context) (to *uint) {
+	if !ctx.IsSet(BlocksToFlag.Name) {
+		return nil
+	}
+	value := ctx.Uint(BlocksToFlag.Name)
+	return &value
+}
+
+// importBlocksAction is the action for the "import-blocks" subcommand. It imports
+// the blocks of a file written by the "export-blocks" subcommand in the node database.
+func importBlocksAction(ctx *cli.Context) (err error) {
+	inputPath := ctx.String(BlocksInputFlag.Name)
+	if inputPath == "" {
+		return errors.New("must provide argument to --input")
+	}
+
+	format := dot.BlocksFormat(ctx.String(BlocksFormatFlag.Name))
+	if !format.IsValid() {
+		return fmt.Errorf("--%s must be either %s or %s",
+			BlocksFormatFlag.Name, dot.BinaryBlocksFormat, dot.JSONBlocksFormat)
+	}
+
+	lvl, err := setupLogger(ctx)
+	if err != nil {
+		logger.Errorf("failed to setup logger: %s", err)
+		return err
+	}
+
+	cfg, err := createDotConfig(ctx)
+	if err != nil {
+		logger.Errorf("failed to create node configuration: %s", err)
+		return err
+	}
+	cfg.Global.LogLvl = lvl
+	cfg.Global.BasePath = utils.ExpandDir(cfg.Global.BasePath)
+
+	if !dot.IsNodeInitialised(cfg.Global.BasePath) {
+		return fmt.Errorf("node at base path %s is not initialised", cfg.Global.BasePath)
+	}
+
+	file, err := os.Open(filepath.Clean(inputPath))
+	if err != nil {
+		return fmt.Errorf("opening input file: %w", err)
+	}
+	defer func() {
+		closeErr := file.Close()
+		if err == nil && closeErr != nil {
+			err = fmt.Errorf("closing input file: %w", closeErr)
+		}
+	}()
+
+	return dot.ImportBlocks(cfg, file, format)
+}
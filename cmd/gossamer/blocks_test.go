@@ -0,0 +1,50 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_blocksToNumber(t *testing.T) {
+	t.Parallel()
+
+	zero := uint(0)
+	ten := uint(10)
+
+	testCases := map[string]struct {
+		flags  []string
+		values []interface{}
+		to     *uint
+	}{
+		"not_set": {},
+		"set_to_zero": {
+			flags:  []string{BlocksToFlag.Name},
+			values: []interface{}{uint(0)},
+			to:     &zero,
+		},
+		"set": {
+			flags:  []string{BlocksToFlag.Name},
+			values: []interface{}{uint(10)},
+			to:     &ten,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, err := newTestContext(t.Name(), testCase.flags, testCase.values)
+			require.NoError(t, err)
+
+			to := blocksToNumber(ctx)
+
+			assert.Equal(t, testCase.to, to)
+		})
+	}
+}
@@ -16,10 +16,12 @@
 	ctoml "github.com/ChainSafe/gossamer/dot/config/toml"
 	"github.com/ChainSafe/gossamer/dot/state"
 	"github.com/ChainSafe/gossamer/dot/state/pruner"
+	"github.com/ChainSafe/gossamer/dot/sync"
 	"github.com/ChainSafe/gossamer/internal/log"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/genesis"
 	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
+	"github.com/ChainSafe/gossamer/lib/runtime/wazero"
 	"github.com/ChainSafe/gossamer/lib/utils"
 	"github.com/urfave/cli"
 )
@@ -158,7 +160,7 @@ func createInitConfig(ctx *cli.Context) (*dot.Config, error) {
 	}
 
 	if !cfg.Global.Pruning.IsValid() {
-		return nil, fmt.Errorf("--%s must be %s", PruningFlag.Name, pruner.Archive)
+		return nil, fmt.Errorf("--%s must be either %s or %s", PruningFlag.Name, pruner.Full, pruner.Archive)
 	}
 
 	if cfg.Global.RetainBlocks < dev.DefaultRetainBlocks {
@@ -188,6 +190,10 @@ func createInitConfig(ctx *cli.Context) (*dot.Config, error) {
 	// See /cmd/gossamer/main.go L192.
 	setDotNetworkConfig(ctx, tomlCfg.Network, &cfg.Network)
 
+	if cfg.Network.SyncMode != "" && !cfg.Network.SyncMode.IsValid() {
+		return nil, fmt.Errorf("--%s must be either %s or %s", SyncModeFlag.Name, sync.FullMode, sync.WarpMode)
+	}
+
 	return cfg, nil
 }
 
@@ -454,6 +460,7 @@ func setDotGlobalConfigFromToml(tomlCfg *ctoml.Config, cfg *dot.GlobalConfig) {
 
 		cfg.RetainBlocks = tomlCfg.Global.RetainBlocks
 		cfg.Pruning = pruner.Mode(tomlCfg.Global.Pruning)
+		cfg.TransactionStoragePeriod = tomlCfg.Global.TransactionStoragePeriod
 	}
 }
 
@@ -491,6 +498,16 @@ func setDotGlobalConfigFromFlags(ctx *cli.Context, cfg *dot.GlobalConfig) error
 
 	cfg.RetainBlocks = uint32(flagValue)
 	cfg.Pruning = pruner.Mode(ctx.String(PruningFlag.Name))
+
+	// check --transaction-storage-period flag and update node configuration
+	if ctx.IsSet(TransactionStoragePeriodFlag.Name) {
+		period := ctx.Uint(TransactionStoragePeriodFlag.Name)
+		if uint64(uint32Max) < uint64(period) {
+			return fmt.Errorf("transaction storage period overflows uint32 boundaries, "+
+				"must be less than or equal to: %d", uint32Max)
+		}
+		cfg.TransactionStoragePeriod = uint32(period)
+	}
 	cfg.NoTelemetry = ctx.Bool("no-telemetry")
 
 	var telemetryEndpoints []genesis.TelemetryEndpoint
@@ -585,6 +602,15 @@ func setDotCoreConfig(ctx *cli.Context, tomlCfg ctoml.CoreConfig, cfg *dot.CoreC
 		cfg.BABELead = ctx.GlobalBool(BABELeadFlag.Name)
 	}
 
+	cfg.OffchainWorkers = tomlCfg.OffchainWorkers
+	if ctx.IsSet(OffchainWorkersFlag.Name) {
+		cfg.OffchainWorkers = ctx.GlobalBool(OffchainWorkersFlag.Name)
+	}
+	cfg.OffchainWorkerTimeout = time.Second * time.Duration(tomlCfg.OffchainWorkerTimeout)
+	cfg.OffchainWorkerMaxConcurrency = tomlCfg.OffchainWorkerMaxConcurrency
+
+	cfg.RuntimeInstancePoolSize = tomlCfg.RuntimeInstancePoolSize
+
 	// check --roles flag and update node configuration
 	if roles := ctx.GlobalString(RolesFlag.Name); roles != "" {
 		// convert string to byte
@@ -621,9 +647,16 @@ func setDotCoreConfig(ctx *cli.Context, tomlCfg ctoml.CoreConfig, cfg *dot.CoreC
 		cfg.GrandpaAuthority = false
 	}
 
-	switch tomlCfg.WasmInterpreter {
+	wasmInterpreter := tomlCfg.WasmInterpreter
+	if ctx.IsSet(WasmInterpreterFlag.Name) {
+		wasmInterpreter = ctx.GlobalString(WasmInterpreterFlag.Name)
+	}
+
+	switch wasmInterpreter {
 	case wasmer.Name:
 		cfg.WasmInterpreter = wasmer.Name
+	case wazero.Name:
+		cfg.WasmInterpreter = wazero.Name
 	case "":
 		cfg.WasmInterpreter = gssmr.DefaultWasmInterpreter
 	default:
@@ -647,6 +680,7 @@ func setDotNetworkConfig(ctx *cli.Context, tomlCfg ctoml.NetworkConfig, cfg *dot
 	cfg.MaxPeers = tomlCfg.MaxPeers
 	cfg.PersistentPeers = tomlCfg.PersistentPeers
 	cfg.DiscoveryInterval = time.Second * time.Duration(tomlCfg.DiscoveryInterval)
+	cfg.SyncMode = sync.Mode(tomlCfg.SyncMode)
 
 	// check --port flag and update node configuration
 	if port := ctx.GlobalUint(PortFlag.Name); port != 0 {
@@ -688,6 +722,11 @@ func setDotNetworkConfig(ctx *cli.Context, tomlCfg ctoml.NetworkConfig, cfg *dot
 		cfg.PublicDNS = pubdns
 	}
 
+	// check --sync flag and update node configuration
+	if syncMode := ctx.GlobalString(SyncModeFlag.Name); syncMode != "" {
+		cfg.SyncMode = sync.Mode(syncMode)
+	}
+
 	if len(cfg.PersistentPeers) == 0 {
 		cfg.PersistentPeers = []string(nil)
 	}
@@ -695,10 +734,10 @@ func setDotNetworkConfig(ctx *cli.Context, tomlCfg ctoml.NetworkConfig, cfg *dot
 	logger.Debugf(
 		"network configuration: port=%d bootnodes=%s protocol=%s nobootstrap=%t "+
 			"nomdns=%t minpeers=%d maxpeers=%d persistent-peers=%s "+
-			"discovery-interval=%s",
+			"discovery-interval=%s sync-mode=%s",
 		cfg.Port, strings.Join(cfg.Bootnodes, ","), cfg.ProtocolID, cfg.NoBootstrap,
 		cfg.NoMDNS, cfg.MinPeers, cfg.MaxPeers, strings.Join(cfg.PersistentPeers, ","),
-		cfg.DiscoveryInterval,
+		cfg.DiscoveryInterval, cfg.SyncMode,
 	)
 }
 
@@ -716,6 +755,18 @@ func setDotRPCConfig(ctx *cli.Context, tomlCfg ctoml.RPCConfig, cfg *dot.RPCConf
 	cfg.WSExternal = tomlCfg.WSExternal
 	cfg.WSUnsafe = tomlCfg.WSUnsafe
 	cfg.WSUnsafeExternal = tomlCfg.WSUnsafeExternal
+	// the default maximum batch size is kept if it is not set in the toml configuration,
+	// and batches can only be unlimited with the --rpc-max-batch-size flag.
+	if tomlCfg.MaxBatchSize != 0 {
+		cfg.MaxBatchSize = tomlCfg.MaxBatchSize
+	}
+	cfg.WSMaxConnections = tomlCfg.WSMaxConnections
+	cfg.WSMaxSubscriptionsPerConnection = tomlCfg.WSMaxSubscriptionsPerConnection
+	cfg.MaxRequestSize = tomlCfg.MaxRequestSize
+	cfg.MaxResponseSize = tomlCfg.MaxResponseSize
+	cfg.RateLimit = tomlCfg.RateLimit
+	cfg.RateLimitBurst = tomlCfg.RateLimitBurst
+	cfg.CORS = tomlCfg.CORS
 
 	// check --rpc flag and update node configuration
 	if enabled := ctx.GlobalBool(RPCEnabledFlag.Name); enabled || cfg.Enabled {
@@ -788,6 +839,46 @@ func setDotRPCConfig(ctx *cli.Context, tomlCfg ctoml.RPCConfig, cfg *dot.RPCConf
 		cfg.WSExternal = false
 	}
 
+	// check --rpc-max-batch-size flag and update node configuration
+	if ctx.IsSet(RPCMaxBatchSizeFlag.Name) {
+		cfg.MaxBatchSize = uint32(ctx.GlobalUint(RPCMaxBatchSizeFlag.Name))
+	}
+
+	// check --ws-max-connections flag and update node configuration
+	if ctx.IsSet(WSMaxConnectionsFlag.Name) {
+		cfg.WSMaxConnections = uint32(ctx.GlobalUint(WSMaxConnectionsFlag.Name))
+	}
+
+	// check --ws-max-subscriptions-per-connection flag and update node configuration
+	if ctx.IsSet(WSMaxSubscriptionsPerConnectionFlag.Name) {
+		cfg.WSMaxSubscriptionsPerConnection = uint32(ctx.GlobalUint(WSMaxSubscriptionsPerConnectionFlag.Name))
+	}
+
+	// check --rpc-max-request-size flag and update node configuration
+	if ctx.IsSet(RPCMaxRequestSizeFlag.Name) {
+		cfg.MaxRequestSize = uint32(ctx.GlobalUint(RPCMaxRequestSizeFlag.Name))
+	}
+
+	// check --rpc-max-response-size flag and update node configuration
+	if ctx.IsSet(RPCMaxResponseSizeFlag.Name) {
+		cfg.MaxResponseSize = uint32(ctx.GlobalUint(RPCMaxResponseSizeFlag.Name))
+	}
+
+	// check --rpc-rate-limit flag and update node configuration
+	if ctx.IsSet(RPCRateLimitFlag.Name) {
+		cfg.RateLimit = uint32(ctx.GlobalUint(RPCRateLimitFlag.Name))
+	}
+
+	// check --rpc-rate-limit-burst flag and update node configuration
+	if ctx.IsSet(RPCRateLimitBurstFlag.Name) {
+		cfg.RateLimitBurst = uint32(ctx.GlobalUint(RPCRateLimitBurstFlag.Name))
+	}
+
+	// check --rpc-cors flag and update node configuration
+	if cors := ctx.GlobalString(RPCCORSFlag.Name); cors != "" {
+		cfg.CORS = strings.Split(cors, ",")
+	}
+
 	// format rpc modules
 	if len(cfg.Modules) == 0 {
 		cfg.Modules = []string(nil)
@@ -24,6 +24,7 @@
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/genesis"
 	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/wazero"
 	"github.com/ChainSafe/gossamer/lib/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -401,6 +402,18 @@ func TestCoreConfigFromFlags(t *testing.T) {
 				GrandpaInterval:  testCfg.Core.GrandpaInterval,
 			},
 		},
+		{
+			"Test gossamer --wasm-interpreter",
+			[]string{"config", "roles", "wasm-interpreter"},
+			[]interface{}{testCfgFile, "4", "wazero"},
+			dot.CoreConfig{
+				Roles:            4,
+				BabeAuthority:    true,
+				GrandpaAuthority: true,
+				WasmInterpreter:  wazero.Name,
+				GrandpaInterval:  testCfg.Core.GrandpaInterval,
+			},
+		},
 	}
 
 	for _, c := range testcases {
@@ -567,14 +580,15 @@ func TestRPCConfigFromFlags(t *testing.T) {
 			[]string{"config", "rpc"},
 			[]interface{}{testCfgFile, "true"},
 			dot.RPCConfig{
-				Enabled:    true,
-				External:   testCfg.RPC.External,
-				Port:       testCfg.RPC.Port,
-				Host:       testCfg.RPC.Host,
-				Modules:    testCfg.RPC.Modules,
-				WSPort:     testCfg.RPC.WSPort,
-				WS:         testCfg.RPC.WS,
-				WSExternal: testCfg.RPC.WSExternal,
+				Enabled:      true,
+				External:     testCfg.RPC.External,
+				Port:         testCfg.RPC.Port,
+				Host:         testCfg.RPC.Host,
+				Modules:      testCfg.RPC.Modules,
+				WSPort:       testCfg.RPC.WSPort,
+				MaxBatchSize: testCfg.RPC.MaxBatchSize,
+				WS:           testCfg.RPC.WS,
+				WSExternal:   testCfg.RPC.WSExternal,
 			},
 		},
 		{
@@ -582,14 +596,15 @@ func TestRPCConfigFromFlags(t *testing.T) {
 			[]string{"config", "rpc"},
 			[]interface{}{testCfgFile, "false"},
 			dot.RPCConfig{
-				Enabled:    false,
-				External:   testCfg.RPC.External,
-				Port:       testCfg.RPC.Port,
-				Host:       testCfg.RPC.Host,
-				Modules:    testCfg.RPC.Modules,
-				WSPort:     testCfg.RPC.WSPort,
-				WS:         testCfg.RPC.WS,
-				WSExternal: testCfg.RPC.WSExternal,
+				Enabled:      false,
+				External:     testCfg.RPC.External,
+				Port:         testCfg.RPC.Port,
+				Host:         testCfg.RPC.Host,
+				Modules:      testCfg.RPC.Modules,
+				WSPort:       testCfg.RPC.WSPort,
+				MaxBatchSize: testCfg.RPC.MaxBatchSize,
+				WS:           testCfg.RPC.WS,
+				WSExternal:   testCfg.RPC.WSExternal,
 			},
 		},
 		{
@@ -597,14 +612,15 @@ func TestRPCConfigFromFlags(t *testing.T) {
 			[]string{"config", "rpc-external"},
 			[]interface{}{testCfgFile, "true"},
 			dot.RPCConfig{
-				Enabled:    true,
-				External:   true,
-				Port:       testCfg.RPC.Port,
-				Host:       testCfg.RPC.Host,
-				Modules:    testCfg.RPC.Modules,
-				WSPort:     testCfg.RPC.WSPort,
-				WS:         testCfg.RPC.WS,
-				WSExternal: testCfg.RPC.WSExternal,
+				Enabled:      true,
+				External:     true,
+				Port:         testCfg.RPC.Port,
+				Host:         testCfg.RPC.Host,
+				Modules:      testCfg.RPC.Modules,
+				WSPort:       testCfg.RPC.WSPort,
+				MaxBatchSize: testCfg.RPC.MaxBatchSize,
+				WS:           testCfg.RPC.WS,
+				WSExternal:   testCfg.RPC.WSExternal,
 			},
 		},
 		{
@@ -612,14 +628,15 @@ func TestRPCConfigFromFlags(t *testing.T) {
 			[]string{"config", "rpc-external"},
 			[]interface{}{testCfgFile, "false"},
 			dot.RPCConfig{
-				Enabled:    true,
-				External:   false,
-				Port:       testCfg.RPC.Port,
-				Host:       testCfg.RPC.Host,
-				Modules:    testCfg.RPC.Modules,
-				WSPort:     testCfg.RPC.WSPort,
-				WS:         testCfg.RPC.WS,
-				WSExternal: testCfg.RPC.WSExternal,
+				Enabled:      true,
+				External:     false,
+				Port:         testCfg.RPC.Port,
+				Host:         testCfg.RPC.Host,
+				Modules:      testCfg.RPC.Modules,
+				WSPort:       testCfg.RPC.WSPort,
+				MaxBatchSize: testCfg.RPC.MaxBatchSize,
+				WS:           testCfg.RPC.WS,
+				WSExternal:   testCfg.RPC.WSExternal,
 			},
 		},
 		{
@@ -627,14 +644,15 @@ func TestRPCConfigFromFlags(t *testing.T) {
 			[]string{"config", "rpchost"},
 			[]interface{}{testCfgFile, "testhost"}, // rpc must be enabled
 			dot.RPCConfig{
-				Enabled:    testCfg.RPC.Enabled,
-				External:   testCfg.RPC.External,
-				Port:       testCfg.RPC.Port,
-				Host:       "testhost",
-				Modules:    testCfg.RPC.Modules,
-				WSPort:     testCfg.RPC.WSPort,
-				WS:         testCfg.RPC.WS,
-				WSExternal: testCfg.RPC.WSExternal,
+				Enabled:      testCfg.RPC.Enabled,
+				External:     testCfg.RPC.External,
+				Port:         testCfg.RPC.Port,
+				Host:         "testhost",
+				Modules:      testCfg.RPC.Modules,
+				WSPort:       testCfg.RPC.WSPort,
+				MaxBatchSize: testCfg.RPC.MaxBatchSize,
+				WS:           testCfg.RPC.WS,
+				WSExternal:   testCfg.RPC.WSExternal,
 			},
 		},
 		{
@@ -642,14 +660,15 @@ func TestRPCConfigFromFlags(t *testing.T) {
 			[]string{"config", "rpcport"},
 			[]interface{}{testCfgFile, "5678"}, // rpc must be enabled
 			dot.RPCConfig{
-				Enabled:    testCfg.RPC.Enabled,
-				External:   testCfg.RPC.External,
-				Port:       5678,
-				Host:       testCfg.RPC.Host,
-				Modules:    testCfg.RPC.Modules,
-				WSPort:     testCfg.RPC.WSPort,
-				WS:         testCfg.RPC.WS,
-				WSExternal: testCfg.RPC.WSExternal,
+				Enabled:      testCfg.RPC.Enabled,
+				External:     testCfg.RPC.External,
+				Port:         5678,
+				Host:         testCfg.RPC.Host,
+				Modules:      testCfg.RPC.Modules,
+				WSPort:       testCfg.RPC.WSPort,
+				MaxBatchSize: testCfg.RPC.MaxBatchSize,
+				WS:           testCfg.RPC.WS,
+				WSExternal:   testCfg.RPC.WSExternal,
 			},
 		},
 		{
@@ -657,14 +676,15 @@ func TestRPCConfigFromFlags(t *testing.T) {
 			[]string{"config", "rpcmods"},
 			[]interface{}{testCfgFile, "mod1,mod2"}, // rpc must be enabled
 			dot.RPCConfig{
-				Enabled:    testCfg.RPC.Enabled,
-				External:   testCfg.RPC.External,
-				Port:       testCfg.RPC.Port,
-				Host:       testCfg.RPC.Host,
-				Modules:    []string{"mod1", "mod2"},
-				WSPort:     testCfg.RPC.WSPort,
-				WS:         testCfg.RPC.WS,
-				WSExternal: testCfg.RPC.WSExternal,
+				Enabled:      testCfg.RPC.Enabled,
+				External:     testCfg.RPC.External,
+				Port:         testCfg.RPC.Port,
+				Host:         testCfg.RPC.Host,
+				Modules:      []string{"mod1", "mod2"},
+				WSPort:       testCfg.RPC.WSPort,
+				MaxBatchSize: testCfg.RPC.MaxBatchSize,
+				WS:           testCfg.RPC.WS,
+				WSExternal:   testCfg.RPC.WSExternal,
 			},
 		},
 		{
@@ -672,14 +692,31 @@ func TestRPCConfigFromFlags(t *testing.T) {
 			[]string{"config", "wsport"},
 			[]interface{}{testCfgFile, "7070"},
 			dot.RPCConfig{
-				Enabled:    testCfg.RPC.Enabled,
-				External:   testCfg.RPC.External,
-				Port:       testCfg.RPC.Port,
-				Host:       testCfg.RPC.Host,
-				Modules:    testCfg.RPC.Modules,
-				WSPort:     7070,
-				WS:         testCfg.RPC.WS,
-				WSExternal: testCfg.RPC.WSExternal,
+				Enabled:      testCfg.RPC.Enabled,
+				External:     testCfg.RPC.External,
+				Port:         testCfg.RPC.Port,
+				Host:         testCfg.RPC.Host,
+				Modules:      testCfg.RPC.Modules,
+				WSPort:       7070,
+				MaxBatchSize: testCfg.RPC.MaxBatchSize,
+				WS:           testCfg.RPC.WS,
+				WSExternal:   testCfg.RPC.WSExternal,
+			},
+		},
+		{
+			"Test gossamer --rpc-max-batch-size",
+			[]string{"config", "rpc-max-batch-size"},
+			[]interface{}{testCfgFile, "0"},
+			dot.RPCConfig{
+				Enabled:      testCfg.RPC.Enabled,
+				External:     testCfg.RPC.External,
+				Port:         testCfg.RPC.Port,
+				Host:         testCfg.RPC.Host,
+				Modules:      testCfg.RPC.Modules,
+				WSPort:       testCfg.RPC.WSPort,
+				MaxBatchSize: 0,
+				WS:           testCfg.RPC.WS,
+				WSExternal:   testCfg.RPC.WSExternal,
 			},
 		},
 		{
@@ -687,14 +724,15 @@ func TestRPCConfigFromFlags(t *testing.T) {
 			[]string{"config", "ws"},
 			[]interface{}{testCfgFile, true},
 			dot.RPCConfig{
-				Enabled:    testCfg.RPC.Enabled,
-				External:   testCfg.RPC.External,
-				Port:       testCfg.RPC.Port,
-				Host:       testCfg.RPC.Host,
-				Modules:    testCfg.RPC.Modules,
-				WSPort:     testCfg.RPC.WSPort,
-				WS:         true,
-				WSExternal: testCfg.RPC.WSExternal,
+				Enabled:      testCfg.RPC.Enabled,
+				External:     testCfg.RPC.External,
+				Port:         testCfg.RPC.Port,
+				Host:         testCfg.RPC.Host,
+				Modules:      testCfg.RPC.Modules,
+				WSPort:       testCfg.RPC.WSPort,
+				MaxBatchSize: testCfg.RPC.MaxBatchSize,
+				WS:           true,
+				WSExternal:   testCfg.RPC.WSExternal,
 			},
 		},
 		{
@@ -702,14 +740,15 @@ func TestRPCConfigFromFlags(t *testing.T) {
 			[]string{"config", "w"},
 			[]interface{}{testCfgFile, false},
 			dot.RPCConfig{
-				Enabled:    testCfg.RPC.Enabled,
-				External:   testCfg.RPC.External,
-				Port:       testCfg.RPC.Port,
-				Host:       testCfg.RPC.Host,
-				Modules:    testCfg.RPC.Modules,
-				WSPort:     testCfg.RPC.WSPort,
-				WS:         false,
-				WSExternal: testCfg.RPC.WSExternal,
+				Enabled:      testCfg.RPC.Enabled,
+				External:     testCfg.RPC.External,
+				Port:         testCfg.RPC.Port,
+				Host:         testCfg.RPC.Host,
+				Modules:      testCfg.RPC.Modules,
+				WSPort:       testCfg.RPC.WSPort,
+				MaxBatchSize: testCfg.RPC.MaxBatchSize,
+				WS:           false,
+				WSExternal:   testCfg.RPC.WSExternal,
 			},
 		},
 		{
@@ -717,14 +756,15 @@ func TestRPCConfigFromFlags(t *testing.T) {
 			[]string{"config", "ws-external"},
 			[]interface{}{testCfgFile, true},
 			dot.RPCConfig{
-				Enabled:    testCfg.RPC.Enabled,
-				External:   testCfg.RPC.External,
-				Port:       testCfg.RPC.Port,
-				Host:       testCfg.RPC.Host,
-				Modules:    testCfg.RPC.Modules,
-				WSPort:     testCfg.RPC.WSPort,
-				WS:         true,
-				WSExternal: true,
+				Enabled:      testCfg.RPC.Enabled,
+				External:     testCfg.RPC.External,
+				Port:         testCfg.RPC.Port,
+				Host:         testCfg.RPC.Host,
+				Modules:      testCfg.RPC.Modules,
+				WSPort:       testCfg.RPC.WSPort,
+				MaxBatchSize: testCfg.RPC.MaxBatchSize,
+				WS:           true,
+				WSExternal:   true,
 			},
 		},
 		{
@@ -732,14 +772,15 @@ func TestRPCConfigFromFlags(t *testing.T) {
 			[]string{"config", "ws-external"},
 			[]interface{}{testCfgFile, false},
 			dot.RPCConfig{
-				Enabled:    testCfg.RPC.Enabled,
-				External:   testCfg.RPC.External,
-				Port:       testCfg.RPC.Port,
-				Host:       testCfg.RPC.Host,
-				Modules:    testCfg.RPC.Modules,
-				WSPort:     testCfg.RPC.WSPort,
-				WS:         true,
-				WSExternal: false,
+				Enabled:      testCfg.RPC.Enabled,
+				External:     testCfg.RPC.External,
+				Port:         testCfg.RPC.Port,
+				Host:         testCfg.RPC.Host,
+				Modules:      testCfg.RPC.Modules,
+				WSPort:       testCfg.RPC.WSPort,
+				MaxBatchSize: testCfg.RPC.MaxBatchSize,
+				WS:           true,
+				WSExternal:   false,
 			},
 		},
 	}
@@ -78,6 +78,8 @@ func dotConfigToToml(dcfg *dot.Config) *ctoml.Config {
 		MetricsAddress: dcfg.Global.MetricsAddress,
 		RetainBlocks:   dcfg.Global.RetainBlocks,
 		Pruning:        string(dcfg.Global.Pruning),
+
+		TransactionStoragePeriod: dcfg.Global.TransactionStoragePeriod,
 	}
 
 	cfg.Log = ctoml.LogConfig{
@@ -105,6 +107,12 @@ func dotConfigToToml(dcfg *dot.Config) *ctoml.Config {
 		BabeAuthority:    dcfg.Core.BabeAuthority,
 		GrandpaAuthority: dcfg.Core.GrandpaAuthority,
 		GrandpaInterval:  uint32(dcfg.Core.GrandpaInterval / time.Second),
+
+		OffchainWorkers:              dcfg.Core.OffchainWorkers,
+		OffchainWorkerTimeout:        uint32(dcfg.Core.OffchainWorkerTimeout / time.Second),
+		OffchainWorkerMaxConcurrency: dcfg.Core.OffchainWorkerMaxConcurrency,
+
+		RuntimeInstancePoolSize: dcfg.Core.RuntimeInstancePoolSize,
 	}
 
 	cfg.Network = ctoml.NetworkConfig{
@@ -116,6 +124,7 @@ func dotConfigToToml(dcfg *dot.Config) *ctoml.Config {
 		DiscoveryInterval: int(dcfg.Network.DiscoveryInterval / time.Second),
 		MinPeers:          dcfg.Network.MinPeers,
 		MaxPeers:          dcfg.Network.MaxPeers,
+		SyncMode:          string(dcfg.Network.SyncMode),
 	}
 
 	cfg.RPC = ctoml.RPCConfig{
@@ -131,6 +140,15 @@ func dotConfigToToml(dcfg *dot.Config) *ctoml.Config {
 		WSExternal:       dcfg.RPC.WSExternal,
 		WSUnsafe:         dcfg.RPC.WSUnsafe,
 		WSUnsafeExternal: dcfg.RPC.WSUnsafeExternal,
+		MaxBatchSize:     dcfg.RPC.MaxBatchSize,
+
+		WSMaxConnections:                dcfg.RPC.WSMaxConnections,
+		WSMaxSubscriptionsPerConnection: dcfg.RPC.WSMaxSubscriptionsPerConnection,
+		MaxRequestSize:                  dcfg.RPC.MaxRequestSize,
+		MaxResponseSize:                 dcfg.RPC.MaxResponseSize,
+		RateLimit:                       dcfg.RPC.RateLimit,
+		RateLimitBurst:                  dcfg.RPC.RateLimitBurst,
+		CORS:                            dcfg.RPC.CORS,
 	}
 
 	return cfg
@@ -179,6 +179,32 @@
 	}
 )
 
+// ExportBlocks and ImportBlocks flags
+var (
+	BlocksFromFlag = cli.UintFlag{
+		Name:  "from",
+		Usage: "Number of the first block to export",
+		Value: 1,
+	}
+	BlocksToFlag = cli.UintFlag{
+		Name:  "to",
+		Usage: "Number of the last block to export, defaults to the best block",
+	}
+	BlocksFormatFlag = cli.StringFlag{
+		Name:  "format",
+		Usage: "Format of the blocks file, either binary or json",
+		Value: "binary",
+	}
+	BlocksOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "Path to the file to write the exported blocks to",
+	}
+	BlocksInputFlag = cli.StringFlag{
+		Name:  "input",
+		Usage: "Path to the blocks file to import",
+	}
+)
+
 // BuildSpec-only flags
 var (
 	RawFlag = cli.BoolFlag{
@@ -232,6 +258,11 @@
 		Name:  "pubdns",
 		Usage: "Overrides public DNS used for peer to peer networking",
 	}
+	// SyncModeFlag sets the sync mode
+	SyncModeFlag = cli.StringFlag{
+		Name:  "sync",
+		Usage: "Sync mode, either full or warp. Warp mode verifies GRANDPA finality proofs before syncing blocks",
+	}
 )
 
 // RPC service configuration flags
@@ -296,6 +327,46 @@
 		Name:  "ws-unsafe-external",
 		Usage: "Enable external access to websocket unsafe calls",
 	}
+	// RPCMaxBatchSizeFlag Maximum number of requests in a JSON-RPC batch
+	RPCMaxBatchSizeFlag = cli.UintFlag{
+		Name:  "rpc-max-batch-size",
+		Usage: "Maximum number of requests in a HTTP-RPC or websocket JSON-RPC batch, defaults to 100, 0 for no limit",
+	}
+	// WSMaxConnectionsFlag Maximum number of websocket connections
+	WSMaxConnectionsFlag = cli.UintFlag{
+		Name:  "ws-max-connections",
+		Usage: "Maximum number of websocket connections, 0 for no limit",
+	}
+	// WSMaxSubscriptionsPerConnectionFlag Maximum number of subscriptions per websocket connection
+	WSMaxSubscriptionsPerConnectionFlag = cli.UintFlag{
+		Name:  "ws-max-subscriptions-per-connection",
+		Usage: "Maximum number of subscriptions per websocket connection, 0 for no limit",
+	}
+	// RPCMaxRequestSizeFlag Maximum size of a HTTP-RPC or websocket request
+	RPCMaxRequestSizeFlag = cli.UintFlag{
+		Name:  "rpc-max-request-size",
+		Usage: "Maximum size in megabytes of a HTTP-RPC or websocket request, 0 for no limit",
+	}
+	// RPCMaxResponseSizeFlag Maximum size of a HTTP-RPC or websocket response
+	RPCMaxResponseSizeFlag = cli.UintFlag{
+		Name:  "rpc-max-response-size",
+		Usage: "Maximum size in megabytes of a HTTP-RPC or websocket response, 0 for no limit",
+	}
+	// RPCRateLimitFlag Number of requests per second allowed for each IP address
+	RPCRateLimitFlag = cli.UintFlag{
+		Name:  "rpc-rate-limit",
+		Usage: "Number of HTTP-RPC and websocket requests per second allowed for each IP address, 0 for no limit",
+	}
+	// RPCRateLimitBurstFlag Number of requests allowed in a burst for each IP address
+	RPCRateLimitBurstFlag = cli.UintFlag{
+		Name:  "rpc-rate-limit-burst",
+		Usage: "Number of HTTP-RPC and websocket requests allowed in a burst for each IP address, defaults to the rate limit",
+	}
+	// RPCCORSFlag Origins allowed to send HTTP-RPC and websocket requests from a browser
+	RPCCORSFlag = cli.StringFlag{
+		Name:  "rpc-cors",
+		Usage: "Origins allowed to send HTTP-RPC and websocket requests from a browser, comma separated list, \"*\" for all",
+	}
 )
 
 // Account management flags
@@ -355,9 +426,16 @@
 	// PruningFlag triggers the online pruning of historical state tries.
 	PruningFlag = cli.StringFlag{
 		Name:  "pruning",
-		Usage: `State trie online pruning ("archive")`,
+		Usage: `State trie online pruning ("full", "archive")`,
 		Value: dev.DefaultPruningMode,
 	}
+
+	// TransactionStoragePeriodFlag sets the number of finalised blocks for which
+	// indexed transactions are kept, zero keeping them forever.
+	TransactionStoragePeriodFlag = cli.UintFlag{
+		Name:  "transaction-storage-period",
+		Usage: "Number of finalised blocks for which indexed transactions are kept, 0 to keep them forever",
+	}
 )
 
 // BABE flags
@@ -368,6 +446,24 @@
 	}
 )
 
+// Offchain worker flags
+var (
+	// OffchainWorkersFlag enables running the runtime offchain workers on each new best block
+	OffchainWorkersFlag = cli.BoolFlag{
+		Name:  "offchain-workers",
+		Usage: "Run the runtime offchain workers on each new best block",
+	}
+)
+
+// Runtime flags
+var (
+	// WasmInterpreterFlag sets the wasm interpreter used to run the runtime
+	WasmInterpreterFlag = cli.StringFlag{
+		Name:  "wasm-interpreter",
+		Usage: "Wasm interpreter used to run the runtime; can be 'wasmer' or 'wazero'",
+	}
+)
+
 // flag sets that are shared by multiple commands
 var (
 	// GlobalFlags are flags that are valid for use with the root command and all subcommands
@@ -408,6 +504,7 @@
 		NoMDNSFlag,
 		PublicIPFlag,
 		PublicDNSFlag,
+		SyncModeFlag,
 
 		// rpc flags
 		RPCEnabledFlag,
@@ -422,6 +519,14 @@
 		WSUnsafeEnabledFlag,
 		WSUnsafeExternalFlag,
 		WSPortFlag,
+		RPCMaxBatchSizeFlag,
+		WSMaxConnectionsFlag,
+		WSMaxSubscriptionsPerConnectionFlag,
+		RPCMaxRequestSizeFlag,
+		RPCMaxResponseSizeFlag,
+		RPCRateLimitFlag,
+		RPCRateLimitBurstFlag,
+		RPCCORSFlag,
 
 		// metrics flag
 		PublishMetricsFlag,
@@ -433,6 +538,15 @@
 
 		// BABE flags
 		BABELeadFlag,
+
+		// offchain worker flags
+		OffchainWorkersFlag,
+
+		// runtime flags
+		WasmInterpreterFlag,
+
+		// state flags
+		TransactionStoragePeriodFlag,
 	}
 )
 
@@ -482,6 +596,18 @@
 		FirstSlotFlag,
 	}
 
+	ExportBlocksFlags = append([]cli.Flag{
+		BlocksFromFlag,
+		BlocksToFlag,
+		BlocksFormatFlag,
+		BlocksOutputFlag,
+	}, GlobalFlags...)
+
+	ImportBlocksFlags = append([]cli.Flag{
+		BlocksInputFlag,
+		BlocksFormatFlag,
+	}, append(GlobalFlags, StartupFlags...)...)
+
 	PruningFlags = []cli.Flag{
 		ChainFlag,
 		ConfigFlag,
@@ -28,6 +28,8 @@
 	importRuntimeCommandName = "import-runtime"
 	importStateCommandName   = "import-state"
 	pruningStateCommandName  = "prune-state"
+	exportBlocksCommandName  = "export-blocks"
+	importBlocksCommandName  = "import-blocks"
 )
 
 // app is the cli application
@@ -114,6 +116,30 @@
 			"\tUsage: gossamer import-state --state state.json --header header.json --first-slot <first slot of network>\n",
 	}
 
+	exportBlocksCommand = cli.Command{
+		Action:    FixFlagOrder(exportBlocksAction),
+		Name:      exportBlocksCommandName,
+		Usage:     "Export blocks from the node database to a file",
+		ArgsUsage: "",
+		Flags:     ExportBlocksFlags,
+		Category:  "EXPORT-BLOCKS",
+		Description: "The export-blocks command writes the header, body and justification " +
+			"of each block in the given range to a file, in binary (SCALE) or json format.\n" +
+			"\tUsage: gossamer export-blocks --chain <chain-name> --from 1 --to 1000 --output blocks.bin\n",
+	}
+
+	importBlocksCommand = cli.Command{
+		Action:    FixFlagOrder(importBlocksAction),
+		Name:      importBlocksCommandName,
+		Usage:     "Import blocks from a file written by export-blocks",
+		ArgsUsage: "",
+		Flags:     ImportBlocksFlags,
+		Category:  "IMPORT-BLOCKS",
+		Description: "The import-blocks command verifies, executes and imports the blocks " +
+			"of a file written by export-blocks, the same way as blocks synced from peers.\n" +
+			"\tUsage: gossamer import-blocks --chain <chain-name> --input blocks.bin\n",
+	}
+
 	pruningCommand = cli.Command{
 		Action:    FixFlagOrder(pruneState),
 		Name:      pruningStateCommandName,
@@ -143,6 +169,8 @@ func init() {
 		importRuntimeCommand,
 		importStateCommand,
 		pruningCommand,
+		exportBlocksCommand,
+		importBlocksCommand,
 	}
 	app.Flags = RootFlags
 }
@@ -277,6 +305,16 @@ func gossamerAction(ctx *cli.Context) error {
 		return err
 	}
 
+	// session keys generated by author_rotateKeys are encrypted using the --password value
+	cfg.Account.Password = ctx.String(PasswordFlag.Name)
+	if cfg.Account.Password != "" {
+		err = keystore.LoadSessionKeys(ks, cfg.Global.BasePath, []byte(cfg.Account.Password))
+		if err != nil {
+			logger.Errorf("failed to load session keys: %s", err)
+			return err
+		}
+	}
+
 	node, err := dot.NewNode(cfg, ks)
 	if err != nil {
 		logger.Errorf("failed to create node services: %s", err)
@@ -0,0 +1,426 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/telemetry"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+// BlocksFormat is the format of an exported blocks file.
+type BlocksFormat string
+
+const (
+	// BinaryBlocksFormat is the format of a file containing
+	// the SCALE encoded block data of each block, one after the other.
+	BinaryBlocksFormat BlocksFormat = "binary"
+	// JSONBlocksFormat is the format of a file containing a JSON object
+	// per block, with its SCALE encoded header and extrinsics as hex strings.
+	JSONBlocksFormat BlocksFormat = "json"
+)
+
+// IsValid checks whether the blocks format is valid
+func (f BlocksFormat) IsValid() bool {
+	switch f {
+	case BinaryBlocksFormat, JSONBlocksFormat:
+		return true
+	default:
+		return false
+	}
+}
+
+// ExportBlocks writes the block data of the blocks from number `from` to number `to`
+// included from the database at the given base path to the writer. If `to` is nil,
+// blocks are exported up to the best block.
+func ExportBlocks(basepath string, from uint, to *uint, format BlocksFormat, w io.Writer) (err error) {
+	writer, err := newBlockDataWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	stateSrvc := state.NewService(state.Config{
+		Path:      basepath,
+		LogLevel:  log.Info,
+		Telemetry: telemetry.NewNoopMailer(),
+	})
+
+	err = stateSrvc.SetupBase()
+	if err != nil {
+		return fmt.Errorf("cannot setup state database: %w", err)
+	}
+
+	err = stateSrvc.Start()
+	if err != nil {
+		return fmt.Errorf("cannot start state service: %w", err)
+	}
+	defer func() {
+		stopErr := stateSrvc.Stop()
+		if err == nil && stopErr != nil {
+			err = fmt.Errorf("cannot stop state service: %w", stopErr)
+		}
+	}()
+
+	var last uint
+	if to != nil {
+		last = *to
+	} else {
+		last, err = stateSrvc.Block.BestBlockNumber()
+		if err != nil {
+			return fmt.Errorf("getting best block number: %w", err)
+		}
+	}
+
+	if from > last {
+		return fmt.Errorf("%w: from block #%d is after to block #%d", errInvalidBlocksRange, from, last)
+	}
+
+	for number := from; number <= last; number++ {
+		blockData, err := getBlockData(stateSrvc.Block, number)
+		if err != nil {
+			return fmt.Errorf("getting block data for block #%d: %w", number, err)
+		}
+
+		err = writer.write(blockData)
+		if err != nil {
+			return fmt.Errorf("writing block data for block #%d: %w", number, err)
+		}
+	}
+
+	logger.Infof("exported %d blocks from block #%d to block #%d", last-from+1, from, last)
+	return writer.flush()
+}
+
+func getBlockData(blockState *state.BlockState, number uint) (blockData *types.BlockData, err error) {
+	hash, err := blockState.GetHashByNumber(number)
+	if err != nil {
+		return nil, fmt.Errorf("getting block hash: %w", err)
+	}
+
+	header, err := blockState.GetHeader(hash)
+	if err != nil {
+		return nil, fmt.Errorf("getting header: %w", err)
+	}
+
+	body, err := blockState.GetBlockBody(hash)
+	if err != nil {
+		return nil, fmt.Errorf("getting body: %w", err)
+	}
+
+	blockData = &types.BlockData{
+		Hash:   hash,
+		Header: header,
+		Body:   body,
+	}
+
+	hasJustification, err := blockState.HasJustification(hash)
+	if err != nil {
+		return nil, fmt.Errorf("checking for justification: %w", err)
+	}
+
+	if hasJustification {
+		justification, err := blockState.GetJustification(hash)
+		if err != nil {
+			return nil, fmt.Errorf("getting justification: %w", err)
+		}
+		blockData.Justification = &justification
+	}
+
+	return blockData, nil
+}
+
+// ImportBlocks reads the block data of the blocks from the reader and imports them
+// in the node database, verifying and executing each block the same way as blocks
+// received from peers when syncing. The node must be initialised already.
+func ImportBlocks(cfg *Config, r io.Reader, format BlocksFormat) (err error) {
+	reader, err := newBlockDataReader(r, format)
+	if err != nil {
+		return err
+	}
+
+	// the node is not an authority and does not run offchain workers while importing blocks
+	cfg.Core.BabeAuthority = false
+	cfg.Core.GrandpaAuthority = false
+	cfg.Core.OffchainWorkers = false
+
+	builder := nodeBuilder{}
+	telemetryMailer := telemetry.NewNoopMailer()
+
+	stateSrvc, err := builder.createStateService(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create state service: %w", err)
+	}
+	stateSrvc.Telemetry = telemetryMailer
+
+	err = startStateService(cfg, stateSrvc)
+	if err != nil {
+		return fmt.Errorf("cannot start state service: %w", err)
+	}
+	defer func() {
+		stopErr := stateSrvc.Stop()
+		if err == nil && stopErr != nil {
+			err = fmt.Errorf("cannot stop state service: %w", stopErr)
+		}
+	}()
+
+	ns, err := builder.createRuntimeStorage(stateSrvc)
+	if err != nil {
+		return fmt.Errorf("failed to create runtime storage: %w", err)
+	}
+
+	ks := keystore.NewGlobalKeystore()
+	err = builder.loadRuntime(cfg, ns, stateSrvc, ks, nil)
+	if err != nil {
+		return fmt.Errorf("failed to load runtime: %w", err)
+	}
+
+	verifier := builder.createBlockVerifier(stateSrvc)
+
+	digestHandler, err := builder.createDigestHandler(cfg.Log.DigestLvl, stateSrvc)
+	if err != nil {
+		return fmt.Errorf("failed to create digest handler: %w", err)
+	}
+
+	err = digestHandler.Start()
+	if err != nil {
+		return fmt.Errorf("failed to start digest handler: %w", err)
+	}
+	defer func() {
+		stopErr := digestHandler.Stop()
+		if err == nil && stopErr != nil {
+			err = fmt.Errorf("failed to stop digest handler: %w", stopErr)
+		}
+	}()
+
+	coreSrvc, err := builder.createCoreService(cfg, ks, stateSrvc, nil, digestHandler)
+	if err != nil {
+		return fmt.Errorf("failed to create core service: %w", err)
+	}
+
+	finalityGadget, err := builder.createGRANDPAService(cfg, stateSrvc, ks.Gran, nil, telemetryMailer)
+	if err != nil {
+		return fmt.Errorf("failed to create grandpa service: %w", err)
+	}
+
+	syncer, err := builder.newSyncService(cfg, stateSrvc, finalityGadget, verifier, coreSrvc, nil, telemetryMailer)
+	if err != nil {
+		return fmt.Errorf("failed to create sync service: %w", err)
+	}
+
+	imported := 0
+	for {
+		blockData, err := reader.read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("reading block data: %w", err)
+		}
+
+		err = syncer.ImportBlockData(blockData)
+		if err != nil {
+			return fmt.Errorf("importing block #%d (%s): %w",
+				blockData.Header.Number, blockData.Hash, err)
+		}
+		imported++
+	}
+
+	logger.Infof("imported %d blocks", imported)
+	return nil
+}
+
+type blockDataWriter interface {
+	write(blockData *types.BlockData) error
+	flush() error
+}
+
+type blockDataReader interface {
+	// read returns the next block data, or io.EOF if there is no more block data.
+	read() (blockData *types.BlockData, err error)
+}
+
+func newBlockDataWriter(w io.Writer, format BlocksFormat) (blockDataWriter, error) {
+	bufferedWriter := bufio.NewWriter(w)
+	switch format {
+	case BinaryBlocksFormat:
+		return &binaryBlockDataWriter{writer: bufferedWriter}, nil
+	case JSONBlocksFormat:
+		return &jsonBlockDataWriter{writer: bufferedWriter, encoder: json.NewEncoder(bufferedWriter)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errInvalidBlocksFormat, format)
+	}
+}
+
+func newBlockDataReader(r io.Reader, format BlocksFormat) (blockDataReader, error) {
+	bufferedReader := bufio.NewReader(r)
+	switch format {
+	case BinaryBlocksFormat:
+		return &binaryBlockDataReader{reader: bufferedReader, decoder: scale.NewDecoder(bufferedReader)}, nil
+	case JSONBlocksFormat:
+		return &jsonBlockDataReader{decoder: json.NewDecoder(bufferedReader)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errInvalidBlocksFormat, format)
+	}
+}
+
+type binaryBlockDataWriter struct {
+	writer *bufio.Writer
+}
+
+func (b *binaryBlockDataWriter) write(blockData *types.BlockData) error {
+	encoded, err := scale.Marshal(*blockData)
+	if err != nil {
+		return fmt.Errorf("scale encoding block data: %w", err)
+	}
+
+	_, err = b.writer.Write(encoded)
+	return err
+}
+
+func (b *binaryBlockDataWriter) flush() error {
+	return b.writer.Flush()
+}
+
+type binaryBlockDataReader struct {
+	reader  *bufio.Reader
+	decoder *scale.Decoder
+}
+
+func (b *binaryBlockDataReader) read() (blockData *types.BlockData, err error) {
+	_, err = b.reader.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	// the header is set so its digest can be decoded.
+	blockData = &types.BlockData{Header: types.NewEmptyHeader()}
+	err = b.decoder.Decode(blockData)
+	if err != nil {
+		return nil, fmt.Errorf("scale decoding block data: %w", err)
+	}
+
+	err = checkBlockData(blockData)
+	if err != nil {
+		return nil, err
+	}
+
+	return blockData, nil
+}
+
+// jsonBlockData is the JSON representation of a block data.
+type jsonBlockData struct {
+	Hash          common.Hash `json:"hash"`
+	Header        string      `json:"header"`
+	Body          []string    `json:"body"`
+	Justification string      `json:"justification,omitempty"`
+}
+
+type jsonBlockDataWriter struct {
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (j *jsonBlockDataWriter) write(blockData *types.BlockData) error {
+	encodedHeader, err := scale.Marshal(*blockData.Header)
+	if err != nil {
+		return fmt.Errorf("scale encoding header: %w", err)
+	}
+
+	data := jsonBlockData{
+		Hash:   blockData.Hash,
+		Header: common.BytesToHex(encodedHeader),
+		Body:   make([]string, len(*blockData.Body)),
+	}
+
+	for i, extrinsic := range *blockData.Body {
+		data.Body[i] = common.BytesToHex(extrinsic)
+	}
+
+	if blockData.Justification != nil {
+		data.Justification = common.BytesToHex(*blockData.Justification)
+	}
+
+	return j.encoder.Encode(data)
+}
+
+func (j *jsonBlockDataWriter) flush() error {
+	return j.writer.Flush()
+}
+
+type jsonBlockDataReader struct {
+	decoder *json.Decoder
+}
+
+func (j *jsonBlockDataReader) read() (blockData *types.BlockData, err error) {
+	var data jsonBlockData
+	err = j.decoder.Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+
+	encodedHeader, err := common.HexToBytes(data.Header)
+	if err != nil {
+		return nil, fmt.Errorf("decoding header hex string: %w", err)
+	}
+
+	header := types.NewEmptyHeader()
+	err = scale.Unmarshal(encodedHeader, header)
+	if err != nil {
+		return nil, fmt.Errorf("scale decoding header: %w", err)
+	}
+
+	body := make(types.Body, len(data.Body))
+	for i, extrinsic := range data.Body {
+		body[i], err = common.HexToBytes(extrinsic)
+		if err != nil {
+			return nil, fmt.Errorf("decoding extrinsic %d hex string: %w", i, err)
+		}
+	}
+
+	blockData = &types.BlockData{
+		Hash:   data.Hash,
+		Header: header,
+		Body:   &body,
+	}
+
+	if data.Justification != "" {
+		justification, err := common.HexToBytes(data.Justification)
+		if err != nil {
+			return nil, fmt.Errorf("decoding justification hex string: %w", err)
+		}
+		blockData.Justification = &justification
+	}
+
+	err = checkBlockData(blockData)
+	if err != nil {
+		return nil, err
+	}
+
+	return blockData, nil
+}
+
+// checkBlockData checks the block data read contains a header and a body,
+// and that its hash is the hash of its header.
+func checkBlockData(blockData *types.BlockData) error {
+	if blockData.Body == nil {
+		return fmt.Errorf("block data %s has no body", blockData.Hash)
+	}
+
+	headerHash := blockData.Header.Hash()
+	if blockData.Hash != headerHash {
+		return fmt.Errorf("%w: block data hash is %s but header hash is %s",
+			errBlockHashMismatch, blockData.Hash, headerHash)
+	}
+
+	return nil
+}
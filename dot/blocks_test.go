@@ -0,0 +1,127 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBlockData(t *testing.T, number uint, withJustification bool) *types.BlockData {
+	t.Helper()
+
+	digest := types.NewDigest()
+	err := digest.Add(types.PreRuntimeDigest{
+		ConsensusEngineID: types.BabeEngineID,
+		Data:              []byte{1, 2, 3},
+	})
+	require.NoError(t, err)
+
+	header := types.NewHeader(common.Hash{1}, common.Hash{2}, common.Hash{3}, number, digest)
+	body := types.Body{{4, 5}, {6}}
+
+	blockData := &types.BlockData{
+		Hash:   header.Hash(),
+		Header: header,
+		Body:   &body,
+	}
+
+	if withJustification {
+		justification := []byte{7, 8}
+		blockData.Justification = &justification
+	}
+
+	return blockData
+}
+
+func Test_blockDataWriter_blockDataReader(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		format BlocksFormat
+	}{
+		"binary": {
+			format: BinaryBlocksFormat,
+		},
+		"json": {
+			format: JSONBlocksFormat,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			blocks := []*types.BlockData{
+				newTestBlockData(t, 1, false),
+				newTestBlockData(t, 2, true),
+			}
+
+			buffer := bytes.NewBuffer(nil)
+			writer, err := newBlockDataWriter(buffer, testCase.format)
+			require.NoError(t, err)
+
+			for _, blockData := range blocks {
+				err = writer.write(blockData)
+				require.NoError(t, err)
+			}
+			err = writer.flush()
+			require.NoError(t, err)
+
+			reader, err := newBlockDataReader(buffer, testCase.format)
+			require.NoError(t, err)
+
+			for _, expected := range blocks {
+				blockData, err := reader.read()
+				require.NoError(t, err)
+				assert.Equal(t, expected.Hash, blockData.Hash)
+				assert.Equal(t, expected.Hash, blockData.Header.Hash())
+				assert.Equal(t, expected.Body, blockData.Body)
+				assert.Equal(t, expected.Justification, blockData.Justification)
+			}
+
+			_, err = reader.read()
+			assert.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func Test_blockDataReader_hashMismatch(t *testing.T) {
+	t.Parallel()
+
+	blockData := newTestBlockData(t, 1, false)
+	blockData.Hash = common.Hash{9}
+
+	buffer := bytes.NewBuffer(nil)
+	writer, err := newBlockDataWriter(buffer, BinaryBlocksFormat)
+	require.NoError(t, err)
+	err = writer.write(blockData)
+	require.NoError(t, err)
+	err = writer.flush()
+	require.NoError(t, err)
+
+	reader, err := newBlockDataReader(buffer, BinaryBlocksFormat)
+	require.NoError(t, err)
+
+	_, err = reader.read()
+	assert.ErrorIs(t, err, errBlockHashMismatch)
+}
+
+func Test_newBlockDataWriter_invalidFormat(t *testing.T) {
+	t.Parallel()
+
+	_, err := newBlockDataWriter(nil, BlocksFormat("xml"))
+	assert.ErrorIs(t, err, errInvalidBlocksFormat)
+	assert.EqualError(t, err, "invalid blocks format: xml")
+
+	_, err = newBlockDataReader(nil, BlocksFormat("xml"))
+	assert.ErrorIs(t, err, errInvalidBlocksFormat)
+}
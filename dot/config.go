@@ -13,6 +13,7 @@
 	"github.com/ChainSafe/gossamer/chain/kusama"
 	"github.com/ChainSafe/gossamer/chain/polkadot"
 	"github.com/ChainSafe/gossamer/dot/state/pruner"
+	"github.com/ChainSafe/gossamer/dot/sync"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/internal/log"
 	"github.com/ChainSafe/gossamer/internal/pprof"
@@ -49,6 +50,9 @@ type GlobalConfig struct {
 	TelemetryURLs  []genesis.TelemetryEndpoint
 	RetainBlocks   uint32
 	Pruning        pruner.Mode
+	// TransactionStoragePeriod is the number of finalised blocks for which
+	// indexed transactions are kept, zero keeping them forever.
+	TransactionStoragePeriod uint32
 }
 
 // LogConfig represents the log levels for individual packages
@@ -88,6 +92,9 @@ type InitConfig struct {
 type AccountConfig struct {
 	Key    string
 	Unlock string // TODO: change to []int (#1849)
+	// Password is used to encrypt the session keys generated by author_rotateKeys.
+	// It is never written to the configuration file.
+	Password string
 }
 
 // NetworkConfig is to marshal/unmarshal toml network config vars
@@ -103,6 +110,8 @@ type NetworkConfig struct {
 	DiscoveryInterval time.Duration
 	PublicIP          string
 	PublicDNS         string
+	// SyncMode is the mode used to sync the chain, defaulting to full sync if left empty.
+	SyncMode sync.Mode
 }
 
 // CoreConfig is to marshal/unmarshal toml core config vars
@@ -113,6 +122,19 @@ type CoreConfig struct {
 	GrandpaAuthority bool
 	WasmInterpreter  string
 	GrandpaInterval  time.Duration
+
+	// OffchainWorkers is true if offchain workers are run on each new best block.
+	OffchainWorkers bool
+	// OffchainWorkerTimeout is the maximum duration of an offchain worker run.
+	OffchainWorkerTimeout time.Duration
+	// OffchainWorkerMaxConcurrency is the maximum number of concurrent offchain worker runs.
+	OffchainWorkerMaxConcurrency uint32
+
+	// RuntimeInstancePoolSize is the number of runtime instances per runtime code
+	// used to run transaction validation, runtime calls and block execution in parallel.
+	// If it is zero, these runtime calls are serialised on a single instance.
+	// It is only supported by the wasmer wasm interpreter.
+	RuntimeInstancePoolSize uint32
 }
 
 // RPCConfig is to marshal/unmarshal toml RPC config vars
@@ -129,6 +151,22 @@ type RPCConfig struct {
 	WSExternal       bool
 	WSUnsafe         bool
 	WSUnsafeExternal bool
+	MaxBatchSize     uint32
+	// WSMaxConnections is the maximum number of websocket connections.
+	WSMaxConnections uint32
+	// WSMaxSubscriptionsPerConnection is the maximum number
+	// of subscriptions of a websocket connection.
+	WSMaxSubscriptionsPerConnection uint32
+	// MaxRequestSize is the maximum size of a request in megabytes.
+	MaxRequestSize uint32
+	// MaxResponseSize is the maximum size of a response in megabytes.
+	MaxResponseSize uint32
+	// RateLimit is the number of requests per second allowed for each IP address.
+	RateLimit uint32
+	// RateLimitBurst is the number of requests allowed in a burst for each IP address.
+	RateLimitBurst uint32
+	// CORS is the list of origins allowed to send requests from a browser.
+	CORS []string
 }
 
 func (r *RPCConfig) isRPCEnabled() bool {
@@ -154,7 +192,15 @@ func (r *RPCConfig) String() string {
 		"ws=" + fmt.Sprint(r.WS) + " " +
 		"wsexternal=" + fmt.Sprint(r.WSExternal) + " " +
 		"wsunsafe=" + fmt.Sprint(r.WSUnsafe) + " " +
-		"wsunsafeexternal=" + fmt.Sprint(r.WSUnsafeExternal)
+		"wsunsafeexternal=" + fmt.Sprint(r.WSUnsafeExternal) + " " +
+		"maxbatchsize=" + fmt.Sprint(r.MaxBatchSize) + " " +
+		"wsmaxconnections=" + fmt.Sprint(r.WSMaxConnections) + " " +
+		"wsmaxsubscriptionsperconnection=" + fmt.Sprint(r.WSMaxSubscriptionsPerConnection) + " " +
+		"maxrequestsize=" + fmt.Sprint(r.MaxRequestSize) + " " +
+		"maxresponsesize=" + fmt.Sprint(r.MaxResponseSize) + " " +
+		"ratelimit=" + fmt.Sprint(r.RateLimit) + " " +
+		"ratelimitburst=" + fmt.Sprint(r.RateLimitBurst) + " " +
+		"cors=" + strings.Join(r.CORS, ",")
 }
 
 // StateConfig is the config for the State service
@@ -233,10 +279,11 @@ func GssmrConfig() *Config {
 			MaxPeers:          gssmr.DefaultMaxPeers,
 		},
 		RPC: RPCConfig{
-			Port:    gssmr.DefaultRPCHTTPPort,
-			Host:    gssmr.DefaultRPCHTTPHost,
-			Modules: gssmr.DefaultRPCModules,
-			WSPort:  gssmr.DefaultRPCWSPort,
+			Port:         gssmr.DefaultRPCHTTPPort,
+			Host:         gssmr.DefaultRPCHTTPHost,
+			Modules:      gssmr.DefaultRPCModules,
+			WSPort:       gssmr.DefaultRPCWSPort,
+			MaxBatchSize: gssmr.DefaultRPCMaxBatchSize,
 		},
 		Pprof: PprofConfig{
 			Settings: pprof.Settings{
@@ -290,10 +337,11 @@ func KusamaConfig() *Config {
 			NoMDNS:      kusama.DefaultNoMDNS,
 		},
 		RPC: RPCConfig{
-			Port:    kusama.DefaultRPCHTTPPort,
-			Host:    kusama.DefaultRPCHTTPHost,
-			Modules: kusama.DefaultRPCModules,
-			WSPort:  kusama.DefaultRPCWSPort,
+			Port:         kusama.DefaultRPCHTTPPort,
+			Host:         kusama.DefaultRPCHTTPHost,
+			Modules:      kusama.DefaultRPCModules,
+			WSPort:       kusama.DefaultRPCWSPort,
+			MaxBatchSize: kusama.DefaultRPCMaxBatchSize,
 		},
 		Pprof: PprofConfig{
 			Settings: pprof.Settings{
@@ -347,10 +395,11 @@ func PolkadotConfig() *Config {
 			NoMDNS:      polkadot.DefaultNoMDNS,
 		},
 		RPC: RPCConfig{
-			Port:    polkadot.DefaultRPCHTTPPort,
-			Host:    polkadot.DefaultRPCHTTPHost,
-			Modules: polkadot.DefaultRPCModules,
-			WSPort:  polkadot.DefaultRPCWSPort,
+			Port:         polkadot.DefaultRPCHTTPPort,
+			Host:         polkadot.DefaultRPCHTTPHost,
+			Modules:      polkadot.DefaultRPCModules,
+			WSPort:       polkadot.DefaultRPCWSPort,
+			MaxBatchSize: polkadot.DefaultRPCMaxBatchSize,
 		},
 		Pprof: PprofConfig{
 			Settings: pprof.Settings{
@@ -407,12 +456,13 @@ func DevConfig() *Config {
 			NoMDNS:      dev.DefaultNoMDNS,
 		},
 		RPC: RPCConfig{
-			Port:    dev.DefaultRPCHTTPPort,
-			Host:    dev.DefaultRPCHTTPHost,
-			Modules: dev.DefaultRPCModules,
-			WSPort:  dev.DefaultRPCWSPort,
-			Enabled: dev.DefaultRPCEnabled,
-			WS:      dev.DefaultWSEnabled,
+			Port:         dev.DefaultRPCHTTPPort,
+			Host:         dev.DefaultRPCHTTPHost,
+			Modules:      dev.DefaultRPCModules,
+			WSPort:       dev.DefaultRPCWSPort,
+			MaxBatchSize: dev.DefaultRPCMaxBatchSize,
+			Enabled:      dev.DefaultRPCEnabled,
+			WS:           dev.DefaultWSEnabled,
 		},
 		Pprof: PprofConfig{
 			Settings: pprof.Settings{
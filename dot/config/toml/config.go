@@ -25,6 +25,8 @@ type GlobalConfig struct {
 	MetricsAddress string `toml:"metrics-address,omitempty"`
 	RetainBlocks   uint32 `toml:"retain-blocks,omitempty"`
 	Pruning        string `toml:"pruning,omitempty"`
+
+	TransactionStoragePeriod uint32 `toml:"transaction-storage-period,omitempty"`
 }
 
 // LogConfig represents the log levels for individual packages
@@ -64,6 +66,7 @@ type NetworkConfig struct {
 	DiscoveryInterval int      `toml:"discovery-interval,omitempty"`
 	PublicIP          string   `toml:"public-ip,omitempty"`
 	PublicDNS         string   `toml:"public-dns,omitempty"`
+	SyncMode          string   `toml:"sync-mode,omitempty"`
 }
 
 // CoreConfig is to marshal/unmarshal toml core config vars
@@ -76,6 +79,12 @@ type CoreConfig struct {
 	WasmInterpreter  string `toml:"wasm-interpreter,omitempty"`
 	GrandpaInterval  uint32 `toml:"grandpa-interval,omitempty"`
 	BABELead         bool   `toml:"babe-lead,omitempty"`
+
+	OffchainWorkers              bool   `toml:"offchain-workers,omitempty"`
+	OffchainWorkerTimeout        uint32 `toml:"offchain-worker-timeout,omitempty"`
+	OffchainWorkerMaxConcurrency uint32 `toml:"offchain-worker-max-concurrency,omitempty"`
+
+	RuntimeInstancePoolSize uint32 `toml:"runtime-instance-pool-size,omitempty"`
 }
 
 // StateConfig contains the configuration for the state.
@@ -97,6 +106,15 @@ type RPCConfig struct {
 	WSExternal       bool     `toml:"ws-external,omitempty"`
 	WSUnsafe         bool     `toml:"ws-unsafe,omitempty"`
 	WSUnsafeExternal bool     `toml:"ws-unsafe-external,omitempty"`
+	MaxBatchSize     uint32   `toml:"max-batch-size,omitempty"`
+
+	WSMaxConnections                uint32   `toml:"ws-max-connections,omitempty"`
+	WSMaxSubscriptionsPerConnection uint32   `toml:"ws-max-subscriptions-per-connection,omitempty"`
+	MaxRequestSize                  uint32   `toml:"max-request-size,omitempty"`
+	MaxResponseSize                 uint32   `toml:"max-response-size,omitempty"`
+	RateLimit                       uint32   `toml:"rate-limit,omitempty"`
+	RateLimitBurst                  uint32   `toml:"rate-limit-burst,omitempty"`
+	CORS                            []string `toml:"cors,omitempty"`
 }
 
 // PprofConfig contains the configuration for Pprof.
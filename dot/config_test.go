@@ -70,8 +70,9 @@ func TestConfig(t *testing.T) {
 					Host:           "localhost",
 					Modules: []string{"system", "author", "chain", "state", "rpc", "grandpa", "offchain",
 						"childstate", "syncstate", "payment"},
-					WSPort: 8546,
-					WS:     true,
+					WSPort:       8546,
+					MaxBatchSize: 100,
+					WS:           true,
 				},
 				Pprof: PprofConfig{
 					Settings: pprof.Settings{
@@ -127,6 +128,7 @@ func TestConfig(t *testing.T) {
 					Modules: []string{"system", "author", "chain", "state", "rpc", "grandpa", "offchain",
 						"childstate", "syncstate", "payment"},
 					WSPort:           8546,
+					MaxBatchSize:     100,
 					WS:               false,
 					WSExternal:       false,
 					WSUnsafe:         false,
@@ -191,7 +193,8 @@ func TestConfig(t *testing.T) {
 					Host: "localhost",
 					Modules: []string{"system", "author", "chain", "state", "rpc", "grandpa", "offchain",
 						"childstate", "syncstate", "payment"},
-					WSPort: 8546,
+					WSPort:       8546,
+					MaxBatchSize: 100,
 				},
 				Pprof: PprofConfig{
 					Settings: pprof.Settings{
@@ -237,7 +240,8 @@ func TestConfig(t *testing.T) {
 					Host: "localhost",
 					Modules: []string{"system", "author", "chain", "state", "rpc", "grandpa", "offchain",
 						"childstate", "syncstate", "payment"},
-					WSPort: 8546,
+					WSPort:       8546,
+					MaxBatchSize: 100,
 				},
 				Pprof: PprofConfig{
 					Settings: pprof.Settings{
@@ -406,7 +410,8 @@ func TestRPCConfig_String(t *testing.T) {
 			name:      "default base case",
 			rpcConfig: RPCConfig{},
 			want: "enabled=false external=false unsafe=false unsafeexternal=false port=0 host= modules= wsport=0 ws" +
-				"=false wsexternal=false wsunsafe=false wsunsafeexternal=false",
+				"=false wsexternal=false wsunsafe=false wsunsafeexternal=false maxbatchsize=0 wsmaxconnections=0" +
+				" wsmaxsubscriptionsperconnection=0 maxrequestsize=0 maxresponsesize=0 ratelimit=0 ratelimitburst=0 cors=",
 		},
 		{
 			name: "fields_changed",
@@ -423,9 +428,20 @@ func TestRPCConfig_String(t *testing.T) {
 				WSExternal:       true,
 				WSUnsafe:         true,
 				WSUnsafeExternal: true,
+				MaxBatchSize:     10,
+
+				WSMaxConnections:                100,
+				WSMaxSubscriptionsPerConnection: 1024,
+				MaxRequestSize:                  15,
+				MaxResponseSize:                 16,
+				RateLimit:                       50,
+				RateLimitBurst:                  100,
+				CORS:                            []string{"http://localhost", "https://polkadot.js.org"},
 			},
 			want: "enabled=true external=true unsafe=true unsafeexternal=true port=1234 host=5678 modules= wsport" +
-				"=2345 ws=true wsexternal=true wsunsafe=true wsunsafeexternal=true",
+				"=2345 ws=true wsexternal=true wsunsafe=true wsunsafeexternal=true maxbatchsize=10" +
+				" wsmaxconnections=100 wsmaxsubscriptionsperconnection=1024 maxrequestsize=15 maxresponsesize=16" +
+				" ratelimit=50 ratelimitburst=100 cors=http://localhost,https://polkadot.js.org",
 		},
 	}
 	for _, tt := range tests {
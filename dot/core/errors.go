@@ -15,5 +15,13 @@
 	// ErrEmptyRuntimeCode is returned when the storage :code is empty
 	ErrEmptyRuntimeCode = errors.New("new :code is empty")
 
+	// ErrTransactionBanned is returned when a submitted extrinsic is temporarily banned
+	ErrTransactionBanned = errors.New("transaction is temporarily banned")
+
 	errInvalidTransactionQueueVersion = errors.New("invalid transaction queue version")
+	errOffchainWorkerTimeout          = errors.New("offchain worker timed out")
+	errRuntimeNotAcquirer             = errors.New("runtime cannot provide instances")
+	errInvalidSessionKeys             = errors.New("invalid session keys")
+	errKeypairNotExportable           = errors.New("keypair private key cannot be exported")
+	errKeyNotFound                    = errors.New("key not found in keystore")
 )
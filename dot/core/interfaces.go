@@ -51,9 +51,15 @@ type RuntimeInstance interface {
 		equivocationProof types.BabeEquivocationProof,
 		keyOwnershipProof types.OpaqueKeyOwnershipProof,
 	) error
+	GrandpaGenerateKeyOwnershipProof(setID uint64, authorityID [32]byte) (
+		types.OpaqueKeyOwnershipProof, error)
+	GrandpaSubmitReportEquivocationUnsignedExtrinsic(
+		equivocationProof types.GrandpaEquivocationProof,
+		keyOwnershipProof types.OpaqueKeyOwnershipProof,
+	) error
 	RandomSeed()
-	OffchainWorker()
-	GenerateSessionKeys()
+	OffchainWorker(header *types.Header) error
+	GenerateSessionKeys(seed *[]byte) (publicKeys []byte, err error)
 }
 
 // BlockState interface for block state methods
@@ -68,6 +74,7 @@ type BlockState interface {
 	GetRuntime(blockHash common.Hash) (instance state.Runtime, err error)
 	StoreRuntime(blockHash common.Hash, runtime state.Runtime)
 	LowestCommonAncestor(a, b common.Hash) (common.Hash, error)
+	StoreIndexedTransactions(block *types.Block, operations []rtstorage.TransactionIndexOperation) error
 }
 
 // StorageState interface for storage state methods
@@ -84,9 +91,10 @@ type TransactionState interface {
 	Push(vt *transaction.ValidTransaction) (common.Hash, error)
 	AddToPool(vt *transaction.ValidTransaction) common.Hash
 	RemoveExtrinsic(ext types.Extrinsic)
-	RemoveExtrinsicFromPool(ext types.Extrinsic)
 	PendingInPool() []*transaction.ValidTransaction
+	RemoveExpired(bestBlockNumber uint) (removed []common.Hash)
 	Exists(ext types.Extrinsic) bool
+	IsBanned(hash common.Hash) bool
 }
 
 // Network is the interface for the network service
@@ -113,3 +121,8 @@ type KeyPair interface {
 	Sign(msg []byte) ([]byte, error)
 	Public() crypto.PublicKey
 }
+
+// OffchainWorkerInstance is the runtime instance used to run offchain workers.
+type OffchainWorkerInstance interface {
+	OffchainWorker(header *types.Header) error
+}
@@ -25,15 +25,21 @@ func (s *Service) validateTransaction(head *types.Header, rt RuntimeInstance,
 		return nil, fmt.Errorf("cannot get trie state from storage for root %s: %w", head.StateRoot, err)
 	}
 
-	rt.SetContextStorage(ts)
+	instance, release, err := runtime.AcquireInstance(rt)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	instance.SetContextStorage(ts)
 
 	// validate each transaction
-	externalExt, err := s.buildExternalTransaction(rt, tx)
+	externalExt, err := s.buildExternalTransaction(instance, tx)
 	if err != nil {
 		return nil, fmt.Errorf("building external transaction: %w", err)
 	}
 
-	validity, err = rt.ValidateTransaction(externalExt)
+	validity, err = instance.ValidateTransaction(externalExt)
 	if err != nil {
 		logger.Debugf("failed to validate transaction: %s", err)
 		return nil, err
@@ -76,6 +82,12 @@ func (s *Service) HandleTransactionMessage(peerID peer.ID, msg *network.Transact
 
 	allTxnsAreValid := true
 	for _, tx := range txs {
+		txHash := tx.Hash()
+		if s.transactionState.IsBanned(txHash) {
+			logger.Debugf("ignoring banned transaction %s", txHash)
+			continue
+		}
+
 		validity, err := s.validateTransaction(head, rt, tx)
 		if err != nil {
 			allTxnsAreValid = false
@@ -57,8 +57,11 @@ type mockStorageState struct {
 }
 
 type mockTxnState struct {
-	input *transaction.ValidTransaction
-	hash  common.Hash
+	// isBanned are the extrinsic hashes checked against the ban list.
+	isBanned []common.Hash
+	banned   bool
+	input    *transaction.ValidTransaction
+	hash     common.Hash
 }
 
 type mockSetContextStorage struct {
@@ -225,6 +228,9 @@ type args struct {
 				input: &common.Hash{},
 				err:   errDummyErr,
 			},
+			mockTxnState: &mockTxnState{
+				isBanned: []common.Hash{types.Extrinsic{1, 2, 3}.Hash()},
+			},
 			args: args{
 				peerID: peer.ID("jimbo"),
 				msg: &network.TransactionMessage{
@@ -260,6 +266,9 @@ type args struct {
 				input:     &common.Hash{},
 				trieState: &storage.TrieState{},
 			},
+			mockTxnState: &mockTxnState{
+				isBanned: []common.Hash{types.Extrinsic{1, 2, 3}.Hash()},
+			},
 			mockRuntime: &mockRuntime{
 				runtime:           runtimeMock2,
 				setContextStorage: &mockSetContextStorage{trieState: &storage.TrieState{}},
@@ -305,6 +314,7 @@ type args struct {
 				trieState: &storage.TrieState{},
 			},
 			mockTxnState: &mockTxnState{
+				isBanned: []common.Hash{types.Extrinsic{1, 2, 3}.Hash()},
 				input: transaction.NewValidTransaction(
 					types.Extrinsic{1, 2, 3},
 					&transaction.Validity{
@@ -332,6 +342,37 @@ type args struct {
 			},
 			exp: true,
 		},
+		{
+			name: "banned_transaction",
+			mockNetwork: &mockNetwork{
+				IsSynced: true,
+				ReportPeer: &mockReportPeer{
+					change: peerset.ReputationChange{
+						Value:  peerset.GoodTransactionValue,
+						Reason: peerset.GoodTransactionReason,
+					},
+					id: peer.ID("jimbo"),
+				},
+			},
+			mockBlockState: &mockBlockState{
+				bestHeader: &mockBestHeader{
+					header: testEmptyHeader,
+				},
+				getRuntime: &mockGetRuntime{
+					runtime: runtimeMock,
+				},
+			},
+			mockTxnState: &mockTxnState{
+				isBanned: []common.Hash{types.Extrinsic{1, 2, 3}.Hash()},
+				banned:   true,
+			},
+			args: args{
+				peerID: peer.ID("jimbo"),
+				msg: &network.TransactionMessage{
+					Extrinsics: []types.Extrinsic{{1, 2, 3}},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -372,7 +413,12 @@ type args struct {
 			}
 			if tt.mockTxnState != nil {
 				txnState := NewMockTransactionState(ctrl)
-				txnState.EXPECT().AddToPool(tt.mockTxnState.input).Return(tt.mockTxnState.hash)
+				for _, hash := range tt.mockTxnState.isBanned {
+					txnState.EXPECT().IsBanned(hash).Return(tt.mockTxnState.banned)
+				}
+				if tt.mockTxnState.input != nil {
+					txnState.EXPECT().AddToPool(tt.mockTxnState.input).Return(tt.mockTxnState.hash)
+				}
 				s.transactionState = txnState
 			}
 			if tt.mockRuntime != nil {
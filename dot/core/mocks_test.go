@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/ChainSafe/gossamer/dot/core (interfaces: BlockState,StorageState,TransactionState,Network,CodeSubstitutedState,RuntimeInstance,Telemetry)
+// Source: github.com/ChainSafe/gossamer/dot/core (interfaces: BlockState,StorageState,TransactionState,Network,CodeSubstitutedState,RuntimeInstance,Telemetry,OffchainWorkerInstance)
 
 // Package core is a generated GoMock package.
 package core
@@ -176,6 +176,20 @@ func (mr *MockBlockStateMockRecorder) RangeInMemory(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RangeInMemory", reflect.TypeOf((*MockBlockState)(nil).RangeInMemory), arg0, arg1)
 }
 
+// StoreIndexedTransactions mocks base method.
+func (m *MockBlockState) StoreIndexedTransactions(arg0 *types.Block, arg1 []storage.TransactionIndexOperation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreIndexedTransactions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreIndexedTransactions indicates an expected call of StoreIndexedTransactions.
+func (mr *MockBlockStateMockRecorder) StoreIndexedTransactions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreIndexedTransactions", reflect.TypeOf((*MockBlockState)(nil).StoreIndexedTransactions), arg0, arg1)
+}
+
 // StoreRuntime mocks base method.
 func (m *MockBlockState) StoreRuntime(arg0 common.Hash, arg1 state.Runtime) {
 	m.ctrl.T.Helper()
@@ -345,6 +359,20 @@ func (mr *MockTransactionStateMockRecorder) Exists(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockTransactionState)(nil).Exists), arg0)
 }
 
+// IsBanned mocks base method.
+func (m *MockTransactionState) IsBanned(arg0 common.Hash) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBanned", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsBanned indicates an expected call of IsBanned.
+func (mr *MockTransactionStateMockRecorder) IsBanned(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBanned", reflect.TypeOf((*MockTransactionState)(nil).IsBanned), arg0)
+}
+
 // PendingInPool mocks base method.
 func (m *MockTransactionState) PendingInPool() []*transaction.ValidTransaction {
 	m.ctrl.T.Helper()
@@ -374,28 +402,30 @@ func (mr *MockTransactionStateMockRecorder) Push(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockTransactionState)(nil).Push), arg0)
 }
 
-// RemoveExtrinsic mocks base method.
-func (m *MockTransactionState) RemoveExtrinsic(arg0 types.Extrinsic) {
+// RemoveExpired mocks base method.
+func (m *MockTransactionState) RemoveExpired(arg0 uint) []common.Hash {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "RemoveExtrinsic", arg0)
+	ret := m.ctrl.Call(m, "RemoveExpired", arg0)
+	ret0, _ := ret[0].([]common.Hash)
+	return ret0
 }
 
-// RemoveExtrinsic indicates an expected call of RemoveExtrinsic.
-func (mr *MockTransactionStateMockRecorder) RemoveExtrinsic(arg0 interface{}) *gomock.Call {
+// RemoveExpired indicates an expected call of RemoveExpired.
+func (mr *MockTransactionStateMockRecorder) RemoveExpired(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveExtrinsic", reflect.TypeOf((*MockTransactionState)(nil).RemoveExtrinsic), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveExpired", reflect.TypeOf((*MockTransactionState)(nil).RemoveExpired), arg0)
 }
 
-// RemoveExtrinsicFromPool mocks base method.
-func (m *MockTransactionState) RemoveExtrinsicFromPool(arg0 types.Extrinsic) {
+// RemoveExtrinsic mocks base method.
+func (m *MockTransactionState) RemoveExtrinsic(arg0 types.Extrinsic) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "RemoveExtrinsicFromPool", arg0)
+	m.ctrl.Call(m, "RemoveExtrinsic", arg0)
 }
 
-// RemoveExtrinsicFromPool indicates an expected call of RemoveExtrinsicFromPool.
-func (mr *MockTransactionStateMockRecorder) RemoveExtrinsicFromPool(arg0 interface{}) *gomock.Call {
+// RemoveExtrinsic indicates an expected call of RemoveExtrinsic.
+func (mr *MockTransactionStateMockRecorder) RemoveExtrinsic(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveExtrinsicFromPool", reflect.TypeOf((*MockTransactionState)(nil).RemoveExtrinsicFromPool), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveExtrinsic", reflect.TypeOf((*MockTransactionState)(nil).RemoveExtrinsic), arg0)
 }
 
 // MockNetwork is a mock of Network interface.
@@ -651,15 +681,18 @@ func (mr *MockRuntimeInstanceMockRecorder) FinalizeBlock() *gomock.Call {
 }
 
 // GenerateSessionKeys mocks base method.
-func (m *MockRuntimeInstance) GenerateSessionKeys() {
+func (m *MockRuntimeInstance) GenerateSessionKeys(arg0 *[]byte) ([]byte, error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "GenerateSessionKeys")
+	ret := m.ctrl.Call(m, "GenerateSessionKeys", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // GenerateSessionKeys indicates an expected call of GenerateSessionKeys.
-func (mr *MockRuntimeInstanceMockRecorder) GenerateSessionKeys() *gomock.Call {
+func (mr *MockRuntimeInstanceMockRecorder) GenerateSessionKeys(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateSessionKeys", reflect.TypeOf((*MockRuntimeInstance)(nil).GenerateSessionKeys))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateSessionKeys", reflect.TypeOf((*MockRuntimeInstance)(nil).GenerateSessionKeys), arg0)
 }
 
 // GetCodeHash mocks base method.
@@ -691,6 +724,35 @@ func (mr *MockRuntimeInstanceMockRecorder) GrandpaAuthorities() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrandpaAuthorities", reflect.TypeOf((*MockRuntimeInstance)(nil).GrandpaAuthorities))
 }
 
+// GrandpaGenerateKeyOwnershipProof mocks base method.
+func (m *MockRuntimeInstance) GrandpaGenerateKeyOwnershipProof(arg0 uint64, arg1 [32]byte) (types.OpaqueKeyOwnershipProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrandpaGenerateKeyOwnershipProof", arg0, arg1)
+	ret0, _ := ret[0].(types.OpaqueKeyOwnershipProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrandpaGenerateKeyOwnershipProof indicates an expected call of GrandpaGenerateKeyOwnershipProof.
+func (mr *MockRuntimeInstanceMockRecorder) GrandpaGenerateKeyOwnershipProof(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrandpaGenerateKeyOwnershipProof", reflect.TypeOf((*MockRuntimeInstance)(nil).GrandpaGenerateKeyOwnershipProof), arg0, arg1)
+}
+
+// GrandpaSubmitReportEquivocationUnsignedExtrinsic mocks base method.
+func (m *MockRuntimeInstance) GrandpaSubmitReportEquivocationUnsignedExtrinsic(arg0 types.GrandpaEquivocationProof, arg1 types.OpaqueKeyOwnershipProof) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrandpaSubmitReportEquivocationUnsignedExtrinsic", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrandpaSubmitReportEquivocationUnsignedExtrinsic indicates an expected call of GrandpaSubmitReportEquivocationUnsignedExtrinsic.
+func (mr *MockRuntimeInstanceMockRecorder) GrandpaSubmitReportEquivocationUnsignedExtrinsic(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrandpaSubmitReportEquivocationUnsignedExtrinsic", reflect.TypeOf((*MockRuntimeInstance)(nil).GrandpaSubmitReportEquivocationUnsignedExtrinsic), arg0, arg1)
+}
+
 // InherentExtrinsics mocks base method.
 func (m *MockRuntimeInstance) InherentExtrinsics(arg0 []byte) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -778,15 +840,17 @@ func (mr *MockRuntimeInstanceMockRecorder) NodeStorage() *gomock.Call {
 }
 
 // OffchainWorker mocks base method.
-func (m *MockRuntimeInstance) OffchainWorker() {
+func (m *MockRuntimeInstance) OffchainWorker(arg0 *types.Header) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "OffchainWorker")
+	ret := m.ctrl.Call(m, "OffchainWorker", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // OffchainWorker indicates an expected call of OffchainWorker.
-func (mr *MockRuntimeInstanceMockRecorder) OffchainWorker() *gomock.Call {
+func (mr *MockRuntimeInstanceMockRecorder) OffchainWorker(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OffchainWorker", reflect.TypeOf((*MockRuntimeInstance)(nil).OffchainWorker))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OffchainWorker", reflect.TypeOf((*MockRuntimeInstance)(nil).OffchainWorker), arg0)
 }
 
 // PaymentQueryInfo mocks base method.
@@ -931,3 +995,40 @@ func (mr *MockTelemetryMockRecorder) SendMessage(arg0 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockTelemetry)(nil).SendMessage), arg0)
 }
+
+// MockOffchainWorkerInstance is a mock of OffchainWorkerInstance interface.
+type MockOffchainWorkerInstance struct {
+	ctrl     *gomock.Controller
+	recorder *MockOffchainWorkerInstanceMockRecorder
+}
+
+// MockOffchainWorkerInstanceMockRecorder is the mock recorder for MockOffchainWorkerInstance.
+type MockOffchainWorkerInstanceMockRecorder struct {
+	mock *MockOffchainWorkerInstance
+}
+
+// NewMockOffchainWorkerInstance creates a new mock instance.
+func NewMockOffchainWorkerInstance(ctrl *gomock.Controller) *MockOffchainWorkerInstance {
+	mock := &MockOffchainWorkerInstance{ctrl: ctrl}
+	mock.recorder = &MockOffchainWorkerInstanceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOffchainWorkerInstance) EXPECT() *MockOffchainWorkerInstanceMockRecorder {
+	return m.recorder
+}
+
+// OffchainWorker mocks base method.
+func (m *MockOffchainWorkerInstance) OffchainWorker(arg0 *types.Header) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OffchainWorker", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// OffchainWorker indicates an expected call of OffchainWorker.
+func (mr *MockOffchainWorkerInstanceMockRecorder) OffchainWorker(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OffchainWorker", reflect.TypeOf((*MockOffchainWorkerInstance)(nil).OffchainWorker), arg0)
+}
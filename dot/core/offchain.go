@@ -0,0 +1,156 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+)
+
+const (
+	// defaultOffchainWorkerTimeout is the default maximum duration
+	// the node waits for an offchain worker run to complete.
+	defaultOffchainWorkerTimeout = 10 * time.Second
+	// defaultOffchainWorkerMaxConcurrency is the default maximum number
+	// of offchain worker runs allowed at the same time.
+	defaultOffchainWorkerMaxConcurrency = 1
+)
+
+// OffchainWorkerConfig is the configuration of the offchain workers
+// run on each new best block.
+type OffchainWorkerConfig struct {
+	// Enabled is true if offchain workers should be run.
+	Enabled bool
+	// Timeout is the maximum duration to wait for an offchain worker run.
+	// It defaults to 10 seconds if left to zero.
+	Timeout time.Duration
+	// MaxConcurrency is the maximum number of offchain worker runs
+	// allowed at the same time. It defaults to 1 if left to zero.
+	MaxConcurrency uint32
+}
+
+func (c *OffchainWorkerConfig) setDefaults() {
+	if c.Timeout == 0 {
+		c.Timeout = defaultOffchainWorkerTimeout
+	}
+
+	if c.MaxConcurrency == 0 {
+		c.MaxConcurrency = defaultOffchainWorkerMaxConcurrency
+	}
+}
+
+// newOffchainWorkerInstance acquires a runtime instance from the block runtime given
+// to run offchain workers, with the storage and transaction state given set in its
+// context. The instance is either taken from the instance pool of the block runtime,
+// or instantiated from its already compiled module, so the runtime code is not
+// compiled again. The release function returned must be called once done with it.
+func newOffchainWorkerInstance(blockRuntime RuntimeInstance, storage *rtstorage.TrieState,
+	transactionState TransactionState) (instance OffchainWorkerInstance, release func(), err error) {
+	acquirer, ok := blockRuntime.(runtime.InstanceAcquirer)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %T", errRuntimeNotAcquirer, blockRuntime)
+	}
+
+	acquired, release, err := acquirer.Acquire()
+	if err != nil {
+		return nil, nil, fmt.Errorf("acquiring runtime instance: %w", err)
+	}
+
+	acquired.SetContextStorage(storage)
+
+	// The acquired instance is discarded once released, so the transaction
+	// state can be set in its context without affecting the block runtime.
+	contextGetter, ok := acquired.(interface{ GetContext() *runtime.Context })
+	if ok && transactionState != nil {
+		contextGetter.GetContext().Transaction = transactionState
+	}
+
+	return acquired, release, nil
+}
+
+// runOffchainWorkers runs the offchain workers for the given best block header
+// in the background. It is a no-op if offchain workers are disabled, and skips
+// the block if the maximum number of concurrent offchain worker runs is reached.
+func (s *Service) runOffchainWorkers(header *types.Header) {
+	if !s.offchainWorkerConfig.Enabled {
+		return
+	}
+
+	blockHash := header.Hash()
+
+	select {
+	case s.offchainWorkerSlots <- struct{}{}:
+	default:
+		logger.Debugf("skipping offchain workers for block %s: %d offchain workers are already running",
+			blockHash, s.offchainWorkerConfig.MaxConcurrency)
+		return
+	}
+
+	go func() {
+		releaseSlot := func() { <-s.offchainWorkerSlots }
+		err := s.runOffchainWorker(header, blockHash, releaseSlot)
+		if err != nil {
+			logger.Errorf("running offchain workers for block %s: %s", blockHash, err)
+		}
+	}()
+}
+
+// runOffchainWorker acquires a runtime instance on top of the state of the
+// given block, and calls the runtime offchain worker API with the block header.
+// It returns an error if the call does not complete within the configured timeout.
+// The releaseSlot function given is called once the runtime call returns, which
+// can be after this function returns on timeout, or when returning if the runtime
+// call is never made.
+func (s *Service) runOffchainWorker(header *types.Header, blockHash common.Hash,
+	releaseSlot func()) (err error) {
+	runtimeCalled := false
+	defer func() {
+		if !runtimeCalled {
+			releaseSlot()
+		}
+	}()
+
+	trieState, err := s.storageState.TrieState(&header.StateRoot)
+	if err != nil {
+		return fmt.Errorf("getting trie state: %w", err)
+	}
+
+	rt, err := s.blockState.GetRuntime(blockHash)
+	if err != nil {
+		return fmt.Errorf("getting runtime: %w", err)
+	}
+
+	instance, release, err := s.newOffchainWorkerInstance(rt, trieState, s.transactionState)
+	if err != nil {
+		return fmt.Errorf("creating runtime instance: %w", err)
+	}
+
+	// The runtime call cannot be interrupted, so on timeout it keeps running in
+	// the background until it returns, and the instance is then released and
+	// its slot released. The done channel is buffered so this goroutine never blocks.
+	runtimeCalled = true
+	done := make(chan error, 1)
+	go func() {
+		defer releaseSlot()
+		defer release()
+		done <- instance.OffchainWorker(header)
+	}()
+
+	timer := time.NewTimer(s.offchainWorkerConfig.Timeout)
+	defer timer.Stop()
+
+	select {
+	case err = <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("%w: after %s", errOffchainWorkerTimeout, s.offchainWorkerConfig.Timeout)
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
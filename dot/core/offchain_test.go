@@ -0,0 +1,298 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_OffchainWorkerConfig_setDefaults(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		config         OffchainWorkerConfig
+		expectedConfig OffchainWorkerConfig
+	}{
+		"empty_config": {
+			expectedConfig: OffchainWorkerConfig{
+				Timeout:        defaultOffchainWorkerTimeout,
+				MaxConcurrency: defaultOffchainWorkerMaxConcurrency,
+			},
+		},
+		"set_config": {
+			config: OffchainWorkerConfig{
+				Enabled:        true,
+				Timeout:        time.Second,
+				MaxConcurrency: 3,
+			},
+			expectedConfig: OffchainWorkerConfig{
+				Enabled:        true,
+				Timeout:        time.Second,
+				MaxConcurrency: 3,
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := testCase.config
+			config.setDefaults()
+
+			assert.Equal(t, testCase.expectedConfig, config)
+		})
+	}
+}
+
+func Test_newOffchainWorkerInstance(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	blockRuntime := NewMockRuntimeInstance(ctrl)
+	storage := rtstorage.NewTrieState(trie.NewEmptyTrie())
+
+	instance, release, err := newOffchainWorkerInstance(blockRuntime, storage, nil)
+
+	assert.ErrorIs(t, err, errRuntimeNotAcquirer)
+	assert.EqualError(t, err, "runtime cannot provide instances: *core.MockRuntimeInstance")
+	assert.Nil(t, instance)
+	assert.Nil(t, release)
+}
+
+func Test_Service_runOffchainWorkers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		service := &Service{
+			offchainWorkerSlots: make(chan struct{}, 1),
+		}
+
+		service.runOffchainWorkers(&types.Header{})
+
+		assert.Empty(t, service.offchainWorkerSlots)
+	})
+
+	t.Run("max_concurrency_reached", func(t *testing.T) {
+		t.Parallel()
+
+		service := &Service{
+			offchainWorkerConfig: OffchainWorkerConfig{
+				Enabled:        true,
+				MaxConcurrency: 1,
+			},
+			offchainWorkerSlots: make(chan struct{}, 1),
+		}
+		service.offchainWorkerSlots <- struct{}{}
+
+		// the storage state is nil, so this would panic if an offchain worker was run.
+		service.runOffchainWorkers(types.NewEmptyHeader())
+
+		assert.Len(t, service.offchainWorkerSlots, 1)
+	})
+}
+
+func Test_Service_runOffchainWorker(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+	header := &types.Header{
+		StateRoot: common.Hash{1},
+		Number:    2,
+		Digest:    types.NewDigest(),
+	}
+	blockHash := header.Hash()
+
+	newTrieState := func() *rtstorage.TrieState {
+		return rtstorage.NewTrieState(trie.NewEmptyTrie())
+	}
+
+	testCases := map[string]struct {
+		serviceBuilder func(t *testing.T, ctrl *gomock.Controller) *Service
+		errWrapped     error
+		errMessage     string
+	}{
+		"trie_state_error": {
+			serviceBuilder: func(_ *testing.T, ctrl *gomock.Controller) *Service {
+				storageState := NewMockStorageState(ctrl)
+				storageState.EXPECT().TrieState(&header.StateRoot).Return(nil, errTest)
+				return &Service{storageState: storageState}
+			},
+			errWrapped: errTest,
+			errMessage: "getting trie state: test error",
+		},
+		"get_runtime_error": {
+			serviceBuilder: func(_ *testing.T, ctrl *gomock.Controller) *Service {
+				storageState := NewMockStorageState(ctrl)
+				storageState.EXPECT().TrieState(&header.StateRoot).Return(newTrieState(), nil)
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().GetRuntime(blockHash).Return(nil, errTest)
+				return &Service{
+					storageState: storageState,
+					blockState:   blockState,
+				}
+			},
+			errWrapped: errTest,
+			errMessage: "getting runtime: test error",
+		},
+		"new_instance_error": {
+			serviceBuilder: func(_ *testing.T, ctrl *gomock.Controller) *Service {
+				storageState := NewMockStorageState(ctrl)
+				storageState.EXPECT().TrieState(&header.StateRoot).Return(newTrieState(), nil)
+				runtimeInstance := NewMockRuntimeInstance(ctrl)
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().GetRuntime(blockHash).Return(runtimeInstance, nil)
+				return &Service{
+					storageState: storageState,
+					blockState:   blockState,
+					newOffchainWorkerInstance: func(RuntimeInstance, *rtstorage.TrieState,
+						TransactionState) (OffchainWorkerInstance, func(), error) {
+						return nil, nil, errTest
+					},
+				}
+			},
+			errWrapped: errTest,
+			errMessage: "creating runtime instance: test error",
+		},
+		"offchain_worker_error": {
+			serviceBuilder: func(_ *testing.T, ctrl *gomock.Controller) *Service {
+				storageState := NewMockStorageState(ctrl)
+				storageState.EXPECT().TrieState(&header.StateRoot).Return(newTrieState(), nil)
+				runtimeInstance := NewMockRuntimeInstance(ctrl)
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().GetRuntime(blockHash).Return(runtimeInstance, nil)
+				instance := NewMockOffchainWorkerInstance(ctrl)
+				instance.EXPECT().OffchainWorker(header).Return(errTest)
+				return &Service{
+					ctx:                  context.Background(),
+					storageState:         storageState,
+					blockState:           blockState,
+					offchainWorkerConfig: OffchainWorkerConfig{Timeout: time.Minute},
+					newOffchainWorkerInstance: func(RuntimeInstance, *rtstorage.TrieState,
+						TransactionState) (OffchainWorkerInstance, func(), error) {
+						return instance, func() {}, nil
+					},
+				}
+			},
+			errWrapped: errTest,
+			errMessage: "test error",
+		},
+		"success": {
+			serviceBuilder: func(t *testing.T, ctrl *gomock.Controller) *Service {
+				trieState := newTrieState()
+				storageState := NewMockStorageState(ctrl)
+				storageState.EXPECT().TrieState(&header.StateRoot).Return(trieState, nil)
+				runtimeInstance := NewMockRuntimeInstance(ctrl)
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().GetRuntime(blockHash).Return(runtimeInstance, nil)
+				transactionState := NewMockTransactionState(ctrl)
+				instance := NewMockOffchainWorkerInstance(ctrl)
+				instance.EXPECT().OffchainWorker(header).Return(nil)
+				return &Service{
+					ctx:                  context.Background(),
+					storageState:         storageState,
+					blockState:           blockState,
+					transactionState:     transactionState,
+					offchainWorkerConfig: OffchainWorkerConfig{Timeout: time.Minute},
+					newOffchainWorkerInstance: func(blockRuntime RuntimeInstance,
+						storage *rtstorage.TrieState, transactions TransactionState) (
+						OffchainWorkerInstance, func(), error) {
+						assert.Same(t, runtimeInstance, blockRuntime)
+						assert.Same(t, trieState, storage)
+						assert.Same(t, transactionState, transactions)
+						return instance, func() {}, nil
+					},
+				}
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			service := testCase.serviceBuilder(t, ctrl)
+
+			released := make(chan struct{})
+			releaseSlot := func() { close(released) }
+			err := service.runOffchainWorker(header, blockHash, releaseSlot)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			<-released
+		})
+	}
+}
+
+func Test_Service_runOffchainWorker_timeout(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	header := &types.Header{
+		Number: 1,
+		Digest: types.NewDigest(),
+	}
+	blockHash := header.Hash()
+
+	storageState := NewMockStorageState(ctrl)
+	storageState.EXPECT().TrieState(&header.StateRoot).
+		Return(rtstorage.NewTrieState(trie.NewEmptyTrie()), nil)
+	runtimeInstance := NewMockRuntimeInstance(ctrl)
+	blockState := NewMockBlockState(ctrl)
+	blockState.EXPECT().GetRuntime(blockHash).Return(runtimeInstance, nil)
+
+	unblock := make(chan struct{})
+	instanceReleased := make(chan struct{})
+	instance := NewMockOffchainWorkerInstance(ctrl)
+	instance.EXPECT().OffchainWorker(header).DoAndReturn(func(*types.Header) error {
+		<-unblock
+		return nil
+	})
+
+	service := &Service{
+		ctx:                  context.Background(),
+		storageState:         storageState,
+		blockState:           blockState,
+		offchainWorkerConfig: OffchainWorkerConfig{Timeout: time.Millisecond},
+		newOffchainWorkerInstance: func(RuntimeInstance, *rtstorage.TrieState,
+			TransactionState) (OffchainWorkerInstance, func(), error) {
+			return instance, func() { close(instanceReleased) }, nil
+		},
+	}
+
+	released := make(chan struct{})
+	releaseSlot := func() { close(released) }
+	err := service.runOffchainWorker(header, blockHash, releaseSlot)
+	require.ErrorIs(t, err, errOffchainWorkerTimeout)
+	assert.EqualError(t, err, "offchain worker timed out: after 1ms")
+
+	// the runtime instance and its slot are only released
+	// once the offchain worker call returns.
+	select {
+	case <-released:
+		t.Fatal("slot released before the offchain worker call returned")
+	default:
+	}
+	close(unblock)
+	<-instanceReleased
+	<-released
+}
@@ -11,6 +11,7 @@
 	"sync"
 
 	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/state"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/internal/log"
 	"github.com/ChainSafe/gossamer/lib/blocktree"
@@ -18,7 +19,6 @@
 	"github.com/ChainSafe/gossamer/lib/keystore"
 	"github.com/ChainSafe/gossamer/lib/runtime"
 	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
-	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
 	"github.com/ChainSafe/gossamer/lib/transaction"
 
 	cscale "github.com/centrifuge/go-substrate-rpc-client/v4/scale"
@@ -53,6 +53,16 @@ type Service struct {
 
 	// Keystore
 	keys *keystore.GlobalKeystore
+	// keystoreBasePath is the base path where keys generated by
+	// RotateKeys are written, and keystorePassword is used to encrypt them.
+	keystoreBasePath string
+	keystorePassword []byte
+
+	// offchain workers
+	offchainWorkerConfig      OffchainWorkerConfig
+	offchainWorkerSlots       chan struct{}
+	newOffchainWorkerInstance func(blockRuntime RuntimeInstance, storage *rtstorage.TrieState,
+		transactionState TransactionState) (instance OffchainWorkerInstance, release func(), err error)
 }
 
 // Config holds the configuration for the core Service.
@@ -66,8 +76,16 @@ type Config struct {
 	Keystore         *keystore.GlobalKeystore
 	Runtime          RuntimeInstance
 
+	// KeystoreBasePath is the base path where the session keys generated
+	// by RotateKeys are written. They are not written if it is left empty.
+	KeystoreBasePath string
+	// KeystorePassword is the password used to encrypt the session key files.
+	KeystorePassword []byte
+
 	CodeSubstitutes      map[common.Hash]string
 	CodeSubstitutedState CodeSubstitutedState
+
+	OffchainWorker OffchainWorkerConfig
 }
 
 // NewService returns a new core service that connects the runtime, BABE
@@ -77,11 +95,16 @@ func NewService(cfg *Config) (*Service, error) {
 
 	blockAddCh := make(chan *types.Block, 256)
 
+	offchainWorkerConfig := cfg.OffchainWorker
+	offchainWorkerConfig.setDefaults()
+
 	ctx, cancel := context.WithCancel(context.Background())
 	srv := &Service{
 		ctx:                  ctx,
 		cancel:               cancel,
 		keys:                 cfg.Keystore,
+		keystoreBasePath:     cfg.KeystoreBasePath,
+		keystorePassword:     cfg.KeystorePassword,
 		blockState:           cfg.BlockState,
 		storageState:         cfg.StorageState,
 		transactionState:     cfg.TransactionState,
@@ -89,6 +112,10 @@ func NewService(cfg *Config) (*Service, error) {
 		blockAddCh:           blockAddCh,
 		codeSubstitute:       cfg.CodeSubstitutes,
 		codeSubstitutedState: cfg.CodeSubstitutedState,
+		offchainWorkerConfig: offchainWorkerConfig,
+		offchainWorkerSlots:  make(chan struct{}, offchainWorkerConfig.MaxConcurrency),
+
+		newOffchainWorkerInstance: newOffchainWorkerInstance,
 	}
 
 	return srv, nil
@@ -207,6 +234,12 @@ func (s *Service) handleBlock(block *types.Block, state *rtstorage.TrieState) er
 		} else {
 			return err
 		}
+	} else {
+		// store the extrinsic data the runtime indexed while executing the block
+		err = s.blockState.StoreIndexedTransactions(block, state.TransactionIndexOperations())
+		if err != nil {
+			return err
+		}
 	}
 
 	logger.Debugf("imported block %s and stored state trie with root %s",
@@ -244,7 +277,7 @@ func (s *Service) handleBlock(block *types.Block, state *rtstorage.TrieState) er
 }
 
 func (s *Service) handleCodeSubstitution(hash common.Hash,
-	state *rtstorage.TrieState) (err error) {
+	trieState *rtstorage.TrieState) (err error) {
 	value := s.codeSubstitute[hash]
 	if value == "" {
 		return nil
@@ -263,18 +296,7 @@ func (s *Service) handleCodeSubstitution(hash common.Hash,
 
 	// this needs to create a new runtime instance, otherwise it will update
 	// the blocks that reference the current runtime version to use the code substition
-	cfg := wasmer.Config{
-		Storage:     state,
-		Keystore:    rt.Keystore(),
-		NodeStorage: rt.NodeStorage(),
-		Network:     rt.NetworkService(),
-	}
-
-	if rt.Validator() {
-		cfg.Role = 4
-	}
-
-	next, err := wasmer.NewInstance(code, cfg)
+	next, err := state.NewRuntimeFromPrevious(rt, code, trieState, common.Hash{})
 	if err != nil {
 		return fmt.Errorf("creating new runtime instance: %w", err)
 	}
@@ -312,6 +334,10 @@ func (s *Service) handleBlocksAsync() {
 				// TODO remove once gossamer is in stable state
 				panic(fmt.Errorf("failed to maintain txn pool after re-org: %s", err))
 			}
+
+			if block.Header.Hash() == bestBlockHash {
+				s.runOffchainWorkers(&block.Header)
+			}
 		case <-s.ctx.Done():
 			return
 		}
@@ -396,8 +422,8 @@ func (s *Service) handleChainReorg(best, curr common.Hash) error {
 }
 
 // maintainTransactionPool removes any transactions that were included in
-// the new block, revalidates the transactions in the pool, and moves
-// them to the queue if valid.
+// the new block, revalidates the transactions in the pool, moves them to
+// the queue if valid and ready, and removes the expired transactions.
 // See https://github.com/paritytech/substrate/blob/74804b5649eccfb83c90aec87bdca58e5d5c8789/client/transaction-pool/src/lib.rs#L545
 func (s *Service) maintainTransactionPool(block *types.Block, bestBlockHash common.Hash) error {
 	// remove extrinsics included in a block
@@ -426,13 +452,20 @@ func (s *Service) maintainTransactionPool(block *types.Block, bestBlockHash comm
 			return fmt.Errorf("failed to get runtime to re-validate transactions in pool: %s", err)
 		}
 
-		rt.SetContextStorage(ts)
-		externalExt, err := s.buildExternalTransaction(rt, tx.Extrinsic)
+		instance, release, err := runtime.AcquireInstance(rt)
 		if err != nil {
+			return fmt.Errorf("failed to get runtime instance to re-validate transactions in pool: %w", err)
+		}
+
+		instance.SetContextStorage(ts)
+		externalExt, err := s.buildExternalTransaction(instance, tx.Extrinsic)
+		if err != nil {
+			release()
 			return fmt.Errorf("building external transaction: %s", err)
 		}
 
-		txnValidity, err := rt.ValidateTransaction(externalExt)
+		txnValidity, err := instance.ValidateTransaction(externalExt)
+		release()
 		if err != nil {
 			logger.Debugf("failed to validate transaction for extrinsic %s: %s", tx.Extrinsic, err)
 			s.transactionState.RemoveExtrinsic(tx.Extrinsic)
@@ -441,11 +474,19 @@ func (s *Service) maintainTransactionPool(block *types.Block, bestBlockHash comm
 
 		tx = transaction.NewValidTransaction(tx.Extrinsic, txnValidity)
 
-		// Err is only thrown if tx is already in pool, in which case it still gets removed
-		h, _ := s.transactionState.Push(tx)
+		// the transaction is moved to the queue if the tags it requires are provided,
+		// and otherwise stays in the pool as a future transaction.
+		h, err := s.transactionState.Push(tx)
+		if err != nil {
+			logger.Debugf("failed to push transaction %s: %s", h, err)
+			continue
+		}
+		logger.Tracef("pushed transaction %s", h)
+	}
 
-		s.transactionState.RemoveExtrinsicFromPool(tx.Extrinsic)
-		logger.Tracef("moved transaction %s to queue", h)
+	removed := s.transactionState.RemoveExpired(block.Header.Number)
+	if len(removed) > 0 {
+		logger.Debugf("removed %d expired transactions at block number %d", len(removed), block.Header.Number)
 	}
 	return nil
 }
@@ -502,6 +543,11 @@ func (s *Service) HandleSubmittedExtrinsic(ext types.Extrinsic) error {
 		return nil
 	}
 
+	extHash := ext.Hash()
+	if s.transactionState.IsBanned(extHash) {
+		return fmt.Errorf("%w: %s", ErrTransactionBanned, extHash)
+	}
+
 	bestBlockHash := s.blockState.BestBlockHash()
 
 	stateRoot, err := s.storageState.GetStateRootFromBlock(&bestBlockHash)
@@ -520,14 +566,20 @@ func (s *Service) HandleSubmittedExtrinsic(ext types.Extrinsic) error {
 		return err
 	}
 
-	rt.SetContextStorage(ts)
+	instance, release, err := runtime.AcquireInstance(rt)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	instance.SetContextStorage(ts)
 
-	externalExt, err := s.buildExternalTransaction(rt, ext)
+	externalExt, err := s.buildExternalTransaction(instance, ext)
 	if err != nil {
 		return fmt.Errorf("building external transaction: %w", err)
 	}
 
-	transactionValidity, err := rt.ValidateTransaction(externalExt)
+	transactionValidity, err := instance.ValidateTransaction(externalExt)
 	if err != nil {
 		return err
 	}
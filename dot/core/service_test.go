@@ -408,6 +408,28 @@ func Test_Service_handleBlock(t *testing.T) {
 		execTest(t, service, &block, trieState, blocktree.ErrParentNotFound)
 	})
 
+	t.Run("store indexed transactions error", func(t *testing.T) {
+		t.Parallel()
+		trieState := rtstorage.NewTrieState(nil)
+
+		testHeader := types.NewEmptyHeader()
+		block := types.NewBlock(*testHeader, *types.NewBody([]types.Extrinsic{[]byte{21}}))
+		block.Header.Number = 21
+
+		ctrl := gomock.NewController(t)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().StoreTrie(trieState, &block.Header).Return(nil)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().AddBlock(&block).Return(nil)
+		mockBlockState.EXPECT().StoreIndexedTransactions(&block, nil).Return(errTestDummyError)
+
+		service := &Service{
+			storageState: mockStorageState,
+			blockState:   mockBlockState,
+		}
+		execTest(t, service, &block, trieState, errTestDummyError)
+	})
+
 	t.Run("addBlock error continue", func(t *testing.T) {
 		t.Parallel()
 		trieState := rtstorage.NewTrieState(nil)
@@ -589,6 +611,7 @@ func Test_Service_maintainTransactionPool(t *testing.T) {
 		mockTxnState := NewMockTransactionState(ctrl)
 		mockTxnState.EXPECT().RemoveExtrinsic(types.Extrinsic{21}).Times(2)
 		mockTxnState.EXPECT().PendingInPool().Return([]*transaction.ValidTransaction{vt})
+		mockTxnState.EXPECT().RemoveExpired(uint(21)).Return([]common.Hash{{1}})
 		mockBlockState := NewMockBlockState(ctrl)
 		runtimeBlockHashCall := mockBlockState.EXPECT().BestBlockHash().Return(common.Hash{1})
 		mockBlockState.EXPECT().GetRuntime(common.Hash{1}).
@@ -654,7 +677,7 @@ func Test_Service_maintainTransactionPool(t *testing.T) {
 		mockTxnState.EXPECT().RemoveExtrinsic(types.Extrinsic{21})
 		mockTxnState.EXPECT().PendingInPool().Return([]*transaction.ValidTransaction{vt})
 		mockTxnState.EXPECT().Push(tx).Return(common.Hash{}, nil)
-		mockTxnState.EXPECT().RemoveExtrinsicFromPool(types.Extrinsic{21})
+		mockTxnState.EXPECT().RemoveExpired(uint(21)).Return(nil)
 
 		mockBlockStateOk := NewMockBlockState(ctrl)
 		runtimeBlockHashCall := mockBlockStateOk.EXPECT().BestBlockHash().Return(common.Hash{1})
@@ -1190,6 +1213,22 @@ func TestServiceHandleSubmittedExtrinsic(t *testing.T) {
 		execTest(t, service, nil, nil)
 	})
 
+	t.Run("banned transaction", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockTxnState := NewMockTransactionState(ctrl)
+		mockTxnState.EXPECT().Exists(ext)
+		mockTxnState.EXPECT().IsBanned(ext.Hash()).Return(true)
+		service := &Service{
+			transactionState: mockTxnState,
+			net:              NewMockNetwork(ctrl),
+		}
+
+		err := service.HandleSubmittedExtrinsic(ext)
+		assert.ErrorIs(t, err, ErrTransactionBanned)
+		assert.EqualError(t, err, "transaction is temporarily banned: "+ext.Hash().String())
+	})
+
 	t.Run("trie state err", func(t *testing.T) {
 		t.Parallel()
 		ctrl := gomock.NewController(t)
@@ -1201,6 +1240,7 @@ func TestServiceHandleSubmittedExtrinsic(t *testing.T) {
 		mockBlockState.EXPECT().BestBlockHash().Return(common.Hash{})
 		mockTxnState := NewMockTransactionState(ctrl)
 		mockTxnState.EXPECT().Exists(nil)
+		mockTxnState.EXPECT().IsBanned(ext.Hash())
 		service := &Service{
 			blockState:       mockBlockState,
 			storageState:     mockStorageState,
@@ -1224,6 +1264,7 @@ func TestServiceHandleSubmittedExtrinsic(t *testing.T) {
 
 		mockTxnState := NewMockTransactionState(ctrl)
 		mockTxnState.EXPECT().Exists(nil).MaxTimes(2)
+		mockTxnState.EXPECT().IsBanned(ext.Hash())
 		service := &Service{
 			storageState:     mockStorageState,
 			transactionState: mockTxnState,
@@ -1248,6 +1289,7 @@ func TestServiceHandleSubmittedExtrinsic(t *testing.T) {
 
 		mockTxnState := NewMockTransactionState(ctrl)
 		mockTxnState.EXPECT().Exists(types.Extrinsic{})
+		mockTxnState.EXPECT().IsBanned(ext.Hash())
 
 		runtimeMockErr.EXPECT().ValidateTransaction(externalExt).Return(nil, errDummyErr)
 		runtimeMockErr.EXPECT().Version().Return(runtime.Version{
@@ -1305,6 +1347,7 @@ func TestServiceHandleSubmittedExtrinsic(t *testing.T) {
 
 		mockTxnState := NewMockTransactionState(ctrl)
 		mockTxnState.EXPECT().Exists(types.Extrinsic{}).MaxTimes(2)
+		mockTxnState.EXPECT().IsBanned(ext.Hash())
 		mockTxnState.EXPECT().AddToPool(transaction.NewValidTransaction(ext, &transaction.Validity{Propagate: true}))
 		mockNetState := NewMockNetwork(ctrl)
 		mockNetState.EXPECT().GossipMessage(&network.TransactionMessage{Extrinsics: []types.Extrinsic{ext}})
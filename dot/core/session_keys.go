@@ -0,0 +1,129 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+// sessionKey is a session public key with its key type, as
+// returned by the runtime API call SessionKeys_decode_session_keys.
+type sessionKey struct {
+	PublicKey []byte
+	KeyType   [4]byte
+}
+
+// RotateKeys generates new session keys using the runtime of the best block.
+// The runtime inserts the new keys in the keystore, and they are then written
+// encrypted to the keystore directory of the base path, if one is configured
+// together with a keystore password. They are loaded back in the keystore on
+// restart with keystore.LoadSessionKeys.
+// It returns the SCALE encoded session public keys, as expected by session.setKeys.
+func (s *Service) RotateKeys() (publicKeys []byte, err error) {
+	bestBlockHash := s.blockState.BestBlockHash()
+
+	stateRoot, err := s.storageState.GetStateRootFromBlock(&bestBlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("getting state root from block %s: %w", bestBlockHash, err)
+	}
+
+	trieState, err := s.storageState.TrieState(stateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("getting trie state: %w", err)
+	}
+
+	rt, err := s.blockState.GetRuntime(bestBlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("getting runtime: %w", err)
+	}
+
+	instance, release, err := runtime.AcquireInstance(rt)
+	if err != nil {
+		return nil, fmt.Errorf("acquiring runtime instance: %w", err)
+	}
+	defer release()
+
+	instance.SetContextStorage(trieState)
+
+	publicKeys, err = instance.GenerateSessionKeys(nil)
+	if err != nil {
+		return nil, fmt.Errorf("generating session keys: %w", err)
+	}
+
+	if s.keystoreBasePath == "" {
+		return publicKeys, nil
+	}
+
+	if len(s.keystorePassword) == 0 {
+		logger.Warnf("session keys 0x%x are NOT written to disk since no keystore password "+
+			"is set with --password, and they will be lost on restart", publicKeys)
+		return publicKeys, nil
+	}
+
+	encodedPublicKeys, err := scale.Marshal(publicKeys)
+	if err != nil {
+		return nil, fmt.Errorf("encoding session public keys: %w", err)
+	}
+
+	encodedSessionKeys, err := instance.DecodeSessionKeys(encodedPublicKeys)
+	if err != nil {
+		return nil, fmt.Errorf("decoding session keys: %w", err)
+	}
+
+	var sessionKeys *[]sessionKey
+	err = scale.Unmarshal(encodedSessionKeys, &sessionKeys)
+	if err != nil {
+		return nil, fmt.Errorf("decoding session keys: %w", err)
+	}
+
+	if sessionKeys == nil {
+		return nil, fmt.Errorf("%w: 0x%x", errInvalidSessionKeys, publicKeys)
+	}
+
+	for _, key := range *sessionKeys {
+		err = s.persistSessionKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("persisting %s session key: %w",
+				keystore.Name(key.KeyType[:]), err)
+		}
+	}
+
+	return publicKeys, nil
+}
+
+// persistSessionKey writes the keypair of the given session key from the
+// keystore to the keystore directory, encrypted with the keystore password.
+func (s *Service) persistSessionKey(key sessionKey) error {
+	ks, err := s.keys.GetKeystore(key.KeyType[:])
+	if err != nil {
+		return fmt.Errorf("getting keystore: %w", err)
+	}
+
+	for _, kp := range ks.Keypairs() {
+		if !bytes.Equal(kp.Public().Encode(), key.PublicKey) {
+			continue
+		}
+
+		publicPrivater, ok := kp.(keystore.PublicPrivater)
+		if !ok {
+			return fmt.Errorf("%w: %T", errKeypairNotExportable, kp)
+		}
+
+		name := keystore.Name(key.KeyType[:])
+		_, err = keystore.WriteSessionKey(name, publicPrivater, s.keystoreBasePath, s.keystorePassword)
+		if err != nil {
+			return fmt.Errorf("writing key file: %w", err)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", errKeyNotFound, common.BytesToHex(key.PublicKey))
+}
@@ -0,0 +1,214 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Service_RotateKeys(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+	bestBlockHash := common.Hash{1}
+	publicKeys := []byte{1, 2, 3}
+	encodedPublicKeys := scale.MustMarshal(publicKeys)
+
+	keyring, err := keystore.NewSr25519Keyring()
+	require.NoError(t, err)
+	babeKeypair := keyring.KeyAlice
+
+	newKeystore := func() *keystore.GlobalKeystore {
+		ks := keystore.NewGlobalKeystore()
+		err := ks.Babe.Insert(babeKeypair)
+		require.NoError(t, err)
+		return ks
+	}
+
+	babeSessionKeys := scale.MustMarshal(&[]sessionKey{{
+		PublicKey: babeKeypair.Public().Encode(),
+		KeyType:   [4]byte{'b', 'a', 'b', 'e'},
+	}})
+
+	stateRoot := common.Hash{2}
+	trieState := rtstorage.NewTrieState(trie.NewEmptyTrie())
+
+	testCases := map[string]struct {
+		stateRootErr    error
+		runtimeBuilder  func(ctrl *gomock.Controller) RuntimeInstance
+		persist         bool
+		password        []byte
+		publicKeys      []byte
+		errWrapped      error
+		errMessage      string
+		expectedKeyFile bool
+	}{
+		"get_state_root_error": {
+			stateRootErr: errTest,
+			errWrapped:   errTest,
+			errMessage: "getting state root from block " +
+				"0x0100000000000000000000000000000000000000000000000000000000000000: test error",
+		},
+		"get_runtime_error": {
+			errWrapped: errTest,
+			errMessage: "getting runtime: test error",
+		},
+		"generate_session_keys_error": {
+			runtimeBuilder: func(ctrl *gomock.Controller) RuntimeInstance {
+				instance := NewMockRuntimeInstance(ctrl)
+				instance.EXPECT().SetContextStorage(trieState)
+				instance.EXPECT().GenerateSessionKeys(nil).Return(nil, errTest)
+				return instance
+			},
+			errWrapped: errTest,
+			errMessage: "generating session keys: test error",
+		},
+		"keys_not_persisted": {
+			runtimeBuilder: func(ctrl *gomock.Controller) RuntimeInstance {
+				instance := NewMockRuntimeInstance(ctrl)
+				instance.EXPECT().SetContextStorage(trieState)
+				instance.EXPECT().GenerateSessionKeys(nil).Return(publicKeys, nil)
+				return instance
+			},
+			publicKeys: publicKeys,
+		},
+		"keys_not_persisted_without_password": {
+			runtimeBuilder: func(ctrl *gomock.Controller) RuntimeInstance {
+				instance := NewMockRuntimeInstance(ctrl)
+				instance.EXPECT().SetContextStorage(trieState)
+				instance.EXPECT().GenerateSessionKeys(nil).Return(publicKeys, nil)
+				return instance
+			},
+			persist:    true,
+			password:   []byte{},
+			publicKeys: publicKeys,
+		},
+		"decode_session_keys_error": {
+			runtimeBuilder: func(ctrl *gomock.Controller) RuntimeInstance {
+				instance := NewMockRuntimeInstance(ctrl)
+				instance.EXPECT().SetContextStorage(trieState)
+				instance.EXPECT().GenerateSessionKeys(nil).Return(publicKeys, nil)
+				instance.EXPECT().DecodeSessionKeys(encodedPublicKeys).Return(nil, errTest)
+				return instance
+			},
+			persist:    true,
+			errWrapped: errTest,
+			errMessage: "decoding session keys: test error",
+		},
+		"invalid_session_keys": {
+			runtimeBuilder: func(ctrl *gomock.Controller) RuntimeInstance {
+				instance := NewMockRuntimeInstance(ctrl)
+				instance.EXPECT().SetContextStorage(trieState)
+				instance.EXPECT().GenerateSessionKeys(nil).Return(publicKeys, nil)
+				instance.EXPECT().DecodeSessionKeys(encodedPublicKeys).Return([]byte{0}, nil)
+				return instance
+			},
+			persist:    true,
+			errWrapped: errInvalidSessionKeys,
+			errMessage: "invalid session keys: 0x010203",
+		},
+		"key_not_found": {
+			runtimeBuilder: func(ctrl *gomock.Controller) RuntimeInstance {
+				instance := NewMockRuntimeInstance(ctrl)
+				instance.EXPECT().SetContextStorage(trieState)
+				instance.EXPECT().GenerateSessionKeys(nil).Return(publicKeys, nil)
+				sessionKeys := scale.MustMarshal(&[]sessionKey{{
+					PublicKey: []byte{1},
+					KeyType:   [4]byte{'g', 'r', 'a', 'n'},
+				}})
+				instance.EXPECT().DecodeSessionKeys(encodedPublicKeys).Return(sessionKeys, nil)
+				return instance
+			},
+			persist:    true,
+			errWrapped: errKeyNotFound,
+			errMessage: "persisting gran session key: key not found in keystore: 0x01",
+		},
+		"success": {
+			runtimeBuilder: func(ctrl *gomock.Controller) RuntimeInstance {
+				instance := NewMockRuntimeInstance(ctrl)
+				instance.EXPECT().SetContextStorage(trieState)
+				instance.EXPECT().GenerateSessionKeys(nil).Return(publicKeys, nil)
+				instance.EXPECT().DecodeSessionKeys(encodedPublicKeys).Return(babeSessionKeys, nil)
+				return instance
+			},
+			persist:         true,
+			publicKeys:      publicKeys,
+			expectedKeyFile: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			blockState := NewMockBlockState(ctrl)
+			blockState.EXPECT().BestBlockHash().Return(bestBlockHash)
+			storageState := NewMockStorageState(ctrl)
+			if testCase.stateRootErr != nil {
+				storageState.EXPECT().GetStateRootFromBlock(&bestBlockHash).
+					Return(nil, testCase.stateRootErr)
+			} else {
+				storageState.EXPECT().GetStateRootFromBlock(&bestBlockHash).Return(&stateRoot, nil)
+				storageState.EXPECT().TrieState(&stateRoot).Return(trieState, nil)
+				if testCase.runtimeBuilder == nil {
+					blockState.EXPECT().GetRuntime(bestBlockHash).Return(nil, errTest)
+				} else {
+					blockState.EXPECT().GetRuntime(bestBlockHash).
+						Return(testCase.runtimeBuilder(ctrl), nil)
+				}
+			}
+
+			service := &Service{
+				blockState:       blockState,
+				storageState:     storageState,
+				keys:             newKeystore(),
+				keystorePassword: []byte("password"),
+			}
+			if testCase.password != nil {
+				service.keystorePassword = testCase.password
+			}
+			if testCase.persist {
+				service.keystoreBasePath = t.TempDir()
+			}
+
+			publicKeys, err := service.RotateKeys()
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.publicKeys, publicKeys)
+
+			if !testCase.expectedKeyFile {
+				if service.keystoreBasePath != "" {
+					keyFiles, err := filepath.Glob(filepath.Join(service.keystoreBasePath,
+						"keystore", "session", "*", "*.key"))
+					require.NoError(t, err)
+					assert.Empty(t, keyFiles)
+				}
+				return
+			}
+
+			keyFile := filepath.Join(service.keystoreBasePath, "keystore", "session", "babe",
+				hex.EncodeToString(babeKeypair.Public().Encode())+".key")
+			privateKey, err := keystore.ReadFromFileAndDecrypt(keyFile, service.keystorePassword)
+			require.NoError(t, err)
+			assert.Equal(t, babeKeypair.Private().Encode(), privateKey.Encode())
+		})
+	}
+}
@@ -13,4 +13,8 @@
 // ErrInvalidKeystoreType when trying to create a service with the wrong keystore type
 var ErrInvalidKeystoreType = errors.New("invalid keystore type")
 
-var ErrWasmInterpreterName = errors.New("unknown wasm interpreter name")
+var (
+	errInvalidBlocksFormat = errors.New("invalid blocks format")
+	errInvalidBlocksRange  = errors.New("invalid blocks range")
+	errBlockHashMismatch   = errors.New("block hash does not match header hash")
+)
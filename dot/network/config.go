@@ -64,6 +64,12 @@ type Config struct {
 	BlockState         BlockState
 	Syncer             Syncer
 	TransactionHandler TransactionHandler
+	// StorageState is used to answer light client requests.
+	// Light client requests are ignored if it is left to nil.
+	StorageState StorageState
+	// WarpSyncProvider is used to answer warp sync proof requests.
+	// Warp sync requests are not served if it is left to nil.
+	WarpSyncProvider WarpSyncProvider
 
 	// Used to specify the address broadcasted to other peers, and avoids using pubip.Get
 	PublicIP string
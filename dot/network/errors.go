@@ -16,4 +16,9 @@
 	errInvalidStartingBlockType      = errors.New("invalid StartingBlock in messsage")
 	errInboundHanshakeExists         = errors.New("an inbound handshake already exists for given peer")
 	errInvalidRole                   = errors.New("invalid role")
+	errBlockNotFinalised             = errors.New("block is not finalised")
+	errNoJustification               = errors.New("no justification found")
+	errInvalidStateRequestBlock      = errors.New("state request block hash is not valid")
+	errInvalidStateRequestStart      = errors.New("state request has too many start keys")
+	errInvalidChildStorageKey        = errors.New("key is not a child storage key")
 )
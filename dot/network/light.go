@@ -4,12 +4,20 @@
 package network
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"math"
 
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 
+	"github.com/ChainSafe/chaindb"
 	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 )
@@ -46,18 +54,23 @@ func (s *Service) handleLightMsg(stream libp2pnetwork.Stream, msg Message) (err
 		return nil
 	}
 
+	if s.storageState == nil {
+		logger.Debug("ignoring LightRequest since no storage state is set")
+		return nil
+	}
+
 	resp := NewLightResponse()
 	switch {
 	case lr.RemoteCallRequest != nil:
-		resp.RemoteCallResponse, err = remoteCallResp(lr.RemoteCallRequest)
+		resp.RemoteCallResponse, err = s.remoteCallResp(lr.RemoteCallRequest)
 	case lr.RemoteHeaderRequest != nil:
-		resp.RemoteHeaderResponse, err = remoteHeaderResp(lr.RemoteHeaderRequest)
+		resp.RemoteHeaderResponse, err = s.remoteHeaderResp(lr.RemoteHeaderRequest)
 	case lr.RemoteChangesRequest != nil:
 		resp.RemoteChangesResponse, err = remoteChangeResp(lr.RemoteChangesRequest)
 	case lr.RemoteReadRequest != nil:
-		resp.RemoteReadResponse, err = remoteReadResp(lr.RemoteReadRequest)
+		resp.RemoteReadResponse, err = s.remoteReadResp(lr.RemoteReadRequest)
 	case lr.RemoteReadChildRequest != nil:
-		resp.RemoteReadResponse, err = remoteReadChildResp(lr.RemoteReadChildRequest)
+		resp.RemoteReadResponse, err = s.remoteReadChildResp(lr.RemoteReadChildRequest)
 	default:
 		logger.Warn("ignoring LightRequest without request data")
 		return nil
@@ -67,8 +80,7 @@ func (s *Service) handleLightMsg(stream libp2pnetwork.Stream, msg Message) (err
 		return err
 	}
 
-	// TODO(arijit): Remove once we implement the internal APIs. Added to increase code coverage. (#1856)
-	logger.Debugf("LightResponse message: %s", resp)
+	logger.Tracef("LightResponse message: %s", resp)
 
 	err = s.host.writeToStream(stream, resp)
 	if err != nil {
@@ -334,7 +346,7 @@ func newRemoteReadResponse() *RemoteReadResponse {
 // RemoteHeaderResponse ...
 type RemoteHeaderResponse struct {
 	Header []*types.Header
-	proof  []byte
+	Proof  []byte
 }
 
 func newRemoteHeaderResponse() *RemoteHeaderResponse {
@@ -443,21 +455,477 @@ func (rr *RemoteReadResponse) String() string {
 
 // String formats a RemoteHeaderResponse as a string
 func (rh *RemoteHeaderResponse) String() string {
-	return fmt.Sprintf("Header =%+v Proof =%s", rh.Header, string(rh.proof))
+	return fmt.Sprintf("Header =%+v Proof =%s", rh.Header, string(rh.Proof))
 }
 
-func remoteCallResp(_ *RemoteCallRequest) (*RemoteCallResponse, error) {
-	return &RemoteCallResponse{}, nil
+// remoteCallResp executes the runtime method of the request at the requested
+// block, and returns a proof of all the state trie keys accessed during execution,
+// including the keys absent from the state trie.
+func (s *Service) remoteCallResp(req *RemoteCallRequest) (*RemoteCallResponse, error) {
+	blockHash := common.BytesToHash(req.Block)
+	stateRoot, err := s.storageState.GetStateRootFromBlock(&blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("getting state root for block %s: %w", blockHash, err)
+	}
+
+	trieState, err := s.storageState.TrieState(stateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("getting trie state for state root %s: %w", stateRoot, err)
+	}
+
+	recorder := newReadRecorder(trieState)
+	_, err = s.blockState.CallRuntime(blockHash, recorder, req.Method, req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("executing runtime method %s: %w", req.Method, err)
+	}
+
+	// Note the proof is generated against the block state root, so keys
+	// written and then read during execution are proven with their value,
+	// or absence, at the block state root.
+	encodedProofNodes, err := s.generateProofNodes(*stateRoot, recorder.keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, keyToChild := range recorder.childTrieKeys {
+		childEncodedProofNodes, err := s.generateChildProofNodes(stateRoot,
+			[]byte(keyToChild), recorder.childKeys[keyToChild])
+		if err != nil {
+			return nil, err
+		}
+		encodedProofNodes = append(encodedProofNodes, childEncodedProofNodes...)
+	}
+
+	proof, err := scale.Marshal(encodedProofNodes)
+	if err != nil {
+		return nil, fmt.Errorf("encoding proof: %w", err)
+	}
+
+	return &RemoteCallResponse{Proof: proof}, nil
 }
+
 func remoteChangeResp(_ *RemoteChangesRequest) (*RemoteChangesResponse, error) {
+	// Changes tries are not supported, so an empty response is returned.
 	return &RemoteChangesResponse{}, nil
 }
-func remoteHeaderResp(_ *RemoteHeaderRequest) (*RemoteHeaderResponse, error) {
-	return &RemoteHeaderResponse{}, nil
+
+// remoteHeaderResp returns the header at the requested block number,
+// together with a proof of the header against the finalised chain.
+// Only finalised headers are served, since there are no canonical hash tries
+// (CHT) to prove a header belongs to the chain.
+func (s *Service) remoteHeaderResp(req *RemoteHeaderRequest) (*RemoteHeaderResponse, error) {
+	var blockNumber uint
+	err := scale.Unmarshal(req.Block, &blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("decoding block number: %w", err)
+	}
+
+	finalised, err := s.blockState.GetHighestFinalisedHeader()
+	if err != nil {
+		return nil, fmt.Errorf("getting highest finalised header: %w", err)
+	}
+
+	if blockNumber > finalised.Number {
+		return nil, fmt.Errorf("%w: block number %d is greater than finalised block number %d",
+			errBlockNotFinalised, blockNumber, finalised.Number)
+	}
+
+	header, err := s.blockState.GetHeaderByNumber(blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("getting header for block number %d: %w", blockNumber, err)
+	}
+
+	proof, err := s.generateHeaderProof(header, finalised.Number)
+	if err != nil {
+		return nil, fmt.Errorf("generating header proof: %w", err)
+	}
+
+	encodedProof, err := scale.Marshal(*proof)
+	if err != nil {
+		return nil, fmt.Errorf("encoding header proof: %w", err)
+	}
+
+	return &RemoteHeaderResponse{
+		Header: []*types.Header{header},
+		Proof:  encodedProof,
+	}, nil
+}
+
+// headerProof proves a header belongs to the finalised chain.
+type headerProof struct {
+	// Headers are the headers descending from the proven header,
+	// up to and including the header justified. It is empty if
+	// the proven header is itself justified.
+	Headers []types.Header
+	// Justification is the GRANDPA justification of the last header
+	// of Headers, or of the proven header if Headers is empty.
+	Justification []byte
+}
+
+// generateHeaderProof generates a proof of the given finalised header
+// using the closest descendant finalised block having a justification,
+// up to the finalised block number given.
+func (s *Service) generateHeaderProof(header *types.Header, finalisedNumber uint) (
+	proof *headerProof, err error) {
+	proof = &headerProof{}
+	for {
+		justification, err := s.blockState.GetJustification(header.Hash())
+		if err == nil && justification != nil {
+			proof.Justification = justification
+			return proof, nil
+		} else if err != nil && !errors.Is(err, chaindb.ErrKeyNotFound) {
+			return nil, fmt.Errorf("getting justification for block %s: %w", header.Hash(), err)
+		}
+
+		if header.Number == finalisedNumber {
+			return nil, fmt.Errorf("%w: for blocks %d to %d",
+				errNoJustification, header.Number-uint(len(proof.Headers)), finalisedNumber)
+		}
+
+		nextNumber := header.Number + 1
+		header, err = s.blockState.GetHeaderByNumber(nextNumber)
+		if err != nil {
+			return nil, fmt.Errorf("getting header for block number %d: %w", nextNumber, err)
+		}
+		proof.Headers = append(proof.Headers, *header)
+	}
+}
+
+// remoteReadChildResp returns a proof of the child trie root in the state trie
+// together with a proof of the requested keys in the child trie, including
+// the keys absent from the child trie.
+func (s *Service) remoteReadChildResp(req *RemoteReadChildRequest) (*RemoteReadResponse, error) {
+	blockHash := common.BytesToHash(req.Block)
+	stateRoot, err := s.storageState.GetStateRootFromBlock(&blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("getting state root for block %s: %w", blockHash, err)
+	}
+
+	encodedProofNodes, err := s.generateChildProofNodes(stateRoot, req.StorageKey, req.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := scale.Marshal(encodedProofNodes)
+	if err != nil {
+		return nil, fmt.Errorf("encoding proof: %w", err)
+	}
+
+	return &RemoteReadResponse{Proof: proof}, nil
 }
-func remoteReadChildResp(_ *RemoteReadChildRequest) (*RemoteReadResponse, error) {
-	return &RemoteReadResponse{}, nil
+
+// remoteReadResp returns a proof of the requested keys in the state trie,
+// including the keys absent from the state trie.
+func (s *Service) remoteReadResp(req *RemoteReadRequest) (*RemoteReadResponse, error) {
+	blockHash := common.BytesToHash(req.Block)
+	stateRoot, err := s.storageState.GetStateRootFromBlock(&blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("getting state root for block %s: %w", blockHash, err)
+	}
+
+	encodedProofNodes, err := s.generateProofNodes(*stateRoot, req.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := scale.Marshal(encodedProofNodes)
+	if err != nil {
+		return nil, fmt.Errorf("encoding proof: %w", err)
+	}
+
+	return &RemoteReadResponse{Proof: proof}, nil
 }
-func remoteReadResp(_ *RemoteReadRequest) (*RemoteReadResponse, error) {
-	return &RemoteReadResponse{}, nil
+
+// generateProofNodes generates the encoded proof nodes for the keys given
+// in the trie with the given root, proving absent keys absent.
+func (s *Service) generateProofNodes(root common.Hash, keys [][]byte) (
+	encodedProofNodes [][]byte, err error) {
+	encodedProofNodes = [][]byte{}
+	if len(keys) == 0 {
+		return encodedProofNodes, nil
+	}
+
+	encodedProofNodes, err = s.storageState.GenerateTrieProofWithAbsentKeys(root, keys)
+	if err != nil {
+		return nil, fmt.Errorf("generating proof: %w", err)
+	}
+	return encodedProofNodes, nil
+}
+
+// generateChildProofNodes generates the encoded proof nodes of the child trie
+// root in the state trie with the given root, together with the encoded
+// proof nodes of the keys given in the child trie. If the child trie does not
+// exist, only the proof of absence of the child trie root is returned.
+func (s *Service) generateChildProofNodes(stateRoot *common.Hash, keyToChild []byte,
+	keys [][]byte) (encodedProofNodes [][]byte, err error) {
+	childRootKey := make([]byte, 0, len(trie.ChildStorageKeyPrefix)+len(keyToChild))
+	childRootKey = append(childRootKey, trie.ChildStorageKeyPrefix...)
+	childRootKey = append(childRootKey, keyToChild...)
+	encodedProofNodes, err = s.generateProofNodes(*stateRoot, [][]byte{childRootKey})
+	if err != nil {
+		return nil, fmt.Errorf("for child trie root key 0x%x: %w", childRootKey, err)
+	}
+
+	childTrie, err := s.storageState.GetStorageChild(stateRoot, keyToChild)
+	if errors.Is(err, trie.ErrChildTrieDoesNotExist) {
+		return encodedProofNodes, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("getting child trie at key 0x%x: %w", keyToChild, err)
+	}
+
+	childRoot, err := childTrie.Hash()
+	if err != nil {
+		return nil, fmt.Errorf("hashing child trie: %w", err)
+	}
+
+	childEncodedProofNodes, err := s.generateProofNodes(childRoot, keys)
+	if err != nil {
+		return nil, fmt.Errorf("for child trie keys: %w", err)
+	}
+
+	return append(encodedProofNodes, childEncodedProofNodes...), nil
+}
+
+// readRecorder is a runtime storage recording the keys accessed through it,
+// in order to generate a proof of execution of a runtime call.
+// Keys written are only recorded once the runtime computes the storage root,
+// since the trie nodes on their path are needed to recompute the root.
+type readRecorder struct {
+	runtime.Storage
+	keys    [][]byte
+	keysSet map[string]struct{}
+	// childTrieKeys are the keys of the child tries accessed, in order.
+	childTrieKeys []string
+	// childKeys maps the key of each child trie accessed to the keys read in it.
+	childKeys    map[string][][]byte
+	childKeysSet map[string]map[string]struct{}
+	// writtenKeys are the keys written to the state trie, in order.
+	writtenKeys    [][]byte
+	writtenKeysSet map[string]struct{}
+	// writtenChildKeys maps the key of each child trie written to
+	// the keys written in it.
+	writtenChildKeys    map[string][][]byte
+	writtenChildKeysSet map[string]map[string]struct{}
+	// writtenChildTrieKeys are the keys of the child tries written, in order.
+	writtenChildTrieKeys []string
+}
+
+func newReadRecorder(trieState *rtstorage.TrieState) *readRecorder {
+	return &readRecorder{
+		Storage:             trieState,
+		keysSet:             make(map[string]struct{}),
+		childKeys:           make(map[string][][]byte),
+		childKeysSet:        make(map[string]map[string]struct{}),
+		writtenKeysSet:      make(map[string]struct{}),
+		writtenChildKeys:    make(map[string][][]byte),
+		writtenChildKeysSet: make(map[string]map[string]struct{}),
+	}
+}
+
+func (r *readRecorder) record(key []byte) {
+	r.keys = recordKey(r.keys, r.keysSet, key)
+}
+
+// recordChildTrie records the child trie at the given key, such that
+// its root is proven in the state trie.
+func (r *readRecorder) recordChildTrie(keyToChild []byte) (keysSet map[string]struct{}) {
+	keysSet, ok := r.childKeysSet[string(keyToChild)]
+	if !ok {
+		keysSet = make(map[string]struct{})
+		r.childKeysSet[string(keyToChild)] = keysSet
+		r.childTrieKeys = append(r.childTrieKeys, string(keyToChild))
+	}
+	return keysSet
+}
+
+func (r *readRecorder) recordChild(keyToChild, key []byte) {
+	keysSet := r.recordChildTrie(keyToChild)
+	r.childKeys[string(keyToChild)] = recordKey(r.childKeys[string(keyToChild)], keysSet, key)
+}
+
+// recordPrefix records the key given as prefix, and the keys
+// following it in the state trie, up to the first key not starting
+// with the prefix or up to limit+1 keys starting with the prefix.
+// This is enough to prove which keys a prefix deletion deletes, and
+// whether all the keys with the prefix are deleted.
+func (r *readRecorder) recordPrefix(prefix []byte, limit uint32) {
+	r.record(prefix)
+	key := prefix
+	for recorded := uint64(0); recorded <= uint64(limit); recorded++ {
+		key = r.NextKey(key)
+		if key == nil || !bytes.HasPrefix(key, prefix) {
+			return
+		}
+	}
+}
+
+// recordChildPrefix is the child trie equivalent of recordPrefix.
+func (r *readRecorder) recordChildPrefix(keyToChild, prefix []byte, limit uint32) {
+	r.recordChild(keyToChild, prefix)
+	key := prefix
+	for recorded := uint64(0); recorded <= uint64(limit); recorded++ {
+		var err error
+		key, err = r.GetChildNextKey(keyToChild, key)
+		if err != nil || key == nil || !bytes.HasPrefix(key, prefix) {
+			return
+		}
+	}
+}
+
+func (r *readRecorder) recordWrite(key []byte) {
+	r.writtenKeys = recordKey(r.writtenKeys, r.writtenKeysSet, key)
+}
+
+func (r *readRecorder) recordChildWrite(keyToChild, key []byte) {
+	keysSet, ok := r.writtenChildKeysSet[string(keyToChild)]
+	if !ok {
+		keysSet = make(map[string]struct{})
+		r.writtenChildKeysSet[string(keyToChild)] = keysSet
+		r.writtenChildTrieKeys = append(r.writtenChildTrieKeys, string(keyToChild))
+	}
+	if key == nil {
+		return
+	}
+	r.writtenChildKeys[string(keyToChild)] = recordKey(r.writtenChildKeys[string(keyToChild)], keysSet, key)
+}
+
+// recordKey appends a copy of the key given to the keys given,
+// if the key is not already in the keys set given.
+func recordKey(keys [][]byte, keysSet map[string]struct{}, key []byte) (
+	updatedKeys [][]byte) {
+	if _, has := keysSet[string(key)]; has {
+		return keys
+	}
+	keysSet[string(key)] = struct{}{}
+	keyCopy := make([]byte, len(key))
+	copy(keyCopy, key)
+	return append(keys, keyCopy)
+}
+
+// Get gets the value at the given key and records the key.
+func (r *readRecorder) Get(key []byte) []byte {
+	r.record(key)
+	return r.Storage.Get(key)
+}
+
+// NextKey returns the next key and records both the key given and the
+// next key, so the proof covers the range of keys between the two.
+func (r *readRecorder) NextKey(key []byte) []byte {
+	r.record(key)
+	nextKey := r.Storage.NextKey(key)
+	if nextKey != nil {
+		r.record(nextKey)
+	}
+	return nextKey
+}
+
+// Put puts the value at the given key and records the key as written.
+func (r *readRecorder) Put(key, value []byte) (err error) {
+	r.recordWrite(key)
+	return r.Storage.Put(key, value)
+}
+
+// Delete deletes the given key and records the key as written.
+func (r *readRecorder) Delete(key []byte) (err error) {
+	r.recordWrite(key)
+	return r.Storage.Delete(key)
+}
+
+// ClearPrefix records the keys starting with the given prefix
+// and deletes them.
+func (r *readRecorder) ClearPrefix(prefix []byte) (err error) {
+	r.recordPrefix(prefix, math.MaxUint32)
+	return r.Storage.ClearPrefix(prefix)
+}
+
+// ClearPrefixLimit records the keys starting with the given prefix
+// up to the limit given, and deletes them.
+func (r *readRecorder) ClearPrefixLimit(prefix []byte, limit uint32) (
+	deleted uint32, allDeleted bool, err error) {
+	r.recordPrefix(prefix, limit)
+	return r.Storage.ClearPrefixLimit(prefix, limit)
+}
+
+// Root records all the keys written so far, which are needed to
+// recompute the root, and returns the root hash of the state trie.
+func (r *readRecorder) Root() (common.Hash, error) {
+	for _, key := range r.writtenKeys {
+		r.record(key)
+	}
+	for _, keyToChild := range r.writtenChildTrieKeys {
+		r.recordChildTrie([]byte(keyToChild))
+		for _, key := range r.writtenChildKeys[keyToChild] {
+			r.recordChild([]byte(keyToChild), key)
+		}
+	}
+	return r.Storage.Root()
+}
+
+// GetChild gets the child trie at the given key and records it.
+func (r *readRecorder) GetChild(keyToChild []byte) (*trie.Trie, error) {
+	r.recordChildTrie(keyToChild)
+	return r.Storage.GetChild(keyToChild)
+}
+
+// SetChild sets the child trie at the given key and records it as written.
+func (r *readRecorder) SetChild(keyToChild []byte, child *trie.Trie) error {
+	r.recordChildWrite(keyToChild, nil)
+	return r.Storage.SetChild(keyToChild, child)
+}
+
+// DeleteChild deletes the child trie at the given key and records it.
+func (r *readRecorder) DeleteChild(keyToChild []byte) (err error) {
+	r.recordChildTrie(keyToChild)
+	return r.Storage.DeleteChild(keyToChild)
+}
+
+// DeleteChildLimit records the child trie at the given key together with
+// the keys to delete in it, and deletes them.
+func (r *readRecorder) DeleteChildLimit(keyToChild []byte, limit *[]byte) (
+	deleted uint32, allDeleted bool, err error) {
+	if limit == nil {
+		r.recordChildTrie(keyToChild)
+	} else if len(*limit) >= 4 {
+		r.recordChildPrefix(keyToChild, nil, binary.LittleEndian.Uint32(*limit))
+	}
+	return r.Storage.DeleteChildLimit(keyToChild, limit)
+}
+
+// GetChildStorage gets the value at the given key in the given child trie
+// and records the key.
+func (r *readRecorder) GetChildStorage(keyToChild, key []byte) ([]byte, error) {
+	r.recordChild(keyToChild, key)
+	return r.Storage.GetChildStorage(keyToChild, key)
+}
+
+// SetChildStorage sets the value at the given key in the given child trie
+// and records the key as written.
+func (r *readRecorder) SetChildStorage(keyToChild, key, value []byte) error {
+	r.recordChildWrite(keyToChild, key)
+	return r.Storage.SetChildStorage(keyToChild, key, value)
+}
+
+// ClearChildStorage deletes the given key in the given child trie
+// and records the key as written.
+func (r *readRecorder) ClearChildStorage(keyToChild, key []byte) error {
+	r.recordChildWrite(keyToChild, key)
+	return r.Storage.ClearChildStorage(keyToChild, key)
+}
+
+// ClearPrefixInChild records the keys starting with the given prefix
+// in the given child trie, and deletes them.
+func (r *readRecorder) ClearPrefixInChild(keyToChild, prefix []byte) error {
+	r.recordChildPrefix(keyToChild, prefix, math.MaxUint32)
+	return r.Storage.ClearPrefixInChild(keyToChild, prefix)
+}
+
+// GetChildNextKey returns the next key in the given child trie and records
+// both the key given and the next key.
+func (r *readRecorder) GetChildNextKey(keyToChild, key []byte) ([]byte, error) {
+	r.recordChild(keyToChild, key)
+	nextKey, err := r.Storage.GetChildNextKey(keyToChild, key)
+	if err == nil && nextKey != nil {
+		r.recordChild(keyToChild, nextKey)
+	}
+	return nextKey, err
 }
@@ -33,7 +33,7 @@ func TestEncodeLightRequest(t *testing.T) {
 
 func TestEncodeLightResponse(t *testing.T) {
 	t.Parallel()
-	exp := common.MustHexToBytes("0x00000000000000")
+	exp := common.MustHexToBytes("0x0000000000000000")
 
 	testLightResponse := NewLightResponse()
 	enc, err := testLightResponse.Encode()
@@ -0,0 +1,319 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Service_remoteHeaderResp(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+	header1 := &types.Header{Number: 1, Digest: types.NewDigest()}
+	header2 := &types.Header{ParentHash: header1.Hash(), Number: 2, Digest: types.NewDigest()}
+
+	testCases := map[string]struct {
+		blockStateBuilder func(ctrl *gomock.Controller) BlockState
+		request           *RemoteHeaderRequest
+		response          *RemoteHeaderResponse
+		errWrapped        error
+		errMessage        string
+	}{
+		"bad_block_number": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				return NewMockBlockState(ctrl)
+			},
+			request:    &RemoteHeaderRequest{Block: []byte{}},
+			errWrapped: io.EOF,
+			errMessage: "decoding block number: reading byte: EOF",
+		},
+		"block_not_finalised": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().GetHighestFinalisedHeader().
+					Return(&types.Header{Number: 1}, nil)
+				return blockState
+			},
+			request:    &RemoteHeaderRequest{Block: scale.MustMarshal(uint(2))},
+			errWrapped: errBlockNotFinalised,
+			errMessage: "block is not finalised: block number 2 is greater than finalised block number 1",
+		},
+		"get_header_error": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().GetHighestFinalisedHeader().
+					Return(&types.Header{Number: 2}, nil)
+				blockState.EXPECT().GetHeaderByNumber(uint(2)).Return(nil, errTest)
+				return blockState
+			},
+			request:    &RemoteHeaderRequest{Block: scale.MustMarshal(uint(2))},
+			errWrapped: errTest,
+			errMessage: "getting header for block number 2: test error",
+		},
+		"justification_error": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().GetHighestFinalisedHeader().
+					Return(&types.Header{Number: 2}, nil)
+				blockState.EXPECT().GetHeaderByNumber(uint(1)).Return(header1, nil)
+				blockState.EXPECT().GetJustification(header1.Hash()).Return(nil, errTest)
+				return blockState
+			},
+			request:    &RemoteHeaderRequest{Block: scale.MustMarshal(uint(1))},
+			errWrapped: errTest,
+			errMessage: "generating header proof: getting justification for block " +
+				header1.Hash().String() + ": test error",
+		},
+		"no_justification": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().GetHighestFinalisedHeader().
+					Return(&types.Header{Number: 2}, nil)
+				blockState.EXPECT().GetHeaderByNumber(uint(1)).Return(header1, nil)
+				blockState.EXPECT().GetJustification(header1.Hash()).
+					Return(nil, chaindb.ErrKeyNotFound)
+				blockState.EXPECT().GetHeaderByNumber(uint(2)).Return(header2, nil)
+				blockState.EXPECT().GetJustification(header2.Hash()).
+					Return(nil, chaindb.ErrKeyNotFound)
+				return blockState
+			},
+			request:    &RemoteHeaderRequest{Block: scale.MustMarshal(uint(1))},
+			errWrapped: errNoJustification,
+			errMessage: "generating header proof: no justification found: for blocks 1 to 2",
+		},
+		"justified_header": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().GetHighestFinalisedHeader().
+					Return(&types.Header{Number: 2}, nil)
+				blockState.EXPECT().GetHeaderByNumber(uint(1)).Return(header1, nil)
+				blockState.EXPECT().GetJustification(header1.Hash()).Return([]byte{1}, nil)
+				return blockState
+			},
+			request: &RemoteHeaderRequest{Block: scale.MustMarshal(uint(1))},
+			response: &RemoteHeaderResponse{
+				Header: []*types.Header{header1},
+				Proof:  scale.MustMarshal(headerProof{Justification: []byte{1}}),
+			},
+		},
+		"justified_descendant": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().GetHighestFinalisedHeader().
+					Return(&types.Header{Number: 2}, nil)
+				blockState.EXPECT().GetHeaderByNumber(uint(1)).Return(header1, nil)
+				blockState.EXPECT().GetJustification(header1.Hash()).
+					Return(nil, chaindb.ErrKeyNotFound)
+				blockState.EXPECT().GetHeaderByNumber(uint(2)).Return(header2, nil)
+				blockState.EXPECT().GetJustification(header2.Hash()).Return([]byte{2}, nil)
+				return blockState
+			},
+			request: &RemoteHeaderRequest{Block: scale.MustMarshal(uint(1))},
+			response: &RemoteHeaderResponse{
+				Header: []*types.Header{header1},
+				Proof: scale.MustMarshal(headerProof{
+					Headers:       []types.Header{*header2},
+					Justification: []byte{2},
+				}),
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			service := &Service{
+				blockState: testCase.blockStateBuilder(ctrl),
+			}
+
+			response, err := service.remoteHeaderResp(testCase.request)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.response, response)
+		})
+	}
+}
+
+func Test_Service_remoteReadResp(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+	blockHash := common.Hash{1}
+	stateRoot := common.Hash{2}
+
+	testCases := map[string]struct {
+		storageStateBuilder func(ctrl *gomock.Controller) StorageState
+		request             *RemoteReadRequest
+		response            *RemoteReadResponse
+		errWrapped          error
+		errMessage          string
+	}{
+		"state_root_error": {
+			storageStateBuilder: func(ctrl *gomock.Controller) StorageState {
+				storageState := NewMockStorageState(ctrl)
+				storageState.EXPECT().GetStateRootFromBlock(&blockHash).Return(nil, errTest)
+				return storageState
+			},
+			request:    &RemoteReadRequest{Block: blockHash.ToBytes()},
+			errWrapped: errTest,
+			errMessage: "getting state root for block " +
+				"0x0100000000000000000000000000000000000000000000000000000000000000: test error",
+		},
+		"proof_generation_error": {
+			storageStateBuilder: func(ctrl *gomock.Controller) StorageState {
+				storageState := NewMockStorageState(ctrl)
+				storageState.EXPECT().GetStateRootFromBlock(&blockHash).Return(&stateRoot, nil)
+				storageState.EXPECT().GenerateTrieProofWithAbsentKeys(stateRoot, [][]byte{[]byte("key")}).
+					Return(nil, errTest)
+				return storageState
+			},
+			request: &RemoteReadRequest{
+				Block: blockHash.ToBytes(),
+				Keys:  [][]byte{[]byte("key")},
+			},
+			errWrapped: errTest,
+			errMessage: "generating proof: test error",
+		},
+		"missing_keys_proven_absent": {
+			storageStateBuilder: func(ctrl *gomock.Controller) StorageState {
+				storageState := NewMockStorageState(ctrl)
+				storageState.EXPECT().GetStateRootFromBlock(&blockHash).Return(&stateRoot, nil)
+				storageState.EXPECT().GenerateTrieProofWithAbsentKeys(stateRoot,
+					[][]byte{[]byte("key"), []byte("missing")}).
+					Return([][]byte{{1}, {2}}, nil)
+				return storageState
+			},
+			request: &RemoteReadRequest{
+				Block: blockHash.ToBytes(),
+				Keys:  [][]byte{[]byte("key"), []byte("missing")},
+			},
+			response: &RemoteReadResponse{
+				Proof: scale.MustMarshal([][]byte{{1}, {2}}),
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			service := &Service{
+				storageState: testCase.storageStateBuilder(ctrl),
+			}
+
+			response, err := service.remoteReadResp(testCase.request)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.response, response)
+		})
+	}
+}
+
+func Test_readRecorder(t *testing.T) {
+	t.Parallel()
+
+	tr := trie.NewEmptyTrie()
+	tr.Put([]byte("a"), []byte{1})
+	tr.Put([]byte("b"), []byte{2})
+	childTrie := trie.NewEmptyTrie()
+	childTrie.Put([]byte("c"), []byte{3})
+	err := tr.SetChild([]byte("child"), childTrie)
+	require.NoError(t, err)
+	recorder := newReadRecorder(rtstorage.NewTrieState(tr))
+
+	value := recorder.Get([]byte("a"))
+	require.Equal(t, []byte{1}, value)
+	_ = recorder.Get([]byte("a"))
+	nextKey := recorder.NextKey([]byte("a"))
+	require.Equal(t, []byte("b"), nextKey)
+	_ = recorder.Get([]byte("absent"))
+
+	expectedKeys := [][]byte{[]byte("a"), []byte("b"), []byte("absent")}
+	assert.Equal(t, expectedKeys, recorder.keys)
+
+	value, err = recorder.GetChildStorage([]byte("child"), []byte("c"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{3}, value)
+	nextKey, err = recorder.GetChildNextKey([]byte("child"), []byte("b"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("c"), nextKey)
+
+	assert.Equal(t, []string{"child"}, recorder.childTrieKeys)
+	expectedChildKeys := map[string][][]byte{
+		"child": {[]byte("c"), []byte("b")},
+	}
+	assert.Equal(t, expectedChildKeys, recorder.childKeys)
+}
+
+func Test_readRecorder_deletionsAndRoot(t *testing.T) {
+	t.Parallel()
+
+	tr := trie.NewEmptyTrie()
+	tr.Put([]byte("pa"), []byte{1})
+	tr.Put([]byte("pb"), []byte{2})
+	tr.Put([]byte("q"), []byte{3})
+	childTrie := trie.NewEmptyTrie()
+	childTrie.Put([]byte("c"), []byte{4})
+	childTrie.Put([]byte("d"), []byte{5})
+	err := tr.SetChild([]byte("child"), childTrie)
+	require.NoError(t, err)
+	recorder := newReadRecorder(rtstorage.NewTrieState(tr))
+
+	deleted, allDeleted, err := recorder.ClearPrefixLimit([]byte("p"), 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), deleted)
+	assert.False(t, allDeleted)
+	expectedKeys := [][]byte{[]byte("p"), []byte("pa"), []byte("pb")}
+	assert.Equal(t, expectedKeys, recorder.keys)
+
+	err = recorder.Put([]byte("w"), []byte{6})
+	require.NoError(t, err)
+	err = recorder.SetChildStorage([]byte("child"), []byte("e"), []byte{7})
+	require.NoError(t, err)
+	// Written keys are only recorded once the root is computed.
+	assert.Equal(t, expectedKeys, recorder.keys)
+	assert.Empty(t, recorder.childTrieKeys)
+
+	_, err = recorder.Root()
+	require.NoError(t, err)
+	expectedKeys = append(expectedKeys, []byte("w"))
+	assert.Equal(t, expectedKeys, recorder.keys)
+	assert.Equal(t, []string{"child"}, recorder.childTrieKeys)
+	assert.Equal(t, map[string][][]byte{"child": {[]byte("e")}}, recorder.childKeys)
+
+	_, err = recorder.GetChild([]byte("other"))
+	require.ErrorIs(t, err, trie.ErrChildTrieDoesNotExist)
+	err = recorder.ClearPrefixInChild([]byte("child"), []byte("c"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"child", "other"}, recorder.childTrieKeys)
+	expectedChildKeys := map[string][][]byte{
+		"child": {[]byte("e"), []byte("c"), []byte("d")},
+	}
+	assert.Equal(t, expectedChildKeys, recorder.childKeys)
+}
@@ -32,6 +32,8 @@
 	// the following are sub-protocols used by the node
 	syncID          = "/sync/2"
 	lightID         = "/light/2"
+	warpSyncID      = "/sync/warp"
+	stateID         = "/state/2"
 	blockAnnounceID = "/block-announces/1"
 	transactionsID  = "/transactions/1"
 
@@ -120,6 +122,8 @@ type Service struct {
 	blockState         BlockState
 	syncer             Syncer
 	transactionHandler TransactionHandler
+	storageState       StorageState
+	warpSyncProvider   WarpSyncProvider
 
 	// Configuration options
 	noBootstrap bool
@@ -204,6 +208,8 @@ func NewService(cfg *Config) (*Service, error) {
 		mdns:                   mdnsService,
 		gossip:                 newGossip(),
 		blockState:             cfg.BlockState,
+		storageState:           cfg.StorageState,
+		warpSyncProvider:       cfg.WarpSyncProvider,
 		transactionHandler:     cfg.TransactionHandler,
 		noBootstrap:            cfg.NoBootstrap,
 		noMDNS:                 cfg.NoMDNS,
@@ -248,6 +254,10 @@ func (s *Service) Start() error {
 
 	s.host.registerStreamHandler(s.host.protocolID+syncID, s.handleSyncStream)
 	s.host.registerStreamHandler(s.host.protocolID+lightID, s.handleLightStream)
+	s.host.registerStreamHandler(s.host.protocolID+stateID, s.handleStateStream)
+	if s.warpSyncProvider != nil {
+		s.host.registerStreamHandler(s.host.protocolID+warpSyncID, s.handleWarpSyncStream)
+	}
 
 	// register block announce protocol
 	err := s.RegisterNotificationsProtocol(
@@ -11,6 +11,9 @@
 	"github.com/ChainSafe/gossamer/dot/peerset"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
 )
 
 // BlockState interface for block state methods
@@ -18,6 +21,25 @@ type BlockState interface {
 	BestBlockHeader() (*types.Header, error)
 	GenesisHash() common.Hash
 	GetHighestFinalisedHeader() (*types.Header, error)
+	GetHeaderByNumber(num uint) (*types.Header, error)
+	GetJustification(hash common.Hash) ([]byte, error)
+	CallRuntime(blockHash common.Hash, storage runtime.Storage,
+		function string, data []byte) (result []byte, err error)
+}
+
+// StorageState is the interface for storage state methods
+// used to answer light client requests.
+type StorageState interface {
+	GetStateRootFromBlock(blockHash *common.Hash) (*common.Hash, error)
+	TrieState(root *common.Hash) (*rtstorage.TrieState, error)
+	GetStorageChild(root *common.Hash, keyToChild []byte) (*trie.Trie, error)
+	GenerateTrieProof(stateRoot common.Hash, keys [][]byte) ([][]byte, error)
+	GenerateTrieProofWithAbsentKeys(stateRoot common.Hash, keys [][]byte) ([][]byte, error)
+}
+
+// WarpSyncProvider is the interface used to answer warp sync proof requests.
+type WarpSyncProvider interface {
+	Generate(start common.Hash) (encodedProof []byte, err error)
 }
 
 // Syncer is implemented by the syncing service
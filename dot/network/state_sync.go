@@ -0,0 +1,414 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/ChainSafe/gossamer/dot/network/proto"
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// maxStateResponseEntriesSize is the maximum total size of the keys
+	// and values sent in a state response, excluding the proof.
+	maxStateResponseEntriesSize = 2 * 1024 * 1024 // 2mb
+
+	// maxStateResponseSize is the maximum size of a state response, including the proof.
+	maxStateResponseSize uint64 = 1024 * 1024 * 16 // 16mb
+
+	stateRequestTimeout = time.Second * 30
+)
+
+// StateRequest is a request for the storage entries of the state of a block.
+type StateRequest struct {
+	// Block is the hash of the block to get the state from.
+	Block common.Hash
+	// Start is empty to start from the first key of the state, or contains
+	// the top trie key after which to continue. It contains two keys to continue
+	// in the child trie stored at the first key, after the second key.
+	Start [][]byte
+	// NoProof is true if the response should not contain a Merkle proof of the entries.
+	NoProof bool
+}
+
+// Encode returns the protobuf encoded StateRequest
+func (r *StateRequest) Encode() ([]byte, error) {
+	msg := &pb.StateRequest{
+		Block:   r.Block.ToBytes(),
+		Start:   r.Start,
+		NoProof: r.NoProof,
+	}
+	return proto.Marshal(msg)
+}
+
+// Decode decodes the protobuf encoded input to a StateRequest
+func (r *StateRequest) Decode(in []byte) error {
+	msg := &pb.StateRequest{}
+	err := proto.Unmarshal(in, msg)
+	if err != nil {
+		return err
+	}
+
+	if len(msg.Block) != common.HashLength {
+		return fmt.Errorf("%w: %d bytes", errInvalidStateRequestBlock, len(msg.Block))
+	}
+
+	if len(msg.Start) > 2 {
+		return fmt.Errorf("%w: %d keys", errInvalidStateRequestStart, len(msg.Start))
+	}
+
+	r.Block = common.BytesToHash(msg.Block)
+	r.Start = msg.Start
+	r.NoProof = msg.NoProof
+	return nil
+}
+
+// String formats a StateRequest as a string
+func (r *StateRequest) String() string {
+	return fmt.Sprintf("StateRequest Block=%s Start=%s NoProof=%t",
+		r.Block, formatKeys(r.Start), r.NoProof)
+}
+
+// StateEntry is a key value pair of the state.
+type StateEntry struct {
+	Key   []byte
+	Value []byte
+}
+
+// KeyValueStateEntry contains the entries of the top trie or of a child trie.
+type KeyValueStateEntry struct {
+	// StateRoot is empty for the top trie, and is the root hash of the child trie otherwise.
+	StateRoot []byte
+	Entries   []StateEntry
+	// Complete is true if there are no more entries in the trie.
+	Complete bool
+}
+
+// StateResponse is a response to a StateRequest.
+type StateResponse struct {
+	// Entries contains the entries of the top trie first,
+	// followed by the entries of the child tries.
+	Entries []KeyValueStateEntry
+	// Proof contains the encoded trie proof nodes of all the entries,
+	// unless the request had NoProof set.
+	Proof [][]byte
+}
+
+// Encode returns the protobuf encoded StateResponse
+func (r *StateResponse) Encode() ([]byte, error) {
+	msg := &pb.StateResponse{
+		Entries: make([]*pb.KeyValueStateEntry, len(r.Entries)),
+	}
+
+	for i, keyValueStateEntry := range r.Entries {
+		entries := make([]*pb.StateEntry, len(keyValueStateEntry.Entries))
+		for j, entry := range keyValueStateEntry.Entries {
+			entries[j] = &pb.StateEntry{
+				Key:   entry.Key,
+				Value: entry.Value,
+			}
+		}
+
+		msg.Entries[i] = &pb.KeyValueStateEntry{
+			StateRoot: keyValueStateEntry.StateRoot,
+			Entries:   entries,
+			Complete:  keyValueStateEntry.Complete,
+		}
+	}
+
+	if len(r.Proof) > 0 {
+		proof, err := scale.Marshal(r.Proof)
+		if err != nil {
+			return nil, fmt.Errorf("encoding proof: %w", err)
+		}
+		msg.Proof = proof
+	}
+
+	return proto.Marshal(msg)
+}
+
+// Decode decodes the protobuf encoded input to a StateResponse
+func (r *StateResponse) Decode(in []byte) error {
+	msg := &pb.StateResponse{}
+	err := proto.Unmarshal(in, msg)
+	if err != nil {
+		return err
+	}
+
+	r.Entries = make([]KeyValueStateEntry, len(msg.Entries))
+	for i, keyValueStateEntry := range msg.Entries {
+		entries := make([]StateEntry, len(keyValueStateEntry.Entries))
+		for j, entry := range keyValueStateEntry.Entries {
+			entries[j] = StateEntry{
+				Key:   entry.Key,
+				Value: entry.Value,
+			}
+		}
+
+		r.Entries[i] = KeyValueStateEntry{
+			StateRoot: keyValueStateEntry.StateRoot,
+			Entries:   entries,
+			Complete:  keyValueStateEntry.Complete,
+		}
+	}
+
+	r.Proof = nil
+	if len(msg.Proof) > 0 {
+		err = scale.Unmarshal(msg.Proof, &r.Proof)
+		if err != nil {
+			return fmt.Errorf("decoding proof: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// String formats a StateResponse as a string
+func (r *StateResponse) String() string {
+	numberOfEntries := 0
+	for _, keyValueStateEntry := range r.Entries {
+		numberOfEntries += len(keyValueStateEntry.Entries)
+	}
+	return fmt.Sprintf("StateResponse Tries=%d Entries=%d ProofNodes=%d",
+		len(r.Entries), numberOfEntries, len(r.Proof))
+}
+
+func formatKeys(keys [][]byte) string {
+	formatted := make([]string, len(keys))
+	for i, key := range keys {
+		formatted[i] = common.BytesToHex(key)
+	}
+	return fmt.Sprintf("%v", formatted)
+}
+
+// DoStateRequest sends a state request to the given peer.
+// If a response is received within a certain time period, it is returned,
+// otherwise an error is returned.
+func (s *Service) DoStateRequest(to peer.ID, req *StateRequest) (*StateResponse, error) {
+	s.host.p2pHost.ConnManager().Protect(to, "")
+	defer s.host.p2pHost.ConnManager().Unprotect(to, "")
+
+	ctx, cancel := context.WithTimeout(s.ctx, stateRequestTimeout)
+	defer cancel()
+
+	stream, err := s.host.p2pHost.NewStream(ctx, to, s.host.protocolID+stateID)
+	if err != nil {
+		return nil, fmt.Errorf("opening stream: %w", err)
+	}
+
+	defer func() {
+		err := stream.Close()
+		if err != nil {
+			logger.Warnf("failed to close stream: %s", err)
+		}
+	}()
+
+	err = s.host.writeToStream(stream, req)
+	if err != nil {
+		return nil, fmt.Errorf("writing request: %w", err)
+	}
+
+	buf := make([]byte, maxStateResponseSize)
+	n, err := readStream(stream, &buf, maxStateResponseSize)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	if n == 0 {
+		return nil, fmt.Errorf("received empty message")
+	}
+
+	response := new(StateResponse)
+	err = response.Decode(buf[:n])
+	if err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return response, nil
+}
+
+// handleStateStream handles streams with the <protocol-id>/state/2 protocol ID
+func (s *Service) handleStateStream(stream libp2pnetwork.Stream) {
+	if stream == nil {
+		return
+	}
+
+	s.readStream(stream, decodeStateMessage, s.handleStateMessage, maxBlockResponseSize)
+}
+
+func decodeStateMessage(in []byte, _ peer.ID, _ bool) (Message, error) {
+	msg := new(StateRequest)
+	err := msg.Decode(in)
+	return msg, err
+}
+
+// handleStateMessage answers the state requests received over inbound streams.
+func (s *Service) handleStateMessage(stream libp2pnetwork.Stream, msg Message) error {
+	defer func() {
+		err := stream.Close()
+		if err != nil {
+			logger.Warnf("failed to close stream: %s", err)
+		}
+	}()
+
+	req, ok := msg.(*StateRequest)
+	if !ok {
+		return nil
+	}
+
+	if s.storageState == nil {
+		logger.Debug("ignoring StateRequest since no storage state is set")
+		return nil
+	}
+
+	resp, err := s.createStateResponse(req)
+	if err != nil {
+		logger.Debugf("cannot create response for request %s: %s", req, err)
+		return nil
+	}
+
+	err = s.host.writeToStream(stream, resp)
+	if err != nil {
+		logger.Debugf("failed to send StateResponse message to peer %s: %s", stream.Conn().RemotePeer(), err)
+		return err
+	}
+
+	return nil
+}
+
+// createStateResponse returns the state entries of the requested block following
+// the start keys of the request. The response contains the top trie entries, and the
+// child trie entries of each child trie key found, up to a maximum total size.
+func (s *Service) createStateResponse(req *StateRequest) (*StateResponse, error) {
+	stateRoot, err := s.storageState.GetStateRootFromBlock(&req.Block)
+	if err != nil {
+		return nil, fmt.Errorf("getting state root: %w", err)
+	}
+
+	trieState, err := s.storageState.TrieState(stateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("getting trie state: %w", err)
+	}
+
+	var topStart []byte
+	if len(req.Start) > 0 {
+		topStart = req.Start[0]
+	}
+
+	top := KeyValueStateEntry{}
+	var children []KeyValueStateEntry
+	size := 0
+
+	if len(req.Start) == 2 {
+		child, err := collectChildStateEntries(trieState, req.Start[0], req.Start[1], &size)
+		if err != nil {
+			return nil, fmt.Errorf("collecting child trie entries: %w", err)
+		}
+		children = append(children, *child)
+	}
+
+	for key := topStart; len(children) == 0 || children[len(children)-1].Complete; {
+		if size >= maxStateResponseEntriesSize {
+			break
+		}
+
+		key = trieState.NextKey(key)
+		if key == nil {
+			top.Complete = true
+			break
+		}
+
+		value := trieState.Get(key)
+		top.Entries = append(top.Entries, StateEntry{Key: key, Value: value})
+		size += len(key) + len(value)
+
+		if bytes.HasPrefix(key, trie.ChildStorageKeyPrefix) {
+			child, err := collectChildStateEntries(trieState, key, nil, &size)
+			if err != nil {
+				return nil, fmt.Errorf("collecting child trie entries: %w", err)
+			}
+			children = append(children, *child)
+		}
+	}
+
+	response := &StateResponse{
+		Entries: append([]KeyValueStateEntry{top}, children...),
+	}
+
+	if req.NoProof {
+		return response, nil
+	}
+
+	for _, keyValueStateEntry := range response.Entries {
+		if len(keyValueStateEntry.Entries) == 0 {
+			continue
+		}
+
+		root := *stateRoot
+		if len(keyValueStateEntry.StateRoot) > 0 {
+			root = common.BytesToHash(keyValueStateEntry.StateRoot)
+		}
+
+		keys := make([][]byte, len(keyValueStateEntry.Entries))
+		for i, entry := range keyValueStateEntry.Entries {
+			keys[i] = entry.Key
+		}
+
+		encodedProofNodes, err := s.storageState.GenerateTrieProof(root, keys)
+		if err != nil {
+			return nil, fmt.Errorf("generating proof for trie with root %s: %w", root, err)
+		}
+		response.Proof = append(response.Proof, encodedProofNodes...)
+	}
+
+	return response, nil
+}
+
+// collectChildStateEntries collects the entries of the child trie stored at the
+// given top trie key, following the start key, until the total size reaches
+// the maximum response size.
+func collectChildStateEntries(trieState *rtstorage.TrieState, childStorageKey, start []byte,
+	size *int) (*KeyValueStateEntry, error) {
+	if !bytes.HasPrefix(childStorageKey, trie.ChildStorageKeyPrefix) {
+		return nil, fmt.Errorf("%w: 0x%x", errInvalidChildStorageKey, childStorageKey)
+	}
+	keyToChild := childStorageKey[len(trie.ChildStorageKeyPrefix):]
+
+	child := &KeyValueStateEntry{
+		StateRoot: trieState.Get(childStorageKey),
+	}
+
+	for key := start; *size < maxStateResponseEntriesSize; {
+		var err error
+		key, err = trieState.GetChildNextKey(keyToChild, key)
+		if err != nil {
+			return nil, fmt.Errorf("getting next key: %w", err)
+		}
+
+		if key == nil {
+			child.Complete = true
+			break
+		}
+
+		value, err := trieState.GetChildStorage(keyToChild, key)
+		if err != nil {
+			return nil, fmt.Errorf("getting value: %w", err)
+		}
+
+		child.Entries = append(child.Entries, StateEntry{Key: key, Value: value})
+		*size += len(key) + len(value)
+	}
+
+	return child, nil
+}
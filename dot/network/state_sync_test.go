@@ -0,0 +1,186 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_StateRequest_EncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	request := &StateRequest{
+		Block:   common.Hash{1, 2, 3},
+		Start:   [][]byte{{1}, {2}},
+		NoProof: true,
+	}
+
+	encoded, err := request.Encode()
+	require.NoError(t, err)
+
+	decoded := new(StateRequest)
+	err = decoded.Decode(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, request, decoded)
+
+	request.Start = [][]byte{{1}, {2}, {3}}
+	encoded, err = request.Encode()
+	require.NoError(t, err)
+	err = decoded.Decode(encoded)
+	assert.ErrorIs(t, err, errInvalidStateRequestStart)
+}
+
+func Test_StateResponse_EncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	response := &StateResponse{
+		Entries: []KeyValueStateEntry{{
+			Entries: []StateEntry{{Key: []byte{1}, Value: []byte{2}}},
+		}, {
+			StateRoot: []byte{3},
+			Entries:   []StateEntry{{Key: []byte{4}, Value: []byte{5}}},
+			Complete:  true,
+		}},
+		Proof: [][]byte{{6}, {7, 8}},
+	}
+
+	encoded, err := response.Encode()
+	require.NoError(t, err)
+
+	decoded := new(StateResponse)
+	err = decoded.Decode(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, response, decoded)
+}
+
+func Test_Service_createStateResponse(t *testing.T) {
+	t.Parallel()
+
+	blockHash := common.Hash{1}
+	keyToChild := []byte("child")
+	childStorageKey := append(append([]byte{}, trie.ChildStorageKeyPrefix...), keyToChild...)
+	largeValue := bytes.Repeat([]byte{1}, maxStateResponseEntriesSize)
+
+	newTrieState := func(t *testing.T) *rtstorage.TrieState {
+		child := trie.NewEmptyTrie()
+		require.NoError(t, child.Put([]byte("a"), []byte("child_a")))
+		require.NoError(t, child.Put([]byte("b"), largeValue))
+		require.NoError(t, child.Put([]byte("c"), []byte("child_c")))
+
+		top := trie.NewEmptyTrie()
+		require.NoError(t, top.Put([]byte("a"), []byte("top_a")))
+		require.NoError(t, top.SetChild(keyToChild, child))
+		require.NoError(t, top.Put([]byte("z"), []byte("top_z")))
+		return rtstorage.NewTrieState(top)
+	}
+
+	childRoot := func(t *testing.T, trieState *rtstorage.TrieState) []byte {
+		child, err := trieState.GetChild(keyToChild)
+		require.NoError(t, err)
+		return child.MustHash().ToBytes()
+	}
+
+	errTest := errors.New("test error")
+
+	testCases := map[string]struct {
+		request          *StateRequest
+		expectedResponse func(t *testing.T, trieState *rtstorage.TrieState) *StateResponse
+		proofNodes       [][]byte
+		proofErr         error
+		errMessage       string
+	}{
+		"from_first_key_stops_in_child_trie": {
+			request: &StateRequest{Block: blockHash, NoProof: true},
+			expectedResponse: func(t *testing.T, trieState *rtstorage.TrieState) *StateResponse {
+				return &StateResponse{Entries: []KeyValueStateEntry{{
+					Entries: []StateEntry{{Key: childStorageKey, Value: childRoot(t, trieState)}},
+				}, {
+					StateRoot: childRoot(t, trieState),
+					Entries: []StateEntry{
+						{Key: []byte("a"), Value: []byte("child_a")},
+						{Key: []byte("b"), Value: largeValue},
+					},
+				}}}
+			},
+		},
+		"continue_in_child_trie": {
+			request: &StateRequest{Block: blockHash, Start: [][]byte{childStorageKey, []byte("b")}, NoProof: true},
+			expectedResponse: func(t *testing.T, trieState *rtstorage.TrieState) *StateResponse {
+				return &StateResponse{Entries: []KeyValueStateEntry{{
+					Entries: []StateEntry{
+						{Key: []byte("a"), Value: []byte("top_a")},
+						{Key: []byte("z"), Value: []byte("top_z")},
+					},
+					Complete: true,
+				}, {
+					StateRoot: childRoot(t, trieState),
+					Entries:   []StateEntry{{Key: []byte("c"), Value: []byte("child_c")}},
+					Complete:  true,
+				}}}
+			},
+		},
+		"with_proof": {
+			request:    &StateRequest{Block: blockHash, Start: [][]byte{[]byte("a")}},
+			proofNodes: [][]byte{{1}},
+			expectedResponse: func(t *testing.T, trieState *rtstorage.TrieState) *StateResponse {
+				return &StateResponse{
+					Entries: []KeyValueStateEntry{{
+						Entries:  []StateEntry{{Key: []byte("z"), Value: []byte("top_z")}},
+						Complete: true,
+					}},
+					Proof: [][]byte{{1}},
+				}
+			},
+		},
+		"invalid_child_storage_key": {
+			request:    &StateRequest{Block: blockHash, Start: [][]byte{[]byte("a"), []byte("b")}},
+			errMessage: "collecting child trie entries: key is not a child storage key: 0x61",
+		},
+		"proof_error": {
+			request:  &StateRequest{Block: blockHash, Start: [][]byte{[]byte("a")}},
+			proofErr: errTest,
+			errMessage: "generating proof for trie with root " +
+				"0x0100000000000000000000000000000000000000000000000000000000000000: test error",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			trieState := newTrieState(t)
+			stateRoot := common.Hash{1}
+
+			storageState := NewMockStorageState(ctrl)
+			storageState.EXPECT().GetStateRootFromBlock(&blockHash).Return(&stateRoot, nil)
+			storageState.EXPECT().TrieState(&stateRoot).Return(trieState, nil)
+			if testCase.proofNodes != nil || testCase.proofErr != nil {
+				storageState.EXPECT().GenerateTrieProof(stateRoot, [][]byte{[]byte("z")}).
+					Return(testCase.proofNodes, testCase.proofErr)
+			}
+
+			service := &Service{storageState: storageState}
+
+			response, err := service.createStateResponse(testCase.request)
+
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedResponse(t, trieState), response)
+		})
+	}
+}
@@ -205,15 +205,16 @@ func readStream(stream libp2pnetwork.Stream, bufPointer *[]byte, maxSize uint64)
 		return 0, nil // msg length of 0 is allowed, for example transactions handshake
 	}
 
+	if length > maxSize {
+		logger.Warnf("received message with size %d greater than max size %d, closing stream", length, maxSize)
+		return 0, fmt.Errorf("message size greater than maximum: got %d", length)
+	}
+
 	if length > uint64(len(buf)) {
 		extraBytes := int(length) - len(buf)
 		*bufPointer = append(buf, make([]byte, extraBytes)...) // TODO #2288 use bytes.Buffer instead
 		logger.Warnf("received message with size %d greater than allocated message buffer size %d", length, len(buf))
-	}
-
-	if length > maxSize {
-		logger.Warnf("received message with size %d greater than max size %d, closing stream", length, maxSize)
-		return 0, fmt.Errorf("message size greater than maximum: got %d", length)
+		buf = *bufPointer
 	}
 
 	tot = 0
@@ -0,0 +1,135 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// maxWarpSyncProofResponseSize is the maximum size of a warp sync proof response.
+	maxWarpSyncProofResponseSize uint64 = 1024 * 1024 * 16 // 16mb
+
+	warpSyncRequestTimeout = time.Second * 30
+)
+
+// WarpSyncProofRequest is a request for a warp sync proof
+// starting from the given finalised block hash.
+type WarpSyncProofRequest struct {
+	Begin common.Hash
+}
+
+// Encode returns the SCALE encoding of the warp sync proof request.
+func (r *WarpSyncProofRequest) Encode() ([]byte, error) {
+	return scale.Marshal(*r)
+}
+
+// Decode decodes the SCALE encoded warp sync proof request.
+func (r *WarpSyncProofRequest) Decode(in []byte) error {
+	return scale.Unmarshal(in, r)
+}
+
+// String returns the string representation of the warp sync proof request.
+func (r *WarpSyncProofRequest) String() string {
+	return fmt.Sprintf("WarpSyncProofRequest Begin=%s", r.Begin)
+}
+
+// encodedMessage is an already encoded message written as is to a stream.
+type encodedMessage []byte
+
+func (m encodedMessage) Encode() ([]byte, error) {
+	return m, nil
+}
+
+// DoWarpSyncRequest sends a warp sync proof request to the given peer
+// and returns the SCALE encoded warp sync proof received in response.
+func (s *Service) DoWarpSyncRequest(to peer.ID, req *WarpSyncProofRequest) (encodedProof []byte, err error) {
+	s.host.p2pHost.ConnManager().Protect(to, "")
+	defer s.host.p2pHost.ConnManager().Unprotect(to, "")
+
+	ctx, cancel := context.WithTimeout(s.ctx, warpSyncRequestTimeout)
+	defer cancel()
+
+	stream, err := s.host.p2pHost.NewStream(ctx, to, s.host.protocolID+warpSyncID)
+	if err != nil {
+		return nil, fmt.Errorf("opening stream: %w", err)
+	}
+
+	defer func() {
+		err := stream.Close()
+		if err != nil {
+			logger.Warnf("failed to close stream: %s", err)
+		}
+	}()
+
+	err = s.host.writeToStream(stream, req)
+	if err != nil {
+		return nil, fmt.Errorf("writing request: %w", err)
+	}
+
+	// warp sync requests are rare, so the buffer is allocated for each request
+	// instead of being kept around as for block responses.
+	buf := make([]byte, maxWarpSyncProofResponseSize)
+	n, err := readStream(stream, &buf, maxWarpSyncProofResponseSize)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	if n == 0 {
+		return nil, fmt.Errorf("received empty message")
+	}
+
+	return buf[:n], nil
+}
+
+// handleWarpSyncStream handles streams with the <protocol-id>/sync/warp protocol ID
+func (s *Service) handleWarpSyncStream(stream libp2pnetwork.Stream) {
+	if stream == nil {
+		return
+	}
+
+	s.readStream(stream, decodeWarpSyncMessage, s.handleWarpSyncMessage, maxBlockResponseSize)
+}
+
+func decodeWarpSyncMessage(in []byte, _ peer.ID, _ bool) (Message, error) {
+	msg := new(WarpSyncProofRequest)
+	err := msg.Decode(in)
+	return msg, err
+}
+
+// handleWarpSyncMessage answers the warp sync proof requests received over inbound streams.
+func (s *Service) handleWarpSyncMessage(stream libp2pnetwork.Stream, msg Message) error {
+	defer func() {
+		err := stream.Close()
+		if err != nil {
+			logger.Warnf("failed to close stream: %s", err)
+		}
+	}()
+
+	req, ok := msg.(*WarpSyncProofRequest)
+	if !ok {
+		return nil
+	}
+
+	encodedProof, err := s.warpSyncProvider.Generate(req.Begin)
+	if err != nil {
+		logger.Debugf("cannot create warp sync proof for request %s: %s", req, err)
+		return nil
+	}
+
+	err = s.host.writeToStream(stream, encodedMessage(encodedProof))
+	if err != nil {
+		logger.Debugf("failed to send warp sync proof to peer %s: %s", stream.Conn().RemotePeer(), err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_decodeWarpSyncMessage(t *testing.T) {
+	t.Parallel()
+
+	request := &WarpSyncProofRequest{Begin: common.Hash{1, 2, 3}}
+	encoded, err := request.Encode()
+	require.NoError(t, err)
+	assert.Len(t, encoded, common.HashLength)
+
+	msg, err := decodeWarpSyncMessage(encoded, peer.ID("peer"), true)
+	require.NoError(t, err)
+	assert.Equal(t, request, msg)
+
+	_, err = decodeWarpSyncMessage(encoded[:1], peer.ID("peer"), true)
+	assert.Error(t, err)
+}
@@ -0,0 +1,219 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	gorillajson2 "github.com/gorilla/rpc/v2/json2"
+)
+
+// maxBatchConcurrency is the maximum number of requests of a batch
+// served concurrently.
+const maxBatchConcurrency = 16
+
+// batchHandler serves JSON-RPC batches, which are JSON arrays of requests,
+// by serving each of their requests concurrently with the wrapped handler
+// and responding with the array of their responses, in the order of the
+// requests. Other requests are served by the wrapped handler directly.
+type batchHandler struct {
+	handler http.Handler
+	// maxBatchSize is the maximum number of requests in a batch,
+	// zero meaning batches are not limited.
+	maxBatchSize uint32
+}
+
+func newBatchHandler(handler http.Handler, maxBatchSize uint32) *batchHandler {
+	return &batchHandler{
+		handler:      handler,
+		maxBatchSize: maxBatchSize,
+	}
+}
+
+// errorResponse is an error response written outside of the JSON-RPC codec,
+// such as the response to an invalid batch which has a null id.
+type errorResponse struct {
+	Version string              `json:"jsonrpc"`
+	Error   *gorillajson2.Error `json:"error"`
+	ID      *json.RawMessage    `json:"id"`
+}
+
+func (b *batchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		b.handler.ServeHTTP(w, r)
+		return
+	}
+
+	body, r, err := readRequestBody(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reading request body: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if !body.isBatch {
+		b.handler.ServeHTTP(w, r)
+		return
+	}
+
+	if body.batchErr != nil {
+		writeErrorResponse(w, nil, gorillajson2.E_PARSE, fmt.Sprintf("parsing batch: %s", body.batchErr))
+		return
+	}
+
+	requests := body.batch
+	if len(requests) == 0 {
+		writeErrorResponse(w, nil, gorillajson2.E_INVALID_REQ, "empty batch")
+		return
+	}
+
+	if b.maxBatchSize > 0 && uint64(len(requests)) > uint64(b.maxBatchSize) {
+		writeErrorResponse(w, nil, gorillajson2.E_INVALID_REQ,
+			fmt.Sprintf("batch of %d requests exceeds the maximum batch size of %d",
+				len(requests), b.maxBatchSize))
+		return
+	}
+
+	responses := make([]json.RawMessage, len(requests))
+	semaphore := make(chan struct{}, maxBatchConcurrency)
+	var wg sync.WaitGroup
+	wg.Add(len(requests))
+	for i, request := range requests {
+		semaphore <- struct{}{}
+		go func(i int, request json.RawMessage) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			responses[i] = b.serveBatchRequest(r, request)
+		}(i, request)
+	}
+	wg.Wait()
+
+	// notifications have no response
+	nonEmptyResponses := make([]json.RawMessage, 0, len(responses))
+	for _, response := range responses {
+		if len(response) > 0 {
+			nonEmptyResponses = append(nonEmptyResponses, response)
+		}
+	}
+
+	if len(nonEmptyResponses) == 0 {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(nonEmptyResponses)
+	if err != nil {
+		logger.Debugf("failed to write batch response: %s", err)
+	}
+}
+
+// serveBatchRequest serves the request of a batch, and returns its JSON response
+// which is empty for notifications.
+func (b *batchHandler) serveBatchRequest(batch *http.Request, request json.RawMessage) (
+	response json.RawMessage) {
+	r := batch.Clone(batch.Context())
+	r.Body = io.NopCloser(bytes.NewReader(request))
+	r.ContentLength = int64(len(request))
+
+	recorder := &batchResponseRecorder{header: make(http.Header)}
+	b.handler.ServeHTTP(recorder, r)
+
+	response = bytes.TrimSpace(recorder.body.Bytes())
+	if len(response) > 0 && !json.Valid(response) {
+		// the wrapped handler responds in plain text if it cannot handle the request at all.
+		encoded, err := json.Marshal(errorResponse{
+			Version: "2.0",
+			Error: &gorillajson2.Error{
+				Code:    gorillajson2.E_SERVER,
+				Message: string(response),
+			},
+		})
+		if err != nil {
+			logger.Debugf("failed to encode batch request error: %s", err)
+			return nil
+		}
+		return encoded
+	}
+
+	return response
+}
+
+func writeErrorResponse(w http.ResponseWriter, id *json.RawMessage,
+	code gorillajson2.ErrorCode, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err := json.NewEncoder(w).Encode(errorResponse{
+		Version: "2.0",
+		Error: &gorillajson2.Error{
+			Code:    code,
+			Message: message,
+		},
+		ID: id,
+	})
+	if err != nil {
+		logger.Debugf("failed to write error response: %s", err)
+	}
+}
+
+// requestBodyContextKey is the context key of the body of a HTTP request,
+// set once the body is read and parsed by readRequestBody.
+type requestBodyContextKey struct{}
+
+// requestBody is the body of a HTTP request, parsed if it is a JSON-RPC batch.
+type requestBody struct {
+	isBatch bool
+	// batch is the requests of the batch if the body is a batch.
+	batch []json.RawMessage
+	// batchErr is the error parsing the batch if the body is an invalid batch.
+	batchErr error
+}
+
+// readRequestBody reads the body of the HTTP request given and parses it if it is
+// a JSON-RPC batch. The body is only read and parsed once, and the request returned
+// carries the parsed body in its context for the next handlers, as well as a body
+// which can be read again.
+func readRequestBody(r *http.Request) (body *requestBody, _ *http.Request, err error) {
+	body, ok := r.Context().Value(requestBodyContextKey{}).(*requestBody)
+	if ok {
+		return body, r, nil
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body = &requestBody{isBatch: isBatchRequest(data)}
+	if body.isBatch {
+		body.batchErr = json.Unmarshal(data, &body.batch)
+	}
+
+	r = r.WithContext(context.WithValue(r.Context(), requestBodyContextKey{}, body))
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	return body, r, nil
+}
+
+// isBatchRequest returns true if the request body is a JSON-RPC batch,
+// which is a JSON array of requests.
+func isBatchRequest(body []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(body, " \t\r\n"), []byte("["))
+}
+
+// batchResponseRecorder records the response to a request of a batch.
+type batchResponseRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (b *batchResponseRecorder) Header() http.Header { return b.header }
+
+func (b *batchResponseRecorder) Write(data []byte) (int, error) { return b.body.Write(data) }
+
+func (*batchResponseRecorder) WriteHeader(int) {}
@@ -0,0 +1,160 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_batchHandler_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	// echoHandler responds with the request body, except for notifications
+	// which are requests without id, and delays the first request of the
+	// batches to check responses are ordered as the requests.
+	echoHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		if bytes.Contains(body, []byte(`"id":1`)) {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if !bytes.Contains(body, []byte(`"id"`)) {
+			return
+		}
+		_, err = w.Write(body)
+		require.NoError(t, err)
+	})
+
+	testCases := map[string]struct {
+		maxBatchSize uint32
+		method       string
+		body         string
+		response     string
+	}{
+		"not_a_post_request": {
+			method:   http.MethodGet,
+			body:     `[{"id":1}]`,
+			response: `[{"id":1}]`,
+		},
+		"single_request": {
+			method:   http.MethodPost,
+			body:     `{"id":1}`,
+			response: `{"id":1}`,
+		},
+		"invalid_batch": {
+			method: http.MethodPost,
+			body:   `[{"id":1}`,
+			response: `{"jsonrpc":"2.0","error":{"code":-32700,` +
+				`"message":"parsing batch: unexpected end of JSON input","data":null},"id":null}` + "\n",
+		},
+		"empty_batch": {
+			method:   http.MethodPost,
+			body:     ` []`,
+			response: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"empty batch","data":null},"id":null}` + "\n",
+		},
+		"batch_too_large": {
+			maxBatchSize: 1,
+			method:       http.MethodPost,
+			body:         `[{"id":1},{"id":2}]`,
+			response: `{"jsonrpc":"2.0","error":{"code":-32600,` +
+				`"message":"batch of 2 requests exceeds the maximum batch size of 1","data":null},"id":null}` + "\n",
+		},
+		"batch": {
+			maxBatchSize: 3,
+			method:       http.MethodPost,
+			body:         `[{"id":1},{"method":"notification"},{"id":2}]`,
+			response:     `[{"id":1},{"id":2}]` + "\n",
+		},
+		"notifications_batch": {
+			method: http.MethodPost,
+			body:   `[{"method":"notification"},{"method":"notification"}]`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newBatchHandler(echoHandler, testCase.maxBatchSize)
+			request := httptest.NewRequest(testCase.method, "/", bytes.NewBufferString(testCase.body))
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, testCase.response, recorder.Body.String())
+		})
+	}
+}
+
+func Test_batchHandler_ServeHTTP_concurrency(t *testing.T) {
+	t.Parallel()
+
+	var mutex sync.Mutex
+	var concurrent, maxConcurrent int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		concurrent++
+		if concurrent > maxConcurrent {
+			maxConcurrent = concurrent
+		}
+		mutex.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mutex.Lock()
+		concurrent--
+		mutex.Unlock()
+	})
+
+	const batchSize = 3 * maxBatchConcurrency
+	requests := make([]string, batchSize)
+	for i := range requests {
+		requests[i] = `{"method":"notification"}`
+	}
+	body := "[" + strings.Join(requests, ",") + "]"
+
+	batchHandler := newBatchHandler(handler, batchSize)
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+	batchHandler.ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.LessOrEqual(t, maxConcurrent, maxBatchConcurrency)
+}
+
+func Test_readRequestBody(t *testing.T) {
+	t.Parallel()
+
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`[{"id":1},{"id":2}]`))
+
+	body, request, err := readRequestBody(request)
+	require.NoError(t, err)
+	expectedBody := &requestBody{
+		isBatch: true,
+		batch:   []json.RawMessage{json.RawMessage(`{"id":1}`), json.RawMessage(`{"id":2}`)},
+	}
+	assert.Equal(t, expectedBody, body)
+
+	// the body is parsed only once, and can still be read
+	parsedBody, parsedRequest, err := readRequestBody(request)
+	require.NoError(t, err)
+	assert.Same(t, body, parsedBody)
+	assert.Same(t, request, parsedRequest)
+
+	data, err := io.ReadAll(parsedRequest.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"id":1},{"id":2}]`, string(data))
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"net/http"
+	"strings"
+)
+
+// allOrigins allows requests from any origin when in the list of allowed origins.
+const allOrigins = "*"
+
+// isOriginAllowed returns true if the origin is in the list of allowed origins,
+// or if the list contains "*". Origins are compared case insensitively.
+func isOriginAllowed(origin string, allowedOrigins []string) bool {
+	for _, allowedOrigin := range allowedOrigins {
+		if allowedOrigin == allOrigins || strings.EqualFold(allowedOrigin, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// corsHandler rejects requests from browsers with an origin not in the
+// allowed origins, answers preflight requests and sets the CORS headers
+// on responses to requests from allowed origins.
+type corsHandler struct {
+	handler        http.Handler
+	allowedOrigins []string
+}
+
+func (h *corsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// not a cross origin request from a browser
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Add("Vary", "Origin")
+
+	if !isOriginAllowed(origin, h.allowedOrigins) {
+		logger.Debugf("request from origin %s refused", origin)
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+
+	if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	h.handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_isOriginAllowed(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		origin         string
+		allowedOrigins []string
+		allowed        bool
+	}{
+		"no_allowed_origin": {
+			origin: "https://polkadot.js.org",
+		},
+		"origin_not_allowed": {
+			origin:         "https://polkadot.js.org",
+			allowedOrigins: []string{"http://localhost"},
+		},
+		"origin_allowed": {
+			origin:         "https://Polkadot.js.org",
+			allowedOrigins: []string{"http://localhost", "https://polkadot.js.org"},
+			allowed:        true,
+		},
+		"all_origins_allowed": {
+			origin:         "https://polkadot.js.org",
+			allowedOrigins: []string{"*"},
+			allowed:        true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			allowed := isOriginAllowed(testCase.origin, testCase.allowedOrigins)
+
+			assert.Equal(t, testCase.allowed, allowed)
+		})
+	}
+}
+
+func Test_corsHandler_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	testCases := map[string]struct {
+		method      string
+		headers     map[string]string
+		status      int
+		body        string
+		allowOrigin string
+	}{
+		"no_origin": {
+			method: http.MethodPost,
+			status: http.StatusOK,
+			body:   "ok",
+		},
+		"origin_not_allowed": {
+			method:  http.MethodPost,
+			headers: map[string]string{"Origin": "https://example.com"},
+			status:  http.StatusForbidden,
+			body:    "origin not allowed\n",
+		},
+		"origin_allowed": {
+			method:      http.MethodPost,
+			headers:     map[string]string{"Origin": "https://polkadot.js.org"},
+			status:      http.StatusOK,
+			body:        "ok",
+			allowOrigin: "https://polkadot.js.org",
+		},
+		"preflight_request": {
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://polkadot.js.org",
+				"Access-Control-Request-Method": http.MethodPost,
+			},
+			status:      http.StatusNoContent,
+			allowOrigin: "https://polkadot.js.org",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := &corsHandler{
+				handler:        okHandler,
+				allowedOrigins: []string{"https://polkadot.js.org"},
+			}
+
+			request := httptest.NewRequest(testCase.method, "/", nil)
+			for key, value := range testCase.headers {
+				request.Header.Set(key, value)
+			}
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, testCase.status, recorder.Code)
+			assert.Equal(t, testCase.body, recorder.Body.String())
+			assert.Equal(t, testCase.allowOrigin, recorder.Header().Get("Access-Control-Allow-Origin"))
+		})
+	}
+}
@@ -7,6 +7,8 @@
 	"fmt"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"sync"
 	"time"
 
 	"github.com/ChainSafe/gossamer/dot/rpc/modules"
@@ -25,7 +27,13 @@ type HTTPServer struct {
 	logger       *log.Logger
 	rpcServer    *rpc.Server // Actual RPC call handler
 	serverConfig *HTTPServerConfig
+	rateLimiter  *ipRateLimiter
+
+	wsConnsMutex sync.Mutex
 	wsConns      []*subscription.WSConn
+	// wsUpgrades is the number of websocket connections being upgraded,
+	// which count towards the maximum number of websocket connections.
+	wsUpgrades int
 }
 
 // HTTPServerConfig configures the HTTPServer
@@ -55,6 +63,30 @@ type HTTPServerConfig struct {
 	WSUnsafeExternal    bool
 	WSPort              uint32
 	Modules             []string
+	// MaxBatchSize is the maximum number of requests in a JSON-RPC batch,
+	// zero meaning batches are not limited.
+	MaxBatchSize uint32
+	// WSMaxConnections is the maximum number of websocket connections,
+	// zero meaning connections are not limited.
+	WSMaxConnections uint32
+	// WSMaxSubscriptionsPerConnection is the maximum number of subscriptions
+	// of a websocket connection, zero meaning subscriptions are not limited.
+	WSMaxSubscriptionsPerConnection uint32
+	// MaxRequestSize is the maximum size in bytes of a request,
+	// zero meaning requests are not limited.
+	MaxRequestSize uint64
+	// MaxResponseSize is the maximum size in bytes of a response,
+	// zero meaning responses are not limited.
+	MaxResponseSize uint64
+	// RateLimit is the number of requests per second allowed for each
+	// IP address, zero meaning requests are not limited.
+	RateLimit uint32
+	// RateLimitBurst is the number of requests allowed in a burst for each
+	// IP address, defaulting to the rate limit if zero.
+	RateLimitBurst uint32
+	// CORS is the list of origins allowed to send requests from a browser,
+	// "*" allowing all origins. Origins are not checked if it is empty.
+	CORS []string
 }
 
 func (h *HTTPServerConfig) rpcUnsafeEnabled() bool {
@@ -86,6 +118,10 @@ func NewHTTPServer(cfg *HTTPServerConfig) *HTTPServer {
 		serverConfig: cfg,
 	}
 
+	if cfg.RateLimit > 0 {
+		server.rateLimiter = newIPRateLimiter(cfg.RateLimit, cfg.RateLimitBurst)
+	}
+
 	server.RegisterModules(cfg.Modules)
 	return server
 }
@@ -145,7 +181,7 @@ func (h *HTTPServer) Start() error {
 
 	h.logger.Infof("Starting HTTP Server on host %s and port %d...", h.serverConfig.Host, h.serverConfig.RPCPort)
 	r := mux.NewRouter()
-	r.Handle("/", h.rpcServer)
+	r.Handle("/", h.newHTTPHandler())
 
 	validate := validator.New()
 	// Add custom validator for `common.Hash`
@@ -178,9 +214,47 @@ func (h *HTTPServer) Start() error {
 	return nil
 }
 
+// newHTTPHandler returns the handler of HTTP requests, serving them with the
+// rpc server once checked against the configured origins and limits.
+func (h *HTTPServer) newHTTPHandler() http.Handler {
+	handler := h.newRPCHandler(h.rateLimiter)
+
+	if len(h.serverConfig.CORS) > 0 {
+		handler = &corsHandler{
+			handler:        handler,
+			allowedOrigins: h.serverConfig.CORS,
+		}
+	}
+
+	return handler
+}
+
+// newRPCHandler returns the handler serving requests with the rpc server once
+// checked against the configured size limits and the rate limiter given,
+// which can be nil to not limit the rate of requests.
+func (h *HTTPServer) newRPCHandler(rateLimiter *ipRateLimiter) http.Handler {
+	var handler http.Handler = newBatchHandler(h.rpcServer, h.serverConfig.MaxBatchSize)
+
+	if rateLimiter != nil {
+		handler = &rateLimitHandler{
+			handler: handler,
+			limiter: rateLimiter,
+		}
+	}
+
+	return &sizeLimitHandler{
+		handler:         handler,
+		maxRequestSize:  h.serverConfig.MaxRequestSize,
+		maxResponseSize: h.serverConfig.MaxResponseSize,
+	}
+}
+
 // Stop stops the server
 func (h *HTTPServer) Stop() error {
 	if h.serverConfig.WS {
+		h.wsConnsMutex.Lock()
+		defer h.wsConnsMutex.Unlock()
+
 		// close all channels and websocket connections
 		for _, conn := range h.wsConns {
 			for _, sub := range conn.Subscriptions {
@@ -205,6 +279,12 @@ func (h *HTTPServer) Stop() error {
 func (h *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	var upg = websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if len(h.serverConfig.CORS) > 0 && origin != "" && !isOriginAllowed(origin, h.serverConfig.CORS) {
+				logger.Debugf("websocket request from origin %s refused", origin)
+				return false
+			}
+
 			if !h.serverConfig.exposeWS() {
 				ip, _, err := net.SplitHostPort(r.RemoteAddr)
 				if err != nil {
@@ -225,16 +305,81 @@ func (h *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		logger.Errorf("unable to parse remote address %s: %s", r.RemoteAddr, err)
+		http.Error(w, "unable to parse remote address", http.StatusBadRequest)
+		return
+	}
+
+	if !h.startWSUpgrade() {
+		logger.Debugf("websocket connection from %s refused: too many connections", ip)
+		http.Error(w, "too many websocket connections", http.StatusServiceUnavailable)
+		return
+	}
+
 	ws, err := upg.Upgrade(w, r, nil)
 	if err != nil {
+		h.endWSUpgrade(nil)
 		h.logger.Errorf("websocket upgrade failed: %s", err)
 		return
 	}
 	// create wsConn
 	wsc := NewWSConn(ws, h.serverConfig)
-	h.wsConns = append(h.wsConns, wsc)
+	// requests are rate limited on the websocket connection, so they are
+	// forwarded in-process to the rpc server without rate limiting.
+	wsc.HTTP = &inProcessClient{handler: h.newRPCHandler(nil)}
+	if h.rateLimiter != nil {
+		wsc.RateLimiter = &connRateLimiter{
+			limiter: h.rateLimiter,
+			ip:      ip,
+		}
+	}
+	h.endWSUpgrade(wsc)
 
-	go wsc.HandleConn()
+	go func() {
+		wsc.HandleConn()
+		h.removeWSConn(wsc)
+	}()
+}
+
+// startWSUpgrade returns true and counts the websocket connection being
+// upgraded if the maximum number of connections is not reached.
+func (h *HTTPServer) startWSUpgrade() (ok bool) {
+	h.wsConnsMutex.Lock()
+	defer h.wsConnsMutex.Unlock()
+
+	maxConnections := uint64(h.serverConfig.WSMaxConnections)
+	if maxConnections > 0 && uint64(len(h.wsConns)+h.wsUpgrades) >= maxConnections {
+		return false
+	}
+
+	h.wsUpgrades++
+	return true
+}
+
+// endWSUpgrade ends the upgrade of a websocket connection,
+// adding the connection if the upgrade succeeded.
+func (h *HTTPServer) endWSUpgrade(wsc *subscription.WSConn) {
+	h.wsConnsMutex.Lock()
+	defer h.wsConnsMutex.Unlock()
+
+	h.wsUpgrades--
+	if wsc != nil {
+		h.wsConns = append(h.wsConns, wsc)
+	}
+}
+
+func (h *HTTPServer) removeWSConn(wsc *subscription.WSConn) {
+	h.wsConnsMutex.Lock()
+	defer h.wsConnsMutex.Unlock()
+
+	for i, conn := range h.wsConns {
+		if conn == wsc {
+			h.wsConns = append(h.wsConns[:i], h.wsConns[i+1:]...)
+			return
+		}
+	}
 }
 
 // NewWSConn to create new WebSocket Connection struct
@@ -251,6 +396,30 @@ func NewWSConn(conn *websocket.Conn, cfg *HTTPServerConfig) *subscription.WSConn
 		HTTP: &http.Client{
 			Timeout: time.Second * 30,
 		},
+		MaxBatchSize:     cfg.MaxBatchSize,
+		MaxSubscriptions: cfg.WSMaxSubscriptionsPerConnection,
+		MaxResponseSize:  cfg.MaxResponseSize,
+	}
+
+	if cfg.MaxRequestSize > 0 {
+		conn.SetReadLimit(int64(cfg.MaxRequestSize))
 	}
 	return c
 }
+
+// inProcessClient serves the requests forwarded by websocket
+// connections in-process with the handler given.
+type inProcessClient struct {
+	handler http.Handler
+}
+
+// Do serves the request given with the handler, and returns its response.
+// The request is served as a request from the loopback address, since the
+// websocket connection it comes from is already checked against the
+// websocket access configuration.
+func (c *inProcessClient) Do(r *http.Request) (*http.Response, error) {
+	r.RemoteAddr = "127.0.0.1:0"
+	recorder := httptest.NewRecorder()
+	c.handler.ServeHTTP(recorder, r)
+	return recorder.Result(), nil
+}
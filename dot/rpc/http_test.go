@@ -30,6 +30,7 @@
 	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -380,3 +381,28 @@ func newCoreServiceTest(t *testing.T) *core.Service {
 
 	return s
 }
+
+func Test_inProcessClient_Do(t *testing.T) {
+	t.Parallel()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "127.0.0.1:0", r.RemoteAddr)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write(body)
+	})
+	client := &inProcessClient{handler: handler}
+
+	request, err := http.NewRequest(http.MethodPost, "http://localhost:8545/", bytes.NewBufferString("data"))
+	require.NoError(t, err)
+
+	response, err := client.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(body))
+}
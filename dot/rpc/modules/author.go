@@ -4,7 +4,9 @@
 package modules
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -47,6 +49,33 @@ type ExtrinsicOrHash struct {
 	Extrinsic []byte
 }
 
+// UnmarshalJSON decodes either a {"hash": "0x..."} object
+// or an {"extrinsic": "0x..."} object into the ExtrinsicOrHash.
+func (e *ExtrinsicOrHash) UnmarshalJSON(data []byte) error {
+	var value struct {
+		Hash      *common.Hash `json:"hash"`
+		Extrinsic *string      `json:"extrinsic"`
+	}
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case value.Hash != nil:
+		e.Hash = *value.Hash
+	case value.Extrinsic != nil:
+		e.Extrinsic, err = common.HexToBytes(*value.Extrinsic)
+		if err != nil {
+			return fmt.Errorf("decoding extrinsic: %w", err)
+		}
+	default:
+		return ErrExtrinsicOrHashEmpty
+	}
+
+	return nil
+}
+
 // ExtrinsicOrHashRequest is a array of ExtrinsicOrHash
 type ExtrinsicOrHashRequest []ExtrinsicOrHash
 
@@ -59,8 +88,8 @@ type ExtrinsicOrHash struct {
 // RemoveExtrinsicsResponse is a array of hash used to Remove extrinsics
 type RemoveExtrinsicsResponse []common.Hash
 
-// KeyRotateResponse is a byte array used to rotate
-type KeyRotateResponse []byte
+// KeyRotateResponse is the hex encoded SCALE encoded session public keys
+type KeyRotateResponse string
 
 // HasSessionKeyResponse is the response to the RPC call author_hasSessionKeys
 type HasSessionKeyResponse bool
@@ -192,12 +221,34 @@ func (am *AuthorModule) PendingExtrinsics(r *http.Request, req *EmptyRequest, re
 }
 
 // RemoveExtrinsic Remove given extrinsic from the pool and temporarily ban it to prevent reimporting
-func (am *AuthorModule) RemoveExtrinsic(r *http.Request, _ *ExtrinsicOrHashRequest, _ *RemoveExtrinsicsResponse) error {
+func (am *AuthorModule) RemoveExtrinsic(r *http.Request, req *ExtrinsicOrHashRequest,
+	res *RemoveExtrinsicsResponse) error {
+	hashes := make([]common.Hash, len(*req))
+	for i, extrinsicOrHash := range *req {
+		if extrinsicOrHash.Extrinsic != nil {
+			hashes[i] = types.Extrinsic(extrinsicOrHash.Extrinsic).Hash()
+		} else {
+			hashes[i] = extrinsicOrHash.Hash
+		}
+	}
+
+	removed := am.txStateAPI.RemoveAndBan(hashes)
+	*res = RemoveExtrinsicsResponse(removed)
+	if *res == nil {
+		*res = RemoveExtrinsicsResponse{}
+	}
+
 	return nil
 }
 
 // RotateKeys Generate new session keys and returns the corresponding public keys
 func (am *AuthorModule) RotateKeys(r *http.Request, req *EmptyRequest, res *KeyRotateResponse) error {
+	publicKeys, err := am.coreAPI.RotateKeys()
+	if err != nil {
+		return fmt.Errorf("rotating keys: %w", err)
+	}
+
+	*res = KeyRotateResponse(common.BytesToHex(publicKeys))
 	return nil
 }
 
@@ -494,3 +494,158 @@ type args struct {
 		})
 	}
 }
+
+func TestAuthorModule_RotateKeys(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+
+	testCases := map[string]struct {
+		coreAPIBuilder func(ctrl *gomock.Controller) CoreAPI
+		response       KeyRotateResponse
+		errWrapped     error
+		errMessage     string
+	}{
+		"rotate_keys_error": {
+			coreAPIBuilder: func(ctrl *gomock.Controller) CoreAPI {
+				coreAPI := mocks.NewMockCoreAPI(ctrl)
+				coreAPI.EXPECT().RotateKeys().Return(nil, errTest)
+				return coreAPI
+			},
+			errWrapped: errTest,
+			errMessage: "rotating keys: test error",
+		},
+		"success": {
+			coreAPIBuilder: func(ctrl *gomock.Controller) CoreAPI {
+				coreAPI := mocks.NewMockCoreAPI(ctrl)
+				coreAPI.EXPECT().RotateKeys().Return([]byte{1, 2, 3}, nil)
+				return coreAPI
+			},
+			response: "0x010203",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			module := &AuthorModule{
+				coreAPI: testCase.coreAPIBuilder(ctrl),
+			}
+
+			var response KeyRotateResponse
+			err := module.RotateKeys(nil, nil, &response)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.response, response)
+		})
+	}
+}
+
+func TestExtrinsicOrHash_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		data            string
+		extrinsicOrHash ExtrinsicOrHash
+		errWrapped      error
+		errMessage      string
+	}{
+		"hash": {
+			data: `{"hash":"0x0100000000000000000000000000000000000000000000000000000000000000"}`,
+			extrinsicOrHash: ExtrinsicOrHash{
+				Hash: common.Hash{1},
+			},
+		},
+		"extrinsic": {
+			data: `{"extrinsic":"0x010203"}`,
+			extrinsicOrHash: ExtrinsicOrHash{
+				Extrinsic: []byte{1, 2, 3},
+			},
+		},
+		"bad_extrinsic": {
+			data:       `{"extrinsic":"010203"}`,
+			errWrapped: common.ErrNoPrefix,
+			errMessage: "decoding extrinsic: could not byteify non 0x prefixed string: 010203",
+		},
+		"empty": {
+			data:       `{}`,
+			errWrapped: ErrExtrinsicOrHashEmpty,
+			errMessage: "expected either an extrinsic or a hash",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var extrinsicOrHash ExtrinsicOrHash
+			err := extrinsicOrHash.UnmarshalJSON([]byte(testCase.data))
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.extrinsicOrHash, extrinsicOrHash)
+		})
+	}
+}
+
+func TestAuthorModule_RemoveExtrinsic(t *testing.T) {
+	t.Parallel()
+
+	extrinsic := types.Extrinsic{1, 2, 3}
+
+	testCases := map[string]struct {
+		txStateAPIBuilder func(ctrl *gomock.Controller) TransactionStateAPI
+		request           ExtrinsicOrHashRequest
+		response          RemoveExtrinsicsResponse
+	}{
+		"none_removed": {
+			txStateAPIBuilder: func(ctrl *gomock.Controller) TransactionStateAPI {
+				txStateAPI := mocks.NewMockTransactionStateAPI(ctrl)
+				txStateAPI.EXPECT().RemoveAndBan([]common.Hash{{1}}).Return(nil)
+				return txStateAPI
+			},
+			request:  ExtrinsicOrHashRequest{{Hash: common.Hash{1}}},
+			response: RemoveExtrinsicsResponse{},
+		},
+		"hash_and_extrinsic": {
+			txStateAPIBuilder: func(ctrl *gomock.Controller) TransactionStateAPI {
+				txStateAPI := mocks.NewMockTransactionStateAPI(ctrl)
+				txStateAPI.EXPECT().RemoveAndBan([]common.Hash{{1}, extrinsic.Hash()}).
+					Return([]common.Hash{{1}, extrinsic.Hash(), {2}})
+				return txStateAPI
+			},
+			request: ExtrinsicOrHashRequest{
+				{Hash: common.Hash{1}},
+				{Extrinsic: extrinsic},
+			},
+			response: RemoveExtrinsicsResponse{{1}, extrinsic.Hash(), {2}},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			module := &AuthorModule{
+				txStateAPI: testCase.txStateAPIBuilder(ctrl),
+			}
+
+			var response RemoveExtrinsicsResponse
+			err := module.RemoveExtrinsic(nil, &testCase.request, &response)
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.response, response)
+		})
+	}
+}
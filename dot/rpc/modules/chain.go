@@ -4,11 +4,13 @@
 package modules
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
 	"strconv"
 
+	"github.com/ChainSafe/gossamer/dot/state"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/pkg/scale"
@@ -58,6 +60,15 @@ type ChainBlockResponse struct {
 // ChainHashResponse interface to handle response
 type ChainHashResponse interface{}
 
+// ChainIndexedTransactionRequest holds the content hash of an indexed transaction
+type ChainIndexedTransactionRequest struct {
+	Hash common.Hash
+}
+
+// ChainIndexedTransactionResponse is the hex encoded indexed transaction data,
+// or nil if no data is indexed for the requested hash.
+type ChainIndexedTransactionResponse interface{}
+
 // ChainModule is an RPC module providing access to storage API points.
 type ChainModule struct {
 	blockAPI BlockAPI
@@ -151,6 +162,22 @@ func (cm *ChainModule) GetHeader(r *http.Request, req *ChainHashRequest, res *Ch
 	return err
 }
 
+// GetIndexedTransaction returns the transaction data indexed by the runtime for the given
+// content hash, or null if no data is indexed or if it was pruned.
+func (cm *ChainModule) GetIndexedTransaction(
+	_ *http.Request, req *ChainIndexedTransactionRequest, res *ChainIndexedTransactionResponse) error {
+	data, err := cm.blockAPI.GetIndexedTransaction(req.Hash)
+	if err != nil {
+		if errors.Is(err, state.ErrIndexedTransactionNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	*res = common.BytesToHex(data)
+	return nil
+}
+
 // SubscribeFinalizedHeads handled by websocket handler, but this func should remain
 // here so it's added to rpc_methods list
 func (cm *ChainModule) SubscribeFinalizedHeads(_ *http.Request, _ *EmptyRequest, _ *ChainBlockHeaderResponse) error {
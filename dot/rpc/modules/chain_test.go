@@ -9,6 +9,7 @@
 	"testing"
 
 	"github.com/ChainSafe/gossamer/dot/rpc/modules/mocks"
+	"github.com/ChainSafe/gossamer/dot/state"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/golang/mock/gomock"
@@ -362,6 +363,66 @@ type args struct {
 	}
 }
 
+func TestChainModule_GetIndexedTransaction(t *testing.T) {
+	t.Parallel()
+
+	contentHash := common.Hash{1, 2}
+	errTest := errors.New("test error")
+
+	testCases := map[string]struct {
+		blockAPIBuilder func(ctrl *gomock.Controller) BlockAPI
+		res             ChainIndexedTransactionResponse
+		errWrapped      error
+		errMessage      string
+	}{
+		"indexed_transaction_found": {
+			blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+				blockAPI := mocks.NewMockBlockAPI(ctrl)
+				blockAPI.EXPECT().GetIndexedTransaction(contentHash).Return([]byte{3, 4}, nil)
+				return blockAPI
+			},
+			res: "0x0304",
+		},
+		"indexed_transaction_not_found": {
+			blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+				blockAPI := mocks.NewMockBlockAPI(ctrl)
+				blockAPI.EXPECT().GetIndexedTransaction(contentHash).
+					Return(nil, state.ErrIndexedTransactionNotFound)
+				return blockAPI
+			},
+		},
+		"get_indexed_transaction_error": {
+			blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+				blockAPI := mocks.NewMockBlockAPI(ctrl)
+				blockAPI.EXPECT().GetIndexedTransaction(contentHash).Return(nil, errTest)
+				return blockAPI
+			},
+			errWrapped: errTest,
+			errMessage: "test error",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			chainModule := NewChainModule(testCase.blockAPIBuilder(ctrl))
+			request := &ChainIndexedTransactionRequest{Hash: contentHash}
+
+			var res ChainIndexedTransactionResponse
+			err := chainModule.GetIndexedTransaction(nil, request, &res)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.res, res)
+		})
+	}
+}
+
 func TestChainModule_GetHeader(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
@@ -7,6 +7,7 @@
 	"net/http"
 
 	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/trie"
 )
 
 // GetKeysRequest represents the request to retrieve the keys of a child storage
@@ -52,7 +53,7 @@ func NewChildStateModule(s StorageAPI, b BlockAPI) *ChildStateModule {
 }
 
 // GetKeys returns the keys from the specified child storage. The keys can also be filtered based on a prefix.
-func (cs *ChildStateModule) GetKeys(_ *http.Request, req *GetKeysRequest, res *[]string) error {
+func (cs *ChildStateModule) GetKeys(_ *http.Request, req *GetKeysRequest, res *[]string) (err error) {
 	var hash common.Hash
 
 	if req.Hash == nil {
@@ -66,12 +67,13 @@ func (cs *ChildStateModule) GetKeys(_ *http.Request, req *GetKeysRequest, res *[
 		return err
 	}
 
-	trie, err := cs.storageAPI.GetStorageChild(stateRoot, req.Key)
+	childTrie, err := cs.storageAPI.GetStorageChild(stateRoot, req.Key)
 	if err != nil {
 		return err
 	}
 
-	keys := trie.GetKeysWithPrefix(req.Prefix)
+	defer trie.CatchLoadNodePanic(&err)
+	keys := childTrie.GetKeysWithPrefix(req.Prefix)
 	hexKeys := make([]string, len(keys))
 	for idx, k := range keys {
 		hexKeys[idx] = common.BytesToHex(k)
@@ -8,4 +8,9 @@
 var (
 	ErrSubscriptionTransport = errors.New("subscriptions are not available on this transport")
 	ErrStartBlockHashEmpty   = errors.New("the start block hash cannot be an empty value")
+	ErrEmptyRuntimeMethod    = errors.New("runtime method name cannot be empty")
+	ErrExtrinsicOrHashEmpty  = errors.New("expected either an extrinsic or a hash")
+	ErrInvalidBlockRange     = errors.New("invalid block range")
+	ErrTooManyStorageKeys    = errors.New("too many storage keys")
+	ErrTooManyStorageChanges = errors.New("too many storage changes")
 )
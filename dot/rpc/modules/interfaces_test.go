@@ -35,8 +35,10 @@ type Runtime interface {
 	PaymentQueryInfo(ext []byte) (*types.RuntimeDispatchInfo, error)
 	BabeGenerateKeyOwnershipProof(slot uint64, offenderPublicKey [32]byte) (types.OpaqueKeyOwnershipProof, error)
 	BabeSubmitReportEquivocationUnsignedExtrinsic(types.BabeEquivocationProof, types.OpaqueKeyOwnershipProof) error
+	GrandpaGenerateKeyOwnershipProof(setID uint64, authorityID [32]byte) (types.OpaqueKeyOwnershipProof, error)
+	GrandpaSubmitReportEquivocationUnsignedExtrinsic(types.GrandpaEquivocationProof, types.OpaqueKeyOwnershipProof) error
 	CheckInherents()
 	RandomSeed()
-	OffchainWorker()
-	GenerateSessionKeys()
+	OffchainWorker(header *types.Header) error
+	GenerateSessionKeys(seed *[]byte) (publicKeys []byte, err error)
 }
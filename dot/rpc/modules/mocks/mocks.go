@@ -14,6 +14,7 @@
 	ed25519 "github.com/ChainSafe/gossamer/lib/crypto/ed25519"
 	genesis "github.com/ChainSafe/gossamer/lib/genesis"
 	runtime "github.com/ChainSafe/gossamer/lib/runtime"
+	storage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 	transaction "github.com/ChainSafe/gossamer/lib/transaction"
 	trie "github.com/ChainSafe/gossamer/lib/trie"
 	gomock "github.com/golang/mock/gomock"
@@ -57,6 +58,21 @@ func (mr *MockStorageAPIMockRecorder) Entries(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Entries", reflect.TypeOf((*MockStorageAPI)(nil).Entries), arg0)
 }
 
+// GetChangedKeys mocks base method.
+func (m *MockStorageAPI) GetChangedKeys(arg0, arg1 common.Hash) ([][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChangedKeys", arg0, arg1)
+	ret0, _ := ret[0].([][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChangedKeys indicates an expected call of GetChangedKeys.
+func (mr *MockStorageAPIMockRecorder) GetChangedKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChangedKeys", reflect.TypeOf((*MockStorageAPI)(nil).GetChangedKeys), arg0, arg1)
+}
+
 // GetKeysWithPrefix mocks base method.
 func (m *MockStorageAPI) GetKeysWithPrefix(arg0 *common.Hash, arg1 []byte) ([][]byte, error) {
 	m.ctrl.T.Helper()
@@ -147,6 +163,20 @@ func (mr *MockStorageAPIMockRecorder) GetStorageFromChild(arg0, arg1, arg2 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStorageFromChild", reflect.TypeOf((*MockStorageAPI)(nil).GetStorageFromChild), arg0, arg1, arg2)
 }
 
+// PinBlockNumber mocks base method.
+func (m *MockStorageAPI) PinBlockNumber(arg0 uint) func() {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PinBlockNumber", arg0)
+	ret0, _ := ret[0].(func())
+	return ret0
+}
+
+// PinBlockNumber indicates an expected call of PinBlockNumber.
+func (mr *MockStorageAPIMockRecorder) PinBlockNumber(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PinBlockNumber", reflect.TypeOf((*MockStorageAPI)(nil).PinBlockNumber), arg0)
+}
+
 // RegisterStorageObserver mocks base method.
 func (m *MockStorageAPI) RegisterStorageObserver(arg0 state.Observer) {
 	m.ctrl.T.Helper()
@@ -159,6 +189,21 @@ func (mr *MockStorageAPIMockRecorder) RegisterStorageObserver(arg0 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterStorageObserver", reflect.TypeOf((*MockStorageAPI)(nil).RegisterStorageObserver), arg0)
 }
 
+// TrieState mocks base method.
+func (m *MockStorageAPI) TrieState(arg0 *common.Hash) (*storage.TrieState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrieState", arg0)
+	ret0, _ := ret[0].(*storage.TrieState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TrieState indicates an expected call of TrieState.
+func (mr *MockStorageAPIMockRecorder) TrieState(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrieState", reflect.TypeOf((*MockStorageAPI)(nil).TrieState), arg0)
+}
+
 // UnregisterStorageObserver mocks base method.
 func (m *MockStorageAPI) UnregisterStorageObserver(arg0 state.Observer) {
 	m.ctrl.T.Helper()
@@ -335,6 +380,21 @@ func (mr *MockBlockAPIMockRecorder) GetImportedBlockNotifierChannel() *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImportedBlockNotifierChannel", reflect.TypeOf((*MockBlockAPI)(nil).GetImportedBlockNotifierChannel))
 }
 
+// GetIndexedTransaction mocks base method.
+func (m *MockBlockAPI) GetIndexedTransaction(arg0 common.Hash) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIndexedTransaction", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIndexedTransaction indicates an expected call of GetIndexedTransaction.
+func (mr *MockBlockAPIMockRecorder) GetIndexedTransaction(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIndexedTransaction", reflect.TypeOf((*MockBlockAPI)(nil).GetIndexedTransaction), arg0)
+}
+
 // GetJustification mocks base method.
 func (m *MockBlockAPI) GetJustification(arg0 common.Hash) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -350,6 +410,20 @@ func (mr *MockBlockAPIMockRecorder) GetJustification(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJustification", reflect.TypeOf((*MockBlockAPI)(nil).GetJustification), arg0)
 }
 
+// GetNonFinalisedBlocks mocks base method.
+func (m *MockBlockAPI) GetNonFinalisedBlocks() []common.Hash {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNonFinalisedBlocks")
+	ret0, _ := ret[0].([]common.Hash)
+	return ret0
+}
+
+// GetNonFinalisedBlocks indicates an expected call of GetNonFinalisedBlocks.
+func (mr *MockBlockAPIMockRecorder) GetNonFinalisedBlocks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNonFinalisedBlocks", reflect.TypeOf((*MockBlockAPI)(nil).GetNonFinalisedBlocks))
+}
+
 // GetRuntime mocks base method.
 func (m *MockBlockAPI) GetRuntime(arg0 common.Hash) (state.Runtime, error) {
 	m.ctrl.T.Helper()
@@ -697,6 +771,20 @@ func (mr *MockTransactionStateAPIMockRecorder) Pending() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pending", reflect.TypeOf((*MockTransactionStateAPI)(nil).Pending))
 }
 
+// RemoveAndBan mocks base method.
+func (m *MockTransactionStateAPI) RemoveAndBan(arg0 []common.Hash) []common.Hash {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAndBan", arg0)
+	ret0, _ := ret[0].([]common.Hash)
+	return ret0
+}
+
+// RemoveAndBan indicates an expected call of RemoveAndBan.
+func (mr *MockTransactionStateAPIMockRecorder) RemoveAndBan(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAndBan", reflect.TypeOf((*MockTransactionStateAPI)(nil).RemoveAndBan), arg0)
+}
+
 // MockCoreAPI is a mock of CoreAPI interface.
 type MockCoreAPI struct {
 	ctrl     *gomock.Controller
@@ -824,6 +912,21 @@ func (mr *MockCoreAPIMockRecorder) InsertKey(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertKey", reflect.TypeOf((*MockCoreAPI)(nil).InsertKey), arg0, arg1)
 }
 
+// RotateKeys mocks base method.
+func (m *MockCoreAPI) RotateKeys() ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateKeys")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateKeys indicates an expected call of RotateKeys.
+func (mr *MockCoreAPIMockRecorder) RotateKeys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateKeys", reflect.TypeOf((*MockCoreAPI)(nil).RotateKeys))
+}
+
 // MockSystemAPI is a mock of SystemAPI interface.
 type MockSystemAPI struct {
 	ctrl     *gomock.Controller
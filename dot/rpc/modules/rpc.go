@@ -19,6 +19,7 @@
 		"state_getPairs",
 		"state_getKeysPaged",
 		"state_queryStorage",
+		"state_traceBlock",
 	}
 
 	// AliasesMethods is a map that links the original methods to their aliases
@@ -5,15 +5,40 @@
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 )
 
+const (
+	// maxQueryStorageKeys is the maximum number of keys queried
+	// by state_queryStorage and state_queryStorageAt.
+	maxQueryStorageKeys = 1000
+	// maxQueryStorageBlocks is the maximum number of blocks
+	// in the block range queried by state_queryStorage.
+	maxQueryStorageBlocks = 100000
+	// maxQueryStorageChanges is the maximum number of storage changes returned
+	// by state_queryStorage and state_queryStorageAt, to limit their response size.
+	maxQueryStorageChanges = 100000
+	// maxTraceBlockResponseSize is the maximum size in bytes of the JSON encoded
+	// block trace returned by state_traceBlock, above which a trace error is returned instead.
+	maxTraceBlockResponseSize = 15 * 1024 * 1024
+	// defaultTraceBlockTargets are the tracing targets used by state_traceBlock
+	// if no tracing targets are given.
+	defaultTraceBlockTargets = "pallet,frame,state"
+	// traceBlockStorageTarget is the target of the storage access events of a block trace.
+	traceBlockStorageTarget = "state"
+	// traceBlockRootSpanID is the identifier of the span of the block execution,
+	// parent of all the other spans and events of a block trace.
+	traceBlockRootSpanID uint64 = 1
+)
+
 // StateGetReadProofRequest json fields
 type StateGetReadProofRequest struct {
 	Keys []string
@@ -23,7 +48,7 @@ type StateGetReadProofRequest struct {
 // StateCallRequest holds json fields
 type StateCallRequest struct {
 	Method string       `json:"method"`
-	Data   []byte       `json:"data"`
+	Data   string       `json:"data"`
 	Block  *common.Hash `json:"block"`
 }
 
@@ -76,11 +101,25 @@ type StateStorageQueryRangeRequest struct {
 	EndBlock   common.Hash `json:"block"`
 }
 
+// StateStorageQueryAtRequest holds json fields
+type StateStorageQueryAtRequest struct {
+	Keys []string     `json:"keys" validate:"required"`
+	At   *common.Hash `json:"at"`
+}
+
+// StateTraceBlockRequest holds json fields
+type StateTraceBlockRequest struct {
+	Block       common.Hash `json:"block" validate:"required"`
+	Targets     *string     `json:"targets"`
+	StorageKeys *string     `json:"storageKeys"`
+	Methods     *string     `json:"methods"`
+}
+
 // StateStorageKeysQuery field to store storage keys
 type StateStorageKeysQuery [][]byte
 
-// StateCallResponse holds json fields
-type StateCallResponse []byte
+// StateCallResponse is the hex encoded result of the runtime call
+type StateCallResponse string
 
 // StateKeysResponse field to store the state keys
 type StateKeysResponse [][]byte
@@ -127,6 +166,50 @@ type StorageChangeSetResponse struct {
 	Changes [][2]*string `json:"changes"`
 }
 
+// TraceBlockResponse is the response of state_traceBlock, holding either
+// the block trace or an error if the block trace is too large.
+type TraceBlockResponse struct {
+	BlockTrace *BlockTrace `json:"blockTrace,omitempty"`
+	TraceError *TraceError `json:"traceError,omitempty"`
+}
+
+// TraceError is the error returned instead of a block trace.
+type TraceError struct {
+	Error string `json:"error"`
+}
+
+// BlockTrace is the trace of the execution of a block.
+type BlockTrace struct {
+	BlockHash      string       `json:"blockHash"`
+	ParentHash     string       `json:"parentHash"`
+	TracingTargets string       `json:"tracingTargets"`
+	StorageKeys    string       `json:"storageKeys"`
+	Methods        string       `json:"methods"`
+	Spans          []TraceSpan  `json:"spans"`
+	Events         []TraceEvent `json:"events"`
+}
+
+// TraceSpan is a span of a block trace.
+type TraceSpan struct {
+	ID       uint64  `json:"id"`
+	ParentID *uint64 `json:"parentId"`
+	Name     string  `json:"name"`
+	Target   string  `json:"target"`
+	Wasm     bool    `json:"wasm"`
+}
+
+// TraceEvent is an event of a block trace.
+type TraceEvent struct {
+	Target   string         `json:"target"`
+	Data     TraceEventData `json:"data"`
+	ParentID *uint64        `json:"parentId"`
+}
+
+// TraceEventData holds the values of a trace event.
+type TraceEventData struct {
+	StringValues map[string]string `json:"stringValues"`
+}
+
 // KeyValueOption struct holds json fields
 type KeyValueOption []byte
 
@@ -239,10 +322,56 @@ func (sm *StateModule) GetPairs(_ *http.Request, req *StatePairRequest, res *Sta
 	return nil
 }
 
-// Call isn't implemented properly yet.
-func (sm *StateModule) Call(_ *http.Request, _ *StateCallRequest, _ *StateCallResponse) error {
-	_ = sm.networkAPI
-	_ = sm.storageAPI
+// Call executes the runtime API method given with the SCALE encoded data given,
+// at the state of the block given. If no block hash is provided, the best block is used.
+// The runtime call is executed on a copy of the block state trie, so any storage
+// changes made by the call are discarded, and on a runtime instance acquired for
+// the call only, so it cannot interfere with other runtime calls such as block import.
+func (sm *StateModule) Call(_ *http.Request, req *StateCallRequest, res *StateCallResponse) error {
+	if req.Method == "" {
+		return ErrEmptyRuntimeMethod
+	}
+
+	var blockHash common.Hash
+	if req.Block != nil {
+		blockHash = *req.Block
+	} else {
+		blockHash = sm.blockAPI.BestBlockHash()
+	}
+
+	data, err := common.HexToBytes(req.Data)
+	if err != nil {
+		return fmt.Errorf("decoding call data %q: %w", req.Data, err)
+	}
+
+	stateRoot, err := sm.storageAPI.GetStateRootFromBlock(&blockHash)
+	if err != nil {
+		return fmt.Errorf("getting state root for block %s: %w", blockHash, err)
+	}
+
+	trieState, err := sm.storageAPI.TrieState(stateRoot)
+	if err != nil {
+		return fmt.Errorf("getting trie state for state root %s: %w", stateRoot, err)
+	}
+
+	rt, err := sm.blockAPI.GetRuntime(blockHash)
+	if err != nil {
+		return fmt.Errorf("getting runtime for block %s: %w", blockHash, err)
+	}
+
+	instance, release, err := runtime.AcquireInstance(rt)
+	if err != nil {
+		return fmt.Errorf("getting runtime instance for block %s: %w", blockHash, err)
+	}
+	defer release()
+
+	instance.SetContextStorage(trieState)
+	result, err := instance.Exec(req.Method, data)
+	if err != nil {
+		return fmt.Errorf("executing runtime method %s: %w", req.Method, err)
+	}
+
+	*res = StateCallResponse(common.BytesToHex(result))
 	return nil
 }
 
@@ -426,6 +555,8 @@ func (sm *StateModule) GetStorageSize(
 
 // QueryStorage queries historical storage entries (by key) starting from a given request start block
 // and until a given end block, or until the best block if the given end block is nil.
+// The storage values of the keys are only read for blocks where the keys changed,
+// found by comparing the state tries of consecutive blocks.
 func (sm *StateModule) QueryStorage(
 	_ *http.Request, req *StateStorageQueryRangeRequest, res *[]StorageChangeSetResponse) error {
 	if req.StartBlock.IsEmpty() {
@@ -449,48 +580,357 @@ func (sm *StateModule) QueryStorage(
 	}
 	endBlockNumber := endBlock.Header.Number
 
-	response := make([]StorageChangeSetResponse, 0, endBlockNumber-startBlockNumber)
-	lastValue := make([]*string, len(req.Keys))
+	switch {
+	case endBlockNumber < startBlockNumber:
+		return fmt.Errorf("%w: end block number %d is lower than start block number %d",
+			ErrInvalidBlockRange, endBlockNumber, startBlockNumber)
+	case endBlockNumber-startBlockNumber+1 > maxQueryStorageBlocks:
+		return fmt.Errorf("%w: %d blocks exceed the maximum of %d blocks",
+			ErrInvalidBlockRange, endBlockNumber-startBlockNumber+1, maxQueryStorageBlocks)
+	}
 
+	blockHashes := make([]common.Hash, 0, endBlockNumber-startBlockNumber+1)
 	for i := startBlockNumber; i <= endBlockNumber; i++ {
 		blockHash, err := sm.blockAPI.GetHashByNumber(i)
 		if err != nil {
 			return fmt.Errorf("cannot get hash by number: %w", err)
 		}
-		changes := make([][2]*string, 0, len(req.Keys))
+		blockHashes = append(blockHashes, blockHash)
+	}
+
+	response, err := sm.queryStorage(req.Keys, blockHashes)
+	if err != nil {
+		return err
+	}
+
+	*res = response
+	return nil
+}
+
+// QueryStorageAt queries the storage entries (by key) at the given block,
+// or at the best block if no block is given.
+func (sm *StateModule) QueryStorageAt(
+	_ *http.Request, req *StateStorageQueryAtRequest, res *[]StorageChangeSetResponse) error {
+	blockHash := sm.blockAPI.BestBlockHash()
+	if req.At != nil {
+		blockHash = *req.At
+	}
+
+	response, err := sm.queryStorage(req.Keys, []common.Hash{blockHash})
+	if err != nil {
+		return err
+	}
+
+	*res = response
+	return nil
+}
+
+// queryStorage returns the storage changes of the given hex encoded keys for each
+// of the given block hashes. All the keys are returned for the first block, and
+// only the keys with a value different from the previous block are returned for
+// the following blocks.
+func (sm *StateModule) queryStorage(hexKeys []string, blockHashes []common.Hash) (
+	response []StorageChangeSetResponse, err error) {
+	if len(hexKeys) > maxQueryStorageKeys {
+		return nil, fmt.Errorf("%w: %d keys exceed the maximum of %d keys",
+			ErrTooManyStorageKeys, len(hexKeys), maxQueryStorageKeys)
+	}
 
-		for j, key := range req.Keys {
-			value, err := sm.storageAPI.GetStorageByBlockHash(&blockHash, common.MustHexToBytes(key))
+	keys := make([][]byte, len(hexKeys))
+	for i, hexKey := range hexKeys {
+		keys[i], err = common.HexToBytes(hexKey)
+		if err != nil {
+			return nil, fmt.Errorf("decoding key %s: %w", hexKey, err)
+		}
+	}
+
+	response = make([]StorageChangeSetResponse, 0, len(blockHashes))
+	lastValues := make([]*string, len(keys))
+	var previousStateRoot common.Hash
+	changesCount := 0
+
+	for i := range blockHashes {
+		blockHash := blockHashes[i]
+		stateRoot, err := sm.storageAPI.GetStateRootFromBlock(&blockHash)
+		if err != nil {
+			return nil, fmt.Errorf("getting state root of block %s: %w", blockHash, err)
+		}
+
+		// nil for the first block, since all the keys are returned for it.
+		var changedKeys map[string]struct{}
+		if i > 0 {
+			changedKeys, err = sm.getChangedKeys(previousStateRoot, *stateRoot)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		changes := make([][2]*string, 0, len(keys))
+		for j, key := range keys {
+			if changedKeys != nil {
+				_, changed := changedKeys[string(key)]
+				if !changed {
+					continue
+				}
+			}
+
+			value, err := sm.storageAPI.GetStorage(stateRoot, key)
 			if err != nil {
-				return fmt.Errorf("getting value by block hash: %w", err)
+				return nil, fmt.Errorf("getting value at block %s: %w", blockHash, err)
 			}
+
 			var hexValue *string
 			if len(value) > 0 {
 				hexValue = stringPtr(common.BytesToHex(value))
 			}
 
-			differentValueEncountered := i == startBlockNumber ||
-				lastValue[j] == nil && hexValue != nil ||
-				lastValue[j] != nil && *lastValue[j] != *hexValue
-			if differentValueEncountered {
-				changes = append(changes, [2]*string{stringPtr(key), hexValue})
-				lastValue[j] = hexValue
+			if i > 0 && equalStringPointers(lastValues[j], hexValue) {
+				continue
 			}
 
+			changes = append(changes, [2]*string{stringPtr(hexKeys[j]), hexValue})
+			lastValues[j] = hexValue
+		}
+
+		changesCount += len(changes)
+		if changesCount > maxQueryStorageChanges {
+			return nil, fmt.Errorf("%w: changes exceed the maximum of %d changes at block %s",
+				ErrTooManyStorageChanges, maxQueryStorageChanges, blockHash)
 		}
 
 		response = append(response, StorageChangeSetResponse{
 			Block:   &blockHash,
 			Changes: changes,
 		})
+		previousStateRoot = *stateRoot
 	}
 
-	*res = response
-	return nil
+	return response, nil
+}
+
+// getChangedKeys returns the set of keys changed between the two state roots given.
+func (sm *StateModule) getChangedKeys(previousStateRoot, stateRoot common.Hash) (
+	changedKeys map[string]struct{}, err error) {
+	keys, err := sm.storageAPI.GetChangedKeys(previousStateRoot, stateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("getting changed keys between state roots %s and %s: %w",
+			previousStateRoot, stateRoot, err)
+	}
+
+	changedKeys = make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		changedKeys[string(key)] = struct{}{}
+	}
+	return changedKeys, nil
+}
+
+func equalStringPointers(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
 }
 
 func stringPtr(s string) *string { return &s }
 
+// TraceBlock executes again the block with the given hash on a copy of the state of its
+// parent block, and returns the runtime log messages and the storage accesses of the execution.
+// Targets, storage keys and methods are optional comma separated prefixes filtering
+// the log message targets, the hex encoded storage keys and the storage methods.
+func (sm *StateModule) TraceBlock(
+	_ *http.Request, req *StateTraceBlockRequest, res *TraceBlockResponse) error {
+	block, err := sm.blockAPI.GetBlockByHash(req.Block)
+	if err != nil {
+		return fmt.Errorf("getting block: %w", err)
+	}
+
+	parentHash := block.Header.ParentHash
+	parentHeader, err := sm.blockAPI.GetHeader(parentHash)
+	if err != nil {
+		return fmt.Errorf("getting parent block header: %w", err)
+	}
+
+	trieState, err := sm.storageAPI.TrieState(&parentHeader.StateRoot)
+	if err != nil {
+		return fmt.Errorf("getting parent block trie state: %w", err)
+	}
+	tracingTrieState := rtstorage.NewTracingTrieState(trieState)
+
+	rt, err := sm.blockAPI.GetRuntime(parentHash)
+	if err != nil {
+		return fmt.Errorf("getting runtime: %w", err)
+	}
+
+	instance, release, err := runtime.AcquireInstance(rt)
+	if err != nil {
+		return err
+	}
+
+	instance.SetContextStorage(tracingTrieState)
+	_, err = instance.ExecuteBlock(block)
+	release()
+	if err != nil {
+		return fmt.Errorf("executing block: %w", err)
+	}
+
+	targets := defaultTraceBlockTargets
+	if req.Targets != nil {
+		targets = *req.Targets
+	}
+
+	var storageKeys, methods string
+	if req.StorageKeys != nil {
+		storageKeys = *req.StorageKeys
+	}
+	if req.Methods != nil {
+		methods = *req.Methods
+	}
+
+	blockTrace := newBlockTrace(req.Block, parentHash, targets, storageKeys, methods,
+		tracingTrieState.Logs(), tracingTrieState.Accesses())
+
+	encodedBlockTrace, err := json.Marshal(blockTrace)
+	if err != nil {
+		return fmt.Errorf("encoding block trace: %w", err)
+	}
+
+	if len(encodedBlockTrace) > maxTraceBlockResponseSize {
+		*res = TraceBlockResponse{
+			TraceError: &TraceError{
+				Error: fmt.Sprintf("block trace of %d bytes exceeds the maximum of %d bytes",
+					len(encodedBlockTrace), maxTraceBlockResponseSize),
+			},
+		}
+		return nil
+	}
+
+	*res = TraceBlockResponse{BlockTrace: blockTrace}
+	return nil
+}
+
+// newBlockTrace returns the block trace of the runtime log messages and storage accesses
+// given, filtered using the comma separated targets, storage keys and methods prefixes.
+// Each runtime log message is a span and each storage access is an event, all children
+// of the root span of the block execution.
+func newBlockTrace(blockHash, parentHash common.Hash, targets, storageKeys, methods string,
+	logs []rtstorage.RuntimeLog, accesses []rtstorage.StorageAccess) (blockTrace *BlockTrace) {
+	targetPrefixes := splitTraceFilter(targets)
+	for i, targetPrefix := range targetPrefixes {
+		// targets can be suffixed with a log level such as pallet=debug
+		targetPrefixes[i], _, _ = strings.Cut(targetPrefix, "=")
+	}
+
+	storageKeyPrefixes := splitTraceFilter(storageKeys)
+	for i, storageKeyPrefix := range storageKeyPrefixes {
+		storageKeyPrefixes[i] = strings.TrimPrefix(strings.ToLower(storageKeyPrefix), "0x")
+	}
+
+	methodPrefixes := splitTraceFilter(methods)
+
+	rootSpanID := traceBlockRootSpanID
+	spans := []TraceSpan{{
+		ID:     rootSpanID,
+		Name:   "execute_block",
+		Target: "state_tracing",
+	}}
+	for _, log := range logs {
+		if !hasAnyPrefix(log.Target, targetPrefixes) {
+			continue
+		}
+
+		spans = append(spans, TraceSpan{
+			ID:       rootSpanID + uint64(len(spans)),
+			ParentID: &rootSpanID,
+			Name:     log.Message,
+			Target:   log.Target,
+			Wasm:     true,
+		})
+	}
+
+	events := []TraceEvent{}
+	if hasAnyPrefix(traceBlockStorageTarget, targetPrefixes) {
+		for _, access := range accesses {
+			if !hasAnyPrefix(hex.EncodeToString(access.Key), storageKeyPrefixes) ||
+				!hasAnyPrefix(access.Method, methodPrefixes) {
+				continue
+			}
+
+			events = append(events, TraceEvent{
+				Target:   traceBlockStorageTarget,
+				Data:     TraceEventData{StringValues: storageAccessValues(access)},
+				ParentID: &rootSpanID,
+			})
+		}
+	}
+
+	return &BlockTrace{
+		BlockHash:      blockHash.String(),
+		ParentHash:     parentHash.String(),
+		TracingTargets: targets,
+		StorageKeys:    storageKeys,
+		Methods:        methods,
+		Spans:          spans,
+		Events:         events,
+	}
+}
+
+// storageAccessValues returns the trace event values of a storage access,
+// where byte slices are hex encoded without 0x prefix.
+func storageAccessValues(access rtstorage.StorageAccess) (values map[string]string) {
+	values = map[string]string{
+		"method": access.Method,
+	}
+
+	if access.KeyToChild != nil {
+		values["child_key"] = hex.EncodeToString(access.KeyToChild)
+	}
+
+	if access.Key != nil {
+		values["key"] = hex.EncodeToString(access.Key)
+	}
+
+	optionalHex := "None"
+	if access.Value != nil {
+		optionalHex = hex.EncodeToString(access.Value)
+	}
+
+	switch access.Method {
+	case rtstorage.MethodGet, rtstorage.MethodChildGet,
+		rtstorage.MethodNextKey, rtstorage.MethodChildNextKey:
+		values["result"] = optionalHex
+	case rtstorage.MethodPut, rtstorage.MethodChildPut:
+		values["value"] = optionalHex
+	}
+
+	return values
+}
+
+// splitTraceFilter splits a comma separated trace filter into its non-empty prefixes.
+func splitTraceFilter(filter string) (prefixes []string) {
+	for _, prefix := range strings.Split(filter, ",") {
+		prefix = strings.TrimSpace(prefix)
+		if prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// hasAnyPrefix returns true if s starts with any of the prefixes given,
+// or if no prefix is given.
+func hasAnyPrefix(s string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // SubscribeRuntimeVersion initialised a runtime version subscription and returns the current version
 // See dot/rpc/subscription
 func (sm *StateModule) SubscribeRuntimeVersion(
@@ -364,12 +364,13 @@ func TestStateModule_QueryStorage(t *testing.T) {
 		mockBlockAPI.EXPECT().GetHashByNumber(uint(4)).Return(common.Hash{3, 4}, nil)
 
 		mockStorageAPI := NewMockStorageAPI(ctrl)
-		mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{1, 2}, []byte{144}).Return([]byte(`value`), nil)
-		mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{1, 2}, []byte{128}).
-			Return([]byte(`another value`), nil)
-		mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{3, 4}, []byte{144}).Return([]byte(`value`), nil)
-		mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{3, 4}, []byte{128}).
+		mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{1, 2}).Return(&common.Hash{5, 6}, nil)
+		mockStorageAPI.EXPECT().GetStorage(&common.Hash{5, 6}, []byte{144}).Return([]byte(`value`), nil)
+		mockStorageAPI.EXPECT().GetStorage(&common.Hash{5, 6}, []byte{128}).
 			Return([]byte(`another value`), nil)
+		mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{3, 4}).Return(&common.Hash{7, 8}, nil)
+		mockStorageAPI.EXPECT().GetChangedKeys(common.Hash{5, 6}, common.Hash{7, 8}).
+			Return([][]byte{{1}}, nil)
 
 		module := new(StateModule)
 		module.blockAPI = mockBlockAPI
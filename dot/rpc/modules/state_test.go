@@ -18,6 +18,7 @@
 import (
 	"errors"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/ChainSafe/gossamer/dot/rpc/modules/mocks"
@@ -26,6 +27,9 @@
 	"github.com/ChainSafe/gossamer/lib/blocktree"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/runtime"
+	mocksruntime "github.com/ChainSafe/gossamer/lib/runtime/mocks"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -280,16 +284,144 @@ type args struct {
 	}
 }
 
-// Implement Tests once function is implemented
 func TestCall(t *testing.T) {
-	ctrl := gomock.NewController(t)
+	t.Parallel()
 
-	mockNetworkAPI := mocks.NewMockNetworkAPI(ctrl)
-	mockStorageAPI := mocks.NewMockStorageAPI(ctrl)
-	sm := NewStateModule(mockNetworkAPI, mockStorageAPI, nil, nil)
+	hash := common.MustHexToHash("0x3aa96b0149b6ca3688878bdbd19464448624136398e3ce45b9e755d3ab61355a")
+	stateRoot := common.MustHexToHash("0x6a6f5b3e3e0b0f3c53e5b1bbfb5e1d1fd6a1db6ad9dd2bd8b7e48b5e4b4d8c21")
+	trieState := rtstorage.NewTrieState(trie.NewEmptyTrie())
+	errTest := errors.New("test error")
 
-	err := sm.Call(nil, nil, nil)
-	assert.NoError(t, err)
+	testCases := map[string]struct {
+		blockAPIBuilder   func(ctrl *gomock.Controller) BlockAPI
+		storageAPIBuilder func(ctrl *gomock.Controller) StorageAPI
+		request           *StateCallRequest
+		expected          StateCallResponse
+		errWrapped        error
+		errMessage        string
+	}{
+		"empty_method": {
+			blockAPIBuilder:   func(ctrl *gomock.Controller) BlockAPI { return nil },
+			storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI { return nil },
+			request:           &StateCallRequest{},
+			errWrapped:        ErrEmptyRuntimeMethod,
+			errMessage:        "runtime method name cannot be empty",
+		},
+		"invalid_data": {
+			blockAPIBuilder:   func(ctrl *gomock.Controller) BlockAPI { return nil },
+			storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI { return nil },
+			request: &StateCallRequest{
+				Method: "Core_version",
+				Data:   "0xzz",
+				Block:  &hash,
+			},
+			errMessage: "decoding call data \"0xzz\": encoding/hex: invalid byte: U+007A 'z': 0xzz",
+		},
+		"state_root_error": {
+			blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI { return nil },
+			storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
+				storageAPI := mocks.NewMockStorageAPI(ctrl)
+				storageAPI.EXPECT().GetStateRootFromBlock(&hash).Return(nil, errTest)
+				return storageAPI
+			},
+			request: &StateCallRequest{
+				Method: "Core_version",
+				Data:   "0x",
+				Block:  &hash,
+			},
+			errWrapped: errTest,
+			errMessage: "getting state root for block " + hash.String() + ": test error",
+		},
+		"runtime_error": {
+			blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+				blockAPI := mocks.NewMockBlockAPI(ctrl)
+				blockAPI.EXPECT().GetRuntime(hash).Return(nil, errTest)
+				return blockAPI
+			},
+			storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
+				storageAPI := mocks.NewMockStorageAPI(ctrl)
+				storageAPI.EXPECT().GetStateRootFromBlock(&hash).Return(&stateRoot, nil)
+				storageAPI.EXPECT().TrieState(&stateRoot).Return(trieState, nil)
+				return storageAPI
+			},
+			request: &StateCallRequest{
+				Method: "Core_version",
+				Data:   "0x",
+				Block:  &hash,
+			},
+			errWrapped: errTest,
+			errMessage: "getting runtime for block " + hash.String() + ": test error",
+		},
+		"exec_error": {
+			blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+				runtimeInstance := mocksruntime.NewMockInstance(ctrl)
+				runtimeInstance.EXPECT().SetContextStorage(trieState)
+				runtimeInstance.EXPECT().Exec("Core_version", []byte{}).Return(nil, errTest)
+				blockAPI := mocks.NewMockBlockAPI(ctrl)
+				blockAPI.EXPECT().GetRuntime(hash).Return(runtimeInstance, nil)
+				return blockAPI
+			},
+			storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
+				storageAPI := mocks.NewMockStorageAPI(ctrl)
+				storageAPI.EXPECT().GetStateRootFromBlock(&hash).Return(&stateRoot, nil)
+				storageAPI.EXPECT().TrieState(&stateRoot).Return(trieState, nil)
+				return storageAPI
+			},
+			request: &StateCallRequest{
+				Method: "Core_version",
+				Data:   "0x",
+				Block:  &hash,
+			},
+			errWrapped: errTest,
+			errMessage: "executing runtime method Core_version: test error",
+		},
+		"best_block": {
+			blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+				runtimeInstance := mocksruntime.NewMockInstance(ctrl)
+				runtimeInstance.EXPECT().SetContextStorage(trieState)
+				runtimeInstance.EXPECT().Exec("AccountNonceApi_account_nonce", []byte{1, 2}).
+					Return([]byte{3, 4}, nil)
+				blockAPI := mocks.NewMockBlockAPI(ctrl)
+				blockAPI.EXPECT().BestBlockHash().Return(hash)
+				blockAPI.EXPECT().GetRuntime(hash).Return(runtimeInstance, nil)
+				return blockAPI
+			},
+			storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
+				storageAPI := mocks.NewMockStorageAPI(ctrl)
+				storageAPI.EXPECT().GetStateRootFromBlock(&hash).Return(&stateRoot, nil)
+				storageAPI.EXPECT().TrieState(&stateRoot).Return(trieState, nil)
+				return storageAPI
+			},
+			request: &StateCallRequest{
+				Method: "AccountNonceApi_account_nonce",
+				Data:   "0x0102",
+			},
+			expected: StateCallResponse("0x0304"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			sm := NewStateModule(nil, testCase.storageAPIBuilder(ctrl), nil, testCase.blockAPIBuilder(ctrl))
+
+			var res StateCallResponse
+			err := sm.Call(nil, testCase.request, &res)
+
+			if testCase.errWrapped != nil {
+				assert.ErrorIs(t, err, testCase.errWrapped)
+			}
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.expected, res)
+		})
+	}
 }
 
 func TestStateModuleGetMetadata(t *testing.T) {
@@ -833,10 +965,11 @@ type args struct {
 		req *StateStorageQueryRangeRequest
 	}
 	tests := map[string]struct {
-		fields    fields
-		args      args
-		errRegexp string
-		exp       []StorageChangeSetResponse
+		fields     fields
+		args       args
+		errWrapped error
+		errRegexp  string
+		exp        []StorageChangeSetResponse
 	}{
 		"missing_start_block_error": {
 			fields: fields{
@@ -877,18 +1010,21 @@ type args struct {
 			fields: fields{
 				storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
 					mockStorageAPI := NewMockStorageAPI(ctrl)
-					mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{2}, []byte{1, 2, 4}).
+					mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{2}).Return(&common.Hash{12}, nil)
+					mockStorageAPI.EXPECT().GetStorage(&common.Hash{12}, []byte{1, 2, 4}).
 						Return([]byte{1, 1, 1}, nil)
-					mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{2}, []byte{9, 9, 9}).
+					mockStorageAPI.EXPECT().GetStorage(&common.Hash{12}, []byte{9, 9, 9}).
 						Return([]byte{9, 9, 9, 9}, nil)
-					mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{3}, []byte{1, 2, 4}).
+					mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{3}).Return(&common.Hash{13}, nil)
+					mockStorageAPI.EXPECT().GetChangedKeys(common.Hash{12}, common.Hash{13}).
+						Return([][]byte{{1, 2, 4}, {5}}, nil)
+					mockStorageAPI.EXPECT().GetStorage(&common.Hash{13}, []byte{1, 2, 4}).
 						Return([]byte{2, 2, 2}, nil)
-					mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{3}, []byte{9, 9, 9}).
-						Return([]byte{9, 9, 9, 9}, nil)
-					mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{4}, []byte{1, 2, 4}).
+					mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{4}).Return(&common.Hash{14}, nil)
+					mockStorageAPI.EXPECT().GetChangedKeys(common.Hash{13}, common.Hash{14}).
+						Return([][]byte{{1, 2, 4}}, nil)
+					mockStorageAPI.EXPECT().GetStorage(&common.Hash{14}, []byte{1, 2, 4}).
 						Return([]byte{3, 3, 3}, nil)
-					mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{4}, []byte{9, 9, 9}).
-						Return([]byte{9, 9, 9, 9}, nil)
 					return mockStorageAPI
 				},
 				blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
@@ -935,13 +1071,23 @@ type args struct {
 			fields: fields{
 				storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
 					mockStorageAPI := NewMockStorageAPI(ctrl)
-					mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{1}, []byte{1, 2, 4}).
+					mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{1}).Return(&common.Hash{11}, nil)
+					mockStorageAPI.EXPECT().GetStorage(&common.Hash{11}, []byte{1, 2, 4}).
 						Return([]byte{}, nil)
-					mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{2}, []byte{1, 2, 4}).
+					mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{2}).Return(&common.Hash{12}, nil)
+					mockStorageAPI.EXPECT().GetChangedKeys(common.Hash{11}, common.Hash{12}).
+						Return([][]byte{{1, 2, 4}}, nil)
+					mockStorageAPI.EXPECT().GetStorage(&common.Hash{12}, []byte{1, 2, 4}).
 						Return([]byte{1, 1, 1}, nil)
-					mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{3}, []byte{1, 2, 4}).
+					mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{3}).Return(&common.Hash{13}, nil)
+					mockStorageAPI.EXPECT().GetChangedKeys(common.Hash{12}, common.Hash{13}).
+						Return([][]byte{{1, 2, 4}}, nil)
+					mockStorageAPI.EXPECT().GetStorage(&common.Hash{13}, []byte{1, 2, 4}).
 						Return([]byte{2, 2, 2}, nil)
-					mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{4}, []byte{1, 2, 4}).
+					mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{4}).Return(&common.Hash{14}, nil)
+					mockStorageAPI.EXPECT().GetChangedKeys(common.Hash{13}, common.Hash{14}).
+						Return([][]byte{{1, 2, 4}}, nil)
+					mockStorageAPI.EXPECT().GetStorage(&common.Hash{14}, []byte{1, 2, 4}).
 						Return([]byte{3, 3, 3}, nil)
 					return mockStorageAPI
 				},
@@ -995,10 +1141,12 @@ type args struct {
 			fields: fields{
 				storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
 					mockStorageAPI := NewMockStorageAPI(ctrl)
-					mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{2}, []byte{1, 2, 4}).
-						Return([]byte{1, 1, 1}, nil)
-					mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{3}, []byte{1, 2, 4}).
+					mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{2}).Return(&common.Hash{12}, nil)
+					mockStorageAPI.EXPECT().GetStorage(&common.Hash{12}, []byte{1, 2, 4}).
 						Return([]byte{1, 1, 1}, nil)
+					mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{3}).Return(&common.Hash{12}, nil)
+					mockStorageAPI.EXPECT().GetChangedKeys(common.Hash{12}, common.Hash{12}).
+						Return(nil, nil)
 					return mockStorageAPI
 				},
 				blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
@@ -1054,6 +1202,54 @@ type args struct {
 			exp:       []StorageChangeSetResponse{},
 			errRegexp: "getting block by hash: test error",
 		},
+		"start_block/end_block/end_block_before_start_block": {
+			fields: fields{
+				storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
+					return NewMockStorageAPI(ctrl)
+				},
+				blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+					mockBlockAPI := NewMockBlockAPI(ctrl)
+					mockBlockAPI.EXPECT().GetBlockByHash(common.Hash{3}).
+						Return(&types.Block{Header: types.Header{Number: 2}}, nil)
+					mockBlockAPI.EXPECT().GetBlockByHash(common.Hash{2}).
+						Return(&types.Block{Header: types.Header{Number: 1}}, nil)
+					return mockBlockAPI
+				}},
+			args: args{
+				req: &StateStorageQueryRangeRequest{
+					Keys:       []string{"0x010204"},
+					StartBlock: common.Hash{3},
+					EndBlock:   common.Hash{2},
+				},
+			},
+			exp:        []StorageChangeSetResponse{},
+			errWrapped: ErrInvalidBlockRange,
+			errRegexp:  "invalid block range: end block number 1 is lower than start block number 2",
+		},
+		"start_block/end_block/too_many_blocks": {
+			fields: fields{
+				storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
+					return NewMockStorageAPI(ctrl)
+				},
+				blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+					mockBlockAPI := NewMockBlockAPI(ctrl)
+					mockBlockAPI.EXPECT().GetBlockByHash(common.Hash{2}).
+						Return(&types.Block{Header: types.Header{Number: 1}}, nil)
+					mockBlockAPI.EXPECT().GetBlockByHash(common.Hash{3}).
+						Return(&types.Block{Header: types.Header{Number: maxQueryStorageBlocks + 1}}, nil)
+					return mockBlockAPI
+				}},
+			args: args{
+				req: &StateStorageQueryRangeRequest{
+					Keys:       []string{"0x010204"},
+					StartBlock: common.Hash{2},
+					EndBlock:   common.Hash{3},
+				},
+			},
+			exp:        []StorageChangeSetResponse{},
+			errWrapped: ErrInvalidBlockRange,
+			errRegexp:  "invalid block range: 100001 blocks exceed the maximum of 100000 blocks",
+		},
 		"start_block/end_block/error_get_hash_by_number": {
 			fields: fields{
 				storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
@@ -1079,10 +1275,93 @@ type args struct {
 			exp:       []StorageChangeSetResponse{},
 			errRegexp: "cannot get hash by number: cannot find node with number lower than root node",
 		},
-		"start_block/end_block/error_get_storage_by_block_hash": {
+		"start_block/end_block/too_many_keys": {
+			fields: fields{
+				storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
+					return NewMockStorageAPI(ctrl)
+				},
+				blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+					mockBlockAPI := NewMockBlockAPI(ctrl)
+					mockBlockAPI.EXPECT().GetBlockByHash(common.Hash{2}).
+						Return(&types.Block{Header: types.Header{Number: 1}}, nil).Times(2)
+					mockBlockAPI.EXPECT().GetHashByNumber(uint(1)).Return(common.Hash{2}, nil)
+					return mockBlockAPI
+				}},
+			args: args{
+				req: &StateStorageQueryRangeRequest{
+					Keys:       make([]string, maxQueryStorageKeys+1),
+					StartBlock: common.Hash{2},
+					EndBlock:   common.Hash{2},
+				},
+			},
+			exp:        []StorageChangeSetResponse{},
+			errWrapped: ErrTooManyStorageKeys,
+			errRegexp:  "too many storage keys: 1001 keys exceed the maximum of 1000 keys",
+		},
+		"start_block/end_block/error_get_state_root": {
+			fields: fields{
+				storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
+					mockStorageAPI := NewMockStorageAPI(ctrl)
+					mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{2}).Return(nil, errTest)
+					return mockStorageAPI
+				},
+				blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+					mockBlockAPI := NewMockBlockAPI(ctrl)
+					mockBlockAPI.EXPECT().GetBlockByHash(common.Hash{2}).
+						Return(&types.Block{Header: types.Header{Number: 1}}, nil).Times(2)
+					mockBlockAPI.EXPECT().GetHashByNumber(uint(1)).Return(common.Hash{2}, nil)
+					return mockBlockAPI
+				}},
+			args: args{
+				req: &StateStorageQueryRangeRequest{
+					Keys:       []string{"0x010204"},
+					StartBlock: common.Hash{2},
+					EndBlock:   common.Hash{2},
+				},
+			},
+			exp:        []StorageChangeSetResponse{},
+			errWrapped: errTest,
+			errRegexp: "getting state root of block " +
+				"0x0200000000000000000000000000000000000000000000000000000000000000: test error",
+		},
+		"start_block/end_block/error_get_changed_keys": {
+			fields: fields{
+				storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
+					mockStorageAPI := NewMockStorageAPI(ctrl)
+					mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{2}).Return(&common.Hash{12}, nil)
+					mockStorageAPI.EXPECT().GetStorage(&common.Hash{12}, []byte{1, 2, 4}).
+						Return([]byte{1, 1, 1}, nil)
+					mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{3}).Return(&common.Hash{13}, nil)
+					mockStorageAPI.EXPECT().GetChangedKeys(common.Hash{12}, common.Hash{13}).
+						Return(nil, errTest)
+					return mockStorageAPI
+				},
+				blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+					mockBlockAPI := NewMockBlockAPI(ctrl)
+					mockBlockAPI.EXPECT().GetBlockByHash(common.Hash{2}).
+						Return(&types.Block{Header: types.Header{Number: 1}}, nil)
+					mockBlockAPI.EXPECT().GetBlockByHash(common.Hash{3}).
+						Return(&types.Block{Header: types.Header{Number: 2}}, nil)
+					mockBlockAPI.EXPECT().GetHashByNumber(uint(1)).Return(common.Hash{2}, nil)
+					mockBlockAPI.EXPECT().GetHashByNumber(uint(2)).Return(common.Hash{3}, nil)
+					return mockBlockAPI
+				}},
+			args: args{
+				req: &StateStorageQueryRangeRequest{
+					Keys:       []string{"0x010204"},
+					StartBlock: common.Hash{2},
+					EndBlock:   common.Hash{3},
+				},
+			},
+			exp:        []StorageChangeSetResponse{},
+			errWrapped: errTest,
+			errRegexp:  "getting changed keys between state roots 0x0c0+ and 0x0d0+: test error",
+		},
+		"start_block/end_block/error_get_storage": {
 			fields: fields{func(ctrl *gomock.Controller) StorageAPI {
 				mockStorageAPI := NewMockStorageAPI(ctrl)
-				mockStorageAPI.EXPECT().GetStorageByBlockHash(&common.Hash{2}, []byte{1, 2, 4}).Return(nil, errTest)
+				mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{2}).Return(&common.Hash{12}, nil)
+				mockStorageAPI.EXPECT().GetStorage(&common.Hash{12}, []byte{1, 2, 4}).Return(nil, errTest)
 				return mockStorageAPI
 			},
 				func(ctrl *gomock.Controller) BlockAPI {
@@ -1096,6 +1375,7 @@ func(ctrl *gomock.Controller) BlockAPI {
 						Header: types.Header{Number: 2},
 					}, nil)
 					mockBlockAPI.EXPECT().GetHashByNumber(uint(1)).Return(common.Hash{2}, nil)
+					mockBlockAPI.EXPECT().GetHashByNumber(uint(2)).Return(common.Hash{3}, nil)
 					return mockBlockAPI
 				}},
 			args: args{
@@ -1105,8 +1385,10 @@ func(ctrl *gomock.Controller) BlockAPI {
 					EndBlock:   common.Hash{3},
 				},
 			},
-			exp:       []StorageChangeSetResponse{},
-			errRegexp: "getting value by block hash: test error",
+			exp:        []StorageChangeSetResponse{},
+			errWrapped: errTest,
+			errRegexp: "getting value at block " +
+				"0x0200000000000000000000000000000000000000000000000000000000000000: test error",
 		},
 	}
 	for name, tt := range tests {
@@ -1125,7 +1407,353 @@ func(ctrl *gomock.Controller) BlockAPI {
 			} else {
 				assert.NoError(t, err)
 			}
+			if tt.errWrapped != nil {
+				assert.ErrorIs(t, err, tt.errWrapped)
+			}
 			assert.Equal(t, tt.exp, res)
 		})
 	}
 }
+
+func TestStateModuleQueryStorageAt(t *testing.T) {
+	t.Parallel()
+	errTest := errors.New("test error")
+
+	tests := map[string]struct {
+		storageAPIBuilder func(ctrl *gomock.Controller) StorageAPI
+		blockAPIBuilder   func(ctrl *gomock.Controller) BlockAPI
+		req               *StateStorageQueryAtRequest
+		errWrapped        error
+		errMessage        string
+		exp               []StorageChangeSetResponse
+	}{
+		"best_block": {
+			storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
+				mockStorageAPI := NewMockStorageAPI(ctrl)
+				mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{4}).Return(&common.Hash{14}, nil)
+				mockStorageAPI.EXPECT().GetStorage(&common.Hash{14}, []byte{1, 2, 4}).
+					Return([]byte{1, 1, 1}, nil)
+				mockStorageAPI.EXPECT().GetStorage(&common.Hash{14}, []byte{9, 9, 9}).
+					Return(nil, nil)
+				return mockStorageAPI
+			},
+			blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+				mockBlockAPI := NewMockBlockAPI(ctrl)
+				mockBlockAPI.EXPECT().BestBlockHash().Return(common.Hash{4})
+				return mockBlockAPI
+			},
+			req: &StateStorageQueryAtRequest{
+				Keys: []string{"0x010204", "0x090909"},
+			},
+			exp: []StorageChangeSetResponse{
+				{
+					Block: &common.Hash{4},
+					Changes: [][2]*string{
+						makeChange("0x010204", "0x010101"),
+						{stringPtr("0x090909"), nil},
+					},
+				},
+			},
+		},
+		"at_block": {
+			storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
+				mockStorageAPI := NewMockStorageAPI(ctrl)
+				mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{2}).Return(&common.Hash{12}, nil)
+				mockStorageAPI.EXPECT().GetStorage(&common.Hash{12}, []byte{1, 2, 4}).
+					Return([]byte{2, 2, 2}, nil)
+				return mockStorageAPI
+			},
+			blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+				mockBlockAPI := NewMockBlockAPI(ctrl)
+				mockBlockAPI.EXPECT().BestBlockHash().Return(common.Hash{4})
+				return mockBlockAPI
+			},
+			req: &StateStorageQueryAtRequest{
+				Keys: []string{"0x010204"},
+				At:   &common.Hash{2},
+			},
+			exp: []StorageChangeSetResponse{
+				{
+					Block: &common.Hash{2},
+					Changes: [][2]*string{
+						makeChange("0x010204", "0x020202"),
+					},
+				},
+			},
+		},
+		"invalid_key": {
+			storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
+				return NewMockStorageAPI(ctrl)
+			},
+			blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+				mockBlockAPI := NewMockBlockAPI(ctrl)
+				mockBlockAPI.EXPECT().BestBlockHash().Return(common.Hash{4})
+				return mockBlockAPI
+			},
+			req: &StateStorageQueryAtRequest{
+				Keys: []string{"010204"},
+			},
+			errWrapped: common.ErrNoPrefix,
+			errMessage: "decoding key 010204: could not byteify non 0x prefixed string: 010204",
+		},
+		"get_storage_error": {
+			storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
+				mockStorageAPI := NewMockStorageAPI(ctrl)
+				mockStorageAPI.EXPECT().GetStateRootFromBlock(&common.Hash{4}).Return(&common.Hash{14}, nil)
+				mockStorageAPI.EXPECT().GetStorage(&common.Hash{14}, []byte{1, 2, 4}).
+					Return(nil, errTest)
+				return mockStorageAPI
+			},
+			blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+				mockBlockAPI := NewMockBlockAPI(ctrl)
+				mockBlockAPI.EXPECT().BestBlockHash().Return(common.Hash{4})
+				return mockBlockAPI
+			},
+			req: &StateStorageQueryAtRequest{
+				Keys: []string{"0x010204"},
+			},
+			errWrapped: errTest,
+			errMessage: "getting value at block " +
+				"0x0400000000000000000000000000000000000000000000000000000000000000: test error",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			sm := &StateModule{
+				storageAPI: tt.storageAPIBuilder(ctrl),
+				blockAPI:   tt.blockAPIBuilder(ctrl),
+			}
+			var res []StorageChangeSetResponse
+			err := sm.QueryStorageAt(nil, tt.req, &res)
+			assert.ErrorIs(t, err, tt.errWrapped)
+			if tt.errWrapped != nil {
+				assert.EqualError(t, err, tt.errMessage)
+			}
+			assert.Equal(t, tt.exp, res)
+		})
+	}
+}
+
+func TestStateModuleTraceBlock(t *testing.T) {
+	t.Parallel()
+	errTest := errors.New("test error")
+
+	blockHash := common.Hash{2}
+	parentHash := common.Hash{1}
+	parentStateRoot := common.Hash{11}
+	block := &types.Block{
+		Header: types.Header{
+			ParentHash: parentHash,
+			Number:     2,
+		},
+	}
+
+	// executeBlock simulates the runtime execution of the block
+	// by accessing the storage and logging messages.
+	executeBlock := func(storage *runtime.Storage) func(*types.Block) ([]byte, error) {
+		return func(*types.Block) ([]byte, error) {
+			recorder := (*storage).(runtime.LogRecorder)
+			recorder.RecordLog(2, "pallet_balances", "transfer")
+			recorder.RecordLog(3, "runtime", "ignored")
+			_ = (*storage).Get([]byte{1, 2})
+			_ = (*storage).Put([]byte{3, 4}, []byte{5})
+			_ = (*storage).Delete([]byte{1, 2})
+			return nil, nil
+		}
+	}
+
+	blockAPIBuilder := func(ctrl *gomock.Controller) BlockAPI {
+		var storage runtime.Storage
+		runtimeInstance := mocksruntime.NewMockInstance(ctrl)
+		runtimeInstance.EXPECT().SetContextStorage(gomock.Any()).
+			Do(func(s runtime.Storage) { storage = s })
+		runtimeInstance.EXPECT().ExecuteBlock(block).DoAndReturn(executeBlock(&storage))
+		mockBlockAPI := NewMockBlockAPI(ctrl)
+		mockBlockAPI.EXPECT().GetBlockByHash(blockHash).Return(block, nil)
+		mockBlockAPI.EXPECT().GetHeader(parentHash).
+			Return(&types.Header{StateRoot: parentStateRoot}, nil)
+		mockBlockAPI.EXPECT().GetRuntime(parentHash).Return(runtimeInstance, nil)
+		return mockBlockAPI
+	}
+
+	storageAPIBuilder := func(ctrl *gomock.Controller) StorageAPI {
+		trieState := rtstorage.NewTrieState(trie.NewEmptyTrie())
+		err := trieState.Put([]byte{1, 2}, []byte{9})
+		if err != nil {
+			panic(err)
+		}
+		mockStorageAPI := NewMockStorageAPI(ctrl)
+		mockStorageAPI.EXPECT().TrieState(&parentStateRoot).Return(trieState, nil)
+		return mockStorageAPI
+	}
+
+	rootSpanID := traceBlockRootSpanID
+	rootSpan := TraceSpan{ID: rootSpanID, Name: "execute_block", Target: "state_tracing"}
+	transferSpan := TraceSpan{ID: 2, ParentID: &rootSpanID, Name: "transfer", Target: "pallet_balances", Wasm: true}
+	newEvent := func(values map[string]string) TraceEvent {
+		return TraceEvent{
+			Target:   "state",
+			Data:     TraceEventData{StringValues: values},
+			ParentID: &rootSpanID,
+		}
+	}
+
+	tests := map[string]struct {
+		storageAPIBuilder func(ctrl *gomock.Controller) StorageAPI
+		blockAPIBuilder   func(ctrl *gomock.Controller) BlockAPI
+		req               *StateTraceBlockRequest
+		errWrapped        error
+		errMessage        string
+		exp               TraceBlockResponse
+	}{
+		"get_block_error": {
+			storageAPIBuilder: func(ctrl *gomock.Controller) StorageAPI {
+				return NewMockStorageAPI(ctrl)
+			},
+			blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+				mockBlockAPI := NewMockBlockAPI(ctrl)
+				mockBlockAPI.EXPECT().GetBlockByHash(blockHash).Return(nil, errTest)
+				return mockBlockAPI
+			},
+			req:        &StateTraceBlockRequest{Block: blockHash},
+			errWrapped: errTest,
+			errMessage: "getting block: test error",
+		},
+		"execute_block_error": {
+			storageAPIBuilder: storageAPIBuilder,
+			blockAPIBuilder: func(ctrl *gomock.Controller) BlockAPI {
+				runtimeInstance := mocksruntime.NewMockInstance(ctrl)
+				runtimeInstance.EXPECT().SetContextStorage(gomock.Any())
+				runtimeInstance.EXPECT().ExecuteBlock(block).Return(nil, errTest)
+				mockBlockAPI := NewMockBlockAPI(ctrl)
+				mockBlockAPI.EXPECT().GetBlockByHash(blockHash).Return(block, nil)
+				mockBlockAPI.EXPECT().GetHeader(parentHash).
+					Return(&types.Header{StateRoot: parentStateRoot}, nil)
+				mockBlockAPI.EXPECT().GetRuntime(parentHash).Return(runtimeInstance, nil)
+				return mockBlockAPI
+			},
+			req:        &StateTraceBlockRequest{Block: blockHash},
+			errWrapped: errTest,
+			errMessage: "executing block: test error",
+		},
+		"default_filters": {
+			storageAPIBuilder: storageAPIBuilder,
+			blockAPIBuilder:   blockAPIBuilder,
+			req:               &StateTraceBlockRequest{Block: blockHash},
+			exp: TraceBlockResponse{
+				BlockTrace: &BlockTrace{
+					BlockHash:      blockHash.String(),
+					ParentHash:     parentHash.String(),
+					TracingTargets: "pallet,frame,state",
+					Spans:          []TraceSpan{rootSpan, transferSpan},
+					Events: []TraceEvent{
+						newEvent(map[string]string{"method": "Get", "key": "0102", "result": "09"}),
+						newEvent(map[string]string{"method": "Put", "key": "0304", "value": "05"}),
+						newEvent(map[string]string{"method": "Clear", "key": "0102"}),
+					},
+				},
+			},
+		},
+		"filters": {
+			storageAPIBuilder: storageAPIBuilder,
+			blockAPIBuilder:   blockAPIBuilder,
+			req: &StateTraceBlockRequest{
+				Block:       blockHash,
+				Targets:     stringPtr("state,runtime=debug"),
+				StorageKeys: stringPtr("0x01"),
+				Methods:     stringPtr("Get,Put"),
+			},
+			exp: TraceBlockResponse{
+				BlockTrace: &BlockTrace{
+					BlockHash:      blockHash.String(),
+					ParentHash:     parentHash.String(),
+					TracingTargets: "state,runtime=debug",
+					StorageKeys:    "0x01",
+					Methods:        "Get,Put",
+					Spans: []TraceSpan{
+						rootSpan,
+						{ID: 2, ParentID: &rootSpanID, Name: "ignored", Target: "runtime", Wasm: true},
+					},
+					Events: []TraceEvent{
+						newEvent(map[string]string{"method": "Get", "key": "0102", "result": "09"}),
+					},
+				},
+			},
+		},
+		"no_storage_target": {
+			storageAPIBuilder: storageAPIBuilder,
+			blockAPIBuilder:   blockAPIBuilder,
+			req: &StateTraceBlockRequest{
+				Block:   blockHash,
+				Targets: stringPtr("pallet"),
+			},
+			exp: TraceBlockResponse{
+				BlockTrace: &BlockTrace{
+					BlockHash:      blockHash.String(),
+					ParentHash:     parentHash.String(),
+					TracingTargets: "pallet",
+					Spans:          []TraceSpan{rootSpan, transferSpan},
+					Events:         []TraceEvent{},
+				},
+			},
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			sm := &StateModule{
+				storageAPI: tt.storageAPIBuilder(ctrl),
+				blockAPI:   tt.blockAPIBuilder(ctrl),
+			}
+			var res TraceBlockResponse
+			err := sm.TraceBlock(nil, tt.req, &res)
+			assert.ErrorIs(t, err, tt.errWrapped)
+			if tt.errWrapped != nil {
+				assert.EqualError(t, err, tt.errMessage)
+			}
+			assert.Equal(t, tt.exp, res)
+		})
+	}
+}
+
+func TestStateModuleTraceBlock_tooLarge(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	blockHash := common.Hash{2}
+	block := &types.Block{Header: types.Header{ParentHash: common.Hash{1}}}
+	message := strings.Repeat("a", maxTraceBlockResponseSize)
+
+	var storage runtime.Storage
+	runtimeInstance := mocksruntime.NewMockInstance(ctrl)
+	runtimeInstance.EXPECT().SetContextStorage(gomock.Any()).
+		Do(func(s runtime.Storage) { storage = s })
+	runtimeInstance.EXPECT().ExecuteBlock(block).DoAndReturn(func(*types.Block) ([]byte, error) {
+		storage.(runtime.LogRecorder).RecordLog(2, "pallet", message)
+		return nil, nil
+	})
+	mockBlockAPI := NewMockBlockAPI(ctrl)
+	mockBlockAPI.EXPECT().GetBlockByHash(blockHash).Return(block, nil)
+	mockBlockAPI.EXPECT().GetHeader(common.Hash{1}).Return(&types.Header{StateRoot: common.Hash{11}}, nil)
+	mockBlockAPI.EXPECT().GetRuntime(common.Hash{1}).Return(runtimeInstance, nil)
+	mockStorageAPI := NewMockStorageAPI(ctrl)
+	mockStorageAPI.EXPECT().TrieState(&common.Hash{11}).
+		Return(rtstorage.NewTrieState(trie.NewEmptyTrie()), nil)
+
+	sm := &StateModule{
+		storageAPI: mockStorageAPI,
+		blockAPI:   mockBlockAPI,
+	}
+
+	var res TraceBlockResponse
+	err := sm.TraceBlock(nil, &StateTraceBlockRequest{Block: blockHash}, &res)
+	assert.NoError(t, err)
+	assert.Nil(t, res.BlockTrace)
+	assert.Equal(t, &TraceError{Error: "block trace of 15729042 bytes exceeds the maximum of 15728640 bytes"},
+		res.TraceError)
+}
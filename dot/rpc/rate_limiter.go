@@ -0,0 +1,167 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	gorillajson2 "github.com/gorilla/rpc/v2/json2"
+)
+
+// ipRateLimiter limits the rate of requests of each IP address,
+// using a token bucket per IP address.
+type ipRateLimiter struct {
+	mutex sync.Mutex
+	// rate is the number of tokens added to a bucket per second.
+	rate float64
+	// burst is the maximum number of tokens of a bucket.
+	burst       float64
+	buckets     map[string]*tokenBucket
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// rateLimiterCleanupInterval is the interval at which the buckets
+// refilled since their last request are removed.
+const rateLimiterCleanupInterval = time.Minute
+
+// newIPRateLimiter creates a rate limiter allowing the given number of requests
+// per second for each IP address, with bursts of up to the given burst size.
+// The burst size defaults to the rate if it is zero.
+func newIPRateLimiter(rate, burst uint32) *ipRateLimiter {
+	if burst == 0 {
+		burst = rate
+	}
+
+	return &ipRateLimiter{
+		rate:        float64(rate),
+		burst:       float64(burst),
+		buckets:     make(map[string]*tokenBucket),
+		lastCleanup: time.Now(),
+		now:         time.Now,
+	}
+}
+
+// allow returns true if a request of the given IP address is allowed,
+// consuming a token of its bucket.
+func (l *ipRateLimiter) allow(ip string) bool {
+	return l.allowN(ip, 1)
+}
+
+// allowN returns true if n requests of the given IP address are allowed,
+// consuming n tokens of its bucket. No token is consumed if the bucket
+// has less than n tokens.
+func (l *ipRateLimiter) allowN(ip string, n uint) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastCleanup) >= rateLimiterCleanupInterval {
+		l.cleanup(now)
+	}
+
+	bucket, ok := l.buckets[ip]
+	if !ok {
+		bucket = &tokenBucket{
+			tokens:     l.burst,
+			lastRefill: now,
+		}
+		l.buckets[ip] = bucket
+	}
+
+	elapsed := now.Sub(bucket.lastRefill).Seconds()
+	bucket.tokens = math.Min(l.burst, bucket.tokens+elapsed*l.rate)
+	bucket.lastRefill = now
+
+	if bucket.tokens < float64(n) {
+		return false
+	}
+
+	bucket.tokens -= float64(n)
+	return true
+}
+
+// cleanup removes the buckets which would be full by now, since they
+// are equivalent to the new bucket created for the next request.
+// It is NOT THREAD SAFE to use, and the mutex must be held.
+func (l *ipRateLimiter) cleanup(now time.Time) {
+	for ip, bucket := range l.buckets {
+		elapsed := now.Sub(bucket.lastRefill).Seconds()
+		if bucket.tokens+elapsed*l.rate >= l.burst {
+			delete(l.buckets, ip)
+		}
+	}
+	l.lastCleanup = now
+}
+
+// connRateLimiter limits the rate of requests received on a websocket
+// connection, as requests of the IP address of the connection.
+type connRateLimiter struct {
+	limiter *ipRateLimiter
+	ip      string
+}
+
+// Allow returns true if a request received on the connection is allowed.
+func (c *connRateLimiter) Allow() bool {
+	return c.limiter.allow(c.ip)
+}
+
+// rateLimitHandler responds with a too many requests error to requests
+// exceeding the rate limit of their IP address, and serves other requests
+// with the wrapped handler. Each request of a batch counts as a request,
+// and batches with more requests than the burst size are rejected since
+// they could never be allowed.
+type rateLimitHandler struct {
+	handler http.Handler
+	limiter *ipRateLimiter
+}
+
+func (h *rateLimitHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		logger.Errorf("unable to parse remote address %s: %s", r.RemoteAddr, err)
+		http.Error(w, "unable to parse remote address", http.StatusBadRequest)
+		return
+	}
+
+	requests := uint(1)
+	if r.Method == http.MethodPost {
+		var body *requestBody
+		body, r, err = readRequestBody(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("reading request body: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		// invalid and empty batches are counted as a single request
+		if len(body.batch) > 0 {
+			requests = uint(len(body.batch))
+		}
+	}
+
+	if float64(requests) > h.limiter.burst {
+		writeErrorResponse(w, nil, gorillajson2.E_INVALID_REQ,
+			fmt.Sprintf("batch of %d requests exceeds the rate limit burst of %d",
+				requests, uint(h.limiter.burst)))
+		return
+	}
+
+	if !h.limiter.allowN(ip, requests) {
+		logger.Debugf("rate limit exceeded for %s", ip)
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+
+	h.handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ipRateLimiter_allow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	limiter := newIPRateLimiter(2, 3)
+	limiter.now = func() time.Time { return now }
+	limiter.lastCleanup = now
+
+	// burst of 3 requests
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.allow("1.1.1.1"))
+	}
+	assert.False(t, limiter.allow("1.1.1.1"))
+
+	// other IP addresses have their own bucket
+	assert.True(t, limiter.allow("2.2.2.2"))
+
+	// one token is added every half second
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, limiter.allow("1.1.1.1"))
+	assert.False(t, limiter.allow("1.1.1.1"))
+
+	// buckets are refilled up to the burst size
+	now = now.Add(10 * time.Second)
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.allow("1.1.1.1"))
+	}
+	assert.False(t, limiter.allow("1.1.1.1"))
+	assert.Len(t, limiter.buckets, 2)
+
+	// full buckets are removed on cleanup
+	now = now.Add(rateLimiterCleanupInterval)
+	assert.True(t, limiter.allow("1.1.1.1"))
+	assert.Len(t, limiter.buckets, 1)
+}
+
+func Test_ipRateLimiter_allowN(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	limiter := newIPRateLimiter(2, 3)
+	limiter.now = func() time.Time { return now }
+	limiter.lastCleanup = now
+
+	assert.True(t, limiter.allowN("1.1.1.1", 2))
+	// no token is consumed if there are not enough tokens
+	assert.False(t, limiter.allowN("1.1.1.1", 2))
+	assert.True(t, limiter.allowN("1.1.1.1", 1))
+	assert.False(t, limiter.allowN("1.1.1.1", 1))
+}
+
+func Test_newIPRateLimiter_defaultBurst(t *testing.T) {
+	t.Parallel()
+
+	limiter := newIPRateLimiter(5, 0)
+
+	assert.Equal(t, float64(5), limiter.rate)
+	assert.Equal(t, float64(5), limiter.burst)
+}
+
+func Test_rateLimitHandler_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	testCases := map[string]struct {
+		burst      uint32
+		remoteAddr string
+		body       string
+		statuses   []int
+		response   string
+	}{
+		"invalid_remote_address": {
+			remoteAddr: "1.1.1.1",
+			statuses:   []int{http.StatusBadRequest},
+		},
+		"rate_limited": {
+			remoteAddr: "1.1.1.1:1234",
+			statuses:   []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		"loopback_rate_limited": {
+			remoteAddr: "127.0.0.1:1234",
+			statuses:   []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		"batch_requests_rate_limited": {
+			burst:      2,
+			remoteAddr: "1.1.1.1:1234",
+			body:       `[{"jsonrpc":"2.0","id":1,"method":"a"},{"jsonrpc":"2.0","id":2,"method":"b"}]`,
+			statuses:   []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		"batch_exceeding_burst": {
+			remoteAddr: "1.1.1.1:1234",
+			body:       `[{"jsonrpc":"2.0","id":1,"method":"a"},{"jsonrpc":"2.0","id":2,"method":"b"}]`,
+			statuses:   []int{http.StatusOK},
+			response: `{"jsonrpc":"2.0","error":{"code":-32600,` +
+				`"message":"batch of 2 requests exceeds the rate limit burst of 1","data":null},"id":null}` + "\n",
+		},
+		"invalid_batch_counted_once": {
+			remoteAddr: "1.1.1.1:1234",
+			body:       `[`,
+			statuses:   []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := &rateLimitHandler{
+				handler: okHandler,
+				limiter: newIPRateLimiter(1, testCase.burst),
+			}
+
+			var recorder *httptest.ResponseRecorder
+			for _, status := range testCase.statuses {
+				request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(testCase.body))
+				request.RemoteAddr = testCase.remoteAddr
+				recorder = httptest.NewRecorder()
+
+				handler.ServeHTTP(recorder, request)
+
+				assert.Equal(t, status, recorder.Code)
+			}
+
+			if testCase.response != "" {
+				assert.Equal(t, testCase.response, recorder.Body.String())
+			}
+		})
+	}
+}
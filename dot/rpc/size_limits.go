@@ -0,0 +1,109 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ChainSafe/gossamer/dot/rpc/subscription"
+	gorillajson2 "github.com/gorilla/rpc/v2/json2"
+)
+
+// sizeLimitHandler rejects requests with a body larger than the maximum
+// request size, and replaces responses with a body larger than the maximum
+// response size by an error response. A zero maximum size means the size
+// is not limited.
+type sizeLimitHandler struct {
+	handler         http.Handler
+	maxRequestSize  uint64
+	maxResponseSize uint64
+}
+
+func (h *sizeLimitHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.maxRequestSize == 0 && h.maxResponseSize == 0 {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	if h.maxRequestSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, int64(h.maxRequestSize))
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			http.Error(w, fmt.Sprintf("request body exceeds the maximum of %d bytes", maxBytesError.Limit),
+				http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("reading request body: %s", err), http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if h.maxResponseSize == 0 {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	limitedWriter := &limitedResponseWriter{
+		ResponseWriter: w,
+		status:         http.StatusOK,
+		maxSize:        h.maxResponseSize,
+	}
+	h.handler.ServeHTTP(limitedWriter, r)
+
+	if limitedWriter.exceeded {
+		// the request id is null for batches, which cannot be decoded here.
+		var request struct {
+			ID *json.RawMessage `json:"id"`
+		}
+		_ = json.Unmarshal(body, &request)
+
+		logger.Debugf("response exceeds the maximum of %d bytes", h.maxResponseSize)
+		writeErrorResponse(w, request.ID, gorillajson2.ErrorCode(subscription.LimitExceededCode),
+			subscription.ResponseTooLargeMessage)
+		return
+	}
+
+	w.WriteHeader(limitedWriter.status)
+	_, err = w.Write(limitedWriter.body.Bytes())
+	if err != nil {
+		logger.Debugf("failed to write response: %s", err)
+	}
+}
+
+// limitedResponseWriter buffers the response body, up to the maximum size.
+// Headers are set on the wrapped response writer.
+type limitedResponseWriter struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	maxSize  uint64
+	exceeded bool
+}
+
+func (l *limitedResponseWriter) WriteHeader(status int) {
+	l.status = status
+}
+
+func (l *limitedResponseWriter) Write(data []byte) (int, error) {
+	if l.exceeded {
+		return len(data), nil
+	}
+
+	if uint64(l.body.Len())+uint64(len(data)) > l.maxSize {
+		l.exceeded = true
+		l.body.Reset()
+		return len(data), nil
+	}
+
+	return l.body.Write(data)
+}
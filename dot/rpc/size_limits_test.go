@@ -0,0 +1,92 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_sizeLimitHandler_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	// echoHandler responds with the request body twice.
+	echoHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write(body)
+		_, _ = w.Write(body)
+	})
+
+	testCases := map[string]struct {
+		maxRequestSize  uint64
+		maxResponseSize uint64
+		body            string
+		status          int
+		response        string
+	}{
+		"no_limit": {
+			body:     `{"id":1}`,
+			status:   http.StatusAccepted,
+			response: `{"id":1}{"id":1}`,
+		},
+		"request_too_large": {
+			maxRequestSize: 7,
+			body:           `{"id":1}`,
+			status:         http.StatusRequestEntityTooLarge,
+			response:       "request body exceeds the maximum of 7 bytes\n",
+		},
+		"response_too_large": {
+			maxRequestSize:  8,
+			maxResponseSize: 15,
+			body:            `{"id":1}`,
+			status:          http.StatusOK,
+			response: `{"jsonrpc":"2.0","error":{"code":-32005,` +
+				`"message":"Response is too large","data":null},"id":1}` + "\n",
+		},
+		"batch_response_too_large": {
+			maxResponseSize: 15,
+			body:            `[{"id":1}]`,
+			status:          http.StatusOK,
+			response: `{"jsonrpc":"2.0","error":{"code":-32005,` +
+				`"message":"Response is too large","data":null},"id":null}` + "\n",
+		},
+		"within_limits": {
+			maxRequestSize:  8,
+			maxResponseSize: 16,
+			body:            `{"id":1}`,
+			status:          http.StatusAccepted,
+			response:        `{"id":1}{"id":1}`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := &sizeLimitHandler{
+				handler:         echoHandler,
+				maxRequestSize:  testCase.maxRequestSize,
+				maxResponseSize: testCase.maxResponseSize,
+			}
+
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(testCase.body))
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, testCase.status, recorder.Code)
+			assert.Equal(t, testCase.response, recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package subscription
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// maxBatchConcurrency is the maximum number of requests of a batch
+// handled concurrently.
+const maxBatchConcurrency = 16
+
+// isBatchMessage returns true if the message is a JSON-RPC batch,
+// which is a JSON array of requests.
+func isBatchMessage(rawBytes []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(rawBytes, " \t\r\n"), []byte("["))
+}
+
+// wsBatch collects the messages sent on the websocket connection while
+// the requests of a batch are handled. Responses are matched to the batch
+// requests using their ids, and all other messages are notifications to
+// send once the batch response is sent.
+type wsBatch struct {
+	ids           []float64
+	responses     []json.RawMessage
+	notifications []json.RawMessage
+}
+
+func newWSBatch(size int) *wsBatch {
+	return &wsBatch{
+		ids:       make([]float64, size),
+		responses: make([]json.RawMessage, size),
+	}
+}
+
+// add adds the JSON encoded message as the response of the first batch request
+// with the same id and no response yet, or as a notification otherwise.
+// It is NOT THREAD SAFE to use, and the connection lock must be held.
+func (b *wsBatch) add(encoded json.RawMessage) {
+	responseID := messageID(encoded)
+	if responseID != nil {
+		for i, id := range b.ids {
+			if id == *responseID && b.responses[i] == nil {
+				b.responses[i] = encoded
+				return
+			}
+		}
+	}
+
+	b.notifications = append(b.notifications, encoded)
+}
+
+// handleBatch handles the requests of a JSON-RPC batch concurrently, and then
+// sends the array of their responses in the order of the requests. Listeners
+// created by the batch requests only start once the batch response is sent.
+func (c *WSConn) handleBatch(rawBytes []byte) {
+	var rawMessages []json.RawMessage
+	err := json.Unmarshal(rawBytes, &rawMessages)
+	if err != nil || len(rawMessages) == 0 {
+		logger.Debugf("websocket failed to parse batch: %v", err)
+		c.safeSendError(0, big.NewInt(InvalidRequestCode), InvalidRequestMessage)
+		return
+	}
+
+	if c.MaxBatchSize > 0 && uint64(len(rawMessages)) > uint64(c.MaxBatchSize) {
+		c.safeSendError(0, big.NewInt(InvalidRequestCode),
+			fmt.Sprintf("batch of %d requests exceeds the maximum batch size of %d",
+				len(rawMessages), c.MaxBatchSize))
+		return
+	}
+
+	batch := newWSBatch(len(rawMessages))
+	wsMessages := make([]*websocketMessage, len(rawMessages))
+	for i, rawMessage := range rawMessages {
+		wsMessage, err := parseWebsocketMessage(rawMessage)
+		if err != nil {
+			logger.Debugf("websocket failed to parse batch request: %s", err)
+			// the response is set directly since it cannot be matched by id
+			batch.responses[i], err = json.Marshal(
+				newErrorResponseJSON(0, big.NewInt(InvalidRequestCode), InvalidRequestMessage))
+			if err != nil {
+				logger.Debugf("error encoding websocket message: %s", err)
+			}
+			continue
+		}
+
+		batch.ids[i] = wsMessage.ID
+
+		// each request of the batch consumes a request of the rate limit
+		if !c.allowRequest() {
+			batch.responses[i], err = json.Marshal(
+				newErrorResponseJSON(wsMessage.ID, big.NewInt(LimitExceededCode), RateLimitExceededMessage))
+			if err != nil {
+				logger.Debugf("error encoding websocket message: %s", err)
+			}
+			continue
+		}
+
+		wsMessages[i] = wsMessage
+	}
+
+	c.mu.Lock()
+	c.batch = batch
+	c.mu.Unlock()
+
+	listeners := make([]Listener, len(rawMessages))
+	semaphore := make(chan struct{}, maxBatchConcurrency)
+	var wg sync.WaitGroup
+	for i, wsMessage := range wsMessages {
+		if wsMessage == nil {
+			continue
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, rawMessage []byte, wsMessage *websocketMessage) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			listeners[i] = c.handleMessage(rawMessage, wsMessage)
+		}(i, rawMessages[i], wsMessage)
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	c.batch = nil
+	c.sendBatch(batch)
+	c.mu.Unlock()
+
+	for _, listener := range listeners {
+		if listener != nil {
+			listener.Listen()
+		}
+	}
+}
+
+// sendBatch sends the responses of the batch requests in a single array,
+// omitting requests without response, followed by the notifications
+// collected while handling the batch.
+// It is NOT THREAD SAFE to use, and the connection lock must be held.
+func (c *WSConn) sendBatch(batch *wsBatch) {
+	responses := make([]json.RawMessage, 0, len(batch.responses))
+	for _, response := range batch.responses {
+		if response != nil {
+			responses = append(responses, response)
+		}
+	}
+
+	if len(responses) > 0 {
+		encoded, err := json.Marshal(responses)
+		if err != nil {
+			logger.Debugf("error encoding websocket batch response: %s", err)
+		} else {
+			c.writeMessage(encoded)
+		}
+	}
+
+	for _, notification := range batch.notifications {
+		c.writeMessage(notification)
+	}
+}
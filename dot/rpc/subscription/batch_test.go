@@ -0,0 +1,83 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package subscription
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_wsBatch_add(t *testing.T) {
+	t.Parallel()
+
+	batch := newWSBatch(3)
+	batch.ids = []float64{1, 2, 1}
+
+	batch.add(json.RawMessage(`{"jsonrpc":"2.0","result":true,"id":1}`))
+	batch.add(json.RawMessage(`{"jsonrpc":"2.0","method":"method","params":{"result":"result","subscription":1}}`))
+	batch.add(json.RawMessage(`{"jsonrpc":"2.0","result":false,"id":1}`))
+	batch.add(json.RawMessage(`{"jsonrpc":"2.0","result":false,"id":3}`))
+
+	expectedResponses := []json.RawMessage{
+		json.RawMessage(`{"jsonrpc":"2.0","result":true,"id":1}`),
+		nil,
+		json.RawMessage(`{"jsonrpc":"2.0","result":false,"id":1}`),
+	}
+	assert.Equal(t, expectedResponses, batch.responses)
+
+	expectedNotifications := []json.RawMessage{
+		json.RawMessage(`{"jsonrpc":"2.0","method":"method","params":{"result":"result","subscription":1}}`),
+		json.RawMessage(`{"jsonrpc":"2.0","result":false,"id":3}`),
+	}
+	assert.Equal(t, expectedNotifications, batch.notifications)
+}
+
+func TestWSConn_HandleConn_batch(t *testing.T) {
+	t.Parallel()
+
+	wsconn, ws, cancel := setupWSConn(t)
+	defer cancel()
+	wsconn.Subscriptions = make(map[uint32]Listener)
+	wsconn.MaxBatchSize = 3
+
+	go wsconn.HandleConn()
+
+	testCases := []struct {
+		sentMessage string
+		expected    string
+	}{
+		{
+			sentMessage: `[]`,
+			expected:    `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid request"},"id":0}`,
+		},
+		{
+			sentMessage: `[{"id":1},{"id":2},{"id":3},{"id":4}]`,
+			expected: `{"jsonrpc":"2.0","error":{"code":-32600,` +
+				`"message":"batch of 4 requests exceeds the maximum batch size of 3"},"id":0}`,
+		},
+		{
+			sentMessage: `[
+				{"jsonrpc":"2.0","id":1,"method":"state_unsubscribeStorage","params":["5"]},
+				{"jsonrpc":"2.0","id":2},
+				{"jsonrpc":"2.0","id":3,"method":"chainHead_unstable_unpin","params":["5","0x01"]}
+			]`,
+			expected: `[{"jsonrpc":"2.0","result":false,"id":1},` +
+				`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid request"},"id":0},` +
+				`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid request"},"id":3}]`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := ws.WriteMessage(websocket.TextMessage, []byte(testCase.sentMessage))
+		require.NoError(t, err)
+
+		_, message, err := ws.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected+"\n", string(message))
+	}
+}
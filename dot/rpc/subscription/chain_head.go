@@ -0,0 +1,899 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package subscription
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+const (
+	chainHeadFollowEventMethod  = "chainHead_unstable_followEvent"
+	chainHeadBodyEventMethod    = "chainHead_unstable_bodyEvent"
+	chainHeadStorageEventMethod = "chainHead_unstable_storageEvent"
+	chainHeadCallEventMethod    = "chainHead_unstable_callEvent"
+
+	// maxPinnedBlocks is the maximum number of blocks a follow subscription
+	// can hold pinned before it gets stopped.
+	maxPinnedBlocks = 512
+)
+
+var (
+	errTooManyPinnedBlocks   = errors.New("too many pinned blocks")
+	errFollowListenerStopped = errors.New("follow subscription is stopped")
+	errBlockNotPinned        = errors.New("block is not pinned")
+	errInvalidHashLength     = errors.New("invalid hash length")
+)
+
+type chainHeadHandler func(reqID float64, params interface{})
+
+func (c *WSConn) getChainHeadHandler(method string) chainHeadHandler {
+	switch method {
+	case chainHeadUnstableUnfollow:
+		return c.handleChainHeadUnfollow
+	case chainHeadUnstableHeader:
+		return c.handleChainHeadHeader
+	case chainHeadUnstableBody:
+		return c.handleChainHeadBody
+	case chainHeadUnstableStorage:
+		return c.handleChainHeadStorage
+	case chainHeadUnstableCall:
+		return c.handleChainHeadCall
+	case chainHeadUnstableUnpin:
+		return c.handleChainHeadUnpin
+	default:
+		return nil
+	}
+}
+
+// chainHeadRuntime is the runtime field of the initialized and newBlock events.
+type chainHeadRuntime struct {
+	Type string                `json:"type"`
+	Spec *chainHeadRuntimeSpec `json:"spec,omitempty"`
+}
+
+type chainHeadRuntimeSpec struct {
+	SpecName           string            `json:"specName"`
+	ImplName           string            `json:"implName"`
+	AuthoringVersion   uint32            `json:"authoringVersion"`
+	SpecVersion        uint32            `json:"specVersion"`
+	ImplVersion        uint32            `json:"implVersion"`
+	TransactionVersion uint32            `json:"transactionVersion"`
+	Apis               map[string]uint32 `json:"apis"`
+}
+
+func newChainHeadRuntime(version runtime.Version) *chainHeadRuntime {
+	apis := make(map[string]uint32, len(version.APIItems))
+	for _, apiItem := range version.APIItems {
+		apis[common.BytesToHex(apiItem.Name[:])] = apiItem.Ver
+	}
+
+	return &chainHeadRuntime{
+		Type: "valid",
+		Spec: &chainHeadRuntimeSpec{
+			SpecName:           string(version.SpecName),
+			ImplName:           string(version.ImplName),
+			AuthoringVersion:   version.AuthoringVersion,
+			SpecVersion:        version.SpecVersion,
+			ImplVersion:        version.ImplVersion,
+			TransactionVersion: version.TransactionVersion,
+			Apis:               apis,
+		},
+	}
+}
+
+type chainHeadInitializedEvent struct {
+	Event                 string            `json:"event"`
+	FinalizedBlockHash    common.Hash       `json:"finalizedBlockHash"`
+	FinalizedBlockRuntime *chainHeadRuntime `json:"finalizedBlockRuntime,omitempty"`
+}
+
+type chainHeadNewBlockEvent struct {
+	Event           string            `json:"event"`
+	BlockHash       common.Hash       `json:"blockHash"`
+	ParentBlockHash common.Hash       `json:"parentBlockHash"`
+	NewRuntime      *chainHeadRuntime `json:"newRuntime"`
+}
+
+type chainHeadBestBlockChangedEvent struct {
+	Event         string      `json:"event"`
+	BestBlockHash common.Hash `json:"bestBlockHash"`
+}
+
+type chainHeadFinalizedEvent struct {
+	Event                string        `json:"event"`
+	FinalizedBlockHashes []common.Hash `json:"finalizedBlockHashes"`
+	PrunedBlockHashes    []common.Hash `json:"prunedBlockHashes"`
+}
+
+type chainHeadStopEvent struct {
+	Event string `json:"event"`
+}
+
+// chainHeadOperationEvent is the event sent for body, storage and call operations.
+type chainHeadOperationEvent struct {
+	Event  string      `json:"event"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// chainHeadBlock is a block pinned by a follow subscription, holding
+// everything needed to serve requests on it after it gets pruned.
+// Its state trie nodes are kept in the database by pinning the number of
+// one of its finalised ancestors, until unpinState is called.
+type chainHeadBlock struct {
+	header          types.Header
+	body            types.Body
+	trie            *trie.Trie
+	runtime         state.Runtime
+	finalisedNumber uint
+	unpinState      func()
+}
+
+// chainHeadReportedBlock is a block reported to the client in a newBlock event.
+type chainHeadReportedBlock struct {
+	hash       common.Hash
+	parentHash common.Hash
+	number     uint
+	version    runtime.Version
+}
+
+// ChainHeadFollowListener follows the chain for a chainHead_unstable_follow
+// subscription, and keeps the blocks it reports pinned until the client
+// unpins them.
+type ChainHeadFollowListener struct {
+	wsconn        *WSConn
+	subID         uint32
+	withRuntime   bool
+	importedChan  chan *types.Block
+	finalizedChan chan *types.FinalisationInfo
+	done          chan struct{}
+	cancel        chan struct{}
+	cancelTimeout time.Duration
+
+	pinnedMutex sync.Mutex
+	pinned      map[common.Hash]*chainHeadBlock
+	stopped     bool
+
+	// fields below are only accessed by the listening goroutine
+	finalised   *chainHeadReportedBlock
+	unfinalised map[common.Hash]*chainHeadReportedBlock
+	bestHash    common.Hash
+}
+
+func newChainHeadFollowListener(conn *WSConn, withRuntime bool) *ChainHeadFollowListener {
+	return &ChainHeadFollowListener{
+		wsconn:        conn,
+		withRuntime:   withRuntime,
+		cancel:        make(chan struct{}, 1),
+		done:          make(chan struct{}, 1),
+		cancelTimeout: defaultCancelTimeout,
+		pinned:        make(map[common.Hash]*chainHeadBlock),
+		unfinalised:   make(map[common.Hash]*chainHeadReportedBlock),
+	}
+}
+
+func (c *WSConn) initChainHeadFollowListener(reqID float64, params interface{}) (Listener, error) {
+	if c.BlockAPI == nil || c.StorageAPI == nil {
+		c.safeSendError(reqID, nil, "error BlockAPI or StorageAPI not set")
+		return nil, fmt.Errorf("error BlockAPI or StorageAPI not set")
+	}
+
+	withRuntime, err := parseChainHeadFollowParams(params)
+	if err != nil {
+		c.safeSendError(reqID, big.NewInt(InvalidRequestCode), InvalidRequestMessage)
+		return nil, err
+	}
+
+	listener := newChainHeadFollowListener(c, withRuntime)
+	listener.importedChan = c.BlockAPI.GetImportedBlockNotifierChannel()
+	listener.finalizedChan = c.BlockAPI.GetFinalisedNotifierChannel()
+
+	c.mu.Lock()
+	listener.subID = atomic.AddUint32(&c.qtyListeners, 1)
+	c.Subscriptions[listener.subID] = listener
+	c.mu.Unlock()
+
+	c.safeSend(NewSubscriptionResponseJSON(listener.subID, reqID))
+	return listener, nil
+}
+
+// Listen reports the current finalised block and its descendants, and then
+// starts a goroutine reporting imported and finalised blocks.
+func (l *ChainHeadFollowListener) Listen() {
+	go func() {
+		defer func() {
+			l.wsconn.BlockAPI.FreeImportedBlockNotifierChannel(l.importedChan)
+			l.wsconn.BlockAPI.FreeFinalisedNotifierChannel(l.finalizedChan)
+			close(l.done)
+		}()
+
+		err := l.initialise()
+		if err != nil {
+			logger.Warnf("failed to initialise chainHead follow subscription %d: %s", l.subID, err)
+			l.stop()
+			return
+		}
+
+		for {
+			select {
+			case <-l.cancel:
+				return
+			case block, ok := <-l.importedChan:
+				if !ok {
+					l.stop()
+					return
+				}
+
+				if block == nil {
+					continue
+				}
+
+				err = l.handleImportedBlock(block)
+			case info, ok := <-l.finalizedChan:
+				if !ok {
+					l.stop()
+					return
+				}
+
+				if info == nil {
+					continue
+				}
+
+				l.handleFinalisedBlock(info.Header.Hash())
+			}
+
+			if err != nil {
+				logger.Warnf("stopping chainHead follow subscription %d: %s", l.subID, err)
+				l.stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop cancels the listening goroutine and unpins all the blocks.
+func (l *ChainHeadFollowListener) Stop() error {
+	l.pinnedMutex.Lock()
+	l.stopped = true
+	l.unpinAll()
+	l.pinnedMutex.Unlock()
+
+	return cancelWithTimeout(l.cancel, l.done, l.cancelTimeout)
+}
+
+// stop notifies the client the subscription is stopped and unpins all the blocks.
+func (l *ChainHeadFollowListener) stop() {
+	l.pinnedMutex.Lock()
+	l.stopped = true
+	l.unpinAll()
+	l.pinnedMutex.Unlock()
+
+	l.sendEvent(chainHeadStopEvent{Event: "stop"})
+}
+
+func (l *ChainHeadFollowListener) sendEvent(event interface{}) {
+	l.wsconn.safeSend(newSubscriptionResponse(chainHeadFollowEventMethod, l.subID, event))
+}
+
+func (l *ChainHeadFollowListener) initialise() error {
+	finalisedHash, err := l.wsconn.BlockAPI.GetHighestFinalisedHash()
+	if err != nil {
+		return fmt.Errorf("getting highest finalised hash: %w", err)
+	}
+
+	block, err := l.wsconn.BlockAPI.GetBlockByHash(finalisedHash)
+	if err != nil {
+		return fmt.Errorf("getting finalised block: %w", err)
+	}
+
+	pinnedBlock, err := l.pin(block, block.Header.Number)
+	if err != nil {
+		return fmt.Errorf("pinning finalised block: %w", err)
+	}
+
+	l.finalised = &chainHeadReportedBlock{
+		hash:       finalisedHash,
+		parentHash: block.Header.ParentHash,
+		number:     block.Header.Number,
+	}
+	l.bestHash = finalisedHash
+
+	event := chainHeadInitializedEvent{
+		Event:              "initialized",
+		FinalizedBlockHash: finalisedHash,
+	}
+	if l.withRuntime {
+		l.finalised.version = pinnedBlock.runtime.Version()
+		event.FinalizedBlockRuntime = newChainHeadRuntime(l.finalised.version)
+	}
+	l.sendEvent(event)
+
+	// blocks are ordered with parents before their children
+	for _, hash := range l.wsconn.BlockAPI.GetNonFinalisedBlocks() {
+		if hash == finalisedHash {
+			continue
+		}
+
+		block, err := l.wsconn.BlockAPI.GetBlockByHash(hash)
+		if err != nil {
+			return fmt.Errorf("getting non finalised block: %w", err)
+		}
+
+		err = l.handleImportedBlock(block)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// handleImportedBlock reports and pins the imported block, and reports
+// the best block if it changed.
+func (l *ChainHeadFollowListener) handleImportedBlock(block *types.Block) error {
+	hash := block.Header.Hash()
+	if l.isReported(hash) {
+		return nil
+	}
+
+	parentHash := block.Header.ParentHash
+	parent, ok := l.unfinalised[parentHash]
+	if !ok {
+		if parentHash != l.finalised.hash {
+			logger.Debugf("not reporting block %s with unreported parent %s", hash, parentHash)
+			return nil
+		}
+		parent = l.finalised
+	}
+
+	pinnedBlock, err := l.pin(block, l.finalised.number)
+	if err != nil {
+		return fmt.Errorf("pinning block %s: %w", hash, err)
+	}
+
+	reported := &chainHeadReportedBlock{
+		hash:       hash,
+		parentHash: parentHash,
+		number:     block.Header.Number,
+	}
+	l.unfinalised[hash] = reported
+
+	event := chainHeadNewBlockEvent{
+		Event:           "newBlock",
+		BlockHash:       hash,
+		ParentBlockHash: parentHash,
+	}
+	if l.withRuntime {
+		reported.version = pinnedBlock.runtime.Version()
+		if !reflect.DeepEqual(reported.version, parent.version) {
+			event.NewRuntime = newChainHeadRuntime(reported.version)
+		}
+	}
+	l.sendEvent(event)
+
+	l.updateBestBlock()
+	return nil
+}
+
+// handleFinalisedBlock reports the blocks finalised and pruned
+// by the finalisation of the block with the given hash.
+func (l *ChainHeadFollowListener) handleFinalisedBlock(hash common.Hash) {
+	finalised, pruned := l.finalise(hash)
+	if len(finalised) == 0 {
+		return
+	}
+
+	// the best block must be reported before the finalized
+	// event if the previous best block got pruned.
+	l.updateBestBlock()
+
+	l.sendEvent(chainHeadFinalizedEvent{
+		Event:                "finalized",
+		FinalizedBlockHashes: finalised,
+		PrunedBlockHashes:    pruned,
+	})
+}
+
+// finalise updates the reported blocks for the finalisation of the block
+// with the given hash, and returns the hashes of the newly finalised blocks
+// in ascending order together with the hashes of the pruned blocks.
+func (l *ChainHeadFollowListener) finalise(hash common.Hash) (finalised, pruned []common.Hash) {
+	newFinalised, ok := l.unfinalised[hash]
+	if !ok {
+		if hash != l.finalised.hash {
+			logger.Debugf("not reporting finalisation of unreported block %s", hash)
+		}
+		return nil, nil
+	}
+
+	for block := newFinalised; block != nil; block = l.unfinalised[block.parentHash] {
+		finalised = append(finalised, block.hash)
+	}
+
+	for i, j := 0, len(finalised)-1; i < j; i, j = i+1, j-1 {
+		finalised[i], finalised[j] = finalised[j], finalised[i]
+	}
+
+	for _, finalisedHash := range finalised {
+		delete(l.unfinalised, finalisedHash)
+	}
+	l.finalised = newFinalised
+
+	prunedBlocks := make([]*chainHeadReportedBlock, 0)
+	for _, block := range l.unfinalised {
+		if !l.descendsFromFinalised(block) {
+			prunedBlocks = append(prunedBlocks, block)
+		}
+	}
+
+	sort.Slice(prunedBlocks, func(i, j int) bool {
+		if prunedBlocks[i].number != prunedBlocks[j].number {
+			return prunedBlocks[i].number < prunedBlocks[j].number
+		}
+		return bytes.Compare(prunedBlocks[i].hash[:], prunedBlocks[j].hash[:]) < 0
+	})
+
+	pruned = make([]common.Hash, len(prunedBlocks))
+	for i, block := range prunedBlocks {
+		pruned[i] = block.hash
+	}
+
+	for _, prunedHash := range pruned {
+		delete(l.unfinalised, prunedHash)
+	}
+
+	return finalised, pruned
+}
+
+func (l *ChainHeadFollowListener) descendsFromFinalised(block *chainHeadReportedBlock) bool {
+	for {
+		if block.parentHash == l.finalised.hash {
+			return true
+		}
+
+		parent, ok := l.unfinalised[block.parentHash]
+		if !ok {
+			return false
+		}
+		block = parent
+	}
+}
+
+func (l *ChainHeadFollowListener) isReported(hash common.Hash) bool {
+	if hash == l.finalised.hash {
+		return true
+	}
+	_, ok := l.unfinalised[hash]
+	return ok
+}
+
+// updateBestBlock reports the best block of the block state if it changed
+// and was reported, and falls back on the finalised block if the previously
+// reported best block got pruned.
+func (l *ChainHeadFollowListener) updateBestBlock() {
+	bestHash := l.wsconn.BlockAPI.BestBlockHash()
+	if !l.isReported(bestHash) {
+		if l.isReported(l.bestHash) {
+			return
+		}
+		bestHash = l.finalised.hash
+	}
+
+	if bestHash == l.bestHash {
+		return
+	}
+
+	l.bestHash = bestHash
+	l.sendEvent(chainHeadBestBlockChangedEvent{
+		Event:         "bestBlockChanged",
+		BestBlockHash: bestHash,
+	})
+}
+
+// pin pins the given block, and prevents the state trie nodes of the block from
+// being pruned from the database by pinning the given finalised block number,
+// which must be the number of a finalised ancestor of the block.
+func (l *ChainHeadFollowListener) pin(block *types.Block, finalisedNumber uint) (
+	pinnedBlock *chainHeadBlock, err error) {
+	hash := block.Header.Hash()
+
+	unpinState := l.wsconn.StorageAPI.PinBlockNumber(finalisedNumber)
+	defer func() {
+		if err != nil {
+			unpinState()
+		}
+	}()
+
+	trieState, err := l.wsconn.StorageAPI.TrieState(&block.Header.StateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("getting trie state: %w", err)
+	}
+
+	rt, err := l.wsconn.BlockAPI.GetRuntime(hash)
+	if err != nil {
+		return nil, fmt.Errorf("getting runtime: %w", err)
+	}
+
+	pinnedBlock = &chainHeadBlock{
+		header:          block.Header,
+		body:            block.Body,
+		trie:            trieState.Trie(),
+		runtime:         rt,
+		finalisedNumber: finalisedNumber,
+		unpinState:      unpinState,
+	}
+
+	l.pinnedMutex.Lock()
+	defer l.pinnedMutex.Unlock()
+
+	if l.stopped {
+		return nil, errFollowListenerStopped
+	}
+
+	if len(l.pinned) >= maxPinnedBlocks {
+		return nil, fmt.Errorf("%w: maximum is %d", errTooManyPinnedBlocks, maxPinnedBlocks)
+	}
+
+	l.pinned[hash] = pinnedBlock
+	return pinnedBlock, nil
+}
+
+func (l *ChainHeadFollowListener) getPinned(hash common.Hash) (pinnedBlock *chainHeadBlock, err error) {
+	l.pinnedMutex.Lock()
+	defer l.pinnedMutex.Unlock()
+
+	if l.stopped {
+		return nil, errFollowListenerStopped
+	}
+
+	pinnedBlock, ok := l.pinned[hash]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errBlockNotPinned, hash)
+	}
+	return pinnedBlock, nil
+}
+
+// acquirePinned returns the pinned block with the given hash, and keeps its state
+// trie nodes in the database until the release function returned is called, even
+// if the block gets unpinned in the meantime.
+func (l *ChainHeadFollowListener) acquirePinned(hash common.Hash) (
+	pinnedBlock *chainHeadBlock, release func(), err error) {
+	l.pinnedMutex.Lock()
+	defer l.pinnedMutex.Unlock()
+
+	if l.stopped {
+		return nil, nil, errFollowListenerStopped
+	}
+
+	pinnedBlock, ok := l.pinned[hash]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", errBlockNotPinned, hash)
+	}
+
+	release = l.wsconn.StorageAPI.PinBlockNumber(pinnedBlock.finalisedNumber)
+	return pinnedBlock, release, nil
+}
+
+func (l *ChainHeadFollowListener) unpin(hash common.Hash) error {
+	l.pinnedMutex.Lock()
+	defer l.pinnedMutex.Unlock()
+
+	if l.stopped {
+		return errFollowListenerStopped
+	}
+
+	pinnedBlock, ok := l.pinned[hash]
+	if !ok {
+		return fmt.Errorf("%w: %s", errBlockNotPinned, hash)
+	}
+
+	pinnedBlock.unpinState()
+	delete(l.pinned, hash)
+	return nil
+}
+
+// unpinAll unpins all the blocks, and must be called with the pinned mutex locked.
+func (l *ChainHeadFollowListener) unpinAll() {
+	for _, pinnedBlock := range l.pinned {
+		pinnedBlock.unpinState()
+	}
+	l.pinned = make(map[common.Hash]*chainHeadBlock)
+}
+
+func (c *WSConn) getChainHeadFollowListener(params interface{}) (*ChainHeadFollowListener, error) {
+	subscribeID, err := parseSubscribeID(params)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	listener, ok := c.Subscriptions[subscribeID].(*ChainHeadFollowListener)
+	c.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("follow subscription id %d: %w", subscribeID, errCannotFindListener)
+	}
+
+	return listener, nil
+}
+
+// getChainHeadPinned returns the pinned block targeted by the request parameters,
+// which start with the follow subscription id followed by the block hash.
+func (c *WSConn) getChainHeadPinned(params interface{}) (pinnedBlock *chainHeadBlock, err error) {
+	listener, err := c.getChainHeadFollowListener(params)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := parseHashParam(params, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return listener.getPinned(hash)
+}
+
+func (c *WSConn) handleChainHeadUnfollow(reqID float64, params interface{}) {
+	listener, err := c.getChainHeadFollowListener(params)
+	if err != nil {
+		logger.Debugf("failed to get follow subscription to unfollow: %s", err)
+		c.safeSendError(reqID, big.NewInt(InvalidRequestCode), InvalidRequestMessage)
+		return
+	}
+
+	c.mu.Lock()
+	delete(c.Subscriptions, listener.subID)
+	c.mu.Unlock()
+
+	err = listener.Stop()
+	if err != nil {
+		logger.Warnf("failed to stop follow subscription %d: %s", listener.subID, err)
+	}
+
+	c.safeSend(newResultResponseJSON(nil, reqID))
+}
+
+func (c *WSConn) handleChainHeadHeader(reqID float64, params interface{}) {
+	pinnedBlock, err := c.getChainHeadPinned(params)
+	if err != nil {
+		logger.Debugf("failed to get pinned block header: %s", err)
+		c.safeSend(newResultResponseJSON(nil, reqID))
+		return
+	}
+
+	encodedHeader, err := scale.Marshal(pinnedBlock.header)
+	if err != nil {
+		c.safeSendError(reqID, nil, fmt.Sprintf("encoding header: %s", err))
+		return
+	}
+
+	c.safeSend(newResultResponseJSON(common.BytesToHex(encodedHeader), reqID))
+}
+
+func (c *WSConn) handleChainHeadUnpin(reqID float64, params interface{}) {
+	err := c.unpinChainHeadBlock(params)
+	if err != nil {
+		logger.Debugf("failed to unpin block: %s", err)
+		c.safeSendError(reqID, big.NewInt(InvalidRequestCode), InvalidRequestMessage)
+		return
+	}
+
+	c.safeSend(newResultResponseJSON(nil, reqID))
+}
+
+func (c *WSConn) unpinChainHeadBlock(params interface{}) error {
+	listener, err := c.getChainHeadFollowListener(params)
+	if err != nil {
+		return err
+	}
+
+	hash, err := parseHashParam(params, 1)
+	if err != nil {
+		return err
+	}
+
+	return listener.unpin(hash)
+}
+
+func (c *WSConn) handleChainHeadBody(reqID float64, params interface{}) {
+	c.handleChainHeadOperation(reqID, params, chainHeadBodyEventMethod,
+		func(pinnedBlock *chainHeadBlock) (result interface{}, err error) {
+			extrinsics := make([]string, len(pinnedBlock.body))
+			for i, extrinsic := range pinnedBlock.body {
+				extrinsics[i] = common.BytesToHex(extrinsic)
+			}
+			return extrinsics, nil
+		})
+}
+
+func (c *WSConn) handleChainHeadStorage(reqID float64, params interface{}) {
+	c.handleChainHeadOperation(reqID, params, chainHeadStorageEventMethod,
+		func(pinnedBlock *chainHeadBlock) (result interface{}, err error) {
+			// the lazy trie panics if a trie node cannot be loaded from the database
+			defer trie.CatchLoadNodePanic(&err)
+
+			key, err := parseHexParam(params, 2)
+			if err != nil {
+				return nil, err
+			}
+
+			trieState := rtstorage.NewTrieState(pinnedBlock.trie.Snapshot())
+
+			var value []byte
+			if hasParam(params, 3) {
+				childKey, err := parseHexParam(params, 3)
+				if err != nil {
+					return nil, err
+				}
+
+				value, err = trieState.GetChildStorage(childKey, key)
+				if err != nil {
+					return nil, fmt.Errorf("getting child storage: %w", err)
+				}
+			} else {
+				value = trieState.Get(key)
+			}
+
+			if value == nil {
+				return nil, nil
+			}
+			return common.BytesToHex(value), nil
+		})
+}
+
+func (c *WSConn) handleChainHeadCall(reqID float64, params interface{}) {
+	c.handleChainHeadOperation(reqID, params, chainHeadCallEventMethod,
+		func(pinnedBlock *chainHeadBlock) (result interface{}, err error) {
+			function, err := parseStringParam(params, 2)
+			if err != nil {
+				return nil, err
+			}
+
+			callParameters, err := parseHexParam(params, 3)
+			if err != nil {
+				return nil, err
+			}
+
+			instance, release, err := runtime.AcquireInstance(pinnedBlock.runtime)
+			if err != nil {
+				return nil, fmt.Errorf("getting runtime instance: %w", err)
+			}
+			defer release()
+
+			instance.SetContextStorage(rtstorage.NewTrieState(pinnedBlock.trie.Snapshot()))
+			output, err := instance.Exec(function, callParameters)
+			if err != nil {
+				return nil, fmt.Errorf("executing runtime function %s: %w", function, err)
+			}
+
+			return common.BytesToHex(output), nil
+		})
+}
+
+// handleChainHeadOperation responds with a new operation subscription id, and
+// then runs the operation on the pinned block targeted by the request parameters
+// in a goroutine, so the connection keeps on reading requests meanwhile.
+// The outcome of the operation is sent as an event of the operation subscription.
+func (c *WSConn) handleChainHeadOperation(reqID float64, params interface{}, method string,
+	operation func(pinnedBlock *chainHeadBlock) (result interface{}, err error)) {
+	listener, err := c.getChainHeadFollowListener(params)
+	if err != nil {
+		logger.Debugf("failed to get follow subscription: %s", err)
+		c.safeSendError(reqID, big.NewInt(InvalidRequestCode), InvalidRequestMessage)
+		return
+	}
+
+	subID := atomic.AddUint32(&c.qtyListeners, 1)
+	c.safeSend(NewSubscriptionResponseJSON(subID, reqID))
+
+	go c.runChainHeadOperation(listener, params, method, subID, operation)
+}
+
+// runChainHeadOperation runs the operation on the pinned block targeted by the
+// request parameters, and sends its outcome as an event of the operation subscription.
+func (c *WSConn) runChainHeadOperation(listener *ChainHeadFollowListener, params interface{},
+	method string, subID uint32, operation func(pinnedBlock *chainHeadBlock) (result interface{}, err error)) {
+	hash, err := parseHashParam(params, 1)
+	if err != nil {
+		c.safeSend(newSubscriptionResponse(method, subID,
+			chainHeadOperationEvent{Event: "error", Error: err.Error()}))
+		return
+	}
+
+	pinnedBlock, release, err := listener.acquirePinned(hash)
+	if err != nil {
+		c.safeSend(newSubscriptionResponse(method, subID, chainHeadOperationEvent{Event: "disjoint"}))
+		return
+	}
+	defer release()
+
+	result, err := operation(pinnedBlock)
+	if err != nil {
+		c.safeSend(newSubscriptionResponse(method, subID,
+			chainHeadOperationEvent{Event: "error", Error: err.Error()}))
+		return
+	}
+
+	c.safeSend(newSubscriptionResponse(method, subID, chainHeadOperationEvent{Event: "done", Result: result}))
+}
+
+func parseChainHeadFollowParams(params interface{}) (withRuntime bool, err error) {
+	values, ok := params.([]interface{})
+	if !ok {
+		return false, fmt.Errorf("%w: %T, expected type []interface{}", errUnexpectedType, params)
+	}
+
+	if len(values) != 1 {
+		return false, fmt.Errorf("%w: expected 1 param, got: %d", errUnexpectedParamLen, len(values))
+	}
+
+	withRuntime, ok = values[0].(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: %T, expected type bool", errUnexpectedType, values[0])
+	}
+
+	return withRuntime, nil
+}
+
+func hasParam(params interface{}, index int) bool {
+	values, ok := params.([]interface{})
+	return ok && index < len(values) && values[index] != nil
+}
+
+func parseStringParam(params interface{}, index int) (value string, err error) {
+	values, ok := params.([]interface{})
+	if !ok {
+		return "", fmt.Errorf("%w: %T, expected type []interface{}", errUnexpectedType, params)
+	}
+
+	if index >= len(values) {
+		return "", fmt.Errorf("%w: expected at least %d params, got: %d",
+			errUnexpectedParamLen, index+1, len(values))
+	}
+
+	value, ok = values[index].(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %T, expected type string", errUnexpectedType, values[index])
+	}
+
+	return value, nil
+}
+
+func parseHexParam(params interface{}, index int) (value []byte, err error) {
+	hexValue, err := parseStringParam(params, index)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.HexToBytes(hexValue)
+}
+
+func parseHashParam(params interface{}, index int) (hash common.Hash, err error) {
+	hashBytes, err := parseHexParam(params, index)
+	if err != nil {
+		return hash, err
+	}
+
+	if len(hashBytes) != common.HashLength {
+		return hash, fmt.Errorf("%w: expected %d bytes, got: %d",
+			errInvalidHashLength, common.HashLength, len(hashBytes))
+	}
+
+	return common.NewHash(hashBytes), nil
+}
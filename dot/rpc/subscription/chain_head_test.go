@@ -0,0 +1,244 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package subscription
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/rpc/modules/mocks"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ChainHeadFollowListener_finalise(t *testing.T) {
+	t.Parallel()
+
+	// finalised block 0 with children 1a and 1b, with 1a having children 2a
+	// and 2b, and 1b having child 2c.
+	block0 := &chainHeadReportedBlock{hash: common.Hash{0}}
+	block1a := &chainHeadReportedBlock{hash: common.Hash{0x1a}, parentHash: block0.hash, number: 1}
+	block1b := &chainHeadReportedBlock{hash: common.Hash{0x1b}, parentHash: block0.hash, number: 1}
+	block2a := &chainHeadReportedBlock{hash: common.Hash{0x2a}, parentHash: block1a.hash, number: 2}
+	block2b := &chainHeadReportedBlock{hash: common.Hash{0x2b}, parentHash: block1a.hash, number: 2}
+	block2c := &chainHeadReportedBlock{hash: common.Hash{0x2c}, parentHash: block1b.hash, number: 2}
+
+	testCases := map[string]struct {
+		hash                common.Hash
+		finalised           []common.Hash
+		pruned              []common.Hash
+		expectedFinalised   *chainHeadReportedBlock
+		expectedUnfinalised []common.Hash
+	}{
+		"unreported_block": {
+			hash:                common.Hash{0xff},
+			expectedFinalised:   block0,
+			expectedUnfinalised: []common.Hash{block1a.hash, block1b.hash, block2a.hash, block2b.hash, block2c.hash},
+		},
+		"already_finalised_block": {
+			hash:                block0.hash,
+			expectedFinalised:   block0,
+			expectedUnfinalised: []common.Hash{block1a.hash, block1b.hash, block2a.hash, block2b.hash, block2c.hash},
+		},
+		"finalise_child": {
+			hash:                block1a.hash,
+			finalised:           []common.Hash{block1a.hash},
+			pruned:              []common.Hash{block1b.hash, block2c.hash},
+			expectedFinalised:   block1a,
+			expectedUnfinalised: []common.Hash{block2a.hash, block2b.hash},
+		},
+		"finalise_grandchild": {
+			hash:              block2c.hash,
+			finalised:         []common.Hash{block1b.hash, block2c.hash},
+			pruned:            []common.Hash{block1a.hash, block2a.hash, block2b.hash},
+			expectedFinalised: block2c,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			listener := &ChainHeadFollowListener{
+				finalised: block0,
+				unfinalised: map[common.Hash]*chainHeadReportedBlock{
+					block1a.hash: block1a,
+					block1b.hash: block1b,
+					block2a.hash: block2a,
+					block2b.hash: block2b,
+					block2c.hash: block2c,
+				},
+			}
+
+			finalised, pruned := listener.finalise(testCase.hash)
+
+			assert.Equal(t, testCase.finalised, finalised)
+			assert.Equal(t, testCase.pruned, pruned)
+			assert.Equal(t, testCase.expectedFinalised, listener.finalised)
+
+			unfinalised := make([]common.Hash, 0, len(listener.unfinalised))
+			for hash := range listener.unfinalised {
+				unfinalised = append(unfinalised, hash)
+			}
+			assert.ElementsMatch(t, testCase.expectedUnfinalised, unfinalised)
+		})
+	}
+}
+
+func Test_ChainHeadFollowListener_pinning(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	storageAPI := mocks.NewMockStorageAPI(ctrl)
+	blockAPI := mocks.NewMockBlockAPI(ctrl)
+	listener := newChainHeadFollowListener(&WSConn{
+		StorageAPI: storageAPI,
+		BlockAPI:   blockAPI,
+	}, false)
+
+	statePins := 0
+	pinState := func(uint) func() {
+		statePins++
+		return func() { statePins-- }
+	}
+
+	block := &types.Block{Header: types.Header{Number: 5}}
+	hash := block.Header.Hash()
+
+	// the state pin is released if pinning fails
+	errTest := errors.New("test error")
+	storageAPI.EXPECT().PinBlockNumber(uint(3)).DoAndReturn(pinState)
+	storageAPI.EXPECT().TrieState(&block.Header.StateRoot).Return(nil, errTest)
+	_, err := listener.pin(block, 3)
+	require.ErrorIs(t, err, errTest)
+	assert.Equal(t, 0, statePins)
+
+	storageAPI.EXPECT().PinBlockNumber(uint(3)).DoAndReturn(pinState)
+	storageAPI.EXPECT().TrieState(&block.Header.StateRoot).
+		Return(rtstorage.NewTrieState(trie.NewEmptyTrie()), nil)
+	blockAPI.EXPECT().GetRuntime(hash).Return(nil, nil)
+	_, err = listener.pin(block, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 1, statePins)
+
+	// an operation keeps the state pinned after the block is unpinned
+	storageAPI.EXPECT().PinBlockNumber(uint(3)).DoAndReturn(pinState)
+	pinnedBlock, release, err := listener.acquirePinned(hash)
+	require.NoError(t, err)
+	assert.Equal(t, block.Header, pinnedBlock.header)
+	assert.Equal(t, 2, statePins)
+
+	err = listener.unpin(hash)
+	require.NoError(t, err)
+	assert.Equal(t, 1, statePins)
+
+	err = listener.unpin(hash)
+	assert.ErrorIs(t, err, errBlockNotPinned)
+
+	release()
+	assert.Equal(t, 0, statePins)
+
+	_, _, err = listener.acquirePinned(hash)
+	assert.ErrorIs(t, err, errBlockNotPinned)
+}
+
+func Test_parseChainHeadFollowParams(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		params      interface{}
+		withRuntime bool
+		errMessage  string
+	}{
+		"params_not_a_slice": {
+			params:     "true",
+			errMessage: "unexpected type: string, expected type []interface{}",
+		},
+		"no_param": {
+			params:     []interface{}{},
+			errMessage: "unexpected params length: expected 1 param, got: 0",
+		},
+		"param_not_a_bool": {
+			params:     []interface{}{"true"},
+			errMessage: "unexpected type: string, expected type bool",
+		},
+		"with_runtime": {
+			params:      []interface{}{true},
+			withRuntime: true,
+		},
+		"without_runtime": {
+			params: []interface{}{false},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			withRuntime, err := parseChainHeadFollowParams(testCase.params)
+
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.withRuntime, withRuntime)
+		})
+	}
+}
+
+func Test_parseHashParam(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		params     interface{}
+		index      int
+		hash       common.Hash
+		errMessage string
+	}{
+		"missing_param": {
+			params:     []interface{}{"0x01"},
+			index:      1,
+			errMessage: "unexpected params length: expected at least 2 params, got: 1",
+		},
+		"param_not_a_string": {
+			params:     []interface{}{1, 2},
+			index:      1,
+			errMessage: "unexpected type: int, expected type string",
+		},
+		"short_hash": {
+			params:     []interface{}{"follow", "0x0102"},
+			index:      1,
+			errMessage: "invalid hash length: expected 32 bytes, got: 2",
+		},
+		"valid_hash": {
+			params: []interface{}{"follow", common.Hash{1, 2}.String()},
+			index:  1,
+			hash:   common.Hash{1, 2},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			hash, err := parseHashParam(testCase.params, testCase.index)
+
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.hash, hash)
+		})
+	}
+}
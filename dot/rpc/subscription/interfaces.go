@@ -8,6 +8,7 @@
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 	"github.com/ChainSafe/gossamer/lib/transaction"
 )
 
@@ -15,10 +16,17 @@
 type StorageAPI interface {
 	RegisterStorageObserver(observer state.Observer)
 	UnregisterStorageObserver(observer state.Observer)
+	TrieState(root *common.Hash) (*rtstorage.TrieState, error)
+	PinBlockNumber(blockNumber uint) (unpin func())
 }
 
 // BlockAPI is the interface for the block state
 type BlockAPI interface {
+	BestBlockHash() common.Hash
+	GetBlockByHash(hash common.Hash) (*types.Block, error)
+	GetHighestFinalisedHash() (common.Hash, error)
+	GetNonFinalisedBlocks() []common.Hash
+	GetRuntime(blockHash common.Hash) (instance state.Runtime, err error)
 	GetJustification(hash common.Hash) ([]byte, error)
 	GetImportedBlockNotifierChannel() chan *types.Block
 	FreeImportedBlockNotifierChannel(ch chan *types.Block)
@@ -38,3 +46,8 @@ type CoreAPI interface {
 	GetRuntimeVersion(bhash *common.Hash) (runtime.Version, error)
 	HandleSubmittedExtrinsic(types.Extrinsic) error
 }
+
+// RateLimiter limits the rate of requests received on a connection.
+type RateLimiter interface {
+	Allow() bool
+}
@@ -22,6 +22,19 @@ type Params struct {
 // InvalidRequestMessage error message for invalid request parameters
 const InvalidRequestMessage = "Invalid request"
 
+// LimitExceededCode error code returned for requests exceeding a limit of the server
+const LimitExceededCode = -32005
+
+// RateLimitExceededMessage error message for requests exceeding the rate limit
+const RateLimitExceededMessage = "Rate limit exceeded"
+
+// TooManySubscriptionsMessage error message for subscriptions exceeding
+// the maximum number of subscriptions of the connection
+const TooManySubscriptionsMessage = "Too many subscriptions"
+
+// ResponseTooLargeMessage error message for responses exceeding the maximum response size
+const ResponseTooLargeMessage = "Response is too large"
+
 func newSubcriptionBaseResponseJSON() BaseResponseJSON {
 	return BaseResponseJSON{
 		Jsonrpc: "2.0",
@@ -69,3 +82,18 @@ func newBooleanResponseJSON(value bool, reqID float64) BooleanResponse {
 		ID:      reqID,
 	}
 }
+
+// ResultResponse for responses that return arbitrary values
+type ResultResponse struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Result  interface{} `json:"result"`
+	ID      float64     `json:"id"`
+}
+
+func newResultResponseJSON(value interface{}, reqID float64) ResultResponse {
+	return ResultResponse{
+		JSONRPC: "2.0",
+		Result:  value,
+		ID:      reqID,
+	}
+}
@@ -19,6 +19,13 @@
 	stateSubscribeStorage          string = "state_subscribeStorage"
 	stateSubscribeRuntimeVersion   string = "state_subscribeRuntimeVersion"
 	grandpaSubscribeJustifications string = "grandpa_subscribeJustifications"
+	chainHeadUnstableFollow        string = "chainHead_unstable_follow"
+	chainHeadUnstableUnfollow      string = "chainHead_unstable_unfollow"
+	chainHeadUnstableHeader        string = "chainHead_unstable_header"
+	chainHeadUnstableBody          string = "chainHead_unstable_body"
+	chainHeadUnstableStorage       string = "chainHead_unstable_storage"
+	chainHeadUnstableCall          string = "chainHead_unstable_call"
+	chainHeadUnstableUnpin         string = "chainHead_unstable_unpin"
 )
 
 type setupListener func(reqid float64, params interface{}) (Listener, error)
@@ -46,21 +53,29 @@ func (c *WSConn) getSetupListener(method string) setupListener {
 		return c.initRuntimeVersionListener
 	case grandpaSubscribeJustifications:
 		return c.initGrandpaJustificationListener
+	case chainHeadUnstableFollow:
+		return c.initChainHeadFollowListener
 	default:
 		return nil
 	}
 }
 
+// getUnsubListener returns the listener of the subscription to unsubscribe,
+// and removes it from the subscriptions of the connection.
 func (c *WSConn) getUnsubListener(params interface{}) (Listener, error) {
 	subscribeID, err := parseSubscribeID(params)
 	if err != nil {
 		return nil, err
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	listener, ok := c.Subscriptions[subscribeID]
 	if !ok {
 		return nil, fmt.Errorf("subscriber id %v: %w", subscribeID, errCannotFindListener)
 	}
+	delete(c.Subscriptions, subscribeID)
 
 	return listener, nil
 }
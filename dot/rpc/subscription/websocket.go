@@ -36,6 +36,7 @@ type httpclient interface {
 	errUnexpectedParamLen      = errors.New("unexpected params length")
 	errCannotReadFromWebsocket = errors.New("cannot read message from websocket")
 	errEmptyMethod             = errors.New("empty method")
+	errNotificationTooLarge    = errors.New("notification is too large")
 )
 
 var logger = log.NewFromGlobal(log.AddContext("pkg", "rpc/subscription"))
@@ -53,87 +54,151 @@ type WSConn struct {
 	TxStateAPI    TransactionStateAPI
 	RPCHost       string
 	HTTP          httpclient
+	// MaxBatchSize is the maximum number of requests in a batch,
+	// zero meaning batches are not limited.
+	MaxBatchSize uint32
+	// MaxSubscriptions is the maximum number of subscriptions,
+	// zero meaning subscriptions are not limited.
+	MaxSubscriptions uint32
+	// MaxResponseSize is the maximum size in bytes of a message sent,
+	// zero meaning messages are not limited.
+	MaxResponseSize uint64
+	// RateLimiter limits the rate of requests, and is nil if requests are not limited.
+	RateLimiter RateLimiter
+	// batch collects the messages sent while handling a batch.
+	batch *wsBatch
+	// pendingSubscriptions is the number of subscriptions being set up,
+	// counted together with the subscriptions against MaxSubscriptions.
+	pendingSubscriptions uint32
 }
 
-// readWebsocketMessage will read and parse the message data to a string->interface{} data
-func (c *WSConn) readWebsocketMessage() (rawBytes []byte, wsMessage *websocketMessage, err error) {
+// readWebsocketMessage will read the message data from the websocket connection
+func (c *WSConn) readWebsocketMessage() (rawBytes []byte, err error) {
 	_, rawBytes, err = c.Wsconn.ReadMessage()
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w: %s", errCannotReadFromWebsocket, err.Error())
+		return nil, fmt.Errorf("%w: %s", errCannotReadFromWebsocket, err.Error())
 	}
 
+	return rawBytes, nil
+}
+
+// parseWebsocketMessage will parse the message data to a websocketMessage
+func parseWebsocketMessage(rawBytes []byte) (wsMessage *websocketMessage, err error) {
 	wsMessage = new(websocketMessage)
 	err = json.Unmarshal(rawBytes, wsMessage)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if wsMessage.Method == "" {
-		return nil, nil, errEmptyMethod
+		return nil, errEmptyMethod
 	}
 
-	return rawBytes, wsMessage, nil
+	return wsMessage, nil
 }
 
 // HandleConn handles messages received on websocket connections
 func (c *WSConn) HandleConn() {
 	for {
-		rawBytes, wsMessage, err := c.readWebsocketMessage()
+		rawBytes, err := c.readWebsocketMessage()
 		if err != nil {
 			logger.Debugf("websocket failed to read message: %s", err)
-			if errors.Is(err, errCannotReadFromWebsocket) {
-				return
-			}
+			return
+		}
+
+		logger.Tracef("websocket message received: %s", string(rawBytes))
+
+		if isBatchMessage(rawBytes) {
+			// each request of the batch is rate limited on its own
+			c.handleBatch(rawBytes)
+			continue
+		}
 
+		wsMessage, err := parseWebsocketMessage(rawBytes)
+		if err != nil {
+			logger.Debugf("websocket failed to parse message: %s", err)
 			c.safeSendError(0, big.NewInt(InvalidRequestCode), InvalidRequestMessage)
 			continue
 		}
 
-		logger.Tracef("websocket message received: %s", string(rawBytes))
-		logger.Debugf("ws method %s called with params %v", wsMessage.Method, wsMessage.Params)
+		if !c.allowRequest() {
+			c.safeSendError(wsMessage.ID, big.NewInt(LimitExceededCode), RateLimitExceededMessage)
+			continue
+		}
 
-		if !strings.Contains(wsMessage.Method, "_unsubscribe") && !strings.Contains(wsMessage.Method, "_unwatch") {
-			setupListener := c.getSetupListener(wsMessage.Method)
+		listener := c.handleMessage(rawBytes, wsMessage)
+		if listener != nil {
+			listener.Listen()
+		}
+	}
+}
 
-			if setupListener == nil {
-				c.executeRPCCall(rawBytes)
-				continue
-			}
+func (c *WSConn) allowRequest() bool {
+	if c.RateLimiter == nil || c.RateLimiter.Allow() {
+		return true
+	}
 
-			listener, err := setupListener(wsMessage.ID, wsMessage.Params)
-			if err != nil {
-				logger.Warnf("failed to create listener (method=%s): %s", wsMessage.Method, err)
-				continue
-			}
+	logger.Debugf("websocket rate limit exceeded for %s", c.Wsconn.RemoteAddr())
+	return false
+}
 
-			listener.Listen()
-			continue
+// handleMessage handles a single request received on the websocket connection,
+// and returns the listener to start if the request creates a subscription.
+func (c *WSConn) handleMessage(rawBytes []byte, wsMessage *websocketMessage) (listener Listener) {
+	logger.Debugf("ws method %s called with params %v", wsMessage.Method, wsMessage.Params)
+
+	chainHeadHandler := c.getChainHeadHandler(wsMessage.Method)
+	if chainHeadHandler != nil {
+		chainHeadHandler(wsMessage.ID, wsMessage.Params)
+		return nil
+	}
+
+	if !strings.Contains(wsMessage.Method, "_unsubscribe") && !strings.Contains(wsMessage.Method, "_unwatch") {
+		setupListener := c.getSetupListener(wsMessage.Method)
+
+		if setupListener == nil {
+			c.executeRPCCall(rawBytes)
+			return nil
+		}
+
+		if !c.reserveSubscription() {
+			c.safeSendError(wsMessage.ID, big.NewInt(LimitExceededCode), TooManySubscriptionsMessage)
+			return nil
 		}
 
-		listener, err := c.getUnsubListener(wsMessage.Params)
+		listener, err := setupListener(wsMessage.ID, wsMessage.Params)
+		c.releaseSubscription()
 		if err != nil {
-			logger.Warnf("failed to get unsubscriber (method=%s): %s", wsMessage.Method, err)
+			logger.Warnf("failed to create listener (method=%s): %s", wsMessage.Method, err)
+			return nil
+		}
 
-			if errors.Is(err, errUknownParamSubscribeID) || errors.Is(err, errCannotFindUnsubsriber) {
-				c.safeSendError(wsMessage.ID, big.NewInt(InvalidRequestCode), InvalidRequestMessage)
-				continue
-			}
+		return listener
+	}
 
-			if errors.Is(err, errCannotParseID) || errors.Is(err, errCannotFindListener) {
-				c.safeSend(newBooleanResponseJSON(false, wsMessage.ID))
-				continue
-			}
+	listener, err := c.getUnsubListener(wsMessage.Params)
+	if err != nil {
+		logger.Warnf("failed to get unsubscriber (method=%s): %s", wsMessage.Method, err)
+
+		if errors.Is(err, errUknownParamSubscribeID) || errors.Is(err, errCannotFindUnsubsriber) {
+			c.safeSendError(wsMessage.ID, big.NewInt(InvalidRequestCode), InvalidRequestMessage)
+			return nil
 		}
 
-		err = listener.Stop()
-		if err != nil {
-			logger.Warnf("failed to stop listener goroutine (method=%s): %s", wsMessage.Method, err)
+		if errors.Is(err, errCannotParseID) || errors.Is(err, errCannotFindListener) {
 			c.safeSend(newBooleanResponseJSON(false, wsMessage.ID))
+			return nil
 		}
+	}
 
-		c.safeSend(newBooleanResponseJSON(true, wsMessage.ID))
-		continue
+	err = listener.Stop()
+	if err != nil {
+		logger.Warnf("failed to stop listener goroutine (method=%s): %s", wsMessage.Method, err)
+		c.safeSend(newBooleanResponseJSON(false, wsMessage.ID))
 	}
+
+	c.safeSend(newBooleanResponseJSON(true, wsMessage.ID))
+	return nil
 }
 
 func (c *WSConn) executeRPCCall(data []byte) {
@@ -406,32 +471,98 @@ func (c *WSConn) initGrandpaJustificationListener(reqID float64, _ interface{})
 	return jl, nil
 }
 
-func (c *WSConn) safeSend(msg interface{}) {
+// reserveSubscription returns false if the connection has the maximum number
+// of subscriptions, including the ones being set up. Otherwise it reserves a
+// subscription being set up, which must be released with releaseSubscription
+// once the subscription is set up and added to the subscriptions, or failed.
+func (c *WSConn) reserveSubscription() (ok bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	err := c.Wsconn.WriteJSON(msg)
-	if err != nil {
-		logger.Debugf("error sending websocket message: %s", err)
+
+	subscriptions := uint64(len(c.Subscriptions)) + uint64(c.pendingSubscriptions)
+	if c.MaxSubscriptions > 0 && subscriptions >= uint64(c.MaxSubscriptions) {
+		return false
 	}
+
+	c.pendingSubscriptions++
+	return true
 }
 
-func (c *WSConn) safeSendError(reqID float64, errorCode *big.Int, message string) {
-	res := &ErrorResponseJSON{
-		Jsonrpc: "2.0",
-		Error: &ErrorMessageJSON{
-			Code:    errorCode,
-			Message: message,
-		},
-		ID: reqID,
+// releaseSubscription releases a subscription reserved with reserveSubscription.
+func (c *WSConn) releaseSubscription() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pendingSubscriptions--
+}
+
+func (c *WSConn) safeSend(msg interface{}) {
+	encoded, err := c.encodeMessage(msg)
+	if err != nil {
+		logger.Debugf("error encoding websocket message: %s", err)
+		return
 	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	err := c.Wsconn.WriteJSON(res)
+
+	if c.batch != nil {
+		c.batch.add(encoded)
+		return
+	}
+
+	c.writeMessage(encoded)
+}
+
+// encodeMessage JSON encodes the message, replacing a response larger
+// than the maximum response size by an error response.
+func (c *WSConn) encodeMessage(msg interface{}) (encoded []byte, err error) {
+	encoded, err = json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.MaxResponseSize == 0 || uint64(len(encoded)) <= c.MaxResponseSize {
+		return encoded, nil
+	}
+
+	id := messageID(encoded)
+	if id == nil {
+		return nil, fmt.Errorf("%w: %d bytes exceed the maximum of %d bytes",
+			errNotificationTooLarge, len(encoded), c.MaxResponseSize)
+	}
+
+	logger.Debugf("websocket response of %d bytes exceeds the maximum of %d bytes",
+		len(encoded), c.MaxResponseSize)
+	return json.Marshal(newErrorResponseJSON(*id, big.NewInt(LimitExceededCode), ResponseTooLargeMessage))
+}
+
+// writeMessage writes the JSON encoded message on the connection.
+// It is NOT THREAD SAFE to use, and the connection lock must be held.
+func (c *WSConn) writeMessage(encoded []byte) {
+	// messages end with a new line, as written by the JSON encoder
+	err := c.Wsconn.WriteMessage(websocket.TextMessage, append(encoded, '\n'))
 	if err != nil {
 		logger.Debugf("error sending websocket message: %s", err)
 	}
 }
 
+// messageID returns the id of the JSON encoded message,
+// which is nil if the message is not a response.
+func messageID(encoded []byte) (id *float64) {
+	var response struct {
+		ID *float64 `json:"id"`
+	}
+	err := json.Unmarshal(encoded, &response)
+	if err != nil {
+		return nil
+	}
+	return response.ID
+}
+
+func (c *WSConn) safeSendError(reqID float64, errorCode *big.Int, message string) {
+	c.safeSend(newErrorResponseJSON(reqID, errorCode, message))
+}
+
 func (c *WSConn) prepareRequest(b []byte) (*http.Request, error) {
 	buff := &bytes.Buffer{}
 	if _, err := buff.Write(b); err != nil {
@@ -485,6 +616,17 @@ type ErrorResponseJSON struct {
 	ID      float64           `json:"id"`
 }
 
+func newErrorResponseJSON(reqID float64, errorCode *big.Int, message string) *ErrorResponseJSON {
+	return &ErrorResponseJSON{
+		Jsonrpc: "2.0",
+		Error: &ErrorMessageJSON{
+			Code:    errorCode,
+			Message: message,
+		},
+		ID: reqID,
+	}
+}
+
 // ErrorMessageJSON json for error messages
 type ErrorMessageJSON struct {
 	Code    *big.Int `json:"code"`
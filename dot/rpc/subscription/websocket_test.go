@@ -0,0 +1,152 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package subscription
+
+import (
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWSConn_encodeMessage(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		maxResponseSize uint64
+		message         interface{}
+		encoded         string
+		errWrapped      error
+		errMessage      string
+	}{
+		"no_limit": {
+			message: newBooleanResponseJSON(true, 1),
+			encoded: `{"jsonrpc":"2.0","result":true,"id":1}`,
+		},
+		"within_limit": {
+			maxResponseSize: 38,
+			message:         newBooleanResponseJSON(true, 1),
+			encoded:         `{"jsonrpc":"2.0","result":true,"id":1}`,
+		},
+		"response_too_large": {
+			maxResponseSize: 37,
+			message:         newBooleanResponseJSON(true, 1),
+			encoded: `{"jsonrpc":"2.0","error":{"code":-32005,` +
+				`"message":"Response is too large"},"id":1}`,
+		},
+		"notification_too_large": {
+			maxResponseSize: 10,
+			message:         newSubcriptionBaseResponseJSON(),
+			errWrapped:      errNotificationTooLarge,
+			errMessage:      "notification is too large: 71 bytes exceed the maximum of 10 bytes",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wsConn := &WSConn{MaxResponseSize: testCase.maxResponseSize}
+
+			encoded, err := wsConn.encodeMessage(testCase.message)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+				return
+			}
+			assert.Equal(t, testCase.encoded, string(encoded))
+		})
+	}
+}
+
+type rateLimiterStub struct {
+	allowed int
+}
+
+func (r *rateLimiterStub) Allow() bool {
+	if r.allowed == 0 {
+		return false
+	}
+	r.allowed--
+	return true
+}
+
+func TestWSConn_HandleConn_limits(t *testing.T) {
+	t.Parallel()
+
+	wsconn, ws, cancel := setupWSConn(t)
+	defer cancel()
+	wsconn.Subscriptions = map[uint32]Listener{
+		1: NewBlockListener(wsconn),
+	}
+	wsconn.MaxSubscriptions = 1
+	wsconn.RateLimiter = &rateLimiterStub{allowed: 2}
+
+	go wsconn.HandleConn()
+
+	testCases := []struct {
+		sentMessage string
+		expected    string
+	}{
+		{
+			sentMessage: `{"jsonrpc":"2.0","id":1,"method":"chain_subscribeNewHeads","params":[]}`,
+			expected: `{"jsonrpc":"2.0","error":{"code":-32005,` +
+				`"message":"Too many subscriptions"},"id":1}`,
+		},
+		{
+			sentMessage: `[{"jsonrpc":"2.0","id":2,"method":"chain_subscribeNewHeads","params":[]},` +
+				`{"jsonrpc":"2.0","id":3,"method":"chain_subscribeNewHeads","params":[]}]`,
+			expected: `[{"jsonrpc":"2.0","error":{"code":-32005,"message":"Too many subscriptions"},"id":2},` +
+				`{"jsonrpc":"2.0","error":{"code":-32005,"message":"Rate limit exceeded"},"id":3}]`,
+		},
+		{
+			sentMessage: `{"jsonrpc":"2.0","id":4,"method":"chain_subscribeNewHeads","params":[]}`,
+			expected: `{"jsonrpc":"2.0","error":{"code":-32005,` +
+				`"message":"Rate limit exceeded"},"id":4}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := ws.WriteMessage(websocket.TextMessage, []byte(testCase.sentMessage))
+		require.NoError(t, err)
+
+		_, message, err := ws.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected+"\n", string(message))
+	}
+}
+
+func Test_messageID(t *testing.T) {
+	t.Parallel()
+
+	id := messageID([]byte(`{"jsonrpc":"2.0","result":true,"id":1}`))
+	require.NotNil(t, id)
+	assert.Equal(t, float64(1), *id)
+	assert.Nil(t, messageID([]byte(`{"method":"method"}`)))
+	assert.Nil(t, messageID([]byte(`invalid`)))
+}
+
+func TestWSConn_reserveSubscription(t *testing.T) {
+	t.Parallel()
+
+	wsconn := &WSConn{
+		Subscriptions:    map[uint32]Listener{1: &BlockListener{}},
+		MaxSubscriptions: 3,
+	}
+
+	// subscriptions being set up count towards the maximum
+	assert.True(t, wsconn.reserveSubscription())
+	assert.True(t, wsconn.reserveSubscription())
+	assert.False(t, wsconn.reserveSubscription())
+
+	wsconn.releaseSubscription()
+	assert.True(t, wsconn.reserveSubscription())
+	assert.False(t, wsconn.reserveSubscription())
+
+	wsconn.MaxSubscriptions = 0
+	assert.True(t, wsconn.reserveSubscription())
+}
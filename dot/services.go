@@ -29,7 +29,6 @@
 	"github.com/ChainSafe/gossamer/lib/grandpa"
 	"github.com/ChainSafe/gossamer/lib/keystore"
 	"github.com/ChainSafe/gossamer/lib/runtime"
-	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
 	"github.com/ChainSafe/gossamer/lib/utils"
 )
 
@@ -62,9 +61,10 @@ func (nodeBuilder) createStateService(cfg *Config) (*state.Service, error) {
 	logger.Debug("creating state service...")
 
 	config := state.Config{
-		Path:     cfg.Global.BasePath,
-		LogLevel: cfg.Log.StateLvl,
-		Metrics:  metrics.NewIntervalConfig(cfg.Global.PublishMetrics),
+		Path:                     cfg.Global.BasePath,
+		LogLevel:                 cfg.Log.StateLvl,
+		Metrics:                  metrics.NewIntervalConfig(cfg.Global.PublishMetrics),
+		TransactionStoragePeriod: cfg.Global.TransactionStoragePeriod,
 	}
 
 	stateSrvc := state.NewService(config)
@@ -138,25 +138,18 @@ func createRuntime(cfg *Config, ns runtime.NodeStorage, st *state.Service,
 		return nil, err
 	}
 
-	switch cfg.Core.WasmInterpreter {
-	case wasmer.Name:
-		rtCfg := wasmer.Config{
-			Storage:     ts,
-			Keystore:    ks,
-			LogLvl:      cfg.Log.RuntimeLvl,
-			NodeStorage: ns,
-			Network:     net,
-			Role:        cfg.Core.Roles,
-			CodeHash:    codeHash,
-		}
-
-		// create runtime executor
-		rt, err = wasmer.NewInstance(code, rtCfg)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create runtime executor: %s", err)
-		}
-	default:
-		return nil, fmt.Errorf("%w: %s", ErrWasmInterpreterName, cfg.Core.WasmInterpreter)
+	rt, err = state.NewRuntime(cfg.Core.WasmInterpreter, code, state.RuntimeConfig{
+		Storage:     ts,
+		Keystore:    ks,
+		LogLvl:      cfg.Log.RuntimeLvl,
+		NodeStorage: ns,
+		Network:     net,
+		Role:        cfg.Core.Roles,
+		CodeHash:    codeHash,
+		PoolSize:    int(cfg.Core.RuntimeInstancePoolSize),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create runtime executor: %w", err)
 	}
 
 	st.Block.StoreRuntime(st.Block.BestBlockHash(), rt)
@@ -257,9 +250,16 @@ func (nodeBuilder) createCoreService(cfg *Config, ks *keystore.GlobalKeystore,
 		StorageState:         st.Storage,
 		TransactionState:     st.Transaction,
 		Keystore:             ks,
+		KeystoreBasePath:     cfg.Global.BasePath,
+		KeystorePassword:     []byte(cfg.Account.Password),
 		Network:              net,
 		CodeSubstitutes:      codeSubs,
 		CodeSubstitutedState: st.Base,
+		OffchainWorker: core.OffchainWorkerConfig{
+			Enabled:        cfg.Core.OffchainWorkers,
+			Timeout:        cfg.Core.OffchainWorkerTimeout,
+			MaxConcurrency: cfg.Core.OffchainWorkerMaxConcurrency,
+		},
 	}
 
 	// create new core service
@@ -291,6 +291,8 @@ func (nodeBuilder) createNetworkService(cfg *Config, stateSrvc *state.Service,
 	networkConfig := network.Config{
 		LogLvl:            cfg.Log.NetworkLvl,
 		BlockState:        stateSrvc.Block,
+		StorageState:      stateSrvc.Storage,
+		WarpSyncProvider:  grandpa.NewWarpSyncProofProvider(stateSrvc.Block, stateSrvc.Grandpa),
 		BasePath:          cfg.Global.BasePath,
 		Roles:             cfg.Core.Roles,
 		Port:              cfg.Network.Port,
@@ -320,6 +322,10 @@ func (nodeBuilder) createNetworkService(cfg *Config, stateSrvc *state.Service,
 
 // RPC Service
 
+// megabyte is the number of bytes in a megabyte, used to convert
+// the RPC request and response sizes configured in megabytes.
+const megabyte = 1024 * 1024
+
 // createRPCService creates the RPC service from the provided core configuration
 func (nodeBuilder) createRPCService(params rpcServiceSettings) (*rpc.HTTPServer, error) {
 	logger.Infof(
@@ -370,6 +376,15 @@ func (nodeBuilder) createRPCService(params rpcServiceSettings) (*rpc.HTTPServer,
 		WSUnsafeExternal:    params.config.RPC.WSUnsafeExternal,
 		WSPort:              params.config.RPC.WSPort,
 		Modules:             params.config.RPC.Modules,
+		MaxBatchSize:        params.config.RPC.MaxBatchSize,
+
+		WSMaxConnections:                params.config.RPC.WSMaxConnections,
+		WSMaxSubscriptionsPerConnection: params.config.RPC.WSMaxSubscriptionsPerConnection,
+		MaxRequestSize:                  uint64(params.config.RPC.MaxRequestSize) * megabyte,
+		MaxResponseSize:                 uint64(params.config.RPC.MaxResponseSize) * megabyte,
+		RateLimit:                       params.config.RPC.RateLimit,
+		RateLimitBurst:                  params.config.RPC.RateLimitBurst,
+		CORS:                            params.config.RPC.CORS,
 	}
 
 	return rpc.NewHTTPServer(rpcConfig), nil
@@ -414,6 +429,7 @@ func (nodeBuilder) createGRANDPAService(cfg *Config, st *state.Service, ks KeySt
 		LogLvl:       cfg.Log.FinalityGadgetLvl,
 		BlockState:   st.Block,
 		GrandpaState: st.Grandpa,
+		StorageState: st.Storage,
 		Voters:       voters,
 		Authority:    cfg.Core.GrandpaAuthority,
 		Network:      net,
@@ -453,6 +469,12 @@ func (nodeBuilder) newSyncService(cfg *Config, st *state.Service, fg BlockJustif
 		MaxPeers:           cfg.Network.MaxPeers,
 		SlotDuration:       slotDuration,
 		Telemetry:          telemetryMailer,
+		Mode:               cfg.Network.SyncMode,
+	}
+
+	if cfg.Network.SyncMode == sync.WarpMode {
+		syncCfg.GrandpaState = st.Grandpa
+		syncCfg.WarpSyncProofVerifier = grandpa.NewWarpSyncProofProvider(st.Block, st.Grandpa)
 	}
 
 	return sync.NewService(syncCfg)
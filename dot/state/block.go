@@ -12,6 +12,7 @@
 	"time"
 
 	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/state/pruner"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/blocktree"
 	"github.com/ChainSafe/gossamer/lib/common"
@@ -36,9 +37,14 @@
 	receiptPrefix       = []byte("rcp") // receiptPrefix + hash -> receipt
 	messageQueuePrefix  = []byte("mqp") // messageQueuePrefix + hash -> message queue
 	justificationPrefix = []byte("jcp") // justificationPrefix + hash -> justification
+	// indexedTransactionPrefix + content hash -> indexed transaction
+	indexedTransactionPrefix = []byte("itx")
+	// blockIndexedTransactionsPrefix + hash -> indexed transaction content hashes
+	blockIndexedTransactionsPrefix = []byte("itb")
 
-	errNilBlockTree = errors.New("blocktree is nil")
-	errNilBlockBody = errors.New("block body is nil")
+	errNilBlockTree     = errors.New("blocktree is nil")
+	errNilBlockBody     = errors.New("block body is nil")
+	errEmptyRuntimeCode = errors.New("runtime code is empty")
 
 	syncedBlocksGauge = promauto.NewGauge(prometheus.GaugeOpts{
 		Namespace: "gossamer_network_syncer",
@@ -69,6 +75,14 @@ type BlockState struct {
 	runtimeUpdateSubscriptions     map[uint32]chan<- runtime.Version
 
 	telemetry Telemetry
+
+	// transactionStoragePeriod is the number of finalised blocks for which
+	// indexed transactions are kept. A zero value keeps them forever.
+	transactionStoragePeriod uint32
+	// storagePruner prunes the state trie nodes of finalised blocks
+	// falling out of the window of retained blocks on finalisation.
+	// It is nil if no pruning is done.
+	storagePruner pruner.Pruner
 }
 
 // NewBlockState will create a new BlockState backed by the database located at basePath
@@ -771,19 +785,7 @@ func (bs *BlockState) HandleRuntimeChanges(newState *rtstorage.TrieState,
 			bHash, codeHash, previousVersion.SpecVersion, currCodeHash, newVersion.SpecVersion)
 	}
 
-	rtCfg := wasmer.Config{
-		Storage:     newState,
-		Keystore:    rt.Keystore(),
-		NodeStorage: rt.NodeStorage(),
-		Network:     rt.NetworkService(),
-		CodeHash:    currCodeHash,
-	}
-
-	if rt.Validator() {
-		rtCfg.Role = 4
-	}
-
-	instance, err := wasmer.NewInstance(code, rtCfg)
+	instance, err := NewRuntimeFromPrevious(rt, code, newState, currCodeHash)
 	if err != nil {
 		return err
 	}
@@ -805,6 +807,34 @@ func (bs *BlockState) GetRuntime(blockHash common.Hash) (instance Runtime, err e
 	return bs.bt.GetBlockRuntime(blockHash)
 }
 
+// CallRuntime executes the runtime function given with the data given, using the
+// runtime of the block given and the storage given. The call runs on a runtime
+// instance acquired for the call only, so it does not interfere with other calls.
+// The result returned is a copy, since the memory of the instance is reused once
+// it is released.
+func (bs *BlockState) CallRuntime(blockHash common.Hash, storage runtime.Storage,
+	function string, data []byte) (result []byte, err error) {
+	rt, err := bs.GetRuntime(blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("getting runtime: %w", err)
+	}
+
+	instance, release, err := runtime.AcquireInstance(rt)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	instance.SetContextStorage(storage)
+	result, err = instance.Exec(function, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Note bytes.Clone cannot be used until the module requires Go 1.20.
+	return append([]byte(nil), result...), nil
+}
+
 // StoreRuntime stores the runtime for corresponding block hash.
 func (bs *BlockState) StoreRuntime(hash common.Hash, rt Runtime) {
 	bs.bt.StoreRuntime(hash, rt)
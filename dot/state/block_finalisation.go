@@ -9,7 +9,9 @@
 
 	"github.com/ChainSafe/gossamer/dot/telemetry"
 	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/blocktree"
 	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 )
 
 var highestRoundAndSetIDKey = []byte("hrs")
@@ -144,6 +146,10 @@ func (bs *BlockState) SetFinalisedHash(hash common.Hash, round, setID uint64) er
 
 	pruned := bs.bt.Prune(hash)
 	for _, hash := range pruned {
+		if err := bs.pruneIndexedTransactions(hash); err != nil {
+			return fmt.Errorf("failed to prune indexed transactions of pruned block %s: %w", hash, err)
+		}
+
 		blockHeader := bs.unfinalisedBlocks.delete(hash)
 		if blockHeader == nil {
 			continue
@@ -175,6 +181,16 @@ func (bs *BlockState) SetFinalisedHash(hash common.Hash, round, setID uint64) er
 	)
 
 	if bs.lastFinalised != hash {
+		lastFinalisedHeader, err := bs.GetHeader(bs.lastFinalised)
+		if err != nil {
+			return fmt.Errorf("failed to get last finalised header: %w", err)
+		}
+
+		err = bs.pruneFinalisedIndexedTransactions(lastFinalisedHeader.Number, header.Number)
+		if err != nil {
+			return fmt.Errorf("failed to prune finalised indexed transactions: %w", err)
+		}
+
 		defer func(lastFinalised common.Hash) {
 			err := bs.deleteFromTries(lastFinalised)
 			if err != nil {
@@ -183,10 +199,88 @@ func (bs *BlockState) SetFinalisedHash(hash common.Hash, round, setID uint64) er
 		}(bs.lastFinalised)
 	}
 
+	if bs.storagePruner != nil {
+		err = bs.storagePruner.PruneFinalised(header.Number)
+		if err != nil {
+			return fmt.Errorf("failed to prune storage on finalisation: %w", err)
+		}
+	}
+
 	bs.lastFinalised = hash
 	return nil
 }
 
+// SetWarpSyncedBlock sets the given header as the highest finalised block and as the
+// root of the block tree, with the given set ID and round 0, so blocks are synced from it.
+// It is used once the state of the block is downloaded when warp syncing, and the runtime
+// of the block is instantiated from its state given.
+func (bs *BlockState) SetWarpSyncedBlock(header *types.Header, state *rtstorage.TrieState,
+	setID uint64) (err error) {
+	bs.Lock()
+	defer bs.Unlock()
+
+	hash := header.Hash()
+	code := state.LoadCode()
+	if len(code) == 0 {
+		return fmt.Errorf("%w: in state of block %s", errEmptyRuntimeCode, hash)
+	}
+
+	codeHash, err := common.Blake2bHash(code)
+	if err != nil {
+		return fmt.Errorf("hashing runtime code: %w", err)
+	}
+
+	previous, err := bs.bt.GetBlockRuntime(bs.lastFinalised)
+	if err != nil {
+		return fmt.Errorf("getting runtime of last finalised block: %w", err)
+	}
+
+	rt, err := NewRuntimeFromPrevious(previous, code, state, codeHash)
+	if err != nil {
+		return fmt.Errorf("instantiating runtime of block %s: %w", hash, err)
+	}
+	defer func() {
+		if err != nil {
+			rt.Stop()
+		}
+	}()
+
+	err = bs.SetHeader(header)
+	if err != nil {
+		return fmt.Errorf("setting header: %w", err)
+	}
+
+	batch := bs.db.NewBatch()
+	err = batch.Put(headerHashKey(uint64(header.Number)), hash.ToBytes())
+	if err != nil {
+		return fmt.Errorf("setting block hash by number: %w", err)
+	}
+
+	err = batch.Put(finalisedHashKey(0, setID), hash[:])
+	if err != nil {
+		return fmt.Errorf("setting finalised hash key: %w", err)
+	}
+
+	err = batch.Put(highestRoundAndSetIDKey, roundAndSetIDToBytes(0, setID))
+	if err != nil {
+		return fmt.Errorf("setting highest round and set ID: %w", err)
+	}
+
+	err = batch.Flush()
+	if err != nil {
+		return fmt.Errorf("writing batch: %w", err)
+	}
+
+	bs.bt = blocktree.NewBlockTreeFromRoot(header)
+	bs.bt.StoreRuntime(hash, rt)
+	bs.unfinalisedBlocks = newHashToBlockMap()
+	bs.lastFinalised = hash
+	previous.Stop()
+
+	bs.telemetry.SendMessage(telemetry.NewNotifyFinalized(hash, fmt.Sprint(header.Number)))
+	return nil
+}
+
 func (bs *BlockState) deleteFromTries(lastFinalised common.Hash) error {
 	lastFinalisedHeader, err := bs.GetHeader(lastFinalised)
 	if err != nil {
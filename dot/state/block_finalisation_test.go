@@ -7,8 +7,13 @@
 	"testing"
 
 	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/blocktree"
 	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/mocks"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/golang/mock/gomock"
 
 	"github.com/stretchr/testify/require"
 )
@@ -152,3 +157,43 @@ func TestSetFinalisedHash_setFirstSlotOnFinalisation(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, firstSlot, res)
 }
+
+func TestBlockState_SetWarpSyncedBlock(t *testing.T) {
+	t.Parallel()
+
+	header := &types.Header{
+		ParentHash: common.Hash{1},
+		Number:     100,
+		Digest:     types.NewDigest(),
+	}
+
+	emptyState := rtstorage.NewTrieState(trie.NewEmptyTrie())
+	err := emptyState.Put(common.CodeKey, nil)
+	require.NoError(t, err)
+	bs := newTestBlockState(t, newTriesEmpty())
+	err = bs.SetWarpSyncedBlock(header, emptyState, 2)
+	require.ErrorIs(t, err, errEmptyRuntimeCode)
+
+	invalidCodeState := rtstorage.NewTrieState(trie.NewEmptyTrie())
+	err = invalidCodeState.Put(common.CodeKey, []byte{1, 2, 3})
+	require.NoError(t, err)
+	err = bs.SetWarpSyncedBlock(header, invalidCodeState, 2)
+	require.ErrorIs(t, err, blocktree.ErrFailedToGetRuntime)
+
+	ctrl := gomock.NewController(t)
+	previous := mocks.NewMockInstance(ctrl)
+	previous.EXPECT().Validator().Return(false)
+	previous.EXPECT().Keystore().Return(nil)
+	previous.EXPECT().NodeStorage().Return(runtime.NodeStorage{})
+	previous.EXPECT().NetworkService().Return(nil)
+	bs.StoreRuntime(testGenesisHeader.Hash(), previous)
+
+	err = bs.SetWarpSyncedBlock(header, invalidCodeState, 2)
+	require.ErrorContains(t, err, "instantiating runtime of block "+header.Hash().String())
+
+	// the block state is left unchanged on error
+	finalisedHeader, err := bs.GetHighestFinalisedHeader()
+	require.NoError(t, err)
+	require.Equal(t, testGenesisHeader.Hash(), finalisedHeader.Hash())
+	require.Equal(t, testGenesisHeader.Hash(), bs.BestBlockHash())
+}
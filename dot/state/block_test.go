@@ -12,6 +12,8 @@
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/blocktree"
 	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime/mocks"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 	"github.com/ChainSafe/gossamer/lib/trie"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 	"github.com/golang/mock/gomock"
@@ -960,3 +962,28 @@ func Test_loadHeaderFromDisk_WithGenesisBlock(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, genesisHeader.Hash(), header.Hash())
 }
+
+func TestBlockState_CallRuntime(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	bs := newTestBlockState(t, newTriesEmpty())
+	blockHash := testGenesisHeader.Hash()
+	storage := rtstorage.NewTrieState(trie.NewEmptyTrie())
+
+	_, err := bs.CallRuntime(blockHash, storage, "Core_version", nil)
+	require.ErrorIs(t, err, blocktree.ErrFailedToGetRuntime)
+
+	instance := mocks.NewMockInstance(ctrl)
+	instance.EXPECT().SetContextStorage(storage)
+	instanceResult := []byte{2}
+	instance.EXPECT().Exec("Core_version", []byte{1}).Return(instanceResult, nil)
+	bs.StoreRuntime(blockHash, instance)
+
+	result, err := bs.CallRuntime(blockHash, storage, "Core_version", []byte{1})
+	require.NoError(t, err)
+
+	// the instance memory holding its result may be reused once released
+	instanceResult[0] = 3
+	require.Equal(t, []byte{2}, result)
+}
@@ -0,0 +1,263 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+// ErrIndexedTransactionNotFound is returned when no indexed transaction
+// is stored for a content hash.
+var ErrIndexedTransactionNotFound = errors.New("indexed transaction not found")
+
+// indexedTransaction is the data of an extrinsic indexed by the runtime,
+// together with the number of blocks referencing it. The data is deleted
+// once no block references it anymore.
+type indexedTransaction struct {
+	Data       []byte
+	References uint32
+}
+
+func indexedTransactionKey(hash common.Hash) []byte {
+	return append(indexedTransactionPrefix, hash.ToBytes()...)
+}
+
+func blockIndexedTransactionsKey(blockHash common.Hash) []byte {
+	return append(blockIndexedTransactionsPrefix, blockHash.ToBytes()...)
+}
+
+// StoreIndexedTransactions applies the transaction indexing operations
+// requested by the runtime when executing the given block. Indexed data
+// is stored keyed by its content hash, and each operation of the block,
+// whether an insertion or a renewal, references the data until the block
+// is pruned, see SetFinalisedHash.
+func (bs *BlockState) StoreIndexedTransactions(block *types.Block,
+	operations []rtstorage.TransactionIndexOperation) error {
+	if len(operations) == 0 {
+		return nil
+	}
+
+	bs.Lock()
+	defer bs.Unlock()
+
+	blockHash := block.Header.Hash()
+	transactions := make(map[common.Hash]*indexedTransaction, len(operations))
+	referenced := make([]common.Hash, 0, len(operations))
+
+	for _, operation := range operations {
+		transaction, ok := transactions[operation.Hash]
+		if !ok {
+			var err error
+			transaction, err = bs.getIndexedTransaction(operation.Hash)
+			if err != nil && !errors.Is(err, ErrIndexedTransactionNotFound) {
+				return fmt.Errorf("getting indexed transaction: %w", err)
+			}
+		}
+
+		if operation.Renew {
+			if transaction == nil {
+				logger.Debugf("cannot renew unknown indexed transaction %s in block %s",
+					operation.Hash, blockHash)
+				continue
+			}
+		} else if transaction == nil {
+			data, err := extrinsicIndexedData(block.Body, operation)
+			if err != nil {
+				logger.Debugf("cannot index transaction %s in block %s: %s",
+					operation.Hash, blockHash, err)
+				continue
+			}
+			transaction = &indexedTransaction{Data: data}
+		}
+
+		transaction.References++
+		transactions[operation.Hash] = transaction
+		referenced = append(referenced, operation.Hash)
+	}
+
+	if len(referenced) == 0 {
+		return nil
+	}
+
+	batch := bs.db.NewBatch()
+	for hash, transaction := range transactions {
+		encoded, err := scale.Marshal(*transaction)
+		if err != nil {
+			return fmt.Errorf("encoding indexed transaction: %w", err)
+		}
+
+		err = batch.Put(indexedTransactionKey(hash), encoded)
+		if err != nil {
+			return fmt.Errorf("putting indexed transaction in batch: %w", err)
+		}
+	}
+
+	encodedReferenced, err := scale.Marshal(referenced)
+	if err != nil {
+		return fmt.Errorf("encoding indexed transaction hashes: %w", err)
+	}
+
+	err = batch.Put(blockIndexedTransactionsKey(blockHash), encodedReferenced)
+	if err != nil {
+		return fmt.Errorf("putting indexed transaction hashes in batch: %w", err)
+	}
+
+	return batch.Flush()
+}
+
+// extrinsicIndexedData returns the data to index for the given insert
+// operation, which is the end of the extrinsic it refers to.
+func extrinsicIndexedData(body types.Body, operation rtstorage.TransactionIndexOperation) (
+	data []byte, err error) {
+	if uint64(operation.Extrinsic) >= uint64(len(body)) {
+		return nil, fmt.Errorf("extrinsic index %d is out of range for %d extrinsics",
+			operation.Extrinsic, len(body))
+	}
+
+	extrinsic := body[operation.Extrinsic]
+	if uint64(operation.Size) > uint64(len(extrinsic)) {
+		return nil, fmt.Errorf("size %d is larger than extrinsic %d of %d bytes",
+			operation.Size, operation.Extrinsic, len(extrinsic))
+	}
+
+	data = make([]byte, operation.Size)
+	copy(data, extrinsic[len(extrinsic)-int(operation.Size):])
+	return data, nil
+}
+
+// GetIndexedTransaction returns the indexed transaction data
+// for the given content hash.
+func (bs *BlockState) GetIndexedTransaction(hash common.Hash) (data []byte, err error) {
+	bs.RLock()
+	defer bs.RUnlock()
+
+	transaction, err := bs.getIndexedTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+	return transaction.Data, nil
+}
+
+func (bs *BlockState) getIndexedTransaction(hash common.Hash) (
+	transaction *indexedTransaction, err error) {
+	encoded, err := bs.db.Get(indexedTransactionKey(hash))
+	if err != nil {
+		if errors.Is(err, chaindb.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w: for content hash %s", ErrIndexedTransactionNotFound, hash)
+		}
+		return nil, fmt.Errorf("getting indexed transaction from database: %w", err)
+	}
+
+	transaction = new(indexedTransaction)
+	err = scale.Unmarshal(encoded, transaction)
+	if err != nil {
+		return nil, fmt.Errorf("decoding indexed transaction: %w", err)
+	}
+	return transaction, nil
+}
+
+// pruneIndexedTransactions releases the references to indexed transactions
+// held by the block with the given hash, deleting the indexed transactions
+// no longer referenced by any block.
+// It is NOT THREAD SAFE to use, and the block state lock must be held.
+func (bs *BlockState) pruneIndexedTransactions(blockHash common.Hash) error {
+	encodedReferenced, err := bs.db.Get(blockIndexedTransactionsKey(blockHash))
+	if err != nil {
+		if errors.Is(err, chaindb.ErrKeyNotFound) {
+			return nil
+		}
+		return fmt.Errorf("getting indexed transaction hashes: %w", err)
+	}
+
+	var referenced []common.Hash
+	err = scale.Unmarshal(encodedReferenced, &referenced)
+	if err != nil {
+		return fmt.Errorf("decoding indexed transaction hashes: %w", err)
+	}
+
+	transactions := make(map[common.Hash]*indexedTransaction, len(referenced))
+	for _, hash := range referenced {
+		transaction, ok := transactions[hash]
+		if !ok {
+			transaction, err = bs.getIndexedTransaction(hash)
+			if errors.Is(err, ErrIndexedTransactionNotFound) {
+				continue
+			} else if err != nil {
+				return fmt.Errorf("getting indexed transaction: %w", err)
+			}
+			transactions[hash] = transaction
+		}
+
+		if transaction.References > 0 {
+			transaction.References--
+		}
+	}
+
+	batch := bs.db.NewBatch()
+	for hash, transaction := range transactions {
+		if transaction.References == 0 {
+			err = batch.Del(indexedTransactionKey(hash))
+			if err != nil {
+				return fmt.Errorf("deleting indexed transaction in batch: %w", err)
+			}
+			continue
+		}
+
+		encoded, err := scale.Marshal(*transaction)
+		if err != nil {
+			return fmt.Errorf("encoding indexed transaction: %w", err)
+		}
+
+		err = batch.Put(indexedTransactionKey(hash), encoded)
+		if err != nil {
+			return fmt.Errorf("putting indexed transaction in batch: %w", err)
+		}
+	}
+
+	err = batch.Del(blockIndexedTransactionsKey(blockHash))
+	if err != nil {
+		return fmt.Errorf("deleting indexed transaction hashes in batch: %w", err)
+	}
+
+	return batch.Flush()
+}
+
+// pruneFinalisedIndexedTransactions prunes the indexed transactions of the
+// finalised blocks falling out of the transaction storage period, when
+// finalising the block with the given number after the previously
+// finalised block with the given number.
+// It is NOT THREAD SAFE to use, and the block state lock must be held.
+func (bs *BlockState) pruneFinalisedIndexedTransactions(previousFinalised, finalised uint) error {
+	period := uint(bs.transactionStoragePeriod)
+	if period == 0 || finalised <= period {
+		return nil
+	}
+
+	start := uint(0)
+	if previousFinalised > period {
+		start = previousFinalised - period + 1
+	}
+	end := finalised - period
+
+	for number := start; number <= end; number++ {
+		hash, err := bs.db.Get(headerHashKey(uint64(number)))
+		if err != nil {
+			return fmt.Errorf("getting hash of finalised block number %d: %w", number, err)
+		}
+
+		err = bs.pruneIndexedTransactions(common.NewHash(hash))
+		if err != nil {
+			return fmt.Errorf("pruning indexed transactions of block number %d: %w", number, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_extrinsicIndexedData(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		body       types.Body
+		operation  rtstorage.TransactionIndexOperation
+		data       []byte
+		errMessage string
+	}{
+		"extrinsic_out_of_range": {
+			body:       types.Body{{1}},
+			operation:  rtstorage.TransactionIndexOperation{Extrinsic: 1},
+			errMessage: "extrinsic index 1 is out of range for 1 extrinsics",
+		},
+		"size_too_large": {
+			body:       types.Body{{1, 2}},
+			operation:  rtstorage.TransactionIndexOperation{Size: 3},
+			errMessage: "size 3 is larger than extrinsic 0 of 2 bytes",
+		},
+		"end_of_extrinsic": {
+			body:      types.Body{{1}, {1, 2, 3, 4}},
+			operation: rtstorage.TransactionIndexOperation{Extrinsic: 1, Size: 2},
+			data:      []byte{3, 4},
+		},
+		"zero_size": {
+			body:      types.Body{{1}},
+			operation: rtstorage.TransactionIndexOperation{},
+			data:      []byte{},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := extrinsicIndexedData(testCase.body, testCase.operation)
+
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.data, data)
+		})
+	}
+}
+
+func newTestIndexedBlock(t *testing.T, parentHash common.Hash, number uint, body types.Body) *types.Block {
+	t.Helper()
+
+	digest := types.NewDigest()
+	preDigest, err := types.NewBabeSecondaryPlainPreDigest(0, uint64(number)).ToPreRuntimeDigest()
+	require.NoError(t, err)
+	err = digest.Add(*preDigest)
+	require.NoError(t, err)
+
+	return &types.Block{
+		Header: types.Header{
+			ParentHash: parentHash,
+			Number:     number,
+			Digest:     digest,
+		},
+		Body: body,
+	}
+}
+
+func TestBlockState_StoreIndexedTransactions(t *testing.T) {
+	t.Parallel()
+
+	bs := newTestBlockState(t, newTriesEmpty())
+
+	hashA := common.Hash{0xa}
+	hashB := common.Hash{0xb}
+	unknownHash := common.Hash{0xc}
+
+	block := newTestIndexedBlock(t, testGenesisHeader.Hash(), 1, types.Body{{1, 2, 3}, {4, 5}})
+	operations := []rtstorage.TransactionIndexOperation{
+		{Extrinsic: 0, Size: 2, Hash: hashA},
+		{Extrinsic: 1, Size: 2, Hash: hashB},
+		{Extrinsic: 1, Size: 2, Hash: hashB},
+		{Extrinsic: 2, Size: 1, Hash: unknownHash},
+		{Extrinsic: 0, Hash: unknownHash, Renew: true},
+	}
+
+	err := bs.StoreIndexedTransactions(block, operations)
+	require.NoError(t, err)
+
+	data, err := bs.GetIndexedTransaction(hashA)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2, 3}, data)
+
+	transaction, err := bs.getIndexedTransaction(hashB)
+	require.NoError(t, err)
+	expectedTransaction := &indexedTransaction{Data: []byte{4, 5}, References: 2}
+	assert.Equal(t, expectedTransaction, transaction)
+
+	_, err = bs.GetIndexedTransaction(unknownHash)
+	assert.ErrorIs(t, err, ErrIndexedTransactionNotFound)
+
+	// renewing in another block adds a reference
+	renewBlock := newTestIndexedBlock(t, block.Header.Hash(), 2, types.Body{})
+	err = bs.StoreIndexedTransactions(renewBlock, []rtstorage.TransactionIndexOperation{
+		{Hash: hashA, Renew: true},
+	})
+	require.NoError(t, err)
+
+	transaction, err = bs.getIndexedTransaction(hashA)
+	require.NoError(t, err)
+	expectedTransaction = &indexedTransaction{Data: []byte{2, 3}, References: 2}
+	assert.Equal(t, expectedTransaction, transaction)
+
+	// pruning the first block keeps only the renewed transaction
+	err = bs.pruneIndexedTransactions(block.Header.Hash())
+	require.NoError(t, err)
+
+	data, err = bs.GetIndexedTransaction(hashA)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2, 3}, data)
+
+	_, err = bs.GetIndexedTransaction(hashB)
+	assert.ErrorIs(t, err, ErrIndexedTransactionNotFound)
+
+	err = bs.pruneIndexedTransactions(renewBlock.Header.Hash())
+	require.NoError(t, err)
+
+	_, err = bs.GetIndexedTransaction(hashA)
+	assert.ErrorIs(t, err, ErrIndexedTransactionNotFound)
+}
+
+func TestBlockState_SetFinalisedHash_pruneIndexedTransactions(t *testing.T) {
+	t.Parallel()
+
+	bs := newTestBlockState(t, newTriesEmpty())
+	bs.transactionStoragePeriod = 2
+
+	contentHashes := make([]common.Hash, 4)
+	blocks := make([]*types.Block, 4)
+	parentHash := testGenesisHeader.Hash()
+	for i := range blocks {
+		contentHashes[i] = common.Hash{byte(i + 1)}
+		blocks[i] = newTestIndexedBlock(t, parentHash, uint(i+1), types.Body{{byte(i)}})
+		parentHash = blocks[i].Header.Hash()
+
+		err := bs.AddBlock(blocks[i])
+		require.NoError(t, err)
+
+		err = bs.StoreIndexedTransactions(blocks[i], []rtstorage.TransactionIndexOperation{
+			{Size: 1, Hash: contentHashes[i]},
+		})
+		require.NoError(t, err)
+	}
+
+	// fork block at number 1 which gets pruned on finalisation
+	forkBlock := newTestIndexedBlock(t, testGenesisHeader.Hash(), 1, types.Body{{0xf}})
+	forkBlock.Header.StateRoot = common.Hash{0xf}
+	err := bs.AddBlock(forkBlock)
+	require.NoError(t, err)
+	forkContentHash := common.Hash{0xf}
+	err = bs.StoreIndexedTransactions(forkBlock, []rtstorage.TransactionIndexOperation{
+		{Size: 1, Hash: forkContentHash},
+	})
+	require.NoError(t, err)
+
+	err = bs.SetFinalisedHash(blocks[2].Header.Hash(), 1, 1)
+	require.NoError(t, err)
+
+	_, err = bs.GetIndexedTransaction(forkContentHash)
+	assert.ErrorIs(t, err, ErrIndexedTransactionNotFound)
+	_, err = bs.GetIndexedTransaction(contentHashes[0])
+	assert.ErrorIs(t, err, ErrIndexedTransactionNotFound)
+	for _, contentHash := range contentHashes[1:] {
+		_, err = bs.GetIndexedTransaction(contentHash)
+		assert.NoError(t, err)
+	}
+
+	err = bs.SetFinalisedHash(blocks[3].Header.Hash(), 1, 2)
+	require.NoError(t, err)
+
+	_, err = bs.GetIndexedTransaction(contentHashes[1])
+	assert.ErrorIs(t, err, ErrIndexedTransactionNotFound)
+	for _, contentHash := range contentHashes[2:] {
+		data, err := bs.GetIndexedTransaction(contentHash)
+		assert.NoError(t, err)
+		assert.Len(t, data, 1)
+	}
+}
@@ -407,6 +407,29 @@ func (s *GrandpaState) SetNextChange(authorities []types.GrandpaVoter, number ui
 	return nil
 }
 
+// SetCurrentAuthoritySet sets the given authorities as the current authority set with
+// the given set ID, for the descendants of the block with the given number.
+// It is used when warp syncing to that block.
+func (s *GrandpaState) SetCurrentAuthoritySet(setID uint64, authorities []types.GrandpaVoter,
+	number uint) error {
+	err := s.setAuthorities(setID, authorities)
+	if err != nil {
+		return fmt.Errorf("setting authorities: %w", err)
+	}
+
+	err = s.setChangeSetIDAtBlock(setID, number)
+	if err != nil {
+		return fmt.Errorf("setting set ID change block number: %w", err)
+	}
+
+	err = s.setCurrentSetID(setID)
+	if err != nil {
+		return fmt.Errorf("setting current set ID: %w", err)
+	}
+
+	return nil
+}
+
 // IncrementSetID increments the set ID
 func (s *GrandpaState) IncrementSetID() (newSetID uint64, err error) {
 	currSetID, err := s.GetCurrentSetID()
@@ -457,6 +480,12 @@ func (s *GrandpaState) GetSetIDByBlockNumber(blockNumber uint) (uint64, error) {
 			return 0, err
 		}
 
+		if blockNumber > changeUpper {
+			return curr + 1, nil
+		}
+
+		// Set id changes before an authority set set by warp sync are unknown,
+		// so changeLower is only looked up if the block is not after changeUpper.
 		changeLower, err := s.GetSetIDChange(curr)
 		if err != nil {
 			return 0, err
@@ -467,14 +496,10 @@ func (s *GrandpaState) GetSetIDByBlockNumber(blockNumber uint) (uint64, error) {
 		// would be more than changeLower.
 		// Next set id change happens at the last block of current set. Thus, a block number from
 		// given set could be lower or equal to changeUpper.
-		if blockNumber <= changeUpper && blockNumber > changeLower {
+		if blockNumber > changeLower {
 			return curr, nil
 		}
 
-		if blockNumber > changeUpper {
-			return curr + 1, nil
-		}
-
 		curr = curr - 1
 
 		if int(curr) < 0 {
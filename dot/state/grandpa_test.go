@@ -73,6 +73,28 @@ func TestGrandpaState_IncrementSetID(t *testing.T) {
 	require.Equal(t, genesisSetID+1, setID)
 }
 
+func TestGrandpaState_SetCurrentAuthoritySet(t *testing.T) {
+	db := NewInMemoryDB(t)
+	gs, err := NewGrandpaStateFromGenesis(db, nil, testAuths)
+	require.NoError(t, err)
+
+	authorities := []types.GrandpaVoter{{Key: *kr.Bob().Public().(*ed25519.PublicKey), ID: 1}}
+	err = gs.SetCurrentAuthoritySet(3, authorities, 100)
+	require.NoError(t, err)
+
+	setID, err := gs.GetCurrentSetID()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), setID)
+
+	auths, err := gs.GetAuthorities(3)
+	require.NoError(t, err)
+	require.Equal(t, authorities, auths)
+
+	setID, err = gs.GetSetIDByBlockNumber(101)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), setID)
+}
+
 func TestGrandpaState_GetSetIDByBlockNumber(t *testing.T) {
 	db := NewInMemoryDB(t)
 	gs, err := NewGrandpaStateFromGenesis(db, nil, testAuths)
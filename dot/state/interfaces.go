@@ -91,9 +91,11 @@ type Runtime interface {
 	CheckInherents()
 	BabeGenerateKeyOwnershipProof(slot uint64, offenderPublicKey [32]byte) (types.OpaqueKeyOwnershipProof, error)
 	BabeSubmitReportEquivocationUnsignedExtrinsic(types.BabeEquivocationProof, types.OpaqueKeyOwnershipProof) error
+	GrandpaGenerateKeyOwnershipProof(setID uint64, authorityID [32]byte) (types.OpaqueKeyOwnershipProof, error)
+	GrandpaSubmitReportEquivocationUnsignedExtrinsic(types.GrandpaEquivocationProof, types.OpaqueKeyOwnershipProof) error
 	RandomSeed()
-	OffchainWorker()
-	GenerateSessionKeys()
+	OffchainWorker(header *types.Header) error
+	GenerateSessionKeys(seed *[]byte) (publicKeys []byte, err error)
 }
 
 // BabeConfigurer returns the babe configuration of the runtime.
@@ -0,0 +1,588 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package pruner
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+const journalPrefix = "journal"
+
+var (
+	lastPrunedKey          = []byte("last_pruned")
+	journalRecordPrefix    = []byte("record")
+	blockHashesPrefix      = []byte("block_hashes")
+	insertedNodeHashPrefix = []byte("inserted")
+)
+
+var logger = log.NewFromGlobal(
+	log.AddContext("pkg", "pruner"),
+)
+
+// Database is the database interface used by the full node pruner.
+type Database interface {
+	Get(key []byte) (value []byte, err error)
+	Put(key, value []byte) error
+	NewBatch() chaindb.Batch
+}
+
+// NewBatcher creates a new database batch.
+type NewBatcher interface {
+	NewBatch() chaindb.Batch
+}
+
+// BlockState is the block state interface used by the full node pruner.
+type BlockState interface {
+	GetHighestFinalisedHeader() (*types.Header, error)
+	GetHashByNumber(blockNumber uint) (common.Hash, error)
+}
+
+// journalKey identifies the journal record of a block.
+type journalKey struct {
+	BlockNumber int64
+	BlockHash   common.Hash
+}
+
+// journalRecord holds the merkle values of the trie nodes inserted
+// and deleted in the state trie of a block, compared to its parent.
+type journalRecord struct {
+	InsertedMerkleValues [][]byte
+	DeletedMerkleValues  [][]byte
+}
+
+// FullNode stores a journal of the state trie nodes inserted and deleted for
+// each block, and prunes trie nodes from the storage database for finalised
+// blocks falling out of the window of retained blocks, both when a block is
+// imported and when a block is finalised.
+// For each pruned block number, the node hashes deleted by the finalised block
+// are removed from the storage database, and the node hashes inserted by blocks
+// on pruned forks are removed as well, unless they are still in use by a journaled
+// block not yet pruned.
+type FullNode struct {
+	storageDatabase NewBatcher
+	journalDatabase Database
+	blockState      BlockState
+	retainBlocks    uint32
+
+	// nextBlockNumberToPrune is the next block number to prune.
+	// It is persisted to the journal database as the last pruned
+	// block number once a block number is fully pruned.
+	nextBlockNumberToPrune int64
+	// highestBlockNumber is the highest block number journaled
+	// since the pruner started.
+	highestBlockNumber int64
+	// initialised is false until the pruner knows from which block number
+	// to start pruning, either from the database or from the first block
+	// journal record stored.
+	initialised bool
+	// pinned maps block numbers pinned to their number of pins.
+	// Pruning stops before the lowest block number pinned.
+	pinned map[uint]uint
+	mutex  sync.Mutex
+}
+
+// NewFullNode creates a full node pruner using the database given to store
+// its journal and the storage database given to prune trie nodes from.
+func NewFullNode(database chaindb.Database, storageDatabase NewBatcher,
+	retainBlocks uint32, blockState BlockState) (pruner *FullNode, err error) {
+	pruner = &FullNode{
+		storageDatabase: storageDatabase,
+		journalDatabase: chaindb.NewTable(database, journalPrefix),
+		blockState:      blockState,
+		retainBlocks:    retainBlocks,
+		pinned:          make(map[uint]uint),
+	}
+
+	lastPruned, err := pruner.getLastPrunedBlockNumber()
+	switch {
+	case errors.Is(err, chaindb.ErrKeyNotFound):
+		logger.Debug("no block pruned yet")
+	case err != nil:
+		return nil, fmt.Errorf("getting last pruned block number: %w", err)
+	default:
+		logger.Debugf("last pruned block number is %d", lastPruned)
+		pruner.nextBlockNumberToPrune = lastPruned + 1
+		pruner.initialised = true
+	}
+
+	return pruner, nil
+}
+
+// StoreJournalRecord stores the trie node merkle values inserted and deleted
+// for the given block in the journal database, and then prunes all finalised
+// block numbers falling out of the window of retained blocks.
+func (p *FullNode) StoreJournalRecord(deletedMerkleValues, insertedMerkleValues map[string]struct{},
+	blockHash common.Hash, blockNum int64) (err error) {
+	if blockNum == 0 {
+		// the genesis state trie is never pruned
+		return nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.initialised {
+		// Store the block number before the first journaled block as the last
+		// pruned block number, so pruning resumes from it after a restart.
+		err = p.journalDatabase.Put(lastPrunedKey, encodeBlockNumber(blockNum-1))
+		if err != nil {
+			return fmt.Errorf("storing last pruned block number: %w", err)
+		}
+		p.nextBlockNumberToPrune = blockNum
+		p.initialised = true
+	}
+
+	if blockNum < p.nextBlockNumberToPrune {
+		logger.Debugf("block number %d is already pruned, not storing journal record for block %s",
+			blockNum, blockHash)
+		return nil
+	}
+
+	err = p.storeJournalRecord(deletedMerkleValues, insertedMerkleValues, blockHash, blockNum)
+	if err != nil {
+		return fmt.Errorf("storing journal record for block %s: %w", blockHash, err)
+	}
+
+	if blockNum > p.highestBlockNumber {
+		p.highestBlockNumber = blockNum
+	}
+
+	err = p.prune(p.highestBlockNumber)
+	if err != nil {
+		return fmt.Errorf("pruning: %w", err)
+	}
+
+	return nil
+}
+
+// PruneFinalised prunes all finalised block numbers falling out of the
+// window of retained blocks, once the block with the given number is finalised.
+// Blocks are usually finalised well after being imported, so this is where
+// most of the pruning happens.
+func (p *FullNode) PruneFinalised(finalisedNumber uint) (err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.initialised {
+		// no journal record stored yet
+		return nil
+	}
+
+	highestBlockNumber := p.highestBlockNumber
+	if int64(finalisedNumber) > highestBlockNumber {
+		// no block was imported since the pruner started
+		highestBlockNumber = int64(finalisedNumber)
+	}
+
+	err = p.prune(highestBlockNumber)
+	if err != nil {
+		return fmt.Errorf("pruning: %w", err)
+	}
+
+	return nil
+}
+
+// PinBlockNumber prevents the given block number and the block numbers above
+// it from being pruned, until the unpin function returned is called. Pinning
+// a finalised block number keeps the state tries of all its descendants in the
+// storage database, including the ones on forks. The unpin function can safely
+// be called more than once.
+func (p *FullNode) PinBlockNumber(blockNumber uint) (unpin func()) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.pinned[blockNumber]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+
+			p.pinned[blockNumber]--
+			if p.pinned[blockNumber] == 0 {
+				delete(p.pinned, blockNumber)
+			}
+		})
+	}
+}
+
+func (p *FullNode) storeJournalRecord(deletedMerkleValues, insertedMerkleValues map[string]struct{},
+	blockHash common.Hash, blockNum int64) (err error) {
+	key := journalKey{BlockNumber: blockNum, BlockHash: blockHash}
+	record := journalRecord{
+		InsertedMerkleValues: setToSlice(insertedMerkleValues),
+		DeletedMerkleValues:  setToSlice(deletedMerkleValues),
+	}
+
+	batch := p.journalDatabase.NewBatch()
+
+	encodedRecord, err := scale.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encoding journal record: %w", err)
+	}
+
+	err = batch.Put(journalRecordKey(key), encodedRecord)
+	if err != nil {
+		return fmt.Errorf("putting journal record in batch: %w", err)
+	}
+
+	blockHashes, err := p.getBlockHashes(blockNum)
+	if err != nil {
+		return fmt.Errorf("getting block hashes for block number %d: %w", blockNum, err)
+	}
+
+	if !containsHash(blockHashes, blockHash) {
+		blockHashes = append(blockHashes, blockHash)
+		err = putScaleEncoded(batch, blockHashesKey(blockNum), blockHashes)
+		if err != nil {
+			return fmt.Errorf("putting block hashes for block number %d in batch: %w", blockNum, err)
+		}
+	}
+
+	for _, merkleValue := range record.InsertedMerkleValues {
+		journalKeys, err := p.getInsertedIndex(merkleValue)
+		if err != nil {
+			return fmt.Errorf("getting journal keys for inserted merkle value 0x%x: %w", merkleValue, err)
+		}
+
+		if containsJournalKey(journalKeys, key) {
+			continue
+		}
+
+		journalKeys = append(journalKeys, key)
+		err = putScaleEncoded(batch, insertedIndexKey(merkleValue), journalKeys)
+		if err != nil {
+			return fmt.Errorf("putting journal keys for inserted merkle value 0x%x in batch: %w",
+				merkleValue, err)
+		}
+	}
+
+	err = batch.Flush()
+	if err != nil {
+		return fmt.Errorf("flushing batch: %w", err)
+	}
+
+	logger.Debugf("journal record stored for block %s with number %d", blockHash, blockNum)
+	return nil
+}
+
+// prune prunes all the finalised block numbers falling out of the
+// window of retained blocks, relative to the given block number.
+func (p *FullNode) prune(blockNum int64) (err error) {
+	pruneUpTo := blockNum - int64(p.retainBlocks)
+	if pruneUpTo < p.nextBlockNumberToPrune {
+		return nil
+	}
+
+	finalisedHeader, err := p.blockState.GetHighestFinalisedHeader()
+	if err != nil {
+		return fmt.Errorf("getting highest finalised header: %w", err)
+	}
+
+	finalisedNumber := int64(finalisedHeader.Number)
+	if finalisedNumber < pruneUpTo {
+		// only finalised block numbers can be pruned
+		pruneUpTo = finalisedNumber
+	}
+
+	for pinnedNumber := range p.pinned {
+		if int64(pinnedNumber) <= pruneUpTo {
+			// pinned block numbers are pruned once unpinned
+			pruneUpTo = int64(pinnedNumber) - 1
+		}
+	}
+
+	for ; p.nextBlockNumberToPrune <= pruneUpTo; p.nextBlockNumberToPrune++ {
+		err = p.pruneBlockNumber(p.nextBlockNumberToPrune)
+		if err != nil {
+			return fmt.Errorf("pruning block number %d: %w", p.nextBlockNumberToPrune, err)
+		}
+	}
+
+	return nil
+}
+
+// pruneBlockNumber prunes the trie nodes and journal records for all the
+// blocks at the given block number. The storage database deletions are
+// flushed before the journal database deletions so pruning can safely
+// resume for this block number if the node stops in between.
+func (p *FullNode) pruneBlockNumber(blockNum int64) (err error) {
+	blockHashes, err := p.getBlockHashes(blockNum)
+	if err != nil {
+		return fmt.Errorf("getting block hashes: %w", err)
+	}
+
+	journalBatch := p.journalDatabase.NewBatch()
+
+	if len(blockHashes) > 0 {
+		finalisedHash, err := p.blockState.GetHashByNumber(uint(blockNum))
+		if err != nil {
+			return fmt.Errorf("getting finalised block hash: %w", err)
+		}
+
+		err = p.pruneStorage(blockNum, blockHashes, finalisedHash, journalBatch)
+		if err != nil {
+			return fmt.Errorf("pruning storage: %w", err)
+		}
+	}
+
+	err = journalBatch.Del(blockHashesKey(blockNum))
+	if err != nil {
+		return fmt.Errorf("deleting block hashes from batch: %w", err)
+	}
+
+	err = journalBatch.Put(lastPrunedKey, encodeBlockNumber(blockNum))
+	if err != nil {
+		return fmt.Errorf("putting last pruned block number in batch: %w", err)
+	}
+
+	err = journalBatch.Flush()
+	if err != nil {
+		return fmt.Errorf("flushing journal batch: %w", err)
+	}
+
+	logger.Debugf("pruned block number %d", blockNum)
+	return nil
+}
+
+// pruneStorage deletes from the storage database the trie nodes deleted by
+// the finalised block and the trie nodes inserted by blocks on pruned forks,
+// at the given block number. It also adds the deletions of the journal records
+// for these blocks to the given journal batch.
+func (p *FullNode) pruneStorage(blockNum int64, blockHashes []common.Hash,
+	finalisedHash common.Hash, journalBatch chaindb.Batch) (err error) {
+	// insertedIndex is the in-memory copy of the inserted index entries
+	// modified while pruning this block number.
+	insertedIndex := make(map[string][]journalKey)
+	records := make(map[common.Hash]journalRecord, len(blockHashes))
+
+	for _, blockHash := range blockHashes {
+		key := journalKey{BlockNumber: blockNum, BlockHash: blockHash}
+		record, err := p.getJournalRecord(key)
+		if err != nil {
+			return fmt.Errorf("getting journal record for block %s: %w", blockHash, err)
+		}
+		records[blockHash] = record
+
+		for _, merkleValue := range record.InsertedMerkleValues {
+			journalKeys, ok := insertedIndex[string(merkleValue)]
+			if !ok {
+				journalKeys, err = p.getInsertedIndex(merkleValue)
+				if err != nil {
+					return fmt.Errorf("getting journal keys for inserted merkle value 0x%x: %w",
+						merkleValue, err)
+				}
+			}
+			insertedIndex[string(merkleValue)] = removeJournalKey(journalKeys, key)
+		}
+
+		err = journalBatch.Del(journalRecordKey(key))
+		if err != nil {
+			return fmt.Errorf("deleting journal record from batch: %w", err)
+		}
+	}
+
+	// isInUse returns true if the merkle value given is inserted
+	// by a journaled block not being pruned.
+	isInUse := func(merkleValue []byte) (inUse bool, err error) {
+		journalKeys, ok := insertedIndex[string(merkleValue)]
+		if !ok {
+			journalKeys, err = p.getInsertedIndex(merkleValue)
+			if err != nil {
+				return false, fmt.Errorf("getting journal keys for inserted merkle value 0x%x: %w",
+					merkleValue, err)
+			}
+		}
+		return len(journalKeys) > 0, nil
+	}
+
+	finalisedInserted := make(map[string]struct{})
+	if finalisedRecord, ok := records[finalisedHash]; ok {
+		for _, merkleValue := range finalisedRecord.InsertedMerkleValues {
+			finalisedInserted[string(merkleValue)] = struct{}{}
+		}
+	}
+
+	storageBatch := p.storageDatabase.NewBatch()
+	for blockHash, record := range records {
+		merkleValuesToDelete := record.DeletedMerkleValues
+		if blockHash != finalisedHash {
+			// The block is on a pruned fork, so the nodes it inserted can be deleted.
+			// The nodes it deleted are still in use by the finalised chain.
+			merkleValuesToDelete = record.InsertedMerkleValues
+		}
+
+		for _, merkleValue := range merkleValuesToDelete {
+			if _, ok := finalisedInserted[string(merkleValue)]; ok {
+				continue
+			}
+
+			inUse, err := isInUse(merkleValue)
+			if err != nil {
+				return err
+			} else if inUse {
+				continue
+			}
+
+			err = storageBatch.Del(merkleValue)
+			if err != nil {
+				return fmt.Errorf("deleting merkle value 0x%x from storage batch: %w", merkleValue, err)
+			}
+		}
+	}
+
+	err = storageBatch.Flush()
+	if err != nil {
+		return fmt.Errorf("flushing storage batch: %w", err)
+	}
+
+	for merkleValue, journalKeys := range insertedIndex {
+		key := insertedIndexKey([]byte(merkleValue))
+		if len(journalKeys) == 0 {
+			err = journalBatch.Del(key)
+		} else {
+			err = putScaleEncoded(journalBatch, key, journalKeys)
+		}
+		if err != nil {
+			return fmt.Errorf("updating journal keys for inserted merkle value 0x%x in batch: %w",
+				merkleValue, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *FullNode) getLastPrunedBlockNumber() (blockNum int64, err error) {
+	encoded, err := p.journalDatabase.Get(lastPrunedKey)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(binary.BigEndian.Uint64(encoded)), nil
+}
+
+func (p *FullNode) getJournalRecord(key journalKey) (record journalRecord, err error) {
+	encoded, err := p.journalDatabase.Get(journalRecordKey(key))
+	if err != nil {
+		return record, fmt.Errorf("getting from database: %w", err)
+	}
+
+	err = scale.Unmarshal(encoded, &record)
+	if err != nil {
+		return record, fmt.Errorf("decoding journal record: %w", err)
+	}
+
+	return record, nil
+}
+
+func (p *FullNode) getBlockHashes(blockNum int64) (blockHashes []common.Hash, err error) {
+	encoded, err := p.journalDatabase.Get(blockHashesKey(blockNum))
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("getting from database: %w", err)
+	}
+
+	err = scale.Unmarshal(encoded, &blockHashes)
+	if err != nil {
+		return nil, fmt.Errorf("decoding block hashes: %w", err)
+	}
+
+	return blockHashes, nil
+}
+
+func (p *FullNode) getInsertedIndex(merkleValue []byte) (journalKeys []journalKey, err error) {
+	encoded, err := p.journalDatabase.Get(insertedIndexKey(merkleValue))
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("getting from database: %w", err)
+	}
+
+	err = scale.Unmarshal(encoded, &journalKeys)
+	if err != nil {
+		return nil, fmt.Errorf("decoding journal keys: %w", err)
+	}
+
+	return journalKeys, nil
+}
+
+func putScaleEncoded(putter chaindb.Writer, key []byte, value interface{}) (err error) {
+	encoded, err := scale.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encoding: %w", err)
+	}
+	return putter.Put(key, encoded)
+}
+
+func encodeBlockNumber(blockNum int64) (encoded []byte) {
+	encoded = make([]byte, 8)
+	binary.BigEndian.PutUint64(encoded, uint64(blockNum))
+	return encoded
+}
+
+func journalRecordKey(key journalKey) []byte {
+	return concatBytes(journalRecordPrefix, encodeBlockNumber(key.BlockNumber), key.BlockHash.ToBytes())
+}
+
+func blockHashesKey(blockNum int64) []byte {
+	return concatBytes(blockHashesPrefix, encodeBlockNumber(blockNum))
+}
+
+func insertedIndexKey(merkleValue []byte) []byte {
+	return concatBytes(insertedNodeHashPrefix, merkleValue)
+}
+
+func concatBytes(slices ...[]byte) (result []byte) {
+	for _, slice := range slices {
+		result = append(result, slice...)
+	}
+	return result
+}
+
+func setToSlice(set map[string]struct{}) (slice [][]byte) {
+	slice = make([][]byte, 0, len(set))
+	for key := range set {
+		slice = append(slice, []byte(key))
+	}
+	return slice
+}
+
+func containsHash(hashes []common.Hash, hash common.Hash) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+func containsJournalKey(keys []journalKey, key journalKey) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func removeJournalKey(keys []journalKey, key journalKey) (result []journalKey) {
+	result = make([]journalKey, 0, len(keys))
+	for _, k := range keys {
+		if k != key {
+			result = append(result, k)
+		}
+	}
+	return result
+}
@@ -0,0 +1,249 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package pruner
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDatabase(t *testing.T) *chaindb.BadgerDB {
+	t.Helper()
+	database, err := chaindb.NewBadgerDB(&chaindb.Config{InMemory: true})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := database.Close()
+		assert.NoError(t, err)
+	})
+	return database
+}
+
+func makeSet(keys ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[key] = struct{}{}
+	}
+	return set
+}
+
+func assertStorageKeys(t *testing.T, storageDatabase chaindb.Database,
+	expectedPresent, expectedAbsent []string) {
+	t.Helper()
+	for _, key := range expectedPresent {
+		has, err := storageDatabase.Has([]byte(key))
+		require.NoError(t, err)
+		assert.Truef(t, has, "key %q should be present", key)
+	}
+	for _, key := range expectedAbsent {
+		has, err := storageDatabase.Has([]byte(key))
+		require.NoError(t, err)
+		assert.Falsef(t, has, "key %q should be absent", key)
+	}
+}
+
+func Test_NewFullNode(t *testing.T) {
+	t.Parallel()
+
+	database := newTestDatabase(t)
+	storageDatabase := chaindb.NewTable(database, "storage")
+
+	pruner, err := NewFullNode(database, storageDatabase, 256, nil)
+	require.NoError(t, err)
+	assert.False(t, pruner.initialised)
+
+	err = pruner.journalDatabase.Put(lastPrunedKey, encodeBlockNumber(10))
+	require.NoError(t, err)
+
+	pruner, err = NewFullNode(database, storageDatabase, 256, nil)
+	require.NoError(t, err)
+	assert.True(t, pruner.initialised)
+	assert.Equal(t, int64(11), pruner.nextBlockNumberToPrune)
+}
+
+func Test_FullNode_StoreJournalRecord(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	database := newTestDatabase(t)
+	storageDatabase := chaindb.NewTable(database, "storage")
+	for _, key := range []string{"a", "b", "c", "e"} {
+		err := storageDatabase.Put([]byte(key), []byte{1})
+		require.NoError(t, err)
+	}
+
+	blockState := NewMockBlockState(ctrl)
+
+	const retainBlocks = 1
+	pruner, err := NewFullNode(database, storageDatabase, retainBlocks, blockState)
+	require.NoError(t, err)
+
+	canonicalHash1 := common.Hash{1}
+	forkHash1 := common.Hash{0xf, 1}
+	canonicalHash2 := common.Hash{2}
+	canonicalHash3 := common.Hash{3}
+	canonicalHash4 := common.Hash{4}
+
+	// Genesis block is never journaled nor pruned
+	err = pruner.StoreJournalRecord(makeSet("e"), nil, common.Hash{}, 0)
+	require.NoError(t, err)
+	assert.False(t, pruner.initialised)
+
+	err = pruner.StoreJournalRecord(makeSet("a"), makeSet("b"), canonicalHash1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pruner.nextBlockNumberToPrune)
+
+	err = pruner.StoreJournalRecord(makeSet("a"), makeSet("c"), forkHash1, 1)
+	require.NoError(t, err)
+
+	// Block number 1 is finalised and falls out of the retained window
+	blockState.EXPECT().GetHighestFinalisedHeader().Return(&types.Header{Number: 1}, nil)
+	blockState.EXPECT().GetHashByNumber(uint(1)).Return(canonicalHash1, nil)
+	err = pruner.StoreJournalRecord(makeSet("b"), makeSet("d"), canonicalHash2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pruner.nextBlockNumberToPrune)
+
+	// "a" is deleted by the finalised block and "c" is only inserted by the pruned fork.
+	assertStorageKeys(t, storageDatabase, []string{"b", "e"}, []string{"a", "c"})
+
+	_, err = pruner.journalDatabase.Get(journalRecordKey(journalKey{BlockNumber: 1, BlockHash: forkHash1}))
+	assert.ErrorIs(t, err, chaindb.ErrKeyNotFound)
+	_, err = pruner.journalDatabase.Get(journalRecordKey(journalKey{BlockNumber: 1, BlockHash: canonicalHash1}))
+	assert.ErrorIs(t, err, chaindb.ErrKeyNotFound)
+
+	// Block 3 re-inserts "b" deleted at block 2, so it must not be pruned.
+	blockState.EXPECT().GetHighestFinalisedHeader().Return(&types.Header{Number: 1}, nil)
+	err = pruner.StoreJournalRecord(nil, makeSet("b"), canonicalHash3, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pruner.nextBlockNumberToPrune)
+
+	// Restart the pruner, it should resume from the last pruned block number.
+	pruner, err = NewFullNode(database, storageDatabase, retainBlocks, blockState)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pruner.nextBlockNumberToPrune)
+
+	blockState.EXPECT().GetHighestFinalisedHeader().Return(&types.Header{Number: 3}, nil)
+	blockState.EXPECT().GetHashByNumber(uint(2)).Return(canonicalHash2, nil)
+	blockState.EXPECT().GetHashByNumber(uint(3)).Return(canonicalHash3, nil)
+	err = pruner.StoreJournalRecord(nil, nil, canonicalHash4, 4)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), pruner.nextBlockNumberToPrune)
+
+	assertStorageKeys(t, storageDatabase, []string{"b", "e"}, []string{"a", "c"})
+
+	lastPruned, err := pruner.getLastPrunedBlockNumber()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), lastPruned)
+
+	// Storing a journal record for an already pruned block number is a no-op.
+	err = pruner.StoreJournalRecord(nil, makeSet("x"), common.Hash{0xf, 2}, 2)
+	require.NoError(t, err)
+	blockHashes, err := pruner.getBlockHashes(2)
+	require.NoError(t, err)
+	assert.Empty(t, blockHashes)
+}
+
+func Test_FullNode_PruneFinalised(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	database := newTestDatabase(t)
+	storageDatabase := chaindb.NewTable(database, "storage")
+	for _, key := range []string{"a", "b"} {
+		err := storageDatabase.Put([]byte(key), []byte{1})
+		require.NoError(t, err)
+	}
+
+	blockState := NewMockBlockState(ctrl)
+
+	const retainBlocks = 1
+	pruner, err := NewFullNode(database, storageDatabase, retainBlocks, blockState)
+	require.NoError(t, err)
+
+	// Nothing is journaled yet so there is nothing to prune.
+	err = pruner.PruneFinalised(5)
+	require.NoError(t, err)
+
+	canonicalHash1 := common.Hash{1}
+	canonicalHash2 := common.Hash{2}
+
+	err = pruner.StoreJournalRecord(makeSet("a"), makeSet("b"), canonicalHash1, 1)
+	require.NoError(t, err)
+
+	// No block is finalised yet, so block number 1 cannot be pruned on import.
+	blockState.EXPECT().GetHighestFinalisedHeader().Return(&types.Header{}, nil)
+	err = pruner.StoreJournalRecord(nil, nil, canonicalHash2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pruner.nextBlockNumberToPrune)
+	assertStorageKeys(t, storageDatabase, []string{"a", "b"}, nil)
+
+	// Block number 1 is pruned once block 2 is finalised.
+	blockState.EXPECT().GetHighestFinalisedHeader().Return(&types.Header{Number: 2}, nil)
+	blockState.EXPECT().GetHashByNumber(uint(1)).Return(canonicalHash1, nil)
+	err = pruner.PruneFinalised(2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pruner.nextBlockNumberToPrune)
+	assertStorageKeys(t, storageDatabase, []string{"b"}, []string{"a"})
+}
+
+func Test_FullNode_PinBlockNumber(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	database := newTestDatabase(t)
+	storageDatabase := chaindb.NewTable(database, "storage")
+	for _, key := range []string{"a", "b"} {
+		err := storageDatabase.Put([]byte(key), []byte{1})
+		require.NoError(t, err)
+	}
+
+	blockState := NewMockBlockState(ctrl)
+
+	const retainBlocks = 1
+	pruner, err := NewFullNode(database, storageDatabase, retainBlocks, blockState)
+	require.NoError(t, err)
+
+	canonicalHash1 := common.Hash{1}
+
+	err = pruner.StoreJournalRecord(makeSet("a"), makeSet("b"), canonicalHash1, 1)
+	require.NoError(t, err)
+
+	unpin := pruner.PinBlockNumber(1)
+	secondUnpin := pruner.PinBlockNumber(1)
+
+	// Block number 1 is not pruned while pinned.
+	blockState.EXPECT().GetHighestFinalisedHeader().Return(&types.Header{Number: 2}, nil)
+	err = pruner.PruneFinalised(2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pruner.nextBlockNumberToPrune)
+	assertStorageKeys(t, storageDatabase, []string{"a", "b"}, nil)
+
+	// Unpinning more than once has no effect, and block
+	// number 1 stays pinned by its other pin.
+	unpin()
+	unpin()
+	blockState.EXPECT().GetHighestFinalisedHeader().Return(&types.Header{Number: 2}, nil)
+	err = pruner.PruneFinalised(2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pruner.nextBlockNumberToPrune)
+	assertStorageKeys(t, storageDatabase, []string{"a", "b"}, nil)
+
+	// Block number 1 is pruned once it is no longer pinned.
+	secondUnpin()
+	blockState.EXPECT().GetHighestFinalisedHeader().Return(&types.Header{Number: 2}, nil)
+	blockState.EXPECT().GetHashByNumber(uint(1)).Return(canonicalHash1, nil)
+	err = pruner.PruneFinalised(2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pruner.nextBlockNumberToPrune)
+	assertStorageKeys(t, storageDatabase, []string{"b"}, []string{"a"})
+	assert.Empty(t, pruner.pinned)
+}
@@ -8,6 +8,8 @@
 )
 
 const (
+	// Full pruner mode.
+	Full = Mode("full")
 	// Archive pruner mode.
 	Archive = Mode("archive")
 )
@@ -18,7 +20,7 @@
 // IsValid checks whether the pruning mode is valid
 func (p Mode) IsValid() bool {
 	switch p {
-	case Archive:
+	case Full, Archive:
 		return true
 	default:
 		return false
@@ -35,6 +37,8 @@ type Config struct {
 type Pruner interface {
 	StoreJournalRecord(deletedMerkleValues, insertedMerkleValues map[string]struct{},
 		blockHash common.Hash, blockNum int64) error
+	PruneFinalised(finalisedNumber uint) error
+	PinBlockNumber(blockNumber uint) (unpin func())
 }
 
 // ArchiveNode is a no-op since we don't prune nodes in archive mode.
@@ -45,3 +49,14 @@ func (*ArchiveNode) StoreJournalRecord(_, _ map[string]struct{},
 	_ common.Hash, _ int64) error {
 	return nil
 }
+
+// PruneFinalised for archive node doesn't do anything.
+func (*ArchiveNode) PruneFinalised(_ uint) error {
+	return nil
+}
+
+// PinBlockNumber for archive node doesn't do anything
+// and returns a no-op unpin function.
+func (*ArchiveNode) PinBlockNumber(_ uint) (unpin func()) {
+	return func() {}
+}
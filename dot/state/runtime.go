@@ -0,0 +1,110 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
+	"github.com/ChainSafe/gossamer/lib/runtime/wazero"
+)
+
+var (
+	// ErrWasmInterpreterName is returned when the wasm interpreter name is not known.
+	ErrWasmInterpreterName = errors.New("unknown wasm interpreter name")
+	// ErrPoolSizeNotSupported is returned when a runtime instance pool size is
+	// given for a wasm interpreter which does not support instance pools.
+	ErrPoolSizeNotSupported = errors.New("runtime instance pool is not supported")
+)
+
+// RuntimeConfig is the configuration used to create a runtime instance.
+type RuntimeConfig struct {
+	Storage     *rtstorage.TrieState
+	Keystore    *keystore.GlobalKeystore
+	LogLvl      log.Level
+	Role        common.Roles
+	NodeStorage runtime.NodeStorage
+	Network     runtime.BasicNetwork
+	CodeHash    common.Hash
+	// PoolSize is the number of instances pre-instantiated in the
+	// instance pool of the runtime. It is only supported by the
+	// wasmer interpreter.
+	PoolSize int
+}
+
+// NewRuntime creates a runtime instance running the code given,
+// using the wasm interpreter with the name given.
+func NewRuntime(wasmInterpreter string, code []byte, cfg RuntimeConfig) (instance Runtime, err error) {
+	switch wasmInterpreter {
+	case wasmer.Name:
+		wasmerInstance, err := wasmer.NewInstance(code, wasmer.Config{
+			Storage:     cfg.Storage,
+			Keystore:    cfg.Keystore,
+			LogLvl:      cfg.LogLvl,
+			Role:        cfg.Role,
+			NodeStorage: cfg.NodeStorage,
+			Network:     cfg.Network,
+			CodeHash:    cfg.CodeHash,
+			PoolSize:    cfg.PoolSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return wasmerInstance, nil
+	case wazero.Name:
+		if cfg.PoolSize > 0 {
+			return nil, fmt.Errorf("%w: by wasm interpreter %s", ErrPoolSizeNotSupported, wasmInterpreter)
+		}
+
+		wazeroInstance, err := wazero.NewInstance(code, wazero.Config{
+			Storage:     cfg.Storage,
+			Keystore:    cfg.Keystore,
+			LogLvl:      cfg.LogLvl,
+			Role:        cfg.Role,
+			NodeStorage: cfg.NodeStorage,
+			Network:     cfg.Network,
+			CodeHash:    cfg.CodeHash,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return wazeroInstance, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrWasmInterpreterName, wasmInterpreter)
+	}
+}
+
+// NewRuntimeFromPrevious creates a runtime instance running the code given, using
+// the same wasm interpreter and configuration as the previous runtime given.
+func NewRuntimeFromPrevious(previous Runtime, code []byte, storage *rtstorage.TrieState,
+	codeHash common.Hash) (instance Runtime, err error) {
+	cfg := RuntimeConfig{
+		Storage:     storage,
+		Keystore:    previous.Keystore(),
+		NodeStorage: previous.NodeStorage(),
+		Network:     previous.NetworkService(),
+		CodeHash:    codeHash,
+	}
+
+	if previous.Validator() {
+		cfg.Role = common.AuthorityRole
+	}
+
+	wasmInterpreter := wasmer.Name
+	switch previousInstance := previous.(type) {
+	case *wasmer.Instance:
+		// the new runtime uses the same instance pool size as the previous runtime.
+		cfg.PoolSize = previousInstance.PoolSize()
+	case *wazero.Instance:
+		wasmInterpreter = wazero.Name
+	}
+
+	return NewRuntime(wasmInterpreter, code, cfg)
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
+	"github.com/ChainSafe/gossamer/lib/runtime/wazero"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewRuntime(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		wasmInterpreter string
+		code            []byte
+		cfg             RuntimeConfig
+		errWrapped      error
+		errMessage      string
+	}{
+		"unknown_wasm_interpreter": {
+			wasmInterpreter: "unknown",
+			errWrapped:      ErrWasmInterpreterName,
+			errMessage:      "unknown wasm interpreter name: unknown",
+		},
+		"wazero_pool_size": {
+			wasmInterpreter: wazero.Name,
+			cfg:             RuntimeConfig{PoolSize: 1},
+			errWrapped:      ErrPoolSizeNotSupported,
+			errMessage:      "runtime instance pool is not supported: by wasm interpreter wazero",
+		},
+		"wasmer_invalid_code": {
+			wasmInterpreter: wasmer.Name,
+			code:            []byte{82, 188, 83, 118, 70, 219, 142, 5, 1},
+			errWrapped:      wasmer.ErrWASMDecompress,
+			errMessage:      "setting up VM: wasm decompression failed: unexpected EOF",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			instance, err := NewRuntime(testCase.wasmInterpreter, testCase.code, testCase.cfg)
+
+			assert.Nil(t, instance)
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			assert.EqualError(t, err, testCase.errMessage)
+		})
+	}
+}
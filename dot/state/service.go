@@ -39,6 +39,8 @@ type Service struct {
 	PrunerCfg pruner.Config
 	Telemetry Telemetry
 
+	transactionStoragePeriod uint32
+
 	// Below are for testing only.
 	BabeThresholdNumerator   uint64
 	BabeThresholdDenominator uint64
@@ -51,6 +53,9 @@ type Config struct {
 	PrunerCfg pruner.Config
 	Telemetry Telemetry
 	Metrics   metrics.IntervalConfig
+	// TransactionStoragePeriod is the number of finalised blocks for which
+	// indexed transactions are kept. A zero value keeps them forever.
+	TransactionStoragePeriod uint32
 }
 
 // NewService create a new instance of Service
@@ -67,6 +72,8 @@ func NewService(config Config) *Service {
 		closeCh:   make(chan interface{}),
 		PrunerCfg: config.PrunerCfg,
 		Telemetry: config.Telemetry,
+
+		transactionStoragePeriod: config.TransactionStoragePeriod,
 	}
 }
 
@@ -120,6 +127,7 @@ func (s *Service) Start() (err error) {
 	if err != nil {
 		return fmt.Errorf("failed to create block state: %w", err)
 	}
+	s.Block.transactionStoragePeriod = s.transactionStoragePeriod
 
 	// retrieve latest header
 	bestHeader, err := s.Block.GetHighestFinalisedHeader()
@@ -136,6 +144,14 @@ func (s *Service) Start() (err error) {
 		return fmt.Errorf("failed to create storage state: %w", err)
 	}
 
+	if s.PrunerCfg.Mode == pruner.Full {
+		s.Storage.pruner, err = pruner.NewFullNode(s.db, s.Storage.db, s.PrunerCfg.RetainedBlocks, s.Block)
+		if err != nil {
+			return fmt.Errorf("failed to create full node pruner: %w", err)
+		}
+		s.Block.storagePruner = s.Storage.pruner
+	}
+
 	// load current storage state trie into memory
 	_, err = s.Storage.LoadFromDB(stateRoot)
 	if err != nil {
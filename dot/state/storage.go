@@ -21,6 +21,10 @@
 var storagePrefix = "storage"
 var codeKey = common.CodeKey
 
+// nodeCacheCapacity is the maximum number of trie nodes kept in
+// memory in the cache shared by the tries loaded lazily from the database.
+const nodeCacheCapacity = 100000
+
 // ErrTrieDoesNotExist is returned when attempting to interact with a trie that is not stored in the StorageState
 var ErrTrieDoesNotExist = errors.New("trie with given root does not exist")
 
@@ -32,6 +36,7 @@ func errTrieDoesNotExist(hash common.Hash) error {
 type StorageState struct {
 	blockState *BlockState
 	tries      *Tries
+	nodeCache  *trie.NodeCache
 
 	db GetNewBatcher
 	sync.RWMutex
@@ -51,6 +56,7 @@ func NewStorageState(db *chaindb.BadgerDB, blockState *BlockState,
 	return &StorageState{
 		blockState:   blockState,
 		tries:        tries,
+		nodeCache:    trie.NewNodeCache(nodeCacheCapacity),
 		db:           storageTable,
 		observerList: []Observer{},
 		pruner:       &pruner.ArchiveNode{},
@@ -86,8 +92,18 @@ func (s *StorageState) StoreTrie(ts *rtstorage.TrieState, header *types.Header)
 	return nil
 }
 
+// PinBlockNumber prevents the state tries of the finalised block with the given
+// number and of its descendants from being pruned, until the unpin function
+// returned is called.
+func (s *StorageState) PinBlockNumber(blockNumber uint) (unpin func()) {
+	return s.pruner.PinBlockNumber(blockNumber)
+}
+
 // TrieState returns the TrieState for a given state root.
 // If no state root is provided, it returns the TrieState for the current chain head.
+// The trie is fully loaded from the database if it is not in memory, and is not a
+// lazy trie, since it is modified and used by the runtime which cannot recover from
+// a lazy trie failing to load a node.
 func (s *StorageState) TrieState(root *common.Hash) (*rtstorage.TrieState, error) {
 	if root == nil {
 		sr, err := s.blockState.BestBlockStateRoot()
@@ -104,8 +120,6 @@ func (s *StorageState) TrieState(root *common.Hash) (*rtstorage.TrieState, error
 		if err != nil {
 			return nil, err
 		}
-
-		s.tries.softSet(*root, t)
 	} else if t.MustHash() != *root {
 		panic("trie does not have expected root")
 	}
@@ -129,6 +143,9 @@ func (s *StorageState) LoadFromDB(root common.Hash) (*trie.Trie, error) {
 	return t, nil
 }
 
+// loadTrie returns the trie with the given root, or with the state root of the
+// best block if no root is given, using getTrieForReading. The trie returned
+// must only be read from, and reads must be guarded with trie.CatchLoadNodePanic.
 func (s *StorageState) loadTrie(root *common.Hash) (*trie.Trie, error) {
 	if root == nil {
 		sr, err := s.blockState.BestBlockStateRoot()
@@ -138,12 +155,7 @@ func (s *StorageState) loadTrie(root *common.Hash) (*trie.Trie, error) {
 		root = &sr
 	}
 
-	t := s.tries.get(*root)
-	if t != nil {
-		return t, nil
-	}
-
-	tr, err := s.LoadFromDB(*root)
+	tr, err := s.getTrieForReading(*root)
 	if err != nil {
 		return nil, fmt.Errorf("trie does not exist at root %s: %w", *root, err)
 	}
@@ -160,7 +172,7 @@ func (s *StorageState) ExistsStorage(root *common.Hash, key []byte) (bool, error
 
 // GetStorage gets the object from the trie using the given key and storage hash
 // If no hash is provided, the current chain head is used
-func (s *StorageState) GetStorage(root *common.Hash, key []byte) ([]byte, error) {
+func (s *StorageState) GetStorage(root *common.Hash, key []byte) (value []byte, err error) {
 	if root == nil {
 		sr, err := s.blockState.BestBlockStateRoot()
 		if err != nil {
@@ -171,8 +183,8 @@ func (s *StorageState) GetStorage(root *common.Hash, key []byte) ([]byte, error)
 
 	t := s.tries.get(*root)
 	if t != nil {
-		val := t.Get(key)
-		return val, nil
+		defer trie.CatchLoadNodePanic(&err)
+		return t.Get(key), nil
 	}
 
 	return trie.GetFromDB(s.db, *root, key)
@@ -217,49 +229,91 @@ func (s *StorageState) GetStateRootFromBlock(bhash *common.Hash) (*common.Hash,
 	return &header.StateRoot, nil
 }
 
+// GetChangedKeys returns the keys of the storage values added, modified or
+// deleted between the state tries with the given roots, in lexicographic order.
+// Only the trie nodes differing between the two tries are loaded from the database.
+func (s *StorageState) GetChangedKeys(previousRoot, nextRoot common.Hash) (changedKeys [][]byte, err error) {
+	if previousRoot == nextRoot {
+		return nil, nil
+	}
+
+	previous, err := s.getTrieForReading(previousRoot)
+	if err != nil {
+		return nil, fmt.Errorf("getting previous trie: %w", err)
+	}
+
+	next, err := s.getTrieForReading(nextRoot)
+	if err != nil {
+		return nil, fmt.Errorf("getting next trie: %w", err)
+	}
+
+	changedKeys, err = trie.Diff(previous, next)
+	if err != nil {
+		return nil, fmt.Errorf("comparing tries: %w", err)
+	}
+
+	return changedKeys, nil
+}
+
+// getTrieForReading returns the trie with the given root from the tries in memory,
+// or a trie lazily loaded from the database otherwise. The lazy trie is not cached
+// in the tries in memory, so it must only be read from.
+func (s *StorageState) getTrieForReading(root common.Hash) (*trie.Trie, error) {
+	t := s.tries.get(root)
+	if t != nil {
+		return t, nil
+	}
+
+	return trie.NewLazyTrie(s.db, root, s.nodeCache)
+}
+
 // StorageRoot returns the root hash of the current storage trie
 func (s *StorageState) StorageRoot() (common.Hash, error) {
 	return s.blockState.BestBlockStateRoot()
 }
 
 // Entries returns Entries from the trie with the given state root
-func (s *StorageState) Entries(root *common.Hash) (map[string][]byte, error) {
+func (s *StorageState) Entries(root *common.Hash) (entries map[string][]byte, err error) {
 	tr, err := s.loadTrie(root)
 	if err != nil {
 		return nil, err
 	}
 
+	defer trie.CatchLoadNodePanic(&err)
 	return tr.Entries(), nil
 }
 
 // GetKeysWithPrefix returns all that match the given prefix for the given hash
 // (or best block state root if hash is nil) in lexicographic order
-func (s *StorageState) GetKeysWithPrefix(root *common.Hash, prefix []byte) ([][]byte, error) {
+func (s *StorageState) GetKeysWithPrefix(root *common.Hash, prefix []byte) (keys [][]byte, err error) {
 	tr, err := s.loadTrie(root)
 	if err != nil {
 		return nil, err
 	}
 
+	defer trie.CatchLoadNodePanic(&err)
 	return tr.GetKeysWithPrefix(prefix), nil
 }
 
 // GetStorageChild returns a child trie, if it exists
-func (s *StorageState) GetStorageChild(root *common.Hash, keyToChild []byte) (*trie.Trie, error) {
+func (s *StorageState) GetStorageChild(root *common.Hash, keyToChild []byte) (child *trie.Trie, err error) {
 	tr, err := s.loadTrie(root)
 	if err != nil {
 		return nil, err
 	}
 
+	defer trie.CatchLoadNodePanic(&err)
 	return tr.GetChild(keyToChild)
 }
 
 // GetStorageFromChild get a value from a child trie
-func (s *StorageState) GetStorageFromChild(root *common.Hash, keyToChild, key []byte) ([]byte, error) {
+func (s *StorageState) GetStorageFromChild(root *common.Hash, keyToChild, key []byte) (value []byte, err error) {
 	tr, err := s.loadTrie(root)
 	if err != nil {
 		return nil, err
 	}
 
+	defer trie.CatchLoadNodePanic(&err)
 	return tr.GetFromChild(keyToChild, key)
 }
 
@@ -283,3 +337,10 @@ func (s *StorageState) GenerateTrieProof(stateRoot common.Hash, keys [][]byte) (
 	encodedProofNodes [][]byte, err error) {
 	return proof.Generate(stateRoot[:], keys, s.db)
 }
+
+// GenerateTrieProofWithAbsentKeys returns the proofs related to the keys on
+// the state root trie, where keys absent from the trie are proven absent.
+func (s *StorageState) GenerateTrieProofWithAbsentKeys(stateRoot common.Hash, keys [][]byte) (
+	encodedProofNodes [][]byte, err error) {
+	return proof.GenerateWithAbsentKeys(stateRoot[:], keys, s.db)
+}
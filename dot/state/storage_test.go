@@ -4,6 +4,8 @@
 package state
 
 import (
+	"bytes"
+	"fmt"
 	"testing"
 	"time"
 
@@ -150,6 +152,95 @@ func TestStorage_LoadFromDB(t *testing.T) {
 	require.Equal(t, 4, len(entries))
 }
 
+func TestStorage_GetChangedKeys(t *testing.T) {
+	storage := newTestStorageState(t)
+	ts, err := storage.TrieState(&trie.EmptyHash)
+	require.NoError(t, err)
+
+	ts.Put([]byte("key1"), []byte("value1"))
+	ts.Put([]byte("key2"), []byte("value2"))
+	ts.Put([]byte("key3"), []byte("value3"))
+
+	previousRoot, err := ts.Root()
+	require.NoError(t, err)
+	err = storage.StoreTrie(ts, nil)
+	require.NoError(t, err)
+
+	ts, err = storage.TrieState(&previousRoot)
+	require.NoError(t, err)
+
+	ts.Put([]byte("key1"), []byte("newvalue1"))
+	err = ts.Delete([]byte("key3"))
+	require.NoError(t, err)
+	ts.Put([]byte("key4"), []byte("value4"))
+
+	nextRoot, err := ts.Root()
+	require.NoError(t, err)
+	err = storage.StoreTrie(ts, nil)
+	require.NoError(t, err)
+
+	expectedChangedKeys := [][]byte{[]byte("key1"), []byte("key3"), []byte("key4")}
+
+	changedKeys, err := storage.GetChangedKeys(previousRoot, nextRoot)
+	require.NoError(t, err)
+	require.Equal(t, expectedChangedKeys, changedKeys)
+
+	// compare tries loaded from the database
+	storage.blockState.tries.delete(previousRoot)
+	storage.blockState.tries.delete(nextRoot)
+
+	changedKeys, err = storage.GetChangedKeys(previousRoot, nextRoot)
+	require.NoError(t, err)
+	require.Equal(t, expectedChangedKeys, changedKeys)
+	require.Nil(t, storage.blockState.tries.get(previousRoot))
+
+	changedKeys, err = storage.GetChangedKeys(nextRoot, nextRoot)
+	require.NoError(t, err)
+	require.Empty(t, changedKeys)
+}
+
+func TestStorage_historicalReads(t *testing.T) {
+	storage := newTestStorageState(t)
+	ts, err := storage.TrieState(&trie.EmptyHash)
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		key := []byte(fmt.Sprintf("key%d", i))
+		ts.Put(key, bytes.Repeat([]byte{byte(i)}, 40))
+	}
+
+	root, err := ts.Root()
+	require.NoError(t, err)
+	inserted, _, err := ts.GetChangedNodeHashes()
+	require.NoError(t, err)
+	err = storage.StoreTrie(ts, nil)
+	require.NoError(t, err)
+	storage.blockState.tries.delete(root)
+
+	keys, err := storage.GetKeysWithPrefix(&root, []byte("key"))
+	require.NoError(t, err)
+	require.Len(t, keys, 20)
+	// historical tries read from are not cached
+	require.Nil(t, storage.blockState.tries.get(root))
+
+	// delete the trie nodes except the root node, as pruning does
+	batch := storage.db.NewBatch()
+	for merkleValue := range inserted {
+		if merkleValue != string(root.ToBytes()) {
+			err = batch.Del([]byte(merkleValue))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, batch.Flush())
+	storage.nodeCache = nil
+
+	_, err = storage.GetKeysWithPrefix(&root, []byte("key"))
+	require.ErrorIs(t, err, trie.ErrLoadNode)
+
+	_, err = storage.Entries(&root)
+	require.ErrorIs(t, err, trie.ErrLoadNode)
+}
+
 func TestStorage_StoreTrie_NotSyncing(t *testing.T) {
 	storage := newTestStorageState(t)
 	ts, err := storage.TrieState(&trie.EmptyHash)
@@ -4,6 +4,9 @@
 package state
 
 import (
+	"errors"
+	"math"
+	"sort"
 	"sync"
 	"time"
 
@@ -14,10 +17,41 @@
 	"github.com/ChainSafe/gossamer/lib/transaction"
 )
 
-// TransactionState represents the queue of transactions
+const (
+	// transactionBanDuration is the duration for which extrinsics
+	// removed with RemoveAndBan are not accepted again.
+	transactionBanDuration = 30 * time.Minute
+	// defaultMaxTransactions is the default maximum number
+	// of transactions in the queue and the pool.
+	defaultMaxTransactions = 8192
+	// defaultMaxTransactionsSize is the default maximum total size
+	// in bytes of the transactions in the queue and the pool.
+	defaultMaxTransactionsSize = 20 * 1024 * 1024
+)
+
+// ErrTransactionPoolFull is returned when a transaction is dropped because the
+// queue and the pool are full of transactions with a higher priority.
+var ErrTransactionPoolFull = errors.New("transaction pool is full")
+
+// TransactionState represents the queue of ready transactions, and the
+// pool of future transactions requiring tags not yet provided.
+// Note the tags provided by the transactions included in imported blocks are
+// not tracked. Instead, the transactions of the pool are re-validated against
+// the state of the new best block and pushed again, and the runtime no longer
+// reports the tags provided on chain as required, so they are then moved to the queue.
 type TransactionState struct {
-	queue *transaction.PriorityQueue
-	pool  *transaction.Pool
+	queue   *transaction.PriorityQueue
+	pool    *transaction.Pool
+	banList *transaction.BanList
+
+	// mu protects the moves of transactions between the queue and the
+	// pool, as well as the fields below.
+	mu sync.Mutex
+	// validUntil maps an extrinsic hash to the last block number at which it is valid.
+	validUntil      map[common.Hash]uint
+	bestBlockNumber uint
+	maxTransactions int
+	maxSize         int
 
 	// notifierChannels are used to notify transaction status. It maps a channel to
 	// hex string of the extrinsic it is supposed to notify about.
@@ -32,15 +66,109 @@ func NewTransactionState(telemetry Telemetry) *TransactionState {
 	return &TransactionState{
 		queue:            transaction.NewPriorityQueue(),
 		pool:             transaction.NewPool(),
+		banList:          transaction.NewBanList(transactionBanDuration),
+		validUntil:       make(map[common.Hash]uint),
+		maxTransactions:  defaultMaxTransactions,
+		maxSize:          defaultMaxTransactionsSize,
 		notifierChannels: make(map[chan transaction.Status]string),
 		telemetry:        telemetry,
 	}
 }
 
-// Push pushes a transaction to the queue, ordered by priority
+// Push pushes a transaction to the queue, ordered by priority, if all the tags it requires
+// are provided by transactions of the queue. Otherwise, it is added to the pool as a future
+// transaction. Transactions of the queue providing the same tags are replaced if they have a
+// lower priority, and transactions of the pool are moved to the queue once the tags they
+// require are provided.
 func (s *TransactionState) Push(vt *transaction.ValidTransaction) (common.Hash, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash := vt.Extrinsic.Hash()
+	if !s.isReady(vt) {
+		if s.pool.Get(hash) == nil {
+			s.notifyStatus(vt.Extrinsic, transaction.Future)
+		}
+		s.pool.Insert(vt)
+		s.setValidUntil(hash, vt.Validity.Longevity)
+	} else {
+		err := s.pushReady(vt)
+		if err != nil {
+			return hash, err
+		}
+		s.promote()
+	}
+
+	if s.enforceLimits(hash) {
+		return hash, ErrTransactionPoolFull
+	}
+	return hash, nil
+}
+
+// pushReady moves a transaction to the queue, replacing the transactions
+// of the queue providing the same tags.
+func (s *TransactionState) pushReady(vt *transaction.ValidTransaction) error {
 	s.notifyStatus(vt.Extrinsic, transaction.Ready)
-	return s.queue.Push(vt)
+
+	hash := vt.Extrinsic.Hash()
+	s.pool.Remove(hash)
+	_, replaced, err := s.queue.Replace(vt)
+	if errors.Is(err, transaction.ErrTooLowPriority) {
+		delete(s.validUntil, hash)
+		s.notifyStatus(vt.Extrinsic, transaction.Dropped)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, replacedTx := range replaced {
+		delete(s.validUntil, replacedTx.Extrinsic.Hash())
+		s.notifyStatus(replacedTx.Extrinsic, transaction.Usurped)
+	}
+
+	s.setValidUntil(hash, vt.Validity.Longevity)
+	return nil
+}
+
+// promote moves the transactions of the pool requiring tags all provided
+// by transactions of the queue to the queue, until none is left.
+func (s *TransactionState) promote() {
+	for promoted := true; promoted; {
+		promoted = false
+		for _, tx := range s.pool.Transactions() {
+			if !s.isReady(tx) {
+				continue
+			}
+
+			err := s.pushReady(tx)
+			if err != nil {
+				logger.Debugf("failed to move transaction %s to queue: %s", tx.Extrinsic.Hash(), err)
+				continue
+			}
+			promoted = true
+		}
+	}
+}
+
+// isReady returns true if all the tags required by the
+// transaction are provided by transactions of the queue.
+func (s *TransactionState) isReady(vt *transaction.ValidTransaction) bool {
+	for _, tag := range vt.Validity.Requires {
+		if !s.queue.IsProvided(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// setValidUntil records the last block number at which the transaction is
+// valid, given its longevity from the current best block number.
+func (s *TransactionState) setValidUntil(hash common.Hash, longevity uint64) {
+	validUntil := uint(math.MaxUint)
+	if longevity < uint64(validUntil-s.bestBlockNumber) {
+		validUntil = s.bestBlockNumber + uint(longevity)
+	}
+	s.validUntil[hash] = validUntil
 }
 
 // Pop removes and returns the head of the queue
@@ -77,8 +205,17 @@ func (s *TransactionState) Exists(ext types.Extrinsic) bool {
 
 // RemoveExtrinsic removes an extrinsic from the queue and pool
 func (s *TransactionState) RemoveExtrinsic(ext types.Extrinsic) {
-	s.pool.Remove(ext.Hash())
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExtrinsic(ext)
+}
+
+func (s *TransactionState) removeExtrinsic(ext types.Extrinsic) {
+	hash := ext.Hash()
+	s.pool.Remove(hash)
 	s.queue.RemoveExtrinsic(ext)
+	delete(s.validUntil, hash)
 }
 
 // RemoveExtrinsicFromPool removes an extrinsic from the pool
@@ -86,11 +223,178 @@ func (s *TransactionState) RemoveExtrinsicFromPool(ext types.Extrinsic) {
 	s.pool.Remove(ext.Hash())
 }
 
-// AddToPool adds a transaction to the pool
+// IsBanned returns true if the extrinsic hash is temporarily banned, false otherwise
+func (s *TransactionState) IsBanned(hash common.Hash) bool {
+	return s.banList.IsBanned(hash)
+}
+
+// RemoveAndBan removes the transactions with the given extrinsic hashes from the queue
+// and the pool, together with the transactions requiring any of the tags they provide.
+// The given hashes and the hashes of all the removed transactions are then temporarily
+// banned. It returns the hashes of the removed transactions.
+func (s *TransactionState) RemoveAndBan(hashes []common.Hash) (removed []common.Hash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.banList.Ban(hashes...)
+
+	index := newPendingIndex(s.Pending())
+	for _, tx := range s.removeWithDependents(index, hashes, transaction.Invalid) {
+		removed = append(removed, tx.Extrinsic.Hash())
+	}
+
+	s.banList.Ban(removed...)
+	return removed
+}
+
+// RemoveExpired sets the best block number used to compute until when transactions are valid,
+// and removes the transactions of the queue and the pool no longer valid at this block number,
+// together with the transactions requiring any of the tags they provide.
+// It returns the hashes of the removed transactions.
+func (s *TransactionState) RemoveExpired(bestBlockNumber uint) (removed []common.Hash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bestBlockNumber = bestBlockNumber
+
+	var expired []common.Hash
+	for hash, validUntil := range s.validUntil {
+		if s.pool.Get(hash) == nil && !s.queue.Exists(hash) {
+			// popped from the queue
+			delete(s.validUntil, hash)
+			continue
+		}
+
+		if validUntil < bestBlockNumber {
+			expired = append(expired, hash)
+		}
+	}
+
+	if len(expired) == 0 {
+		return nil
+	}
+
+	index := newPendingIndex(s.Pending())
+	for _, tx := range s.removeWithDependents(index, expired, transaction.Invalid) {
+		removed = append(removed, tx.Extrinsic.Hash())
+	}
+	return removed
+}
+
+// pendingIndex indexes the transactions of the queue and the pool by extrinsic
+// hash, and by tag required, to find the transactions depending on a transaction.
+type pendingIndex struct {
+	transactions map[common.Hash]*transaction.ValidTransaction
+	// requiredBy maps a tag to the extrinsic hashes of the transactions requiring it.
+	requiredBy map[string][]common.Hash
+}
+
+// newPendingIndex indexes the given transactions of the queue and the pool.
+func newPendingIndex(pending []*transaction.ValidTransaction) *pendingIndex {
+	index := &pendingIndex{
+		transactions: make(map[common.Hash]*transaction.ValidTransaction, len(pending)),
+		requiredBy:   make(map[string][]common.Hash),
+	}
+
+	for _, tx := range pending {
+		hash := tx.Extrinsic.Hash()
+		index.transactions[hash] = tx
+		if tx.Validity == nil {
+			continue
+		}
+		for _, tag := range tx.Validity.Requires {
+			index.requiredBy[string(tag)] = append(index.requiredBy[string(tag)], hash)
+		}
+	}
+
+	return index
+}
+
+// removeWithDependents removes the transactions with the given extrinsic hashes from the
+// queue and the pool, together with the transactions requiring any of the tags they provide,
+// and notifies them with the given status. The index given is updated and can be reused
+// for subsequent calls. It returns the removed transactions.
+func (s *TransactionState) removeWithDependents(index *pendingIndex, hashes []common.Hash,
+	status transaction.Status) (removed []*transaction.ValidTransaction) {
+	toRemove := make([]common.Hash, len(hashes))
+	copy(toRemove, hashes)
+	for len(toRemove) > 0 {
+		hash := toRemove[0]
+		toRemove = toRemove[1:]
+
+		tx, ok := index.transactions[hash]
+		if !ok {
+			// already removed
+			continue
+		}
+		delete(index.transactions, hash)
+
+		s.removeExtrinsic(tx.Extrinsic)
+		s.notifyStatus(tx.Extrinsic, status)
+		removed = append(removed, tx)
+
+		if tx.Validity == nil {
+			continue
+		}
+
+		for _, tag := range tx.Validity.Provides {
+			toRemove = append(toRemove, index.requiredBy[string(tag)]...)
+		}
+	}
+
+	return removed
+}
+
+// enforceLimits drops the transactions with the lowest priority from the queue and the pool,
+// together with the transactions requiring any of the tags they provide, until the number and
+// total size of the transactions are within the limits. It returns true if the transaction
+// with the given extrinsic hash was dropped, false otherwise.
+func (s *TransactionState) enforceLimits(hash common.Hash) (dropped bool) {
+	pending := s.Pending()
+	count, size := len(pending), 0
+	for _, tx := range pending {
+		size += len(tx.Extrinsic)
+	}
+
+	if count <= s.maxTransactions && size <= s.maxSize {
+		return false
+	}
+
+	index := newPendingIndex(pending)
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].Validity.Priority < pending[j].Validity.Priority
+	})
+
+	for _, tx := range pending {
+		if count <= s.maxTransactions && size <= s.maxSize {
+			break
+		}
+
+		removed := s.removeWithDependents(index, []common.Hash{tx.Extrinsic.Hash()}, transaction.Dropped)
+		for _, removedTx := range removed {
+			count--
+			size -= len(removedTx.Extrinsic)
+			if removedTx.Extrinsic.Hash() == hash {
+				dropped = true
+			}
+		}
+	}
+
+	return dropped
+}
+
+// AddToPool adds a transaction to the pool. If the queue and the pool are full, the
+// transactions with the lowest priority are dropped, possibly including the transaction.
 func (s *TransactionState) AddToPool(vt *transaction.ValidTransaction) common.Hash {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.notifyStatus(vt.Extrinsic, transaction.Future)
 
 	hash := s.pool.Insert(vt)
+	s.setValidUntil(hash, vt.Validity.Longevity)
+	s.enforceLimits(hash)
 
 	s.telemetry.SendMessage(
 		telemetry.NewTxpoolImport(uint(s.queue.Len()), uint(s.pool.Len())),
@@ -4,6 +4,7 @@
 package state
 
 import (
+	"math"
 	"math/rand"
 	"sort"
 	"testing"
@@ -99,7 +100,7 @@ func TestTransactionState_NotifierChannels(t *testing.T) {
 	for i := 0; i < expectedFutureCount; i++ {
 		dummyTransactions[i] = &transaction.ValidTransaction{
 			Extrinsic: ext,
-			Validity:  transaction.NewValidity(0, [][]byte{{}}, [][]byte{{}}, 0, false),
+			Validity:  transaction.NewValidity(0, nil, [][]byte{{}}, 0, false),
 		}
 
 		ts.AddToPool(dummyTransactions[i])
@@ -123,3 +124,219 @@ func TestTransactionState_NotifierChannels(t *testing.T) {
 	require.Equal(t, expectedFutureCount, futureCount)
 	require.Equal(t, expectedReadyCount, readyCount)
 }
+
+func TestTransactionState_RemoveAndBan(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	telemetryMock := NewMockTelemetry(ctrl)
+	telemetryMock.EXPECT().SendMessage(gomock.Any()).AnyTimes()
+
+	ts := NewTransactionState(telemetryMock)
+
+	parent := transaction.NewValidTransaction(types.Extrinsic{1},
+		&transaction.Validity{Priority: 1, Provides: [][]byte{{1}}})
+	child := transaction.NewValidTransaction(types.Extrinsic{2},
+		&transaction.Validity{Priority: 1, Requires: [][]byte{{1}}, Provides: [][]byte{{2}}})
+	grandChild := transaction.NewValidTransaction(types.Extrinsic{3},
+		&transaction.Validity{Requires: [][]byte{{2}}})
+	unrelated := transaction.NewValidTransaction(types.Extrinsic{4},
+		&transaction.Validity{Requires: [][]byte{{3}}, Provides: [][]byte{{4}}})
+
+	_, err := ts.Push(parent)
+	require.NoError(t, err)
+	_, err = ts.Push(child)
+	require.NoError(t, err)
+	ts.AddToPool(grandChild)
+	ts.AddToPool(unrelated)
+
+	unknownHash := common.Hash{1}
+	removed := ts.RemoveAndBan([]common.Hash{parent.Extrinsic.Hash(), unknownHash})
+
+	expectedRemoved := []common.Hash{
+		parent.Extrinsic.Hash(),
+		child.Extrinsic.Hash(),
+		grandChild.Extrinsic.Hash(),
+	}
+	require.Equal(t, expectedRemoved, removed)
+	require.Equal(t, []*transaction.ValidTransaction{unrelated}, ts.Pending())
+
+	for _, hash := range append(expectedRemoved, unknownHash) {
+		require.True(t, ts.IsBanned(hash))
+	}
+	require.False(t, ts.IsBanned(unrelated.Extrinsic.Hash()))
+}
+
+func TestTransactionState_Push(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	telemetryMock := NewMockTelemetry(ctrl)
+	telemetryMock.EXPECT().SendMessage(gomock.Any()).AnyTimes()
+
+	ts := NewTransactionState(telemetryMock)
+
+	nonce0 := transaction.NewValidTransaction(types.Extrinsic{0},
+		&transaction.Validity{Priority: 1, Provides: [][]byte{{0}}})
+	nonce1 := transaction.NewValidTransaction(types.Extrinsic{1},
+		&transaction.Validity{Priority: 2, Requires: [][]byte{{0}}, Provides: [][]byte{{1}}})
+	nonce2 := transaction.NewValidTransaction(types.Extrinsic{2},
+		&transaction.Validity{Priority: 3, Requires: [][]byte{{1}}, Provides: [][]byte{{2}}})
+
+	_, err := ts.Push(nonce2)
+	require.NoError(t, err)
+	_, err = ts.Push(nonce1)
+	require.NoError(t, err)
+
+	pendingInPool := ts.PendingInPool()
+	sort.Slice(pendingInPool, func(i, j int) bool {
+		return pendingInPool[i].Extrinsic[0] < pendingInPool[j].Extrinsic[0]
+	})
+	require.Equal(t, []*transaction.ValidTransaction{nonce1, nonce2}, pendingInPool)
+	require.Nil(t, ts.Peek())
+
+	// pushing the first transaction promotes the future transactions
+	_, err = ts.Push(nonce0)
+	require.NoError(t, err)
+	require.Empty(t, ts.PendingInPool())
+
+	for _, expected := range []*transaction.ValidTransaction{nonce0, nonce1, nonce2} {
+		require.Equal(t, expected, ts.Pop())
+	}
+	require.Nil(t, ts.Pop())
+}
+
+func TestTransactionState_Push_Replace(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	telemetryMock := NewMockTelemetry(ctrl)
+	telemetryMock.EXPECT().SendMessage(gomock.Any()).AnyTimes()
+
+	ts := NewTransactionState(telemetryMock)
+
+	low := transaction.NewValidTransaction(types.Extrinsic{1},
+		&transaction.Validity{Priority: 1, Provides: [][]byte{{0}}})
+	high := transaction.NewValidTransaction(types.Extrinsic{2},
+		&transaction.Validity{Priority: 2, Provides: [][]byte{{0}}})
+
+	lowStatus := ts.GetStatusNotifierChannel(low.Extrinsic)
+	defer ts.FreeStatusNotifierChannel(lowStatus)
+
+	_, err := ts.Push(low)
+	require.NoError(t, err)
+	_, err = ts.Push(high)
+	require.NoError(t, err)
+	require.Equal(t, []*transaction.ValidTransaction{high}, ts.Pending())
+	require.Equal(t, transaction.Ready, <-lowStatus)
+	require.Equal(t, transaction.Usurped, <-lowStatus)
+
+	_, err = ts.Push(low)
+	require.ErrorIs(t, err, transaction.ErrTooLowPriority)
+	require.Equal(t, []*transaction.ValidTransaction{high}, ts.Pending())
+}
+
+func TestTransactionState_RemoveExpired(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	telemetryMock := NewMockTelemetry(ctrl)
+	telemetryMock.EXPECT().SendMessage(gomock.Any()).AnyTimes()
+
+	ts := NewTransactionState(telemetryMock)
+	ts.RemoveExpired(10)
+
+	mortal := transaction.NewValidTransaction(types.Extrinsic{1},
+		&transaction.Validity{Priority: 1, Provides: [][]byte{{1}}, Longevity: 2})
+	dependent := transaction.NewValidTransaction(types.Extrinsic{2},
+		&transaction.Validity{Priority: 1, Requires: [][]byte{{1}}, Longevity: math.MaxUint64})
+	immortal := transaction.NewValidTransaction(types.Extrinsic{3},
+		&transaction.Validity{Priority: 1, Longevity: math.MaxUint64})
+
+	_, err := ts.Push(mortal)
+	require.NoError(t, err)
+	_, err = ts.Push(dependent)
+	require.NoError(t, err)
+	ts.AddToPool(immortal)
+
+	removed := ts.RemoveExpired(12)
+	require.Empty(t, removed)
+	require.Len(t, ts.Pending(), 3)
+
+	removed = ts.RemoveExpired(13)
+	expectedRemoved := []common.Hash{mortal.Extrinsic.Hash(), dependent.Extrinsic.Hash()}
+	require.Equal(t, expectedRemoved, removed)
+	require.Equal(t, []*transaction.ValidTransaction{immortal}, ts.Pending())
+}
+
+func TestTransactionState_Limits(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	telemetryMock := NewMockTelemetry(ctrl)
+	telemetryMock.EXPECT().SendMessage(gomock.Any()).AnyTimes()
+
+	ts := NewTransactionState(telemetryMock)
+	ts.maxTransactions = 2
+	ts.maxSize = 4
+
+	medium := transaction.NewValidTransaction(types.Extrinsic{1},
+		&transaction.Validity{Priority: 2})
+	low := transaction.NewValidTransaction(types.Extrinsic{2},
+		&transaction.Validity{Priority: 1, Provides: [][]byte{{1}}})
+	lowDependent := transaction.NewValidTransaction(types.Extrinsic{3},
+		&transaction.Validity{Priority: 4, Requires: [][]byte{{1}}})
+	high := transaction.NewValidTransaction(types.Extrinsic{4},
+		&transaction.Validity{Priority: 3})
+	lowest := transaction.NewValidTransaction(types.Extrinsic{5},
+		&transaction.Validity{Priority: 0})
+	large := transaction.NewValidTransaction(types.Extrinsic{6, 6, 6, 6},
+		&transaction.Validity{Priority: 5})
+
+	_, err := ts.Push(medium)
+	require.NoError(t, err)
+	_, err = ts.Push(low)
+	require.NoError(t, err)
+
+	// the lowest priority transaction is dropped with its dependent transaction
+	_, err = ts.Push(lowDependent)
+	require.ErrorIs(t, err, ErrTransactionPoolFull)
+	require.Equal(t, []*transaction.ValidTransaction{medium}, ts.Pending())
+
+	_, err = ts.Push(high)
+	require.NoError(t, err)
+
+	_, err = ts.Push(lowest)
+	require.ErrorIs(t, err, ErrTransactionPoolFull)
+	require.False(t, ts.Exists(lowest.Extrinsic))
+
+	// the size limit drops all the other transactions
+	_, err = ts.Push(large)
+	require.NoError(t, err)
+	require.Equal(t, []*transaction.ValidTransaction{large}, ts.Pending())
+}
+
+func Test_newPendingIndex(t *testing.T) {
+	t.Parallel()
+
+	first := transaction.NewValidTransaction(types.Extrinsic{1},
+		&transaction.Validity{Requires: [][]byte{{1}, {2}}})
+	second := transaction.NewValidTransaction(types.Extrinsic{2},
+		&transaction.Validity{Requires: [][]byte{{2}}})
+	noValidity := transaction.NewValidTransaction(types.Extrinsic{3}, nil)
+
+	index := newPendingIndex([]*transaction.ValidTransaction{first, second, noValidity})
+
+	expectedIndex := &pendingIndex{
+		transactions: map[common.Hash]*transaction.ValidTransaction{
+			first.Extrinsic.Hash():      first,
+			second.Extrinsic.Hash():     second,
+			noValidity.Extrinsic.Hash(): noValidity,
+		},
+		requiredBy: map[string][]common.Hash{
+			string([]byte{1}): {first.Extrinsic.Hash()},
+			string([]byte{2}): {first.Extrinsic.Hash(), second.Extrinsic.Hash()},
+		},
+	}
+	require.Equal(t, expectedIndex, index)
+}
@@ -12,12 +12,14 @@
 	"github.com/ChainSafe/gossamer/dot/telemetry"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/blocktree"
+	"github.com/ChainSafe/gossamer/lib/runtime"
 )
 
 // ChainProcessor processes ready blocks.
 // it is implemented by *chainProcessor
 type ChainProcessor interface {
 	processReadyBlocks()
+	processBlockData(blockData types.BlockData) error
 	stop()
 }
 
@@ -258,9 +260,14 @@ func (s *chainProcessor) handleBlock(block *types.Block, announceImportedBlock b
 		return err
 	}
 
-	rt.SetContextStorage(ts)
+	instance, release, err := runtime.AcquireInstance(rt)
+	if err != nil {
+		return err
+	}
 
-	_, err = rt.ExecuteBlock(block)
+	instance.SetContextStorage(ts)
+	_, err = instance.ExecuteBlock(block)
+	release()
 	if err != nil {
 		return fmt.Errorf("failed to execute block %d: %w", block.Header.Number, err)
 	}
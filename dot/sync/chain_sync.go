@@ -155,6 +155,10 @@ type chainSync struct {
 
 	benchmarker *syncBenchmarker
 
+	// warpSyncer and stateSyncer are set if the node warp syncs before syncing blocks
+	warpSyncer  *warpSyncer
+	stateSyncer *stateSyncer
+
 	finalisedCh <-chan *types.FinalisationInfo
 
 	minPeers         int
@@ -174,6 +178,8 @@ type chainSyncConfig struct {
 	pendingBlocks      DisjointBlockSet
 	minPeers, maxPeers int
 	slotDuration       time.Duration
+	warpSyncer         *warpSyncer
+	stateSyncer        *stateSyncer
 }
 
 func newChainSync(cfg chainSyncConfig) *chainSync {
@@ -197,6 +203,8 @@ func newChainSync(cfg chainSyncConfig) *chainSync {
 		state:            bootstrap,
 		handler:          newBootstrapSyncer(cfg.bs),
 		benchmarker:      newSyncBenchmarker(syncSamplesToKeep),
+		warpSyncer:       cfg.warpSyncer,
+		stateSyncer:      cfg.stateSyncer,
 		finalisedCh:      cfg.bs.GetFinalisedNotifierChannel(),
 		minPeers:         cfg.minPeers,
 		maxWorkerRetries: uint16(cfg.maxPeers),
@@ -219,6 +227,10 @@ func (cs *chainSync) start() {
 		time.Sleep(time.Millisecond * 100)
 	}
 
+	if cs.warpSyncer != nil {
+		cs.warpSync()
+	}
+
 	isSyncedGauge.Set(float64(cs.state))
 
 	pendingBlockDoneCh := make(chan struct{})
@@ -229,6 +241,47 @@ func (cs *chainSync) start() {
 	go cs.logSyncSpeed()
 }
 
+// warpSync verifies the GRANDPA finality proofs from our highest
+// finalised block up to the highest block finalised by our peers,
+// and downloads the state of that block.
+func (cs *chainSync) warpSync() {
+	cs.RLock()
+	peers := make([]peer.ID, 0, len(cs.peerState))
+	for who := range cs.peerState {
+		peers = append(peers, who)
+	}
+	cs.RUnlock()
+
+	result, err := cs.warpSyncer.sync(peers)
+	if err != nil {
+		logger.Warnf("warp sync failed, falling back to full sync: %s", err)
+		return
+	}
+
+	if result == nil {
+		logger.Info("no finalised block to warp sync to, using full sync")
+		return
+	}
+
+	state, err := cs.stateSyncer.sync(peers, &result.Header)
+	if err != nil {
+		logger.Warnf("failed to download state of block #%d (%s), falling back to full sync: %s",
+			result.Header.Number, result.Header.Hash(), err)
+		return
+	}
+
+	err = cs.warpSyncer.setSyncedBlock(result, state)
+	if err != nil {
+		logger.Warnf("failed to set warp synced block #%d (%s), falling back to full sync: %s",
+			result.Header.Number, result.Header.Hash(), err)
+		return
+	}
+
+	logger.Infof("warp synced to finalised block #%d (%s), syncing blocks from it",
+		result.Header.Number, result.Header.Hash())
+	cs.setMode(tip)
+}
+
 func (cs *chainSync) stop() {
 	if cs.pendingBlockDoneCh != nil {
 		close(cs.pendingBlockDoneCh)
@@ -34,4 +34,10 @@
 	errFailedToGetParent            = errors.New("failed to get parent header")
 	errStartAndEndMismatch          = errors.New("request start and end hash are not on the same chain")
 	errFailedToGetDescendant        = errors.New("failed to find descendant block")
+
+	// stateSyncer errors
+	errEmptyStateResponse   = errors.New("state response has no entries")
+	errInvalidStateResponse = errors.New("invalid state response")
+	errStateRootMismatch    = errors.New("state root does not match block state root")
+	errStateSyncIncomplete  = errors.New("state download is incomplete")
 )
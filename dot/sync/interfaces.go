@@ -12,6 +12,7 @@
 	"github.com/ChainSafe/gossamer/dot/state"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/grandpa"
 	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 	"github.com/libp2p/go-libp2p/core/peer"
 )
@@ -41,11 +42,13 @@ type BlockState interface {
 	GetHeaderByNumber(num uint) (*types.Header, error)
 	GetAllBlocksAtNumber(num uint) ([]common.Hash, error)
 	IsDescendantOf(parent, child common.Hash) (bool, error)
+	SetWarpSyncedBlock(header *types.Header, state *rtstorage.TrieState, setID uint64) error
 }
 
 // StorageState is the interface for the storage state
 type StorageState interface {
 	TrieState(root *common.Hash) (*rtstorage.TrieState, error)
+	StoreTrie(ts *rtstorage.TrieState, header *types.Header) error
 	sync.Locker
 }
 
@@ -64,6 +67,19 @@ type FinalityGadget interface {
 	VerifyBlockJustification(common.Hash, []byte) ([]byte, error)
 }
 
+// GrandpaState is the interface for the grandpa state
+type GrandpaState interface {
+	GetCurrentSetID() (uint64, error)
+	GetAuthorities(setID uint64) ([]types.GrandpaVoter, error)
+	SetCurrentAuthoritySet(setID uint64, authorities []types.GrandpaVoter, number uint) error
+}
+
+// WarpSyncProofVerifier verifies warp sync proofs
+type WarpSyncProofVerifier interface {
+	Verify(encodedProof []byte, setID uint64, authorities []types.GrandpaVoter) (
+		*grandpa.WarpSyncVerificationResult, error)
+}
+
 // BlockImportHandler is the interface for the handler of newly imported blocks
 type BlockImportHandler interface {
 	HandleBlockImport(block *types.Block, state *rtstorage.TrieState, announce bool) error
@@ -76,6 +92,14 @@ type Network interface {
 	// it is returned, otherwise an error is returned.
 	DoBlockRequest(to peer.ID, req *network.BlockRequestMessage) (*network.BlockResponseMessage, error)
 
+	// DoWarpSyncRequest sends a warp sync proof request to the given peer
+	// and returns the SCALE encoded warp sync proof received in response.
+	DoWarpSyncRequest(to peer.ID, req *network.WarpSyncProofRequest) (encodedProof []byte, err error)
+
+	// DoStateRequest sends a state request to the given peer
+	// and returns the state response received.
+	DoStateRequest(to peer.ID, req *network.StateRequest) (*network.StateResponse, error)
+
 	// Peers returns a list of currently connected peers
 	Peers() []common.PeerInfo
 
@@ -7,6 +7,7 @@
 import (
 	reflect "reflect"
 
+	types "github.com/ChainSafe/gossamer/dot/types"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -33,6 +34,20 @@ func (m *MockChainProcessor) EXPECT() *MockChainProcessorMockRecorder {
 	return m.recorder
 }
 
+// processBlockData mocks base method.
+func (m *MockChainProcessor) processBlockData(arg0 types.BlockData) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "processBlockData", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// processBlockData indicates an expected call of processBlockData.
+func (mr *MockChainProcessorMockRecorder) processBlockData(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "processBlockData", reflect.TypeOf((*MockChainProcessor)(nil).processBlockData), arg0)
+}
+
 // processReadyBlocks mocks base method.
 func (m *MockChainProcessor) processReadyBlocks() {
 	m.ctrl.T.Helper()
@@ -366,6 +366,20 @@ func (mr *MockBlockStateMockRecorder) SetJustification(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetJustification", reflect.TypeOf((*MockBlockState)(nil).SetJustification), arg0, arg1)
 }
 
+// SetWarpSyncedBlock mocks base method.
+func (m *MockBlockState) SetWarpSyncedBlock(arg0 *types.Header, arg1 *storage.TrieState, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWarpSyncedBlock", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWarpSyncedBlock indicates an expected call of SetWarpSyncedBlock.
+func (mr *MockBlockStateMockRecorder) SetWarpSyncedBlock(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWarpSyncedBlock", reflect.TypeOf((*MockBlockState)(nil).SetWarpSyncedBlock), arg0, arg1, arg2)
+}
+
 // StoreRuntime mocks base method.
 func (m *MockBlockState) StoreRuntime(arg0 common.Hash, arg1 state.Runtime) {
 	m.ctrl.T.Helper()
@@ -413,6 +427,20 @@ func (mr *MockStorageStateMockRecorder) Lock() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockStorageState)(nil).Lock))
 }
 
+// StoreTrie mocks base method.
+func (m *MockStorageState) StoreTrie(arg0 *storage.TrieState, arg1 *types.Header) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreTrie", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreTrie indicates an expected call of StoreTrie.
+func (mr *MockStorageStateMockRecorder) StoreTrie(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreTrie", reflect.TypeOf((*MockStorageState)(nil).StoreTrie), arg0, arg1)
+}
+
 // TrieState mocks base method.
 func (m *MockStorageState) TrieState(arg0 *common.Hash) (*storage.TrieState, error) {
 	m.ctrl.T.Helper()
@@ -625,6 +653,36 @@ func (mr *MockNetworkMockRecorder) DoBlockRequest(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoBlockRequest", reflect.TypeOf((*MockNetwork)(nil).DoBlockRequest), arg0, arg1)
 }
 
+// DoStateRequest mocks base method.
+func (m *MockNetwork) DoStateRequest(arg0 peer.ID, arg1 *network.StateRequest) (*network.StateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoStateRequest", arg0, arg1)
+	ret0, _ := ret[0].(*network.StateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoStateRequest indicates an expected call of DoStateRequest.
+func (mr *MockNetworkMockRecorder) DoStateRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoStateRequest", reflect.TypeOf((*MockNetwork)(nil).DoStateRequest), arg0, arg1)
+}
+
+// DoWarpSyncRequest mocks base method.
+func (m *MockNetwork) DoWarpSyncRequest(arg0 peer.ID, arg1 *network.WarpSyncProofRequest) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoWarpSyncRequest", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoWarpSyncRequest indicates an expected call of DoWarpSyncRequest.
+func (mr *MockNetworkMockRecorder) DoWarpSyncRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoWarpSyncRequest", reflect.TypeOf((*MockNetwork)(nil).DoWarpSyncRequest), arg0, arg1)
+}
+
 // Peers mocks base method.
 func (m *MockNetwork) Peers() []common.PeerInfo {
 	m.ctrl.T.Helper()
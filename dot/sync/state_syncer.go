@@ -0,0 +1,326 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/peerset"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/lib/trie/proof"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// stateSyncer downloads the state of a block from peers using the
+// state request protocol, and verifies it against the block state root.
+type stateSyncer struct {
+	network      Network
+	storageState StorageState
+	// getRuntimeVersion returns the version of the runtime code given,
+	// and is used to find the state trie version of the state downloaded.
+	getRuntimeVersion func(code []byte) (version runtime.Version, err error)
+}
+
+func newStateSyncer(network Network, storageState StorageState) *stateSyncer {
+	return &stateSyncer{
+		network:           network,
+		storageState:      storageState,
+		getRuntimeVersion: wasmer.GetRuntimeVersion,
+	}
+}
+
+// stateDownload holds the state entries downloaded so far.
+type stateDownload struct {
+	top *trie.Trie
+	// children maps child storage keys to their child trie.
+	children map[string]*trie.Trie
+	// start is the start of the next state request.
+	start [][]byte
+}
+
+// sync downloads the state of the given header from the given peers, one peer
+// at a time, verifies it and stores it in the storage state. Peers sending
+// invalid responses are reported and the download continues with the next peer.
+func (s *stateSyncer) sync(peers []peer.ID, header *types.Header) (state *trie.Trie, err error) {
+	if len(peers) == 0 {
+		return nil, errNoPeers
+	}
+
+	download := &stateDownload{
+		top:      trie.NewEmptyTrie(),
+		children: make(map[string]*trie.Trie),
+	}
+
+	blockHash := header.Hash()
+	for len(peers) > 0 {
+		who := peers[0]
+		request := &network.StateRequest{
+			Block: blockHash,
+			Start: download.start,
+		}
+
+		response, err := s.network.DoStateRequest(who, request)
+		if err != nil {
+			logger.Debugf("failed to get state from peer %s: %s", who, err)
+			peers = peers[1:]
+			continue
+		}
+
+		complete, err := download.add(header.StateRoot, response)
+		if err != nil {
+			logger.Debugf("invalid state response from peer %s: %s", who, err)
+			s.network.ReportPeer(peerset.ReputationChange{
+				Value:  peerset.BadMessageValue,
+				Reason: peerset.BadMessageReason,
+			}, who)
+			peers = peers[1:]
+			continue
+		}
+
+		if complete {
+			return s.store(download, header)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: for block #%d (%s)", errStateSyncIncomplete, header.Number, blockHash)
+}
+
+// store sets the downloaded child tries in the top trie, using the state trie version
+// of the runtime code downloaded, checks the resulting state root matches the block
+// state root and writes the state to the database.
+func (s *stateSyncer) store(download *stateDownload, header *types.Header) (state *trie.Trie, err error) {
+	version, err := s.stateVersion(download.top)
+	if err != nil {
+		return nil, fmt.Errorf("getting state trie version: %w", err)
+	}
+
+	top, err := withVersion(download.top, version)
+	if err != nil {
+		return nil, fmt.Errorf("setting top trie version: %w", err)
+	}
+
+	for childStorageKey, child := range download.children {
+		child, err = withVersion(child, version)
+		if err != nil {
+			return nil, fmt.Errorf("setting child trie version: %w", err)
+		}
+
+		keyToChild := []byte(childStorageKey)[len(trie.ChildStorageKeyPrefix):]
+		err = top.SetChild(keyToChild, child)
+		if err != nil {
+			return nil, fmt.Errorf("setting child trie at key 0x%x: %w", childStorageKey, err)
+		}
+	}
+
+	stateRoot, err := top.Hash()
+	if err != nil {
+		return nil, fmt.Errorf("hashing state trie: %w", err)
+	}
+
+	if stateRoot != header.StateRoot {
+		return nil, fmt.Errorf("%w: expected %s but got %s",
+			errStateRootMismatch, header.StateRoot, stateRoot)
+	}
+
+	err = s.storageState.StoreTrie(rtstorage.NewTrieState(top), header)
+	if err != nil {
+		return nil, fmt.Errorf("storing state trie: %w", err)
+	}
+
+	return top, nil
+}
+
+// stateVersion returns the state trie version of the runtime code found at the
+// `:code` key of the trie given, or the state trie version 0 if there is no runtime code.
+func (s *stateSyncer) stateVersion(top *trie.Trie) (version trie.Version, err error) {
+	code := top.Get(common.CodeKey)
+	if len(code) == 0 {
+		return trie.V0, nil
+	}
+
+	runtimeVersion, err := s.getRuntimeVersion(code)
+	if err != nil {
+		return version, fmt.Errorf("getting runtime version: %w", err)
+	}
+
+	version, err = trie.VersionFromUint32(runtimeVersion.StateVersion)
+	if err != nil {
+		return version, fmt.Errorf("parsing runtime state version: %w", err)
+	}
+
+	return version, nil
+}
+
+// withVersion returns the trie given if it uses the state trie version given,
+// and otherwise returns a copy of the trie using the state trie version given.
+// A copy is needed since the nodes already in a trie keep their encoding
+// when its version is changed.
+func withVersion(t *trie.Trie, version trie.Version) (versioned *trie.Trie, err error) {
+	if t.Version() == version {
+		return t, nil
+	}
+
+	versioned = trie.NewEmptyTrie()
+	versioned.SetVersion(version)
+	for key, value := range t.Entries() {
+		err = versioned.Put([]byte(key), value)
+		if err != nil {
+			return nil, fmt.Errorf("inserting entry: %w", err)
+		}
+	}
+
+	return versioned, nil
+}
+
+// add verifies the entries of the state response and inserts them in the
+// downloaded tries. It returns true if the state download is complete.
+func (d *stateDownload) add(stateRoot common.Hash, response *network.StateResponse) (
+	complete bool, err error) {
+	if len(response.Entries) == 0 {
+		return false, errEmptyStateResponse
+	}
+
+	top := response.Entries[0]
+	if len(top.StateRoot) != 0 {
+		return false, fmt.Errorf("%w: top trie entries have state root 0x%x",
+			errInvalidStateResponse, top.StateRoot)
+	}
+
+	var topStart []byte
+	var childStorageKeys [][]byte
+	if len(d.start) > 0 {
+		topStart = d.start[0]
+	}
+	if len(d.start) == 2 {
+		childStorageKeys = append(childStorageKeys, d.start[0])
+	}
+
+	err = verifyStateEntries(response.Proof, stateRoot.ToBytes(), topStart, top.Entries)
+	if err != nil {
+		return false, fmt.Errorf("verifying top trie entries: %w", err)
+	}
+
+	for _, entry := range top.Entries {
+		if bytes.HasPrefix(entry.Key, trie.ChildStorageKeyPrefix) {
+			childStorageKeys = append(childStorageKeys, entry.Key)
+		}
+	}
+
+	childEntries := response.Entries[1:]
+	if len(childEntries) != len(childStorageKeys) {
+		return false, fmt.Errorf("%w: expected %d child tries but got %d",
+			errInvalidStateResponse, len(childStorageKeys), len(childEntries))
+	}
+
+	for i, child := range childEntries {
+		expectedRoot := d.childRoot(childStorageKeys[i], top.Entries)
+		if !bytes.Equal(child.StateRoot, expectedRoot) {
+			return false, fmt.Errorf("%w: expected child trie root 0x%x but got 0x%x",
+				errInvalidStateResponse, expectedRoot, child.StateRoot)
+		}
+
+		var childStart []byte
+		if i == 0 && len(d.start) == 2 {
+			childStart = d.start[1]
+		}
+
+		err = verifyStateEntries(response.Proof, child.StateRoot, childStart, child.Entries)
+		if err != nil {
+			return false, fmt.Errorf("verifying child trie entries: %w", err)
+		}
+	}
+
+	for _, entry := range top.Entries {
+		err = d.top.Put(entry.Key, entry.Value)
+		if err != nil {
+			return false, fmt.Errorf("inserting top trie entry: %w", err)
+		}
+	}
+
+	for i, child := range childEntries {
+		childTrie, ok := d.children[string(childStorageKeys[i])]
+		if !ok {
+			childTrie = trie.NewEmptyTrie()
+			d.children[string(childStorageKeys[i])] = childTrie
+		}
+
+		for _, entry := range child.Entries {
+			err = childTrie.Put(entry.Key, entry.Value)
+			if err != nil {
+				return false, fmt.Errorf("inserting child trie entry: %w", err)
+			}
+		}
+	}
+
+	childIncomplete := len(childEntries) > 0 && !childEntries[len(childEntries)-1].Complete
+	if top.Complete && !childIncomplete {
+		return true, nil
+	}
+
+	numberOfEntries := len(top.Entries)
+	for _, child := range childEntries {
+		numberOfEntries += len(child.Entries)
+	}
+	if numberOfEntries == 0 {
+		return false, fmt.Errorf("%w: no entries in incomplete response", errInvalidStateResponse)
+	}
+
+	if childIncomplete {
+		lastChild := childEntries[len(childEntries)-1]
+		var lastChildKey []byte
+		if len(lastChild.Entries) > 0 {
+			lastChildKey = lastChild.Entries[len(lastChild.Entries)-1].Key
+		}
+		d.start = [][]byte{childStorageKeys[len(childStorageKeys)-1], lastChildKey}
+		return false, nil
+	}
+
+	if len(top.Entries) > 0 {
+		topStart = top.Entries[len(top.Entries)-1].Key
+	}
+	d.start = [][]byte{topStart}
+	return false, nil
+}
+
+// childRoot returns the root hash of the child trie at the given child storage key,
+// from the top trie entries of the response or from the entries downloaded before.
+func (d *stateDownload) childRoot(childStorageKey []byte, topEntries []network.StateEntry) []byte {
+	for _, entry := range topEntries {
+		if bytes.Equal(entry.Key, childStorageKey) {
+			return entry.Value
+		}
+	}
+	return d.top.Get(childStorageKey)
+}
+
+// verifyStateEntries verifies the state entries are sorted after the start key,
+// and are part of the trie with the given root using the proof given.
+func verifyStateEntries(encodedProofNodes [][]byte, root, start []byte,
+	entries []network.StateEntry) (err error) {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	keys := make([][]byte, len(entries))
+	values := make([][]byte, len(entries))
+	previousKey := start
+	for i, entry := range entries {
+		if previousKey != nil && bytes.Compare(entry.Key, previousKey) <= 0 {
+			return fmt.Errorf("%w: key 0x%x is not after key 0x%x",
+				errInvalidStateResponse, entry.Key, previousKey)
+		}
+		previousKey = entry.Key
+		keys[i] = entry.Key
+		values[i] = entry.Value
+	}
+
+	return proof.VerifyEntries(encodedProofNodes, root, keys, values)
+}
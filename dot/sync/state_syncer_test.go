@@ -0,0 +1,273 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/peerset"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/lib/trie/proof"
+	"github.com/golang/mock/gomock"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_stateSyncer_sync(t *testing.T) {
+	t.Parallel()
+
+	keyToChild := []byte("child")
+	childStorageKey := append(append([]byte{}, trie.ChildStorageKeyPrefix...), keyToChild...)
+
+	child := trie.NewEmptyTrie()
+	require.NoError(t, child.Put([]byte("x"), []byte("child_x")))
+	require.NoError(t, child.Put([]byte("y"), []byte("child_y")))
+	childRoot := child.MustHash()
+
+	top := trie.NewEmptyTrie()
+	require.NoError(t, top.Put([]byte("a"), []byte("top_a")))
+	require.NoError(t, top.SetChild(keyToChild, child))
+	stateRoot := top.MustHash()
+
+	database, err := chaindb.NewBadgerDB(&chaindb.Config{InMemory: true})
+	require.NoError(t, err)
+	err = top.WriteDirty(database)
+	require.NoError(t, err)
+
+	generateProof := func(root common.Hash, keys ...[]byte) [][]byte {
+		encodedProofNodes, err := proof.Generate(root.ToBytes(), keys, database)
+		require.NoError(t, err)
+		return encodedProofNodes
+	}
+
+	header := types.NewHeader(common.Hash{}, stateRoot, common.Hash{}, 1, types.NewDigest())
+	firstRequest := &network.StateRequest{Block: header.Hash()}
+	secondRequest := &network.StateRequest{
+		Block: header.Hash(),
+		Start: [][]byte{childStorageKey, []byte("x")},
+	}
+
+	firstResponse := &network.StateResponse{
+		Entries: []network.KeyValueStateEntry{{
+			Entries: []network.StateEntry{{Key: childStorageKey, Value: childRoot.ToBytes()}},
+		}, {
+			StateRoot: childRoot.ToBytes(),
+			Entries:   []network.StateEntry{{Key: []byte("x"), Value: []byte("child_x")}},
+		}},
+		Proof: append(generateProof(stateRoot, childStorageKey), generateProof(childRoot, []byte("x"))...),
+	}
+	secondResponse := &network.StateResponse{
+		Entries: []network.KeyValueStateEntry{{
+			Entries:  []network.StateEntry{{Key: []byte("a"), Value: []byte("top_a")}},
+			Complete: true,
+		}, {
+			StateRoot: childRoot.ToBytes(),
+			Entries:   []network.StateEntry{{Key: []byte("y"), Value: []byte("child_y")}},
+			Complete:  true,
+		}},
+		Proof: append(generateProof(stateRoot, []byte("a")), generateProof(childRoot, []byte("y"))...),
+	}
+	responseWithoutProof := &network.StateResponse{
+		Entries: firstResponse.Entries,
+	}
+	incompleteStateResponse := &network.StateResponse{
+		Entries: []network.KeyValueStateEntry{{
+			Entries:  []network.StateEntry{{Key: []byte("a"), Value: []byte("top_a")}},
+			Complete: true,
+		}},
+		Proof: generateProof(stateRoot, []byte("a")),
+	}
+
+	errTest := errors.New("test error")
+	badMessage := peerset.ReputationChange{
+		Value:  peerset.BadMessageValue,
+		Reason: peerset.BadMessageReason,
+	}
+
+	testCases := map[string]struct {
+		peers        []peer.ID
+		setupNetwork func(net *MockNetwork)
+		storeTrie    bool
+		errWrapped   error
+	}{
+		"no_peers": {
+			errWrapped: errNoPeers,
+		},
+		"download_in_two_responses": {
+			peers: []peer.ID{"a"},
+			setupNetwork: func(net *MockNetwork) {
+				net.EXPECT().DoStateRequest(peer.ID("a"), firstRequest).Return(firstResponse, nil)
+				net.EXPECT().DoStateRequest(peer.ID("a"), secondRequest).Return(secondResponse, nil)
+			},
+			storeTrie: true,
+		},
+		"invalid_proof_reports_peer": {
+			peers: []peer.ID{"a", "b"},
+			setupNetwork: func(net *MockNetwork) {
+				net.EXPECT().DoStateRequest(peer.ID("a"), firstRequest).Return(responseWithoutProof, nil)
+				net.EXPECT().ReportPeer(badMessage, peer.ID("a"))
+				net.EXPECT().DoStateRequest(peer.ID("b"), firstRequest).Return(firstResponse, nil)
+				net.EXPECT().DoStateRequest(peer.ID("b"), secondRequest).Return(secondResponse, nil)
+			},
+			storeTrie: true,
+		},
+		"request_error_on_last_peer": {
+			peers: []peer.ID{"a"},
+			setupNetwork: func(net *MockNetwork) {
+				net.EXPECT().DoStateRequest(peer.ID("a"), firstRequest).Return(firstResponse, nil)
+				net.EXPECT().DoStateRequest(peer.ID("a"), secondRequest).Return(nil, errTest)
+			},
+			errWrapped: errStateSyncIncomplete,
+		},
+		"state_root_mismatch": {
+			peers: []peer.ID{"a"},
+			setupNetwork: func(net *MockNetwork) {
+				net.EXPECT().DoStateRequest(peer.ID("a"), firstRequest).Return(incompleteStateResponse, nil)
+			},
+			errWrapped: errStateRootMismatch,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			net := NewMockNetwork(ctrl)
+			if testCase.setupNetwork != nil {
+				testCase.setupNetwork(net)
+			}
+
+			storageState := NewMockStorageState(ctrl)
+			if testCase.storeTrie {
+				storageState.EXPECT().StoreTrie(gomock.Any(), header).Return(nil)
+			}
+
+			syncer := newStateSyncer(net, storageState)
+
+			state, err := syncer.sync(testCase.peers, header)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.Nil(t, state)
+				return
+			}
+			assert.Equal(t, stateRoot, state.MustHash())
+		})
+	}
+}
+
+func Test_stateSyncer_store(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+	code := []byte("runtime code")
+	largeValue := make([]byte, 40) // hashed with the state trie version 1
+	keyToChild := []byte("child")
+	childStorageKey := append(append([]byte{}, trie.ChildStorageKeyPrefix...), keyToChild...)
+
+	newStateRoot := func(version trie.Version) common.Hash {
+		child := trie.NewEmptyTrie()
+		child.SetVersion(version)
+		require.NoError(t, child.Put([]byte("x"), largeValue))
+		top := trie.NewEmptyTrie()
+		top.SetVersion(version)
+		require.NoError(t, top.Put(common.CodeKey, code))
+		require.NoError(t, top.Put([]byte("a"), largeValue))
+		require.NoError(t, top.SetChild(keyToChild, child))
+		return top.MustHash()
+	}
+	stateRootV0 := newStateRoot(trie.V0)
+	stateRootV1 := newStateRoot(trie.V1)
+	require.NotEqual(t, stateRootV0, stateRootV1)
+
+	newDownload := func() *stateDownload {
+		child := trie.NewEmptyTrie()
+		require.NoError(t, child.Put([]byte("x"), largeValue))
+		top := trie.NewEmptyTrie()
+		require.NoError(t, top.Put(common.CodeKey, code))
+		require.NoError(t, top.Put([]byte("a"), largeValue))
+		require.NoError(t, top.Put(childStorageKey, child.MustHash().ToBytes()))
+		return &stateDownload{
+			top:      top,
+			children: map[string]*trie.Trie{string(childStorageKey): child},
+		}
+	}
+
+	testCases := map[string]struct {
+		stateVersion uint32
+		versionErr   error
+		stateRoot    common.Hash
+		storeTrie    bool
+		errWrapped   error
+		errMessage   string
+	}{
+		"runtime_version_error": {
+			versionErr: errTest,
+			errWrapped: errTest,
+			errMessage: "getting state trie version: getting runtime version: test error",
+		},
+		"invalid_state_version": {
+			stateVersion: 2,
+			errWrapped:   trie.ErrVersionUnknown,
+			errMessage: "getting state trie version: parsing runtime state version: " +
+				"state trie version unknown: 2",
+		},
+		"state_version_0": {
+			stateRoot: stateRootV0,
+			storeTrie: true,
+		},
+		"state_version_1": {
+			stateVersion: 1,
+			stateRoot:    stateRootV1,
+			storeTrie:    true,
+		},
+		"state_version_1_root_mismatch": {
+			stateVersion: 1,
+			stateRoot:    stateRootV0,
+			errWrapped:   errStateRootMismatch,
+			errMessage: "state root does not match block state root: expected " + stateRootV0.String() +
+				" but got " + stateRootV1.String(),
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			header := types.NewHeader(common.Hash{}, testCase.stateRoot, common.Hash{}, 1, types.NewDigest())
+			storageState := NewMockStorageState(ctrl)
+			if testCase.storeTrie {
+				storageState.EXPECT().StoreTrie(gomock.Any(), header).Return(nil)
+			}
+
+			syncer := &stateSyncer{
+				storageState: storageState,
+				getRuntimeVersion: func(runtimeCode []byte) (runtime.Version, error) {
+					assert.Equal(t, code, runtimeCode)
+					return runtime.Version{StateVersion: testCase.stateVersion}, testCase.versionErr
+				},
+			}
+
+			state, err := syncer.store(newDownload(), header)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+				return
+			}
+			assert.Equal(t, testCase.stateRoot, state.MustHash())
+		})
+	}
+}
@@ -15,6 +15,27 @@
 
 var logger = log.NewFromGlobal(log.AddContext("pkg", "sync"))
 
+const (
+	// FullMode syncs by importing and executing every block from our best block.
+	FullMode = Mode("full")
+	// WarpMode first verifies the GRANDPA finality proofs up to the highest
+	// finalised block of our peers, and then syncs blocks from there.
+	WarpMode = Mode("warp")
+)
+
+// Mode is the mode used to sync the chain
+type Mode string
+
+// IsValid checks whether the sync mode is valid
+func (m Mode) IsValid() bool {
+	switch m {
+	case FullMode, WarpMode:
+		return true
+	default:
+		return false
+	}
+}
+
 // Service deals with chain syncing by sending block request messages and watching for responses.
 type Service struct {
 	blockState     BlockState
@@ -36,6 +57,11 @@ type Config struct {
 	MinPeers, MaxPeers int
 	SlotDuration       time.Duration
 	Telemetry          Telemetry
+
+	// Mode is the sync mode, and defaults to FullMode if left empty.
+	Mode                  Mode
+	GrandpaState          GrandpaState
+	WarpSyncProofVerifier WarpSyncProofVerifier
 }
 
 // NewService returns a new *sync.Service
@@ -54,6 +80,11 @@ func NewService(cfg *Config) (*Service, error) {
 		maxPeers:      cfg.MaxPeers,
 		slotDuration:  cfg.SlotDuration,
 	}
+	if cfg.Mode == WarpMode {
+		csCfg.warpSyncer = newWarpSyncer(cfg.Network, cfg.BlockState,
+			cfg.GrandpaState, cfg.WarpSyncProofVerifier)
+		csCfg.stateSyncer = newStateSyncer(cfg.Network, cfg.StorageState)
+	}
 	chainSync := newChainSync(csCfg)
 
 	cpCfg := chainProcessorConfig{
@@ -105,6 +136,13 @@ func (s *Service) HandleBlockAnnounce(from peer.ID, msg *network.BlockAnnounceMe
 	return s.chainSync.setBlockAnnounce(from, header)
 }
 
+// ImportBlockData imports the given block data through the same verification
+// and execution path as the blocks received from peers. It is used to import
+// blocks from a file, so the parent of the block must already be imported.
+func (s *Service) ImportBlockData(blockData *types.BlockData) error {
+	return s.chainProcessor.processBlockData(*blockData)
+}
+
 // IsSynced exposes the synced state
 func (s *Service) IsSynced() bool {
 	return s.chainSync.syncState() == tip
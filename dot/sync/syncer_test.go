@@ -279,6 +279,24 @@ func TestService_Stop(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestService_ImportBlockData(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	errTest := errors.New("test error")
+	blockData := &types.BlockData{Hash: common.Hash{1}}
+
+	chainProcessor := NewMockChainProcessor(ctrl)
+	chainProcessor.EXPECT().processBlockData(*blockData).Return(errTest)
+
+	service := &Service{
+		chainProcessor: chainProcessor,
+	}
+
+	err := service.ImportBlockData(blockData)
+	assert.ErrorIs(t, err, errTest)
+}
+
 func Test_reverseBlockData(t *testing.T) {
 	t.Parallel()
 
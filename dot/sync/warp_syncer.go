@@ -0,0 +1,132 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/peerset"
+	"github.com/ChainSafe/gossamer/lib/grandpa"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// warpSyncer downloads and verifies the chain of GRANDPA justifications
+// of the authority set changes, from our highest finalised block up to
+// the highest block finalised by our peers.
+type warpSyncer struct {
+	network      Network
+	blockState   BlockState
+	grandpaState GrandpaState
+	verifier     WarpSyncProofVerifier
+}
+
+func newWarpSyncer(network Network, blockState BlockState, grandpaState GrandpaState,
+	verifier WarpSyncProofVerifier) *warpSyncer {
+	return &warpSyncer{
+		network:      network,
+		blockState:   blockState,
+		grandpaState: grandpaState,
+		verifier:     verifier,
+	}
+}
+
+// sync requests warp sync proofs from the given peers, one peer at a time,
+// until a proof reaching the highest block finalised by the peer is verified.
+// It returns the last verified header with the authority set for its descendants,
+// or a nil result if the peers have no block finalised after our highest finalised block.
+func (w *warpSyncer) sync(peers []peer.ID) (result *grandpa.WarpSyncVerificationResult, err error) {
+	if len(peers) == 0 {
+		return nil, errNoPeers
+	}
+
+	finalisedHeader, err := w.blockState.GetHighestFinalisedHeader()
+	if err != nil {
+		return nil, fmt.Errorf("getting highest finalised header: %w", err)
+	}
+
+	setID, err := w.grandpaState.GetCurrentSetID()
+	if err != nil {
+		return nil, fmt.Errorf("getting current set id: %w", err)
+	}
+
+	authorities, err := w.grandpaState.GetAuthorities(setID)
+	if err != nil {
+		return nil, fmt.Errorf("getting authorities of set id %d: %w", setID, err)
+	}
+
+	start := finalisedHeader
+	for len(peers) > 0 {
+		who := peers[0]
+		request := &network.WarpSyncProofRequest{Begin: start.Hash()}
+		encodedProof, err := w.network.DoWarpSyncRequest(who, request)
+		if err != nil {
+			logger.Debugf("failed to get warp sync proof from peer %s: %s", who, err)
+			peers = peers[1:]
+			continue
+		}
+
+		verified, err := w.verifier.Verify(encodedProof, setID, authorities)
+		if errors.Is(err, grandpa.ErrEmptyWarpSyncProof) {
+			logger.Debugf("peer %s has no block finalised after block #%d (%s)",
+				who, start.Number, start.Hash())
+			peers = peers[1:]
+			continue
+		} else if err != nil {
+			logger.Debugf("failed to verify warp sync proof from peer %s: %s", who, err)
+			w.network.ReportPeer(peerset.ReputationChange{
+				Value:  peerset.BadJustificationValue,
+				Reason: peerset.BadJustificationReason,
+			}, who)
+			peers = peers[1:]
+			continue
+		}
+
+		if verified.Header.Number <= start.Number {
+			logger.Debugf("warp sync proof from peer %s does not progress past block #%d",
+				who, start.Number)
+			peers = peers[1:]
+			continue
+		}
+
+		logger.Infof("warp sync verified block #%d (%s) with authority set id %d",
+			verified.Header.Number, verified.Header.Hash(), verified.SetID)
+
+		result = verified
+		if verified.Completed {
+			return result, nil
+		}
+
+		start = &verified.Header
+		setID = verified.SetID
+		authorities = verified.Authorities
+	}
+
+	if result != nil {
+		// the proof is incomplete but its verified part is still usable.
+		return result, nil
+	}
+
+	return nil, nil //nolint:nilnil
+}
+
+// setSyncedBlock sets the header of the warp sync result as the highest finalised
+// block and the root of the block tree, with the state downloaded for it, and sets
+// the authority set of the result as the current GRANDPA authority set.
+func (w *warpSyncer) setSyncedBlock(result *grandpa.WarpSyncVerificationResult, state *trie.Trie) error {
+	err := w.blockState.SetWarpSyncedBlock(&result.Header, rtstorage.NewTrieState(state), result.SetID)
+	if err != nil {
+		return fmt.Errorf("setting warp synced block: %w", err)
+	}
+
+	err = w.grandpaState.SetCurrentAuthoritySet(result.SetID, result.Authorities, result.Header.Number)
+	if err != nil {
+		return fmt.Errorf("setting authority set id %d: %w", result.SetID, err)
+	}
+
+	return nil
+}
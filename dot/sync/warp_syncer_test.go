@@ -0,0 +1,231 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/peerset"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/grandpa"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/golang/mock/gomock"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_warpSyncer_sync(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+	finalisedHeader := types.NewHeader(common.Hash{}, common.Hash{}, common.Hash{}, 1, types.NewDigest())
+	authorities := []types.GrandpaVoter{{ID: 1}}
+	middleHeader := types.NewHeader(finalisedHeader.Hash(), common.Hash{}, common.Hash{}, 10, types.NewDigest())
+	middleAuthorities := []types.GrandpaVoter{{ID: 2}}
+	lastHeader := types.NewHeader(middleHeader.Hash(), common.Hash{}, common.Hash{}, 20, types.NewDigest())
+	lastAuthorities := []types.GrandpaVoter{{ID: 3}}
+
+	middleResult := &grandpa.WarpSyncVerificationResult{
+		Header:      *middleHeader,
+		SetID:       2,
+		Authorities: middleAuthorities,
+	}
+	lastResult := &grandpa.WarpSyncVerificationResult{
+		Header:      *lastHeader,
+		SetID:       3,
+		Authorities: lastAuthorities,
+		Completed:   true,
+	}
+
+	badJustification := peerset.ReputationChange{
+		Value:  peerset.BadJustificationValue,
+		Reason: peerset.BadJustificationReason,
+	}
+
+	testCases := map[string]struct {
+		peers          []peer.ID
+		setupNetwork   func(network *MockNetwork)
+		setupVerifier  func(verifier *MockWarpSyncProofVerifier)
+		noStateQueries bool
+		result         *grandpa.WarpSyncVerificationResult
+		errWrapped     error
+	}{
+		"no_peers": {
+			noStateQueries: true,
+			errWrapped:     errNoPeers,
+		},
+		"completed_in_one_proof": {
+			peers: []peer.ID{"a"},
+			setupNetwork: func(net *MockNetwork) {
+				net.EXPECT().DoWarpSyncRequest(peer.ID("a"),
+					&network.WarpSyncProofRequest{Begin: finalisedHeader.Hash()}).
+					Return([]byte{1}, nil)
+			},
+			setupVerifier: func(verifier *MockWarpSyncProofVerifier) {
+				verifier.EXPECT().Verify([]byte{1}, uint64(1), authorities).Return(lastResult, nil)
+			},
+			result: lastResult,
+		},
+		"completed_in_two_proofs": {
+			peers: []peer.ID{"a"},
+			setupNetwork: func(net *MockNetwork) {
+				net.EXPECT().DoWarpSyncRequest(peer.ID("a"),
+					&network.WarpSyncProofRequest{Begin: finalisedHeader.Hash()}).
+					Return([]byte{1}, nil)
+				net.EXPECT().DoWarpSyncRequest(peer.ID("a"),
+					&network.WarpSyncProofRequest{Begin: middleHeader.Hash()}).
+					Return([]byte{2}, nil)
+			},
+			setupVerifier: func(verifier *MockWarpSyncProofVerifier) {
+				verifier.EXPECT().Verify([]byte{1}, uint64(1), authorities).Return(middleResult, nil)
+				verifier.EXPECT().Verify([]byte{2}, uint64(2), middleAuthorities).Return(lastResult, nil)
+			},
+			result: lastResult,
+		},
+		"request_error_uses_next_peer": {
+			peers: []peer.ID{"a", "b"},
+			setupNetwork: func(net *MockNetwork) {
+				net.EXPECT().DoWarpSyncRequest(peer.ID("a"), gomock.Any()).Return(nil, errTest)
+				net.EXPECT().DoWarpSyncRequest(peer.ID("b"), gomock.Any()).Return([]byte{1}, nil)
+			},
+			setupVerifier: func(verifier *MockWarpSyncProofVerifier) {
+				verifier.EXPECT().Verify([]byte{1}, uint64(1), authorities).Return(lastResult, nil)
+			},
+			result: lastResult,
+		},
+		"invalid_proof_reports_peer": {
+			peers: []peer.ID{"a", "b"},
+			setupNetwork: func(net *MockNetwork) {
+				net.EXPECT().DoWarpSyncRequest(peer.ID("a"), gomock.Any()).Return([]byte{0}, nil)
+				net.EXPECT().ReportPeer(badJustification, peer.ID("a"))
+				net.EXPECT().DoWarpSyncRequest(peer.ID("b"), gomock.Any()).Return([]byte{1}, nil)
+			},
+			setupVerifier: func(verifier *MockWarpSyncProofVerifier) {
+				verifier.EXPECT().Verify([]byte{0}, uint64(1), authorities).Return(nil, errTest)
+				verifier.EXPECT().Verify([]byte{1}, uint64(1), authorities).Return(lastResult, nil)
+			},
+			result: lastResult,
+		},
+		"nothing_to_warp_sync": {
+			peers: []peer.ID{"a"},
+			setupNetwork: func(net *MockNetwork) {
+				net.EXPECT().DoWarpSyncRequest(peer.ID("a"), gomock.Any()).Return([]byte{1}, nil)
+			},
+			setupVerifier: func(verifier *MockWarpSyncProofVerifier) {
+				verifier.EXPECT().Verify([]byte{1}, uint64(1), authorities).
+					Return(nil, grandpa.ErrEmptyWarpSyncProof)
+			},
+		},
+		"incomplete_proof_from_last_peer": {
+			peers: []peer.ID{"a"},
+			setupNetwork: func(net *MockNetwork) {
+				net.EXPECT().DoWarpSyncRequest(peer.ID("a"),
+					&network.WarpSyncProofRequest{Begin: finalisedHeader.Hash()}).
+					Return([]byte{1}, nil)
+				net.EXPECT().DoWarpSyncRequest(peer.ID("a"),
+					&network.WarpSyncProofRequest{Begin: middleHeader.Hash()}).
+					Return(nil, errTest)
+			},
+			setupVerifier: func(verifier *MockWarpSyncProofVerifier) {
+				verifier.EXPECT().Verify([]byte{1}, uint64(1), authorities).Return(middleResult, nil)
+			},
+			result: middleResult,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			net := NewMockNetwork(ctrl)
+			if testCase.setupNetwork != nil {
+				testCase.setupNetwork(net)
+			}
+
+			verifier := NewMockWarpSyncProofVerifier(ctrl)
+			if testCase.setupVerifier != nil {
+				testCase.setupVerifier(verifier)
+			}
+
+			blockState := NewMockBlockState(ctrl)
+			grandpaState := NewMockGrandpaState(ctrl)
+			if !testCase.noStateQueries {
+				blockState.EXPECT().GetHighestFinalisedHeader().Return(finalisedHeader, nil)
+				grandpaState.EXPECT().GetCurrentSetID().Return(uint64(1), nil)
+				grandpaState.EXPECT().GetAuthorities(uint64(1)).Return(authorities, nil)
+			}
+
+			syncer := newWarpSyncer(net, blockState, grandpaState, verifier)
+
+			result, err := syncer.sync(testCase.peers)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			assert.Equal(t, testCase.result, result)
+		})
+	}
+}
+
+func Test_warpSyncer_setSyncedBlock(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+	header := types.NewHeader(common.Hash{1}, common.Hash{}, common.Hash{}, 20, types.NewDigest())
+	authorities := []types.GrandpaVoter{{ID: 3}}
+	result := &grandpa.WarpSyncVerificationResult{
+		Header:      *header,
+		SetID:       3,
+		Authorities: authorities,
+	}
+	state := trie.NewEmptyTrie()
+
+	testCases := map[string]struct {
+		setWarpSyncedBlockErr error
+		setAuthoritySetErr    error
+		errWrapped            error
+		errMessage            string
+	}{
+		"set_warp_synced_block_error": {
+			setWarpSyncedBlockErr: errTest,
+			errWrapped:            errTest,
+			errMessage:            "setting warp synced block: test error",
+		},
+		"set_authority_set_error": {
+			setAuthoritySetErr: errTest,
+			errWrapped:         errTest,
+			errMessage:         "setting authority set id 3: test error",
+		},
+		"success": {},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			blockState := NewMockBlockState(ctrl)
+			blockState.EXPECT().SetWarpSyncedBlock(header, rtstorage.NewTrieState(state), uint64(3)).
+				Return(testCase.setWarpSyncedBlockErr)
+			grandpaState := NewMockGrandpaState(ctrl)
+			if testCase.setWarpSyncedBlockErr == nil {
+				grandpaState.EXPECT().SetCurrentAuthoritySet(uint64(3), authorities, uint(20)).
+					Return(testCase.setAuthoritySetErr)
+			}
+
+			syncer := newWarpSyncer(nil, blockState, grandpaState, nil)
+			err := syncer.setSyncedBlock(result, state)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
@@ -3,7 +3,10 @@
 
 package types
 
-import "github.com/ChainSafe/gossamer/lib/crypto/sr25519"
+import (
+	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
 
 // BabeEquivocationProof represents a babe equivocation proof.
 // An equivocation happens when a validator produces more than one block on the same slot.
@@ -30,3 +33,47 @@ type BabeEquivocationProof struct {
 // opaque representation, implementers of the runtime API will have to make
 // sure that all usages of `OpaqueKeyOwnershipProof` refer to the same type.
 type OpaqueKeyOwnershipProof []byte
+
+// GrandpaEquivocation is used to create a proof of equivocation of a GRANDPA voter.
+// An equivocation happens when a voter signs two different votes in the same round
+// and for the same stage (prevote or precommit).
+type GrandpaEquivocation struct {
+	// RoundNumber is the round in which the equivocation happened.
+	RoundNumber uint64
+	// ID is the public key of the equivocator.
+	ID [32]byte
+	// FirstVote is the first vote involved in the equivocation.
+	FirstVote GrandpaVote
+	// FirstSignature is the signature of the first vote.
+	FirstSignature [64]byte
+	// SecondVote is the second vote involved in the equivocation.
+	SecondVote GrandpaVote
+	// SecondSignature is the signature of the second vote.
+	SecondSignature [64]byte
+}
+
+// PreVoteEquivocation is an equivocation of a GRANDPA voter during the prevote stage.
+type PreVoteEquivocation GrandpaEquivocation
+
+// Index returns VDT index
+func (PreVoteEquivocation) Index() uint { return 0 }
+
+// PreCommitEquivocation is an equivocation of a GRANDPA voter during the precommit stage.
+type PreCommitEquivocation GrandpaEquivocation
+
+// Index returns VDT index
+func (PreCommitEquivocation) Index() uint { return 1 }
+
+// NewGrandpaEquivocation returns a new VaryingDataType to represent a GRANDPA equivocation
+func NewGrandpaEquivocation() scale.VaryingDataType {
+	return scale.MustNewVaryingDataType(PreVoteEquivocation{}, PreCommitEquivocation{})
+}
+
+// GrandpaEquivocationProof is the proof of a GRANDPA voter equivocation,
+// as expected by the runtime to report it.
+type GrandpaEquivocationProof struct {
+	// SetID is the authority set id in which the equivocation happened.
+	SetID uint64
+	// Equivocation is either a PreVoteEquivocation or a PreCommitEquivocation.
+	Equivocation scale.VaryingDataType
+}
@@ -28,3 +28,50 @@ func TestEquivocationProof(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expectedEncoding, actualEncoding)
 }
+
+func TestGrandpaEquivocationProof(t *testing.T) {
+	t.Parallel()
+
+	equivocation := NewGrandpaEquivocation()
+	err := equivocation.Set(PreCommitEquivocation{
+		RoundNumber:     2,
+		ID:              [32]byte{3},
+		FirstVote:       GrandpaVote{Hash: common.Hash{4}, Number: 5},
+		FirstSignature:  [64]byte{6},
+		SecondVote:      GrandpaVote{Hash: common.Hash{7}, Number: 8},
+		SecondSignature: [64]byte{9},
+	})
+	require.NoError(t, err)
+
+	proof := GrandpaEquivocationProof{
+		SetID:        1,
+		Equivocation: equivocation,
+	}
+
+	encoded, err := scale.Marshal(proof)
+	require.NoError(t, err)
+
+	expectedEncoding := []byte{1, 0, 0, 0, 0, 0, 0, 0} // set id
+	expectedEncoding = append(expectedEncoding, 1)     // precommit variant
+	expectedEncoding = append(expectedEncoding, 2, 0, 0, 0, 0, 0, 0, 0)
+	expectedEncoding = append(expectedEncoding, 3)
+	expectedEncoding = append(expectedEncoding, make([]byte, 31)...)
+	expectedEncoding = append(expectedEncoding, 4)
+	expectedEncoding = append(expectedEncoding, make([]byte, 31)...)
+	expectedEncoding = append(expectedEncoding, 5, 0, 0, 0)
+	expectedEncoding = append(expectedEncoding, 6)
+	expectedEncoding = append(expectedEncoding, make([]byte, 63)...)
+	expectedEncoding = append(expectedEncoding, 7)
+	expectedEncoding = append(expectedEncoding, make([]byte, 31)...)
+	expectedEncoding = append(expectedEncoding, 8, 0, 0, 0)
+	expectedEncoding = append(expectedEncoding, 9)
+	expectedEncoding = append(expectedEncoding, make([]byte, 63)...)
+	require.Equal(t, expectedEncoding, encoded)
+
+	decoded := GrandpaEquivocationProof{
+		Equivocation: NewGrandpaEquivocation(),
+	}
+	err = scale.Unmarshal(encoded, &decoded)
+	require.NoError(t, err)
+	require.Equal(t, proof, decoded)
+}
@@ -145,6 +145,7 @@ type args struct {
 					Modules: []string{"system", "author", "chain", "state", "rpc", "grandpa", "offchain",
 						"childstate", "syncstate", "payment"},
 					WSPort:           8546,
+					MaxBatchSize:     100,
 					WS:               false,
 					WSExternal:       false,
 					WSUnsafe:         false,
@@ -18,6 +18,7 @@
 	// TODO remove once the following issue is done:
 	// https://github.com/ChainSafe/gossamer/issues/2631 .
 	ErrDecodeStorageValue = errors.New("cannot decode storage value")
+	ErrReadHashedValue    = errors.New("cannot read hashed storage value")
 	ErrReadChildrenBitmap = errors.New("cannot read children bitmap")
 	// ErrDecodeChildHash is defined since no sentinel error is defined
 	// in the scale package.
@@ -39,13 +40,13 @@ func Decode(reader io.Reader) (n *Node, err error) {
 	}
 
 	switch variant {
-	case leafVariant.bits:
-		n, err = decodeLeaf(reader, partialKeyLength)
+	case leafVariant.bits, leafWithHashedValueVariant.bits:
+		n, err = decodeLeaf(reader, variant, partialKeyLength)
 		if err != nil {
 			return nil, fmt.Errorf("cannot decode leaf: %w", err)
 		}
 		return n, nil
-	case branchVariant.bits, branchWithValueVariant.bits:
+	case branchVariant.bits, branchWithValueVariant.bits, branchWithHashedValueVariant.bits:
 		n, err = decodeBranch(reader, variant, partialKeyLength)
 		if err != nil {
 			return nil, fmt.Errorf("cannot decode branch: %w", err)
@@ -82,11 +83,18 @@ func decodeBranch(reader io.Reader, variant byte, partialKeyLength uint16) (
 
 	sd := scale.NewDecoder(reader)
 
-	if variant == branchWithValueVariant.bits {
+	switch variant {
+	case branchWithValueVariant.bits:
 		err := sd.Decode(&node.StorageValue)
 		if err != nil {
 			return nil, fmt.Errorf("%w: %s", ErrDecodeStorageValue, err)
 		}
+	case branchWithHashedValueVariant.bits:
+		node.StorageValue, err = decodeHashedValue(reader)
+		if err != nil {
+			return nil, err
+		}
+		node.IsHashedValue = true
 	}
 
 	for i := 0; i < ChildrenCapacity; i++ {
@@ -123,7 +131,7 @@ func decodeBranch(reader io.Reader, variant byte, partialKeyLength uint16) (
 }
 
 // decodeLeaf reads from a reader and decodes to a leaf node.
-func decodeLeaf(reader io.Reader, partialKeyLength uint16) (node *Node, err error) {
+func decodeLeaf(reader io.Reader, variant byte, partialKeyLength uint16) (node *Node, err error) {
 	node = &Node{}
 
 	node.PartialKey, err = decodeKey(reader, partialKeyLength)
@@ -131,6 +139,15 @@ func decodeLeaf(reader io.Reader, partialKeyLength uint16) (node *Node, err erro
 		return nil, fmt.Errorf("cannot decode key: %w", err)
 	}
 
+	if variant == leafWithHashedValueVariant.bits {
+		node.StorageValue, err = decodeHashedValue(reader)
+		if err != nil {
+			return nil, err
+		}
+		node.IsHashedValue = true
+		return node, nil
+	}
+
 	sd := scale.NewDecoder(reader)
 	err = sd.Decode(&node.StorageValue)
 	if err != nil {
@@ -139,3 +156,15 @@ func decodeLeaf(reader io.Reader, partialKeyLength uint16) (node *Node, err erro
 
 	return node, nil
 }
+
+// decodeHashedValue reads the 32 bytes hash of a storage value
+// from the reader, for nodes with a hashed value variant.
+func decodeHashedValue(reader io.Reader) (hashedValue []byte, err error) {
+	const hashLength = 32
+	hashedValue = make([]byte, hashLength)
+	_, err = io.ReadFull(reader, hashedValue)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrReadHashedValue, err)
+	}
+	return hashedValue, nil
+}
@@ -223,6 +223,29 @@ func Test_decodeBranch(t *testing.T) {
 				Descendants: 1,
 			},
 		},
+		"success_for_branch_with_hashed_value": {
+			reader: bytes.NewBuffer(concatByteSlices([][]byte{
+				{9},                         // key data
+				{0, 4},                      // children bitmap
+				bytes.Repeat([]byte{7}, 32), // branch hashed storage value
+				scaleEncodedChildHash,
+			})),
+			variant:          branchWithHashedValueVariant.bits,
+			partialKeyLength: 1,
+			branch: &Node{
+				PartialKey:    []byte{9},
+				StorageValue:  bytes.Repeat([]byte{7}, 32),
+				IsHashedValue: true,
+				Children: padRightChildren([]*Node{
+					nil, nil, nil, nil, nil,
+					nil, nil, nil, nil, nil,
+					{
+						MerkleValue: childHash,
+					},
+				}),
+				Descendants: 1,
+			},
+		},
 		"branch_with_inlined_node_decoding_error": {
 			reader: bytes.NewBuffer(concatByteSlices([][]byte{
 				{1},                        // key data
@@ -363,6 +386,31 @@ func Test_decodeLeaf(t *testing.T) {
 				StorageValue: []byte{1, 2, 3, 4, 5},
 			},
 		},
+		"missing_hashed_value_data": {
+			reader: bytes.NewBuffer([]byte{
+				9,       // key data
+				1, 2, 3, // truncated hashed value data
+			}),
+			variant:          leafWithHashedValueVariant.bits,
+			partialKeyLength: 1,
+			errWrapped:       ErrReadHashedValue,
+			errMessage:       "cannot read hashed storage value: unexpected EOF",
+		},
+		"hashed_value_success": {
+			reader: bytes.NewBuffer(
+				concatByteSlices([][]byte{
+					{9},                         // key data
+					bytes.Repeat([]byte{1}, 32), // hashed value data
+				}),
+			),
+			variant:          leafWithHashedValueVariant.bits,
+			partialKeyLength: 1,
+			leaf: &Node{
+				PartialKey:    []byte{9},
+				StorageValue:  bytes.Repeat([]byte{1}, 32),
+				IsHashedValue: true,
+			},
+		},
 	}
 
 	for name, testCase := range testCases {
@@ -370,7 +418,7 @@ func Test_decodeLeaf(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			leaf, err := decodeLeaf(testCase.reader,
+			leaf, err := decodeLeaf(testCase.reader, testCase.variant,
 				testCase.partialKeyLength)
 
 			assert.ErrorIs(t, err, testCase.errWrapped)
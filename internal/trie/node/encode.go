@@ -40,7 +40,20 @@ func (n *Node) Encode(buffer Buffer) (err error) {
 	// Only encode node storage value if the node has a storage value,
 	// even if it is empty. Do not encode if the branch is without value.
 	// Note leaves and branches with value cannot have a `nil` storage value.
-	if n.StorageValue != nil {
+	if n.IsHashedValue {
+		hashedValue := n.StorageValue
+		if len(hashedValue) != common.HashLength {
+			// the storage value is resolved so hash it, values
+			// are only hashed if they are longer than a hash.
+			hash := common.MustBlake2bHash(n.StorageValue)
+			hashedValue = hash[:]
+		}
+
+		_, err = buffer.Write(hashedValue)
+		if err != nil {
+			return fmt.Errorf("writing hashed storage value: %w", err)
+		}
+	} else if n.StorageValue != nil {
 		encoder := scale.NewEncoder(buffer)
 		err = encoder.Encode(n.StorageValue)
 		if err != nil {
@@ -4,9 +4,11 @@
 package node
 
 import (
+	"bytes"
 	"errors"
 	"testing"
 
+	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -108,6 +110,32 @@ func Test_Node_Encode(t *testing.T) {
 			},
 			expectedEncoding: []byte{1, 2, 3},
 		},
+		"leaf_with_hashed_value_success": {
+			node: &Node{
+				PartialKey:    []byte{1, 2, 3},
+				StorageValue:  bytes.Repeat([]byte{1}, 33),
+				IsHashedValue: true,
+			},
+			writes: []writeCall{
+				{written: []byte{leafWithHashedValueVariant.bits | 3}}, // partial key length 3
+				{written: []byte{0x01, 0x23}},                          // partial key
+				{written: common.MustBlake2bHash(bytes.Repeat([]byte{1}, 33)).ToBytes()},
+			},
+			expectedEncoding: []byte{1, 2, 3},
+		},
+		"leaf_with_unresolved_hashed_value_success": {
+			node: &Node{
+				PartialKey:    []byte{1, 2, 3},
+				StorageValue:  bytes.Repeat([]byte{2}, 32),
+				IsHashedValue: true,
+			},
+			writes: []writeCall{
+				{written: []byte{leafWithHashedValueVariant.bits | 3}}, // partial key length 3
+				{written: []byte{0x01, 0x23}},                          // partial key
+				{written: bytes.Repeat([]byte{2}, 32)},                 // unresolved hashed value
+			},
+			expectedEncoding: []byte{1, 2, 3},
+		},
 		"branch_header_encoding_error": {
 			node: &Node{
 				Children:   make([]*Node, ChildrenCapacity),
@@ -18,11 +18,16 @@ func encodeHeader(node *Node, writer io.Writer) (err error) {
 
 	// Merge variant byte and partial key length together
 	var variant variant
-	if node.Kind() == Leaf {
+	switch {
+	case node.Kind() == Leaf && node.IsHashedValue:
+		variant = leafWithHashedValueVariant
+	case node.Kind() == Leaf:
 		variant = leafVariant
-	} else if node.StorageValue == nil {
+	case node.StorageValue == nil:
 		variant = branchVariant
-	} else {
+	case node.IsHashedValue:
+		variant = branchWithHashedValueVariant
+	default:
 		variant = branchWithValueVariant
 	}
 
@@ -132,9 +137,11 @@ func decodeHeader(reader io.Reader) (variant byte,
 // the decodeHeaderByte function below.
 // For 7 variants, the performance is improved by ~20%.
 var variantsOrderedByBitMask = [...]variant{
-	leafVariant,            // mask 1100_0000
-	branchVariant,          // mask 1100_0000
-	branchWithValueVariant, // mask 1100_0000
+	leafVariant,                  // mask 1100_0000
+	branchVariant,                // mask 1100_0000
+	branchWithValueVariant,       // mask 1100_0000
+	leafWithHashedValueVariant,   // mask 1110_0000
+	branchWithHashedValueVariant, // mask 1111_0000
 }
 
 func decodeHeaderByte(header byte) (variantBits,
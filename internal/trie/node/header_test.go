@@ -41,6 +41,16 @@ func Test_encodeHeader(t *testing.T) {
 				{written: []byte{branchWithValueVariant.bits}},
 			},
 		},
+		"branch_with_hashed_value": {
+			node: &Node{
+				StorageValue:  make([]byte, 33),
+				IsHashedValue: true,
+				Children:      make([]*Node, ChildrenCapacity),
+			},
+			writes: []writeCall{
+				{written: []byte{branchWithHashedValueVariant.bits}},
+			},
+		},
 		"branch_with_key_of_length_30": {
 			node: &Node{
 				PartialKey: make([]byte, 30),
@@ -116,6 +126,18 @@ func Test_encodeHeader(t *testing.T) {
 				{written: []byte{leafVariant.bits}},
 			},
 		},
+		"leaf_with_hashed_value_and_key_of_length_31": {
+			node: &Node{
+				PartialKey:    make([]byte, 31),
+				StorageValue:  make([]byte, 33),
+				IsHashedValue: true,
+			},
+			writes: []writeCall{
+				{written: []byte{leafWithHashedValueVariant.bits | 31}},
+				{written: []byte{0x00}}, // trailing 0 to indicate the partial
+				// key length is done here.
+			},
+		},
 		"leaf_with_key_of_length_30": {
 			node: &Node{
 				PartialKey: make([]byte, 30),
@@ -295,10 +317,10 @@ func Test_decodeHeader(t *testing.T) {
 		},
 		"header_byte_decoding_error": {
 			reads: []readCall{
-				{buffArgCap: 1, read: []byte{0b0011_1110}},
+				{buffArgCap: 1, read: []byte{0b0000_1110}},
 			},
 			errWrapped: ErrVariantUnknown,
-			errMessage: "decoding header byte: node variant is unknown: for header byte 00111110",
+			errMessage: "decoding header byte: node variant is unknown: for header byte 00001110",
 		},
 		"partial_key_length_contained_in_first_byte": {
 			reads: []readCall{
@@ -398,6 +420,18 @@ func Test_decodeHeaderByte(t *testing.T) {
 			partialKeyLengthHeader:     0b0010_1001,
 			partialKeyLengthHeaderMask: 0b0011_1111,
 		},
+		"leaf_with_hashed_value_header": {
+			header:                     0b0010_1001,
+			variantBits:                0b0010_0000,
+			partialKeyLengthHeader:     0b0000_1001,
+			partialKeyLengthHeaderMask: 0b0001_1111,
+		},
+		"branch_with_hashed_value_header": {
+			header:                     0b0001_1001,
+			variantBits:                0b0001_0000,
+			partialKeyLengthHeader:     0b0000_1001,
+			partialKeyLengthHeaderMask: 0b0000_1111,
+		},
 		"unknown_variant_header": {
 			header:     0b0000_0000,
 			errWrapped: ErrVariantUnknown,
@@ -433,7 +467,7 @@ func Test_variantsOrderedByBitMask(t *testing.T) {
 	copy(sortedSlice, variantsOrderedByBitMask[:])
 
 	sort.Slice(slice, func(i, j int) bool {
-		return slice[i].mask > slice[j].mask
+		return slice[i].mask < slice[j].mask
 	})
 
 	assert.Equal(t, sortedSlice, slice)
@@ -18,6 +18,11 @@ type Node struct {
 	// PartialKey is the partial key bytes in nibbles (0 to f in hexadecimal)
 	PartialKey   []byte
 	StorageValue []byte
+	// IsHashedValue is true when the node storage value is encoded
+	// as its Blake2b hash, as specified for state trie version 1.
+	// Note the StorageValue field contains the full value once
+	// resolved, or the 32 bytes hash if not resolved yet.
+	IsHashedValue bool
 	// Generation is incremented on every trie Snapshot() call.
 	// Each node also contain a certain Generation number,
 	// which is updated to match the trie Generation once they are
@@ -59,6 +64,9 @@ func (n *Node) StringNode() (stringNode *gotree.Node) {
 	stringNode.Appendf("Dirty: %t", n.Dirty)
 	stringNode.Appendf("Key: " + bytesToString(n.PartialKey))
 	stringNode.Appendf("Storage value: " + bytesToString(n.StorageValue))
+	if n.IsHashedValue {
+		stringNode.Appendf("Hashed value: %t", n.IsHashedValue)
+	}
 	if n.Descendants > 0 { // must be a branch
 		stringNode.Appendf("Descendants: %d", n.Descendants)
 	}
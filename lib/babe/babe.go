@@ -15,6 +15,7 @@
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/internal/log"
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
+	"github.com/ChainSafe/gossamer/lib/runtime"
 
 	ethmetrics "github.com/ethereum/go-ethereum/metrics"
 )
@@ -502,9 +503,15 @@ func (b *Service) handleSlot(epoch uint64, slot Slot,
 		return err
 	}
 
-	rt.SetContextStorage(ts)
+	instance, release, err := runtime.AcquireInstance(rt)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	instance.SetContextStorage(ts)
 
-	block, err := b.buildBlock(parent, slot, rt, authorityIndex, preRuntimeDigest)
+	block, err := b.buildBlock(parent, slot, instance, authorityIndex, preRuntimeDigest)
 	if err != nil {
 		return err
 	}
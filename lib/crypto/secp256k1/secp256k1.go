@@ -9,6 +9,7 @@
 	"errors"
 	"fmt"
 
+	"github.com/ChainSafe/go-schnorrkel"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/crypto"
 	secp256k1 "github.com/ethereum/go-ethereum/crypto"
@@ -127,6 +128,22 @@ func NewKeypairFromPrivateKeyString(in string) (*Keypair, error) {
 	return NewKeypairFromPrivate(priv)
 }
 
+// NewKeypairFromMnenomic returns a new Keypair using the given mnemonic and password.
+// The private key is the mini secret derived from the mnemonic, as done by Substrate.
+func NewKeypairFromMnenomic(mnemonic, password string) (*Keypair, error) {
+	seed, err := schnorrkel.SeedFromMnemonic(mnemonic, password)
+	if err != nil {
+		return nil, err
+	}
+
+	priv, err := NewPrivateKey(seed[:PrivateKeyLength])
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeypairFromPrivate(priv)
+}
+
 // GenerateKeypair will generate a Keypair
 func GenerateKeypair() (*Keypair, error) {
 	priv, err := secp256k1.GenerateKey()
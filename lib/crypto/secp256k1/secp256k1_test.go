@@ -137,6 +137,24 @@ func TestRecoverPublicKey(t *testing.T) {
 	require.Equal(t, kp.Public(), r)
 }
 
+func TestNewKeypairFromMnenomic(t *testing.T) {
+	mnemonic := "twist sausage october vivid neglect swear crumble hawk beauty fabric egg fragile"
+	kp, err := NewKeypairFromMnenomic(mnemonic, "")
+	require.NoError(t, err)
+	require.Len(t, kp.Public().Encode(), 33)
+
+	again, err := NewKeypairFromMnenomic(mnemonic, "")
+	require.NoError(t, err)
+	require.Equal(t, kp.Public().Encode(), again.Public().Encode())
+
+	withPassword, err := NewKeypairFromMnenomic(mnemonic, "password")
+	require.NoError(t, err)
+	require.NotEqual(t, kp.Public().Encode(), withPassword.Public().Encode())
+
+	_, err = NewKeypairFromMnenomic("invalid mnemonic", "")
+	require.Error(t, err)
+}
+
 func TestRecoverPublicKeyCompressed(t *testing.T) {
 	kp, err := GenerateKeypair()
 	require.NoError(t, err)
@@ -5,8 +5,9 @@
 
 import (
 	"errors"
+	"runtime"
 	"sync"
-	"time"
+	"sync/atomic"
 )
 
 var ErrSignatureVerificationFailed = errors.New("failed to verify signature")
@@ -14,7 +15,8 @@
 // SigVerifyFunc verifies a signature given a public key and a message
 type SigVerifyFunc func(pubkey, sig, msg []byte) (err error)
 
-// SignatureInfo ...
+// SignatureInfo contains a signature to verify, with its
+// public key, message and the function to verify it.
 type SignatureInfo struct {
 	PubKey     []byte
 	Sign       []byte
@@ -22,16 +24,28 @@ type SignatureInfo struct {
 	VerifyFunc SigVerifyFunc
 }
 
-// SignatureVerifier ...
+// signaturesBufferSize is the number of signatures that can be added
+// to a batch before Add blocks waiting for the workers to catch up.
+const signaturesBufferSize = 1024
+
+// SignatureVerifier verifies batches of signatures in parallel
+// background goroutines.
 type SignatureVerifier struct {
-	batch   []*SignatureInfo
-	init    bool // Indicates whether the batch processing is started.
-	invalid bool // Set to true if any signature verification fails.
 	logger  Erroer
-	closeCh chan struct{}
-	sync.RWMutex
-	sync.Once
-	sync.WaitGroup
+	workers int
+
+	// mutex protects the fields below it.
+	mutex   sync.Mutex
+	started bool
+	// pending holds the signatures added before the batch is started.
+	pending []*SignatureInfo
+	// signatures is the channel of signatures to verify
+	// sent to the workers once the batch is started.
+	signatures chan *SignatureInfo
+	workersWG  sync.WaitGroup
+
+	// invalid is set to true if any signature of the batch fails to verify.
+	invalid atomic.Bool
 }
 
 // NewSignatureVerifier initialises SignatureVerifier which does background verification of signatures.
@@ -40,115 +54,139 @@ type SignatureVerifier struct {
 // Signatures can be added to the batch using Add().
 func NewSignatureVerifier(logger Erroer) *SignatureVerifier {
 	return &SignatureVerifier{
-		batch:   make([]*SignatureInfo, 0),
-		init:    false,
-		invalid: false,
 		logger:  logger,
-		RWMutex: sync.RWMutex{},
-		closeCh: make(chan struct{}),
+		workers: runtime.NumCPU(),
 	}
 }
 
-// Start signature verification in batch.
+// Start starts a batch of signature verification, where signatures added
+// are verified in parallel background goroutines until Finish is called.
+// Signatures added before starting the batch are verified as part of the batch.
+// If a batch is already started, it is discarded and a new batch is started.
 func (sv *SignatureVerifier) Start() {
-	// Update the init state.
-	sv.Lock()
-	sv.init = true
-	sv.Unlock()
-
-	sv.WaitGroup.Add(1)
-
-	go func() {
-		defer sv.Done()
-		for {
-			select {
-			case <-sv.closeCh:
-				return
-			default:
-				signature := sv.Remove()
-				if signature == nil {
-					continue
-				}
-				err := signature.VerifyFunc(signature.PubKey, signature.Sign, signature.Msg)
-				if err != nil {
-					sv.logger.Errorf("[ext_crypto_start_batch_verify_version_1]: %s", err)
-					sv.Invalid()
-					return
-				}
-			}
+	sv.mutex.Lock()
+	defer sv.mutex.Unlock()
+	sv.start()
+}
+
+// start starts a batch of signature verification.
+// It is NOT THREAD SAFE to use, and the mutex must be held by the caller.
+func (sv *SignatureVerifier) start() {
+	if sv.started {
+		sv.logger.Errorf("batch verification already started, discarding previous batch")
+		sv.finish()
+	}
+
+	sv.started = true
+	sv.signatures = make(chan *SignatureInfo, signaturesBufferSize)
+	sv.workersWG.Add(sv.workers)
+	for i := 0; i < sv.workers; i++ {
+		go sv.verify(sv.signatures)
+	}
+
+	for _, signature := range sv.pending {
+		sv.signatures <- signature
+	}
+	sv.pending = nil
+}
+
+// verify verifies the signatures received on the channel given until
+// it is closed. Once a signature is invalid, the remaining signatures
+// are drained without being verified.
+func (sv *SignatureVerifier) verify(signatures <-chan *SignatureInfo) {
+	defer sv.workersWG.Done()
+	for signature := range signatures {
+		if sv.invalid.Load() {
+			continue
+		}
+
+		err := signature.VerifyFunc(signature.PubKey, signature.Sign, signature.Msg)
+		if err != nil {
+			sv.logger.Errorf("[ext_crypto_start_batch_verify_version_1]: %s", err)
+			sv.invalid.Store(true)
 		}
-	}()
+	}
 }
 
-// IsStarted ...
+// IsStarted returns true if a batch of signature verification is started.
 func (sv *SignatureVerifier) IsStarted() bool {
-	sv.RLock()
-	defer sv.RUnlock()
-	return sv.init
+	sv.mutex.Lock()
+	defer sv.mutex.Unlock()
+	return sv.started
 }
 
-// IsInvalid ...
+// IsInvalid returns true if a signature of the current batch failed to verify.
 func (sv *SignatureVerifier) IsInvalid() bool {
-	sv.RLock()
-	defer sv.RUnlock()
-	return sv.invalid
-}
-
-// Invalid ...
-func (sv *SignatureVerifier) Invalid() {
-	sv.RLock()
-	defer sv.RUnlock()
-	sv.invalid = true
+	return sv.invalid.Load()
 }
 
-// Add ...
+// Add adds a signature to the batch to verify. The public key, signature
+// and message are copied, so the caller can reuse their underlying memory,
+// for example the runtime memory, once Add returns.
+// The signature is not verified if a signature of the batch is already invalid.
 func (sv *SignatureVerifier) Add(s *SignatureInfo) {
 	if sv.IsInvalid() {
 		return
 	}
 
-	sv.Lock()
-	defer sv.Unlock()
-	sv.batch = append(sv.batch, s)
+	signature := &SignatureInfo{
+		PubKey:     copyBytes(s.PubKey),
+		Sign:       copyBytes(s.Sign),
+		Msg:        copyBytes(s.Msg),
+		VerifyFunc: s.VerifyFunc,
+	}
+
+	sv.mutex.Lock()
+	defer sv.mutex.Unlock()
+
+	if !sv.started {
+		sv.pending = append(sv.pending, signature)
+		return
+	}
+
+	sv.signatures <- signature
 }
 
-// Remove returns the first signature from the batch. Returns nil if batch is empty.
-func (sv *SignatureVerifier) Remove() *SignatureInfo {
-	sv.Lock()
-	defer sv.Unlock()
-	if len(sv.batch) == 0 {
-		return nil
+// Finish waits for all the signatures of the batch to be verified and resets
+// the verifier for the next batch. It returns true if all the signatures are
+// valid, and false otherwise. Signatures added without starting a batch are
+// verified before returning.
+func (sv *SignatureVerifier) Finish() (valid bool) {
+	sv.mutex.Lock()
+	defer sv.mutex.Unlock()
+
+	if !sv.started && len(sv.pending) > 0 {
+		sv.start()
 	}
-	sign := sv.batch[0]
-	sv.batch = sv.batch[1:len(sv.batch)]
-	return sign
+
+	return sv.finish()
 }
 
-// Reset reset the signature verifier for reuse.
-func (sv *SignatureVerifier) Reset() {
-	sv.Lock()
-	defer sv.Unlock()
-	sv.init = false
-	sv.batch = make([]*SignatureInfo, 0)
-	sv.invalid = false
-	sv.closeCh = make(chan struct{})
+// finish closes the signatures channel, waits for the workers to verify
+// the remaining signatures and resets the verifier for the next batch.
+// It returns true if all the signatures of the batch are valid.
+// It is NOT THREAD SAFE to use, and the mutex must be held by the caller.
+func (sv *SignatureVerifier) finish() (valid bool) {
+	if sv.started {
+		close(sv.signatures)
+		sv.workersWG.Wait()
+	}
+
+	valid = !sv.invalid.Load()
+
+	sv.started = false
+	sv.pending = nil
+	sv.signatures = nil
+	sv.invalid.Store(false)
+
+	return valid
 }
 
-// Finish waits till batch is finished. Returns true if all the signatures are valid, Otherwise returns false.
-func (sv *SignatureVerifier) Finish() bool {
-	for {
-		time.Sleep(100 * time.Millisecond)
-		sv.Lock()
-		if sv.invalid || len(sv.batch) == 0 {
-			close(sv.closeCh)
-			sv.Unlock()
-			break
-		}
-		sv.RUnlock()
+func copyBytes(b []byte) (copied []byte) {
+	if b == nil {
+		return nil
 	}
-	// Wait till start function to finish and then reset it.
-	sv.Wait()
-	isInvalid := sv.IsInvalid()
-	sv.Reset()
-	return !isInvalid
+	copied = make([]byte, len(b))
+	copy(copied, b)
+	return copied
 }
@@ -13,6 +13,7 @@
 	"github.com/ChainSafe/gossamer/lib/crypto/secp256k1"
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -124,3 +125,92 @@ func TestVerifySignature(t *testing.T) {
 	}
 
 }
+
+func TestSignatureVerifier_batch(t *testing.T) {
+	t.Parallel()
+
+	message := []byte("message")
+	keypair, err := sr25519.GenerateKeypair()
+	require.NoError(t, err)
+	signature, err := keypair.Sign(message)
+	require.NoError(t, err)
+
+	validSignature := &crypto.SignatureInfo{
+		PubKey:     keypair.Public().Encode(),
+		Sign:       signature,
+		Msg:        message,
+		VerifyFunc: sr25519.VerifySignature,
+	}
+	invalidSignature := &crypto.SignatureInfo{
+		PubKey:     keypair.Public().Encode(),
+		Sign:       signature,
+		Msg:        []byte("other message"),
+		VerifyFunc: sr25519.VerifySignature,
+	}
+
+	t.Run("many_valid_signatures", func(t *testing.T) {
+		t.Parallel()
+
+		signVerify := crypto.NewSignatureVerifier(log.New(log.SetWriter(io.Discard)))
+		signVerify.Start()
+		for i := 0; i < 2000; i++ {
+			signVerify.Add(validSignature)
+		}
+		assert.True(t, signVerify.Finish())
+	})
+
+	t.Run("one_invalid_signature", func(t *testing.T) {
+		t.Parallel()
+
+		signVerify := crypto.NewSignatureVerifier(log.New(log.SetWriter(io.Discard)))
+		signVerify.Start()
+		for i := 0; i < 100; i++ {
+			signVerify.Add(validSignature)
+		}
+		signVerify.Add(invalidSignature)
+		assert.False(t, signVerify.Finish())
+		assert.False(t, signVerify.IsStarted())
+
+		// the next batch is not affected by the previous invalid batch
+		signVerify.Start()
+		signVerify.Add(validSignature)
+		assert.True(t, signVerify.Finish())
+	})
+
+	t.Run("signature_data_copied", func(t *testing.T) {
+		t.Parallel()
+
+		signVerify := crypto.NewSignatureVerifier(log.New(log.SetWriter(io.Discard)))
+		signVerify.Start()
+
+		msg := append([]byte{}, message...)
+		signVerify.Add(&crypto.SignatureInfo{
+			PubKey:     keypair.Public().Encode(),
+			Sign:       signature,
+			Msg:        msg,
+			VerifyFunc: sr25519.VerifySignature,
+		})
+		// the caller memory is reused once the signature is added
+		copy(msg, "garbage")
+
+		assert.True(t, signVerify.Finish())
+	})
+
+	t.Run("start_discards_previous_batch", func(t *testing.T) {
+		t.Parallel()
+
+		signVerify := crypto.NewSignatureVerifier(log.New(log.SetWriter(io.Discard)))
+		signVerify.Start()
+		signVerify.Add(invalidSignature)
+		signVerify.Start()
+		signVerify.Add(validSignature)
+		assert.True(t, signVerify.Finish())
+	})
+
+	t.Run("finish_without_start", func(t *testing.T) {
+		t.Parallel()
+
+		signVerify := crypto.NewSignatureVerifier(log.New(log.SetWriter(io.Discard)))
+		assert.True(t, signVerify.Finish())
+	})
+}
@@ -83,9 +83,16 @@
 	// ErrAuthorityNotInSet is returned when a precommit within a justification is signed by a key not in the authority set
 	ErrAuthorityNotInSet = errors.New("authority is not in set")
 
-	errVoteToSignatureMismatch = errors.New("votes and authority count mismatch")
-	errVoteBlockMismatch       = errors.New("block in vote is not descendant of previously finalised block")
-	errVoteFromSelf            = errors.New("got vote from ourselves")
-	errRoundOutOfBounds        = errors.New("round out of bounds")
-	errRoundsMismatch          = errors.New("rounds mismatch")
+	// ErrEmptyWarpSyncProof is returned when verifying a warp sync proof without fragments,
+	// which is the case when the peer has no block finalised after the requested start block.
+	ErrEmptyWarpSyncProof = errors.New("warp sync proof is empty")
+
+	errVoteToSignatureMismatch  = errors.New("votes and authority count mismatch")
+	errVoteBlockMismatch        = errors.New("block in vote is not descendant of previously finalised block")
+	errVoteFromSelf             = errors.New("got vote from ourselves")
+	errRoundOutOfBounds         = errors.New("round out of bounds")
+	errRoundsMismatch           = errors.New("rounds mismatch")
+	errInvalidEquivocationStage = errors.New("invalid equivocation stage")
+	errStartBlockNotFinalised   = errors.New("start block is not finalised")
+	errMissingScheduledChange   = errors.New("missing scheduled authority set change")
 )
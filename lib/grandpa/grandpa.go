@@ -49,6 +49,7 @@ type Service struct {
 	cancel         context.CancelFunc
 	blockState     BlockState
 	grandpaState   GrandpaState
+	storageState   StorageState
 	keypair        *ed25519.Keypair // TODO: change to grandpa keystore (#1870)
 	mapLock        sync.Mutex
 	chanLock       sync.Mutex
@@ -87,6 +88,7 @@ type Config struct {
 	LogLvl       log.Level
 	BlockState   BlockState
 	GrandpaState GrandpaState
+	StorageState StorageState
 	Network      Network
 	Voters       []Voter
 	Keypair      *ed25519.Keypair
@@ -137,6 +139,7 @@ func NewService(cfg *Config) (*Service, error) {
 		state:              NewState(cfg.Voters, setID, round),
 		blockState:         cfg.BlockState,
 		grandpaState:       cfg.GrandpaState,
+		storageState:       cfg.StorageState,
 		keypair:            cfg.Keypair,
 		authority:          cfg.Authority,
 		prevotes:           new(sync.Map),
@@ -8,8 +8,10 @@
 	"github.com/libp2p/go-libp2p/core/protocol"
 
 	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/state"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 )
 
 // BlockState is the interface required by GRANDPA into the block state
@@ -30,8 +32,10 @@ type BlockState interface {
 	GetFinalisedNotifierChannel() chan *types.FinalisationInfo
 	FreeFinalisedNotifierChannel(ch chan *types.FinalisationInfo)
 	SetJustification(hash common.Hash, data []byte) error
+	GetJustification(hash common.Hash) ([]byte, error)
 	BestBlockNumber() (blockNumber uint, err error)
 	GetHighestRoundAndSetID() (uint64, uint64, error)
+	GetRuntime(blockHash common.Hash) (instance state.Runtime, err error)
 }
 
 // GrandpaState is the interface required by grandpa into the grandpa state
@@ -39,6 +43,7 @@ type GrandpaState interface {
 	GetCurrentSetID() (uint64, error)
 	GetAuthorities(setID uint64) ([]types.GrandpaVoter, error)
 	GetSetIDByBlockNumber(num uint) (uint64, error)
+	GetSetIDChange(setID uint64) (blockNumber uint, err error)
 	SetLatestRound(round uint64) error
 	GetLatestRound() (uint64, error)
 	SetPrevotes(round, setID uint64, data []SignedVote) error
@@ -48,6 +53,11 @@ type GrandpaState interface {
 	NextGrandpaAuthorityChange(bestBlockHash common.Hash, bestBlockNumber uint) (blockHeight uint, err error)
 }
 
+// StorageState is the interface required by GRANDPA into the storage state
+type StorageState interface {
+	TrieState(root *common.Hash) (*rtstorage.TrieState, error)
+}
+
 // Network is the interface required by GRANDPA for the network
 type Network interface {
 	GossipMessage(msg network.NotificationsMessage)
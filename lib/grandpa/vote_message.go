@@ -9,8 +9,10 @@
 	"fmt"
 
 	"github.com/ChainSafe/gossamer/dot/telemetry"
+	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/blocktree"
 	"github.com/ChainSafe/gossamer/lib/crypto/ed25519"
+	"github.com/ChainSafe/gossamer/lib/runtime"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -215,8 +217,30 @@ func (s *Service) validateVoteMessage(from peer.ID, m *VoteMessage) (*Vote, erro
 
 // checkForEquivocation checks if the vote is an equivocatory vote.
 // it returns true if so, false otherwise.
-// additionally, if the vote is equivocatory, it updates the service's votes and equivocations.
+// additionally, if the vote is equivocatory, it updates the service's votes and equivocations,
+// and reports the equivocation to the runtime if it is the first one of the voter.
 func (s *Service) checkForEquivocation(voter *Voter, vote *SignedVote, stage Subround) bool {
+	existingVote, equivocated := s.updateEquivocations(voter, vote, stage)
+	if existingVote == nil || stage == primaryProposal {
+		// Primary proposals are stored as prevotes but are not votes,
+		// so their equivocations cannot be reported.
+		return equivocated
+	}
+
+	// The equivocation is reported outside the map lock since
+	// calling the runtime is slow.
+	err := s.reportEquivocation(stage, existingVote, vote)
+	if err != nil {
+		logger.Errorf("reporting equivocation: %s", err)
+	}
+	return equivocated
+}
+
+// updateEquivocations checks if the vote is an equivocatory vote and updates
+// the service's votes and equivocations if so. It returns the existing vote of
+// the voter if the voter equivocated for the first time with this vote.
+func (s *Service) updateEquivocations(voter *Voter, vote *SignedVote, stage Subround) (
+	existingVote *SignedVote, equivocated bool) {
 	v := voter.Key.AsBytes()
 
 	// save justification, since equivocatory vote may still be used in justification
@@ -236,22 +260,92 @@ func (s *Service) checkForEquivocation(voter *Voter, vote *SignedVote, stage Sub
 	if has {
 		// if the voter has already equivocated, every vote in that round is an equivocatory vote
 		eq[v] = append(eq[v], vote)
-		return true
+		return nil, true
 	}
 
-	existingVote, has := s.loadVote(v, stage)
+	existingVote, has = s.loadVote(v, stage)
 	if !has {
-		return false
+		return nil, false
 	}
 
 	if has && existingVote.Vote.Hash != vote.Vote.Hash {
 		// the voter has already voted, all their votes are now equivocatory
 		eq[v] = []*SignedVote{existingVote, vote}
 		s.deleteVote(v, stage)
-		return true
+		return existingVote, true
+	}
+
+	return nil, false
+}
+
+// reportEquivocation builds an equivocation proof from the two conflicting votes
+// of a voter and submits it to the runtime, along with the key ownership
+// proof of the equivocator obtained from the runtime.
+func (s *Service) reportEquivocation(stage Subround, firstVote, secondVote *SignedVote) error {
+	offenderPublicKey := [32]byte(firstVote.AuthorityID)
+	grandpaEquivocation := types.GrandpaEquivocation{
+		RoundNumber:     s.state.round,
+		ID:              offenderPublicKey,
+		FirstVote:       firstVote.Vote,
+		FirstSignature:  firstVote.Signature,
+		SecondVote:      secondVote.Vote,
+		SecondSignature: secondVote.Signature,
+	}
+
+	// Only prevote and precommit equivocations can be reported,
+	// primary proposals are not votes.
+	equivocation := types.NewGrandpaEquivocation()
+	var err error
+	switch stage {
+	case prevote:
+		err = equivocation.Set(types.PreVoteEquivocation(grandpaEquivocation))
+	case precommit:
+		err = equivocation.Set(types.PreCommitEquivocation(grandpaEquivocation))
+	default:
+		return fmt.Errorf("%w: %s", errInvalidEquivocationStage, stage)
+	}
+	if err != nil {
+		return fmt.Errorf("setting equivocation: %w", err)
+	}
+
+	bestBlockHeader, err := s.blockState.BestBlockHeader()
+	if err != nil {
+		return fmt.Errorf("getting best block header: %w", err)
 	}
 
-	return false
+	rt, err := s.blockState.GetRuntime(bestBlockHeader.Hash())
+	if err != nil {
+		return fmt.Errorf("getting runtime: %w", err)
+	}
+
+	runtimeInstance, release, err := runtime.AcquireInstance(rt)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	trieState, err := s.storageState.TrieState(&bestBlockHeader.StateRoot)
+	if err != nil {
+		return fmt.Errorf("getting trie state: %w", err)
+	}
+	runtimeInstance.SetContextStorage(trieState)
+
+	keyOwnershipProof, err := runtimeInstance.GrandpaGenerateKeyOwnershipProof(s.state.setID, offenderPublicKey)
+	if err != nil {
+		return fmt.Errorf("getting key ownership proof from runtime: %w", err)
+	}
+
+	equivocationProof := types.GrandpaEquivocationProof{
+		SetID:        s.state.setID,
+		Equivocation: equivocation,
+	}
+
+	err = runtimeInstance.GrandpaSubmitReportEquivocationUnsignedExtrinsic(equivocationProof, keyOwnershipProof)
+	if err != nil {
+		return fmt.Errorf("submitting equivocation report to runtime: %w", err)
+	}
+
+	return nil
 }
 
 // validateVote checks if the block that is being voted for exists, and that it is a descendant of a
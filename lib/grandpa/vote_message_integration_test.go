@@ -24,6 +24,7 @@ func TestCheckForEquivocation_NoEquivocation(t *testing.T) {
 	cfg := &Config{
 		BlockState:   st.Block,
 		GrandpaState: st.Grandpa,
+		StorageState: st.Storage,
 		Network:      net,
 		Interval:     time.Second,
 	}
@@ -54,6 +55,7 @@ func TestCheckForEquivocation_WithEquivocation(t *testing.T) {
 	cfg := &Config{
 		BlockState:   st.Block,
 		GrandpaState: st.Grandpa,
+		StorageState: st.Storage,
 		Network:      net,
 		Interval:     time.Second,
 	}
@@ -96,6 +98,7 @@ func TestCheckForEquivocation_WithExistingEquivocation(t *testing.T) {
 	cfg := &Config{
 		BlockState:   st.Block,
 		GrandpaState: st.Grandpa,
+		StorageState: st.Storage,
 		Network:      net,
 		Interval:     time.Second,
 	}
@@ -151,6 +154,7 @@ func TestValidateMessage_Valid(t *testing.T) {
 	cfg := &Config{
 		BlockState:   st.Block,
 		GrandpaState: st.Grandpa,
+		StorageState: st.Storage,
 		Voters:       newTestVoters(t),
 		Network:      net,
 		Interval:     time.Second,
@@ -184,6 +188,7 @@ func TestValidateMessage_InvalidSignature(t *testing.T) {
 	cfg := &Config{
 		BlockState:   st.Block,
 		GrandpaState: st.Grandpa,
+		StorageState: st.Storage,
 		Network:      net,
 		Interval:     time.Second,
 	}
@@ -219,6 +224,7 @@ func TestValidateMessage_SetIDMismatch(t *testing.T) {
 	cfg := &Config{
 		BlockState:   st.Block,
 		GrandpaState: st.Grandpa,
+		StorageState: st.Storage,
 		Network:      net,
 		Interval:     time.Second,
 	}
@@ -252,6 +258,7 @@ func TestValidateMessage_Equivocation(t *testing.T) {
 	cfg := &Config{
 		BlockState:   st.Block,
 		GrandpaState: st.Grandpa,
+		StorageState: st.Storage,
 		Voters:       newTestVoters(t),
 		Network:      net,
 		Interval:     time.Second,
@@ -297,6 +304,7 @@ func TestValidateMessage_BlockDoesNotExist(t *testing.T) {
 	cfg := &Config{
 		BlockState:   st.Block,
 		GrandpaState: st.Grandpa,
+		StorageState: st.Storage,
 		Voters:       newTestVoters(t),
 		Network:      net,
 		Interval:     time.Second,
@@ -333,6 +341,7 @@ func TestValidateMessage_IsNotDescendant(t *testing.T) {
 	cfg := &Config{
 		BlockState:   st.Block,
 		GrandpaState: st.Grandpa,
+		StorageState: st.Storage,
 		Voters:       newTestVoters(t),
 		Network:      net,
 		Interval:     time.Second,
@@ -0,0 +1,223 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package grandpa
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime/mocks"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Service_reportEquivocation(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+	bestBlockHeader := &types.Header{Number: 1, StateRoot: common.Hash{1}, Digest: types.NewDigest()}
+	bestBlockHash := bestBlockHeader.Hash()
+	trieState := rtstorage.NewTrieState(trie.NewEmptyTrie())
+	const setID, round = uint64(3), uint64(4)
+
+	firstVote := &SignedVote{
+		Vote:        Vote{Hash: common.Hash{2}, Number: 1},
+		Signature:   [64]byte{1},
+		AuthorityID: testAuthorityID,
+	}
+	secondVote := &SignedVote{
+		Vote:        Vote{Hash: common.Hash{3}, Number: 1},
+		Signature:   [64]byte{2},
+		AuthorityID: testAuthorityID,
+	}
+	keyOwnershipProof := types.OpaqueKeyOwnershipProof{1, 2, 3}
+
+	grandpaEquivocation := types.GrandpaEquivocation{
+		RoundNumber:     round,
+		ID:              testAuthorityID,
+		FirstVote:       firstVote.Vote,
+		FirstSignature:  firstVote.Signature,
+		SecondVote:      secondVote.Vote,
+		SecondSignature: secondVote.Signature,
+	}
+
+	prevoteEquivocation := types.NewGrandpaEquivocation()
+	err := prevoteEquivocation.Set(types.PreVoteEquivocation(grandpaEquivocation))
+	require.NoError(t, err)
+	prevoteEquivocationProof := types.GrandpaEquivocationProof{
+		SetID:        setID,
+		Equivocation: prevoteEquivocation,
+	}
+
+	precommitEquivocation := types.NewGrandpaEquivocation()
+	err = precommitEquivocation.Set(types.PreCommitEquivocation(grandpaEquivocation))
+	require.NoError(t, err)
+	precommitEquivocationProof := types.GrandpaEquivocationProof{
+		SetID:        setID,
+		Equivocation: precommitEquivocation,
+	}
+
+	storageStateBuilder := func(ctrl *gomock.Controller) StorageState {
+		storageState := NewMockStorageState(ctrl)
+		storageState.EXPECT().TrieState(&bestBlockHeader.StateRoot).Return(trieState, nil)
+		return storageState
+	}
+
+	testCases := map[string]struct {
+		blockStateBuilder   func(ctrl *gomock.Controller) BlockState
+		storageStateBuilder func(ctrl *gomock.Controller) StorageState
+		stage               Subround
+		errWrapped          error
+		errMessage          string
+	}{
+		"best_block_header_error": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().BestBlockHeader().Return(nil, errTest)
+				return blockState
+			},
+			stage:      prevote,
+			errWrapped: errTest,
+			errMessage: "getting best block header: test error",
+		},
+		"get_runtime_error": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().BestBlockHeader().Return(bestBlockHeader, nil)
+				blockState.EXPECT().GetRuntime(bestBlockHash).Return(nil, errTest)
+				return blockState
+			},
+			stage:      prevote,
+			errWrapped: errTest,
+			errMessage: "getting runtime: test error",
+		},
+		"trie_state_error": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				runtimeInstance := mocks.NewMockInstance(ctrl)
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().BestBlockHeader().Return(bestBlockHeader, nil)
+				blockState.EXPECT().GetRuntime(bestBlockHash).Return(runtimeInstance, nil)
+				return blockState
+			},
+			storageStateBuilder: func(ctrl *gomock.Controller) StorageState {
+				storageState := NewMockStorageState(ctrl)
+				storageState.EXPECT().TrieState(&bestBlockHeader.StateRoot).Return(nil, errTest)
+				return storageState
+			},
+			stage:      prevote,
+			errWrapped: errTest,
+			errMessage: "getting trie state: test error",
+		},
+		"key_ownership_proof_error": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				runtimeInstance := mocks.NewMockInstance(ctrl)
+				runtimeInstance.EXPECT().SetContextStorage(trieState)
+				runtimeInstance.EXPECT().GrandpaGenerateKeyOwnershipProof(setID, testAuthorityID).
+					Return(nil, errTest)
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().BestBlockHeader().Return(bestBlockHeader, nil)
+				blockState.EXPECT().GetRuntime(bestBlockHash).Return(runtimeInstance, nil)
+				return blockState
+			},
+			storageStateBuilder: storageStateBuilder,
+			stage:               prevote,
+			errWrapped:          errTest,
+			errMessage:          "getting key ownership proof from runtime: test error",
+		},
+		"invalid_stage": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				return NewMockBlockState(ctrl)
+			},
+			stage:      Subround(9),
+			errWrapped: errInvalidEquivocationStage,
+			errMessage: "invalid equivocation stage: unknown",
+		},
+		"primary_proposal_not_reported": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				return NewMockBlockState(ctrl)
+			},
+			stage:      primaryProposal,
+			errWrapped: errInvalidEquivocationStage,
+			errMessage: "invalid equivocation stage: primaryProposal",
+		},
+		"submit_report_error": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				runtimeInstance := mocks.NewMockInstance(ctrl)
+				runtimeInstance.EXPECT().SetContextStorage(trieState)
+				runtimeInstance.EXPECT().GrandpaGenerateKeyOwnershipProof(setID, testAuthorityID).
+					Return(keyOwnershipProof, nil)
+				runtimeInstance.EXPECT().GrandpaSubmitReportEquivocationUnsignedExtrinsic(
+					prevoteEquivocationProof, keyOwnershipProof).Return(errTest)
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().BestBlockHeader().Return(bestBlockHeader, nil)
+				blockState.EXPECT().GetRuntime(bestBlockHash).Return(runtimeInstance, nil)
+				return blockState
+			},
+			storageStateBuilder: storageStateBuilder,
+			stage:               prevote,
+			errWrapped:          errTest,
+			errMessage:          "submitting equivocation report to runtime: test error",
+		},
+		"prevote_equivocation": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				runtimeInstance := mocks.NewMockInstance(ctrl)
+				runtimeInstance.EXPECT().SetContextStorage(trieState)
+				runtimeInstance.EXPECT().GrandpaGenerateKeyOwnershipProof(setID, testAuthorityID).
+					Return(keyOwnershipProof, nil)
+				runtimeInstance.EXPECT().GrandpaSubmitReportEquivocationUnsignedExtrinsic(
+					prevoteEquivocationProof, keyOwnershipProof).Return(nil)
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().BestBlockHeader().Return(bestBlockHeader, nil)
+				blockState.EXPECT().GetRuntime(bestBlockHash).Return(runtimeInstance, nil)
+				return blockState
+			},
+			storageStateBuilder: storageStateBuilder,
+			stage:               prevote,
+		},
+		"precommit_equivocation": {
+			blockStateBuilder: func(ctrl *gomock.Controller) BlockState {
+				runtimeInstance := mocks.NewMockInstance(ctrl)
+				runtimeInstance.EXPECT().SetContextStorage(trieState)
+				runtimeInstance.EXPECT().GrandpaGenerateKeyOwnershipProof(setID, testAuthorityID).
+					Return(keyOwnershipProof, nil)
+				runtimeInstance.EXPECT().GrandpaSubmitReportEquivocationUnsignedExtrinsic(
+					precommitEquivocationProof, keyOwnershipProof).Return(nil)
+				blockState := NewMockBlockState(ctrl)
+				blockState.EXPECT().BestBlockHeader().Return(bestBlockHeader, nil)
+				blockState.EXPECT().GetRuntime(bestBlockHash).Return(runtimeInstance, nil)
+				return blockState
+			},
+			storageStateBuilder: storageStateBuilder,
+			stage:               precommit,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			service := &Service{
+				blockState: testCase.blockStateBuilder(ctrl),
+				state:      NewState(nil, setID, round),
+			}
+			if testCase.storageStateBuilder != nil {
+				service.storageState = testCase.storageStateBuilder(ctrl)
+			}
+
+			err := service.reportEquivocation(testCase.stage, firstVote, secondVote)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
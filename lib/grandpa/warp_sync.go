@@ -0,0 +1,444 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package grandpa
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/crypto/ed25519"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+// maxWarpSyncProofSize is the maximum size in bytes of a generated warp sync proof.
+const maxWarpSyncProofSize = 8 * 1024 * 1024
+
+// WarpSyncJustification is a GRANDPA justification as found in warp sync proofs,
+// with the headers of the ancestry of the blocks voted for by the precommits.
+type WarpSyncJustification struct {
+	Round           uint64
+	Commit          Commit
+	VotesAncestries []types.Header
+}
+
+// WarpSyncFragment is a header finalised by a GRANDPA justification. Except for
+// the last fragment of a proof, the header enacts an authority set change.
+type WarpSyncFragment struct {
+	Header        types.Header
+	Justification WarpSyncJustification
+}
+
+// WarpSyncProof is a chain of warp sync fragments, each one finalised by the
+// authority set announced in the header of the previous fragment.
+type WarpSyncProof struct {
+	Fragments []WarpSyncFragment
+	// IsFinished is true if the proof reaches the highest finalised block.
+	IsFinished bool
+}
+
+// Encode returns the SCALE encoding of the warp sync proof.
+func (p *WarpSyncProof) Encode() ([]byte, error) {
+	return scale.Marshal(*p)
+}
+
+// Decode decodes the SCALE encoded warp sync proof.
+func (p *WarpSyncProof) Decode(data []byte) (err error) {
+	decoder := scale.NewDecoder(bytes.NewReader(data))
+
+	var length uint
+	err = decoder.Decode(&length)
+	if err != nil {
+		return fmt.Errorf("decoding fragments length: %w", err)
+	}
+
+	p.Fragments = nil
+	for i := uint(0); i < length; i++ {
+		header := types.NewEmptyHeader()
+		err = decoder.Decode(header)
+		if err != nil {
+			return fmt.Errorf("decoding header of fragment %d: %w", i, err)
+		}
+
+		justification, err := decodeWarpSyncJustification(decoder, true)
+		if err != nil {
+			return fmt.Errorf("decoding justification of fragment %d: %w", i, err)
+		}
+
+		p.Fragments = append(p.Fragments, WarpSyncFragment{
+			Header:        *header,
+			Justification: *justification,
+		})
+	}
+
+	err = decoder.Decode(&p.IsFinished)
+	if err != nil {
+		return fmt.Errorf("decoding is finished: %w", err)
+	}
+
+	return nil
+}
+
+// decodeWarpSyncJustification decodes a justification from the decoder. If withAncestries
+// is false, the votes ancestries are left empty, as for justifications stored by this node.
+func decodeWarpSyncJustification(decoder *scale.Decoder, withAncestries bool) (
+	justification *WarpSyncJustification, err error) {
+	justification = new(WarpSyncJustification)
+	err = decoder.Decode(&justification.Round)
+	if err != nil {
+		return nil, fmt.Errorf("decoding round: %w", err)
+	}
+
+	err = decoder.Decode(&justification.Commit)
+	if err != nil {
+		return nil, fmt.Errorf("decoding commit: %w", err)
+	}
+
+	if !withAncestries {
+		return justification, nil
+	}
+
+	var length uint
+	err = decoder.Decode(&length)
+	if err != nil {
+		return nil, fmt.Errorf("decoding votes ancestries length: %w", err)
+	}
+
+	for i := uint(0); i < length; i++ {
+		header := types.NewEmptyHeader()
+		err = decoder.Decode(header)
+		if err != nil {
+			return nil, fmt.Errorf("decoding votes ancestry %d: %w", i, err)
+		}
+		justification.VotesAncestries = append(justification.VotesAncestries, *header)
+	}
+
+	return justification, nil
+}
+
+// WarpSyncVerificationResult is the result of the verification of a warp sync proof.
+type WarpSyncVerificationResult struct {
+	// Header is the header of the last fragment of the proof.
+	Header types.Header
+	// SetID is the authority set ID for the descendants of the header.
+	SetID uint64
+	// Authorities are the authorities for the descendants of the header.
+	Authorities []types.GrandpaVoter
+	// Completed is true if the header is the highest block finalised by the peer.
+	Completed bool
+}
+
+// WarpSyncProofProvider generates and verifies warp sync proofs.
+type WarpSyncProofProvider struct {
+	blockState   BlockState
+	grandpaState GrandpaState
+}
+
+// NewWarpSyncProofProvider returns a new warp sync proof provider.
+func NewWarpSyncProofProvider(blockState BlockState, grandpaState GrandpaState) *WarpSyncProofProvider {
+	return &WarpSyncProofProvider{
+		blockState:   blockState,
+		grandpaState: grandpaState,
+	}
+}
+
+// Generate returns the SCALE encoded warp sync proof of the authority set changes
+// finalised after the given finalised start block, up to the highest finalised block.
+// The proof is not finished if it reaches its maximum size before that block.
+func (p *WarpSyncProofProvider) Generate(start common.Hash) (encodedProof []byte, err error) {
+	startHeader, err := p.blockState.GetHeader(start)
+	if err != nil {
+		return nil, fmt.Errorf("getting start header: %w", err)
+	}
+
+	finalisedHeader, err := p.blockState.GetHighestFinalisedHeader()
+	if err != nil {
+		return nil, fmt.Errorf("getting highest finalised header: %w", err)
+	}
+
+	if startHeader.Number > finalisedHeader.Number {
+		return nil, fmt.Errorf("%w: start block number %d is greater than highest finalised block number %d",
+			errStartBlockNotFinalised, startHeader.Number, finalisedHeader.Number)
+	}
+
+	canonicalHeader, err := p.blockState.GetHeaderByNumber(startHeader.Number)
+	if err != nil {
+		return nil, fmt.Errorf("getting header by number: %w", err)
+	}
+
+	if canonicalHeader.Hash() != start {
+		return nil, fmt.Errorf("%w: start block %s is not on the finalised chain",
+			errStartBlockNotFinalised, start)
+	}
+
+	startSetID, err := p.grandpaState.GetSetIDByBlockNumber(startHeader.Number)
+	if err != nil {
+		return nil, fmt.Errorf("getting set id of start block: %w", err)
+	}
+
+	currentSetID, err := p.grandpaState.GetCurrentSetID()
+	if err != nil {
+		return nil, fmt.Errorf("getting current set id: %w", err)
+	}
+
+	proof := &WarpSyncProof{}
+	proofSize := 0
+	limitReached := false
+	for setID := startSetID + 1; setID <= currentSetID; setID++ {
+		changeNumber, err := p.grandpaState.GetSetIDChange(setID)
+		if err != nil {
+			return nil, fmt.Errorf("getting block number of set id %d change: %w", setID, err)
+		}
+
+		header, err := p.blockState.GetHeaderByNumber(changeNumber)
+		if err != nil {
+			return nil, fmt.Errorf("getting header of set id %d change: %w", setID, err)
+		}
+
+		scheduledChange, err := findScheduledChange(header)
+		if err != nil {
+			return nil, fmt.Errorf("finding scheduled change: %w", err)
+		}
+
+		if scheduledChange == nil {
+			// the authority set changed through a forced change, so the chain of
+			// trust between the authority sets is broken and the proof stops here.
+			break
+		}
+
+		fragment, err := p.newFragment(header)
+		if err != nil {
+			return nil, fmt.Errorf("creating fragment for set id %d change: %w", setID, err)
+		}
+
+		encodedFragment, err := scale.Marshal(*fragment)
+		if err != nil {
+			return nil, fmt.Errorf("encoding fragment: %w", err)
+		}
+
+		if proofSize+len(encodedFragment) >= maxWarpSyncProofSize {
+			limitReached = true
+			break
+		}
+
+		proofSize += len(encodedFragment)
+		proof.Fragments = append(proof.Fragments, *fragment)
+	}
+
+	if !limitReached {
+		lastNumber := startHeader.Number
+		if len(proof.Fragments) > 0 {
+			lastNumber = proof.Fragments[len(proof.Fragments)-1].Header.Number
+		}
+
+		if finalisedHeader.Number > lastNumber {
+			fragment, err := p.newFragment(finalisedHeader)
+			switch {
+			case errors.Is(err, ErrNoJustification):
+			case err != nil:
+				return nil, fmt.Errorf("creating fragment for highest finalised block: %w", err)
+			default:
+				proof.Fragments = append(proof.Fragments, *fragment)
+			}
+		}
+
+		proof.IsFinished = true
+	}
+
+	return proof.Encode()
+}
+
+// newFragment returns the warp sync fragment for the header, using its stored justification.
+func (p *WarpSyncProofProvider) newFragment(header *types.Header) (*WarpSyncFragment, error) {
+	hash := header.Hash()
+	encodedJustification, err := p.blockState.GetJustification(hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: for block %s: %s", ErrNoJustification, hash, err)
+	}
+
+	reader := bytes.NewReader(encodedJustification)
+	decoder := scale.NewDecoder(reader)
+	justification, err := decodeWarpSyncJustification(decoder, false)
+	if err != nil {
+		return nil, fmt.Errorf("decoding justification: %w", err)
+	}
+
+	// justifications received from peers include the votes ancestries,
+	// unlike the justifications created by this node.
+	if reader.Len() > 0 {
+		justification, err = decodeWarpSyncJustification(
+			scale.NewDecoder(bytes.NewReader(encodedJustification)), true)
+		if err != nil {
+			return nil, fmt.Errorf("decoding justification: %w", err)
+		}
+	}
+
+	return &WarpSyncFragment{
+		Header:        *header,
+		Justification: *justification,
+	}, nil
+}
+
+// Verify verifies the SCALE encoded warp sync proof, starting from the given authority set.
+// It returns the header of the last fragment of the proof, with the authority set for its descendants.
+func (*WarpSyncProofProvider) Verify(encodedProof []byte, setID uint64,
+	authorities []types.GrandpaVoter) (*WarpSyncVerificationResult, error) {
+	proof := new(WarpSyncProof)
+	err := proof.Decode(encodedProof)
+	if err != nil {
+		return nil, fmt.Errorf("decoding warp sync proof: %w", err)
+	}
+
+	if len(proof.Fragments) == 0 {
+		return nil, ErrEmptyWarpSyncProof
+	}
+
+	for i, fragment := range proof.Fragments {
+		fragment := fragment
+		err = verifyWarpSyncJustification(&fragment.Header, &fragment.Justification, setID, authorities)
+		if err != nil {
+			return nil, fmt.Errorf("verifying justification of fragment %d: %w", i, err)
+		}
+
+		scheduledChange, err := findScheduledChange(&fragment.Header)
+		if err != nil {
+			return nil, fmt.Errorf("finding scheduled change of fragment %d: %w", i, err)
+		}
+
+		if scheduledChange == nil {
+			if i != len(proof.Fragments)-1 {
+				return nil, fmt.Errorf("%w: in header of fragment %d", errMissingScheduledChange, i)
+			}
+			continue
+		}
+
+		authorities, err = types.NewGrandpaVotersFromAuthoritiesRaw(scheduledChange.Auths)
+		if err != nil {
+			return nil, fmt.Errorf("decoding authorities of fragment %d: %w", i, err)
+		}
+		setID++
+	}
+
+	return &WarpSyncVerificationResult{
+		Header:      proof.Fragments[len(proof.Fragments)-1].Header,
+		SetID:       setID,
+		Authorities: authorities,
+		Completed:   proof.IsFinished,
+	}, nil
+}
+
+// verifyWarpSyncJustification verifies the justification finalises the header,
+// with precommits signed by more than two thirds of the given authorities.
+func verifyWarpSyncJustification(header *types.Header, justification *WarpSyncJustification,
+	setID uint64, authorities []types.GrandpaVoter) error {
+	commit := justification.Commit
+	if commit.Hash != header.Hash() || uint(commit.Number) != header.Number {
+		return fmt.Errorf("%w: justification for block #%d (%s) and header for block #%d (%s)",
+			ErrJustificationMismatch, commit.Number, commit.Hash, header.Number, header.Hash())
+	}
+
+	ancestries := make(map[common.Hash]*types.Header, len(justification.VotesAncestries))
+	for i := range justification.VotesAncestries {
+		ancestry := &justification.VotesAncestries[i]
+		ancestries[ancestry.Hash()] = ancestry
+	}
+
+	signers := make(map[ed25519.PublicKeyBytes]struct{}, len(commit.Precommits))
+	for _, signedVote := range commit.Precommits {
+		if !isDescendantInAncestries(commit.Hash, signedVote.Vote.Hash, ancestries) {
+			return fmt.Errorf("%w: precommit for block %s", ErrPrecommitBlockMismatch, signedVote.Vote.Hash)
+		}
+
+		publicKey, err := ed25519.NewPublicKey(signedVote.AuthorityID[:])
+		if err != nil {
+			return fmt.Errorf("decoding authority public key: %w", err)
+		}
+
+		if !isInAuthSet(publicKey, authorities) {
+			return fmt.Errorf("%w: %s", ErrAuthorityNotInSet, publicKey.Hex())
+		}
+
+		message, err := scale.Marshal(FullVote{
+			Stage: precommit,
+			Vote:  signedVote.Vote,
+			Round: justification.Round,
+			SetID: setID,
+		})
+		if err != nil {
+			return fmt.Errorf("encoding precommit: %w", err)
+		}
+
+		ok, err := publicKey.Verify(message, signedVote.Signature[:])
+		if err != nil {
+			return fmt.Errorf("verifying precommit signature: %w", err)
+		}
+
+		if !ok {
+			return fmt.Errorf("%w: precommit from %s", ErrInvalidSignature, publicKey.Hex())
+		}
+
+		signers[signedVote.AuthorityID] = struct{}{}
+	}
+
+	// a justification needs precommits from more than two thirds of the authorities
+	threshold := 2 * len(authorities) / 3
+	if len(signers) <= threshold {
+		return fmt.Errorf("%w: %d signers for %d authorities", ErrMinVotesNotMet, len(signers), len(authorities))
+	}
+
+	return nil
+}
+
+// isDescendantInAncestries returns true if the descendant block is the ancestor
+// block or descends from it through the given ancestries, false otherwise.
+func isDescendantInAncestries(ancestor, descendant common.Hash,
+	ancestries map[common.Hash]*types.Header) bool {
+	for hash := descendant; ; {
+		if hash == ancestor {
+			return true
+		}
+
+		header, ok := ancestries[hash]
+		if !ok {
+			return false
+		}
+		hash = header.ParentHash
+	}
+}
+
+// findScheduledChange returns the GRANDPA scheduled change found in the
+// digest of the header, or nil if the header has no scheduled change.
+func findScheduledChange(header *types.Header) (*types.GrandpaScheduledChange, error) {
+	for _, digestItem := range header.Digest.Types {
+		digestValue, err := digestItem.Value()
+		if err != nil {
+			return nil, fmt.Errorf("getting digest value: %w", err)
+		}
+
+		consensusDigest, ok := digestValue.(types.ConsensusDigest)
+		if !ok || consensusDigest.ConsensusEngineID != types.GrandpaEngineID {
+			continue
+		}
+
+		grandpaDigest := types.NewGrandpaConsensusDigest()
+		err = scale.Unmarshal(consensusDigest.Data, &grandpaDigest)
+		if err != nil {
+			return nil, fmt.Errorf("decoding GRANDPA consensus digest: %w", err)
+		}
+
+		grandpaDigestValue, err := grandpaDigest.Value()
+		if err != nil {
+			return nil, fmt.Errorf("getting GRANDPA consensus digest value: %w", err)
+		}
+
+		scheduledChange, ok := grandpaDigestValue.(types.GrandpaScheduledChange)
+		if ok {
+			return &scheduledChange, nil
+		}
+	}
+
+	return nil, nil //nolint:nilnil
+}
@@ -0,0 +1,286 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package grandpa
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/crypto/ed25519"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestScheduledChangeHeader(t *testing.T, parent *types.Header,
+	keypairs []*ed25519.Keypair) *types.Header {
+	t.Helper()
+
+	auths := make([]types.GrandpaAuthoritiesRaw, len(keypairs))
+	for i, kp := range keypairs {
+		auths[i] = types.GrandpaAuthoritiesRaw{
+			Key: kp.Public().(*ed25519.PublicKey).AsBytes(),
+			ID:  uint64(i),
+		}
+	}
+
+	grandpaDigest := types.NewGrandpaConsensusDigest()
+	err := grandpaDigest.Set(types.GrandpaScheduledChange{Auths: auths})
+	require.NoError(t, err)
+
+	digest := types.NewDigest()
+	err = digest.Add(types.ConsensusDigest{
+		ConsensusEngineID: types.GrandpaEngineID,
+		Data:              scale.MustMarshal(grandpaDigest),
+	})
+	require.NoError(t, err)
+
+	return types.NewHeader(parent.Hash(), common.Hash{}, common.Hash{}, parent.Number+1, digest)
+}
+
+func newTestWarpSyncVoters(keypairs []*ed25519.Keypair) []types.GrandpaVoter {
+	voters := make([]types.GrandpaVoter, len(keypairs))
+	for i, kp := range keypairs {
+		voters[i] = types.GrandpaVoter{
+			Key: *kp.Public().(*ed25519.PublicKey),
+			ID:  uint64(i),
+		}
+	}
+	return voters
+}
+
+func newTestJustification(t *testing.T, header *types.Header, round, setID uint64,
+	keypairs []*ed25519.Keypair) Justification {
+	t.Helper()
+
+	vote := Vote{Hash: header.Hash(), Number: uint32(header.Number)}
+	message := scale.MustMarshal(FullVote{
+		Stage: precommit,
+		Vote:  vote,
+		Round: round,
+		SetID: setID,
+	})
+
+	precommits := make([]SignedVote, len(keypairs))
+	for i, kp := range keypairs {
+		signature, err := kp.Sign(message)
+		require.NoError(t, err)
+		precommits[i] = SignedVote{
+			Vote:        vote,
+			AuthorityID: kp.Public().(*ed25519.PublicKey).AsBytes(),
+		}
+		copy(precommits[i].Signature[:], signature)
+	}
+
+	return Justification{
+		Round: round,
+		Commit: Commit{
+			Hash:       header.Hash(),
+			Number:     uint32(header.Number),
+			Precommits: precommits,
+		},
+	}
+}
+
+func Test_WarpSyncProof_EncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	kr, err := keystore.NewEd25519Keyring()
+	require.NoError(t, err)
+	keypairs := kr.Keys[:3]
+
+	genesisHeader := types.NewHeader(common.Hash{}, common.Hash{}, common.Hash{}, 0, types.NewDigest())
+	header := newTestScheduledChangeHeader(t, genesisHeader, keypairs)
+	ancestry := types.NewHeader(header.Hash(), common.Hash{1}, common.Hash{}, header.Number+1, types.NewDigest())
+	justification := newTestJustification(t, header, 1, 0, keypairs)
+
+	proof := &WarpSyncProof{
+		Fragments: []WarpSyncFragment{{
+			Header: *header,
+			Justification: WarpSyncJustification{
+				Round:           justification.Round,
+				Commit:          justification.Commit,
+				VotesAncestries: []types.Header{*ancestry},
+			},
+		}},
+		IsFinished: true,
+	}
+
+	encoded, err := proof.Encode()
+	require.NoError(t, err)
+
+	decoded := new(WarpSyncProof)
+	err = decoded.Decode(encoded)
+	require.NoError(t, err)
+
+	require.Len(t, decoded.Fragments, 1)
+	fragment := decoded.Fragments[0]
+	assert.Equal(t, header.Hash(), fragment.Header.Hash())
+	assert.Equal(t, justification.Round, fragment.Justification.Round)
+	assert.Equal(t, justification.Commit, fragment.Justification.Commit)
+	require.Len(t, fragment.Justification.VotesAncestries, 1)
+	assert.Equal(t, ancestry.Hash(), fragment.Justification.VotesAncestries[0].Hash())
+	assert.True(t, decoded.IsFinished)
+
+	err = decoded.Decode(encoded[:len(encoded)-1])
+	assert.EqualError(t, err, "decoding is finished: EOF")
+}
+
+func Test_WarpSyncProofProvider_GenerateAndVerify(t *testing.T) {
+	t.Parallel()
+
+	kr, err := keystore.NewEd25519Keyring()
+	require.NoError(t, err)
+	genesisAuthorities := kr.Keys[:3]
+	nextAuthorities := kr.Keys[3:6]
+
+	genesisHeader := types.NewHeader(common.Hash{}, common.Hash{}, common.Hash{}, 0, types.NewDigest())
+	changeHeader := newTestScheduledChangeHeader(t, genesisHeader, nextAuthorities)
+	finalisedHeader := types.NewHeader(changeHeader.Hash(), common.Hash{}, common.Hash{},
+		changeHeader.Number+1, types.NewDigest())
+
+	changeJustification := newTestJustification(t, changeHeader, 1, 0, genesisAuthorities)
+	finalisedJustification := newTestJustification(t, finalisedHeader, 1, 1, nextAuthorities)
+
+	ctrl := gomock.NewController(t)
+	blockState := NewMockBlockState(ctrl)
+	blockState.EXPECT().GetHeader(genesisHeader.Hash()).Return(genesisHeader, nil)
+	blockState.EXPECT().GetHighestFinalisedHeader().Return(finalisedHeader, nil)
+	blockState.EXPECT().GetHeaderByNumber(uint(0)).Return(genesisHeader, nil)
+	blockState.EXPECT().GetHeaderByNumber(uint(1)).Return(changeHeader, nil)
+	blockState.EXPECT().GetJustification(changeHeader.Hash()).
+		Return(scale.MustMarshal(changeJustification), nil)
+	blockState.EXPECT().GetJustification(finalisedHeader.Hash()).
+		Return(scale.MustMarshal(finalisedJustification), nil)
+
+	grandpaState := NewMockGrandpaState(ctrl)
+	grandpaState.EXPECT().GetSetIDByBlockNumber(uint(0)).Return(uint64(0), nil)
+	grandpaState.EXPECT().GetCurrentSetID().Return(uint64(1), nil)
+	grandpaState.EXPECT().GetSetIDChange(uint64(1)).Return(uint(1), nil)
+
+	provider := NewWarpSyncProofProvider(blockState, grandpaState)
+
+	encodedProof, err := provider.Generate(genesisHeader.Hash())
+	require.NoError(t, err)
+
+	result, err := provider.Verify(encodedProof, 0, newTestWarpSyncVoters(genesisAuthorities))
+	require.NoError(t, err)
+
+	assert.Equal(t, finalisedHeader.Hash(), result.Header.Hash())
+	assert.Equal(t, uint64(1), result.SetID)
+	assert.Equal(t, newTestWarpSyncVoters(nextAuthorities), result.Authorities)
+	assert.True(t, result.Completed)
+
+	_, err = provider.Verify(encodedProof, 0, newTestWarpSyncVoters(nextAuthorities))
+	assert.ErrorIs(t, err, ErrAuthorityNotInSet)
+}
+
+func Test_WarpSyncProofProvider_Generate_notFinalised(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	genesisHash := common.Hash{1}
+	forkHeader := types.NewHeader(genesisHash, common.Hash{1}, common.Hash{},
+		1, types.NewDigest())
+	canonicalHeader := types.NewHeader(genesisHash, common.Hash{2}, common.Hash{},
+		1, types.NewDigest())
+
+	blockState := NewMockBlockState(ctrl)
+	blockState.EXPECT().GetHeader(forkHeader.Hash()).Return(forkHeader, nil)
+	blockState.EXPECT().GetHighestFinalisedHeader().Return(canonicalHeader, nil)
+	blockState.EXPECT().GetHeaderByNumber(uint(1)).Return(canonicalHeader, nil)
+
+	provider := NewWarpSyncProofProvider(blockState, nil)
+
+	_, err := provider.Generate(forkHeader.Hash())
+	assert.ErrorIs(t, err, errStartBlockNotFinalised)
+}
+
+func Test_verifyWarpSyncJustification(t *testing.T) {
+	t.Parallel()
+
+	kr, err := keystore.NewEd25519Keyring()
+	require.NoError(t, err)
+	keypairs := kr.Keys[:4]
+	voters := newTestWarpSyncVoters(keypairs)
+
+	header := types.NewHeader(common.Hash{1}, common.Hash{}, common.Hash{}, 1, types.NewDigest())
+	child := types.NewHeader(header.Hash(), common.Hash{}, common.Hash{}, 2, types.NewDigest())
+
+	testCases := map[string]struct {
+		justification func(t *testing.T) *WarpSyncJustification
+		errWrapped    error
+	}{
+		"valid": {
+			justification: func(t *testing.T) *WarpSyncJustification {
+				justification := newTestJustification(t, header, 1, 0, keypairs)
+				return &WarpSyncJustification{Round: 1, Commit: justification.Commit}
+			},
+		},
+		"commit_mismatch": {
+			justification: func(t *testing.T) *WarpSyncJustification {
+				justification := newTestJustification(t, child, 1, 0, keypairs)
+				return &WarpSyncJustification{Round: 1, Commit: justification.Commit}
+			},
+			errWrapped: ErrJustificationMismatch,
+		},
+		"precommit_for_descendant_in_ancestries": {
+			justification: func(t *testing.T) *WarpSyncJustification {
+				justification := newTestJustification(t, child, 1, 0, keypairs)
+				justification.Commit.Hash = header.Hash()
+				justification.Commit.Number = uint32(header.Number)
+				return &WarpSyncJustification{
+					Round:           1,
+					Commit:          justification.Commit,
+					VotesAncestries: []types.Header{*child},
+				}
+			},
+		},
+		"precommit_for_unknown_block": {
+			justification: func(t *testing.T) *WarpSyncJustification {
+				justification := newTestJustification(t, child, 1, 0, keypairs)
+				justification.Commit.Hash = header.Hash()
+				justification.Commit.Number = uint32(header.Number)
+				return &WarpSyncJustification{Round: 1, Commit: justification.Commit}
+			},
+			errWrapped: ErrPrecommitBlockMismatch,
+		},
+		"invalid_signature": {
+			justification: func(t *testing.T) *WarpSyncJustification {
+				justification := newTestJustification(t, header, 2, 0, keypairs)
+				return &WarpSyncJustification{Round: 1, Commit: justification.Commit}
+			},
+			errWrapped: ErrInvalidSignature,
+		},
+		"not_enough_signers": {
+			justification: func(t *testing.T) *WarpSyncJustification {
+				justification := newTestJustification(t, header, 1, 0, keypairs[:2])
+				return &WarpSyncJustification{Round: 1, Commit: justification.Commit}
+			},
+			errWrapped: ErrMinVotesNotMet,
+		},
+		"duplicate_signers": {
+			justification: func(t *testing.T) *WarpSyncJustification {
+				justification := newTestJustification(t, header, 1, 0,
+					[]*ed25519.Keypair{keypairs[0], keypairs[0], keypairs[1], keypairs[1]})
+				return &WarpSyncJustification{Round: 1, Commit: justification.Commit}
+			},
+			errWrapped: ErrMinVotesNotMet,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := verifyWarpSyncJustification(header, testCase.justification(t), 0, voters)
+			assert.ErrorIs(t, err, testCase.errWrapped)
+		})
+	}
+}
@@ -279,6 +279,92 @@ func UnlockKeys(ks Inserter, dir, unlock, password string) error {
 	return nil
 }
 
+// sessionKeysDir returns the directory basepath/keystore/session where the
+// session keys are written, in a subdirectory named after their keystore.
+func sessionKeysDir(basepath string) (string, error) {
+	keyPath, err := utils.KeystoreDir(basepath)
+	if err != nil {
+		return "", fmt.Errorf("getting keystore directory: %w", err)
+	}
+	return filepath.Join(keyPath, "session"), nil
+}
+
+// WriteSessionKey saves the keypair of a session key of the keystore with the given
+// name to basepath/keystore/session/[name]/[public key].key in json format encrypted
+// using the specified password, and returns the resulting filepath.
+func WriteSessionKey(name Name, kp PublicPrivater, basepath string, password []byte) (string, error) {
+	dir, err := sessionKeysDir(basepath)
+	if err != nil {
+		return "", err
+	}
+
+	dir = filepath.Join(dir, string(name))
+	err = os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return "", fmt.Errorf("creating session keys directory: %w", err)
+	}
+
+	fp := filepath.Join(dir, hex.EncodeToString(kp.Public().Encode())+".key")
+	err = EncryptAndWriteToFile(fp, kp.Private(), password)
+	if err != nil {
+		return "", fmt.Errorf("writing key to file: %w", err)
+	}
+
+	return fp, nil
+}
+
+// LoadSessionKeys decrypts the session keys written by WriteSessionKey in the
+// basepath using the specified password, and inserts them in their keystore.
+func LoadSessionKeys(ks *GlobalKeystore, basepath string, password []byte) error {
+	dir, err := sessionKeysDir(basepath)
+	if err != nil {
+		return err
+	}
+
+	keystoreDirs, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("reading session keys directory: %w", err)
+	}
+
+	for _, keystoreDir := range keystoreDirs {
+		if !keystoreDir.IsDir() {
+			continue
+		}
+
+		name := keystoreDir.Name()
+		typedKeystore, err := ks.GetKeystore([]byte(name))
+		if err != nil {
+			return fmt.Errorf("getting keystore %s: %w", name, err)
+		}
+
+		keyFiles, err := filepath.Glob(filepath.Join(dir, name, "*.key"))
+		if err != nil {
+			return fmt.Errorf("listing %s session key files: %w", name, err)
+		}
+
+		for _, keyFile := range keyFiles {
+			priv, err := ReadFromFileAndDecrypt(keyFile, password)
+			if err != nil {
+				return fmt.Errorf("decrypting key file %s: %w", keyFile, err)
+			}
+
+			kp, err := PrivateKeyToKeypair(priv)
+			if err != nil {
+				return fmt.Errorf("creating keypair from key file %s: %w", keyFile, err)
+			}
+
+			err = typedKeystore.Insert(kp)
+			if err != nil {
+				return fmt.Errorf("inserting key in keystore %s: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // DetermineKeyType takes string as defined in https://github.com/w3f/PSPs/blob/psp-rpc-api/psp-002.md#Key-types
 // and returns the crypto.KeyType
 func DetermineKeyType(t string) crypto.KeyType {
@@ -288,6 +374,8 @@ func DetermineKeyType(t string) crypto.KeyType {
 	case "acco", "babe", "para", "asgn",
 		"aura", "imon", "audi", "dumy":
 		return crypto.Sr25519Type
+	case "beef":
+		return crypto.Secp256k1Type
 	}
 	return crypto.UnknownType
 }
@@ -307,6 +395,10 @@ func HasKey(pubKeyStr, keyType string, keystore AddressKeypairGetter) (bool, err
 		pubKey, err = sr25519.NewPublicKey(keyBytes)
 	case crypto.Ed25519Type:
 		pubKey, err = ed25519.NewPublicKey(keyBytes)
+	case crypto.Secp256k1Type:
+		secpPubKey := new(secp256k1.PublicKey)
+		err = secpPubKey.Decode(keyBytes)
+		pubKey = secpPubKey
 	default:
 		err = fmt.Errorf("unknown key type: %s", keyType)
 	}
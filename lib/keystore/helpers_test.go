@@ -51,6 +51,7 @@ func TestLoadKeystore(t *testing.T) {
 	{testType: "imon", expectedType: crypto.Sr25519Type},
 	{testType: "audi", expectedType: crypto.Sr25519Type},
 	{testType: "dumy", expectedType: crypto.Sr25519Type},
+	{testType: "beef", expectedType: crypto.Secp256k1Type},
 	{testType: "xxxx", expectedType: crypto.UnknownType},
 }
 
@@ -289,6 +290,44 @@ func TestUnlockKeys(t *testing.T) {
 	}
 }
 
+func TestWriteAndLoadSessionKeys(t *testing.T) {
+	testdir := t.TempDir()
+
+	babeKeypair, err := sr25519.GenerateKeypair()
+	require.NoError(t, err)
+	granKeypair, err := ed25519.GenerateKeypair()
+	require.NoError(t, err)
+
+	_, err = WriteSessionKey(BabeName, babeKeypair, testdir, testPassword)
+	require.NoError(t, err)
+	keyfile, err := WriteSessionKey(GranName, granKeypair, testdir, testPassword)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(testdir, "keystore", "session", "gran",
+		granKeypair.Public().Hex()[2:]+".key"), keyfile)
+
+	// session keys must not be listed with the keys to unlock
+	keys, err := utils.KeystoreFiles(testdir)
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	ks := NewGlobalKeystore()
+	err = LoadSessionKeys(ks, testdir, []byte("wrong"))
+	require.Error(t, err)
+
+	err = LoadSessionKeys(ks, testdir, testPassword)
+	require.NoError(t, err)
+	require.Equal(t, []crypto.PublicKey{babeKeypair.Public()}, ks.Babe.PublicKeys())
+	require.Equal(t, []crypto.PublicKey{granKeypair.Public()}, ks.Gran.PublicKeys())
+	require.Zero(t, ks.Acco.Size())
+}
+
+func TestLoadSessionKeys_NoSessionKeys(t *testing.T) {
+	ks := NewGlobalKeystore()
+	err := LoadSessionKeys(ks, t.TempDir(), testPassword)
+	require.NoError(t, err)
+	require.Zero(t, ks.Babe.Size())
+}
+
 func TestImportRawPrivateKey_NoType(t *testing.T) {
 	testdir := t.TempDir()
 
@@ -28,6 +28,7 @@
 	AsgnName Name = "asgn"
 	AudiName Name = "audi"
 	DumyName Name = "dumy"
+	BeefName Name = "beef"
 )
 
 // Keystore provides key management functionality
@@ -69,6 +70,7 @@ type GlobalKeystore struct {
 	Imon Keystore
 	Audi Keystore
 	Dumy Keystore
+	Beef Keystore
 }
 
 // NewGlobalKeystore returns a new GlobalKeystore
@@ -83,6 +85,7 @@ func NewGlobalKeystore() *GlobalKeystore {
 		Imon: NewBasicKeystore(ImonName, crypto.Sr25519Type),
 		Audi: NewBasicKeystore(AudiName, crypto.Sr25519Type),
 		Dumy: NewGenericKeystore(DumyName),
+		Beef: NewBasicKeystore(BeefName, crypto.Secp256k1Type),
 	}
 }
 
@@ -108,6 +111,8 @@ func (k *GlobalKeystore) GetKeystore(name []byte) (Keystore, error) {
 		return k.Audi, nil
 	case DumyName:
 		return k.Dumy, nil
+	case BeefName:
+		return k.Beef, nil
 	default:
 		return nil, ErrInvalidKeystoreName
 	}
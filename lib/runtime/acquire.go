@@ -0,0 +1,43 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"errors"
+	"fmt"
+)
+
+// InstanceAcquirer is implemented by runtimes able to provide instances
+// for exclusive use, from a pool of instances sharing the same code or
+// instantiated on demand, such that runtime calls can run concurrently.
+type InstanceAcquirer interface {
+	Acquire() (instance Instance, release func(), err error)
+}
+
+// ErrNotRuntimeInstance is returned when the runtime given does not
+// implement the runtime Instance interface.
+var ErrNotRuntimeInstance = errors.New("runtime does not implement the instance interface")
+
+// AcquireInstance returns an instance from the instance pool of the runtime
+// given if it implements InstanceAcquirer, and the runtime itself otherwise.
+// The release function returned must be called once done with the instance,
+// and the instance must not be used after it is released.
+// Note the storage of the instance must be set with SetContextStorage
+// before each use, since it is reset when the instance is released.
+func AcquireInstance(rt any) (instance Instance, release func(), err error) {
+	acquirer, ok := rt.(InstanceAcquirer)
+	if ok {
+		instance, release, err = acquirer.Acquire()
+		if err != nil {
+			return nil, nil, fmt.Errorf("acquiring runtime instance: %w", err)
+		}
+		return instance, release, nil
+	}
+
+	instance, ok = rt.(Instance)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %T", ErrNotRuntimeInstance, rt)
+	}
+	return instance, func() {}, nil
+}
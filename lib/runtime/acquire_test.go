@@ -0,0 +1,83 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testInstance struct {
+	Instance
+	released bool
+}
+
+type testAcquirer struct {
+	instance *testInstance
+	err      error
+}
+
+func (a *testAcquirer) Acquire() (instance Instance, release func(), err error) {
+	if a.err != nil {
+		return nil, nil, a.err
+	}
+	return a.instance, func() { a.instance.released = true }, nil
+}
+
+func Test_AcquireInstance(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+	pooledInstance := &testInstance{}
+	plainInstance := &testInstance{}
+
+	testCases := map[string]struct {
+		rt               any
+		instance         Instance
+		errWrapped       error
+		errMessage       string
+		expectedReleased bool
+	}{
+		"not_an_instance": {
+			rt:         struct{}{},
+			errWrapped: ErrNotRuntimeInstance,
+			errMessage: "runtime does not implement the instance interface: struct {}",
+		},
+		"plain_instance": {
+			rt:       plainInstance,
+			instance: plainInstance,
+		},
+		"acquire_error": {
+			rt:         &testAcquirer{err: errTest},
+			errWrapped: errTest,
+			errMessage: "acquiring runtime instance: test error",
+		},
+		"pooled_instance": {
+			rt:               &testAcquirer{instance: pooledInstance},
+			instance:         pooledInstance,
+			expectedReleased: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			instance, release, err := AcquireInstance(testCase.rt)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+				return
+			}
+			assert.Same(t, testCase.instance, instance)
+
+			release()
+			assert.Equal(t, testCase.expectedReleased, instance.(*testInstance).released)
+		})
+	}
+}
@@ -76,6 +76,11 @@
 	// BabeAPISubmitReportEquivocationUnsignedExtrinsic is the runtime API call
 	// BabeApi_submit_report_equivocation_unsigned_extrinsic
 	BabeAPISubmitReportEquivocationUnsignedExtrinsic = "BabeApi_submit_report_equivocation_unsigned_extrinsic"
+	// GrandpaAPIGenerateKeyOwnershipProof is the runtime API call GrandpaApi_generate_key_ownership_proof
+	GrandpaAPIGenerateKeyOwnershipProof = "GrandpaApi_generate_key_ownership_proof"
+	// GrandpaAPISubmitReportEquivocationUnsignedExtrinsic is the runtime API call
+	// GrandpaApi_submit_report_equivocation_unsigned_extrinsic
+	GrandpaAPISubmitReportEquivocationUnsignedExtrinsic = "GrandpaApi_submit_report_equivocation_unsigned_extrinsic"
 	// BabeAPIConfiguration is the runtime API call BabeApi_configuration
 	BabeAPIConfiguration = "BabeApi_configuration"
 	// BlockBuilderInherentExtrinsics is the runtime API call BlockBuilder_inherent_extrinsics
@@ -86,10 +91,14 @@
 	BlockBuilderFinalizeBlock = "BlockBuilder_finalize_block"
 	// DecodeSessionKeys is the runtime API call SessionKeys_decode_session_keys
 	DecodeSessionKeys = "SessionKeys_decode_session_keys"
+	// SessionKeysGenerateSessionKeys is the runtime API call SessionKeys_generate_session_keys
+	SessionKeysGenerateSessionKeys = "SessionKeys_generate_session_keys"
 	// TransactionPaymentAPIQueryInfo returns information of a given extrinsic
 	TransactionPaymentAPIQueryInfo = "TransactionPaymentApi_query_info"
 	// TransactionPaymentCallAPIQueryCallInfo returns call query call info
 	TransactionPaymentCallAPIQueryCallInfo = "TransactionPaymentCallApi_query_call_info"
 	// TransactionPaymentCallAPIQueryCallFeeDetails returns call query call fee details
 	TransactionPaymentCallAPIQueryCallFeeDetails = "TransactionPaymentCallApi_query_call_fee_details"
+	// OffchainWorkerAPIOffchainWorker is the runtime API call OffchainWorkerApi_offchain_worker
+	OffchainWorkerAPIOffchainWorker = "OffchainWorkerApi_offchain_worker"
 )
@@ -32,6 +32,16 @@ type Storage interface {
 	BeginStorageTransaction()
 	CommitStorageTransaction()
 	RollbackStorageTransaction()
+	SetVersion(version trie.Version)
+	IndexTransaction(extrinsic, size uint32, hash common.Hash)
+	RenewTransaction(extrinsic uint32, hash common.Hash)
+}
+
+// LogRecorder records the messages logged by the runtime.
+// The runtime context storage can optionally implement it
+// to record the runtime log messages, for example for tracing.
+type LogRecorder interface {
+	RecordLog(level int32, target, message string)
 }
 
 // BasicNetwork interface for functions used by runtime network state function
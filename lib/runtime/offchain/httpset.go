@@ -4,12 +4,16 @@
 package offchain
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 type contextKey string
@@ -29,6 +33,37 @@
 	errInvalidHeaderKey      = errors.New("invalid header key")
 )
 
+var (
+	// ErrDeadlineReached is returned when the deadline of an HTTP operation is reached.
+	ErrDeadlineReached = errors.New("deadline reached")
+	// ErrIO is returned when an HTTP request fails because of an I/O error,
+	// for example if the connection to the remote server is closed.
+	ErrIO = errors.New("io error")
+	// ErrInvalidRequest is returned when the request id is unknown,
+	// or if the operation is not allowed in the current state of the request.
+	ErrInvalidRequest = errors.New("invalid request")
+)
+
+// HTTP errors codes, as defined by the runtime HttpError enum.
+const (
+	httpErrorDeadlineReached byte = iota
+	httpErrorIO
+	httpErrorInvalid
+)
+
+// HTTPErrorCode returns the runtime HttpError enum code corresponding
+// to the given HTTP operation error.
+func HTTPErrorCode(err error) byte {
+	switch {
+	case errors.Is(err, ErrDeadlineReached):
+		return httpErrorDeadlineReached
+	case errors.Is(err, ErrIO):
+		return httpErrorIO
+	default:
+		return httpErrorInvalid
+	}
+}
+
 // requestIDBuffer created to control the amount of available non-duplicated ids
 type requestIDBuffer chan int16
 
@@ -64,6 +99,16 @@ func (b requestIDBuffer) put(i int16) error {
 // the request starts or is waiting to be read
 type Request struct {
 	Request *http.Request
+
+	// body is the request body written so far.
+	body bytes.Buffer
+	// done is nil until the request is sent, and is then
+	// closed once the response or error is received.
+	done chan struct{}
+	// cancel cancels the request context once the request is sent.
+	cancel   context.CancelFunc
+	response *http.Response
+	err      error
 }
 
 // AddHeader adds a new HTTP header into request property, only if request is valid
@@ -73,6 +118,10 @@ func (r *Request) AddHeader(name, value string) error {
 		return errRequestInvalid
 	}
 
+	if r.sent() {
+		return fmt.Errorf("%w: request is already sent", errRequestInvalid)
+	}
+
 	name = strings.TrimSpace(name)
 	if len(name) == 0 {
 		return fmt.Errorf("%w: empty header key", errInvalidHeaderKey)
@@ -82,20 +131,97 @@ func (r *Request) AddHeader(name, value string) error {
 	return nil
 }
 
+func (r *Request) sent() bool {
+	return r.done != nil
+}
+
+// send sends the request with the body written so far using the given client.
+// The response is received in the background, and done is closed once received.
+func (r *Request) send(client *http.Client) {
+	body := r.body.Bytes()
+	r.Request.ContentLength = int64(len(body))
+	if len(body) > 0 {
+		r.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	ctx, cancel := context.WithCancel(r.Request.Context())
+	r.Request = r.Request.WithContext(ctx)
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go func(request *http.Request) {
+		defer close(r.done)
+		response, err := client.Do(request) //nolint:bodyclose
+		if err != nil {
+			r.err = fmt.Errorf("%w: %s", ErrIO, err)
+			return
+		}
+		r.response = response
+	}(r.Request)
+}
+
+// wait waits for the response of a sent request until the deadline.
+// A zero deadline means there is no deadline.
+func (r *Request) wait(deadline time.Time) error {
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-r.done:
+		return r.err
+	case <-timeout:
+		return ErrDeadlineReached
+	}
+}
+
+// close cancels the request and closes its response body, if any.
+func (r *Request) close() {
+	if !r.sent() {
+		return
+	}
+
+	r.cancel()
+	<-r.done
+	if r.response != nil {
+		_ = r.response.Body.Close()
+	}
+}
+
+// HTTPHeader is an HTTP header name and value pair.
+type HTTPHeader struct {
+	Name  []byte
+	Value []byte
+}
+
+// HTTPRequestStatus is the status of a request after waiting for its response.
+type HTTPRequestStatus struct {
+	// StatusCode is the HTTP status code of the response, if Err is nil.
+	StatusCode uint16
+	// Err is nil if the response is received, otherwise it wraps one of
+	// ErrDeadlineReached, ErrIO or ErrInvalidRequest.
+	Err error
+}
+
 // HTTPSet holds a pool of concurrent http request calls
 type HTTPSet struct {
 	*sync.Mutex
 	reqs   map[int16]*Request
 	idBuff requestIDBuffer
+	client *http.Client
 }
 
 // NewHTTPSet creates a offchain http set that can be used
 // by runtime as HTTP clients, the max concurrent requests is 1000
 func NewHTTPSet() *HTTPSet {
 	return &HTTPSet{
-		new(sync.Mutex),
-		make(map[int16]*Request),
-		newIntBuffer(maxConcurrentRequests),
+		Mutex:  new(sync.Mutex),
+		reqs:   make(map[int16]*Request),
+		idBuff: newIntBuffer(maxConcurrentRequests),
+		client: &http.Client{},
 	}
 }
 
@@ -105,6 +231,12 @@ func (p *HTTPSet) StartRequest(method, uri string) (int16, error) {
 	p.Lock()
 	defer p.Unlock()
 
+	req, err := http.NewRequest(method, uri, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header = make(http.Header)
+
 	id, err := p.idBuff.get()
 	if err != nil {
 		return 0, err
@@ -114,18 +246,11 @@ func (p *HTTPSet) StartRequest(method, uri string) (int16, error) {
 		return 0, errRequestIDNotAvailable
 	}
 
-	req, err := http.NewRequest(method, uri, nil)
-	req.Header = make(http.Header)
-
 	ctx := context.WithValue(req.Context(), waitingKey, false)
 	ctx = context.WithValue(ctx, invalidKey, false)
 
 	req = req.WithContext(ctx)
 
-	if err != nil {
-		return 0, err
-	}
-
 	p.reqs[id] = &Request{
 		Request: req,
 	}
@@ -138,6 +263,11 @@ func (p *HTTPSet) Remove(id int16) error {
 	p.Lock()
 	defer p.Unlock()
 
+	req, ok := p.reqs[id]
+	if ok {
+		req.close()
+	}
+
 	delete(p.reqs, id)
 
 	return p.idBuff.put(id)
@@ -150,3 +280,173 @@ func (p *HTTPSet) Get(id int16) *Request {
 
 	return p.reqs[id]
 }
+
+// remove removes the request once it can no longer be used.
+// The id buffer cannot be full since the id was taken from it,
+// so the error from Remove is ignored.
+func (p *HTTPSet) remove(id int16) {
+	_ = p.Remove(id)
+}
+
+// WriteBody appends the chunk to the body of the request. An empty chunk
+// marks the end of the body and sends the request. Writing is not allowed
+// once the request is sent. A zero deadline means there is no deadline.
+func (p *HTTPSet) WriteBody(id int16, chunk []byte, deadline time.Time) error {
+	req := p.Get(id)
+	if req == nil {
+		return fmt.Errorf("%w: unknown request id %d", ErrInvalidRequest, id)
+	}
+
+	if req.sent() {
+		return fmt.Errorf("%w: request id %d is already sent", ErrInvalidRequest, id)
+	}
+
+	if !deadline.IsZero() && !time.Now().Before(deadline) {
+		return ErrDeadlineReached
+	}
+
+	if len(chunk) == 0 {
+		req.send(p.client)
+		return nil
+	}
+
+	_, _ = req.body.Write(chunk) // bytes.Buffer Write never returns an error
+	return nil
+}
+
+// WaitResponses sends the requests not sent yet, and waits for the responses
+// of the given requests until the deadline. It returns the status of each
+// request, in the same order as the request ids given.
+// A zero deadline means there is no deadline.
+func (p *HTTPSet) WaitResponses(ids []int16, deadline time.Time) (statuses []HTTPRequestStatus) {
+	statuses = make([]HTTPRequestStatus, len(ids))
+	for i, id := range ids {
+		req := p.Get(id)
+		if req == nil {
+			statuses[i].Err = fmt.Errorf("%w: unknown request id %d", ErrInvalidRequest, id)
+			continue
+		}
+
+		if !req.sent() {
+			req.send(p.client)
+		}
+
+		err := req.wait(deadline)
+		switch {
+		case errors.Is(err, ErrDeadlineReached):
+			statuses[i].Err = err
+		case err != nil:
+			statuses[i].Err = err
+			p.remove(id)
+		default:
+			statuses[i].StatusCode = uint16(req.response.StatusCode)
+		}
+	}
+
+	return statuses
+}
+
+// ResponseHeaders returns the headers of the response of the request, sorted by name.
+// It returns no header if the request is unknown or its response is not received yet.
+func (p *HTTPSet) ResponseHeaders(id int16) (headers []HTTPHeader) {
+	req := p.Get(id)
+	if req == nil || !req.sent() {
+		return nil
+	}
+
+	select {
+	case <-req.done:
+	default:
+		return nil
+	}
+
+	if req.response == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(req.response.Header))
+	for name := range req.response.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range req.response.Header[name] {
+			headers = append(headers, HTTPHeader{
+				Name:  []byte(name),
+				Value: []byte(value),
+			})
+		}
+	}
+
+	return headers
+}
+
+// ReadResponseBody reads the response body of the request into the buffer,
+// waiting for the response if needed, and returns the number of bytes read.
+// Zero bytes read means the body is fully read, and the request is then removed.
+// The request is also removed if an error is returned, since it can no longer be used.
+// A zero deadline means there is no deadline.
+func (p *HTTPSet) ReadResponseBody(id int16, buffer []byte, deadline time.Time) (n int, err error) {
+	req := p.Get(id)
+	if req == nil {
+		return 0, fmt.Errorf("%w: unknown request id %d", ErrInvalidRequest, id)
+	}
+
+	if !req.sent() {
+		return 0, fmt.Errorf("%w: request id %d is not sent", ErrInvalidRequest, id)
+	}
+
+	defer func() {
+		if n == 0 {
+			p.remove(id)
+		}
+	}()
+
+	err = req.wait(deadline)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(buffer) == 0 {
+		return 0, nil
+	}
+
+	type readResult struct {
+		n   int
+		err error
+	}
+	results := make(chan readResult, 1)
+	go func() {
+		var result readResult
+		for result.n == 0 && result.err == nil {
+			result.n, result.err = req.response.Body.Read(buffer)
+		}
+		results <- result
+	}()
+
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case result := <-results:
+		switch {
+		case result.n > 0:
+			return result.n, nil
+		case errors.Is(result.err, io.EOF):
+			return 0, nil
+		default:
+			return 0, fmt.Errorf("%w: %s", ErrIO, result.err)
+		}
+	case <-timeout:
+		// cancelling the request makes the pending read return,
+		// so wait for it to not write into the buffer afterwards.
+		req.cancel()
+		<-results
+		return 0, ErrDeadlineReached
+	}
+}
@@ -5,10 +5,15 @@
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -64,7 +69,7 @@ func TestOffchainRequest_AddHeader(t *testing.T) {
 		headerK, headerV string
 	}{
 		"should_return_invalid_request": {
-			offReq: Request{invalidReq},
+			offReq: Request{Request: invalidReq},
 			err:    errRequestInvalid,
 		},
 		"should_add_header": {
@@ -101,3 +106,195 @@ func TestOffchainRequest_AddHeader(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPErrorCode(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		err  error
+		code byte
+	}{
+		"deadline_reached": {
+			err:  fmt.Errorf("wrapped: %w", ErrDeadlineReached),
+			code: httpErrorDeadlineReached,
+		},
+		"io_error": {
+			err:  fmt.Errorf("wrapped: %w", ErrIO),
+			code: httpErrorIO,
+		},
+		"invalid_request": {
+			err:  ErrInvalidRequest,
+			code: httpErrorInvalid,
+		},
+		"other_error": {
+			err:  errors.New("test error"),
+			code: httpErrorInvalid,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			code := HTTPErrorCode(testCase.err)
+
+			assert.Equal(t, testCase.code, code)
+		})
+	}
+}
+
+func TestHTTPSet_requestLifecycle(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Request-Header", r.Header.Get("X-Test"))
+		w.WriteHeader(http.StatusCreated)
+		_, err = w.Write(body)
+		assert.NoError(t, err)
+	}))
+	t.Cleanup(server.Close)
+
+	set := NewHTTPSet()
+
+	id, err := set.StartRequest(http.MethodPost, server.URL)
+	require.NoError(t, err)
+
+	err = set.Get(id).AddHeader("X-Test", "test value")
+	require.NoError(t, err)
+
+	err = set.WriteBody(id, []byte("hello "), time.Time{})
+	require.NoError(t, err)
+	err = set.WriteBody(id, []byte("world"), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	// an empty chunk ends the body and sends the request
+	err = set.WriteBody(id, nil, time.Time{})
+	require.NoError(t, err)
+
+	err = set.WriteBody(id, []byte("late"), time.Time{})
+	require.ErrorIs(t, err, ErrInvalidRequest)
+	err = set.Get(id).AddHeader("X-Late", "value")
+	require.ErrorIs(t, err, errRequestInvalid)
+
+	const unknownID int16 = 999
+	statuses := set.WaitResponses([]int16{id, unknownID}, time.Time{})
+	require.Len(t, statuses, 2)
+	assert.Equal(t, HTTPRequestStatus{StatusCode: http.StatusCreated}, statuses[0])
+	assert.ErrorIs(t, statuses[1].Err, ErrInvalidRequest)
+
+	headers := set.ResponseHeaders(id)
+	assert.Contains(t, headers, HTTPHeader{Name: []byte("X-Method"), Value: []byte("POST")})
+	assert.Contains(t, headers, HTTPHeader{Name: []byte("X-Request-Header"), Value: []byte("test value")})
+
+	var body []byte
+	buffer := make([]byte, 4)
+	for {
+		n, err := set.ReadResponseBody(id, buffer, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		if n == 0 {
+			break
+		}
+		body = append(body, buffer[:n]...)
+	}
+	assert.Equal(t, "hello world", string(body))
+
+	// the request is removed once its body is fully read
+	assert.Nil(t, set.Get(id))
+	_, err = set.ReadResponseBody(id, buffer, time.Time{})
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
+
+func TestHTTPSet_WaitResponses_deadlineReached(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	set := NewHTTPSet()
+
+	id, err := set.StartRequest(http.MethodGet, server.URL)
+	require.NoError(t, err)
+
+	// the request is sent by WaitResponses since no body was written
+	statuses := set.WaitResponses([]int16{id}, time.Now().Add(10*time.Millisecond))
+	require.Len(t, statuses, 1)
+	assert.ErrorIs(t, statuses[0].Err, ErrDeadlineReached)
+
+	// the request is still valid after the wait deadline is reached
+	require.NotNil(t, set.Get(id))
+	assert.Empty(t, set.ResponseHeaders(id))
+
+	err = set.Remove(id)
+	require.NoError(t, err)
+}
+
+func TestHTTPSet_WaitResponses_ioError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	set := NewHTTPSet()
+
+	id, err := set.StartRequest(http.MethodGet, server.URL)
+	require.NoError(t, err)
+
+	statuses := set.WaitResponses([]int16{id}, time.Time{})
+	require.Len(t, statuses, 1)
+	assert.ErrorIs(t, statuses[0].Err, ErrIO)
+
+	// the request is removed after an I/O error
+	assert.Nil(t, set.Get(id))
+}
+
+func TestHTTPSet_ReadResponseBody_deadlineReached(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	set := NewHTTPSet()
+
+	id, err := set.StartRequest(http.MethodGet, server.URL)
+	require.NoError(t, err)
+
+	statuses := set.WaitResponses([]int16{id}, time.Time{})
+	require.Len(t, statuses, 1)
+	require.Equal(t, HTTPRequestStatus{StatusCode: http.StatusOK}, statuses[0])
+
+	buffer := make([]byte, 4)
+	n, err := set.ReadResponseBody(id, buffer, time.Now().Add(10*time.Millisecond))
+	assert.ErrorIs(t, err, ErrDeadlineReached)
+	assert.Zero(t, n)
+
+	// the request is removed once the read deadline is reached
+	assert.Nil(t, set.Get(id))
+}
+
+func TestHTTPSet_ReadResponseBody_notSent(t *testing.T) {
+	t.Parallel()
+
+	set := NewHTTPSet()
+
+	id, err := set.StartRequest(http.MethodGet, defaultTestURI)
+	require.NoError(t, err)
+
+	_, err = set.ReadResponseBody(id, make([]byte, 1), time.Time{})
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
@@ -0,0 +1,391 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sandbox
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Section identifiers and import kinds of the wasm binary format,
+// see https://webassembly.github.io/spec/core/binary/modules.html
+const (
+	customSectionID    = 0
+	typeSectionID      = 1
+	importSectionID    = 2
+	functionSectionID  = 3
+	tableSectionID     = 4
+	memorySectionID    = 5
+	exportSectionID    = 7
+	codeSectionID      = 10
+	dataCountSectionID = 12
+
+	functionKind = 0
+	tableKind    = 1
+	memoryKind   = 2
+	globalKind   = 3
+)
+
+var wasmHeader = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+var (
+	ErrWasmHeaderInvalid = errors.New("wasm header is invalid")
+	ErrWasmMalformed     = errors.New("wasm binary is malformed")
+	ErrImportKindUnknown = errors.New("import kind is unknown")
+)
+
+type section struct {
+	id      byte
+	content []byte
+}
+
+// parseSections splits the wasm binary given in its sections.
+func parseSections(code []byte) (sections []section, err error) {
+	if !bytes.HasPrefix(code, wasmHeader) {
+		return nil, ErrWasmHeaderInvalid
+	}
+
+	for offset := len(wasmHeader); offset < len(code); {
+		id := code[offset]
+		offset++
+		size, n, err := readUnsignedLEB128(code[offset:])
+		if err != nil {
+			return nil, fmt.Errorf("reading size of section %d: %w", id, err)
+		}
+		offset += n
+		if uint64(len(code)-offset) < uint64(size) {
+			return nil, fmt.Errorf("%w: section %d size %d exceeds remaining %d bytes",
+				ErrWasmMalformed, id, size, len(code)-offset)
+		}
+		sections = append(sections, section{id: id, content: code[offset : offset+int(size)]})
+		offset += int(size)
+	}
+
+	return sections, nil
+}
+
+// encodeSections encodes the sections given as a wasm binary.
+func encodeSections(sections []section) (code []byte) {
+	code = append(code, wasmHeader...)
+	for _, s := range sections {
+		code = appendSection(code, s.id, s.content)
+	}
+	return code
+}
+
+// setSection sets the content of the section with the given identifier,
+// inserting the section at its position in the section order if needed.
+func setSection(sections []section, id byte, content []byte) (updated []section) {
+	for i, s := range sections {
+		if s.id == id {
+			sections[i].content = content
+			return sections
+		}
+
+		if s.id != customSectionID && sectionOrder(s.id) > sectionOrder(id) {
+			updated = append(updated, sections[:i]...)
+			updated = append(updated, section{id: id, content: content})
+			return append(updated, sections[i:]...)
+		}
+	}
+	return append(sections, section{id: id, content: content})
+}
+
+// sectionOrder returns the order of the non custom section
+// with the given identifier in a wasm binary.
+func sectionOrder(id byte) int {
+	switch id {
+	case dataCountSectionID:
+		// the data count section is placed before the code section
+		return codeSectionID*2 - 1
+	default:
+		return int(id) * 2
+	}
+}
+
+// wasmImport is an import entry of the import section.
+type wasmImport struct {
+	module      string
+	field       string
+	kind        byte
+	description []byte
+}
+
+// parseImports parses the content of an import section.
+func parseImports(content []byte) (imports []wasmImport, err error) {
+	count, offset, err := readUnsignedLEB128(content)
+	if err != nil {
+		return nil, fmt.Errorf("reading imports count: %w", err)
+	}
+
+	for i := uint32(0); i < count; i++ {
+		var names [2]string
+		for j := range names {
+			nameLength, n, err := readUnsignedLEB128(content[offset:])
+			if err != nil {
+				return nil, fmt.Errorf("reading import %d name length: %w", i, err)
+			}
+			offset += n
+			if uint64(len(content)-offset) < uint64(nameLength) {
+				return nil, fmt.Errorf("%w: import %d name is truncated", ErrWasmMalformed, i)
+			}
+			names[j] = string(content[offset : offset+int(nameLength)])
+			offset += int(nameLength)
+		}
+
+		if offset >= len(content) {
+			return nil, fmt.Errorf("%w: import %d is truncated", ErrWasmMalformed, i)
+		}
+		kind := content[offset]
+		offset++
+
+		var descriptionLength int
+		switch kind {
+		case functionKind:
+			_, descriptionLength, err = readUnsignedLEB128(content[offset:])
+		case tableKind:
+			if offset >= len(content) {
+				return nil, fmt.Errorf("%w: import %d is truncated", ErrWasmMalformed, i)
+			}
+			// reference type followed by limits
+			descriptionLength, err = limitsLength(content[offset+1:])
+			descriptionLength++
+		case memoryKind:
+			descriptionLength, err = limitsLength(content[offset:])
+		case globalKind:
+			// value type and mutability
+			descriptionLength = 2
+		default:
+			err = fmt.Errorf("%w: %d", ErrImportKindUnknown, kind)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading import %d description: %w", i, err)
+		}
+
+		if offset+descriptionLength > len(content) {
+			return nil, fmt.Errorf("%w: import %d is truncated", ErrWasmMalformed, i)
+		}
+
+		imports = append(imports, wasmImport{
+			module:      names[0],
+			field:       names[1],
+			kind:        kind,
+			description: content[offset : offset+descriptionLength],
+		})
+		offset += descriptionLength
+	}
+
+	return imports, nil
+}
+
+// encodeImports encodes the imports given as the content of an import section.
+func encodeImports(imports []wasmImport) (content []byte) {
+	content = appendUnsignedLEB128(content, uint32(len(imports)))
+	for _, imp := range imports {
+		content = appendName(content, imp.module)
+		content = appendName(content, imp.field)
+		content = append(content, imp.kind)
+		content = append(content, imp.description...)
+	}
+	return content
+}
+
+// limitsLength returns the length of the encoded limits
+// at the start of the data given.
+func limitsLength(data []byte) (length int, err error) {
+	if len(data) == 0 {
+		return 0, fmt.Errorf("%w: limits are empty", ErrWasmMalformed)
+	}
+
+	const hasMaximumFlag = 0x01
+	flags := data[0]
+	length = 1
+
+	_, n, err := readUnsignedLEB128(data[length:])
+	if err != nil {
+		return 0, fmt.Errorf("reading minimum: %w", err)
+	}
+	length += n
+
+	if flags&hasMaximumFlag != 0 {
+		_, n, err = readUnsignedLEB128(data[length:])
+		if err != nil {
+			return 0, fmt.Errorf("reading maximum: %w", err)
+		}
+		length += n
+	}
+
+	return length, nil
+}
+
+// DispatchCallerExport is the name of the function exported by the
+// supervisor runtime module to call the dispatch thunk given when
+// instantiating a guest module, see AddDispatchCaller.
+const DispatchCallerExport = "gossamer_sandbox_dispatch"
+
+// AddDispatchCaller returns the runtime code given with an additional
+// exported function named DispatchCallerExport, taking as arguments the
+// table index of a dispatch thunk function followed by the arguments to
+// call it with. Host functions of guest modules are dispatched to the
+// runtime by calling the dispatch thunk of the runtime, which is a
+// function pointer, that is an index in the function table of the runtime.
+// Since the wasm engines used cannot call functions of a table from the
+// host, the added function calls the dispatch thunk with `call_indirect`.
+// The code is returned unchanged if the module has no function table.
+func AddDispatchCaller(code []byte) (modified []byte, err error) {
+	sections, err := parseSections(code)
+	if err != nil {
+		return nil, fmt.Errorf("parsing sections: %w", err)
+	}
+
+	var typeCount, importedFunctionCount, functionCount, exportCount uint32
+	var typeContent, functionContent, exportContent, codeContent []byte
+	hasTable := false
+	for _, s := range sections {
+		switch s.id {
+		case typeSectionID:
+			typeContent = s.content
+			typeCount, _, err = readUnsignedLEB128(s.content)
+		case importSectionID:
+			var imports []wasmImport
+			imports, err = parseImports(s.content)
+			for _, imp := range imports {
+				switch imp.kind {
+				case functionKind:
+					importedFunctionCount++
+				case tableKind:
+					hasTable = true
+				}
+			}
+		case functionSectionID:
+			functionContent = s.content
+			functionCount, _, err = readUnsignedLEB128(s.content)
+		case tableSectionID:
+			var tableCount uint32
+			tableCount, _, err = readUnsignedLEB128(s.content)
+			hasTable = hasTable || tableCount > 0
+		case exportSectionID:
+			exportContent = s.content
+			exportCount, _, err = readUnsignedLEB128(s.content)
+		case codeSectionID:
+			codeContent = s.content
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading section %d: %w", s.id, err)
+		}
+	}
+
+	if !hasTable {
+		return code, nil
+	}
+
+	const (
+		i32      = 0x7f
+		i64      = 0x7e
+		funcType = 0x60
+	)
+
+	// the dispatch thunk has the signature
+	// fn(args_ptr: u32, args_len: u32, state: u32, func_index: u32) -> u64
+	thunkType := typeCount
+	callerType := typeCount + 1
+	typeContent = replaceCount(typeContent, typeCount+2)
+	typeContent = append(typeContent, funcType, 4, i32, i32, i32, i32, 1, i64)
+	typeContent = append(typeContent, funcType, 5, i32, i32, i32, i32, i32, 1, i64)
+
+	callerIndex := importedFunctionCount + functionCount
+	functionContent = replaceCount(functionContent, functionCount+1)
+	functionContent = appendUnsignedLEB128(functionContent, callerType)
+
+	exportContent = replaceCount(exportContent, exportCount+1)
+	exportContent = appendName(exportContent, DispatchCallerExport)
+	exportContent = append(exportContent, functionKind)
+	exportContent = appendUnsignedLEB128(exportContent, callerIndex)
+
+	const (
+		localGet     = 0x20
+		callIndirect = 0x11
+		end          = 0x0b
+	)
+	// no locals, push the thunk arguments, then the thunk table index
+	// and call the thunk from the table at index 0.
+	body := []byte{0, localGet, 1, localGet, 2, localGet, 3, localGet, 4, localGet, 0, callIndirect}
+	body = appendUnsignedLEB128(body, thunkType)
+	body = append(body, 0, end)
+	codeContent = replaceCount(codeContent, functionCount+1)
+	codeContent = appendUnsignedLEB128(codeContent, uint32(len(body)))
+	codeContent = append(codeContent, body...)
+
+	sections = setSection(sections, typeSectionID, typeContent)
+	sections = setSection(sections, functionSectionID, functionContent)
+	sections = setSection(sections, exportSectionID, exportContent)
+	sections = setSection(sections, codeSectionID, codeContent)
+
+	return encodeSections(sections), nil
+}
+
+// replaceCount replaces the vector count at the start of the section
+// content given, which can be empty for a section not present yet.
+func replaceCount(content []byte, count uint32) (updated []byte) {
+	updated = appendUnsignedLEB128(nil, count)
+	if len(content) == 0 {
+		return updated
+	}
+	_, n, _ := readUnsignedLEB128(content)
+	return append(updated, content[n:]...)
+}
+
+// memoryModule returns the wasm binary of a module defining
+// and exporting a memory with the given limits, the maximum
+// being ignored if hasMaximum is false.
+func memoryModule(exportName string, initial, maximum uint32, hasMaximum bool) (code []byte) {
+	var memoryContent []byte
+	memoryContent = append(memoryContent, 1) // one memory
+	if hasMaximum {
+		memoryContent = append(memoryContent, 0x01)
+		memoryContent = appendUnsignedLEB128(memoryContent, initial)
+		memoryContent = appendUnsignedLEB128(memoryContent, maximum)
+	} else {
+		memoryContent = append(memoryContent, 0x00)
+		memoryContent = appendUnsignedLEB128(memoryContent, initial)
+	}
+
+	var exportContent []byte
+	exportContent = append(exportContent, 1) // one export
+	exportContent = appendName(exportContent, exportName)
+	exportContent = append(exportContent, memoryKind, 0)
+
+	return encodeSections([]section{
+		{id: memorySectionID, content: memoryContent},
+		{id: exportSectionID, content: exportContent},
+	})
+}
+
+func appendSection(data []byte, id byte, content []byte) []byte {
+	data = append(data, id)
+	data = appendUnsignedLEB128(data, uint32(len(content)))
+	return append(data, content...)
+}
+
+func appendName(data []byte, name string) []byte {
+	data = appendUnsignedLEB128(data, uint32(len(name)))
+	return append(data, name...)
+}
+
+// readUnsignedLEB128 decodes the LEB128 encoded 32 bit unsigned integer
+// at the start of the data given and returns it with its encoded length.
+func readUnsignedLEB128(data []byte) (value uint32, length int, err error) {
+	decoded, length := binary.Uvarint(data)
+	if length <= 0 || decoded > uint64(^uint32(0)) {
+		return 0, 0, fmt.Errorf("%w: invalid LEB128 unsigned integer", ErrWasmMalformed)
+	}
+	return uint32(decoded), length, nil
+}
+
+// appendUnsignedLEB128 appends the LEB128 encoding of the value to the data.
+func appendUnsignedLEB128(data []byte, value uint32) []byte {
+	return binary.AppendUvarint(data, uint64(value))
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sandbox
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func wasmSection(id byte, content ...byte) []byte {
+	return appendSection(nil, id, content)
+}
+
+func wasmBinary(sections ...[]byte) (code []byte) {
+	code = append(code, wasmHeader...)
+	for _, section := range sections {
+		code = append(code, section...)
+	}
+	return code
+}
+
+func Test_AddDispatchCaller(t *testing.T) {
+	t.Parallel()
+
+	tableSection := wasmSection(tableSectionID, 1, 0x70, 0x00, 1)
+	exportContent := appendName([]byte{1}, DispatchCallerExport)
+	exportContent = append(exportContent, functionKind, 0)
+
+	testCases := map[string]struct {
+		code       []byte
+		modified   []byte
+		errWrapped error
+		errMessage string
+	}{
+		"invalid_header": {
+			code:       []byte{0x00, 0x61, 0x73, 0x6d, 0x02, 0x00, 0x00, 0x00},
+			errWrapped: ErrWasmHeaderInvalid,
+			errMessage: "parsing sections: wasm header is invalid",
+		},
+		"no_table": {
+			code:     wasmBinary(wasmSection(memorySectionID, 1, 0x00, 1)),
+			modified: wasmBinary(wasmSection(memorySectionID, 1, 0x00, 1)),
+		},
+		"table_defined": {
+			code: wasmBinary(tableSection),
+			modified: wasmBinary(
+				wasmSection(typeSectionID, 2,
+					0x60, 4, 0x7f, 0x7f, 0x7f, 0x7f, 1, 0x7e,
+					0x60, 5, 0x7f, 0x7f, 0x7f, 0x7f, 0x7f, 1, 0x7e),
+				wasmSection(functionSectionID, 1, 1),
+				tableSection,
+				wasmSection(exportSectionID, exportContent...),
+				wasmSection(codeSectionID, 1, 15,
+					0, 0x20, 1, 0x20, 2, 0x20, 3, 0x20, 4, 0x20, 0, 0x11, 0, 0, 0x0b),
+			),
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			modified, err := AddDispatchCaller(testCase.code)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.modified, modified)
+		})
+	}
+}
+
+func Test_parseImports(t *testing.T) {
+	t.Parallel()
+
+	content := []byte{3}
+	content = append(appendName(appendName(content, "env"), "ext_test"), functionKind, 2)
+	content = append(appendName(appendName(content, "env"), "memory"), memoryKind, 0x01, 1, 0x80, 0x01)
+	content = append(appendName(appendName(content, "env"), "global"), globalKind, 0x7f, 0)
+
+	imports, err := parseImports(content)
+
+	assert.NoError(t, err)
+	expectedImports := []wasmImport{
+		{module: "env", field: "ext_test", kind: functionKind, description: []byte{2}},
+		{module: "env", field: "memory", kind: memoryKind, description: []byte{0x01, 1, 0x80, 0x01}},
+		{module: "env", field: "global", kind: globalKind, description: []byte{0x7f, 0}},
+	}
+	assert.Equal(t, expectedImports, imports)
+	assert.Equal(t, content, encodeImports(imports))
+}
+
+func Test_memoryModule(t *testing.T) {
+	t.Parallel()
+
+	exportContent := append(appendName([]byte{1}, "memory"), memoryKind, 0)
+
+	code := memoryModule("memory", 1, 128, true)
+	expected := wasmBinary(
+		wasmSection(memorySectionID, 1, 0x01, 1, 0x80, 0x01),
+		wasmSection(exportSectionID, exportContent...),
+	)
+	assert.Equal(t, expected, code)
+
+	code = memoryModule("memory", 1, 0, false)
+	expected = wasmBinary(
+		wasmSection(memorySectionID, 1, 0x00, 1),
+		wasmSection(exportSectionID, exportContent...),
+	)
+	assert.Equal(t, expected, code)
+}
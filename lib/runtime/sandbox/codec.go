@@ -0,0 +1,257 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sandbox
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+// ValueType is the type of a value passed between
+// the supervisor and a sandboxed guest instance.
+type ValueType byte
+
+const (
+	// ValueTypeI32 is the 32 bit integer value type.
+	ValueTypeI32 ValueType = iota
+	// ValueTypeI64 is the 64 bit integer value type.
+	ValueTypeI64
+	// ValueTypeF32 is the 32 bit float value type, with the float bits stored.
+	ValueTypeF32
+	// ValueTypeF64 is the 64 bit float value type, with the float bits stored.
+	ValueTypeF64
+)
+
+func (v ValueType) String() string {
+	switch v {
+	case ValueTypeI32:
+		return "i32"
+	case ValueTypeI64:
+		return "i64"
+	case ValueTypeF32:
+		return "f32"
+	case ValueTypeF64:
+		return "f64"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(v))
+	}
+}
+
+// Value is a typed value passed between the supervisor and a guest instance,
+// encoded as the `sp_wasm_interface::Value` enum.
+// Its bits are stored the same way as wasm values on the wazero stack:
+// 32 bit values are stored in the lower 32 bits.
+type Value struct {
+	Type ValueType
+	Bits uint64
+}
+
+var (
+	ErrValueTypeUnknown   = errors.New("value type is unknown")
+	ErrEntityKindUnknown  = errors.New("external entity kind is unknown")
+	ErrReturnValueUnknown = errors.New("return value variant is unknown")
+	ErrHostResultUnknown  = errors.New("host result variant is unknown")
+)
+
+func (v Value) encode(buffer *bytes.Buffer) {
+	buffer.WriteByte(byte(v.Type))
+	switch v.Type {
+	case ValueTypeI32, ValueTypeF32:
+		buffer.Write(binary.LittleEndian.AppendUint32(nil, uint32(v.Bits)))
+	default:
+		buffer.Write(binary.LittleEndian.AppendUint64(nil, v.Bits))
+	}
+}
+
+func decodeValue(reader io.Reader) (value Value, err error) {
+	var valueType [1]byte
+	_, err = io.ReadFull(reader, valueType[:])
+	if err != nil {
+		return value, fmt.Errorf("reading value type: %w", err)
+	}
+	value.Type = ValueType(valueType[0])
+
+	switch value.Type {
+	case ValueTypeI32, ValueTypeF32:
+		var data [4]byte
+		_, err = io.ReadFull(reader, data[:])
+		value.Bits = uint64(binary.LittleEndian.Uint32(data[:]))
+	case ValueTypeI64, ValueTypeF64:
+		var data [8]byte
+		_, err = io.ReadFull(reader, data[:])
+		value.Bits = binary.LittleEndian.Uint64(data[:])
+	default:
+		return value, fmt.Errorf("%w: %d", ErrValueTypeUnknown, value.Type)
+	}
+
+	if err != nil {
+		return value, fmt.Errorf("reading %s value: %w", value.Type, err)
+	}
+	return value, nil
+}
+
+// encodeValues encodes the values given as a SCALE vector of values.
+func encodeValues(values []Value) (encoded []byte) {
+	buffer := bytes.NewBuffer(scale.MustMarshal(uint(len(values))))
+	for _, value := range values {
+		value.encode(buffer)
+	}
+	return buffer.Bytes()
+}
+
+// decodeValues decodes a SCALE vector of values.
+func decodeValues(encoded []byte) (values []Value, err error) {
+	reader := bytes.NewReader(encoded)
+	var length uint
+	err = scale.NewDecoder(reader).Decode(&length)
+	if err != nil {
+		return nil, fmt.Errorf("decoding values length: %w", err)
+	}
+
+	// the values length is not trusted to pre-allocate the slice.
+	for i := uint(0); i < length; i++ {
+		value, err := decodeValue(reader)
+		if err != nil {
+			return nil, fmt.Errorf("decoding value %d: %w", i, err)
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// encodeReturnValue encodes the return value of a function as
+// the `sp_wasm_interface::ReturnValue` enum, with a nil value
+// corresponding to the unit return value.
+func encodeReturnValue(value *Value) (encoded []byte) {
+	buffer := bytes.NewBuffer(nil)
+	if value == nil {
+		buffer.WriteByte(0)
+		return buffer.Bytes()
+	}
+
+	buffer.WriteByte(1)
+	value.encode(buffer)
+	return buffer.Bytes()
+}
+
+// encodeOptionalValue encodes the value given as a SCALE option.
+func encodeOptionalValue(value *Value) (encoded []byte) {
+	// the SCALE option encoding is the same as the return value encoding.
+	return encodeReturnValue(value)
+}
+
+// decodeHostResult decodes the result returned by the dispatch thunk of the
+// supervisor, which is a SCALE encoded `Result<ReturnValue, HostError>`.
+// It returns the value returned by the host function, which is nil for the
+// unit return value, or an error if the host function failed.
+func decodeHostResult(encoded []byte) (value *Value, err error) {
+	reader := bytes.NewReader(encoded)
+	resultVariant, err := reader.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("reading result variant: %w", err)
+	}
+
+	switch resultVariant {
+	case 0:
+	case 1:
+		return nil, ErrHostFunctionFailed
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrHostResultUnknown, resultVariant)
+	}
+
+	returnVariant, err := reader.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("reading return value variant: %w", err)
+	}
+
+	switch returnVariant {
+	case 0:
+		return nil, nil
+	case 1:
+		decoded, err := decodeValue(reader)
+		if err != nil {
+			return nil, fmt.Errorf("decoding return value: %w", err)
+		}
+		return &decoded, nil
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrReturnValueUnknown, returnVariant)
+	}
+}
+
+// entityKind is the kind of an external entity of the environment definition.
+type entityKind byte
+
+const (
+	entityKindFunction entityKind = 1
+	entityKindMemory   entityKind = 2
+)
+
+// environmentEntry is an entry of the environment definition given when
+// instantiating a guest module, mapping an import of the module to either a
+// function dispatched to the supervisor or a sandbox memory.
+type environmentEntry struct {
+	moduleName string
+	fieldName  string
+	kind       entityKind
+	// index is the host function index for a function entity,
+	// and the memory index for a memory entity.
+	index uint32
+}
+
+// decodeEnvironment decodes the SCALE encoded environment
+// definition given when instantiating a guest module.
+func decodeEnvironment(encoded []byte) (entries []environmentEntry, err error) {
+	reader := bytes.NewReader(encoded)
+	decoder := scale.NewDecoder(reader)
+
+	var length uint
+	err = decoder.Decode(&length)
+	if err != nil {
+		return nil, fmt.Errorf("decoding entries length: %w", err)
+	}
+
+	for i := uint(0); i < length; i++ {
+		var moduleName, fieldName []byte
+		err = decoder.Decode(&moduleName)
+		if err != nil {
+			return nil, fmt.Errorf("decoding module name of entry %d: %w", i, err)
+		}
+
+		err = decoder.Decode(&fieldName)
+		if err != nil {
+			return nil, fmt.Errorf("decoding field name of entry %d: %w", i, err)
+		}
+
+		var kind byte
+		var index uint32
+		err = decoder.Decode(&kind)
+		if err != nil {
+			return nil, fmt.Errorf("decoding entity kind of entry %d: %w", i, err)
+		}
+
+		if entityKind(kind) != entityKindFunction && entityKind(kind) != entityKindMemory {
+			return nil, fmt.Errorf("%w: %d for entry %d", ErrEntityKindUnknown, kind, i)
+		}
+
+		err = decoder.Decode(&index)
+		if err != nil {
+			return nil, fmt.Errorf("decoding entity index of entry %d: %w", i, err)
+		}
+
+		entries = append(entries, environmentEntry{
+			moduleName: string(moduleName),
+			fieldName:  string(fieldName),
+			kind:       entityKind(kind),
+			index:      index,
+		})
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sandbox
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_encodeValues(t *testing.T) {
+	t.Parallel()
+
+	values := []Value{
+		{Type: ValueTypeI32, Bits: 1},
+		{Type: ValueTypeI64, Bits: 0x0102030405060708},
+		{Type: ValueTypeF32, Bits: 0x3f800000},
+		{Type: ValueTypeF64, Bits: 0x3ff0000000000000},
+	}
+
+	encoded := encodeValues(values)
+
+	expected := []byte{
+		4 << 2,
+		0, 1, 0, 0, 0,
+		1, 8, 7, 6, 5, 4, 3, 2, 1,
+		2, 0, 0, 0x80, 0x3f,
+		3, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f,
+	}
+	assert.Equal(t, expected, encoded)
+
+	decoded, err := decodeValues(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, values, decoded)
+}
+
+func Test_decodeValues(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		encoded    []byte
+		values     []Value
+		errWrapped error
+		errMessage string
+	}{
+		"empty": {
+			encoded: []byte{0},
+		},
+		"unknown_value_type": {
+			encoded:    []byte{1 << 2, 4},
+			errWrapped: ErrValueTypeUnknown,
+			errMessage: "decoding value 0: value type is unknown: 4",
+		},
+		"truncated_value": {
+			encoded:    []byte{1 << 2, 1, 1, 2},
+			errWrapped: io.ErrUnexpectedEOF,
+			errMessage: "decoding value 0: reading i64 value: unexpected EOF",
+		},
+		"length_exceeding_values": {
+			encoded:    []byte{2 << 2, 0, 1, 0, 0, 0},
+			errWrapped: io.EOF,
+			errMessage: "decoding value 1: reading value type: EOF",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			values, err := decodeValues(testCase.encoded)
+
+			if testCase.errMessage != "" {
+				assert.ErrorIs(t, err, testCase.errWrapped)
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.values, values)
+		})
+	}
+}
+
+func Test_encodeReturnValue(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []byte{0}, encodeReturnValue(nil))
+	assert.Equal(t, []byte{1, 0, 5, 0, 0, 0},
+		encodeReturnValue(&Value{Type: ValueTypeI32, Bits: 5}))
+}
+
+func Test_decodeHostResult(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		encoded    []byte
+		value      *Value
+		errWrapped error
+		errMessage string
+	}{
+		"empty": {
+			errWrapped: io.EOF,
+			errMessage: "reading result variant: EOF",
+		},
+		"host_error": {
+			encoded:    []byte{1},
+			errWrapped: ErrHostFunctionFailed,
+			errMessage: "host function failed",
+		},
+		"unknown_result": {
+			encoded:    []byte{2},
+			errWrapped: ErrHostResultUnknown,
+			errMessage: "host result variant is unknown: 2",
+		},
+		"unit": {
+			encoded: []byte{0, 0},
+		},
+		"value": {
+			encoded: []byte{0, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0},
+			value:   &Value{Type: ValueTypeI64, Bits: 1},
+		},
+		"unknown_return_value": {
+			encoded:    []byte{0, 2},
+			errWrapped: ErrReturnValueUnknown,
+			errMessage: "return value variant is unknown: 2",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			value, err := decodeHostResult(testCase.encoded)
+
+			if testCase.errMessage != "" {
+				assert.ErrorIs(t, err, testCase.errWrapped)
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.value, value)
+		})
+	}
+}
+
+func Test_decodeEnvironment(t *testing.T) {
+	t.Parallel()
+
+	encoded := []byte{2 << 2}
+	encoded = append(encoded, 3<<2, 'e', 'n', 'v', 4<<2, 'g', 'a', 's', '!', 1, 7, 0, 0, 0)
+	encoded = append(encoded, 3<<2, 'e', 'n', 'v', 6<<2, 'm', 'e', 'm', 'o', 'r', 'y', 2, 1, 0, 0, 0)
+
+	entries, err := decodeEnvironment(encoded)
+
+	require.NoError(t, err)
+	expected := []environmentEntry{
+		{moduleName: "env", fieldName: "gas!", kind: entityKindFunction, index: 7},
+		{moduleName: "env", fieldName: "memory", kind: entityKindMemory, index: 1},
+	}
+	assert.Equal(t, expected, entries)
+
+	_, err = decodeEnvironment([]byte{1 << 2, 0, 0, 3, 0, 0, 0, 0})
+	assert.ErrorIs(t, err, ErrEntityKindUnknown)
+	assert.EqualError(t, err, "external entity kind is unknown: 3 for entry 0")
+}
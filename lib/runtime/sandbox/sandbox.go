@@ -0,0 +1,627 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Result codes returned to the runtime by the sandbox host functions,
+// as defined in `sp_sandbox::env`.
+const (
+	ResultOK          uint32 = 0
+	ResultExecution   uint32 = math.MaxUint32     // -1 as i32
+	ResultOutOfBounds uint32 = math.MaxUint32 - 1 // -2 as i32
+	ResultModule      uint32 = math.MaxUint32 - 2 // -3 as i32
+)
+
+const (
+	// MaxMemoryPages is the maximum number of 64KiB pages
+	// a sandbox memory can have, that is 128MiB.
+	MaxMemoryPages = 2048
+	// noMaximum is the maximum number of pages given by the runtime
+	// when creating a memory without a maximum.
+	noMaximum = math.MaxUint32
+)
+
+var (
+	ErrExecution           = errors.New("execution failed")
+	ErrOutOfBounds         = errors.New("out of bounds")
+	ErrModule              = errors.New("module is invalid")
+	ErrInstanceNotFound    = errors.New("instance not found")
+	ErrMemoryNotFound      = errors.New("memory not found")
+	ErrHostFunctionFailed  = errors.New("host function failed")
+	ErrImportNotFound      = errors.New("import not found in environment")
+	ErrImportKindMismatch  = errors.New("import kind does not match environment")
+	ErrImportKindInvalid   = errors.New("import kind is not supported")
+	ErrExportNotFound      = errors.New("export function not found")
+	ErrArgumentsMismatch   = errors.New("arguments do not match function parameters")
+	ErrReturnValueMismatch = errors.New("return value does not match function result")
+	ErrMemoryTooLarge      = errors.New("memory is too large")
+	ErrBufferTooSmall      = errors.New("return value buffer is too small")
+)
+
+// ResultCode returns the result code to return to the runtime
+// for the error returned by a Store method.
+func ResultCode(err error) uint32 {
+	switch {
+	case err == nil:
+		return ResultOK
+	case errors.Is(err, ErrOutOfBounds):
+		return ResultOutOfBounds
+	case errors.Is(err, ErrModule):
+		return ResultModule
+	default:
+		return ResultExecution
+	}
+}
+
+// Supervisor is the runtime instance creating sandboxed guest instances,
+// to which the host function calls of the guest instances are dispatched.
+type Supervisor interface {
+	// Allocate allocates memory of the given size in the supervisor memory.
+	Allocate(size uint32) (pointer uint32, err error)
+	// Deallocate frees the supervisor memory at the pointer given.
+	Deallocate(pointer uint32) error
+	// Read reads size bytes from the supervisor memory at the pointer given,
+	// and returns false if the read is out of range.
+	Read(pointer, size uint32) (data []byte, ok bool)
+	// Write writes the data given to the supervisor memory at the pointer
+	// given, and returns false if the write is out of range.
+	Write(pointer uint32, data []byte) (ok bool)
+	// Dispatch calls the function exported by the supervisor as
+	// DispatchCallerExport with the arguments given, and returns the
+	// pointer size of the encoded result written in the supervisor memory.
+	Dispatch(thunk, argsPointer, argsSize, state, functionIndex uint32) (
+		resultPointerSize int64, err error)
+}
+
+// Store holds the sandboxed guest instances and memories created
+// by the runtime during a runtime call.
+// It is NOT THREAD SAFE to use, which is fine since a runtime
+// instance only runs one runtime call at a time.
+type Store struct {
+	supervisor Supervisor
+	// runtime is the wazero runtime running the guest instances,
+	// which is only created once the runtime uses the sandbox.
+	runtime   wazero.Runtime
+	instances []*instance
+	memories  []api.Module
+}
+
+// instance is a sandboxed guest instance.
+type instance struct {
+	index uint32
+	// modules contains the guest module and the host modules
+	// providing its imported functions.
+	modules []api.Module
+	guest   api.Module
+	// dispatchThunk is the table index of the supervisor function
+	// dispatching the host function calls of the guest.
+	dispatchThunk uint32
+	// state is the opaque state given by the runtime to the current call
+	// of the guest, passed back to the dispatch thunk.
+	state uint32
+}
+
+// NewStore creates a new sandbox store dispatching the
+// host function calls of its instances to the supervisor given.
+func NewStore(supervisor Supervisor) *Store {
+	return &Store{
+		supervisor: supervisor,
+	}
+}
+
+func (s *Store) wazeroRuntime() wazero.Runtime {
+	if s.runtime == nil {
+		config := wazero.NewRuntimeConfig().
+			WithMemoryLimitPages(MaxMemoryPages)
+		s.runtime = wazero.NewRuntimeWithConfig(context.Background(), config)
+	}
+	return s.runtime
+}
+
+// Reset tears down all the instances and memories of the store.
+// It is called at the end of each runtime call.
+func (s *Store) Reset() {
+	if s == nil || s.runtime == nil {
+		return
+	}
+
+	// closing the runtime closes all its modules.
+	_ = s.runtime.Close(context.Background())
+	s.runtime = nil
+	s.instances = nil
+	s.memories = nil
+}
+
+// NewMemory creates a new sandbox memory with the initial and maximum
+// number of pages given, and returns its index.
+// The maximum is the maximum uint32 value for a memory without a maximum.
+func (s *Store) NewMemory(initial, maximum uint32) (memoryIndex uint32, err error) {
+	hasMaximum := maximum != noMaximum
+	if initial > MaxMemoryPages || (hasMaximum && maximum > MaxMemoryPages) {
+		return 0, fmt.Errorf("%w: initial %d pages and maximum %d pages exceed %d pages",
+			ErrMemoryTooLarge, initial, maximum, MaxMemoryPages)
+	}
+
+	ctx := context.Background()
+	memoryIndex = uint32(len(s.memories))
+	code := memoryModule("memory", initial, maximum, hasMaximum)
+	config := wazero.NewModuleConfig().WithName(memoryModuleName(memoryIndex))
+	module, err := s.wazeroRuntime().InstantiateWithConfig(ctx, code, config)
+	if err != nil {
+		return 0, fmt.Errorf("instantiating memory module: %w", err)
+	}
+
+	s.memories = append(s.memories, module)
+	return memoryIndex, nil
+}
+
+func memoryModuleName(memoryIndex uint32) string {
+	return fmt.Sprintf("memory_%d", memoryIndex)
+}
+
+func (s *Store) memory(memoryIndex uint32) (memory api.Memory, err error) {
+	if memoryIndex >= uint32(len(s.memories)) || s.memories[memoryIndex] == nil {
+		return nil, fmt.Errorf("%w: at index %d", ErrMemoryNotFound, memoryIndex)
+	}
+	return s.memories[memoryIndex].Memory(), nil
+}
+
+// MemoryTeardown tears down the memory at the index given.
+func (s *Store) MemoryTeardown(memoryIndex uint32) (err error) {
+	if memoryIndex >= uint32(len(s.memories)) || s.memories[memoryIndex] == nil {
+		return fmt.Errorf("%w: at index %d", ErrMemoryNotFound, memoryIndex)
+	}
+
+	err = s.memories[memoryIndex].Close(context.Background())
+	s.memories[memoryIndex] = nil
+	if err != nil {
+		return fmt.Errorf("closing memory module: %w", err)
+	}
+	return nil
+}
+
+// MemoryGet copies size bytes of the sandbox memory at the index given,
+// from the offset given, to the supervisor memory at the pointer given.
+func (s *Store) MemoryGet(memoryIndex, offset, pointer, size uint32) (err error) {
+	memory, err := s.memory(memoryIndex)
+	if err != nil {
+		return err
+	}
+
+	data, ok := memory.Read(offset, size)
+	if !ok {
+		return fmt.Errorf("%w: reading %d bytes at offset %d of sandbox memory",
+			ErrOutOfBounds, size, offset)
+	}
+
+	ok = s.supervisor.Write(pointer, data)
+	if !ok {
+		return fmt.Errorf("%w: writing %d bytes at pointer %d of supervisor memory",
+			ErrOutOfBounds, size, pointer)
+	}
+	return nil
+}
+
+// MemorySet copies size bytes of the supervisor memory at the pointer given
+// to the sandbox memory at the index given, from the offset given.
+func (s *Store) MemorySet(memoryIndex, offset, pointer, size uint32) (err error) {
+	memory, err := s.memory(memoryIndex)
+	if err != nil {
+		return err
+	}
+
+	data, ok := s.supervisor.Read(pointer, size)
+	if !ok {
+		return fmt.Errorf("%w: reading %d bytes at pointer %d of supervisor memory",
+			ErrOutOfBounds, size, pointer)
+	}
+
+	ok = memory.Write(offset, data)
+	if !ok {
+		return fmt.Errorf("%w: writing %d bytes at offset %d of sandbox memory",
+			ErrOutOfBounds, size, offset)
+	}
+	return nil
+}
+
+// Instantiate instantiates the guest wasm module given, with its imports
+// resolved using the SCALE encoded environment definition given, and
+// returns the index of the instance created. The imported functions of the
+// guest are dispatched to the supervisor by calling the dispatch thunk
+// given, together with the state given while running the start function
+// of the guest. Errors returned wrap ErrModule if the module is invalid,
+// its imports cannot be resolved or its start function fails.
+func (s *Store) Instantiate(dispatchThunk uint32, code, environment []byte,
+	state uint32) (instanceIndex uint32, err error) {
+	entries, err := decodeEnvironment(environment)
+	if err != nil {
+		return 0, fmt.Errorf("%w: decoding environment definition: %s", ErrModule, err)
+	}
+
+	inst := &instance{
+		index:         uint32(len(s.instances)),
+		dispatchThunk: dispatchThunk,
+		state:         state,
+	}
+
+	code, err = s.resolveImports(inst, code, entries)
+	if err != nil {
+		return 0, fmt.Errorf("%w: resolving imports: %s", ErrModule, err)
+	}
+
+	ctx := context.Background()
+	wazeroRuntime := s.wazeroRuntime()
+
+	compiled, err := wazeroRuntime.CompileModule(ctx, code)
+	if err != nil {
+		return 0, fmt.Errorf("%w: compiling module: %s", ErrModule, err)
+	}
+	defer compiled.Close(ctx)
+
+	err = s.instantiateHostModules(ctx, inst, compiled.ImportedFunctions(), entries)
+	if err != nil {
+		inst.close(ctx)
+		return 0, fmt.Errorf("%w: instantiating host modules: %s", ErrModule, err)
+	}
+
+	config := wazero.NewModuleConfig().WithName(guestModuleName(inst.index))
+	inst.guest, err = wazeroRuntime.InstantiateModule(ctx, compiled, config)
+	if err != nil {
+		inst.close(ctx)
+		return 0, fmt.Errorf("%w: instantiating module: %s", ErrModule, err)
+	}
+	inst.modules = append(inst.modules, inst.guest)
+
+	s.instances = append(s.instances, inst)
+	return inst.index, nil
+}
+
+func guestModuleName(instanceIndex uint32) string {
+	return fmt.Sprintf("instance_%d", instanceIndex)
+}
+
+// hostModuleName returns the name of the host module providing the
+// functions imported by the instance from the module name given.
+// The module name is made unique per instance so several instances
+// importing from the same module name can co-exist in the runtime.
+func hostModuleName(instanceIndex uint32, moduleName string) string {
+	return fmt.Sprintf("instance_%d_%s", instanceIndex, moduleName)
+}
+
+// resolveImports returns the guest code with its imports renamed to the
+// names of the host modules and memory modules providing them, as defined
+// in the environment given.
+func (s *Store) resolveImports(inst *instance, code []byte,
+	entries []environmentEntry) (modified []byte, err error) {
+	sections, err := parseSections(code)
+	if err != nil {
+		return nil, fmt.Errorf("parsing sections: %w", err)
+	}
+
+	for i, sec := range sections {
+		if sec.id != importSectionID {
+			continue
+		}
+
+		imports, err := parseImports(sec.content)
+		if err != nil {
+			return nil, fmt.Errorf("parsing imports: %w", err)
+		}
+
+		for j, imp := range imports {
+			entry, ok := findEntry(entries, imp.module, imp.field)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s.%s", ErrImportNotFound, imp.module, imp.field)
+			}
+
+			switch imp.kind {
+			case functionKind:
+				if entry.kind != entityKindFunction {
+					return nil, fmt.Errorf("%w: %s.%s is not a function",
+						ErrImportKindMismatch, imp.module, imp.field)
+				}
+				imports[j].module = hostModuleName(inst.index, imp.module)
+			case memoryKind:
+				if entry.kind != entityKindMemory {
+					return nil, fmt.Errorf("%w: %s.%s is not a memory",
+						ErrImportKindMismatch, imp.module, imp.field)
+				}
+				_, err = s.memory(entry.index)
+				if err != nil {
+					return nil, fmt.Errorf("resolving %s.%s: %w", imp.module, imp.field, err)
+				}
+				imports[j].module = memoryModuleName(entry.index)
+				imports[j].field = "memory"
+			default:
+				return nil, fmt.Errorf("%w: %s.%s has kind %d",
+					ErrImportKindInvalid, imp.module, imp.field, imp.kind)
+			}
+		}
+
+		sections[i].content = encodeImports(imports)
+	}
+
+	return encodeSections(sections), nil
+}
+
+func findEntry(entries []environmentEntry, moduleName, fieldName string) (
+	entry environmentEntry, ok bool) {
+	for _, entry := range entries {
+		if entry.moduleName == moduleName && entry.fieldName == fieldName {
+			return entry, true
+		}
+	}
+	return entry, false
+}
+
+// findHostEntry returns the environment entry of the function
+// imported from the host module name given of the instance.
+func findHostEntry(entries []environmentEntry, instanceIndex uint32,
+	hostModule, fieldName string) (entry environmentEntry, ok bool) {
+	for _, entry := range entries {
+		if hostModuleName(instanceIndex, entry.moduleName) == hostModule &&
+			entry.fieldName == fieldName && entry.kind == entityKindFunction {
+			return entry, true
+		}
+	}
+	return entry, false
+}
+
+// instantiateHostModules instantiates the host modules providing the
+// imported functions given of the instance, dispatching their calls
+// to the supervisor.
+func (s *Store) instantiateHostModules(ctx context.Context, inst *instance,
+	importedFunctions []api.FunctionDefinition, entries []environmentEntry) (err error) {
+	hostModuleBuilders := make(map[string]wazero.HostModuleBuilder)
+	var hostModuleNames []string
+
+	for _, definition := range importedFunctions {
+		hostModule, fieldName, _ := definition.Import()
+		entry, ok := findHostEntry(entries, inst.index, hostModule, fieldName)
+		if !ok {
+			return fmt.Errorf("%w: %s.%s", ErrImportNotFound, hostModule, fieldName)
+		}
+
+		paramTypes, resultTypes := definition.ParamTypes(), definition.ResultTypes()
+		if len(resultTypes) > 1 {
+			return fmt.Errorf("%w: %s.%s has %d results",
+				ErrImportKindInvalid, hostModule, fieldName, len(resultTypes))
+		}
+
+		builder, ok := hostModuleBuilders[hostModule]
+		if !ok {
+			builder = s.wazeroRuntime().NewHostModuleBuilder(hostModule)
+			hostModuleBuilders[hostModule] = builder
+			hostModuleNames = append(hostModuleNames, hostModule)
+		}
+
+		hostFunction := s.newHostFunction(inst, entry.index, paramTypes, resultTypes)
+		builder.NewFunctionBuilder().
+			WithGoModuleFunction(hostFunction, paramTypes, resultTypes).
+			Export(fieldName)
+	}
+
+	for _, name := range hostModuleNames {
+		module, err := hostModuleBuilders[name].Instantiate(ctx)
+		if err != nil {
+			return fmt.Errorf("instantiating host module %s: %w", name, err)
+		}
+		inst.modules = append(inst.modules, module)
+	}
+
+	return nil
+}
+
+// newHostFunction returns a host function dispatching its calls to the
+// supervisor host function with the index given.
+// Errors are raised as panics, which wazero recovers from to make
+// the guest function call fail.
+func (s *Store) newHostFunction(inst *instance, functionIndex uint32,
+	paramTypes, resultTypes []api.ValueType) api.GoModuleFunc {
+	return func(_ context.Context, _ api.Module, stack []uint64) {
+		args := make([]Value, len(paramTypes))
+		for i, paramType := range paramTypes {
+			args[i] = Value{Type: fromWasmType(paramType), Bits: stack[i]}
+		}
+
+		result, err := s.dispatch(inst, functionIndex, args)
+		if err != nil {
+			panic(err)
+		}
+
+		switch {
+		case len(resultTypes) == 0 && result == nil:
+		case len(resultTypes) == 1 && result != nil && result.Type == fromWasmType(resultTypes[0]):
+			stack[0] = result.Bits
+		default:
+			panic(fmt.Errorf("%w: for host function %d", ErrReturnValueMismatch, functionIndex))
+		}
+	}
+}
+
+// dispatch calls the supervisor host function with the index given using
+// the arguments given, and returns the value it returned, which is nil
+// for functions without result.
+func (s *Store) dispatch(inst *instance, functionIndex uint32, args []Value) (
+	result *Value, err error) {
+	encodedArgs := encodeValues(args)
+	argsPointer, err := s.supervisor.Allocate(uint32(len(encodedArgs)))
+	if err != nil {
+		return nil, fmt.Errorf("allocating arguments: %w", err)
+	}
+
+	ok := s.supervisor.Write(argsPointer, encodedArgs)
+	if !ok {
+		return nil, fmt.Errorf("%w: writing arguments", ErrOutOfBounds)
+	}
+
+	resultPointerSize, err := s.supervisor.Dispatch(inst.dispatchThunk,
+		argsPointer, uint32(len(encodedArgs)), inst.state, functionIndex)
+	if err != nil {
+		return nil, fmt.Errorf("dispatching to host function %d: %w", functionIndex, err)
+	}
+
+	err = s.supervisor.Deallocate(argsPointer)
+	if err != nil {
+		return nil, fmt.Errorf("deallocating arguments: %w", err)
+	}
+
+	resultPointer, resultSize := uint32(resultPointerSize), uint32(uint64(resultPointerSize)>>32)
+	encodedResult, ok := s.supervisor.Read(resultPointer, resultSize)
+	if !ok {
+		return nil, fmt.Errorf("%w: reading result", ErrOutOfBounds)
+	}
+
+	result, err = decodeHostResult(encodedResult)
+	if err != nil {
+		return nil, fmt.Errorf("decoding result of host function %d: %w", functionIndex, err)
+	}
+
+	err = s.supervisor.Deallocate(resultPointer)
+	if err != nil {
+		return nil, fmt.Errorf("deallocating result: %w", err)
+	}
+
+	return result, nil
+}
+
+func fromWasmType(wasmType api.ValueType) ValueType {
+	switch wasmType {
+	case api.ValueTypeI32:
+		return ValueTypeI32
+	case api.ValueTypeI64:
+		return ValueTypeI64
+	case api.ValueTypeF32:
+		return ValueTypeF32
+	default:
+		return ValueTypeF64
+	}
+}
+
+func (s *Store) instance(instanceIndex uint32) (inst *instance, err error) {
+	if instanceIndex >= uint32(len(s.instances)) || s.instances[instanceIndex] == nil {
+		return nil, fmt.Errorf("%w: at index %d", ErrInstanceNotFound, instanceIndex)
+	}
+	return s.instances[instanceIndex], nil
+}
+
+// Invoke calls the function exported by the instance at the index given,
+// with the SCALE encoded arguments given and the state given passed back
+// to the dispatch thunk, and writes its SCALE encoded return value to
+// the supervisor memory at the pointer given, which has the size given.
+// Errors returned wrap ErrExecution if the function call fails.
+func (s *Store) Invoke(instanceIndex uint32, exportName string, encodedArgs []byte,
+	returnValuePointer, returnValueSize, state uint32) (err error) {
+	inst, err := s.instance(instanceIndex)
+	if err != nil {
+		return err
+	}
+
+	function := inst.guest.ExportedFunction(exportName)
+	if function == nil {
+		return fmt.Errorf("%w: %s: %s", ErrExecution, ErrExportNotFound, exportName)
+	}
+
+	args, err := decodeValues(encodedArgs)
+	if err != nil {
+		return fmt.Errorf("%w: decoding arguments: %s", ErrExecution, err)
+	}
+
+	definition := function.Definition()
+	paramTypes := definition.ParamTypes()
+	if len(args) != len(paramTypes) {
+		return fmt.Errorf("%w: %s: %d arguments for %d parameters",
+			ErrExecution, ErrArgumentsMismatch, len(args), len(paramTypes))
+	}
+
+	params := make([]uint64, len(args))
+	for i, arg := range args {
+		if arg.Type != fromWasmType(paramTypes[i]) {
+			return fmt.Errorf("%w: %s: argument %d has type %s instead of %s",
+				ErrExecution, ErrArgumentsMismatch, i, arg.Type, fromWasmType(paramTypes[i]))
+		}
+		params[i] = arg.Bits
+	}
+
+	// the state is restored once the call returns,
+	// since the guest can be invoked recursively.
+	previousState := inst.state
+	inst.state = state
+	defer func() { inst.state = previousState }()
+
+	results, err := function.Call(context.Background(), params...)
+	if err != nil {
+		return fmt.Errorf("%w: calling %s: %s", ErrExecution, exportName, err)
+	}
+
+	var returnValue *Value
+	resultTypes := definition.ResultTypes()
+	switch len(resultTypes) {
+	case 0:
+	case 1:
+		returnValue = &Value{Type: fromWasmType(resultTypes[0]), Bits: results[0]}
+	default:
+		return fmt.Errorf("%w: %s: %s has %d results",
+			ErrExecution, ErrReturnValueMismatch, exportName, len(resultTypes))
+	}
+
+	encodedReturnValue := encodeReturnValue(returnValue)
+	if uint32(len(encodedReturnValue)) > returnValueSize {
+		return fmt.Errorf("%w: %s: %d bytes needed but buffer has %d bytes",
+			ErrExecution, ErrBufferTooSmall, len(encodedReturnValue), returnValueSize)
+	}
+
+	ok := s.supervisor.Write(returnValuePointer, encodedReturnValue)
+	if !ok {
+		return fmt.Errorf("%w: writing return value", ErrOutOfBounds)
+	}
+
+	return nil
+}
+
+// GetGlobal returns the SCALE encoded optional value of the global
+// exported with the name given by the instance at the index given.
+// The option is none if the instance does not export such global.
+func (s *Store) GetGlobal(instanceIndex uint32, name string) (encoded []byte, err error) {
+	inst, err := s.instance(instanceIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	global := inst.guest.ExportedGlobal(name)
+	if global == nil {
+		return encodeOptionalValue(nil), nil
+	}
+
+	value := &Value{Type: fromWasmType(global.Type()), Bits: global.Get()}
+	return encodeOptionalValue(value), nil
+}
+
+// Teardown tears down the instance at the index given.
+func (s *Store) Teardown(instanceIndex uint32) (err error) {
+	inst, err := s.instance(instanceIndex)
+	if err != nil {
+		return err
+	}
+
+	inst.close(context.Background())
+	s.instances[instanceIndex] = nil
+	return nil
+}
+
+func (inst *instance) close(ctx context.Context) {
+	for _, module := range inst.modules {
+		_ = module.Close(ctx)
+	}
+	inst.modules = nil
+}
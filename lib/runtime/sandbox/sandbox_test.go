@@ -0,0 +1,238 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ResultCode(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		err  error
+		code uint32
+	}{
+		"no_error": {
+			code: ResultOK,
+		},
+		"out_of_bounds": {
+			err:  fmt.Errorf("%w: reading result", ErrOutOfBounds),
+			code: ResultOutOfBounds,
+		},
+		"module": {
+			err:  fmt.Errorf("%w: compiling module: test", ErrModule),
+			code: ResultModule,
+		},
+		"execution": {
+			err:  fmt.Errorf("%w: calling call: test", ErrExecution),
+			code: ResultExecution,
+		},
+		"other_error": {
+			err:  errors.New("test"),
+			code: ResultExecution,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			code := ResultCode(testCase.err)
+
+			assert.Equal(t, testCase.code, code)
+		})
+	}
+}
+
+func Test_Store_resolveImports(t *testing.T) {
+	t.Parallel()
+
+	importSection := func(imports ...wasmImport) []byte {
+		return wasmSection(importSectionID, encodeImports(imports)...)
+	}
+	functionImport := wasmImport{module: "env", field: "gas", kind: functionKind, description: []byte{0}}
+
+	testCases := map[string]struct {
+		code       []byte
+		entries    []environmentEntry
+		modified   []byte
+		errWrapped error
+		errMessage string
+	}{
+		"function_import": {
+			code: wasmBinary(importSection(functionImport)),
+			entries: []environmentEntry{
+				{moduleName: "env", fieldName: "gas", kind: entityKindFunction, index: 3},
+			},
+			modified: wasmBinary(importSection(wasmImport{
+				module: "instance_1_env", field: "gas", kind: functionKind, description: []byte{0},
+			})),
+		},
+		"import_not_found": {
+			code:       wasmBinary(importSection(functionImport)),
+			errWrapped: ErrImportNotFound,
+			errMessage: "import not found in environment: env.gas",
+		},
+		"import_kind_mismatch": {
+			code: wasmBinary(importSection(functionImport)),
+			entries: []environmentEntry{
+				{moduleName: "env", fieldName: "gas", kind: entityKindMemory},
+			},
+			errWrapped: ErrImportKindMismatch,
+			errMessage: "import kind does not match environment: env.gas is not a function",
+		},
+		"memory_not_found": {
+			code: wasmBinary(importSection(wasmImport{
+				module: "env", field: "memory", kind: memoryKind, description: []byte{0x00, 1},
+			})),
+			entries: []environmentEntry{
+				{moduleName: "env", fieldName: "memory", kind: entityKindMemory, index: 0},
+			},
+			errWrapped: ErrMemoryNotFound,
+			errMessage: "resolving env.memory: memory not found: at index 0",
+		},
+		"global_import": {
+			code: wasmBinary(importSection(wasmImport{
+				module: "env", field: "global", kind: globalKind, description: []byte{0x7f, 0},
+			})),
+			entries: []environmentEntry{
+				{moduleName: "env", fieldName: "global", kind: entityKindFunction},
+			},
+			errWrapped: ErrImportKindInvalid,
+			errMessage: "import kind is not supported: env.global has kind 3",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store := NewStore(nil)
+			inst := &instance{index: 1}
+
+			modified, err := store.resolveImports(inst, testCase.code, testCase.entries)
+
+			if testCase.errWrapped != nil {
+				require.ErrorIs(t, err, testCase.errWrapped)
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.modified, modified)
+		})
+	}
+}
+
+// trappingModule returns a wasm module exporting a function named call
+// which traps, and calling it as start function if start is true.
+func trappingModule(start bool) (code []byte) {
+	const startSectionID = 8
+	exportContent := appendName([]byte{1}, "call")
+	exportContent = append(exportContent, functionKind, 0)
+
+	sections := [][]byte{
+		wasmSection(typeSectionID, 1, 0x60, 0, 0),
+		wasmSection(functionSectionID, 1, 0),
+		wasmSection(exportSectionID, exportContent...),
+	}
+	if start {
+		sections = append(sections, wasmSection(startSectionID, 0))
+	}
+	sections = append(sections, wasmSection(codeSectionID, 1, 3, 0, 0x00, 0x0b))
+	return wasmBinary(sections...)
+}
+
+func Test_Store_Instantiate(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		code          []byte
+		environment   []byte
+		instanceIndex uint32
+		errWrapped    error
+		errMessage    string
+	}{
+		"invalid_environment": {
+			code:        trappingModule(false),
+			environment: []byte{1 << 2, 0, 0, 3, 0, 0, 0, 0},
+			errWrapped:  ErrModule,
+			errMessage: "module is invalid: decoding environment definition: " +
+				"external entity kind is unknown: 3 for entry 0",
+		},
+		"start_function_trap": {
+			code:        trappingModule(true),
+			environment: []byte{0},
+			errWrapped:  ErrModule,
+		},
+		"success": {
+			code:        trappingModule(false),
+			environment: []byte{0},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store := NewStore(nil)
+			t.Cleanup(store.Reset)
+
+			instanceIndex, err := store.Instantiate(0, testCase.code, testCase.environment, 0)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.instanceIndex, instanceIndex)
+		})
+	}
+}
+
+func Test_Store_Invoke(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		exportName string
+		errWrapped error
+		errMessage string
+	}{
+		"export_not_found": {
+			exportName: "missing",
+			errWrapped: ErrExecution,
+			errMessage: "execution failed: export function not found: missing",
+		},
+		"trap": {
+			exportName: "call",
+			errWrapped: ErrExecution,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store := NewStore(nil)
+			t.Cleanup(store.Reset)
+			instanceIndex, err := store.Instantiate(0, trappingModule(false), []byte{0}, 0)
+			require.NoError(t, err)
+
+			err = store.Invoke(instanceIndex, testCase.exportName, []byte{0}, 0, 0, 0)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package storage
+
+import (
+	"sync"
+)
+
+// Storage methods recorded by the tracing trie state.
+const (
+	MethodGet              = "Get"
+	MethodPut              = "Put"
+	MethodClear            = "Clear"
+	MethodClearPrefix      = "ClearPrefix"
+	MethodNextKey          = "NextKey"
+	MethodChildGet         = "ChildGet"
+	MethodChildPut         = "ChildPut"
+	MethodChildClear       = "ChildClear"
+	MethodChildClearPrefix = "ChildClearPrefix"
+	MethodChildNextKey     = "ChildNextKey"
+	MethodKillChild        = "KillChild"
+)
+
+// StorageAccess is a storage access of the runtime
+// recorded by the tracing trie state.
+type StorageAccess struct {
+	// Method is the storage method called, such as Get or Put.
+	Method string
+	// KeyToChild is the key of the child trie accessed,
+	// and is nil for accesses to the main trie.
+	KeyToChild []byte
+	// Key is the key accessed, or the key prefix for prefix methods.
+	// It is nil when killing a child trie.
+	Key []byte
+	// Value is the value read or written, or the next key found.
+	// It is nil if there is no such value.
+	Value []byte
+}
+
+// RuntimeLog is a message logged by the runtime
+// recorded by the tracing trie state.
+type RuntimeLog struct {
+	Level   int32
+	Target  string
+	Message string
+}
+
+// TracingTrieState is a trie state recording the storage accesses
+// and the log messages of the runtime using it as storage.
+type TracingTrieState struct {
+	*TrieState
+	mutex    sync.Mutex
+	accesses []StorageAccess
+	logs     []RuntimeLog
+}
+
+// NewTracingTrieState returns a tracing trie state wrapping the given trie state.
+func NewTracingTrieState(trieState *TrieState) *TracingTrieState {
+	return &TracingTrieState{
+		TrieState: trieState,
+	}
+}
+
+// Accesses returns the storage accesses recorded, in their order of occurrence.
+func (s *TracingTrieState) Accesses() (accesses []StorageAccess) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	accesses = make([]StorageAccess, len(s.accesses))
+	copy(accesses, s.accesses)
+	return accesses
+}
+
+// Logs returns the runtime log messages recorded, in their order of occurrence.
+func (s *TracingTrieState) Logs() (logs []RuntimeLog) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	logs = make([]RuntimeLog, len(s.logs))
+	copy(logs, s.logs)
+	return logs
+}
+
+// RecordLog records a message logged by the runtime.
+func (s *TracingTrieState) RecordLog(level int32, target, message string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.logs = append(s.logs, RuntimeLog{
+		Level:   level,
+		Target:  target,
+		Message: message,
+	})
+}
+
+// recordAccess records a storage access. It copies the byte slices
+// given since they may be backed by the runtime memory.
+func (s *TracingTrieState) recordAccess(method string, keyToChild, key, value []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.accesses = append(s.accesses, StorageAccess{
+		Method:     method,
+		KeyToChild: copyBytes(keyToChild),
+		Key:        copyBytes(key),
+		Value:      copyBytes(value),
+	})
+}
+
+func copyBytes(b []byte) (copied []byte) {
+	if b == nil {
+		return nil
+	}
+	copied = make([]byte, len(b))
+	copy(copied, b)
+	return copied
+}
+
+// Put puts a key-value pair in the trie and records the access.
+func (s *TracingTrieState) Put(key, value []byte) (err error) {
+	err = s.TrieState.Put(key, value)
+	if err == nil {
+		s.recordAccess(MethodPut, nil, key, value)
+	}
+	return err
+}
+
+// Get gets a value from the trie and records the access.
+func (s *TracingTrieState) Get(key []byte) (value []byte) {
+	value = s.TrieState.Get(key)
+	s.recordAccess(MethodGet, nil, key, value)
+	return value
+}
+
+// Delete deletes a key from the trie and records the access.
+func (s *TracingTrieState) Delete(key []byte) (err error) {
+	err = s.TrieState.Delete(key)
+	if err == nil {
+		s.recordAccess(MethodClear, nil, key, nil)
+	}
+	return err
+}
+
+// NextKey returns the next key in the trie in lexicographical order
+// and records the access.
+func (s *TracingTrieState) NextKey(key []byte) (nextKey []byte) {
+	nextKey = s.TrieState.NextKey(key)
+	s.recordAccess(MethodNextKey, nil, key, nextKey)
+	return nextKey
+}
+
+// ClearPrefix deletes all key-value pairs from the trie where the key
+// starts with the given prefix, and records the access.
+func (s *TracingTrieState) ClearPrefix(prefix []byte) (err error) {
+	err = s.TrieState.ClearPrefix(prefix)
+	if err == nil {
+		s.recordAccess(MethodClearPrefix, nil, prefix, nil)
+	}
+	return err
+}
+
+// ClearPrefixLimit deletes key-value pairs from the trie where the key
+// starts with the given prefix till limit reached, and records the access.
+func (s *TracingTrieState) ClearPrefixLimit(prefix []byte, limit uint32) (
+	deleted uint32, allDeleted bool, err error) {
+	deleted, allDeleted, err = s.TrieState.ClearPrefixLimit(prefix, limit)
+	if err == nil {
+		s.recordAccess(MethodClearPrefix, nil, prefix, nil)
+	}
+	return deleted, allDeleted, err
+}
+
+// SetChildStorage sets a key-value pair in a child trie and records the access.
+func (s *TracingTrieState) SetChildStorage(keyToChild, key, value []byte) (err error) {
+	err = s.TrieState.SetChildStorage(keyToChild, key, value)
+	if err == nil {
+		s.recordAccess(MethodChildPut, keyToChild, key, value)
+	}
+	return err
+}
+
+// GetChildStorage returns a value from a child trie and records the access.
+func (s *TracingTrieState) GetChildStorage(keyToChild, key []byte) (value []byte, err error) {
+	value, err = s.TrieState.GetChildStorage(keyToChild, key)
+	if err == nil {
+		s.recordAccess(MethodChildGet, keyToChild, key, value)
+	}
+	return value, err
+}
+
+// ClearChildStorage removes a key-value pair from a child trie
+// and records the access.
+func (s *TracingTrieState) ClearChildStorage(keyToChild, key []byte) (err error) {
+	err = s.TrieState.ClearChildStorage(keyToChild, key)
+	if err == nil {
+		s.recordAccess(MethodChildClear, keyToChild, key, nil)
+	}
+	return err
+}
+
+// ClearPrefixInChild clears all the keys from a child trie starting
+// with the given prefix, and records the access.
+func (s *TracingTrieState) ClearPrefixInChild(keyToChild, prefix []byte) (err error) {
+	err = s.TrieState.ClearPrefixInChild(keyToChild, prefix)
+	if err == nil {
+		s.recordAccess(MethodChildClearPrefix, keyToChild, prefix, nil)
+	}
+	return err
+}
+
+// GetChildNextKey returns the next lexicographical larger key
+// from a child trie, and records the access.
+func (s *TracingTrieState) GetChildNextKey(keyToChild, key []byte) (nextKey []byte, err error) {
+	nextKey, err = s.TrieState.GetChildNextKey(keyToChild, key)
+	if err == nil {
+		s.recordAccess(MethodChildNextKey, keyToChild, key, nextKey)
+	}
+	return nextKey, err
+}
+
+// DeleteChild deletes a child trie from the main trie and records the access.
+func (s *TracingTrieState) DeleteChild(keyToChild []byte) (err error) {
+	err = s.TrieState.DeleteChild(keyToChild)
+	if err == nil {
+		s.recordAccess(MethodKillChild, keyToChild, nil, nil)
+	}
+	return err
+}
+
+// DeleteChildLimit deletes up to limit of database entries by lexicographic order,
+// and records the access.
+func (s *TracingTrieState) DeleteChildLimit(keyToChild []byte, limit *[]byte) (
+	deleted uint32, allDeleted bool, err error) {
+	deleted, allDeleted, err = s.TrieState.DeleteChildLimit(keyToChild, limit)
+	if err == nil {
+		s.recordAccess(MethodKillChild, keyToChild, nil, nil)
+	}
+	return deleted, allDeleted, err
+}
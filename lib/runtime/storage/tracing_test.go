@@ -0,0 +1,81 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracingTrieState(t *testing.T) {
+	t.Parallel()
+
+	ts := NewTrieState(trie.NewEmptyTrie())
+	err := ts.Put([]byte("a"), []byte("1"))
+	require.NoError(t, err)
+
+	tracing := NewTracingTrieState(ts)
+	assert.Empty(t, tracing.Accesses())
+	assert.Empty(t, tracing.Logs())
+
+	key := []byte("b")
+	err = tracing.Put(key, []byte("2"))
+	require.NoError(t, err)
+	// the key recorded must not change with the runtime memory
+	key[0] = 'x'
+
+	value := tracing.Get([]byte("a"))
+	assert.Equal(t, []byte("1"), value)
+	value = tracing.Get([]byte("c"))
+	assert.Nil(t, value)
+
+	nextKey := tracing.NextKey([]byte("a"))
+	assert.Equal(t, []byte("b"), nextKey)
+
+	err = tracing.Delete([]byte("a"))
+	require.NoError(t, err)
+
+	err = tracing.ClearPrefix([]byte("b"))
+	require.NoError(t, err)
+
+	err = tracing.SetChild([]byte("child"), trie.NewEmptyTrie())
+	require.NoError(t, err)
+
+	err = tracing.SetChildStorage([]byte("child"), []byte("d"), []byte("3"))
+	require.NoError(t, err)
+
+	value, err = tracing.GetChildStorage([]byte("child"), []byte("d"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("3"), value)
+
+	err = tracing.DeleteChild([]byte("child"))
+	require.NoError(t, err)
+
+	tracing.RecordLog(2, "runtime", "message")
+
+	expectedAccesses := []StorageAccess{
+		{Method: MethodPut, Key: []byte("b"), Value: []byte("2")},
+		{Method: MethodGet, Key: []byte("a"), Value: []byte("1")},
+		{Method: MethodGet, Key: []byte("c")},
+		{Method: MethodNextKey, Key: []byte("a"), Value: []byte("b")},
+		{Method: MethodClear, Key: []byte("a")},
+		{Method: MethodClearPrefix, Key: []byte("b")},
+		{Method: MethodChildPut, KeyToChild: []byte("child"), Key: []byte("d"), Value: []byte("3")},
+		{Method: MethodChildGet, KeyToChild: []byte("child"), Key: []byte("d"), Value: []byte("3")},
+		{Method: MethodKillChild, KeyToChild: []byte("child")},
+	}
+	assert.Equal(t, expectedAccesses, tracing.Accesses())
+
+	expectedLogs := []RuntimeLog{
+		{Level: 2, Target: "runtime", Message: "message"},
+	}
+	assert.Equal(t, expectedLogs, tracing.Logs())
+
+	// the storage accesses are applied to the trie state wrapped
+	assert.Nil(t, ts.Get([]byte("a")))
+	assert.Nil(t, ts.Get([]byte("b")))
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package storage
+
+import (
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// TransactionIndexOperation is a transaction indexing operation requested
+// by the runtime for an extrinsic of the block being executed.
+type TransactionIndexOperation struct {
+	// Extrinsic is the index of the extrinsic in the block body.
+	Extrinsic uint32
+	// Hash is the content hash of the indexed data.
+	Hash common.Hash
+	// Size is the size of the indexed data, which is at the end
+	// of the encoded extrinsic. It is zero for a renew operation.
+	Size uint32
+	// Renew is true if the operation renews the storage period
+	// of data previously indexed with the same content hash.
+	Renew bool
+}
+
+// IndexTransaction records the runtime request to index the last
+// size bytes of the extrinsic at the given index in the block body,
+// using the given content hash as key.
+func (s *TrieState) IndexTransaction(extrinsic, size uint32, hash common.Hash) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.transactionIndexOperations = append(s.transactionIndexOperations, TransactionIndexOperation{
+		Extrinsic: extrinsic,
+		Hash:      hash,
+		Size:      size,
+	})
+}
+
+// RenewTransaction records the runtime request to renew the storage
+// period of the data indexed with the given content hash, on behalf
+// of the extrinsic at the given index in the block body.
+func (s *TrieState) RenewTransaction(extrinsic uint32, hash common.Hash) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.transactionIndexOperations = append(s.transactionIndexOperations, TransactionIndexOperation{
+		Extrinsic: extrinsic,
+		Hash:      hash,
+		Renew:     true,
+	})
+}
+
+// TransactionIndexOperations returns a copy of the transaction
+// indexing operations recorded, in the order they were requested.
+func (s *TrieState) TransactionIndexOperations() (operations []TransactionIndexOperation) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if len(s.transactionIndexOperations) == 0 {
+		return nil
+	}
+	operations = make([]TransactionIndexOperation, len(s.transactionIndexOperations))
+	copy(operations, s.transactionIndexOperations)
+	return operations
+}
@@ -0,0 +1,32 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrieState_TransactionIndexOperations(t *testing.T) {
+	t.Parallel()
+
+	ts := NewTrieState(nil)
+	assert.Nil(t, ts.TransactionIndexOperations())
+
+	ts.IndexTransaction(1, 10, common.Hash{1})
+	ts.RenewTransaction(2, common.Hash{2})
+
+	expected := []TransactionIndexOperation{
+		{Extrinsic: 1, Hash: common.Hash{1}, Size: 10},
+		{Extrinsic: 2, Hash: common.Hash{2}, Renew: true},
+	}
+	operations := ts.TransactionIndexOperations()
+	assert.Equal(t, expected, operations)
+
+	// the operations returned are a copy
+	operations[0].Size = 0
+	assert.Equal(t, expected, ts.TransactionIndexOperations())
+}
@@ -20,6 +20,9 @@ type TrieState struct {
 	t       *trie.Trie
 	oldTrie *trie.Trie // this is the trie before BeginStorageTransaction is called. set to nil if it isn't called
 	lock    sync.RWMutex
+	// transactionIndexOperations are the transaction indexing operations
+	// requested by the runtime, applied once the block is imported.
+	transactionIndexOperations []TransactionIndexOperation
 }
 
 // NewTrieState returns a new TrieState with the given trie
@@ -69,6 +72,14 @@ func (s *TrieState) RollbackStorageTransaction() {
 	s.oldTrie = nil
 }
 
+// SetVersion sets the state trie version used to encode
+// storage values inserted or modified from now on.
+func (s *TrieState) SetVersion(version trie.Version) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.t.SetVersion(version)
+}
+
 // Put puts a key-value pair in the trie
 func (s *TrieState) Put(key, value []byte) (err error) {
 	s.lock.Lock()
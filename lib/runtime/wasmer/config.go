@@ -23,6 +23,10 @@ type Config struct {
 	Network     BasicNetwork
 	Transaction TransactionState
 	CodeHash    common.Hash
+	// PoolSize is the number of instances pre-instantiated in the
+	// instance pool of the runtime, in addition to the runtime instance
+	// itself. If it is zero, no instance pool is created.
+	PoolSize    int
 	testVersion *runtime.Version
 }
 
@@ -136,6 +136,55 @@ func (in *Instance) BabeSubmitReportEquivocationUnsignedExtrinsic(
 	return err
 }
 
+// GrandpaGenerateKeyOwnershipProof returns the grandpa key ownership proof from the runtime.
+func (in *Instance) GrandpaGenerateKeyOwnershipProof(setID uint64, authorityID [32]byte) (
+	types.OpaqueKeyOwnershipProof, error) {
+
+	// scale encoded set id uint64 + scale encoded array of 32 bytes
+	const maxBufferLength = 8 + 33
+	buffer := bytes.NewBuffer(make([]byte, 0, maxBufferLength))
+	encoder := scale.NewEncoder(buffer)
+	err := encoder.Encode(setID)
+	if err != nil {
+		return nil, fmt.Errorf("encoding set id: %w", err)
+	}
+	err = encoder.Encode(authorityID)
+	if err != nil {
+		return nil, fmt.Errorf("encoding authority id: %w", err)
+	}
+
+	encodedKeyOwnershipProof, err := in.Exec(runtime.GrandpaAPIGenerateKeyOwnershipProof, buffer.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("executing %s: %w", runtime.GrandpaAPIGenerateKeyOwnershipProof, err)
+	}
+
+	keyOwnershipProof := types.OpaqueKeyOwnershipProof{}
+	err = scale.Unmarshal(encodedKeyOwnershipProof, &keyOwnershipProof)
+	if err != nil {
+		return nil, fmt.Errorf("scale decoding key ownership proof: %w", err)
+	}
+
+	return keyOwnershipProof, nil
+}
+
+// GrandpaSubmitReportEquivocationUnsignedExtrinsic reports a grandpa equivocation to the runtime.
+func (in *Instance) GrandpaSubmitReportEquivocationUnsignedExtrinsic(
+	equivocationProof types.GrandpaEquivocationProof, keyOwnershipProof types.OpaqueKeyOwnershipProof,
+) error {
+	buffer := bytes.NewBuffer(nil)
+	encoder := scale.NewEncoder(buffer)
+	err := encoder.Encode(equivocationProof)
+	if err != nil {
+		return fmt.Errorf("encoding equivocation proof: %w", err)
+	}
+	err = encoder.Encode(keyOwnershipProof)
+	if err != nil {
+		return fmt.Errorf("encoding key ownership proof: %w", err)
+	}
+	_, err = in.Exec(runtime.GrandpaAPISubmitReportEquivocationUnsignedExtrinsic, buffer.Bytes())
+	return err
+}
+
 // InitializeBlock calls runtime API function Core_initialise_block
 func (in *Instance) InitializeBlock(header *types.Header) error {
 	encodedHeader, err := scale.Marshal(*header)
@@ -277,6 +326,41 @@ func (in *Instance) QueryCallFeeDetails(ext []byte) (*types.FeeDetails, error) {
 // TODO: use this in block verification process (#1873)
 func (in *Instance) CheckInherents() {}
 
-func (in *Instance) RandomSeed()          {} //nolint:revive
-func (in *Instance) OffchainWorker()      {} //nolint:revive
-func (in *Instance) GenerateSessionKeys() {} //nolint:revive
+// OffchainWorker calls the runtime API function OffchainWorkerApi_offchain_worker
+// with the given block header, which starts the offchain workers of the runtime.
+func (in *Instance) OffchainWorker(header *types.Header) error {
+	encodedHeader, err := scale.Marshal(*header)
+	if err != nil {
+		return fmt.Errorf("encoding header: %w", err)
+	}
+
+	_, err = in.Exec(runtime.OffchainWorkerAPIOffchainWorker, encodedHeader)
+	if err != nil {
+		return fmt.Errorf("executing %s: %w", runtime.OffchainWorkerAPIOffchainWorker, err)
+	}
+
+	return nil
+}
+
+// GenerateSessionKeys generates a new set of session keys in the keystore of the instance,
+// optionally using the given seed, and returns their SCALE encoded public keys.
+func (in *Instance) GenerateSessionKeys(seed *[]byte) (publicKeys []byte, err error) {
+	encodedSeed, err := scale.Marshal(seed)
+	if err != nil {
+		return nil, fmt.Errorf("encoding seed: %w", err)
+	}
+
+	encodedPublicKeys, err := in.Exec(runtime.SessionKeysGenerateSessionKeys, encodedSeed)
+	if err != nil {
+		return nil, fmt.Errorf("executing %s: %w", runtime.SessionKeysGenerateSessionKeys, err)
+	}
+
+	err = scale.Unmarshal(encodedPublicKeys, &publicKeys)
+	if err != nil {
+		return nil, fmt.Errorf("decoding public keys: %w", err)
+	}
+
+	return publicKeys, nil
+}
+
+func (in *Instance) RandomSeed() {} //nolint:revive
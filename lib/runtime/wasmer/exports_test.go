@@ -1074,6 +1074,36 @@ func TestInstance_DecodeSessionKeys(t *testing.T) {
 	require.Len(t, *decodedKeys, 4)
 }
 
+func TestInstance_GenerateSessionKeys(t *testing.T) {
+	instance := NewTestInstance(t, runtime.NODE_RUNTIME_v098)
+
+	publicKeys, err := instance.GenerateSessionKeys(nil)
+	require.NoError(t, err)
+
+	encodedPublicKeys, err := scale.Marshal(publicKeys)
+	require.NoError(t, err)
+
+	decoded, err := instance.DecodeSessionKeys(encodedPublicKeys)
+	require.NoError(t, err)
+
+	var decodedKeys *[]struct {
+		Data []uint8
+		Type [4]uint8
+	}
+
+	err = scale.Unmarshal(decoded, &decodedKeys)
+	require.NoError(t, err)
+	require.NotNil(t, decodedKeys)
+	require.Len(t, *decodedKeys, 4)
+
+	for _, key := range *decodedKeys {
+		ks, err := instance.ctx.Keystore.GetKeystore(key.Type[:])
+		require.NoError(t, err)
+		require.Equal(t, 1, ks.Size())
+		assert.Equal(t, key.Data, ks.PublicKeys()[0].Encode())
+	}
+}
+
 func TestInstance_PaymentQueryInfo(t *testing.T) {
 	tests := []struct {
 		extB       []byte
@@ -1151,7 +1181,7 @@ func newTrieFromPairs(t *testing.T, filename string) *trie.Trie {
 		entries[pairArr[0].(string)] = pairArr[1].(string)
 	}
 
-	tr, err := trie.LoadFromMap(entries)
+	tr, err := trie.LoadFromMap(entries, trie.V0)
 	require.NoError(t, err)
 	return &tr
 }
@@ -7,6 +7,7 @@
 	"errors"
 	"fmt"
 
+	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/genesis"
 	"github.com/ChainSafe/gossamer/lib/trie"
 )
@@ -26,10 +27,37 @@ func NewTrieFromGenesis(gen genesis.Genesis) (tr trie.Trie, err error) {
 			ErrGenesisTopNotFound, gen.Name)
 	}
 
-	tr, err = trie.LoadFromMap(keyValues)
+	tr, err = NewTrieFromKeyValues(keyValues)
 	if err != nil {
 		return tr, fmt.Errorf("loading genesis top key values into trie: %w", err)
 	}
 
 	return tr, nil
 }
+
+// NewTrieFromKeyValues creates a new trie from the given map of hexadecimal
+// encoded keys to hexadecimal encoded values. The trie uses the state trie
+// version of the runtime code found at the `:code` key, or the state trie
+// version 0 if there is no runtime code.
+func NewTrieFromKeyValues(keyValues map[string]string) (tr trie.Trie, err error) {
+	stateVersion := trie.V0
+	codeHex, ok := keyValues[common.BytesToHex(common.CodeKey)]
+	if ok {
+		code, err := common.HexToBytes(codeHex)
+		if err != nil {
+			return tr, fmt.Errorf("decoding runtime code: %w", err)
+		}
+
+		runtimeVersion, err := GetRuntimeVersion(code)
+		if err != nil {
+			return tr, fmt.Errorf("getting runtime version: %w", err)
+		}
+
+		stateVersion, err = trie.VersionFromUint32(runtimeVersion.StateVersion)
+		if err != nil {
+			return tr, fmt.Errorf("parsing runtime state version: %w", err)
+		}
+	}
+
+	return trie.LoadFromMap(keyValues, stateVersion)
+}
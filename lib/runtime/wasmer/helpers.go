@@ -7,11 +7,14 @@
 import "C" //skipcq: SCC-compile
 
 import (
+	"encoding/binary"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/ChainSafe/gossamer/lib/common/types"
 	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/offchain"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 	"github.com/wasmerio/go-ext-wasm/wasmer"
 )
@@ -194,6 +197,49 @@ func toKillStorageResultEnum(allRemoved bool, numRemoved uint32) (
 	return encodedEnumValue, nil
 }
 
+// toHTTPRequestStatusesEnums encodes the offchain HTTP request statuses to a
+// scale encoded vector of HttpRequestStatus enum values and returns it.
+// The format used for each status is:
+// Byte 0: 0 if the deadline is reached, 1 for an IO error, 2 for an invalid
+// request and 3 if the response is received.
+// Byte 1-2: little endian HTTP status code, only if the response is received.
+func toHTTPRequestStatusesEnums(statuses []offchain.HTTPRequestStatus) (
+	encoded []byte, err error) {
+	encoded, err = scale.Marshal(uint(len(statuses)))
+	if err != nil {
+		return nil, fmt.Errorf("scale encoding length: %w", err)
+	}
+
+	const finished = 3
+	for _, status := range statuses {
+		if status.Err != nil {
+			encoded = append(encoded, offchain.HTTPErrorCode(status.Err))
+			continue
+		}
+		encoded = append(encoded, finished)
+		encoded = binary.LittleEndian.AppendUint16(encoded, status.StatusCode)
+	}
+
+	return encoded, nil
+}
+
+// decodeOffchainDeadline decodes the scale encoded optional timestamp in
+// milliseconds used as deadline by the offchain HTTP host functions.
+// A zero deadline is returned if the optional timestamp is none.
+func decodeOffchainDeadline(encoded []byte) (deadline time.Time, err error) {
+	var timestamp *uint64
+	err = scale.Unmarshal(encoded, &timestamp)
+	if err != nil {
+		return deadline, fmt.Errorf("scale decoding: %w", err)
+	}
+
+	if timestamp == nil {
+		return deadline, nil
+	}
+
+	return time.UnixMilli(int64(*timestamp)), nil
+}
+
 // toWasmMemoryFixedSizeOptional copies the `data` byte slice to a 64B array,
 // scale encodes the pointer to the resulting array, writes it to wasm memory
 // and returns the corresponding 64 bit pointer size.
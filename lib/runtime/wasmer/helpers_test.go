@@ -6,11 +6,14 @@
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ChainSafe/gossamer/lib/genesis"
+	"github.com/ChainSafe/gossamer/lib/runtime/offchain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -77,3 +80,65 @@ func Test_panicOnError(t *testing.T) {
 	err = errors.New("test error")
 	assert.PanicsWithValue(t, err, func() { panicOnError(err) })
 }
+
+func Test_toHTTPRequestStatusesEnums(t *testing.T) {
+	t.Parallel()
+
+	statuses := []offchain.HTTPRequestStatus{
+		{Err: offchain.ErrDeadlineReached},
+		{Err: offchain.ErrIO},
+		{Err: offchain.ErrInvalidRequest},
+		{StatusCode: 404},
+	}
+
+	encoded, err := toHTTPRequestStatusesEnums(statuses)
+
+	require.NoError(t, err)
+	expected := []byte{
+		4 << 2,     // compact length
+		0,          // deadline reached
+		1,          // io error
+		2,          // invalid
+		3, 0x94, 1, // finished with status code 404
+	}
+	assert.Equal(t, expected, encoded)
+}
+
+func Test_decodeOffchainDeadline(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		encoded    []byte
+		deadline   time.Time
+		errWrapped error
+		errMessage string
+	}{
+		"none": {
+			encoded: []byte{0},
+		},
+		"some": {
+			encoded:  []byte{1, 0xe8, 0x03, 0, 0, 0, 0, 0, 0},
+			deadline: time.UnixMilli(1000),
+		},
+		"decoding_error": {
+			encoded:    []byte{},
+			errWrapped: io.EOF,
+			errMessage: "scale decoding: EOF",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			deadline, err := decodeOffchainDeadline(testCase.encoded)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.deadline, deadline)
+		})
+	}
+}
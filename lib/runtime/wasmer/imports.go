@@ -15,6 +15,7 @@
 // extern int32_t ext_sandbox_memory_new_version_1(void *context, int32_t a, int32_t b);
 // extern int32_t ext_sandbox_memory_set_version_1(void *context, int32_t a, int32_t b, int32_t c, int32_t d);
 // extern void ext_sandbox_memory_teardown_version_1(void *context, int32_t a);
+// extern int64_t ext_sandbox_get_global_val_version_1(void *context, int32_t a, int64_t b);
 //
 // extern int32_t ext_crypto_ed25519_generate_version_1(void *context, int32_t a, int64_t b);
 // extern int64_t ext_crypto_ed25519_public_keys_version_1(void *context, int32_t a);
@@ -25,6 +26,10 @@
 // extern int64_t ext_crypto_secp256k1_ecdsa_recover_version_2(void *context, int32_t a, int32_t b);
 // extern int64_t ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(void *context, int32_t a, int32_t b);
 // extern int64_t ext_crypto_secp256k1_ecdsa_recover_compressed_version_2(void *context, int32_t a, int32_t b);
+// extern int32_t ext_crypto_ecdsa_generate_version_1(void *context, int32_t a, int64_t b);
+// extern int64_t ext_crypto_ecdsa_public_keys_version_1(void *context, int32_t a);
+// extern int64_t ext_crypto_ecdsa_sign_version_1(void *context, int32_t a, int32_t b, int64_t c);
+// extern int64_t ext_crypto_ecdsa_sign_prehashed_version_1(void *context, int32_t a, int32_t b, int32_t c);
 // extern int32_t ext_crypto_ecdsa_verify_version_2(void *context, int32_t a, int64_t b, int32_t c);
 // extern int32_t ext_crypto_sr25519_generate_version_1(void *context, int32_t a, int64_t b);
 // extern int64_t ext_crypto_sr25519_public_keys_version_1(void *context, int32_t a);
@@ -34,6 +39,7 @@
 // extern void ext_crypto_start_batch_verify_version_1(void *context);
 //
 // extern int32_t ext_trie_blake2_256_root_version_1(void *context, int64_t a);
+// extern int32_t ext_trie_blake2_256_root_version_2(void *context, int64_t a, int32_t b);
 // extern int32_t ext_trie_blake2_256_ordered_root_version_1(void *context, int64_t a);
 // extern int32_t ext_trie_blake2_256_ordered_root_version_2(void *context, int64_t a, int32_t b);
 // extern int32_t ext_trie_blake2_256_verify_proof_version_1(void *context, int32_t a, int64_t b, int64_t c, int64_t d);
@@ -48,6 +54,7 @@
 // extern int64_t ext_default_child_storage_next_key_version_1(void *context, int64_t a, int64_t b);
 // extern int64_t ext_default_child_storage_read_version_1(void *context, int64_t a, int64_t b, int64_t c, int32_t d);
 // extern int64_t ext_default_child_storage_root_version_1(void *context, int64_t a);
+// extern int64_t ext_default_child_storage_root_version_2(void *context, int64_t a, int32_t b);
 // extern void ext_default_child_storage_set_version_1(void *context, int64_t a, int64_t b, int64_t c);
 // extern void ext_default_child_storage_storage_kill_version_1(void *context, int64_t a);
 // extern int32_t ext_default_child_storage_storage_kill_version_2(void *context, int64_t a, int64_t b);
@@ -79,6 +86,10 @@
 // extern void ext_offchain_sleep_until_version_1(void *context, int64_t a);
 // extern int64_t ext_offchain_http_request_start_version_1(void *context, int64_t a, int64_t b, int64_t c);
 // extern int64_t ext_offchain_http_request_add_header_version_1(void *context, int32_t a, int64_t k, int64_t v);
+// extern int64_t ext_offchain_http_request_write_body_version_1(void *context, int32_t a, int64_t b, int64_t c);
+// extern int64_t ext_offchain_http_response_wait_version_1(void *context, int64_t a, int64_t b);
+// extern int64_t ext_offchain_http_response_headers_version_1(void *context, int32_t a);
+// extern int64_t ext_offchain_http_response_read_body_version_1(void *context, int32_t a, int64_t b, int64_t c);
 //
 // extern void ext_storage_append_version_1(void *context, int64_t a, int64_t b);
 // extern int64_t ext_storage_changes_root_version_1(void *context, int64_t a);
@@ -114,7 +125,10 @@
 	"github.com/ChainSafe/gossamer/lib/crypto/ed25519"
 	"github.com/ChainSafe/gossamer/lib/crypto/secp256k1"
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
+	"github.com/ChainSafe/gossamer/lib/keystore"
 	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/offchain"
+	"github.com/ChainSafe/gossamer/lib/runtime/sandbox"
 	"github.com/ChainSafe/gossamer/lib/transaction"
 	"github.com/ChainSafe/gossamer/lib/trie"
 	"github.com/ChainSafe/gossamer/lib/trie/proof"
@@ -135,6 +149,11 @@ func ext_logging_log_version_1(context unsafe.Pointer, level C.int32_t, targetDa
 	target := string(asMemorySlice(instanceContext, targetData))
 	msg := string(asMemorySlice(instanceContext, msgData))
 
+	recorder, ok := instanceContext.Data().(*runtime.Context).Storage.(runtime.LogRecorder)
+	if ok {
+		recorder.RecordLog(int32(level), target, msg)
+	}
+
 	switch int(level) {
 	case 0:
 		logger.Critical("target=" + target + " message=" + msg)
@@ -158,62 +177,157 @@ func ext_logging_max_level_version_1(context unsafe.Pointer) C.int32_t {
 }
 
 //export ext_transaction_index_index_version_1
-func ext_transaction_index_index_version_1(context unsafe.Pointer, a, b, c C.int32_t) {
+func ext_transaction_index_index_version_1(context unsafe.Pointer, extrinsic, size, contextHash C.int32_t) {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
+	instanceContext := wasm.IntoInstanceContext(context)
+	memory := instanceContext.Memory().Data()
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	hash := common.NewHash(memory[contextHash : contextHash+32])
+	storage.IndexTransaction(uint32(extrinsic), uint32(size), hash)
 }
 
 //export ext_transaction_index_renew_version_1
-func ext_transaction_index_renew_version_1(context unsafe.Pointer, a, b C.int32_t) {
+func ext_transaction_index_renew_version_1(context unsafe.Pointer, extrinsic, contextHash C.int32_t) {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
+	instanceContext := wasm.IntoInstanceContext(context)
+	memory := instanceContext.Memory().Data()
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	hash := common.NewHash(memory[contextHash : contextHash+32])
+	storage.RenewTransaction(uint32(extrinsic), hash)
 }
 
 //export ext_sandbox_instance_teardown_version_1
-func ext_sandbox_instance_teardown_version_1(context unsafe.Pointer, a C.int32_t) {
+func ext_sandbox_instance_teardown_version_1(context unsafe.Pointer, instanceIndex C.int32_t) {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	err := runtimeCtx.Sandbox.Teardown(uint32(instanceIndex))
+	if err != nil {
+		logger.Errorf("failed to teardown sandbox instance: %s", err)
+	}
 }
 
 //export ext_sandbox_instantiate_version_1
-func ext_sandbox_instantiate_version_1(context unsafe.Pointer, a C.int32_t, x, y C.int64_t, z C.int32_t) C.int32_t {
+func ext_sandbox_instantiate_version_1(context unsafe.Pointer, dispatchThunk C.int32_t,
+	wasmCodeSpan, environmentSpan C.int64_t, state C.int32_t) C.int32_t {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
-	return 0
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	code := asMemorySlice(instanceContext, wasmCodeSpan)
+	environment := asMemorySlice(instanceContext, environmentSpan)
+
+	instanceIndex, err := runtimeCtx.Sandbox.Instantiate(uint32(dispatchThunk), code, environment, uint32(state))
+	if err != nil {
+		logger.Errorf("failed to instantiate sandbox instance: %s", err)
+		return C.int32_t(sandbox.ResultCode(err))
+	}
+
+	return C.int32_t(instanceIndex)
 }
 
 //export ext_sandbox_invoke_version_1
-func ext_sandbox_invoke_version_1(context unsafe.Pointer, a C.int32_t, x, y C.int64_t, z, d, e C.int32_t) C.int32_t {
+func ext_sandbox_invoke_version_1(context unsafe.Pointer, instanceIndex C.int32_t,
+	exportNameSpan, argsSpan C.int64_t, returnValuePointer, returnValueSize, state C.int32_t) C.int32_t {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
-	return 0
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	exportName := string(asMemorySlice(instanceContext, exportNameSpan))
+	args := asMemorySlice(instanceContext, argsSpan)
+
+	err := runtimeCtx.Sandbox.Invoke(uint32(instanceIndex), exportName, args,
+		uint32(returnValuePointer), uint32(returnValueSize), uint32(state))
+	if err != nil {
+		logger.Debugf("failed to invoke sandbox instance function %s: %s", exportName, err)
+	}
+
+	return C.int32_t(sandbox.ResultCode(err))
 }
 
 //export ext_sandbox_memory_get_version_1
-func ext_sandbox_memory_get_version_1(context unsafe.Pointer, a, z, d, e C.int32_t) C.int32_t {
+func ext_sandbox_memory_get_version_1(context unsafe.Pointer, memoryIndex, offset,
+	bufferPointer, bufferSize C.int32_t) C.int32_t {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
-	return 0
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	err := runtimeCtx.Sandbox.MemoryGet(uint32(memoryIndex), uint32(offset),
+		uint32(bufferPointer), uint32(bufferSize))
+	if err != nil {
+		logger.Debugf("failed to get sandbox memory: %s", err)
+	}
+
+	return C.int32_t(sandbox.ResultCode(err))
 }
 
 //export ext_sandbox_memory_new_version_1
-func ext_sandbox_memory_new_version_1(context unsafe.Pointer, a, z C.int32_t) C.int32_t {
+func ext_sandbox_memory_new_version_1(context unsafe.Pointer, initial, maximum C.int32_t) C.int32_t {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
-	return 0
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	memoryIndex, err := runtimeCtx.Sandbox.NewMemory(uint32(initial), uint32(maximum))
+	if err != nil {
+		logger.Errorf("failed to create sandbox memory: %s", err)
+		return C.int32_t(sandbox.ResultCode(err))
+	}
+
+	return C.int32_t(memoryIndex)
 }
 
 //export ext_sandbox_memory_set_version_1
-func ext_sandbox_memory_set_version_1(context unsafe.Pointer, a, z, d, e C.int32_t) C.int32_t {
+func ext_sandbox_memory_set_version_1(context unsafe.Pointer, memoryIndex, offset,
+	valuePointer, valueSize C.int32_t) C.int32_t {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
-	return 0
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	err := runtimeCtx.Sandbox.MemorySet(uint32(memoryIndex), uint32(offset),
+		uint32(valuePointer), uint32(valueSize))
+	if err != nil {
+		logger.Debugf("failed to set sandbox memory: %s", err)
+	}
+
+	return C.int32_t(sandbox.ResultCode(err))
 }
 
 //export ext_sandbox_memory_teardown_version_1
-func ext_sandbox_memory_teardown_version_1(context unsafe.Pointer, a C.int32_t) {
+func ext_sandbox_memory_teardown_version_1(context unsafe.Pointer, memoryIndex C.int32_t) {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	err := runtimeCtx.Sandbox.MemoryTeardown(uint32(memoryIndex))
+	if err != nil {
+		logger.Errorf("failed to teardown sandbox memory: %s", err)
+	}
+}
+
+//export ext_sandbox_get_global_val_version_1
+func ext_sandbox_get_global_val_version_1(context unsafe.Pointer, instanceIndex C.int32_t,
+	nameSpan C.int64_t) C.int64_t {
+	logger.Trace("executing...")
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	name := string(asMemorySlice(instanceContext, nameSpan))
+	encoded, err := runtimeCtx.Sandbox.GetGlobal(uint32(instanceIndex), name)
+	if err != nil {
+		logger.Errorf("failed to get sandbox global %s: %s", name, err)
+		return 0
+	}
+
+	ret, err := toWasmMemory(instanceContext, encoded)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return C.int64_t(ret)
 }
 
 //export ext_crypto_ed25519_generate_version_1
@@ -447,6 +561,199 @@ func ext_crypto_secp256k1_ecdsa_recover_version_2(context unsafe.Pointer, sig, m
 	return ext_crypto_secp256k1_ecdsa_recover_version_1(context, sig, msg)
 }
 
+//export ext_crypto_ecdsa_generate_version_1
+func ext_crypto_ecdsa_generate_version_1(context unsafe.Pointer, keyTypeID C.int32_t, seedSpan C.int64_t) C.int32_t {
+	logger.Trace("executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	memory := instanceContext.Memory().Data()
+
+	id := memory[keyTypeID : keyTypeID+4]
+	seedBytes := asMemorySlice(instanceContext, seedSpan)
+
+	var seed *[]byte
+	err := scale.Unmarshal(seedBytes, &seed)
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	var kp KeyPair
+
+	if seed != nil {
+		kp, err = secp256k1.NewKeypairFromMnenomic(string(*seed), "")
+	} else {
+		kp, err = secp256k1.GenerateKeypair()
+	}
+
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		return 0
+	}
+
+	err = ks.Insert(kp)
+	if err != nil {
+		logger.Warnf("failed to insert key: %s", err)
+		return 0
+	}
+
+	ret, err := toWasmMemorySized(instanceContext, kp.Public().Encode())
+	if err != nil {
+		logger.Warnf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	logger.Debug("generated ecdsa keypair with public key: " + kp.Public().Hex())
+	return C.int32_t(ret)
+}
+
+//export ext_crypto_ecdsa_public_keys_version_1
+func ext_crypto_ecdsa_public_keys_version_1(context unsafe.Pointer, keyTypeID C.int32_t) C.int64_t {
+	logger.Debug("executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	memory := instanceContext.Memory().Data()
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		ret, _ := toWasmMemory(instanceContext, []byte{0})
+		return C.int64_t(ret)
+	}
+
+	if ks.Type() != crypto.Secp256k1Type && ks.Type() != crypto.UnknownType {
+		logger.Warnf(
+			"error for id 0x%x: keystore type is %s and not the expected secp256k1",
+			id, ks.Type())
+		ret, _ := toWasmMemory(instanceContext, []byte{0})
+		return C.int64_t(ret)
+	}
+
+	var encodedKeys []byte
+	var keysCount int64
+	for _, key := range ks.PublicKeys() {
+		// generic keystores can contain keys of other types
+		if _, ok := key.(*secp256k1.PublicKey); !ok {
+			continue
+		}
+		encodedKeys = append(encodedKeys, key.Encode()...)
+		keysCount++
+	}
+
+	prefix, err := scale.Marshal(big.NewInt(keysCount))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ := toWasmMemory(instanceContext, []byte{0})
+		return C.int64_t(ret)
+	}
+
+	ret, err := toWasmMemory(instanceContext, append(prefix, encodedKeys...))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ = toWasmMemory(instanceContext, []byte{0})
+		return C.int64_t(ret)
+	}
+
+	return C.int64_t(ret)
+}
+
+//export ext_crypto_ecdsa_sign_version_1
+func ext_crypto_ecdsa_sign_version_1(context unsafe.Pointer, keyTypeID, key C.int32_t, msg C.int64_t) C.int64_t {
+	logger.Debug("executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	memory := instanceContext.Memory().Data()
+
+	id := memory[keyTypeID : keyTypeID+4]
+	pubKeyData := memory[key : key+33]
+
+	hash, err := common.Blake2bHash(asMemorySlice(instanceContext, msg))
+	if err != nil {
+		logger.Errorf("failed to hash message: %s", err)
+		return mustToWasmMemoryOptionalNil(instanceContext)
+	}
+
+	ret, err := ecdsaSign(instanceContext, runtimeCtx.Keystore, id, pubKeyData, hash[:])
+	if err != nil {
+		logger.Errorf("failed to sign message: %s", err)
+		return 0
+	}
+
+	return ret
+}
+
+//export ext_crypto_ecdsa_sign_prehashed_version_1
+func ext_crypto_ecdsa_sign_prehashed_version_1(context unsafe.Pointer, keyTypeID, key, msg C.int32_t) C.int64_t {
+	logger.Debug("executing...")
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+	memory := instanceContext.Memory().Data()
+
+	id := memory[keyTypeID : keyTypeID+4]
+	pubKeyData := memory[key : key+33]
+	hash := memory[msg : msg+32]
+
+	ret, err := ecdsaSign(instanceContext, runtimeCtx.Keystore, id, pubKeyData, hash)
+	if err != nil {
+		logger.Errorf("failed to sign message: %s", err)
+		return 0
+	}
+
+	return ret
+}
+
+// ecdsaSign signs the 32 bytes message hash given with the secp256k1 key
+// matching the public key given from the keystore with the key type id given.
+// It writes the SCALE encoded optional 65 bytes signature to memory and returns
+// its pointer size, where the signature is none if the key is not found.
+func ecdsaSign(instanceContext wasm.InstanceContext, globalKeystore *keystore.GlobalKeystore,
+	id, pubKeyData, hash []byte) (cPointerSize C.int64_t, err error) {
+	pubKey := new(secp256k1.PublicKey)
+	err = pubKey.Decode(pubKeyData)
+	if err != nil {
+		logger.Errorf("failed to decode public key: %s", err)
+		return toWasmMemoryOptionalNil(instanceContext)
+	}
+
+	ks, err := globalKeystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		return toWasmMemoryOptionalNil(instanceContext)
+	}
+
+	signingKey := ks.GetKeypair(pubKey)
+	if signingKey == nil {
+		logger.Error("could not find public key " + pubKey.Hex() + " in keystore")
+		return toWasmMemoryOptionalNil(instanceContext)
+	}
+
+	signature, err := signingKey.Sign(hash)
+	if err != nil {
+		logger.Errorf("could not sign message: %s", err)
+		return toWasmMemoryOptionalNil(instanceContext)
+	}
+
+	// the signature is encoded as a SCALE option of a fixed size array.
+	pointerSize, err := toWasmMemory(instanceContext, append([]byte{1}, signature...))
+	if err != nil {
+		return 0, fmt.Errorf("writing signature to memory: %w", err)
+	}
+
+	return C.int64_t(pointerSize), nil
+}
+
 //export ext_crypto_ecdsa_verify_version_2
 func ext_crypto_ecdsa_verify_version_2(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, key C.int32_t) C.int32_t {
 	logger.Trace("executing...")
@@ -781,29 +1088,60 @@ func ext_crypto_sr25519_verify_version_2(context unsafe.Pointer, sig C.int32_t,
 func ext_crypto_start_batch_verify_version_1(context unsafe.Pointer) {
 	logger.Debug("executing...")
 
-	// TODO: fix and re-enable signature verification (#1405)
-	// beginBatchVerify(context)
+	instanceContext := wasm.IntoInstanceContext(context)
+	sigVerifier := instanceContext.Data().(*runtime.Context).SigVerifier
+	sigVerifier.Start()
 }
 
 //export ext_crypto_finish_batch_verify_version_1
 func ext_crypto_finish_batch_verify_version_1(context unsafe.Pointer) C.int32_t {
 	logger.Debug("executing...")
 
-	// TODO: fix and re-enable signature verification (#1405)
-	// return finishBatchVerify(context)
+	instanceContext := wasm.IntoInstanceContext(context)
+	sigVerifier := instanceContext.Data().(*runtime.Context).SigVerifier
+	if !sigVerifier.IsStarted() {
+		logger.Error("batch verification is not started")
+	}
+
+	if !sigVerifier.Finish() {
+		logger.Error("failed to verify batch of signatures")
+		return 0
+	}
+
 	return 1
 }
 
 //export ext_trie_blake2_256_root_version_1
 func ext_trie_blake2_256_root_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
 	logger.Debug("executing...")
+	return trieRoot(context, dataSpan, trie.V0)
+}
 
+//export ext_trie_blake2_256_root_version_2
+func ext_trie_blake2_256_root_version_2(context unsafe.Pointer,
+	dataSpan C.int64_t, version C.int32_t) C.int32_t {
+	logger.Debug("executing...")
+
+	stateVersion, err := trie.VersionFromUint32(uint32(version))
+	if err != nil {
+		logger.Errorf("failed parsing state version: %s", err)
+		return 0
+	}
+
+	return trieRoot(context, dataSpan, stateVersion)
+}
+
+// trieRoot computes the Merkle root hash of the trie built from the
+// SCALE encoded (key, value) tuples at the data span given, using the
+// state trie version given, and returns a pointer to the root hash.
+func trieRoot(context unsafe.Pointer, dataSpan C.int64_t, version trie.Version) C.int32_t {
 	instanceContext := wasm.IntoInstanceContext(context)
 	memory := instanceContext.Memory().Data()
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
 	data := asMemorySlice(instanceContext, dataSpan)
 
 	t := trie.NewEmptyTrie()
+	t.SetVersion(version)
 
 	type kv struct {
 		Key, Value []byte
@@ -846,13 +1184,35 @@ type kv struct {
 //export ext_trie_blake2_256_ordered_root_version_1
 func ext_trie_blake2_256_ordered_root_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
 	logger.Debug("executing...")
+	return trieOrderedRoot(context, dataSpan, trie.V0)
+}
+
+//export ext_trie_blake2_256_ordered_root_version_2
+func ext_trie_blake2_256_ordered_root_version_2(context unsafe.Pointer,
+	dataSpan C.int64_t, version C.int32_t) C.int32_t {
+	logger.Debug("executing...")
 
+	stateVersion, err := trie.VersionFromUint32(uint32(version))
+	if err != nil {
+		logger.Errorf("failed parsing state version: %s", err)
+		return 0
+	}
+
+	return trieOrderedRoot(context, dataSpan, stateVersion)
+}
+
+// trieOrderedRoot computes the Merkle root hash of the trie built from
+// the SCALE encoded values at the data span given, keyed by their
+// SCALE encoded index, using the state trie version given, and returns
+// a pointer to the root hash.
+func trieOrderedRoot(context unsafe.Pointer, dataSpan C.int64_t, version trie.Version) C.int32_t {
 	instanceContext := wasm.IntoInstanceContext(context)
 	memory := instanceContext.Memory().Data()
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
 	data := asMemorySlice(instanceContext, dataSpan)
 
 	t := trie.NewEmptyTrie()
+	t.SetVersion(version)
 	var values [][]byte
 	err := scale.Unmarshal(data, &values)
 	if err != nil {
@@ -896,13 +1256,6 @@ func ext_trie_blake2_256_ordered_root_version_1(context unsafe.Pointer, dataSpan
 	return C.int32_t(ptr)
 }
 
-//export ext_trie_blake2_256_ordered_root_version_2
-func ext_trie_blake2_256_ordered_root_version_2(context unsafe.Pointer,
-	dataSpan C.int64_t, version C.int32_t) C.int32_t {
-	// TODO: update to use state trie version 1 (#2418)
-	return ext_trie_blake2_256_ordered_root_version_1(context, dataSpan)
-}
-
 //export ext_trie_blake2_256_verify_proof_version_1
 func ext_trie_blake2_256_verify_proof_version_1(context unsafe.Pointer,
 	rootSpan C.int32_t, proofSpan, keySpan, valueSpan C.int64_t) C.int32_t {
@@ -1133,7 +1486,28 @@ func ext_default_child_storage_next_key_version_1(context unsafe.Pointer, childS
 func ext_default_child_storage_root_version_1(context unsafe.Pointer,
 	childStorageKey C.int64_t) (ptrSize C.int64_t) {
 	logger.Debug("executing...")
+	return childStorageRoot(context, childStorageKey, nil)
+}
+
+//export ext_default_child_storage_root_version_2
+func ext_default_child_storage_root_version_2(context unsafe.Pointer,
+	childStorageKey C.int64_t, version C.int32_t) (ptrSize C.int64_t) {
+	logger.Debug("executing...")
 
+	stateVersion, err := trie.VersionFromUint32(uint32(version))
+	if err != nil {
+		logger.Errorf("failed parsing state version: %s", err)
+		return 0
+	}
+
+	return childStorageRoot(context, childStorageKey, &stateVersion)
+}
+
+// childStorageRoot returns a pointer size to the Merkle root hash of the
+// child trie at the child storage key span given. If the version given is
+// not nil, the child trie version is set to it before computing the root.
+func childStorageRoot(context unsafe.Pointer, childStorageKey C.int64_t,
+	version *trie.Version) (ptrSize C.int64_t) {
 	instanceContext := wasm.IntoInstanceContext(context)
 	storage := instanceContext.Data().(*runtime.Context).Storage
 
@@ -1143,6 +1517,10 @@ func ext_default_child_storage_root_version_1(context unsafe.Pointer,
 		return 0
 	}
 
+	if version != nil {
+		child.SetVersion(*version)
+	}
+
 	childRoot, err := child.Hash()
 	if err != nil {
 		logger.Errorf("failed to encode child root: %s", err)
@@ -1779,8 +2157,11 @@ func ext_offchain_http_request_add_header_version_1(context unsafe.Pointer,
 	result := scale.NewResult(nil, nil)
 	resultMode := scale.OK
 
-	err := offchainReq.AddHeader(string(name), string(value))
-	if err != nil {
+	var err error
+	if offchainReq == nil {
+		logger.Errorf("failed to add request header: request id %d not found", reqID)
+		resultMode = scale.Err
+	} else if err = offchainReq.AddHeader(string(name), string(value)); err != nil {
 		logger.Errorf("failed to add request header: %s", err)
 		resultMode = scale.Err
 	}
@@ -1806,6 +2187,155 @@ func ext_offchain_http_request_add_header_version_1(context unsafe.Pointer,
 	return C.int64_t(ptr)
 }
 
+//export ext_offchain_http_request_write_body_version_1
+func ext_offchain_http_request_write_body_version_1(context unsafe.Pointer,
+	reqID C.int32_t, chunkSpan, deadlineSpan C.int64_t) (pointerSize C.int64_t) {
+	logger.Debug("executing...")
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	chunk := asMemorySlice(instanceContext, chunkSpan)
+
+	result := scale.NewResult(nil, byte(0))
+
+	deadline, err := decodeOffchainDeadline(asMemorySlice(instanceContext, deadlineSpan))
+	if err != nil {
+		logger.Errorf("failed to decode deadline: %s", err)
+		err = result.Set(scale.Err, offchain.HTTPErrorCode(offchain.ErrInvalidRequest))
+	} else if err = runtimeCtx.OffchainHTTPSet.WriteBody(int16(reqID), chunk, deadline); err != nil {
+		logger.Errorf("failed to write request body: %s", err)
+		err = result.Set(scale.Err, offchain.HTTPErrorCode(err))
+	} else {
+		err = result.Set(scale.OK, nil)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to set the result data: %s", err)
+		return C.int64_t(0)
+	}
+
+	return resultToWasmMemory(instanceContext, result)
+}
+
+//export ext_offchain_http_response_wait_version_1
+func ext_offchain_http_response_wait_version_1(context unsafe.Pointer,
+	idsSpan, deadlineSpan C.int64_t) (pointerSize C.int64_t) {
+	logger.Debug("executing...")
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	var encodedIDs []uint16
+	err := scale.Unmarshal(asMemorySlice(instanceContext, idsSpan), &encodedIDs)
+	if err != nil {
+		logger.Errorf("failed to decode request ids: %s", err)
+		return C.int64_t(0)
+	}
+
+	ids := make([]int16, len(encodedIDs))
+	for i, id := range encodedIDs {
+		ids[i] = int16(id)
+	}
+
+	deadline, err := decodeOffchainDeadline(asMemorySlice(instanceContext, deadlineSpan))
+	if err != nil {
+		logger.Errorf("failed to decode deadline: %s", err)
+		return C.int64_t(0)
+	}
+
+	statuses := runtimeCtx.OffchainHTTPSet.WaitResponses(ids, deadline)
+
+	enc, err := toHTTPRequestStatusesEnums(statuses)
+	if err != nil {
+		logger.Errorf("failed to encode request statuses: %s", err)
+		return C.int64_t(0)
+	}
+
+	ptr, err := toWasmMemory(instanceContext, enc)
+	if err != nil {
+		logger.Errorf("failed to allocate result on memory: %s", err)
+		return C.int64_t(0)
+	}
+
+	return C.int64_t(ptr)
+}
+
+//export ext_offchain_http_response_headers_version_1
+func ext_offchain_http_response_headers_version_1(context unsafe.Pointer,
+	reqID C.int32_t) (pointerSize C.int64_t) {
+	logger.Debug("executing...")
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	headers := runtimeCtx.OffchainHTTPSet.ResponseHeaders(int16(reqID))
+	if headers == nil {
+		headers = []offchain.HTTPHeader{}
+	}
+
+	enc, err := scale.Marshal(headers)
+	if err != nil {
+		logger.Errorf("failed to scale marshal the headers: %s", err)
+		return C.int64_t(0)
+	}
+
+	ptr, err := toWasmMemory(instanceContext, enc)
+	if err != nil {
+		logger.Errorf("failed to allocate result on memory: %s", err)
+		return C.int64_t(0)
+	}
+
+	return C.int64_t(ptr)
+}
+
+//export ext_offchain_http_response_read_body_version_1
+func ext_offchain_http_response_read_body_version_1(context unsafe.Pointer,
+	reqID C.int32_t, bufferSpan, deadlineSpan C.int64_t) (pointerSize C.int64_t) {
+	logger.Debug("executing...")
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	// the response body is read directly into the wasm memory buffer
+	buffer := asMemorySlice(instanceContext, bufferSpan)
+
+	result := scale.NewResult(uint32(0), byte(0))
+
+	deadline, err := decodeOffchainDeadline(asMemorySlice(instanceContext, deadlineSpan))
+	if err != nil {
+		logger.Errorf("failed to decode deadline: %s", err)
+		err = result.Set(scale.Err, offchain.HTTPErrorCode(offchain.ErrInvalidRequest))
+	} else if n, readErr := runtimeCtx.OffchainHTTPSet.ReadResponseBody(
+		int16(reqID), buffer, deadline); readErr != nil {
+		logger.Errorf("failed to read response body: %s", readErr)
+		err = result.Set(scale.Err, offchain.HTTPErrorCode(readErr))
+	} else {
+		err = result.Set(scale.OK, uint32(n))
+	}
+
+	if err != nil {
+		logger.Errorf("failed to set the result data: %s", err)
+		return C.int64_t(0)
+	}
+
+	return resultToWasmMemory(instanceContext, result)
+}
+
+// resultToWasmMemory scale encodes the result, writes it to wasm memory and returns
+// the corresponding 64 bit pointer size, or zero if an error occurs.
+func resultToWasmMemory(instanceContext wasm.InstanceContext, result scale.Result) C.int64_t {
+	enc, err := scale.Marshal(result)
+	if err != nil {
+		logger.Errorf("failed to scale marshal the result: %s", err)
+		return C.int64_t(0)
+	}
+
+	ptr, err := toWasmMemory(instanceContext, enc)
+	if err != nil {
+		logger.Errorf("failed to allocate result on memory: %s", err)
+		return C.int64_t(0)
+	}
+
+	return C.int64_t(ptr)
+}
+
 //export ext_storage_append_version_1
 func ext_storage_append_version_1(context unsafe.Pointer, keySpan, valueSpan C.int64_t) {
 	logger.Trace("executing...")
@@ -2041,7 +2571,18 @@ func ext_storage_root_version_1(context unsafe.Pointer) C.int64_t {
 
 //export ext_storage_root_version_2
 func ext_storage_root_version_2(context unsafe.Pointer, version C.int32_t) C.int64_t {
-	// TODO: update to use state trie version 1 (#2418)
+	logger.Trace("executing...")
+
+	stateVersion, err := trie.VersionFromUint32(uint32(version))
+	if err != nil {
+		logger.Errorf("failed parsing state version: %s", err)
+		return 0
+	}
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	storage := instanceContext.Data().(*runtime.Context).Storage
+	storage.SetVersion(stateVersion)
+
 	return ext_storage_root_version_1(context)
 }
 
@@ -2099,6 +2640,10 @@ func importsNodeRuntime() (imports *wasm.Imports, err error) {
 	}{
 		{"ext_allocator_free_version_1", ext_allocator_free_version_1, C.ext_allocator_free_version_1},
 		{"ext_allocator_malloc_version_1", ext_allocator_malloc_version_1, C.ext_allocator_malloc_version_1},
+		{"ext_crypto_ecdsa_generate_version_1", ext_crypto_ecdsa_generate_version_1, C.ext_crypto_ecdsa_generate_version_1},
+		{"ext_crypto_ecdsa_public_keys_version_1", ext_crypto_ecdsa_public_keys_version_1, C.ext_crypto_ecdsa_public_keys_version_1},
+		{"ext_crypto_ecdsa_sign_version_1", ext_crypto_ecdsa_sign_version_1, C.ext_crypto_ecdsa_sign_version_1},
+		{"ext_crypto_ecdsa_sign_prehashed_version_1", ext_crypto_ecdsa_sign_prehashed_version_1, C.ext_crypto_ecdsa_sign_prehashed_version_1},
 		{"ext_crypto_ecdsa_verify_version_2", ext_crypto_ecdsa_verify_version_2, C.ext_crypto_ecdsa_verify_version_2},
 		{"ext_crypto_ed25519_generate_version_1", ext_crypto_ed25519_generate_version_1, C.ext_crypto_ed25519_generate_version_1},
 		{"ext_crypto_ed25519_public_keys_version_1", ext_crypto_ed25519_public_keys_version_1, C.ext_crypto_ed25519_public_keys_version_1},
@@ -2122,6 +2667,7 @@ func importsNodeRuntime() (imports *wasm.Imports, err error) {
 		{"ext_default_child_storage_next_key_version_1", ext_default_child_storage_next_key_version_1, C.ext_default_child_storage_next_key_version_1},
 		{"ext_default_child_storage_read_version_1", ext_default_child_storage_read_version_1, C.ext_default_child_storage_read_version_1},
 		{"ext_default_child_storage_root_version_1", ext_default_child_storage_root_version_1, C.ext_default_child_storage_root_version_1},
+		{"ext_default_child_storage_root_version_2", ext_default_child_storage_root_version_2, C.ext_default_child_storage_root_version_2},
 		{"ext_default_child_storage_set_version_1", ext_default_child_storage_set_version_1, C.ext_default_child_storage_set_version_1},
 		{"ext_default_child_storage_storage_kill_version_1", ext_default_child_storage_storage_kill_version_1, C.ext_default_child_storage_storage_kill_version_1},
 		{"ext_default_child_storage_storage_kill_version_2", ext_default_child_storage_storage_kill_version_2, C.ext_default_child_storage_storage_kill_version_2},
@@ -2141,6 +2687,10 @@ func importsNodeRuntime() (imports *wasm.Imports, err error) {
 		{"ext_misc_runtime_version_version_1", ext_misc_runtime_version_version_1, C.ext_misc_runtime_version_version_1},
 		{"ext_offchain_http_request_add_header_version_1", ext_offchain_http_request_add_header_version_1, C.ext_offchain_http_request_add_header_version_1},
 		{"ext_offchain_http_request_start_version_1", ext_offchain_http_request_start_version_1, C.ext_offchain_http_request_start_version_1},
+		{"ext_offchain_http_request_write_body_version_1", ext_offchain_http_request_write_body_version_1, C.ext_offchain_http_request_write_body_version_1},
+		{"ext_offchain_http_response_headers_version_1", ext_offchain_http_response_headers_version_1, C.ext_offchain_http_response_headers_version_1},
+		{"ext_offchain_http_response_read_body_version_1", ext_offchain_http_response_read_body_version_1, C.ext_offchain_http_response_read_body_version_1},
+		{"ext_offchain_http_response_wait_version_1", ext_offchain_http_response_wait_version_1, C.ext_offchain_http_response_wait_version_1},
 		{"ext_offchain_index_set_version_1", ext_offchain_index_set_version_1, C.ext_offchain_index_set_version_1},
 		{"ext_offchain_is_validator_version_1", ext_offchain_is_validator_version_1, C.ext_offchain_is_validator_version_1},
 		{"ext_offchain_local_storage_clear_version_1", ext_offchain_local_storage_clear_version_1, C.ext_offchain_local_storage_clear_version_1},
@@ -2152,6 +2702,7 @@ func importsNodeRuntime() (imports *wasm.Imports, err error) {
 		{"ext_offchain_sleep_until_version_1", ext_offchain_sleep_until_version_1, C.ext_offchain_sleep_until_version_1},
 		{"ext_offchain_submit_transaction_version_1", ext_offchain_submit_transaction_version_1, C.ext_offchain_submit_transaction_version_1},
 		{"ext_offchain_timestamp_version_1", ext_offchain_timestamp_version_1, C.ext_offchain_timestamp_version_1},
+		{"ext_sandbox_get_global_val_version_1", ext_sandbox_get_global_val_version_1, C.ext_sandbox_get_global_val_version_1},
 		{"ext_sandbox_instance_teardown_version_1", ext_sandbox_instance_teardown_version_1, C.ext_sandbox_instance_teardown_version_1},
 		{"ext_sandbox_instantiate_version_1", ext_sandbox_instantiate_version_1, C.ext_sandbox_instantiate_version_1},
 		{"ext_sandbox_invoke_version_1", ext_sandbox_invoke_version_1, C.ext_sandbox_invoke_version_1},
@@ -2179,6 +2730,7 @@ func importsNodeRuntime() (imports *wasm.Imports, err error) {
 		{"ext_trie_blake2_256_ordered_root_version_1", ext_trie_blake2_256_ordered_root_version_1, C.ext_trie_blake2_256_ordered_root_version_1},
 		{"ext_trie_blake2_256_ordered_root_version_2", ext_trie_blake2_256_ordered_root_version_2, C.ext_trie_blake2_256_ordered_root_version_2},
 		{"ext_trie_blake2_256_root_version_1", ext_trie_blake2_256_root_version_1, C.ext_trie_blake2_256_root_version_1},
+		{"ext_trie_blake2_256_root_version_2", ext_trie_blake2_256_root_version_2, C.ext_trie_blake2_256_root_version_2},
 		{"ext_trie_blake2_256_verify_proof_version_1", ext_trie_blake2_256_verify_proof_version_1, C.ext_trie_blake2_256_verify_proof_version_1},
 	} {
 		_, err = imports.AppendFunction(toRegister.importName, toRegister.implementation, toRegister.cgoPointer)
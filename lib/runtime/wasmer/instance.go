@@ -14,6 +14,7 @@
 	"github.com/ChainSafe/gossamer/lib/keystore"
 	"github.com/ChainSafe/gossamer/lib/runtime"
 	"github.com/ChainSafe/gossamer/lib/runtime/offchain"
+	"github.com/ChainSafe/gossamer/lib/runtime/sandbox"
 	"github.com/ChainSafe/gossamer/lib/trie"
 
 	"github.com/ChainSafe/gossamer/lib/crypto"
@@ -40,6 +41,18 @@ type Instance struct {
 	isClosed bool
 	codeHash common.Hash
 	mutex    sync.Mutex
+	// module is the compiled module the instance is instantiated from,
+	// used to instantiate other instances of the same code cheaply.
+	module wasm.Module
+	// ownsModule is true if the module must be closed when the instance
+	// is closed, and false if the module is shared with another instance.
+	ownsModule bool
+	// pool is the pool of instances instantiated from the same
+	// code as the instance. It is nil if no pool is configured.
+	pool *instancePool
+	// config is the configuration used to create the instance, and
+	// used to create the instances instantiated from its module.
+	config Config
 }
 
 // NewRuntimeFromGenesis creates a runtime instance from the genesis data
@@ -81,11 +94,39 @@ func NewInstanceFromFile(fp string, cfg Config) (*Instance, error) {
 func NewInstance(code []byte, cfg Config) (instance *Instance, err error) {
 	logger.Patch(log.SetLevel(cfg.LogLvl), log.SetCallerFunc(true))
 
-	wasmInstance, allocator, err := setupVM(code)
+	module, err := setupVM(code)
 	if err != nil {
 		return nil, fmt.Errorf("setting up VM: %w", err)
 	}
 
+	instance, err = newInstanceFromModule(module, cfg, nil)
+	if err != nil {
+		module.Close()
+		return nil, err
+	}
+	instance.ownsModule = true
+
+	if cfg.PoolSize > 0 {
+		instance.pool, err = newInstancePool(module, cfg, instance.ctx.Version, cfg.PoolSize)
+		if err != nil {
+			instance.close()
+			return nil, fmt.Errorf("creating instance pool: %w", err)
+		}
+	}
+
+	return instance, nil
+}
+
+// newInstanceFromModule instantiates a runtime instance from the compiled module given,
+// which is not owned by the instance. If the runtime version given is nil, it is
+// obtained by calling the runtime.
+func newInstanceFromModule(module wasm.Module, cfg Config, version *runtime.Version) (
+	instance *Instance, err error) {
+	wasmInstance, allocator, err := instantiateVM(module)
+	if err != nil {
+		return nil, fmt.Errorf("instantiating VM: %w", err)
+	}
+
 	runtimeCtx := &runtime.Context{
 		Storage:         cfg.Storage,
 		Allocator:       allocator,
@@ -103,11 +144,17 @@ func NewInstance(code []byte, cfg Config) (instance *Instance, err error) {
 		vm:       wasmInstance,
 		ctx:      runtimeCtx,
 		codeHash: cfg.CodeHash,
+		module:   module,
+		config:   cfg,
 	}
+	runtimeCtx.Sandbox = sandbox.NewStore(&sandboxSupervisor{instance: instance})
 
-	if cfg.testVersion != nil {
+	switch {
+	case version != nil:
+		instance.ctx.Version = *version
+	case cfg.testVersion != nil:
 		instance.ctx.Version = *cfg.testVersion
-	} else {
+	default:
 		instance.ctx.Version, err = instance.version()
 		if err != nil {
 			instance.close()
@@ -146,17 +193,65 @@ func (in *Instance) GetContext() *runtime.Context {
 	return in.ctx
 }
 
+// PoolSize returns the number of instances in the instance pool of the runtime.
+func (in *Instance) PoolSize() int {
+	return in.config.PoolSize
+}
+
+// Acquire returns an instance from the instance pool of the runtime,
+// blocking until one is available, together with a function to release
+// the instance back to the pool once done with it.
+// The storage of the instance returned must be set with SetContextStorage
+// before use. If the runtime has no instance pool, a new instance isolated
+// from the runtime instance is instantiated from its compiled module, and
+// is stopped once released.
+func (in *Instance) Acquire() (instance runtime.Instance, release func(), err error) {
+	in.mutex.Lock()
+	pool := in.pool
+	if pool == nil {
+		defer in.mutex.Unlock()
+		if in.isClosed {
+			return nil, nil, ErrInstanceIsStopped
+		}
+
+		version := in.ctx.Version
+		isolated, err := newInstanceFromModule(in.module, isolatedConfig(in.config), &version)
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating isolated instance: %w", err)
+		}
+		return isolated, isolated.Stop, nil
+	}
+	in.mutex.Unlock()
+
+	pooled, err := pool.get()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	release = func() { pool.put(pooled) }
+	return pooled, release, nil
+}
+
 // UpdateRuntimeCode updates the runtime instance to run the given code
 func (in *Instance) UpdateRuntimeCode(code []byte) (err error) {
-	wasmInstance, allocator, err := setupVM(code)
+	module, err := setupVM(code)
 	if err != nil {
 		return fmt.Errorf("setting up VM: %w", err)
 	}
 
+	wasmInstance, allocator, err := instantiateVM(module)
+	if err != nil {
+		module.Close()
+		return fmt.Errorf("instantiating VM: %w", err)
+	}
+
 	in.mutex.Lock()
 	defer in.mutex.Unlock()
 
 	in.close()
+	in.isClosed = false
+	in.module = module
+	in.ownsModule = true
 
 	in.ctx.Allocator = allocator
 	wasmInstance.SetContextData(in.ctx)
@@ -173,6 +268,14 @@ func (in *Instance) UpdateRuntimeCode(code []byte) (err error) {
 	in.ctx.Version = version
 	wasmInstance.SetContextData(in.ctx)
 
+	if in.config.PoolSize > 0 {
+		in.pool, err = newInstancePool(module, in.config, version, in.config.PoolSize)
+		if err != nil {
+			in.close()
+			return fmt.Errorf("creating instance pool: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -201,19 +304,36 @@ func GetRuntimeVersion(code []byte) (version runtime.Version, err error) {
 	ErrWASMDecompress = errors.New("wasm decompression failed")
 )
 
-func setupVM(code []byte) (instance wasm.Instance,
-	allocator *runtime.FreeingBumpHeapAllocator, err error) {
+// setupVM decompresses and compiles the runtime code given.
+func setupVM(code []byte) (module wasm.Module, err error) {
 	if len(code) == 0 {
-		return instance, nil, ErrCodeEmpty
+		return module, ErrCodeEmpty
 	}
 
 	code, err = decompressWasm(code)
 	if err != nil {
 		// Note the sentinel error is wrapped here since the ztsd Go library
 		// does not return any exported sentinel errors.
-		return instance, nil, fmt.Errorf("%w: %s", ErrWASMDecompress, err)
+		return module, fmt.Errorf("%w: %s", ErrWASMDecompress, err)
 	}
 
+	code, err = sandbox.AddDispatchCaller(code)
+	if err != nil {
+		return module, fmt.Errorf("adding sandbox dispatch caller: %w", err)
+	}
+
+	module, err = wasm.Compile(code)
+	if err != nil {
+		return module, fmt.Errorf("compiling web assembly module: %w", err)
+	}
+
+	return module, nil
+}
+
+// instantiateVM instantiates the compiled module given with the node
+// runtime imports and its own memory.
+func instantiateVM(module wasm.Module) (instance wasm.Instance,
+	allocator *runtime.FreeingBumpHeapAllocator, err error) {
 	imports, err := importsNodeRuntime()
 	if err != nil {
 		return instance, nil, fmt.Errorf("creating node runtime imports: %w", err)
@@ -233,7 +353,7 @@ func setupVM(code []byte) (instance wasm.Instance,
 	}
 
 	// Instantiates the WebAssembly module.
-	instance, err = wasm.NewInstanceWithImports(code, imports)
+	instance, err = module.InstantiateWithImports(imports)
 	if err != nil {
 		return instance, nil, fmt.Errorf("creating web assembly instance: %w", err)
 	}
@@ -256,6 +376,18 @@ func setupVM(code []byte) (instance wasm.Instance,
 func (in *Instance) SetContextStorage(s runtime.Storage) {
 	in.mutex.Lock()
 	defer in.mutex.Unlock()
+
+	if s != nil {
+		// Storage values inserted or modified by the runtime are encoded
+		// using the state trie version defined in the runtime version.
+		stateVersion, err := trie.VersionFromUint32(in.ctx.Version.StateVersion)
+		if err != nil {
+			logger.Warnf("using state trie version %s: %s", trie.V0, err)
+			stateVersion = trie.V0
+		}
+		s.SetVersion(stateVersion)
+	}
+
 	in.ctx.Storage = s
 }
 
@@ -267,17 +399,25 @@ func (in *Instance) Stop() {
 	in.close()
 }
 
-// close closes the wasm instance (and its imports)
-// and clears the context allocator. If the instance
-// has previously been closed, it simply returns.
+// close closes the wasm instance (and its imports),
+// clears the context allocator and stops the instance pool.
+// If the instance has previously been closed, it simply returns.
 // It is NOT THREAD SAFE to use.
 func (in *Instance) close() {
 	if in.isClosed {
 		return
 	}
 
+	in.ctx.Sandbox.Reset()
 	in.vm.Close()
 	in.ctx.Allocator.Clear()
+	if in.pool != nil {
+		in.pool.stop()
+		in.pool = nil
+	}
+	if in.ownsModule {
+		in.module.Close()
+	}
 	in.isClosed = true
 }
 
@@ -302,6 +442,8 @@ func (in *Instance) Exec(function string, data []byte) (result []byte, err error
 	}
 
 	defer in.ctx.Allocator.Clear()
+	defer in.discardSignatureBatch()
+	defer in.ctx.Sandbox.Reset()
 
 	// Store the data into memory
 	memory := in.vm.Memory.Data()
@@ -322,6 +464,20 @@ func (in *Instance) Exec(function string, data []byte) (result []byte, err error
 	return memory[outputPtr : outputPtr+outputLength], nil
 }
 
+// discardSignatureBatch discards the batch of signature verification
+// left started by the runtime, for example if the runtime call failed,
+// such that signatures verified by the next runtime calls are not
+// added to the batch without ever being verified.
+// It is NOT THREAD SAFE to use.
+func (in *Instance) discardSignatureBatch() {
+	if !in.ctx.SigVerifier.IsStarted() {
+		return
+	}
+
+	logger.Warn("discarding batch of signature verification not finished by the runtime")
+	_ = in.ctx.SigVerifier.Finish()
+}
+
 // NodeStorage to get reference to runtime node service
 func (in *Instance) NodeStorage() runtime.NodeStorage {
 	return in.ctx.NodeStorage
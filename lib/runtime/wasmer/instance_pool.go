@@ -0,0 +1,124 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wasmer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
+)
+
+// ErrInstancePoolStopped is returned when trying to get an
+// instance from an instance pool which is stopped.
+var ErrInstancePoolStopped = errors.New("instance pool is stopped")
+
+// instancePool is a pool of runtime instances instantiated from the
+// same code, each with its own memory, allocator and context, such
+// that runtime calls can run in parallel on different instances.
+type instancePool struct {
+	instances chan *Instance
+	// module, config and version are used to instantiate
+	// the instances replacing the instances released.
+	module  wasm.Module
+	config  Config
+	version runtime.Version
+	// mutex protects the stopped field and sending to the instances
+	// channel, since the channel is closed when the pool is stopped.
+	// It also prevents the module from being closed, which is done
+	// once the pool is stopped, while instantiating an instance.
+	mutex   sync.Mutex
+	stopped bool
+}
+
+// newInstancePool creates an instance pool of the given size, with all its instances
+// instantiated from the given compiled module, configuration and runtime version.
+func newInstancePool(module wasm.Module, cfg Config, version runtime.Version, size int) (
+	pool *instancePool, err error) {
+	cfg = isolatedConfig(cfg)
+
+	pool = &instancePool{
+		instances: make(chan *Instance, size),
+		module:    module,
+		config:    cfg,
+		version:   version,
+	}
+
+	for i := 0; i < size; i++ {
+		instance, err := newInstanceFromModule(module, cfg, &version)
+		if err != nil {
+			pool.stop()
+			return nil, fmt.Errorf("creating instance %d of %d: %w", i+1, size, err)
+		}
+		pool.instances <- instance
+	}
+
+	return pool, nil
+}
+
+// isolatedConfig returns the configuration of instances instantiated from the module
+// of a runtime instance with the configuration given. These instances have their
+// storage set on each use and do not have an instance pool of their own.
+func isolatedConfig(cfg Config) Config {
+	cfg.Storage = nil
+	cfg.PoolSize = 0
+	return cfg
+}
+
+// get returns an instance from the pool, blocking until one is
+// available. It returns an error if the pool is stopped.
+func (p *instancePool) get() (instance *Instance, err error) {
+	instance, ok := <-p.instances
+	if !ok {
+		return nil, ErrInstancePoolStopped
+	}
+	return instance, nil
+}
+
+// put stops the instance given and puts a new instance instantiated from
+// the module in the pool, such that no memory, globals or context state
+// is carried over from one use of a pooled instance to the next one.
+// If the pool is stopped, the instance is only stopped.
+func (p *instancePool) put(instance *Instance) {
+	instance.Stop()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.stopped {
+		return
+	}
+
+	version := p.version
+	replacement, err := newInstanceFromModule(p.module, p.config, &version)
+	if err != nil {
+		// the pool shrinks by one instance, which should not happen
+		// since the module was already instantiated successfully.
+		logger.Errorf("failed to instantiate instance replacing a released pooled instance: %s", err)
+		return
+	}
+
+	// this never blocks since only instances taken from
+	// the pool are replaced in its buffered channel.
+	p.instances <- replacement
+}
+
+// stop stops the instance pool and all its instances. Instances
+// currently in use are stopped once they are put back in the pool.
+func (p *instancePool) stop() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.stopped {
+		return
+	}
+	p.stopped = true
+
+	close(p.instances)
+	for instance := range p.instances {
+		instance.Stop()
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wasmer
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPooledInstance(t *testing.T, poolSize int) *Instance {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	cfg := setupConfig(t, ctrl, nil, DefaultTestLogLvl, common.NoNetworkRole, runtime.NODE_RUNTIME)
+	cfg.PoolSize = poolSize
+
+	runtimeFilepath, err := runtime.GetRuntime(context.Background(), runtime.NODE_RUNTIME)
+	require.NoError(t, err)
+	code, err := os.ReadFile(runtimeFilepath)
+	require.NoError(t, err)
+
+	instance, err := NewInstance(code, cfg)
+	require.NoError(t, err)
+	t.Cleanup(instance.Stop)
+
+	return instance
+}
+
+func Test_Instance_Acquire(t *testing.T) {
+	t.Parallel()
+
+	t.Run("without_pool", func(t *testing.T) {
+		t.Parallel()
+
+		instance := newTestPooledInstance(t, 0)
+
+		acquired, release, err := instance.Acquire()
+		require.NoError(t, err)
+		assert.NotSame(t, instance, acquired)
+		assert.Equal(t, instance.Version(), acquired.Version())
+
+		acquired.SetContextStorage(storage.NewTrieState(trie.NewEmptyTrie()))
+		_, err = acquired.Exec(runtime.CoreVersion, []byte{})
+		require.NoError(t, err)
+		assert.Nil(t, instance.ctx.Storage)
+
+		// the isolated instance is stopped once released
+		release()
+		_, err = acquired.Exec(runtime.CoreVersion, []byte{})
+		assert.ErrorIs(t, err, ErrInstanceIsStopped)
+
+		// the runtime instance is not affected
+		_, err = instance.Exec(runtime.CoreVersion, []byte{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("concurrent_calls", func(t *testing.T) {
+		t.Parallel()
+
+		const poolSize = 2
+		instance := newTestPooledInstance(t, poolSize)
+		assert.Equal(t, poolSize, instance.PoolSize())
+		expectedVersion := instance.Version()
+
+		const calls = 10
+		var wg sync.WaitGroup
+		wg.Add(calls)
+		errs := make(chan error, calls)
+		for i := 0; i < calls; i++ {
+			go func() {
+				defer wg.Done()
+
+				acquired, release, err := instance.Acquire()
+				if err != nil {
+					errs <- err
+					return
+				}
+				defer release()
+
+				acquired.SetContextStorage(storage.NewTrieState(trie.NewEmptyTrie()))
+				_, err = acquired.Exec(runtime.CoreVersion, []byte{})
+				errs <- err
+				assert.Equal(t, expectedVersion, acquired.Version())
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("released_instance_is_replaced", func(t *testing.T) {
+		t.Parallel()
+
+		instance := newTestPooledInstance(t, 1)
+
+		acquired, release, err := instance.Acquire()
+		require.NoError(t, err)
+		assert.NotSame(t, instance, acquired)
+		acquired.SetContextStorage(storage.NewTrieState(trie.NewEmptyTrie()))
+		release()
+
+		// the released instance is stopped and replaced with a new
+		// instance, so its memory and globals are not reused.
+		_, err = acquired.Exec(runtime.CoreVersion, []byte{})
+		assert.ErrorIs(t, err, ErrInstanceIsStopped)
+
+		reacquired, release, err := instance.Acquire()
+		require.NoError(t, err)
+		defer release()
+		assert.NotSame(t, acquired, reacquired)
+		assert.Nil(t, reacquired.(*Instance).ctx.Storage)
+		assert.Equal(t, instance.Version(), reacquired.Version())
+
+		reacquired.SetContextStorage(storage.NewTrieState(trie.NewEmptyTrie()))
+		_, err = reacquired.Exec(runtime.CoreVersion, []byte{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("stopped_pool", func(t *testing.T) {
+		t.Parallel()
+
+		instance := newTestPooledInstance(t, 1)
+
+		acquired, release, err := instance.Acquire()
+		require.NoError(t, err)
+
+		instance.Stop()
+
+		_, _, err = instance.Acquire()
+		assert.ErrorIs(t, err, ErrInstancePoolStopped)
+
+		// the acquired instance is stopped once released
+		release()
+		_, err = acquired.Exec(runtime.CoreVersion, []byte{})
+		assert.ErrorIs(t, err, ErrInstanceIsStopped)
+	})
+}
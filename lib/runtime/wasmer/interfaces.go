@@ -31,6 +31,9 @@ type Storage interface {
 	BeginStorageTransaction()
 	CommitStorageTransaction()
 	RollbackStorageTransaction()
+	SetVersion(version trie.Version)
+	IndexTransaction(extrinsic, size uint32, hash common.Hash)
+	RenewTransaction(extrinsic uint32, hash common.Hash)
 	LoadCode() []byte
 }
 
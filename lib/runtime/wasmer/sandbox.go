@@ -0,0 +1,61 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wasmer
+
+import (
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/lib/runtime/sandbox"
+)
+
+// sandboxSupervisor implements the sandbox.Supervisor interface
+// using the memory, allocator and exports of the runtime instance.
+// Its methods are called from host functions, so while the instance
+// mutex is held, and must therefore not lock it.
+type sandboxSupervisor struct {
+	instance *Instance
+}
+
+func (s *sandboxSupervisor) Allocate(size uint32) (pointer uint32, err error) {
+	return s.instance.ctx.Allocator.Allocate(size)
+}
+
+func (s *sandboxSupervisor) Deallocate(pointer uint32) error {
+	return s.instance.ctx.Allocator.Deallocate(pointer)
+}
+
+func (s *sandboxSupervisor) Read(pointer, size uint32) (data []byte, ok bool) {
+	memory := s.instance.vm.Memory.Data()
+	if uint64(pointer)+uint64(size) > uint64(len(memory)) {
+		return nil, false
+	}
+	data = make([]byte, size)
+	copy(data, memory[pointer:pointer+size])
+	return data, true
+}
+
+func (s *sandboxSupervisor) Write(pointer uint32, data []byte) (ok bool) {
+	memory := s.instance.vm.Memory.Data()
+	if uint64(pointer)+uint64(len(data)) > uint64(len(memory)) {
+		return false
+	}
+	copy(memory[pointer:], data)
+	return true
+}
+
+func (s *sandboxSupervisor) Dispatch(thunk, argsPointer, argsSize, state, functionIndex uint32) (
+	resultPointerSize int64, err error) {
+	dispatchCaller, ok := s.instance.vm.Exports[sandbox.DispatchCallerExport]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrExportFunctionNotFound, sandbox.DispatchCallerExport)
+	}
+
+	result, err := dispatchCaller(int32(thunk), int32(argsPointer), int32(argsSize),
+		int32(state), int32(functionIndex))
+	if err != nil {
+		return 0, fmt.Errorf("calling dispatch thunk: %w", err)
+	}
+
+	return result.ToI64(), nil
+}
@@ -0,0 +1,328 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wazero
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ChainSafe/gossamer/lib/common/types"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/offchain"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/tetratelabs/wazero/api"
+)
+
+type contextKey struct{}
+
+// runtimeContextKey is the key of the runtime context value
+// in the context given to the host functions.
+var runtimeContextKey = contextKey{}
+
+// runtimeContext returns the runtime context stored in the context
+// given to the host functions by the instance calling the runtime.
+func runtimeContext(ctx context.Context) *runtime.Context {
+	runtimeCtx, ok := ctx.Value(runtimeContextKey).(*runtime.Context)
+	if !ok {
+		panic("runtime context not found in context")
+	}
+	return runtimeCtx
+}
+
+// memoryData returns the memory of the module given as a byte slice.
+// Note the byte slice is only valid until the memory grows.
+func memoryData(m api.Module) (data []byte) {
+	memory := m.Memory()
+	data, ok := memory.Read(0, memory.Size())
+	if !ok {
+		panic("cannot read module memory")
+	}
+	return data
+}
+
+// toPointerSize converts an uint32 pointer and uint32 size
+// to an int64 pointer size.
+func toPointerSize(ptr, size uint32) (pointerSize int64) {
+	return int64(ptr) | (int64(size) << 32)
+}
+
+// splitPointerSize converts an int64 pointer size to an
+// uint32 pointer and an uint32 size.
+func splitPointerSize(pointerSize int64) (ptr, size uint32) {
+	return uint32(pointerSize), uint32(pointerSize >> 32)
+}
+
+// asMemorySlice converts a 64 bit pointer size to a Go byte slice.
+func asMemorySlice(m api.Module, pointerSize int64) (data []byte) {
+	ptr, size := splitPointerSize(pointerSize)
+	data, ok := m.Memory().Read(ptr, size)
+	if !ok {
+		panic(fmt.Sprintf("out of range memory read at pointer %d with size %d", ptr, size))
+	}
+	return data
+}
+
+// toWasmMemory copies a Go byte slice to wasm memory and returns the corresponding
+// 64 bit pointer size.
+func toWasmMemory(ctx context.Context, m api.Module, data []byte) (
+	pointerSize int64, err error) {
+	size := uint32(len(data))
+	ptr, err := toWasmMemorySized(ctx, m, data)
+	if err != nil {
+		return 0, err
+	}
+	return toPointerSize(ptr, size), nil
+}
+
+// toWasmMemorySized copies a Go byte slice to wasm memory and returns the corresponding
+// 32 bit pointer. Note the data must have a well known fixed length in the runtime.
+func toWasmMemorySized(ctx context.Context, m api.Module, data []byte) (
+	pointer uint32, err error) {
+	allocator := runtimeContext(ctx).Allocator
+
+	size := uint32(len(data))
+	pointer, err = allocator.Allocate(size)
+	if err != nil {
+		return 0, fmt.Errorf("allocating: %w", err)
+	}
+
+	ok := m.Memory().Write(pointer, data)
+	if !ok {
+		panic(fmt.Sprintf("out of range memory write at pointer %d with size %d", pointer, size))
+	}
+
+	return pointer, nil
+}
+
+// toWasmMemoryOptional scale encodes the byte slice `data`, writes it to wasm memory
+// and returns the corresponding 64 bit pointer size.
+func toWasmMemoryOptional(ctx context.Context, m api.Module, data []byte) (
+	pointerSize int64, err error) {
+	var optionalSlice *[]byte
+	if data != nil {
+		optionalSlice = &data
+	}
+
+	encoded, err := scale.Marshal(optionalSlice)
+	if err != nil {
+		return 0, err
+	}
+
+	return toWasmMemory(ctx, m, encoded)
+}
+
+// toWasmMemoryResult wraps the data byte slice in a Result type, scale encodes it,
+// copies it to wasm memory and returns the corresponding 64 bit pointer size.
+func toWasmMemoryResult(ctx context.Context, m api.Module, data []byte) (
+	pointerSize int64, err error) {
+	var result *types.Result
+	if len(data) == 0 {
+		result = types.NewResult(byte(1), nil)
+	} else {
+		result = types.NewResult(byte(0), data)
+	}
+
+	encodedResult, err := result.Encode()
+	if err != nil {
+		return 0, fmt.Errorf("encoding result: %w", err)
+	}
+
+	return toWasmMemory(ctx, m, encodedResult)
+}
+
+// toWasmMemoryOptionalUint32 scale encodes the uint32 pointer `data`, writes it to wasm memory
+// and returns the corresponding 64 bit pointer size.
+func toWasmMemoryOptionalUint32(ctx context.Context, m api.Module, data *uint32) (
+	pointerSize int64, err error) {
+	enc, err := scale.Marshal(data)
+	if err != nil {
+		return 0, fmt.Errorf("scale encoding: %w", err)
+	}
+	return toWasmMemory(ctx, m, enc)
+}
+
+// toWasmMemoryFixedSizeOptional copies the `data` byte slice to a 64B array,
+// scale encodes the pointer to the resulting array, writes it to wasm memory
+// and returns the corresponding 64 bit pointer size.
+func toWasmMemoryFixedSizeOptional(ctx context.Context, m api.Module, data []byte) (
+	pointerSize int64, err error) {
+	var optionalFixedSize [64]byte
+	copy(optionalFixedSize[:], data)
+	encodedOptionalFixedSize, err := scale.Marshal(&optionalFixedSize)
+	if err != nil {
+		return 0, fmt.Errorf("scale encoding: %w", err)
+	}
+	return toWasmMemory(ctx, m, encodedOptionalFixedSize)
+}
+
+func mustToWasmMemoryNil(ctx context.Context, _ api.Module) (pointerSize int64) {
+	allocator := runtimeContext(ctx).Allocator
+	ptr, err := allocator.Allocate(0)
+	if err != nil {
+		// we allocate 0 byte, this should never fail
+		panic(err)
+	}
+	return toPointerSize(ptr, 0)
+}
+
+func toWasmMemoryOptionalNil(ctx context.Context, m api.Module) (
+	pointerSize int64, err error) {
+	return toWasmMemoryOptional(ctx, m, nil)
+}
+
+func mustToWasmMemoryOptionalNil(ctx context.Context, m api.Module) (
+	pointerSize int64) {
+	pointerSize, err := toWasmMemoryOptionalNil(ctx, m)
+	if err != nil {
+		panic(err)
+	}
+	return pointerSize
+}
+
+func toWasmMemoryResultEmpty(ctx context.Context, m api.Module) (
+	pointerSize int64, err error) {
+	return toWasmMemoryResult(ctx, m, nil)
+}
+
+func mustToWasmMemoryResultEmpty(ctx context.Context, m api.Module) (
+	pointerSize int64) {
+	pointerSize, err := toWasmMemoryResultEmpty(ctx, m)
+	if err != nil {
+		panic(err)
+	}
+	return pointerSize
+}
+
+// toKillStorageResultEnum encodes the `allRemoved` flag and
+// the `numRemoved` uint32 to a byte slice and returns it.
+// The format used is:
+// Byte 0: 1 if allRemoved is false, 0 otherwise
+// Byte 1-5: scale encoding of numRemoved (up to 4 bytes)
+func toKillStorageResultEnum(allRemoved bool, numRemoved uint32) (
+	encodedEnumValue []byte, err error) {
+	encodedNumRemoved, err := scale.Marshal(numRemoved)
+	if err != nil {
+		return nil, fmt.Errorf("scale encoding: %w", err)
+	}
+
+	encodedEnumValue = make([]byte, len(encodedNumRemoved)+1)
+	if !allRemoved {
+		// At least one key resides in the child trie due to the supplied limit.
+		encodedEnumValue[0] = 1
+	}
+	copy(encodedEnumValue[1:], encodedNumRemoved)
+
+	return encodedEnumValue, nil
+}
+
+// toHTTPRequestStatusesEnums encodes the offchain HTTP request statuses to a
+// scale encoded vector of HttpRequestStatus enum values and returns it.
+// The format used for each status is:
+// Byte 0: 0 if the deadline is reached, 1 for an IO error, 2 for an invalid
+// request and 3 if the response is received.
+// Byte 1-2: little endian HTTP status code, only if the response is received.
+func toHTTPRequestStatusesEnums(statuses []offchain.HTTPRequestStatus) (
+	encoded []byte, err error) {
+	encoded, err = scale.Marshal(uint(len(statuses)))
+	if err != nil {
+		return nil, fmt.Errorf("scale encoding length: %w", err)
+	}
+
+	const finished = 3
+	for _, status := range statuses {
+		if status.Err != nil {
+			encoded = append(encoded, offchain.HTTPErrorCode(status.Err))
+			continue
+		}
+		encoded = append(encoded, finished)
+		encoded = binary.LittleEndian.AppendUint16(encoded, status.StatusCode)
+	}
+
+	return encoded, nil
+}
+
+// decodeOffchainDeadline decodes the scale encoded optional timestamp in
+// milliseconds used as deadline by the offchain HTTP host functions.
+// A zero deadline is returned if the optional timestamp is none.
+func decodeOffchainDeadline(encoded []byte) (deadline time.Time, err error) {
+	var timestamp *uint64
+	err = scale.Unmarshal(encoded, &timestamp)
+	if err != nil {
+		return deadline, fmt.Errorf("scale decoding: %w", err)
+	}
+
+	if timestamp == nil {
+		return deadline, nil
+	}
+
+	return time.UnixMilli(int64(*timestamp)), nil
+}
+
+func storageAppend(storage GetSetter, key, valueToAppend []byte) (err error) {
+	// this function assumes the item in storage is a SCALE encoded array of items
+	// the valueToAppend is a new item, so it appends the item and increases the length prefix by 1
+	currentValue := storage.Get(key)
+
+	var value []byte
+	if len(currentValue) == 0 {
+		nextLength := big.NewInt(1)
+		encodedLength, err := scale.Marshal(nextLength)
+		if err != nil {
+			return fmt.Errorf("scale encoding: %w", err)
+		}
+		value = make([]byte, len(encodedLength)+len(valueToAppend))
+		// append new length prefix to start of items array
+		copy(value, encodedLength)
+		copy(value[len(encodedLength):], valueToAppend)
+	} else {
+		var currentLength *big.Int
+		err := scale.Unmarshal(currentValue, &currentLength)
+		if err != nil {
+			logger.Tracef(
+				"item in storage is not SCALE encoded, overwriting at key 0x%x", key)
+			value = make([]byte, 1+len(valueToAppend))
+			value[0] = 4
+			copy(value[1:], valueToAppend)
+		} else {
+			lengthBytes, err := scale.Marshal(currentLength)
+			if err != nil {
+				return fmt.Errorf("scale encoding: %w", err)
+			}
+
+			// increase length by 1
+			nextLength := big.NewInt(0).Add(currentLength, big.NewInt(1))
+			nextLengthBytes, err := scale.Marshal(nextLength)
+			if err != nil {
+				return fmt.Errorf("scale encoding next length bytes: %w", err)
+			}
+
+			// append new item, pop off number of bytes required for length encoding,
+			// since we're not using old scale.Decoder
+			value = make([]byte, len(nextLengthBytes)+len(currentValue)-len(lengthBytes)+len(valueToAppend))
+			// append new length prefix to start of items array
+			i := 0
+			copy(value[i:], nextLengthBytes)
+			i += len(nextLengthBytes)
+			copy(value[i:], currentValue[len(lengthBytes):])
+			i += len(currentValue) - len(lengthBytes)
+			copy(value[i:], valueToAppend)
+		}
+	}
+
+	err = storage.Put(key, value)
+	if err != nil {
+		return fmt.Errorf("putting key and value in storage: %w", err)
+	}
+
+	return nil
+}
+
+func panicOnError(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wazero
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/lib/runtime/offchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_pointerSize(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		ptr         uint32
+		size        uint32
+		pointerSize int64
+	}{
+		"0": {},
+		"ptr_8_size_32": {
+			ptr:         8,
+			size:        32,
+			pointerSize: int64(8) | (int64(32) << 32),
+		},
+		"ptr_max_uint32_and_size_max_uint32": {
+			ptr:         ^uint32(0),
+			size:        ^uint32(0),
+			pointerSize: ^int64(0),
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pointerSize := toPointerSize(testCase.ptr, testCase.size)
+
+			require.Equal(t, testCase.pointerSize, pointerSize)
+
+			ptr, size := splitPointerSize(pointerSize)
+
+			assert.Equal(t, testCase.ptr, ptr)
+			assert.Equal(t, testCase.size, size)
+		})
+	}
+}
+
+func Test_panicOnError(t *testing.T) {
+	t.Parallel()
+
+	err := (error)(nil)
+	assert.NotPanics(t, func() { panicOnError(err) })
+
+	err = errors.New("test error")
+	assert.PanicsWithValue(t, err, func() { panicOnError(err) })
+}
+
+func Test_toHTTPRequestStatusesEnums(t *testing.T) {
+	t.Parallel()
+
+	statuses := []offchain.HTTPRequestStatus{
+		{Err: offchain.ErrDeadlineReached},
+		{Err: offchain.ErrIO},
+		{Err: offchain.ErrInvalidRequest},
+		{StatusCode: 404},
+	}
+
+	encoded, err := toHTTPRequestStatusesEnums(statuses)
+
+	require.NoError(t, err)
+	expected := []byte{
+		4 << 2,     // compact length
+		0,          // deadline reached
+		1,          // io error
+		2,          // invalid
+		3, 0x94, 1, // finished with status code 404
+	}
+	assert.Equal(t, expected, encoded)
+}
+
+func Test_decodeOffchainDeadline(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		encoded    []byte
+		deadline   time.Time
+		errWrapped error
+		errMessage string
+	}{
+		"none": {
+			encoded: []byte{0},
+		},
+		"some": {
+			encoded:  []byte{1, 0xe8, 0x03, 0, 0, 0, 0, 0, 0},
+			deadline: time.UnixMilli(1000),
+		},
+		"decoding_error": {
+			encoded:    []byte{},
+			errWrapped: io.EOF,
+			errMessage: "scale decoding: EOF",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			deadline, err := decodeOffchainDeadline(testCase.encoded)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.deadline, deadline)
+		})
+	}
+}
@@ -0,0 +1,2460 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wazero
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/crypto"
+	"github.com/ChainSafe/gossamer/lib/crypto/ed25519"
+	"github.com/ChainSafe/gossamer/lib/crypto/secp256k1"
+	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/offchain"
+	"github.com/ChainSafe/gossamer/lib/runtime/sandbox"
+	"github.com/ChainSafe/gossamer/lib/transaction"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/lib/trie/proof"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+const (
+	validateSignatureFail = "failed to validate signature"
+)
+
+func ext_logging_log_version_1(ctx context.Context, m api.Module, level int32, targetData, msgData int64) {
+	logger.Trace("executing...")
+
+	target := string(asMemorySlice(m, targetData))
+	msg := string(asMemorySlice(m, msgData))
+
+	recorder, ok := runtimeContext(ctx).Storage.(runtime.LogRecorder)
+	if ok {
+		recorder.RecordLog(level, target, msg)
+	}
+
+	switch int(level) {
+	case 0:
+		logger.Critical("target=" + target + " message=" + msg)
+	case 1:
+		logger.Warn("target=" + target + " message=" + msg)
+	case 2:
+		logger.Info("target=" + target + " message=" + msg)
+	case 3:
+		logger.Debug("target=" + target + " message=" + msg)
+	case 4:
+		logger.Trace("target=" + target + " message=" + msg)
+	default:
+		logger.Errorf("level=%d target=%s message=%s", int(level), target, msg)
+	}
+}
+
+func ext_logging_max_level_version_1(ctx context.Context, m api.Module) int32 {
+	logger.Trace("executing...")
+	return 4
+}
+
+func ext_transaction_index_index_version_1(ctx context.Context, m api.Module, extrinsic, size, contextHash int32) {
+	logger.Trace("executing...")
+	memory := memoryData(m)
+	storage := runtimeContext(ctx).Storage
+
+	hash := common.NewHash(memory[contextHash : contextHash+32])
+	storage.IndexTransaction(uint32(extrinsic), uint32(size), hash)
+}
+
+func ext_transaction_index_renew_version_1(ctx context.Context, m api.Module, extrinsic, contextHash int32) {
+	logger.Trace("executing...")
+	memory := memoryData(m)
+	storage := runtimeContext(ctx).Storage
+
+	hash := common.NewHash(memory[contextHash : contextHash+32])
+	storage.RenewTransaction(uint32(extrinsic), hash)
+}
+
+func ext_sandbox_instance_teardown_version_1(ctx context.Context, m api.Module, instanceIndex int32) {
+	logger.Trace("executing...")
+	runtimeCtx := runtimeContext(ctx)
+
+	err := runtimeCtx.Sandbox.Teardown(uint32(instanceIndex))
+	if err != nil {
+		logger.Errorf("failed to teardown sandbox instance: %s", err)
+	}
+}
+
+func ext_sandbox_instantiate_version_1(ctx context.Context, m api.Module, dispatchThunk int32,
+	wasmCodeSpan, environmentSpan int64, state int32) int32 {
+	logger.Trace("executing...")
+	runtimeCtx := runtimeContext(ctx)
+
+	code := asMemorySlice(m, wasmCodeSpan)
+	environment := asMemorySlice(m, environmentSpan)
+
+	instanceIndex, err := runtimeCtx.Sandbox.Instantiate(uint32(dispatchThunk), code, environment, uint32(state))
+	if err != nil {
+		logger.Errorf("failed to instantiate sandbox instance: %s", err)
+		return int32(sandbox.ResultCode(err))
+	}
+
+	return int32(instanceIndex)
+}
+
+func ext_sandbox_invoke_version_1(ctx context.Context, m api.Module, instanceIndex int32,
+	exportNameSpan, argsSpan int64, returnValuePointer, returnValueSize, state int32) int32 {
+	logger.Trace("executing...")
+	runtimeCtx := runtimeContext(ctx)
+
+	exportName := string(asMemorySlice(m, exportNameSpan))
+	args := asMemorySlice(m, argsSpan)
+
+	err := runtimeCtx.Sandbox.Invoke(uint32(instanceIndex), exportName, args,
+		uint32(returnValuePointer), uint32(returnValueSize), uint32(state))
+	if err != nil {
+		logger.Debugf("failed to invoke sandbox instance function %s: %s", exportName, err)
+	}
+
+	return int32(sandbox.ResultCode(err))
+}
+
+func ext_sandbox_memory_get_version_1(ctx context.Context, m api.Module, memoryIndex, offset,
+	bufferPointer, bufferSize int32) int32 {
+	logger.Trace("executing...")
+	runtimeCtx := runtimeContext(ctx)
+
+	err := runtimeCtx.Sandbox.MemoryGet(uint32(memoryIndex), uint32(offset),
+		uint32(bufferPointer), uint32(bufferSize))
+	if err != nil {
+		logger.Debugf("failed to get sandbox memory: %s", err)
+	}
+
+	return int32(sandbox.ResultCode(err))
+}
+
+func ext_sandbox_memory_new_version_1(ctx context.Context, m api.Module, initial, maximum int32) int32 {
+	logger.Trace("executing...")
+	runtimeCtx := runtimeContext(ctx)
+
+	memoryIndex, err := runtimeCtx.Sandbox.NewMemory(uint32(initial), uint32(maximum))
+	if err != nil {
+		logger.Errorf("failed to create sandbox memory: %s", err)
+		return int32(sandbox.ResultCode(err))
+	}
+
+	return int32(memoryIndex)
+}
+
+func ext_sandbox_memory_set_version_1(ctx context.Context, m api.Module, memoryIndex, offset,
+	valuePointer, valueSize int32) int32 {
+	logger.Trace("executing...")
+	runtimeCtx := runtimeContext(ctx)
+
+	err := runtimeCtx.Sandbox.MemorySet(uint32(memoryIndex), uint32(offset),
+		uint32(valuePointer), uint32(valueSize))
+	if err != nil {
+		logger.Debugf("failed to set sandbox memory: %s", err)
+	}
+
+	return int32(sandbox.ResultCode(err))
+}
+
+func ext_sandbox_memory_teardown_version_1(ctx context.Context, m api.Module, memoryIndex int32) {
+	logger.Trace("executing...")
+	runtimeCtx := runtimeContext(ctx)
+
+	err := runtimeCtx.Sandbox.MemoryTeardown(uint32(memoryIndex))
+	if err != nil {
+		logger.Errorf("failed to teardown sandbox memory: %s", err)
+	}
+}
+
+func ext_sandbox_get_global_val_version_1(ctx context.Context, m api.Module, instanceIndex int32,
+	nameSpan int64) int64 {
+	logger.Trace("executing...")
+	runtimeCtx := runtimeContext(ctx)
+
+	name := string(asMemorySlice(m, nameSpan))
+	encoded, err := runtimeCtx.Sandbox.GetGlobal(uint32(instanceIndex), name)
+	if err != nil {
+		logger.Errorf("failed to get sandbox global %s: %s", name, err)
+		return 0
+	}
+
+	ret, err := toWasmMemory(ctx, m, encoded)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return ret
+}
+
+func ext_crypto_ed25519_generate_version_1(ctx context.Context, m api.Module, keyTypeID int32, seedSpan int64) int32 {
+	logger.Trace("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+	seedBytes := asMemorySlice(m, seedSpan)
+
+	var seed *[]byte
+	err := scale.Unmarshal(seedBytes, &seed)
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	var kp KeyPair
+
+	if seed != nil {
+		kp, err = ed25519.NewKeypairFromMnenomic(string(*seed), "")
+	} else {
+		kp, err = ed25519.GenerateKeypair()
+	}
+
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		return 0
+	}
+
+	err = ks.Insert(kp)
+	if err != nil {
+		logger.Warnf("failed to insert key: %s", err)
+		return 0
+	}
+
+	ret, err := toWasmMemorySized(ctx, m, kp.Public().Encode())
+	if err != nil {
+		logger.Warnf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	logger.Debug("generated ed25519 keypair with public key: " + kp.Public().Hex())
+	return int32(ret)
+}
+
+func ext_crypto_ed25519_public_keys_version_1(ctx context.Context, m api.Module, keyTypeID int32) int64 {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	if ks.Type() != crypto.Ed25519Type && ks.Type() != crypto.UnknownType {
+		logger.Warnf(
+			"error for id 0x%x: keystore type is %s and not the expected ed25519",
+			id, ks.Type())
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	keys := ks.PublicKeys()
+
+	var encodedKeys []byte
+	for _, key := range keys {
+		encodedKeys = append(encodedKeys, key.Encode()...)
+	}
+
+	prefix, err := scale.Marshal(big.NewInt(int64(len(keys))))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	ret, err := toWasmMemory(ctx, m, append(prefix, encodedKeys...))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ = toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	return int64(ret)
+}
+
+func ext_crypto_ed25519_sign_version_1(ctx context.Context, m api.Module, keyTypeID, key int32, msg int64) int64 {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	pubKeyData := memory[key : key+32]
+	pubKey, err := ed25519.NewPublicKey(pubKeyData)
+	if err != nil {
+		logger.Errorf("failed to get public keys: %s", err)
+		return 0
+	}
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		return mustToWasmMemoryOptionalNil(ctx, m)
+	}
+
+	signingKey := ks.GetKeypair(pubKey)
+	if signingKey == nil {
+		logger.Error("could not find public key " + pubKey.Hex() + " in keystore")
+		ret, err := toWasmMemoryOptionalNil(ctx, m)
+		if err != nil {
+			logger.Errorf("failed to allocate memory: %s", err)
+			return 0
+		}
+		return ret
+	}
+
+	sig, err := signingKey.Sign(asMemorySlice(m, msg))
+	if err != nil {
+		logger.Error("could not sign message")
+	}
+
+	ret, err := toWasmMemoryFixedSizeOptional(ctx, m, sig)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	return int64(ret)
+}
+
+func ext_crypto_ed25519_verify_version_1(ctx context.Context, m api.Module, sig int32,
+	msg int64, key int32) int32 {
+	logger.Debug("executing...")
+
+	memory := memoryData(m)
+	sigVerifier := runtimeContext(ctx).SigVerifier
+
+	signature := memory[sig : sig+64]
+	message := asMemorySlice(m, msg)
+	pubKeyData := memory[key : key+32]
+
+	pubKey, err := ed25519.NewPublicKey(pubKeyData)
+	if err != nil {
+		logger.Error("failed to create public key")
+		return 0
+	}
+
+	if sigVerifier.IsStarted() {
+		signature := crypto.SignatureInfo{
+			PubKey:     pubKey.Encode(),
+			Sign:       signature,
+			Msg:        message,
+			VerifyFunc: ed25519.VerifySignature,
+		}
+		sigVerifier.Add(&signature)
+		return 1
+	}
+
+	if ok, err := pubKey.Verify(message, signature); err != nil || !ok {
+		logger.Error("failed to verify")
+		return 0
+	}
+
+	logger.Debug("verified ed25519 signature")
+	return 1
+}
+
+func ext_crypto_secp256k1_ecdsa_recover_version_1(ctx context.Context, m api.Module, sig, msg int32) int64 {
+	logger.Trace("executing...")
+	memory := memoryData(m)
+
+	// msg must be the 32-byte hash of the message to be signed.
+	// sig must be a 65-byte compact ECDSA signature containing the
+	// recovery id as the last element
+	message := memory[msg : msg+32]
+	signature := memory[sig : sig+65]
+
+	pub, err := secp256k1.RecoverPublicKey(message, signature)
+	if err != nil {
+		logger.Errorf("failed to recover public key: %s", err)
+		ret, err := toWasmMemoryResultEmpty(ctx, m)
+		if err != nil {
+			logger.Errorf("failed to allocate memory: %s", err)
+			return 0
+		}
+		return ret
+	}
+
+	logger.Debugf(
+		"recovered public key of length %d: 0x%x",
+		len(pub), pub)
+
+	ret, err := toWasmMemoryResult(ctx, m, pub[1:])
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	return int64(ret)
+}
+
+func ext_crypto_secp256k1_ecdsa_recover_version_2(ctx context.Context, m api.Module, sig, msg int32) int64 {
+	logger.Trace("executing...")
+	return ext_crypto_secp256k1_ecdsa_recover_version_1(ctx, m, sig, msg)
+}
+
+func ext_crypto_ecdsa_generate_version_1(ctx context.Context, m api.Module, keyTypeID int32, seedSpan int64) int32 {
+	logger.Trace("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+	seedBytes := asMemorySlice(m, seedSpan)
+
+	var seed *[]byte
+	err := scale.Unmarshal(seedBytes, &seed)
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	var kp KeyPair
+
+	if seed != nil {
+		kp, err = secp256k1.NewKeypairFromMnenomic(string(*seed), "")
+	} else {
+		kp, err = secp256k1.GenerateKeypair()
+	}
+
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		return 0
+	}
+
+	err = ks.Insert(kp)
+	if err != nil {
+		logger.Warnf("failed to insert key: %s", err)
+		return 0
+	}
+
+	ret, err := toWasmMemorySized(ctx, m, kp.Public().Encode())
+	if err != nil {
+		logger.Warnf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	logger.Debug("generated ecdsa keypair with public key: " + kp.Public().Hex())
+	return int32(ret)
+}
+
+func ext_crypto_ecdsa_public_keys_version_1(ctx context.Context, m api.Module, keyTypeID int32) int64 {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return ret
+	}
+
+	if ks.Type() != crypto.Secp256k1Type && ks.Type() != crypto.UnknownType {
+		logger.Warnf(
+			"error for id 0x%x: keystore type is %s and not the expected secp256k1",
+			id, ks.Type())
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return ret
+	}
+
+	var encodedKeys []byte
+	var keysCount int64
+	for _, key := range ks.PublicKeys() {
+		// generic keystores can contain keys of other types
+		if _, ok := key.(*secp256k1.PublicKey); !ok {
+			continue
+		}
+		encodedKeys = append(encodedKeys, key.Encode()...)
+		keysCount++
+	}
+
+	prefix, err := scale.Marshal(big.NewInt(keysCount))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return ret
+	}
+
+	ret, err := toWasmMemory(ctx, m, append(prefix, encodedKeys...))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ = toWasmMemory(ctx, m, []byte{0})
+		return ret
+	}
+
+	return ret
+}
+
+func ext_crypto_ecdsa_sign_version_1(ctx context.Context, m api.Module, keyTypeID, key int32, msg int64) int64 {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+	pubKeyData := memory[key : key+33]
+
+	hash, err := common.Blake2bHash(asMemorySlice(m, msg))
+	if err != nil {
+		logger.Errorf("failed to hash message: %s", err)
+		return mustToWasmMemoryOptionalNil(ctx, m)
+	}
+
+	ret, err := ecdsaSign(ctx, m, runtimeCtx.Keystore, id, pubKeyData, hash[:])
+	if err != nil {
+		logger.Errorf("failed to sign message: %s", err)
+		return 0
+	}
+
+	return ret
+}
+
+func ext_crypto_ecdsa_sign_prehashed_version_1(ctx context.Context, m api.Module, keyTypeID, key, msg int32) int64 {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+	pubKeyData := memory[key : key+33]
+	hash := memory[msg : msg+32]
+
+	ret, err := ecdsaSign(ctx, m, runtimeCtx.Keystore, id, pubKeyData, hash)
+	if err != nil {
+		logger.Errorf("failed to sign message: %s", err)
+		return 0
+	}
+
+	return ret
+}
+
+// ecdsaSign signs the 32 bytes message hash given with the secp256k1 key
+// matching the public key given from the keystore with the key type id given.
+// It writes the SCALE encoded optional 65 bytes signature to memory and returns
+// its pointer size, where the signature is none if the key is not found.
+func ecdsaSign(ctx context.Context, m api.Module, globalKeystore *keystore.GlobalKeystore,
+	id, pubKeyData, hash []byte) (pointerSize int64, err error) {
+	pubKey := new(secp256k1.PublicKey)
+	err = pubKey.Decode(pubKeyData)
+	if err != nil {
+		logger.Errorf("failed to decode public key: %s", err)
+		return toWasmMemoryOptionalNil(ctx, m)
+	}
+
+	ks, err := globalKeystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		return toWasmMemoryOptionalNil(ctx, m)
+	}
+
+	signingKey := ks.GetKeypair(pubKey)
+	if signingKey == nil {
+		logger.Error("could not find public key " + pubKey.Hex() + " in keystore")
+		return toWasmMemoryOptionalNil(ctx, m)
+	}
+
+	signature, err := signingKey.Sign(hash)
+	if err != nil {
+		logger.Errorf("could not sign message: %s", err)
+		return toWasmMemoryOptionalNil(ctx, m)
+	}
+
+	// the signature is encoded as a SCALE option of a fixed size array.
+	pointerSize, err = toWasmMemory(ctx, m, append([]byte{1}, signature...))
+	if err != nil {
+		return 0, fmt.Errorf("writing signature to memory: %w", err)
+	}
+
+	return pointerSize, nil
+}
+
+func ext_crypto_ecdsa_verify_version_2(ctx context.Context, m api.Module, sig int32, msg int64, key int32) int32 {
+	logger.Trace("executing...")
+
+	memory := memoryData(m)
+	sigVerifier := runtimeContext(ctx).SigVerifier
+
+	message := asMemorySlice(m, msg)
+	signature := memory[sig : sig+64]
+	pubKey := memory[key : key+33]
+
+	pub := new(secp256k1.PublicKey)
+	err := pub.Decode(pubKey)
+	if err != nil {
+		logger.Errorf("failed to decode public key: %s", err)
+		return int32(0)
+	}
+
+	logger.Debugf("pub=%s, message=0x%x, signature=0x%x",
+		pub.Hex(), fmt.Sprintf("0x%x", message), fmt.Sprintf("0x%x", signature))
+
+	hash, err := common.Blake2bHash(message)
+	if err != nil {
+		logger.Errorf("failed to hash message: %s", err)
+		return int32(0)
+	}
+
+	if sigVerifier.IsStarted() {
+		signature := crypto.SignatureInfo{
+			PubKey:     pub.Encode(),
+			Sign:       signature,
+			Msg:        hash[:],
+			VerifyFunc: secp256k1.VerifySignature,
+		}
+		sigVerifier.Add(&signature)
+		return int32(1)
+	}
+
+	ok, err := pub.Verify(hash[:], signature)
+	if err != nil || !ok {
+		message := validateSignatureFail
+		if err != nil {
+			message += ": " + err.Error()
+		}
+		logger.Errorf(message)
+		return int32(0)
+	}
+
+	logger.Debug("validated signature")
+	return int32(1)
+}
+
+func ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(ctx context.Context, m api.Module, sig, msg int32) int64 {
+	logger.Trace("executing...")
+	memory := memoryData(m)
+
+	// msg must be the 32-byte hash of the message to be signed.
+	// sig must be a 65-byte compact ECDSA signature containing the
+	// recovery id as the last element
+	message := memory[msg : msg+32]
+	signature := memory[sig : sig+65]
+
+	cpub, err := secp256k1.RecoverPublicKeyCompressed(message, signature)
+	if err != nil {
+		logger.Errorf("failed to recover public key: %s", err)
+		return mustToWasmMemoryResultEmpty(ctx, m)
+	}
+
+	logger.Debugf(
+		"recovered public key of length %d: 0x%x",
+		len(cpub), cpub)
+
+	ret, err := toWasmMemoryResult(ctx, m, cpub)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	return int64(ret)
+}
+
+func ext_crypto_secp256k1_ecdsa_recover_compressed_version_2(ctx context.Context, m api.Module, sig, msg int32) int64 {
+	logger.Trace("executing...")
+	return ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(ctx, m, sig, msg)
+}
+
+func ext_crypto_sr25519_generate_version_1(ctx context.Context, m api.Module, keyTypeID int32, seedSpan int64) int32 {
+	logger.Trace("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+	seedBytes := asMemorySlice(m, seedSpan)
+
+	var seed *[]byte
+	err := scale.Unmarshal(seedBytes, &seed)
+	if err != nil {
+		logger.Warnf("cannot generate key: %s", err)
+		return 0
+	}
+
+	var kp KeyPair
+	if seed != nil {
+		kp, err = sr25519.NewKeypairFromMnenomic(string(*seed), "")
+	} else {
+		kp, err = sr25519.GenerateKeypair()
+	}
+
+	if err != nil {
+		logger.Tracef("cannot generate key: %s", err)
+		panic(err)
+	}
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id "+common.BytesToHex(id)+": %s", err)
+		return 0
+	}
+
+	err = ks.Insert(kp)
+	if err != nil {
+		logger.Warnf("failed to insert key: %s", err)
+		return 0
+	}
+
+	ret, err := toWasmMemorySized(ctx, m, kp.Public().Encode())
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	logger.Debug("generated sr25519 keypair with public key: " + kp.Public().Hex())
+	return int32(ret)
+}
+
+func ext_crypto_sr25519_public_keys_version_1(ctx context.Context, m api.Module, keyTypeID int32) int64 {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id "+common.BytesToHex(id)+": %s", err)
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	if ks.Type() != crypto.Sr25519Type && ks.Type() != crypto.UnknownType {
+		logger.Warnf(
+			"keystore type for id 0x%x is %s and not expected sr25519",
+			id, ks.Type())
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	keys := ks.PublicKeys()
+
+	var encodedKeys []byte
+	for _, key := range keys {
+		encodedKeys = append(encodedKeys, key.Encode()...)
+	}
+
+	prefix, err := scale.Marshal(big.NewInt(int64(len(keys))))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ := toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	ret, err := toWasmMemory(ctx, m, append(prefix, encodedKeys...))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ = toWasmMemory(ctx, m, []byte{0})
+		return int64(ret)
+	}
+
+	return int64(ret)
+}
+
+func ext_crypto_sr25519_sign_version_1(ctx context.Context, m api.Module, keyTypeID, key int32, msg int64) int64 {
+	logger.Debug("executing...")
+	runtimeCtx := runtimeContext(ctx)
+	memory := memoryData(m)
+
+	id := memory[keyTypeID : keyTypeID+4]
+
+	ks, err := runtimeCtx.Keystore.GetKeystore(id)
+	if err != nil {
+		logger.Warnf("error for id 0x%x: %s", id, err)
+		return mustToWasmMemoryOptionalNil(ctx, m)
+	}
+
+	var ret int64
+	pubKey, err := sr25519.NewPublicKey(memory[key : key+32])
+	if err != nil {
+		logger.Errorf("failed to get public key: %s", err)
+		return mustToWasmMemoryOptionalNil(ctx, m)
+	}
+
+	signingKey := ks.GetKeypair(pubKey)
+	if signingKey == nil {
+		logger.Error("could not find public key " + pubKey.Hex() + " in keystore")
+		return mustToWasmMemoryOptionalNil(ctx, m)
+	}
+
+	msgData := asMemorySlice(m, msg)
+	sig, err := signingKey.Sign(msgData)
+	if err != nil {
+		logger.Errorf("could not sign message: %s", err)
+		return mustToWasmMemoryOptionalNil(ctx, m)
+	}
+
+	ret, err = toWasmMemoryFixedSizeOptional(ctx, m, sig)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return mustToWasmMemoryOptionalNil(ctx, m)
+	}
+
+	return int64(ret)
+}
+
+func ext_crypto_sr25519_verify_version_1(ctx context.Context, m api.Module, sig int32,
+	msg int64, key int32) int32 {
+	logger.Debug("executing...")
+
+	memory := memoryData(m)
+	sigVerifier := runtimeContext(ctx).SigVerifier
+
+	message := asMemorySlice(m, msg)
+	signature := memory[sig : sig+64]
+
+	pub, err := sr25519.NewPublicKey(memory[key : key+32])
+	if err != nil {
+		logger.Error("invalid sr25519 public key")
+		return 0
+	}
+
+	logger.Debugf(
+		"pub=%s message=0x%x signature=0x%x",
+		pub.Hex(), message, signature)
+
+	if sigVerifier.IsStarted() {
+		signature := crypto.SignatureInfo{
+			PubKey:     pub.Encode(),
+			Sign:       signature,
+			Msg:        message,
+			VerifyFunc: sr25519.VerifySignature,
+		}
+		sigVerifier.Add(&signature)
+		return 1
+	}
+
+	ok, err := pub.VerifyDeprecated(message, signature)
+	if err != nil || !ok {
+		message := validateSignatureFail
+		if err != nil {
+			message += ": " + err.Error()
+		}
+		logger.Debugf(message)
+		// this fails at block 3876, which seems to be expected, based on discussions
+		return 1
+	}
+
+	logger.Debug("verified sr25519 signature")
+	return 1
+}
+
+func ext_crypto_sr25519_verify_version_2(ctx context.Context, m api.Module, sig int32,
+	msg int64, key int32) int32 {
+	logger.Trace("executing...")
+
+	memory := memoryData(m)
+	sigVerifier := runtimeContext(ctx).SigVerifier
+
+	message := asMemorySlice(m, msg)
+	signature := memory[sig : sig+64]
+
+	pub, err := sr25519.NewPublicKey(memory[key : key+32])
+	if err != nil {
+		logger.Error("invalid sr25519 public key")
+		return 0
+	}
+
+	logger.Debugf(
+		"pub=%s; message=0x%x; signature=0x%x",
+		pub.Hex(), message, signature)
+
+	if sigVerifier.IsStarted() {
+		signature := crypto.SignatureInfo{
+			PubKey:     pub.Encode(),
+			Sign:       signature,
+			Msg:        message,
+			VerifyFunc: sr25519.VerifySignature,
+		}
+		sigVerifier.Add(&signature)
+		return 1
+	}
+
+	ok, err := pub.Verify(message, signature)
+	if err != nil || !ok {
+		message := validateSignatureFail
+		if err != nil {
+			message += ": " + err.Error()
+		}
+		logger.Errorf(message)
+		return 0
+	}
+
+	logger.Debug("validated signature")
+	return int32(1)
+}
+
+func ext_crypto_start_batch_verify_version_1(ctx context.Context, m api.Module) {
+	logger.Debug("executing...")
+
+	sigVerifier := runtimeContext(ctx).SigVerifier
+	sigVerifier.Start()
+}
+
+func ext_crypto_finish_batch_verify_version_1(ctx context.Context, m api.Module) int32 {
+	logger.Debug("executing...")
+
+	sigVerifier := runtimeContext(ctx).SigVerifier
+	if !sigVerifier.IsStarted() {
+		logger.Error("batch verification is not started")
+	}
+
+	if !sigVerifier.Finish() {
+		logger.Error("failed to verify batch of signatures")
+		return 0
+	}
+
+	return 1
+}
+
+func ext_trie_blake2_256_root_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Debug("executing...")
+	return trieRoot(ctx, m, dataSpan, trie.V0)
+}
+
+func ext_trie_blake2_256_root_version_2(ctx context.Context, m api.Module,
+	dataSpan int64, version int32) int32 {
+	logger.Debug("executing...")
+
+	stateVersion, err := trie.VersionFromUint32(uint32(version))
+	if err != nil {
+		logger.Errorf("failed parsing state version: %s", err)
+		return 0
+	}
+
+	return trieRoot(ctx, m, dataSpan, stateVersion)
+}
+
+// trieRoot computes the Merkle root hash of the trie built from the
+// SCALE encoded (key, value) tuples at the data span given, using the
+// state trie version given, and returns a pointer to the root hash.
+func trieRoot(ctx context.Context, m api.Module, dataSpan int64, version trie.Version) int32 {
+	runtimeCtx := runtimeContext(ctx)
+	data := asMemorySlice(m, dataSpan)
+
+	t := trie.NewEmptyTrie()
+	t.SetVersion(version)
+
+	type kv struct {
+		Key, Value []byte
+	}
+
+	// this function is expecting an array of (key, value) tuples
+	var kvs []kv
+	if err := scale.Unmarshal(data, &kvs); err != nil {
+		logger.Errorf("failed scale decoding data: %s", err)
+		return 0
+	}
+
+	for _, kv := range kvs {
+		err := t.Put(kv.Key, kv.Value)
+		if err != nil {
+			logger.Errorf("failed putting key 0x%x and value 0x%x into trie: %s",
+				kv.Key, kv.Value, err)
+			return 0
+		}
+	}
+
+	// allocate memory for value and copy value to memory
+	ptr, err := runtimeCtx.Allocator.Allocate(32)
+	if err != nil {
+		logger.Errorf("failed allocating: %s", err)
+		return 0
+	}
+
+	hash, err := t.Hash()
+	if err != nil {
+		logger.Errorf("failed computing trie Merkle root hash: %s", err)
+		return 0
+	}
+
+	logger.Debugf("root hash is %s", hash)
+	// the memory is read after allocating since the allocation may grow it.
+	memory := memoryData(m)
+	copy(memory[ptr:ptr+32], hash[:])
+	return int32(ptr)
+}
+
+func ext_trie_blake2_256_ordered_root_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Debug("executing...")
+	return trieOrderedRoot(ctx, m, dataSpan, trie.V0)
+}
+
+func ext_trie_blake2_256_ordered_root_version_2(ctx context.Context, m api.Module,
+	dataSpan int64, version int32) int32 {
+	logger.Debug("executing...")
+
+	stateVersion, err := trie.VersionFromUint32(uint32(version))
+	if err != nil {
+		logger.Errorf("failed parsing state version: %s", err)
+		return 0
+	}
+
+	return trieOrderedRoot(ctx, m, dataSpan, stateVersion)
+}
+
+// trieOrderedRoot computes the Merkle root hash of the trie built from
+// the SCALE encoded values at the data span given, keyed by their
+// SCALE encoded index, using the state trie version given, and returns
+// a pointer to the root hash.
+func trieOrderedRoot(ctx context.Context, m api.Module, dataSpan int64, version trie.Version) int32 {
+	runtimeCtx := runtimeContext(ctx)
+	data := asMemorySlice(m, dataSpan)
+
+	t := trie.NewEmptyTrie()
+	t.SetVersion(version)
+	var values [][]byte
+	err := scale.Unmarshal(data, &values)
+	if err != nil {
+		logger.Errorf("failed scale decoding data: %s", err)
+		return 0
+	}
+
+	for i, value := range values {
+		key, err := scale.Marshal(big.NewInt(int64(i)))
+		if err != nil {
+			logger.Errorf("failed scale encoding value index %d: %s", i, err)
+			return 0
+		}
+		logger.Tracef(
+			"put key=0x%x and value=0x%x",
+			key, value)
+
+		err = t.Put(key, value)
+		if err != nil {
+			logger.Errorf("failed putting key 0x%x and value 0x%x into trie: %s",
+				key, value, err)
+			return 0
+		}
+	}
+
+	// allocate memory for value and copy value to memory
+	ptr, err := runtimeCtx.Allocator.Allocate(32)
+	if err != nil {
+		logger.Errorf("failed allocating: %s", err)
+		return 0
+	}
+
+	hash, err := t.Hash()
+	if err != nil {
+		logger.Errorf("failed computing trie Merkle root hash: %s", err)
+		return 0
+	}
+
+	logger.Debugf("root hash is %s", hash)
+	// the memory is read after allocating since the allocation may grow it.
+	memory := memoryData(m)
+	copy(memory[ptr:ptr+32], hash[:])
+	return int32(ptr)
+}
+
+func ext_trie_blake2_256_verify_proof_version_1(ctx context.Context, m api.Module,
+	rootSpan int32, proofSpan, keySpan, valueSpan int64) int32 {
+	logger.Debug("executing...")
+
+	toDecProofs := asMemorySlice(m, proofSpan)
+	var encodedProofNodes [][]byte
+	err := scale.Unmarshal(toDecProofs, &encodedProofNodes)
+	if err != nil {
+		logger.Errorf("failed scale decoding proof data: %s", err)
+		return int32(0)
+	}
+
+	key := asMemorySlice(m, keySpan)
+	value := asMemorySlice(m, valueSpan)
+
+	mem := memoryData(m)
+	trieRoot := mem[rootSpan : rootSpan+32]
+
+	err = proof.Verify(encodedProofNodes, trieRoot, key, value)
+	if err != nil {
+		logger.Errorf("failed proof verification: %s", err)
+		return int32(0)
+	}
+
+	return int32(1)
+}
+
+func ext_misc_print_hex_version_1(ctx context.Context, m api.Module, dataSpan int64) {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(m, dataSpan)
+	logger.Debugf("data: 0x%x", data)
+}
+
+func ext_misc_print_num_version_1(_ context.Context, _ api.Module, data int64) {
+	logger.Trace("executing...")
+
+	logger.Debugf("num: %d", int64(data))
+}
+
+func ext_misc_print_utf8_version_1(ctx context.Context, m api.Module, dataSpan int64) {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(m, dataSpan)
+	logger.Debug("utf8: " + string(data))
+}
+
+func ext_misc_runtime_version_version_1(ctx context.Context, m api.Module, dataSpan int64) int64 {
+	logger.Trace("executing...")
+
+	code := asMemorySlice(m, dataSpan)
+
+	version, err := GetRuntimeVersion(code)
+	if err != nil {
+		logger.Errorf("failed to get runtime version: %s", err)
+		return mustToWasmMemoryOptionalNil(ctx, m)
+	}
+
+	// Note the encoding contains all the latest Core_version fields as defined in
+	// https://spec.polkadot.network/#defn-rt-core-version
+	// In other words, decoding older version data with missing fields
+	// and then encoding it will result in a longer encoding due to the
+	// extra version fields. This however remains compatible since the
+	// version fields are still encoded in the same order and an older
+	// decoder would succeed with the longer encoding.
+	encodedData, err := scale.Marshal(version)
+	if err != nil {
+		logger.Errorf("failed to encode result: %s", err)
+		return 0
+	}
+
+	out, err := toWasmMemoryOptional(ctx, m, encodedData)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(out)
+}
+
+func ext_default_child_storage_read_version_1(ctx context.Context, m api.Module,
+	childStorageKey, key, valueOut int64, offset int32) int64 {
+	logger.Debug("executing...")
+
+	storage := runtimeContext(ctx).Storage
+	memory := memoryData(m)
+
+	keyToChild := asMemorySlice(m, childStorageKey)
+	keyBytes := asMemorySlice(m, key)
+	value, err := storage.GetChildStorage(keyToChild, keyBytes)
+	if err != nil {
+		logger.Errorf("failed to get child storage: %s", err)
+		return 0
+	}
+
+	valueBuf, valueLen := splitPointerSize(int64(valueOut))
+	copy(memory[valueBuf:valueBuf+valueLen], value[offset:])
+
+	size := uint32(len(value[offset:]))
+	sizeBuf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(sizeBuf, size)
+
+	sizeSpan, err := toWasmMemoryOptional(ctx, m, sizeBuf)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(sizeSpan)
+}
+
+func ext_default_child_storage_clear_version_1(ctx context.Context, m api.Module, childStorageKey, keySpan int64) {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	storage := runtimeCtx.Storage
+
+	keyToChild := asMemorySlice(m, childStorageKey)
+	key := asMemorySlice(m, keySpan)
+
+	err := storage.ClearChildStorage(keyToChild, key)
+	if err != nil {
+		logger.Errorf("failed to clear child storage: %s", err)
+	}
+}
+
+func ext_default_child_storage_clear_prefix_version_1(ctx context.Context, m api.Module,
+	childStorageKey, prefixSpan int64) {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	storage := runtimeCtx.Storage
+
+	keyToChild := asMemorySlice(m, childStorageKey)
+	prefix := asMemorySlice(m, prefixSpan)
+
+	err := storage.ClearPrefixInChild(keyToChild, prefix)
+	if err != nil {
+		logger.Errorf("failed to clear prefix in child: %s", err)
+	}
+}
+
+func ext_default_child_storage_exists_version_1(ctx context.Context, m api.Module,
+	childStorageKey, key int64) int32 {
+	logger.Debug("executing...")
+
+	storage := runtimeContext(ctx).Storage
+
+	keyToChild := asMemorySlice(m, childStorageKey)
+	keyBytes := asMemorySlice(m, key)
+	child, err := storage.GetChildStorage(keyToChild, keyBytes)
+	if err != nil {
+		logger.Errorf("failed to get child from child storage: %s", err)
+		return 0
+	}
+	if child != nil {
+		return 1
+	}
+	return 0
+}
+
+func ext_default_child_storage_get_version_1(ctx context.Context, m api.Module, childStorageKey, key int64) int64 {
+	logger.Debug("executing...")
+
+	storage := runtimeContext(ctx).Storage
+
+	keyToChild := asMemorySlice(m, childStorageKey)
+	keyBytes := asMemorySlice(m, key)
+	child, err := storage.GetChildStorage(keyToChild, keyBytes)
+	if err != nil {
+		logger.Errorf("failed to get child from child storage: %s", err)
+		return 0
+	}
+
+	value, err := toWasmMemoryOptional(ctx, m, child)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(value)
+}
+
+func ext_default_child_storage_next_key_version_1(ctx context.Context, m api.Module, childStorageKey, key int64) int64 {
+	logger.Debug("executing...")
+
+	storage := runtimeContext(ctx).Storage
+
+	keyToChild := asMemorySlice(m, childStorageKey)
+	keyBytes := asMemorySlice(m, key)
+	child, err := storage.GetChildNextKey(keyToChild, keyBytes)
+	if err != nil {
+		logger.Errorf("failed to get child's next key: %s", err)
+		return 0
+	}
+
+	value, err := toWasmMemoryOptional(ctx, m, child)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(value)
+}
+
+func ext_default_child_storage_root_version_1(ctx context.Context, m api.Module,
+	childStorageKey int64) (ptrSize int64) {
+	logger.Debug("executing...")
+	return childStorageRoot(ctx, m, childStorageKey, nil)
+}
+
+func ext_default_child_storage_root_version_2(ctx context.Context, m api.Module,
+	childStorageKey int64, version int32) (ptrSize int64) {
+	logger.Debug("executing...")
+
+	stateVersion, err := trie.VersionFromUint32(uint32(version))
+	if err != nil {
+		logger.Errorf("failed parsing state version: %s", err)
+		return 0
+	}
+
+	return childStorageRoot(ctx, m, childStorageKey, &stateVersion)
+}
+
+// childStorageRoot returns a pointer size to the Merkle root hash of the
+// child trie at the child storage key span given. If the version given is
+// not nil, the child trie version is set to it before computing the root.
+func childStorageRoot(ctx context.Context, m api.Module, childStorageKey int64,
+	version *trie.Version) (ptrSize int64) {
+	storage := runtimeContext(ctx).Storage
+
+	child, err := storage.GetChild(asMemorySlice(m, childStorageKey))
+	if err != nil {
+		logger.Errorf("failed to retrieve child: %s", err)
+		return 0
+	}
+
+	if version != nil {
+		child.SetVersion(*version)
+	}
+
+	childRoot, err := child.Hash()
+	if err != nil {
+		logger.Errorf("failed to encode child root: %s", err)
+		return 0
+	}
+
+	root, err := toWasmMemoryOptional(ctx, m, childRoot[:])
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(root)
+}
+
+func ext_default_child_storage_set_version_1(ctx context.Context, m api.Module,
+	childStorageKeySpan, keySpan, valueSpan int64) {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	storage := runtimeCtx.Storage
+
+	childStorageKey := asMemorySlice(m, childStorageKeySpan)
+	key := asMemorySlice(m, keySpan)
+	value := asMemorySlice(m, valueSpan)
+
+	cp := make([]byte, len(value))
+	copy(cp, value)
+
+	err := storage.SetChildStorage(childStorageKey, key, cp)
+	if err != nil {
+		logger.Errorf("failed to set value in child storage: %s", err)
+		return
+	}
+}
+
+func ext_default_child_storage_storage_kill_version_1(ctx context.Context, m api.Module, childStorageKeySpan int64) {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	storage := runtimeCtx.Storage
+
+	childStorageKey := asMemorySlice(m, childStorageKeySpan)
+	err := storage.DeleteChild(childStorageKey)
+	panicOnError(err)
+}
+
+func ext_default_child_storage_storage_kill_version_2(ctx context.Context, m api.Module,
+	childStorageKeySpan, lim int64) (allDeleted int32) {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	storage := runtimeCtx.Storage
+	childStorageKey := asMemorySlice(m, childStorageKeySpan)
+
+	limitBytes := asMemorySlice(m, lim)
+
+	var limit *[]byte
+	err := scale.Unmarshal(limitBytes, &limit)
+	if err != nil {
+		logger.Warnf("cannot generate limit: %s", err)
+		return 0
+	}
+
+	_, all, err := storage.DeleteChildLimit(childStorageKey, limit)
+	if err != nil {
+		logger.Warnf("cannot get child storage: %s", err)
+	}
+
+	if all {
+		return 1
+	}
+
+	return 0
+}
+
+type noneRemain uint32
+
+func (noneRemain) Index() uint       { return 0 }
+func (nr noneRemain) String() string { return fmt.Sprintf("noneRemain(%d)", nr) }
+
+type someRemain uint32
+
+func (someRemain) Index() uint       { return 1 }
+func (sr someRemain) String() string { return fmt.Sprintf("someRemain(%d)", sr) }
+
+func ext_default_child_storage_storage_kill_version_3(ctx context.Context, m api.Module,
+	childStorageKeySpan, lim int64) (pointerSize int64) {
+	logger.Debug("executing...")
+	runtimeCtx := runtimeContext(ctx)
+	storage := runtimeCtx.Storage
+	childStorageKey := asMemorySlice(m, childStorageKeySpan)
+
+	limitBytes := asMemorySlice(m, lim)
+
+	var limit *[]byte
+	err := scale.Unmarshal(limitBytes, &limit)
+	if err != nil {
+		logger.Warnf("cannot generate limit: %s", err)
+	}
+
+	deleted, all, err := storage.DeleteChildLimit(childStorageKey, limit)
+	if err != nil {
+		logger.Warnf("cannot get child storage: %s", err)
+		return int64(0)
+	}
+
+	vdt, err := scale.NewVaryingDataType(noneRemain(0), someRemain(0))
+	if err != nil {
+		logger.Warnf("cannot create new varying data type: %s", err)
+	}
+
+	if all {
+		err = vdt.Set(noneRemain(deleted))
+	} else {
+		err = vdt.Set(someRemain(deleted))
+	}
+	if err != nil {
+		logger.Warnf("cannot set varying data type: %s", err)
+		return int64(0)
+	}
+
+	encoded, err := scale.Marshal(vdt)
+	if err != nil {
+		logger.Warnf("problem marshalling varying data type: %s", err)
+		return int64(0)
+	}
+
+	out, err := toWasmMemoryOptional(ctx, m, encoded)
+	if err != nil {
+		logger.Warnf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(out)
+}
+
+func ext_allocator_free_version_1(ctx context.Context, m api.Module, addr int32) {
+	logger.Trace("executing...")
+	runtimeCtx := runtimeContext(ctx)
+
+	// Deallocate memory
+	err := runtimeCtx.Allocator.Deallocate(uint32(addr))
+	if err != nil {
+		logger.Errorf("failed to free memory: %s", err)
+	}
+}
+
+func ext_allocator_malloc_version_1(ctx context.Context, m api.Module, size int32) int32 {
+	logger.Tracef("executing with size %d...", int64(size))
+
+	runtimeCtx := runtimeContext(ctx)
+
+	// Allocate memory
+	res, err := runtimeCtx.Allocator.Allocate(uint32(size))
+	if err != nil {
+		logger.Criticalf("failed to allocate memory: %s", err)
+		panic(err)
+	}
+
+	return int32(res)
+}
+
+func ext_hashing_blake2_128_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(m, dataSpan)
+
+	hash, err := common.Blake2b128(data)
+	if err != nil {
+		logger.Errorf("failed hashing data: %s", err)
+		return 0
+	}
+
+	logger.Debugf(
+		"data 0x%x has hash 0x%x",
+		data, hash)
+
+	out, err := toWasmMemorySized(ctx, m, hash)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int32(out)
+}
+
+func ext_hashing_blake2_256_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(m, dataSpan)
+
+	hash, err := common.Blake2bHash(data)
+	if err != nil {
+		logger.Errorf("failed hashing data: %s", err)
+		return 0
+	}
+
+	logger.Debugf("data 0x%x has hash %s", data, hash)
+
+	out, err := toWasmMemorySized(ctx, m, hash[:])
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int32(out)
+}
+
+func ext_hashing_keccak_256_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(m, dataSpan)
+
+	hash, err := common.Keccak256(data)
+	if err != nil {
+		logger.Errorf("failed hashing data: %s", err)
+		return 0
+	}
+
+	logger.Debugf("data 0x%x has hash %s", data, hash)
+
+	out, err := toWasmMemorySized(ctx, m, hash[:])
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int32(out)
+}
+
+func ext_hashing_sha2_256_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(m, dataSpan)
+	hash := common.Sha256(data)
+
+	logger.Debugf("data 0x%x has hash %s", data, hash)
+
+	out, err := toWasmMemorySized(ctx, m, hash[:])
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int32(out)
+}
+
+func ext_hashing_twox_256_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(m, dataSpan)
+
+	hash, err := common.Twox256(data)
+	if err != nil {
+		logger.Errorf("failed hashing data: %s", err)
+		return 0
+	}
+
+	logger.Debugf("data 0x%x has hash %s", data, hash)
+
+	out, err := toWasmMemorySized(ctx, m, hash[:])
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int32(out)
+}
+
+func ext_hashing_twox_128_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Trace("executing...")
+	data := asMemorySlice(m, dataSpan)
+
+	hash, err := common.Twox128Hash(data)
+	if err != nil {
+		logger.Errorf("failed hashing data: %s", err)
+		return 0
+	}
+
+	logger.Debugf(
+		"data 0x%x hash hash 0x%x",
+		data, hash)
+
+	out, err := toWasmMemorySized(ctx, m, hash)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int32(out)
+}
+
+func ext_hashing_twox_64_version_1(ctx context.Context, m api.Module, dataSpan int64) int32 {
+	logger.Trace("executing...")
+
+	data := asMemorySlice(m, dataSpan)
+
+	hash, err := common.Twox64(data)
+	if err != nil {
+		logger.Errorf("failed hashing data: %s", err)
+		return 0
+	}
+
+	logger.Debugf(
+		"data 0x%x has hash 0x%x",
+		data, hash)
+
+	out, err := toWasmMemorySized(ctx, m, hash)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int32(out)
+}
+
+func ext_offchain_index_set_version_1(ctx context.Context, m api.Module, keySpan, valueSpan int64) {
+	logger.Trace("executing...")
+	runtimeCtx := runtimeContext(ctx)
+
+	storageKey := asMemorySlice(m, keySpan)
+	newValue := asMemorySlice(m, valueSpan)
+	cp := make([]byte, len(newValue))
+	copy(cp, newValue)
+
+	err := runtimeCtx.NodeStorage.BaseDB.Put(storageKey, cp)
+	if err != nil {
+		logger.Errorf("failed to set value in raw storage: %s", err)
+	}
+}
+
+func ext_offchain_local_storage_clear_version_1(ctx context.Context, m api.Module, kind int32, key int64) {
+	logger.Trace("executing...")
+	runtimeCtx := runtimeContext(ctx)
+
+	storageKey := asMemorySlice(m, key)
+
+	memory := memoryData(m)
+	kindInt := binary.LittleEndian.Uint32(memory[kind : kind+4])
+
+	var err error
+
+	switch runtime.NodeStorageType(kindInt) {
+	case runtime.NodeStorageTypePersistent:
+		err = runtimeCtx.NodeStorage.PersistentStorage.Del(storageKey)
+	case runtime.NodeStorageTypeLocal:
+		err = runtimeCtx.NodeStorage.LocalStorage.Del(storageKey)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to clear value from storage: %s", err)
+	}
+}
+
+func ext_offchain_is_validator_version_1(ctx context.Context, m api.Module) int32 {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	if runtimeCtx.Validator {
+		return 1
+	}
+	return 0
+}
+
+func ext_offchain_local_storage_compare_and_set_version_1(ctx context.Context, m api.Module,
+	kind int32, key, oldValue, newValue int64) (newValueSet int32) {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+
+	storageKey := asMemorySlice(m, key)
+
+	var storedValue []byte
+	var err error
+
+	switch runtime.NodeStorageType(kind) {
+	case runtime.NodeStorageTypePersistent:
+		storedValue, err = runtimeCtx.NodeStorage.PersistentStorage.Get(storageKey)
+	case runtime.NodeStorageTypeLocal:
+		storedValue, err = runtimeCtx.NodeStorage.LocalStorage.Get(storageKey)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to get value from storage: %s", err)
+		return 0
+	}
+
+	oldVal := asMemorySlice(m, oldValue)
+	newVal := asMemorySlice(m, newValue)
+	if reflect.DeepEqual(storedValue, oldVal) {
+		cp := make([]byte, len(newVal))
+		copy(cp, newVal)
+		err = runtimeCtx.NodeStorage.LocalStorage.Put(storageKey, cp)
+		if err != nil {
+			logger.Errorf("failed to set value in storage: %s", err)
+			return 0
+		}
+	}
+
+	return 1
+}
+
+func ext_offchain_local_storage_get_version_1(ctx context.Context, m api.Module, kind int32, key int64) int64 {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	storageKey := asMemorySlice(m, key)
+
+	var res []byte
+	var err error
+
+	switch runtime.NodeStorageType(kind) {
+	case runtime.NodeStorageTypePersistent:
+		res, err = runtimeCtx.NodeStorage.PersistentStorage.Get(storageKey)
+	case runtime.NodeStorageTypeLocal:
+		res, err = runtimeCtx.NodeStorage.LocalStorage.Get(storageKey)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to get value from storage: %s", err)
+	}
+	// allocate memory for value and copy value to memory
+	ptr, err := toWasmMemoryOptional(ctx, m, res)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+	return int64(ptr)
+}
+
+func ext_offchain_local_storage_set_version_1(ctx context.Context, m api.Module, kind int32, key, value int64) {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	storageKey := asMemorySlice(m, key)
+	newValue := asMemorySlice(m, value)
+	cp := make([]byte, len(newValue))
+	copy(cp, newValue)
+
+	var err error
+	switch runtime.NodeStorageType(kind) {
+	case runtime.NodeStorageTypePersistent:
+		err = runtimeCtx.NodeStorage.PersistentStorage.Put(storageKey, cp)
+	case runtime.NodeStorageTypeLocal:
+		err = runtimeCtx.NodeStorage.LocalStorage.Put(storageKey, cp)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to set value in storage: %s", err)
+	}
+}
+
+func ext_offchain_network_state_version_1(ctx context.Context, m api.Module) int64 {
+	logger.Debug("executing...")
+	runtimeCtx := runtimeContext(ctx)
+	if runtimeCtx.Network == nil {
+		return 0
+	}
+
+	nsEnc, err := scale.Marshal(runtimeCtx.Network.NetworkState())
+	if err != nil {
+		logger.Errorf("failed at encoding network state: %s", err)
+		return 0
+	}
+
+	// allocate memory for value and copy value to memory
+	ptr, err := toWasmMemorySized(ctx, m, nsEnc)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	return int64(ptr)
+}
+
+func ext_offchain_random_seed_version_1(ctx context.Context, m api.Module) int32 {
+	logger.Debug("executing...")
+
+	seed := make([]byte, 32)
+	_, err := rand.Read(seed)
+	if err != nil {
+		logger.Errorf("failed to generate random seed: %s", err)
+	}
+	ptr, err := toWasmMemorySized(ctx, m, seed)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+	}
+	return int32(ptr)
+}
+
+func ext_offchain_submit_transaction_version_1(ctx context.Context, m api.Module, data int64) int64 {
+	logger.Debug("executing...")
+
+	extBytes := asMemorySlice(m, data)
+
+	var extrinsic []byte
+	err := scale.Unmarshal(extBytes, &extrinsic)
+	if err != nil {
+		logger.Errorf("failed to decode extrinsic data: %s", err)
+	}
+
+	// validate the transaction
+	txv := transaction.NewValidity(0, [][]byte{{}}, [][]byte{{}}, 0, false)
+	vtx := transaction.NewValidTransaction(extrinsic, txv)
+
+	runtimeCtx := runtimeContext(ctx)
+	runtimeCtx.Transaction.AddToPool(vtx)
+
+	ptr, err := toWasmMemoryOptionalNil(ctx, m)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+	}
+	return ptr
+}
+
+func ext_offchain_timestamp_version_1(_ context.Context, _ api.Module) int64 {
+	logger.Trace("executing...")
+
+	now := time.Now().Unix()
+	return int64(now)
+}
+
+func ext_offchain_sleep_until_version_1(_ context.Context, _ api.Module, deadline int64) {
+	logger.Trace("executing...")
+
+	dur := time.Until(time.UnixMilli(int64(deadline)))
+	if dur > 0 {
+		time.Sleep(dur)
+	}
+}
+
+func ext_offchain_http_request_start_version_1(ctx context.Context, m api.Module,
+	methodSpan, uriSpan, metaSpan int64) (pointerSize int64) {
+	logger.Debug("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+
+	httpMethod := asMemorySlice(m, methodSpan)
+	uri := asMemorySlice(m, uriSpan)
+
+	result := scale.NewResult(int16(0), nil)
+
+	reqID, err := runtimeCtx.OffchainHTTPSet.StartRequest(string(httpMethod), string(uri))
+	if err != nil {
+		// StartRequest error already was logged
+		logger.Errorf("failed to start request: %s", err)
+		err = result.Set(scale.Err, nil)
+	} else {
+		err = result.Set(scale.OK, reqID)
+	}
+
+	// note: just check if an error occurs while setting the result data
+	if err != nil {
+		logger.Errorf("failed to set the result data: %s", err)
+		return int64(0)
+	}
+
+	enc, err := scale.Marshal(result)
+	if err != nil {
+		logger.Errorf("failed to scale marshal the result: %s", err)
+		return int64(0)
+	}
+
+	ptr, err := toWasmMemory(ctx, m, enc)
+	if err != nil {
+		logger.Errorf("failed to allocate result on memory: %s", err)
+		return int64(0)
+	}
+
+	return int64(ptr)
+}
+
+func ext_offchain_http_request_add_header_version_1(ctx context.Context, m api.Module,
+	reqID int32, nameSpan, valueSpan int64) (pointerSize int64) {
+	logger.Debug("executing...")
+
+	name := asMemorySlice(m, nameSpan)
+	value := asMemorySlice(m, valueSpan)
+
+	runtimeCtx := runtimeContext(ctx)
+	offchainReq := runtimeCtx.OffchainHTTPSet.Get(int16(reqID))
+
+	result := scale.NewResult(nil, nil)
+	resultMode := scale.OK
+
+	var err error
+	if offchainReq == nil {
+		logger.Errorf("failed to add request header: request id %d not found", reqID)
+		resultMode = scale.Err
+	} else if err = offchainReq.AddHeader(string(name), string(value)); err != nil {
+		logger.Errorf("failed to add request header: %s", err)
+		resultMode = scale.Err
+	}
+
+	err = result.Set(resultMode, nil)
+	if err != nil {
+		logger.Errorf("failed to set the result data: %s", err)
+		return int64(0)
+	}
+
+	enc, err := scale.Marshal(result)
+	if err != nil {
+		logger.Errorf("failed to scale marshal the result: %s", err)
+		return int64(0)
+	}
+
+	ptr, err := toWasmMemory(ctx, m, enc)
+	if err != nil {
+		logger.Errorf("failed to allocate result on memory: %s", err)
+		return int64(0)
+	}
+
+	return int64(ptr)
+}
+
+func ext_offchain_http_request_write_body_version_1(ctx context.Context, m api.Module,
+	reqID int32, chunkSpan, deadlineSpan int64) (pointerSize int64) {
+	logger.Debug("executing...")
+	runtimeCtx := runtimeContext(ctx)
+
+	chunk := asMemorySlice(m, chunkSpan)
+
+	result := scale.NewResult(nil, byte(0))
+
+	deadline, err := decodeOffchainDeadline(asMemorySlice(m, deadlineSpan))
+	if err != nil {
+		logger.Errorf("failed to decode deadline: %s", err)
+		err = result.Set(scale.Err, offchain.HTTPErrorCode(offchain.ErrInvalidRequest))
+	} else if err = runtimeCtx.OffchainHTTPSet.WriteBody(int16(reqID), chunk, deadline); err != nil {
+		logger.Errorf("failed to write request body: %s", err)
+		err = result.Set(scale.Err, offchain.HTTPErrorCode(err))
+	} else {
+		err = result.Set(scale.OK, nil)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to set the result data: %s", err)
+		return int64(0)
+	}
+
+	return resultToWasmMemory(ctx, m, result)
+}
+
+func ext_offchain_http_response_wait_version_1(ctx context.Context, m api.Module,
+	idsSpan, deadlineSpan int64) (pointerSize int64) {
+	logger.Debug("executing...")
+	runtimeCtx := runtimeContext(ctx)
+
+	var encodedIDs []uint16
+	err := scale.Unmarshal(asMemorySlice(m, idsSpan), &encodedIDs)
+	if err != nil {
+		logger.Errorf("failed to decode request ids: %s", err)
+		return int64(0)
+	}
+
+	ids := make([]int16, len(encodedIDs))
+	for i, id := range encodedIDs {
+		ids[i] = int16(id)
+	}
+
+	deadline, err := decodeOffchainDeadline(asMemorySlice(m, deadlineSpan))
+	if err != nil {
+		logger.Errorf("failed to decode deadline: %s", err)
+		return int64(0)
+	}
+
+	statuses := runtimeCtx.OffchainHTTPSet.WaitResponses(ids, deadline)
+
+	enc, err := toHTTPRequestStatusesEnums(statuses)
+	if err != nil {
+		logger.Errorf("failed to encode request statuses: %s", err)
+		return int64(0)
+	}
+
+	ptr, err := toWasmMemory(ctx, m, enc)
+	if err != nil {
+		logger.Errorf("failed to allocate result on memory: %s", err)
+		return int64(0)
+	}
+
+	return int64(ptr)
+}
+
+func ext_offchain_http_response_headers_version_1(ctx context.Context, m api.Module,
+	reqID int32) (pointerSize int64) {
+	logger.Debug("executing...")
+	runtimeCtx := runtimeContext(ctx)
+
+	headers := runtimeCtx.OffchainHTTPSet.ResponseHeaders(int16(reqID))
+	if headers == nil {
+		headers = []offchain.HTTPHeader{}
+	}
+
+	enc, err := scale.Marshal(headers)
+	if err != nil {
+		logger.Errorf("failed to scale marshal the headers: %s", err)
+		return int64(0)
+	}
+
+	ptr, err := toWasmMemory(ctx, m, enc)
+	if err != nil {
+		logger.Errorf("failed to allocate result on memory: %s", err)
+		return int64(0)
+	}
+
+	return int64(ptr)
+}
+
+func ext_offchain_http_response_read_body_version_1(ctx context.Context, m api.Module,
+	reqID int32, bufferSpan, deadlineSpan int64) (pointerSize int64) {
+	logger.Debug("executing...")
+	runtimeCtx := runtimeContext(ctx)
+
+	// the response body is read directly into the wasm memory buffer
+	buffer := asMemorySlice(m, bufferSpan)
+
+	result := scale.NewResult(uint32(0), byte(0))
+
+	deadline, err := decodeOffchainDeadline(asMemorySlice(m, deadlineSpan))
+	if err != nil {
+		logger.Errorf("failed to decode deadline: %s", err)
+		err = result.Set(scale.Err, offchain.HTTPErrorCode(offchain.ErrInvalidRequest))
+	} else if n, readErr := runtimeCtx.OffchainHTTPSet.ReadResponseBody(
+		int16(reqID), buffer, deadline); readErr != nil {
+		logger.Errorf("failed to read response body: %s", readErr)
+		err = result.Set(scale.Err, offchain.HTTPErrorCode(readErr))
+	} else {
+		err = result.Set(scale.OK, uint32(n))
+	}
+
+	if err != nil {
+		logger.Errorf("failed to set the result data: %s", err)
+		return int64(0)
+	}
+
+	return resultToWasmMemory(ctx, m, result)
+}
+
+// resultToWasmMemory scale encodes the result, writes it to wasm memory and returns
+// the corresponding 64 bit pointer size, or zero if an error occurs.
+func resultToWasmMemory(ctx context.Context, m api.Module, result scale.Result) int64 {
+	enc, err := scale.Marshal(result)
+	if err != nil {
+		logger.Errorf("failed to scale marshal the result: %s", err)
+		return int64(0)
+	}
+
+	ptr, err := toWasmMemory(ctx, m, enc)
+	if err != nil {
+		logger.Errorf("failed to allocate result on memory: %s", err)
+		return int64(0)
+	}
+
+	return int64(ptr)
+}
+
+func ext_storage_append_version_1(ctx context.Context, m api.Module, keySpan, valueSpan int64) {
+	logger.Trace("executing...")
+	runtimeCtx := runtimeContext(ctx)
+	storage := runtimeCtx.Storage
+
+	key := asMemorySlice(m, keySpan)
+	valueAppend := asMemorySlice(m, valueSpan)
+	logger.Debugf(
+		"will append value 0x%x to values at key 0x%x",
+		valueAppend, key)
+
+	cp := make([]byte, len(valueAppend))
+	copy(cp, valueAppend)
+
+	err := storageAppend(storage, key, cp)
+	if err != nil {
+		logger.Errorf("failed appending to storage: %s", err)
+	}
+}
+
+func ext_storage_changes_root_version_1(ctx context.Context, m api.Module, parentHashSpan int64) int64 {
+	logger.Trace("executing...")
+	logger.Debug("returning None")
+
+	rootSpan, err := toWasmMemoryOptionalNil(ctx, m)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return rootSpan
+}
+
+func ext_storage_clear_version_1(ctx context.Context, m api.Module, keySpan int64) {
+	logger.Trace("executing...")
+	runtimeCtx := runtimeContext(ctx)
+	storage := runtimeCtx.Storage
+
+	key := asMemorySlice(m, keySpan)
+
+	logger.Debugf("key: 0x%x", key)
+	err := storage.Delete(key)
+	panicOnError(err)
+}
+
+func ext_storage_clear_prefix_version_1(ctx context.Context, m api.Module, prefixSpan int64) {
+	logger.Trace("executing...")
+	runtimeCtx := runtimeContext(ctx)
+	storage := runtimeCtx.Storage
+
+	prefix := asMemorySlice(m, prefixSpan)
+	logger.Debugf("prefix: 0x%x", prefix)
+
+	err := storage.ClearPrefix(prefix)
+	panicOnError(err)
+}
+
+func ext_storage_clear_prefix_version_2(ctx context.Context, m api.Module, prefixSpan, lim int64) int64 {
+	logger.Trace("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	storage := runtimeCtx.Storage
+
+	prefix := asMemorySlice(m, prefixSpan)
+	logger.Debugf("prefix: 0x%x", prefix)
+
+	limitBytes := asMemorySlice(m, lim)
+
+	var limit []byte
+	err := scale.Unmarshal(limitBytes, &limit)
+	if err != nil {
+		logger.Warnf("failed scale decoding limit: %s", err)
+		return mustToWasmMemoryNil(ctx, m)
+	}
+
+	if len(limit) == 0 {
+		// limit is None, set limit to max
+		limit = []byte{0xff, 0xff, 0xff, 0xff}
+	}
+
+	limitUint := binary.LittleEndian.Uint32(limit)
+	numRemoved, all, err := storage.ClearPrefixLimit(prefix, limitUint)
+	if err != nil {
+		logger.Errorf("failed to clear prefix limit: %s", err)
+		return mustToWasmMemoryNil(ctx, m)
+	}
+
+	encBytes, err := toKillStorageResultEnum(all, numRemoved)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return mustToWasmMemoryNil(ctx, m)
+	}
+
+	valueSpan, err := toWasmMemory(ctx, m, encBytes)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return mustToWasmMemoryNil(ctx, m)
+	}
+
+	return int64(valueSpan)
+}
+
+func ext_storage_exists_version_1(ctx context.Context, m api.Module, keySpan int64) int32 {
+	logger.Trace("executing...")
+	storage := runtimeContext(ctx).Storage
+
+	key := asMemorySlice(m, keySpan)
+	logger.Debugf("key: 0x%x", key)
+
+	value := storage.Get(key)
+	if value != nil {
+		return 1
+	}
+
+	return 0
+}
+
+func ext_storage_get_version_1(ctx context.Context, m api.Module, keySpan int64) int64 {
+	logger.Trace("executing...")
+
+	storage := runtimeContext(ctx).Storage
+
+	key := asMemorySlice(m, keySpan)
+	logger.Debugf("key: 0x%x", key)
+
+	value := storage.Get(key)
+	logger.Debugf("value: 0x%x", value)
+
+	valueSpan, err := toWasmMemoryOptional(ctx, m, value)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return mustToWasmMemoryOptionalNil(ctx, m)
+	}
+
+	return int64(valueSpan)
+}
+
+func ext_storage_next_key_version_1(ctx context.Context, m api.Module, keySpan int64) int64 {
+	logger.Trace("executing...")
+
+	storage := runtimeContext(ctx).Storage
+
+	key := asMemorySlice(m, keySpan)
+
+	next := storage.NextKey(key)
+	logger.Debugf(
+		"key: 0x%x; next key 0x%x",
+		key, next)
+
+	nextSpan, err := toWasmMemoryOptional(ctx, m, next)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(nextSpan)
+}
+
+func ext_storage_read_version_1(ctx context.Context, m api.Module, keySpan, valueOut int64, offset int32) int64 {
+	logger.Trace("executing...")
+
+	storage := runtimeContext(ctx).Storage
+	memory := memoryData(m)
+
+	key := asMemorySlice(m, keySpan)
+	value := storage.Get(key)
+	logger.Debugf(
+		"key 0x%x has value 0x%x",
+		key, value)
+
+	if value == nil {
+		return mustToWasmMemoryOptionalNil(ctx, m)
+	}
+
+	var size uint32
+	if uint32(offset) <= uint32(len(value)) {
+		size = uint32(len(value[offset:]))
+		valueBuf, valueLen := splitPointerSize(int64(valueOut))
+		copy(memory[valueBuf:valueBuf+valueLen], value[offset:])
+	}
+
+	sizeSpan, err := toWasmMemoryOptionalUint32(ctx, m, &size)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(sizeSpan)
+}
+
+func ext_storage_root_version_1(ctx context.Context, m api.Module) int64 {
+	logger.Trace("executing...")
+
+	storage := runtimeContext(ctx).Storage
+
+	root, err := storage.Root()
+	if err != nil {
+		logger.Errorf("failed to get storage root: %s", err)
+		return 0
+	}
+
+	logger.Debugf("root hash is: %s", root)
+
+	rootSpan, err := toWasmMemory(ctx, m, root[:])
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return int64(rootSpan)
+}
+
+func ext_storage_root_version_2(ctx context.Context, m api.Module, version int32) int64 {
+	logger.Trace("executing...")
+
+	stateVersion, err := trie.VersionFromUint32(uint32(version))
+	if err != nil {
+		logger.Errorf("failed parsing state version: %s", err)
+		return 0
+	}
+
+	storage := runtimeContext(ctx).Storage
+	storage.SetVersion(stateVersion)
+
+	return ext_storage_root_version_1(ctx, m)
+}
+
+func ext_storage_set_version_1(ctx context.Context, m api.Module, keySpan, valueSpan int64) {
+	logger.Trace("executing...")
+
+	runtimeCtx := runtimeContext(ctx)
+	storage := runtimeCtx.Storage
+
+	key := asMemorySlice(m, keySpan)
+	value := asMemorySlice(m, valueSpan)
+
+	cp := make([]byte, len(value))
+	copy(cp, value)
+
+	logger.Debugf(
+		"key 0x%x has value 0x%x",
+		key, value)
+	err := storage.Put(key, cp)
+	panicOnError(err)
+}
+
+func ext_storage_start_transaction_version_1(ctx context.Context, m api.Module) {
+	logger.Debug("executing...")
+	runtimeContext(ctx).Storage.BeginStorageTransaction()
+}
+
+func ext_storage_rollback_transaction_version_1(ctx context.Context, m api.Module) {
+	logger.Debug("executing...")
+	runtimeContext(ctx).Storage.RollbackStorageTransaction()
+}
+
+func ext_storage_commit_transaction_version_1(ctx context.Context, m api.Module) {
+	logger.Debug("executing...")
+	runtimeContext(ctx).Storage.CommitStorageTransaction()
+}
+
+// importsNodeRuntime instantiates the host module named "env" exporting
+// the host functions imported by the node runtime, in the runtime given.
+func importsNodeRuntime(ctx context.Context, rt wazero.Runtime) (err error) {
+	builder := rt.NewHostModuleBuilder("env")
+
+	for _, toRegister := range []struct {
+		importName     string
+		implementation interface{}
+	}{
+		{"ext_allocator_free_version_1", ext_allocator_free_version_1},
+		{"ext_allocator_malloc_version_1", ext_allocator_malloc_version_1},
+		{"ext_crypto_ecdsa_generate_version_1", ext_crypto_ecdsa_generate_version_1},
+		{"ext_crypto_ecdsa_public_keys_version_1", ext_crypto_ecdsa_public_keys_version_1},
+		{"ext_crypto_ecdsa_sign_version_1", ext_crypto_ecdsa_sign_version_1},
+		{"ext_crypto_ecdsa_sign_prehashed_version_1", ext_crypto_ecdsa_sign_prehashed_version_1},
+		{"ext_crypto_ecdsa_verify_version_2", ext_crypto_ecdsa_verify_version_2},
+		{"ext_crypto_ed25519_generate_version_1", ext_crypto_ed25519_generate_version_1},
+		{"ext_crypto_ed25519_public_keys_version_1", ext_crypto_ed25519_public_keys_version_1},
+		{"ext_crypto_ed25519_sign_version_1", ext_crypto_ed25519_sign_version_1},
+		{"ext_crypto_ed25519_verify_version_1", ext_crypto_ed25519_verify_version_1},
+		{"ext_crypto_finish_batch_verify_version_1", ext_crypto_finish_batch_verify_version_1},
+		{"ext_crypto_secp256k1_ecdsa_recover_compressed_version_1", ext_crypto_secp256k1_ecdsa_recover_compressed_version_1},
+		{"ext_crypto_secp256k1_ecdsa_recover_compressed_version_2", ext_crypto_secp256k1_ecdsa_recover_compressed_version_2},
+		{"ext_crypto_secp256k1_ecdsa_recover_version_1", ext_crypto_secp256k1_ecdsa_recover_version_1},
+		{"ext_crypto_secp256k1_ecdsa_recover_version_2", ext_crypto_secp256k1_ecdsa_recover_version_2},
+		{"ext_crypto_sr25519_generate_version_1", ext_crypto_sr25519_generate_version_1},
+		{"ext_crypto_sr25519_public_keys_version_1", ext_crypto_sr25519_public_keys_version_1},
+		{"ext_crypto_sr25519_sign_version_1", ext_crypto_sr25519_sign_version_1},
+		{"ext_crypto_sr25519_verify_version_1", ext_crypto_sr25519_verify_version_1},
+		{"ext_crypto_sr25519_verify_version_2", ext_crypto_sr25519_verify_version_2},
+		{"ext_crypto_start_batch_verify_version_1", ext_crypto_start_batch_verify_version_1},
+		{"ext_default_child_storage_clear_prefix_version_1", ext_default_child_storage_clear_prefix_version_1},
+		{"ext_default_child_storage_clear_version_1", ext_default_child_storage_clear_version_1},
+		{"ext_default_child_storage_exists_version_1", ext_default_child_storage_exists_version_1},
+		{"ext_default_child_storage_get_version_1", ext_default_child_storage_get_version_1},
+		{"ext_default_child_storage_next_key_version_1", ext_default_child_storage_next_key_version_1},
+		{"ext_default_child_storage_read_version_1", ext_default_child_storage_read_version_1},
+		{"ext_default_child_storage_root_version_1", ext_default_child_storage_root_version_1},
+		{"ext_default_child_storage_root_version_2", ext_default_child_storage_root_version_2},
+		{"ext_default_child_storage_set_version_1", ext_default_child_storage_set_version_1},
+		{"ext_default_child_storage_storage_kill_version_1", ext_default_child_storage_storage_kill_version_1},
+		{"ext_default_child_storage_storage_kill_version_2", ext_default_child_storage_storage_kill_version_2},
+		{"ext_default_child_storage_storage_kill_version_3", ext_default_child_storage_storage_kill_version_3},
+		{"ext_hashing_blake2_128_version_1", ext_hashing_blake2_128_version_1},
+		{"ext_hashing_blake2_256_version_1", ext_hashing_blake2_256_version_1},
+		{"ext_hashing_keccak_256_version_1", ext_hashing_keccak_256_version_1},
+		{"ext_hashing_sha2_256_version_1", ext_hashing_sha2_256_version_1},
+		{"ext_hashing_twox_128_version_1", ext_hashing_twox_128_version_1},
+		{"ext_hashing_twox_256_version_1", ext_hashing_twox_256_version_1},
+		{"ext_hashing_twox_64_version_1", ext_hashing_twox_64_version_1},
+		{"ext_logging_log_version_1", ext_logging_log_version_1},
+		{"ext_logging_max_level_version_1", ext_logging_max_level_version_1},
+		{"ext_misc_print_hex_version_1", ext_misc_print_hex_version_1},
+		{"ext_misc_print_num_version_1", ext_misc_print_num_version_1},
+		{"ext_misc_print_utf8_version_1", ext_misc_print_utf8_version_1},
+		{"ext_misc_runtime_version_version_1", ext_misc_runtime_version_version_1},
+		{"ext_offchain_http_request_add_header_version_1", ext_offchain_http_request_add_header_version_1},
+		{"ext_offchain_http_request_start_version_1", ext_offchain_http_request_start_version_1},
+		{"ext_offchain_http_request_write_body_version_1", ext_offchain_http_request_write_body_version_1},
+		{"ext_offchain_http_response_headers_version_1", ext_offchain_http_response_headers_version_1},
+		{"ext_offchain_http_response_read_body_version_1", ext_offchain_http_response_read_body_version_1},
+		{"ext_offchain_http_response_wait_version_1", ext_offchain_http_response_wait_version_1},
+		{"ext_offchain_index_set_version_1", ext_offchain_index_set_version_1},
+		{"ext_offchain_is_validator_version_1", ext_offchain_is_validator_version_1},
+		{"ext_offchain_local_storage_clear_version_1", ext_offchain_local_storage_clear_version_1},
+		{"ext_offchain_local_storage_compare_and_set_version_1", ext_offchain_local_storage_compare_and_set_version_1},
+		{"ext_offchain_local_storage_get_version_1", ext_offchain_local_storage_get_version_1},
+		{"ext_offchain_local_storage_set_version_1", ext_offchain_local_storage_set_version_1},
+		{"ext_offchain_network_state_version_1", ext_offchain_network_state_version_1},
+		{"ext_offchain_random_seed_version_1", ext_offchain_random_seed_version_1},
+		{"ext_offchain_sleep_until_version_1", ext_offchain_sleep_until_version_1},
+		{"ext_offchain_submit_transaction_version_1", ext_offchain_submit_transaction_version_1},
+		{"ext_offchain_timestamp_version_1", ext_offchain_timestamp_version_1},
+		{"ext_sandbox_get_global_val_version_1", ext_sandbox_get_global_val_version_1},
+		{"ext_sandbox_instance_teardown_version_1", ext_sandbox_instance_teardown_version_1},
+		{"ext_sandbox_instantiate_version_1", ext_sandbox_instantiate_version_1},
+		{"ext_sandbox_invoke_version_1", ext_sandbox_invoke_version_1},
+		{"ext_sandbox_memory_get_version_1", ext_sandbox_memory_get_version_1},
+		{"ext_sandbox_memory_new_version_1", ext_sandbox_memory_new_version_1},
+		{"ext_sandbox_memory_set_version_1", ext_sandbox_memory_set_version_1},
+		{"ext_sandbox_memory_teardown_version_1", ext_sandbox_memory_teardown_version_1},
+		{"ext_storage_append_version_1", ext_storage_append_version_1},
+		{"ext_storage_changes_root_version_1", ext_storage_changes_root_version_1},
+		{"ext_storage_clear_prefix_version_1", ext_storage_clear_prefix_version_1},
+		{"ext_storage_clear_prefix_version_2", ext_storage_clear_prefix_version_2},
+		{"ext_storage_clear_version_1", ext_storage_clear_version_1},
+		{"ext_storage_commit_transaction_version_1", ext_storage_commit_transaction_version_1},
+		{"ext_storage_exists_version_1", ext_storage_exists_version_1},
+		{"ext_storage_get_version_1", ext_storage_get_version_1},
+		{"ext_storage_next_key_version_1", ext_storage_next_key_version_1},
+		{"ext_storage_read_version_1", ext_storage_read_version_1},
+		{"ext_storage_rollback_transaction_version_1", ext_storage_rollback_transaction_version_1},
+		{"ext_storage_root_version_1", ext_storage_root_version_1},
+		{"ext_storage_root_version_2", ext_storage_root_version_2},
+		{"ext_storage_set_version_1", ext_storage_set_version_1},
+		{"ext_storage_start_transaction_version_1", ext_storage_start_transaction_version_1},
+		{"ext_transaction_index_index_version_1", ext_transaction_index_index_version_1},
+		{"ext_transaction_index_renew_version_1", ext_transaction_index_renew_version_1},
+		{"ext_trie_blake2_256_ordered_root_version_1", ext_trie_blake2_256_ordered_root_version_1},
+		{"ext_trie_blake2_256_ordered_root_version_2", ext_trie_blake2_256_ordered_root_version_2},
+		{"ext_trie_blake2_256_root_version_1", ext_trie_blake2_256_root_version_1},
+		{"ext_trie_blake2_256_root_version_2", ext_trie_blake2_256_root_version_2},
+		{"ext_trie_blake2_256_verify_proof_version_1", ext_trie_blake2_256_verify_proof_version_1},
+	} {
+		builder.NewFunctionBuilder().
+			WithFunc(toRegister.implementation).
+			Export(toRegister.importName)
+	}
+
+	_, err = builder.Instantiate(ctx)
+	if err != nil {
+		return fmt.Errorf("instantiating host module: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,493 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wazero
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/crypto"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/offchain"
+	"github.com/ChainSafe/gossamer/lib/runtime/sandbox"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/klauspost/compress/zstd"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Name represents the name of the interpreter
+const Name = "wazero"
+
+var (
+	logger = log.NewFromGlobal(
+		log.AddContext("pkg", "runtime"),
+		log.AddContext("module", "wazero"),
+	)
+)
+
+// Instance represents a runtime instance running on the
+// pure Go wazero WebAssembly runtime.
+type Instance struct {
+	runtime  wazero.Runtime
+	module   api.Module
+	ctx      *runtime.Context
+	isClosed bool
+	codeHash common.Hash
+	mutex    sync.Mutex
+	// compiled is the compiled module the instance module is instantiated
+	// from, used to instantiate other instances of the same code cheaply.
+	compiled wazero.CompiledModule
+	// ownsRuntime is true if the wazero runtime must be closed when the instance
+	// is closed, and false if the runtime is shared with another instance.
+	ownsRuntime bool
+	// config is the configuration used to create the instance, and
+	// used to create the instances instantiated from its compiled module.
+	config Config
+}
+
+// NewRuntimeFromGenesis creates a runtime instance from the genesis data
+func NewRuntimeFromGenesis(cfg Config) (instance *Instance, err error) {
+	if cfg.Storage == nil {
+		return nil, errors.New("storage is nil")
+	}
+
+	code := cfg.Storage.LoadCode()
+	if len(code) == 0 {
+		return nil, fmt.Errorf("cannot find :code in state")
+	}
+
+	return NewInstance(code, cfg)
+}
+
+// NewInstanceFromTrie returns a new runtime instance with the code provided in the given trie
+func NewInstanceFromTrie(t *trie.Trie, cfg Config) (*Instance, error) {
+	code := t.Get(common.CodeKey)
+	if len(code) == 0 {
+		return nil, fmt.Errorf("cannot find :code in trie")
+	}
+
+	return NewInstance(code, cfg)
+}
+
+// NewInstanceFromFile instantiates a runtime from a .wasm file
+func NewInstanceFromFile(fp string, cfg Config) (*Instance, error) {
+	code, err := os.ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewInstance(code, cfg)
+}
+
+// NewInstance instantiates a runtime from raw wasm bytecode
+func NewInstance(code []byte, cfg Config) (instance *Instance, err error) {
+	logger.Patch(log.SetLevel(cfg.LogLvl), log.SetCallerFunc(true))
+
+	wazeroRuntime, compiled, err := setupVM(code)
+	if err != nil {
+		return nil, fmt.Errorf("setting up VM: %w", err)
+	}
+
+	instance, err = newInstanceFromModule(wazeroRuntime, compiled, cfg, nil)
+	if err != nil {
+		closeRuntime(context.Background(), wazeroRuntime)
+		return nil, err
+	}
+	instance.ownsRuntime = true
+
+	return instance, nil
+}
+
+// newInstanceFromModule instantiates a runtime instance from the compiled module given,
+// in the wazero runtime given which is not owned by the instance. If the runtime version
+// given is nil, it is obtained by calling the runtime.
+func newInstanceFromModule(wazeroRuntime wazero.Runtime, compiled wazero.CompiledModule,
+	cfg Config, version *runtime.Version) (instance *Instance, err error) {
+	module, allocator, err := instantiateVM(wazeroRuntime, compiled)
+	if err != nil {
+		return nil, fmt.Errorf("instantiating VM: %w", err)
+	}
+
+	runtimeCtx := &runtime.Context{
+		Storage:         cfg.Storage,
+		Allocator:       allocator,
+		Keystore:        cfg.Keystore,
+		Validator:       cfg.Role == common.AuthorityRole,
+		NodeStorage:     cfg.NodeStorage,
+		Network:         cfg.Network,
+		Transaction:     cfg.Transaction,
+		SigVerifier:     crypto.NewSignatureVerifier(logger),
+		OffchainHTTPSet: offchain.NewHTTPSet(),
+	}
+
+	instance = &Instance{
+		runtime:  wazeroRuntime,
+		module:   module,
+		ctx:      runtimeCtx,
+		codeHash: cfg.CodeHash,
+		compiled: compiled,
+		config:   cfg,
+	}
+	runtimeCtx.Sandbox = sandbox.NewStore(&sandboxSupervisor{instance: instance})
+
+	switch {
+	case version != nil:
+		instance.ctx.Version = *version
+	case cfg.testVersion != nil:
+		instance.ctx.Version = *cfg.testVersion
+	default:
+		instance.ctx.Version, err = instance.version()
+		if err != nil {
+			instance.close()
+			return nil, fmt.Errorf("getting instance version: %w", err)
+		}
+	}
+
+	return instance, nil
+}
+
+// decompressWasm decompresses a Wasm blob that may or may not be compressed with zstd
+// ref: https://github.com/paritytech/substrate/blob/master/primitives/maybe-compressed-blob/src/lib.rs
+func decompressWasm(code []byte) ([]byte, error) {
+	compressionFlag := []byte{82, 188, 83, 118, 70, 219, 142, 5}
+	if !bytes.HasPrefix(code, compressionFlag) {
+		return code, nil
+	}
+
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating zstd reader: %s", err)
+	}
+
+	return decoder.DecodeAll(code[len(compressionFlag):], nil)
+}
+
+// GetCodeHash returns the code of the instance
+func (in *Instance) GetCodeHash() common.Hash {
+	return in.codeHash
+}
+
+// GetContext returns the context of the instance
+func (in *Instance) GetContext() *runtime.Context {
+	return in.ctx
+}
+
+// Acquire returns a new instance isolated from the runtime instance, instantiated
+// from its compiled module, together with a function to stop the instance once done
+// with it. The storage of the instance returned must be set with SetContextStorage
+// before use.
+func (in *Instance) Acquire() (instance runtime.Instance, release func(), err error) {
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+
+	if in.isClosed {
+		return nil, nil, ErrInstanceIsStopped
+	}
+
+	cfg := in.config
+	cfg.Storage = nil
+	version := in.ctx.Version
+	isolated, err := newInstanceFromModule(in.runtime, in.compiled, cfg, &version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating isolated instance: %w", err)
+	}
+	return isolated, isolated.Stop, nil
+}
+
+// UpdateRuntimeCode updates the runtime instance to run the given code
+func (in *Instance) UpdateRuntimeCode(code []byte) (err error) {
+	wazeroRuntime, compiled, err := setupVM(code)
+	if err != nil {
+		return fmt.Errorf("setting up VM: %w", err)
+	}
+
+	module, allocator, err := instantiateVM(wazeroRuntime, compiled)
+	if err != nil {
+		closeRuntime(context.Background(), wazeroRuntime)
+		return fmt.Errorf("instantiating VM: %w", err)
+	}
+
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+
+	in.close()
+
+	in.runtime = wazeroRuntime
+	in.compiled = compiled
+	in.ownsRuntime = true
+	in.module = module
+	in.ctx.Allocator = allocator
+	in.isClosed = false
+
+	// Find runtime instance version and cache it in its
+	// instance context.
+	version, err := in.version()
+	if err != nil {
+		in.close()
+		return fmt.Errorf("getting instance version: %w", err)
+	}
+	in.ctx.Version = version
+
+	return nil
+}
+
+// GetRuntimeVersion finds the runtime version by initiating a temporary
+// runtime instance using the WASM code provided, and querying it.
+func GetRuntimeVersion(code []byte) (version runtime.Version, err error) {
+	config := Config{
+		LogLvl: log.DoNotChange,
+	}
+	instance, err := NewInstance(code, config)
+	if err != nil {
+		return version, fmt.Errorf("creating runtime instance: %w", err)
+	}
+	defer instance.Stop()
+
+	version, err = instance.version()
+	if err != nil {
+		return version, fmt.Errorf("running runtime: %w", err)
+	}
+
+	return version, nil
+}
+
+var (
+	ErrCodeEmpty      = errors.New("code is empty")
+	ErrWASMDecompress = errors.New("wasm decompression failed")
+)
+
+// setupVM decompresses and compiles the runtime code given, in a new
+// wazero runtime with the node runtime imports.
+func setupVM(code []byte) (wazeroRuntime wazero.Runtime, compiled wazero.CompiledModule, err error) {
+	if len(code) == 0 {
+		return nil, nil, ErrCodeEmpty
+	}
+
+	code, err = decompressWasm(code)
+	if err != nil {
+		// Note the sentinel error is wrapped here since the ztsd Go library
+		// does not return any exported sentinel errors.
+		return nil, nil, fmt.Errorf("%w: %s", ErrWASMDecompress, err)
+	}
+
+	code, err = defineImportedMemory(code)
+	if err != nil {
+		return nil, nil, fmt.Errorf("defining imported memory: %w", err)
+	}
+
+	code, err = sandbox.AddDispatchCaller(code)
+	if err != nil {
+		return nil, nil, fmt.Errorf("adding sandbox dispatch caller: %w", err)
+	}
+
+	ctx := context.Background()
+	wazeroRuntime = wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig())
+
+	err = importsNodeRuntime(ctx, wazeroRuntime)
+	if err != nil {
+		closeRuntime(ctx, wazeroRuntime)
+		return nil, nil, fmt.Errorf("creating node runtime imports: %w", err)
+	}
+
+	compiled, err = wazeroRuntime.CompileModule(ctx, code)
+	if err != nil {
+		closeRuntime(ctx, wazeroRuntime)
+		return nil, nil, fmt.Errorf("compiling web assembly module: %w", err)
+	}
+
+	return wazeroRuntime, compiled, nil
+}
+
+// instantiateVM instantiates the compiled module given in the wazero runtime given.
+// The module is anonymous such that it can be instantiated multiple times, and each
+// instantiation has its own memory.
+func instantiateVM(wazeroRuntime wazero.Runtime, compiled wazero.CompiledModule) (
+	module api.Module, allocator *runtime.FreeingBumpHeapAllocator, err error) {
+	ctx := context.Background()
+	module, err = wazeroRuntime.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithName(""))
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating web assembly instance: %w", err)
+	}
+
+	if module.Memory() == nil {
+		closeModule(ctx, module)
+		return nil, nil, errors.New("web assembly instance has no memory")
+	}
+
+	heapBase := runtime.DefaultHeapBase
+	heapBaseGlobal := module.ExportedGlobal("__heap_base")
+	if heapBaseGlobal != nil {
+		heapBase = api.DecodeU32(heapBaseGlobal.Get())
+	}
+
+	allocator = runtime.NewAllocator(&memory{memory: module.Memory()}, heapBase)
+
+	return module, allocator, nil
+}
+
+// closeModule closes the wazero module given, logging any error encountered.
+func closeModule(ctx context.Context, module api.Module) {
+	err := module.Close(ctx)
+	if err != nil {
+		logger.Errorf("closing wazero module: %s", err)
+	}
+}
+
+// closeRuntime closes the wazero runtime given and
+// its modules, logging any error encountered.
+func closeRuntime(ctx context.Context, wazeroRuntime wazero.Runtime) {
+	err := wazeroRuntime.Close(ctx)
+	if err != nil {
+		logger.Errorf("closing wazero runtime: %s", err)
+	}
+}
+
+// SetContextStorage sets the runtime's storage.
+func (in *Instance) SetContextStorage(s runtime.Storage) {
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+
+	if s != nil {
+		// Storage values inserted or modified by the runtime are encoded
+		// using the state trie version defined in the runtime version.
+		stateVersion, err := trie.VersionFromUint32(in.ctx.Version.StateVersion)
+		if err != nil {
+			logger.Warnf("using state trie version %s: %s", trie.V0, err)
+			stateVersion = trie.V0
+		}
+		s.SetVersion(stateVersion)
+	}
+
+	in.ctx.Storage = s
+}
+
+// Stop closes the WASM instance, its imports and clears
+// the context allocator in a thread-safe way.
+func (in *Instance) Stop() {
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+	in.close()
+}
+
+// close closes the wazero module of the instance, or the wazero runtime and
+// all its modules if the runtime is owned by the instance, and clears the
+// context allocator.
+// If the instance has previously been closed, it simply returns.
+// It is NOT THREAD SAFE to use.
+func (in *Instance) close() {
+	if in.isClosed {
+		return
+	}
+
+	in.ctx.Sandbox.Reset()
+	if in.ownsRuntime {
+		closeRuntime(context.Background(), in.runtime)
+	} else {
+		closeModule(context.Background(), in.module)
+	}
+	in.ctx.Allocator.Clear()
+	in.isClosed = true
+}
+
+var (
+	ErrInstanceIsStopped      = errors.New("instance is stopped")
+	ErrExportFunctionNotFound = errors.New("export function not found")
+)
+
+// Exec calls the given function with the given data
+func (in *Instance) Exec(function string, data []byte) (result []byte, err error) {
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+
+	if in.isClosed {
+		return nil, ErrInstanceIsStopped
+	}
+
+	dataLength := uint32(len(data))
+	inputPtr, err := in.ctx.Allocator.Allocate(dataLength)
+	if err != nil {
+		return nil, fmt.Errorf("allocating input memory: %w", err)
+	}
+
+	defer in.ctx.Allocator.Clear()
+	defer in.discardSignatureBatch()
+	defer in.ctx.Sandbox.Reset()
+
+	// Store the data into memory
+	ok := in.module.Memory().Write(inputPtr, data)
+	if !ok {
+		return nil, fmt.Errorf("writing input data to memory at pointer %d", inputPtr)
+	}
+
+	runtimeFunc := in.module.ExportedFunction(function)
+	if runtimeFunc == nil {
+		return nil, fmt.Errorf("%w: %s", ErrExportFunctionNotFound, function)
+	}
+
+	// The runtime context is passed to the host functions through
+	// the context given to the runtime function call.
+	ctx := context.WithValue(context.Background(), runtimeContextKey, in.ctx)
+	values, err := runtimeFunc.Call(ctx, api.EncodeU32(inputPtr), api.EncodeU32(dataLength))
+	if err != nil {
+		return nil, fmt.Errorf("running runtime function: %w", err)
+	}
+
+	if len(values) != 1 {
+		return nil, fmt.Errorf("runtime function returned %d values instead of 1", len(values))
+	}
+
+	outputPtr, outputLength := splitPointerSize(int64(values[0]))
+	output, ok := in.module.Memory().Read(outputPtr, outputLength)
+	if !ok {
+		return nil, fmt.Errorf("reading output from memory at pointer %d with size %d",
+			outputPtr, outputLength)
+	}
+
+	// The output is copied since the memory is reused by the next call.
+	result = make([]byte, len(output))
+	copy(result, output)
+	return result, nil
+}
+
+// discardSignatureBatch discards the batch of signature verification
+// left started by the runtime, for example if the runtime call failed,
+// such that signatures verified by the next runtime calls are not
+// added to the batch without ever being verified.
+// It is NOT THREAD SAFE to use.
+func (in *Instance) discardSignatureBatch() {
+	if !in.ctx.SigVerifier.IsStarted() {
+		return
+	}
+
+	logger.Warn("discarding batch of signature verification not finished by the runtime")
+	_ = in.ctx.SigVerifier.Finish()
+}
+
+// NodeStorage to get reference to runtime node service
+func (in *Instance) NodeStorage() runtime.NodeStorage {
+	return in.ctx.NodeStorage
+}
+
+// NetworkService to get referernce to runtime network service
+func (in *Instance) NetworkService() runtime.BasicNetwork {
+	return in.ctx.Network
+}
+
+// Keystore to get reference to runtime keystore
+func (in *Instance) Keystore() *keystore.GlobalKeystore {
+	return in.ctx.Keystore
+}
+
+// Validator returns the context's Validator
+func (in *Instance) Validator() bool {
+	return in.ctx.Validator
+}
@@ -0,0 +1,282 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wazero
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// memory adapts the memory of a wazero module to
+// the memory interface used by the runtime allocator.
+type memory struct {
+	memory api.Memory
+}
+
+// Data returns the memory as a byte slice, which is only
+// valid until the memory grows.
+func (m *memory) Data() []byte {
+	data, ok := m.memory.Read(0, m.memory.Size())
+	if !ok {
+		panic("cannot read module memory")
+	}
+	return data
+}
+
+// Length returns the memory size in bytes.
+func (m *memory) Length() uint32 {
+	return m.memory.Size()
+}
+
+// Grow grows the memory by the given number of pages.
+func (m *memory) Grow(numPages uint32) error {
+	_, ok := m.memory.Grow(numPages)
+	if !ok {
+		return fmt.Errorf("%w: by %d pages from %d bytes",
+			ErrMemoryGrow, numPages, m.memory.Size())
+	}
+	return nil
+}
+
+// Section identifiers and import kind of the wasm binary format,
+// see https://webassembly.github.io/spec/core/binary/modules.html
+const (
+	customSectionID    = 0
+	importSectionID    = 2
+	memorySectionID    = 5
+	dataCountSectionID = 12
+	codeSectionID      = 10
+
+	functionImportKind = 0
+	tableImportKind    = 1
+	memoryImportKind   = 2
+	globalImportKind   = 3
+)
+
+var wasmHeader = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+var (
+	ErrMemoryGrow        = errors.New("cannot grow memory")
+	ErrWasmHeaderInvalid = errors.New("wasm header is invalid")
+	ErrWasmMalformed     = errors.New("wasm binary is malformed")
+	ErrMemoryDefined     = errors.New("memory is both imported and defined")
+	ErrImportKindUnknown = errors.New("import kind is unknown")
+)
+
+// defineImportedMemory returns the wasm code given with its memory import,
+// if any, replaced by a memory definition with the same limits.
+// Runtimes import their memory from the host, but wazero host modules
+// cannot export memories, so the runtime module defines its memory
+// itself instead, which the host functions access through the module.
+func defineImportedMemory(code []byte) (modified []byte, err error) {
+	if !bytes.HasPrefix(code, wasmHeader) {
+		return nil, ErrWasmHeaderInvalid
+	}
+
+	type section struct {
+		id      byte
+		content []byte
+	}
+
+	var sections []section
+	for offset := len(wasmHeader); offset < len(code); {
+		id := code[offset]
+		offset++
+		size, n, err := readUnsignedLEB128(code[offset:])
+		if err != nil {
+			return nil, fmt.Errorf("reading size of section %d: %w", id, err)
+		}
+		offset += n
+		if uint64(len(code)-offset) < uint64(size) {
+			return nil, fmt.Errorf("%w: section %d size %d exceeds remaining %d bytes",
+				ErrWasmMalformed, id, size, len(code)-offset)
+		}
+		sections = append(sections, section{id: id, content: code[offset : offset+int(size)]})
+		offset += int(size)
+	}
+
+	var memoryLimits []byte
+	for i, s := range sections {
+		if s.id == memorySectionID && memoryLimits != nil {
+			return nil, ErrMemoryDefined
+		}
+
+		if s.id != importSectionID {
+			continue
+		}
+
+		var content []byte
+		content, memoryLimits, err = removeMemoryImport(s.content)
+		if err != nil {
+			return nil, fmt.Errorf("removing memory import: %w", err)
+		}
+		sections[i].content = content
+	}
+
+	if memoryLimits == nil {
+		return code, nil
+	}
+
+	memorySection := section{
+		id: memorySectionID,
+		// vector of one memory type
+		content: append([]byte{1}, memoryLimits...),
+	}
+
+	modified = append(modified, wasmHeader...)
+	inserted := false
+	for _, s := range sections {
+		if s.id == memorySectionID {
+			return nil, ErrMemoryDefined
+		}
+
+		if !inserted && s.id != customSectionID && sectionOrder(s.id) > sectionOrder(memorySectionID) {
+			modified = appendSection(modified, memorySection.id, memorySection.content)
+			inserted = true
+		}
+		modified = appendSection(modified, s.id, s.content)
+	}
+
+	if !inserted {
+		modified = appendSection(modified, memorySection.id, memorySection.content)
+	}
+
+	return modified, nil
+}
+
+// removeMemoryImport returns the import section content given without its
+// memory import, and the encoded limits of the memory import removed.
+// The memory limits returned are nil if there is no memory import.
+func removeMemoryImport(content []byte) (modified, memoryLimits []byte, err error) {
+	count, offset, err := readUnsignedLEB128(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading imports count: %w", err)
+	}
+
+	var imports []byte
+	importsKept := count
+	for i := uint32(0); i < count; i++ {
+		start := offset
+
+		// module name and field name
+		for j := 0; j < 2; j++ {
+			nameLength, n, err := readUnsignedLEB128(content[offset:])
+			if err != nil {
+				return nil, nil, fmt.Errorf("reading import name length: %w", err)
+			}
+			offset += n + int(nameLength)
+		}
+
+		if offset >= len(content) {
+			return nil, nil, fmt.Errorf("%w: import %d is truncated", ErrWasmMalformed, i)
+		}
+		kind := content[offset]
+		offset++
+
+		var descriptionLength int
+		switch kind {
+		case functionImportKind:
+			_, descriptionLength, err = readUnsignedLEB128(content[offset:])
+		case tableImportKind:
+			// reference type followed by limits
+			descriptionLength, err = limitsLength(content[offset+1:])
+			descriptionLength++
+		case memoryImportKind:
+			descriptionLength, err = limitsLength(content[offset:])
+		case globalImportKind:
+			// value type and mutability
+			descriptionLength = 2
+		default:
+			err = fmt.Errorf("%w: %d", ErrImportKindUnknown, kind)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading import %d description: %w", i, err)
+		}
+
+		if offset+descriptionLength > len(content) {
+			return nil, nil, fmt.Errorf("%w: import %d is truncated", ErrWasmMalformed, i)
+		}
+
+		if kind == memoryImportKind {
+			memoryLimits = content[offset : offset+descriptionLength]
+			importsKept--
+		} else {
+			imports = append(imports, content[start:offset+descriptionLength]...)
+		}
+		offset += descriptionLength
+	}
+
+	if memoryLimits == nil {
+		return content, nil, nil
+	}
+
+	modified = appendUnsignedLEB128(nil, importsKept)
+	modified = append(modified, imports...)
+	return modified, memoryLimits, nil
+}
+
+// limitsLength returns the length of the encoded limits
+// at the start of the data given.
+func limitsLength(data []byte) (length int, err error) {
+	if len(data) == 0 {
+		return 0, fmt.Errorf("%w: limits are empty", ErrWasmMalformed)
+	}
+
+	const hasMaximumFlag = 0x01
+	flags := data[0]
+	length = 1
+
+	_, n, err := readUnsignedLEB128(data[length:])
+	if err != nil {
+		return 0, fmt.Errorf("reading minimum: %w", err)
+	}
+	length += n
+
+	if flags&hasMaximumFlag != 0 {
+		_, n, err = readUnsignedLEB128(data[length:])
+		if err != nil {
+			return 0, fmt.Errorf("reading maximum: %w", err)
+		}
+		length += n
+	}
+
+	return length, nil
+}
+
+// sectionOrder returns the order of the non custom section
+// with the given identifier in a wasm binary.
+func sectionOrder(id byte) int {
+	switch id {
+	case dataCountSectionID:
+		// the data count section is placed before the code section
+		return codeSectionID*2 - 1
+	default:
+		return int(id) * 2
+	}
+}
+
+func appendSection(data []byte, id byte, content []byte) []byte {
+	data = append(data, id)
+	data = appendUnsignedLEB128(data, uint32(len(content)))
+	return append(data, content...)
+}
+
+// readUnsignedLEB128 decodes the LEB128 encoded 32 bit unsigned integer
+// at the start of the data given and returns it with its encoded length.
+func readUnsignedLEB128(data []byte) (value uint32, length int, err error) {
+	decoded, length := binary.Uvarint(data)
+	if length <= 0 || decoded > uint64(^uint32(0)) {
+		return 0, 0, fmt.Errorf("%w: invalid LEB128 unsigned integer", ErrWasmMalformed)
+	}
+	return uint32(decoded), length, nil
+}
+
+// appendUnsignedLEB128 appends the LEB128 encoding of the value to the data.
+func appendUnsignedLEB128(data []byte, value uint32) []byte {
+	return binary.AppendUvarint(data, uint64(value))
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wazero
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func wasmSection(id byte, content ...byte) []byte {
+	return appendSection(nil, id, content)
+}
+
+func wasmImport(module, field string, kind byte, description ...byte) []byte {
+	data := append([]byte{byte(len(module))}, module...)
+	data = append(data, byte(len(field)))
+	data = append(data, field...)
+	data = append(data, kind)
+	return append(data, description...)
+}
+
+func wasmBinary(sections ...[]byte) (code []byte) {
+	code = append(code, wasmHeader...)
+	for _, section := range sections {
+		code = append(code, section...)
+	}
+	return code
+}
+
+func Test_defineImportedMemory(t *testing.T) {
+	t.Parallel()
+
+	typeSection := wasmSection(1, 1, 0x60, 0, 0)
+	functionImport := wasmImport("env", "ext_test", functionImportKind, 0)
+	memoryImport := wasmImport("env", "memory", memoryImportKind, 0x00, 20)
+	memoryImportWithMax := wasmImport("env", "memory", memoryImportKind, 0x01, 20, 0x80, 0x01)
+	globalImport := wasmImport("env", "global", globalImportKind, 0x7f, 0)
+	functionSection := wasmSection(3, 1, 0)
+	exportSection := wasmSection(7, append([]byte{1, 4}, append([]byte("main"), 0, 1)...)...)
+	codeSection := wasmSection(10, 1, 2, 0, 0x0b)
+	customSection := wasmSection(0, append([]byte{4}, []byte("name")...)...)
+
+	importSection := func(imports ...[]byte) []byte {
+		content := []byte{byte(len(imports))}
+		for _, imp := range imports {
+			content = append(content, imp...)
+		}
+		return wasmSection(importSectionID, content...)
+	}
+
+	testCases := map[string]struct {
+		code       []byte
+		modified   []byte
+		errWrapped error
+		errMessage string
+	}{
+		"invalid_header": {
+			code:       []byte{0x00, 0x61, 0x73, 0x6d, 0x02, 0x00, 0x00, 0x00},
+			errWrapped: ErrWasmHeaderInvalid,
+			errMessage: "wasm header is invalid",
+		},
+		"section_size_too_large": {
+			code:       wasmBinary([]byte{1, 10, 0}),
+			errWrapped: ErrWasmMalformed,
+			errMessage: "wasm binary is malformed: section 1 size 10 exceeds remaining 1 bytes",
+		},
+		"no_memory_import": {
+			code: wasmBinary(typeSection, importSection(functionImport),
+				functionSection, exportSection, codeSection),
+			modified: wasmBinary(typeSection, importSection(functionImport),
+				functionSection, exportSection, codeSection),
+		},
+		"memory_import_replaced": {
+			code: wasmBinary(typeSection, importSection(functionImport, memoryImport, globalImport),
+				functionSection, exportSection, codeSection, customSection),
+			modified: wasmBinary(typeSection, importSection(functionImport, globalImport),
+				functionSection, wasmSection(memorySectionID, 1, 0x00, 20),
+				exportSection, codeSection, customSection),
+		},
+		"memory_import_with_maximum_replaced": {
+			code: wasmBinary(typeSection, importSection(memoryImportWithMax),
+				functionSection),
+			modified: wasmBinary(typeSection, importSection(),
+				functionSection, wasmSection(memorySectionID, 1, 0x01, 20, 0x80, 0x01)),
+		},
+		"memory_imported_and_defined": {
+			code: wasmBinary(typeSection, importSection(memoryImport),
+				functionSection, wasmSection(memorySectionID, 1, 0x00, 1)),
+			errWrapped: ErrMemoryDefined,
+			errMessage: "memory is both imported and defined",
+		},
+		"unknown_import_kind": {
+			code:       wasmBinary(importSection(wasmImport("env", "unknown", 4, 0))),
+			errWrapped: ErrImportKindUnknown,
+			errMessage: "removing memory import: reading import 0 description: import kind is unknown: 4",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			modified, err := defineImportedMemory(testCase.code)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.modified, modified)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wazero
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/lib/runtime/sandbox"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// sandboxSupervisor implements the sandbox.Supervisor interface
+// using the memory, allocator and exports of the runtime instance.
+// Its methods are called from host functions, so while the instance
+// mutex is held, and must therefore not lock it.
+type sandboxSupervisor struct {
+	instance *Instance
+}
+
+func (s *sandboxSupervisor) Allocate(size uint32) (pointer uint32, err error) {
+	return s.instance.ctx.Allocator.Allocate(size)
+}
+
+func (s *sandboxSupervisor) Deallocate(pointer uint32) error {
+	return s.instance.ctx.Allocator.Deallocate(pointer)
+}
+
+func (s *sandboxSupervisor) Read(pointer, size uint32) (data []byte, ok bool) {
+	memoryData, ok := s.instance.module.Memory().Read(pointer, size)
+	if !ok {
+		return nil, false
+	}
+	data = make([]byte, size)
+	copy(data, memoryData)
+	return data, true
+}
+
+func (s *sandboxSupervisor) Write(pointer uint32, data []byte) (ok bool) {
+	return s.instance.module.Memory().Write(pointer, data)
+}
+
+func (s *sandboxSupervisor) Dispatch(thunk, argsPointer, argsSize, state, functionIndex uint32) (
+	resultPointerSize int64, err error) {
+	dispatchCaller := s.instance.module.ExportedFunction(sandbox.DispatchCallerExport)
+	if dispatchCaller == nil {
+		return 0, fmt.Errorf("%w: %s", ErrExportFunctionNotFound, sandbox.DispatchCallerExport)
+	}
+
+	ctx := context.WithValue(context.Background(), runtimeContextKey, s.instance.ctx)
+	values, err := dispatchCaller.Call(ctx, api.EncodeU32(thunk), api.EncodeU32(argsPointer),
+		api.EncodeU32(argsSize), api.EncodeU32(state), api.EncodeU32(functionIndex))
+	if err != nil {
+		return 0, fmt.Errorf("calling dispatch thunk: %w", err)
+	}
+
+	return int64(values[0]), nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package transaction
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// BanList is a thread safe list of extrinsic hashes temporarily banned
+// from the transaction pool and queue.
+type BanList struct {
+	// banned maps an extrinsic hash to the time its ban expires.
+	banned   map[common.Hash]time.Time
+	duration time.Duration
+	now      func() time.Time
+	mu       sync.Mutex
+}
+
+// NewBanList returns a new empty BanList banning extrinsics for the given duration.
+func NewBanList(duration time.Duration) *BanList {
+	return &BanList{
+		banned:   make(map[common.Hash]time.Time),
+		duration: duration,
+		now:      time.Now,
+	}
+}
+
+// Ban bans the given extrinsic hashes for the duration of the ban list,
+// starting from now. Expired bans are pruned from the list.
+func (b *BanList) Ban(hashes ...common.Hash) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	for hash, expiry := range b.banned {
+		if !now.Before(expiry) {
+			delete(b.banned, hash)
+		}
+	}
+
+	expiry := now.Add(b.duration)
+	for _, hash := range hashes {
+		b.banned[hash] = expiry
+	}
+}
+
+// IsBanned returns true if the extrinsic hash is currently banned, false otherwise.
+func (b *BanList) IsBanned(hash common.Hash) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	expiry, ok := b.banned[hash]
+	if !ok {
+		return false
+	}
+
+	if !b.now().Before(expiry) {
+		delete(b.banned, hash)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,42 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package transaction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BanList(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	banList := NewBanList(time.Minute)
+	banList.now = func() time.Time { return now }
+
+	banList.Ban(common.Hash{1}, common.Hash{2})
+	assert.True(t, banList.IsBanned(common.Hash{1}))
+	assert.True(t, banList.IsBanned(common.Hash{2}))
+	assert.False(t, banList.IsBanned(common.Hash{3}))
+
+	now = now.Add(30 * time.Second)
+	banList.Ban(common.Hash{3})
+	assert.True(t, banList.IsBanned(common.Hash{1}))
+	assert.True(t, banList.IsBanned(common.Hash{3}))
+
+	now = now.Add(30 * time.Second)
+	assert.False(t, banList.IsBanned(common.Hash{1}))
+	assert.True(t, banList.IsBanned(common.Hash{3}))
+
+	// banning prunes the expired bans
+	banList.Ban(common.Hash{1})
+	expectedBanned := map[common.Hash]time.Time{
+		{1}: now.Add(time.Minute),
+		{3}: now.Add(30 * time.Second),
+	}
+	assert.Equal(t, expectedBanned, banList.banned)
+}
@@ -6,6 +6,7 @@
 import (
 	"container/heap"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -15,8 +16,13 @@
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
-// ErrTransactionExists is returned when trying to add a transaction to the queue that already exists
-var ErrTransactionExists = errors.New("transaction is already in queue")
+var (
+	// ErrTransactionExists is returned when trying to add a transaction to the queue that already exists
+	ErrTransactionExists = errors.New("transaction is already in queue")
+	// ErrTooLowPriority is returned when trying to add a transaction to the queue providing
+	// a tag already provided by a transaction in the queue with a higher or equal priority.
+	ErrTooLowPriority = errors.New("priority is too low to replace transaction")
+)
 
 var transactionQueueGauge = promauto.NewGauge(prometheus.GaugeOpts{
 	Namespace: "gossamer_state_transaction",
@@ -36,7 +42,11 @@ type Item struct {
 	order uint64
 
 	// The index is needed by update and is maintained by the heap.Interface methods.
-	index int // The index of the item in the heap.
+	index int // The index of the item in the heap, or -1 if the item is not in the heap.
+
+	// waitingFor is the number of tags required by the item and provided by transactions in
+	// the queue. The item is only added to the heap once it is not waiting for any of them.
+	waitingFor int
 }
 
 // A PriorityQueue implements heap.Interface and holds Items.
@@ -76,11 +86,17 @@ func (pq *priorityQueue) Pop() interface{} { //skipcq: GO-W1029
 	return item
 }
 
-// PriorityQueue is a thread safe wrapper over `priorityQueue`
+// PriorityQueue is a thread safe wrapper over `priorityQueue`.
+// It tracks the dependencies between the transactions of the queue, so a transaction
+// requiring tags provided by other transactions of the queue is only popped after them.
 type PriorityQueue struct {
-	pq           priorityQueue
-	currOrder    uint64
-	txs          map[common.Hash]*Item
+	pq        priorityQueue
+	currOrder uint64
+	txs       map[common.Hash]*Item
+	// providedBy maps a tag to the hash of the transaction of the queue providing it.
+	providedBy map[string]common.Hash
+	// requiredBy maps a tag to the hashes of the transactions of the queue requiring it.
+	requiredBy   map[string][]common.Hash
 	pollInterval time.Duration
 	sync.Mutex
 }
@@ -89,6 +105,8 @@ type PriorityQueue struct {
 func NewPriorityQueue() *PriorityQueue {
 	spq := &PriorityQueue{
 		txs:          make(map[common.Hash]*Item),
+		providedBy:   make(map[string]common.Hash),
+		requiredBy:   make(map[string][]common.Hash),
 		pollInterval: 10 * time.Millisecond,
 	}
 
@@ -101,30 +119,77 @@ func (spq *PriorityQueue) RemoveExtrinsic(ext types.Extrinsic) {
 	spq.Lock()
 	defer spq.Unlock()
 
-	hash := ext.Hash()
-	item, ok := spq.txs[hash]
+	item, ok := spq.txs[ext.Hash()]
 	if !ok {
 		return
 	}
 
-	heap.Remove(&spq.pq, item.index)
-	delete(spq.txs, hash)
+	spq.remove(item)
+	transactionQueueGauge.Set(float64(len(spq.txs)))
 }
 
 // Exists returns true if a hash is in the txs map, false otherwise
 func (spq *PriorityQueue) Exists(extHash common.Hash) bool {
+	spq.Lock()
+	defer spq.Unlock()
+
 	_, ok := spq.txs[extHash]
 	return ok
 }
 
-// Push inserts a valid transaction with priority p into the queue
+// IsProvided returns true if the tag is provided by a transaction of the queue, false otherwise
+func (spq *PriorityQueue) IsProvided(tag []byte) bool {
+	spq.Lock()
+	defer spq.Unlock()
+
+	_, ok := spq.providedBy[string(tag)]
+	return ok
+}
+
+// Push inserts a valid transaction with priority p into the queue.
+// See Replace for how transactions providing the same tags are handled.
 func (spq *PriorityQueue) Push(txn *ValidTransaction) (common.Hash, error) {
+	hash, _, err := spq.Replace(txn)
+	return hash, err
+}
+
+// Replace inserts a valid transaction into the queue, replacing the transactions of the queue
+// providing any of the tags it provides. It returns the replaced transactions, or an error
+// wrapping ErrTooLowPriority if any of them has a priority higher or equal to the transaction.
+// The transaction is only popped once all the transactions of the queue providing tags it
+// requires are popped.
+func (spq *PriorityQueue) Replace(txn *ValidTransaction) (
+	hash common.Hash, replaced []*ValidTransaction, err error) {
 	spq.Lock()
 	defer spq.Unlock()
 
-	hash := txn.Extrinsic.Hash()
+	hash = txn.Extrinsic.Hash()
 	if spq.txs[hash] != nil {
-		return hash, ErrTransactionExists
+		return hash, nil, ErrTransactionExists
+	}
+
+	var toReplace []*Item
+	for _, tag := range txn.Validity.Provides {
+		providerHash, ok := spq.providedBy[string(tag)]
+		if !ok {
+			continue
+		}
+
+		provider := spq.txs[providerHash]
+		if provider.priority >= txn.Validity.Priority {
+			return hash, nil, fmt.Errorf("%w: transaction %s with priority %d provides the same tag",
+				ErrTooLowPriority, providerHash, provider.priority)
+		}
+		toReplace = append(toReplace, provider)
+	}
+
+	for _, item := range toReplace {
+		if _, ok := spq.txs[item.hash]; !ok {
+			// already replaced since it provides multiple tags
+			continue
+		}
+		spq.remove(item)
+		replaced = append(replaced, item.data)
 	}
 
 	item := &Item{
@@ -132,13 +197,84 @@ func (spq *PriorityQueue) Push(txn *ValidTransaction) (common.Hash, error) {
 		hash:     hash,
 		order:    spq.currOrder,
 		priority: txn.Validity.Priority,
+		index:    -1,
 	}
 	spq.currOrder++
-	heap.Push(&spq.pq, item)
+
+	for _, tag := range txn.Validity.Requires {
+		if _, ok := spq.providedBy[string(tag)]; ok {
+			item.waitingFor++
+		}
+	}
+
+	// the transactions of the queue requiring the tags provided
+	// by the transaction now wait for it to be popped.
+	for _, tag := range txn.Validity.Provides {
+		spq.providedBy[string(tag)] = hash
+
+		for _, dependentHash := range spq.requiredBy[string(tag)] {
+			dependent := spq.txs[dependentHash]
+			if dependent.index >= 0 {
+				heap.Remove(&spq.pq, dependent.index)
+			}
+			dependent.waitingFor++
+		}
+	}
+
+	for _, tag := range txn.Validity.Requires {
+		spq.requiredBy[string(tag)] = append(spq.requiredBy[string(tag)], hash)
+	}
+
 	spq.txs[hash] = item
+	if item.waitingFor == 0 {
+		heap.Push(&spq.pq, item)
+	}
 
-	transactionQueueGauge.Set(float64(spq.pq.Len()))
-	return hash, nil
+	transactionQueueGauge.Set(float64(len(spq.txs)))
+	return hash, replaced, nil
+}
+
+// remove removes the item from the queue, and unlocks the items waiting for it.
+// It must be called with the queue lock held.
+func (spq *PriorityQueue) remove(item *Item) {
+	if item.index >= 0 {
+		heap.Remove(&spq.pq, item.index)
+	}
+	delete(spq.txs, item.hash)
+
+	for _, tag := range item.data.Validity.Provides {
+		if spq.providedBy[string(tag)] != item.hash {
+			continue
+		}
+		delete(spq.providedBy, string(tag))
+
+		for _, dependentHash := range spq.requiredBy[string(tag)] {
+			if dependentHash == item.hash {
+				continue
+			}
+			dependent := spq.txs[dependentHash]
+			dependent.waitingFor--
+			if dependent.waitingFor == 0 {
+				heap.Push(&spq.pq, dependent)
+			}
+		}
+	}
+
+	for _, tag := range item.data.Validity.Requires {
+		dependents := spq.requiredBy[string(tag)]
+		for i, dependentHash := range dependents {
+			if dependentHash == item.hash {
+				dependents = append(dependents[:i], dependents[i+1:]...)
+				break
+			}
+		}
+
+		if len(dependents) == 0 {
+			delete(spq.requiredBy, string(tag))
+		} else {
+			spq.requiredBy[string(tag)] = dependents
+		}
+	}
 }
 
 // PopWithTimer returns the next valid transaction from the queue.
@@ -184,10 +320,10 @@ func (spq *PriorityQueue) Pop() *ValidTransaction {
 		return nil
 	}
 
-	item := heap.Pop(&spq.pq).(*Item)
-	delete(spq.txs, item.hash)
+	item := spq.pq[0]
+	spq.remove(item)
 
-	transactionQueueGauge.Set(float64(spq.pq.Len()))
+	transactionQueueGauge.Set(float64(len(spq.txs)))
 	return item.data
 }
 
@@ -201,7 +337,8 @@ func (spq *PriorityQueue) Peek() *ValidTransaction {
 	return spq.pq[0].data
 }
 
-// Pending returns all the transactions currently in the queue
+// Pending returns all the transactions currently in the queue, starting
+// with the transactions not waiting for other transactions of the queue.
 func (spq *PriorityQueue) Pending() []*ValidTransaction {
 	spq.Lock()
 	defer spq.Unlock()
@@ -210,6 +347,12 @@ func (spq *PriorityQueue) Pending() []*ValidTransaction {
 	for idx := 0; idx < spq.pq.Len(); idx++ {
 		txns = append(txns, spq.pq[idx].data)
 	}
+
+	for _, item := range spq.txs {
+		if item.index < 0 {
+			txns = append(txns, item.data)
+		}
+	}
 	return txns
 }
 
@@ -218,5 +361,5 @@ func (spq *PriorityQueue) Len() int {
 	spq.Lock()
 	defer spq.Unlock()
 
-	return spq.pq.Len()
+	return len(spq.txs)
 }
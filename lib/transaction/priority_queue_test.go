@@ -345,3 +345,168 @@ func Test_PriorityQueue_PopWithTimer(t *testing.T) {
 		})
 	}
 }
+
+func TestPriorityQueue_Dependencies(t *testing.T) {
+	t.Parallel()
+
+	nonce0 := &ValidTransaction{
+		Extrinsic: []byte("nonce0"),
+		Validity:  &Validity{Priority: 1, Provides: [][]byte{{0}}},
+	}
+	nonce1 := &ValidTransaction{
+		Extrinsic: []byte("nonce1"),
+		Validity:  &Validity{Priority: 3, Requires: [][]byte{{0}}, Provides: [][]byte{{1}}},
+	}
+	nonce2 := &ValidTransaction{
+		Extrinsic: []byte("nonce2"),
+		Validity:  &Validity{Priority: 4, Requires: [][]byte{{1}}, Provides: [][]byte{{2}}},
+	}
+	other := &ValidTransaction{
+		Extrinsic: []byte("other"),
+		Validity:  &Validity{Priority: 2},
+	}
+
+	pq := NewPriorityQueue()
+	for _, txn := range []*ValidTransaction{nonce0, nonce2, nonce1, other} {
+		_, err := pq.Push(txn)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 4, pq.Len())
+	assert.True(t, pq.IsProvided([]byte{1}))
+	assert.False(t, pq.IsProvided([]byte{3}))
+	assert.ElementsMatch(t, []*ValidTransaction{nonce0, nonce1, nonce2, other}, pq.Pending())
+
+	expected := []*ValidTransaction{other, nonce0, nonce1, nonce2}
+	for _, txn := range expected {
+		assert.Equal(t, txn, pq.Pop())
+	}
+	assert.Nil(t, pq.Pop())
+	assert.False(t, pq.IsProvided([]byte{1}))
+}
+
+func TestPriorityQueue_RemoveExtrinsic_Dependencies(t *testing.T) {
+	t.Parallel()
+
+	nonce0 := &ValidTransaction{
+		Extrinsic: []byte("nonce0"),
+		Validity:  &Validity{Priority: 1, Provides: [][]byte{{0}}},
+	}
+	nonce1 := &ValidTransaction{
+		Extrinsic: []byte("nonce1"),
+		Validity:  &Validity{Priority: 2, Requires: [][]byte{{0}}, Provides: [][]byte{{1}}},
+	}
+
+	pq := NewPriorityQueue()
+	_, err := pq.Push(nonce0)
+	assert.NoError(t, err)
+	_, err = pq.Push(nonce1)
+	assert.NoError(t, err)
+
+	// removing the waiting transaction must not touch the heap
+	pq.RemoveExtrinsic(nonce1.Extrinsic)
+	assert.Equal(t, 1, pq.Len())
+	assert.False(t, pq.IsProvided([]byte{1}))
+
+	_, err = pq.Push(nonce1)
+	assert.NoError(t, err)
+
+	// removing the provider unlocks the waiting transaction
+	pq.RemoveExtrinsic(nonce0.Extrinsic)
+	assert.Equal(t, nonce1, pq.Peek())
+}
+
+func TestPriorityQueue_Replace(t *testing.T) {
+	t.Parallel()
+
+	low := &ValidTransaction{
+		Extrinsic: []byte("low"),
+		Validity:  &Validity{Priority: 1, Provides: [][]byte{{0}}},
+	}
+	equal := &ValidTransaction{
+		Extrinsic: []byte("equal"),
+		Validity:  &Validity{Priority: 1, Provides: [][]byte{{0}}},
+	}
+	high := &ValidTransaction{
+		Extrinsic: []byte("high"),
+		Validity:  &Validity{Priority: 2, Provides: [][]byte{{0}}},
+	}
+
+	pq := NewPriorityQueue()
+	_, replaced, err := pq.Replace(low)
+	assert.NoError(t, err)
+	assert.Empty(t, replaced)
+
+	_, replaced, err = pq.Replace(equal)
+	assert.ErrorIs(t, err, ErrTooLowPriority)
+	assert.Empty(t, replaced)
+
+	_, replaced, err = pq.Replace(high)
+	assert.NoError(t, err)
+	assert.Equal(t, []*ValidTransaction{low}, replaced)
+
+	assert.Equal(t, 1, pq.Len())
+	assert.False(t, pq.Exists(low.Extrinsic.Hash()))
+	assert.Equal(t, high, pq.Pop())
+}
+
+func TestPriorityQueue_Replace_Dependents(t *testing.T) {
+	t.Parallel()
+
+	provider := &ValidTransaction{
+		Extrinsic: []byte("provider"),
+		Validity:  &Validity{Priority: 1, Provides: [][]byte{{0}}},
+	}
+	dependent := &ValidTransaction{
+		Extrinsic: []byte("dependent"),
+		Validity:  &Validity{Priority: 5, Requires: [][]byte{{0}}},
+	}
+	replacement := &ValidTransaction{
+		Extrinsic: []byte("replacement"),
+		Validity:  &Validity{Priority: 2, Provides: [][]byte{{0}}},
+	}
+
+	pq := NewPriorityQueue()
+	for _, txn := range []*ValidTransaction{provider, dependent} {
+		_, err := pq.Push(txn)
+		assert.NoError(t, err)
+	}
+
+	_, replaced, err := pq.Replace(replacement)
+	assert.NoError(t, err)
+	assert.Equal(t, []*ValidTransaction{provider}, replaced)
+
+	// the dependent transaction now waits for the replacement
+	assert.Equal(t, replacement, pq.Pop())
+	assert.Equal(t, dependent, pq.Pop())
+	assert.Nil(t, pq.Pop())
+}
+
+func TestPriorityQueue_Dependencies_OutOfOrder(t *testing.T) {
+	t.Parallel()
+
+	nonce0 := &ValidTransaction{
+		Extrinsic: []byte("nonce0"),
+		Validity:  &Validity{Priority: 1, Provides: [][]byte{{0}}},
+	}
+	nonce1 := &ValidTransaction{
+		Extrinsic: []byte("nonce1"),
+		Validity:  &Validity{Priority: 2, Requires: [][]byte{{0}}, Provides: [][]byte{{1}}},
+	}
+	selfProvided := &ValidTransaction{
+		Extrinsic: []byte("self"),
+		Validity:  &Validity{Priority: 3, Requires: [][]byte{{2}}, Provides: [][]byte{{2}}},
+	}
+
+	pq := NewPriorityQueue()
+	for _, txn := range []*ValidTransaction{nonce1, nonce0, selfProvided} {
+		_, err := pq.Push(txn)
+		assert.NoError(t, err)
+	}
+
+	expected := []*ValidTransaction{selfProvided, nonce0, nonce1}
+	for _, txn := range expected {
+		assert.Equal(t, txn, pq.Pop())
+	}
+	assert.Nil(t, pq.Pop())
+}
@@ -49,12 +49,49 @@ func (t *Trie) Load(db Getter, rootHash common.Hash) error {
 		return fmt.Errorf("cannot decode root node: %w", err)
 	}
 
+	err = resolveHashedValue(db, root)
+	if err != nil {
+		return fmt.Errorf("resolving root node hashed value: %w", err)
+	}
+
 	t.root = root
 	t.root.MerkleValue = rootHashBytes
 
 	return t.loadNode(db, t.root)
 }
 
+// resolveHashedValue replaces the storage value hash of a node having
+// a hashed value with the storage value read from the database.
+// It is a no-op for nodes without hashed value or already resolved.
+func resolveHashedValue(db Getter, n *Node) (err error) {
+	if !n.IsHashedValue || len(n.StorageValue) > MaxInlineValue {
+		return nil
+	}
+
+	storageValueHash := n.StorageValue
+	n.StorageValue, err = db.Get(storageValueHash)
+	if err != nil {
+		return fmt.Errorf("getting storage value with hash 0x%x from database: %w",
+			storageValueHash, err)
+	}
+	return nil
+}
+
+// hashedValueKey returns the database key of the storage value of a
+// node having a hashed value, which is the storage value hash.
+// It returns false if the node storage value is not hashed.
+func hashedValueKey(n *Node) (key common.Hash, ok bool) {
+	if !n.IsHashedValue {
+		return key, false
+	}
+
+	if len(n.StorageValue) > MaxInlineValue {
+		return common.MustBlake2bHash(n.StorageValue), true
+	}
+	// the storage value is not resolved and is the storage value hash
+	return common.NewHash(n.StorageValue), true
+}
+
 func (t *Trie) loadNode(db Getter, n *Node) error {
 	if n.Kind() != node.Branch {
 		return nil
@@ -89,6 +126,12 @@ func (t *Trie) loadNode(db Getter, n *Node) error {
 			return fmt.Errorf("decoding node with Merkle value 0x%x: %w", merkleValue, err)
 		}
 
+		err = resolveHashedValue(db, decodedNode)
+		if err != nil {
+			return fmt.Errorf("resolving hashed value of node with Merkle value 0x%x: %w",
+				merkleValue, err)
+		}
+
 		decodedNode.MerkleValue = merkleValue
 		branch.Children[i] = decodedNode
 
@@ -164,7 +207,8 @@ func PopulateNodeHashes(n *Node, nodeHashes map[string]struct{}) {
 // Note it does not record inlined nodes.
 // It is assumed the node and its descendant nodes have their Merkle value already
 // computed, or the function will panic.
-func recordAllDeleted(n *Node, recorder DeltaRecorder) {
+// For lazy tries, descendant nodes not yet loaded are loaded from the database.
+func (t *Trie) recordAllDeleted(n *Node, recorder DeltaRecorder) {
 	if n == nil {
 		return
 	}
@@ -181,13 +225,17 @@ func recordAllDeleted(n *Node, recorder DeltaRecorder) {
 	nodeHash := common.NewHash(n.MerkleValue)
 	recorder.RecordDeleted(nodeHash)
 
+	n = t.mustResolveNode(n)
+	if storageValueHash, ok := hashedValueKey(n); ok {
+		recorder.RecordDeleted(storageValueHash)
+	}
 	if n.Kind() == node.Leaf {
 		return
 	}
 
 	branch := n
 	for _, child := range branch.Children {
-		recordAllDeleted(child, recorder)
+		t.recordAllDeleted(child, recorder)
 	}
 }
 
@@ -225,7 +273,7 @@ func getFromDBAtNode(db Getter, n *Node, key []byte) (
 	value []byte, err error) {
 	if n.Kind() == node.Leaf {
 		if bytes.Equal(n.PartialKey, key) {
-			return n.StorageValue, nil
+			return getStorageValueFromDB(db, n)
 		}
 		return nil, nil
 	}
@@ -233,7 +281,7 @@ func getFromDBAtNode(db Getter, n *Node, key []byte) (
 	branch := n
 	// Key is equal to the key of this branch or is empty
 	if len(key) == 0 || bytes.Equal(branch.PartialKey, key) {
-		return branch.StorageValue, nil
+		return getStorageValueFromDB(db, branch)
 	}
 
 	commonPrefixLength := lenCommonPrefix(branch.PartialKey, key)
@@ -276,6 +324,16 @@ func getFromDBAtNode(db Getter, n *Node, key []byte) (
 	// Note: do not wrap error since it's called recursively.
 }
 
+// getStorageValueFromDB returns the storage value of the node given,
+// reading it from the database if the node has a hashed value.
+func getStorageValueFromDB(db Getter, n *Node) (value []byte, err error) {
+	err = resolveHashedValue(db, n)
+	if err != nil {
+		return nil, err
+	}
+	return n.StorageValue, nil
+}
+
 // WriteDirty writes all dirty nodes to the database and sets them to clean
 func (t *Trie) WriteDirty(db NewBatcher) error {
 	batch := db.NewBatch()
@@ -312,6 +370,18 @@ func (t *Trie) writeDirtyNode(db Putter, n *Node) (err error) {
 			merkleValue, err)
 	}
 
+	if n.IsHashedValue && len(n.StorageValue) > MaxInlineValue {
+		// The node encoding only contains the hash of the storage value,
+		// so the storage value is stored in the database at its hash.
+		storageValueHash, _ := hashedValueKey(n)
+		err = db.Put(storageValueHash[:], n.StorageValue)
+		if err != nil {
+			return fmt.Errorf(
+				"putting hashed storage value of node with Merkle value 0x%x in database: %w",
+				merkleValue, err)
+		}
+	}
+
 	if n.Kind() != node.Branch {
 		n.SetClean()
 		return nil
@@ -342,10 +412,15 @@ func (t *Trie) writeDirtyNode(db Putter, n *Node) (err error) {
 
 // GetChangedNodeHashes returns the two sets of hashes for all nodes
 // inserted and deleted in the state trie since the last snapshot.
+// The sets also contain the hashes of the hashed storage values
+// inserted and deleted, since these are stored in the database at
+// their hash. A hashed storage value kept by a modified node is
+// in neither set.
 // Returned maps are safe for mutation.
 func (t *Trie) GetChangedNodeHashes() (inserted, deleted map[string]struct{}, err error) {
 	inserted = make(map[string]struct{})
-	err = t.getInsertedNodeHashesAtNode(t.root, inserted)
+	insertedStorageValueHashes := make(map[string]struct{})
+	err = t.getInsertedNodeHashesAtNode(t.root, inserted, insertedStorageValueHashes)
 	if err != nil {
 		return nil, nil, fmt.Errorf("getting inserted node hashes: %w", err)
 	}
@@ -357,10 +432,21 @@ func (t *Trie) GetChangedNodeHashes() (inserted, deleted map[string]struct{}, er
 		deleted[string(nodeHash[:])] = struct{}{}
 	}
 
+	for storageValueHash := range insertedStorageValueHashes {
+		_, isDeleted := deleted[storageValueHash]
+		if isDeleted {
+			// the storage value is unchanged in the database.
+			delete(deleted, storageValueHash)
+			continue
+		}
+		inserted[storageValueHash] = struct{}{}
+	}
+
 	return inserted, deleted, nil
 }
 
-func (t *Trie) getInsertedNodeHashesAtNode(n *Node, merkleValues map[string]struct{}) (err error) {
+func (t *Trie) getInsertedNodeHashesAtNode(n *Node, merkleValues,
+	storageValueHashes map[string]struct{}) (err error) {
 	if n == nil || !n.Dirty {
 		return nil
 	}
@@ -379,6 +465,10 @@ func (t *Trie) getInsertedNodeHashesAtNode(n *Node, merkleValues map[string]stru
 
 	merkleValues[string(merkleValue)] = struct{}{}
 
+	if storageValueHash, ok := hashedValueKey(n); ok {
+		storageValueHashes[string(storageValueHash[:])] = struct{}{}
+	}
+
 	if n.Kind() != node.Branch {
 		return nil
 	}
@@ -388,7 +478,7 @@ func (t *Trie) getInsertedNodeHashesAtNode(n *Node, merkleValues map[string]stru
 			continue
 		}
 
-		err := t.getInsertedNodeHashesAtNode(child, merkleValues)
+		err := t.getInsertedNodeHashesAtNode(child, merkleValues, storageValueHashes)
 		if err != nil {
 			// Note: do not wrap error since this is called recursively.
 			return err
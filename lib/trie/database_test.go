@@ -4,9 +4,11 @@
 package trie
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -254,6 +256,86 @@ func Test_PopulateNodeHashes(t *testing.T) {
 	}
 }
 
+func Test_Trie_V1_Store_Load(t *testing.T) {
+	t.Parallel()
+
+	keyValues := map[string][]byte{
+		"short": {1, 2, 3},
+		"long":  bytes.Repeat([]byte{4}, MaxInlineValue+1),
+		"lo":    bytes.Repeat([]byte{5}, 100),
+	}
+
+	trieV0 := NewEmptyTrie()
+	trieV1 := NewEmptyTrie()
+	trieV1.SetVersion(V1)
+	for key, value := range keyValues {
+		err := trieV0.Put([]byte(key), value)
+		require.NoError(t, err)
+		err = trieV1.Put([]byte(key), value)
+		require.NoError(t, err)
+	}
+
+	rootHash := trieV1.MustHash()
+	assert.NotEqual(t, trieV0.MustHash(), rootHash)
+
+	db := newTestDB(t)
+	err := trieV1.WriteDirty(db)
+	require.NoError(t, err)
+
+	trieFromDB := NewEmptyTrie()
+	err = trieFromDB.Load(db, rootHash)
+	require.NoError(t, err)
+	assert.Equal(t, rootHash, trieFromDB.MustHash())
+
+	for key, expectedValue := range keyValues {
+		assert.Equal(t, expectedValue, trieFromDB.Get([]byte(key)))
+
+		value, err := GetFromDB(db, rootHash, []byte(key))
+		require.NoError(t, err)
+		assert.Equal(t, expectedValue, value)
+	}
+}
+
+func Test_Trie_V1_GetChangedNodeHashes(t *testing.T) {
+	t.Parallel()
+
+	loValue := bytes.Repeat([]byte{1}, 100)
+	longValue := bytes.Repeat([]byte{2}, MaxInlineValue+1)
+	loValueHash := string(common.MustBlake2bHash(loValue).ToBytes())
+	longValueHash := string(common.MustBlake2bHash(longValue).ToBytes())
+
+	trie := NewEmptyTrie()
+	trie.SetVersion(V1)
+	err := trie.Put([]byte("lo"), loValue)
+	require.NoError(t, err)
+	err = trie.Put([]byte("long"), longValue)
+	require.NoError(t, err)
+
+	inserted, deleted, err := trie.GetChangedNodeHashes()
+	require.NoError(t, err)
+	assert.Contains(t, inserted, loValueHash)
+	assert.Contains(t, inserted, longValueHash)
+	assert.Empty(t, deleted)
+
+	err = trie.WriteDirty(newTestDB(t))
+	require.NoError(t, err)
+	trie = trie.Snapshot()
+
+	// The "lo" branch is modified but keeps its hashed storage value,
+	// whereas the "long" leaf hashed storage value is replaced.
+	newLongValue := bytes.Repeat([]byte{3}, MaxInlineValue+1)
+	newLongValueHash := string(common.MustBlake2bHash(newLongValue).ToBytes())
+	err = trie.Put([]byte("long"), newLongValue)
+	require.NoError(t, err)
+
+	inserted, deleted, err = trie.GetChangedNodeHashes()
+	require.NoError(t, err)
+	assert.Contains(t, inserted, newLongValueHash)
+	assert.Contains(t, deleted, longValueHash)
+	assert.NotContains(t, inserted, loValueHash)
+	assert.NotContains(t, deleted, loValueHash)
+}
+
 func Test_GetFromDB(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,240 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/internal/trie/codec"
+	"github.com/ChainSafe/gossamer/internal/trie/node"
+)
+
+// Diff returns the keys in little Endian format of the storage values
+// added, modified or deleted between the previous trie and the next trie,
+// in lexicographic order.
+// Sub-tries with the same Merkle value in both tries are skipped without
+// being traversed, such that only the nodes differing between lazy tries
+// are loaded from the database.
+// Note child tries are not compared, and only changes of their root hash
+// stored in the trie are returned.
+func Diff(previous, next *Trie) (changedKeysLE [][]byte, err error) {
+	var changedKeys [][]byte
+
+	if !equalMerkleValues(previous.root, next.root) {
+		previousRoot, err := newDiffCursor(previous, previous.root)
+		if err != nil {
+			return nil, fmt.Errorf("resolving previous root node: %w", err)
+		}
+
+		nextRoot, err := newDiffCursor(next, next.root)
+		if err != nil {
+			return nil, fmt.Errorf("resolving next root node: %w", err)
+		}
+
+		changedKeys, err = diff(nil, previousRoot, nextRoot, changedKeys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	changedKeysLE = make([][]byte, len(changedKeys))
+	for i, changedKey := range changedKeys {
+		changedKeysLE[i] = codec.NibblesToKeyLE(changedKey)
+	}
+	return changedKeysLE, nil
+}
+
+// diffCursor is a position in a trie, at the remaining partial key
+// nibbles of a node not yet traversed. The node is nil if there is
+// no node at this position in the trie.
+type diffCursor struct {
+	trie       *Trie
+	node       *Node
+	partialKey []byte
+}
+
+// newDiffCursor returns a cursor at the start of the partial key
+// of the node given, which is loaded from the database if needed.
+func newDiffCursor(t *Trie, n *Node) (cursor diffCursor, err error) {
+	n, err = t.ResolveNode(n)
+	if err != nil {
+		return cursor, err
+	}
+
+	cursor = diffCursor{trie: t, node: n}
+	if n != nil {
+		cursor.partialKey = n.PartialKey
+	}
+	return cursor, nil
+}
+
+// diff appends the nibbles keys of the storage values differing between the
+// sub-tries at the cursors given, which are both at the nibbles prefix given.
+// The comparison is symmetric, so the cursors can be given in any order.
+func diff(prefix []byte, a, b diffCursor, changedKeys [][]byte) (
+	updatedChangedKeys [][]byte, err error) {
+	switch {
+	case a.node == nil && b.node == nil:
+		return changedKeys, nil
+	case a.node == nil:
+		return appendAllKeys(prefix, b, changedKeys)
+	case b.node == nil:
+		return appendAllKeys(prefix, a, changedKeys)
+	}
+
+	commonPrefixLength := lenCommonPrefix(a.partialKey, b.partialKey)
+	prefix = concatenateSlices(prefix, a.partialKey[:commonPrefixLength])
+	a.partialKey = a.partialKey[commonPrefixLength:]
+	b.partialKey = b.partialKey[commonPrefixLength:]
+
+	switch {
+	case len(a.partialKey) == 0 && len(b.partialKey) == 0:
+		return diffAtNodes(prefix, a, b, changedKeys)
+	case len(a.partialKey) == 0:
+		return diffAtNodeAndPartialKey(prefix, a, b, changedKeys)
+	case len(b.partialKey) == 0:
+		return diffAtNodeAndPartialKey(prefix, b, a, changedKeys)
+	}
+
+	// Both partial keys diverge, so all the keys of both sub-tries differ.
+	if a.partialKey[0] > b.partialKey[0] {
+		a, b = b, a
+	}
+
+	changedKeys, err = appendAllKeys(prefix, a, changedKeys)
+	if err != nil {
+		return nil, err
+	}
+	return appendAllKeys(prefix, b, changedKeys)
+}
+
+// diffAtNodes appends the nibbles keys of the storage values differing between
+// the two nodes at the cursors given, both having their partial key traversed.
+func diffAtNodes(prefix []byte, a, b diffCursor, changedKeys [][]byte) (
+	updatedChangedKeys [][]byte, err error) {
+	aHasValue, bHasValue := hasStorageValue(a.node), hasStorageValue(b.node)
+	if aHasValue != bHasValue ||
+		(aHasValue && !bytes.Equal(a.node.StorageValue, b.node.StorageValue)) {
+		changedKeys = append(changedKeys, prefix)
+	}
+
+	for i := 0; i < node.ChildrenCapacity; i++ {
+		aChild, bChild := childAt(a.node, i), childAt(b.node, i)
+		if equalMerkleValues(aChild, bChild) {
+			continue
+		}
+
+		aChildCursor, err := newDiffCursor(a.trie, aChild)
+		if err != nil {
+			return nil, fmt.Errorf("resolving child at index %d: %w", i, err)
+		}
+
+		bChildCursor, err := newDiffCursor(b.trie, bChild)
+		if err != nil {
+			return nil, fmt.Errorf("resolving child at index %d: %w", i, err)
+		}
+
+		childPrefix := concatenateSlices(prefix, intToByteSlice(i))
+		changedKeys, err = diff(childPrefix, aChildCursor, bChildCursor, changedKeys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return changedKeys, nil
+}
+
+// diffAtNodeAndPartialKey appends the nibbles keys of the storage values
+// differing between the node at the cursor `atNode` having its partial key
+// traversed, and the node at the cursor `inPartialKey` having remaining
+// partial key nibbles, and hence having no storage value at the prefix given.
+func diffAtNodeAndPartialKey(prefix []byte, atNode, inPartialKey diffCursor, changedKeys [][]byte) (
+	updatedChangedKeys [][]byte, err error) {
+	if hasStorageValue(atNode.node) {
+		changedKeys = append(changedKeys, prefix)
+	}
+
+	nextNibble := inPartialKey.partialKey[0]
+	inPartialKey.partialKey = inPartialKey.partialKey[1:]
+
+	for i := 0; i < node.ChildrenCapacity; i++ {
+		child := childAt(atNode.node, i)
+		if child == nil && i != int(nextNibble) {
+			continue
+		}
+
+		childCursor, err := newDiffCursor(atNode.trie, child)
+		if err != nil {
+			return nil, fmt.Errorf("resolving child at index %d: %w", i, err)
+		}
+
+		childPrefix := concatenateSlices(prefix, intToByteSlice(i))
+		if i == int(nextNibble) {
+			changedKeys, err = diff(childPrefix, childCursor, inPartialKey, changedKeys)
+		} else {
+			changedKeys, err = appendAllKeys(childPrefix, childCursor, changedKeys)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return changedKeys, nil
+}
+
+// appendAllKeys appends the nibbles keys of all the storage values
+// of the sub-trie at the cursor given, loading nodes from the
+// database if needed.
+func appendAllKeys(prefix []byte, cursor diffCursor, keys [][]byte) (
+	updatedKeys [][]byte, err error) {
+	if cursor.node == nil {
+		return keys, nil
+	}
+
+	fullKey := concatenateSlices(prefix, cursor.partialKey)
+	if hasStorageValue(cursor.node) {
+		keys = append(keys, fullKey)
+	}
+
+	for i, child := range cursor.node.Children {
+		if child == nil {
+			continue
+		}
+
+		childCursor, err := newDiffCursor(cursor.trie, child)
+		if err != nil {
+			return nil, fmt.Errorf("resolving child at index %d: %w", i, err)
+		}
+
+		childPrefix := concatenateSlices(fullKey, intToByteSlice(i))
+		keys, err = appendAllKeys(childPrefix, childCursor, keys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// equalMerkleValues returns true if both nodes are nil, or if both
+// nodes have the same Merkle value already computed, in which case
+// the sub-tries of both nodes are identical.
+func equalMerkleValues(a, b *Node) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return len(a.MerkleValue) > 0 && bytes.Equal(a.MerkleValue, b.MerkleValue)
+}
+
+func hasStorageValue(n *Node) bool {
+	return n.Kind() == node.Leaf || n.StorageValue != nil
+}
+
+func childAt(n *Node, index int) (child *Node) {
+	if n.Children == nil {
+		return nil
+	}
+	return n.Children[index]
+}
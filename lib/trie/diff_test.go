@@ -0,0 +1,160 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTrieFromEntries(t *testing.T, entries map[string]string) *Trie {
+	t.Helper()
+
+	trie := NewEmptyTrie()
+	for key, value := range entries {
+		err := trie.Put([]byte(key), []byte(value))
+		require.NoError(t, err)
+	}
+	return trie
+}
+
+func Test_Diff(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		previous      map[string]string
+		next          map[string]string
+		changedKeysLE [][]byte
+	}{
+		"empty_tries": {
+			changedKeysLE: [][]byte{},
+		},
+		"identical_tries": {
+			previous:      map[string]string{"a": "1", "ab": "2", "b": "3"},
+			next:          map[string]string{"a": "1", "ab": "2", "b": "3"},
+			changedKeysLE: [][]byte{},
+		},
+		"all_keys_added": {
+			next:          map[string]string{"a": "1", "ab": "2"},
+			changedKeysLE: [][]byte{[]byte("a"), []byte("ab")},
+		},
+		"all_keys_deleted": {
+			previous:      map[string]string{"a": "1", "ab": "2"},
+			changedKeysLE: [][]byte{[]byte("a"), []byte("ab")},
+		},
+		"value_modified": {
+			previous:      map[string]string{"a": "1", "ab": "2", "b": "3"},
+			next:          map[string]string{"a": "1", "ab": "4", "b": "3"},
+			changedKeysLE: [][]byte{[]byte("ab")},
+		},
+		"empty_value_modified": {
+			previous:      map[string]string{"a": "", "b": "1"},
+			next:          map[string]string{"a": "1", "b": "1"},
+			changedKeysLE: [][]byte{[]byte("a")},
+		},
+		"branch_value_deleted": {
+			previous:      map[string]string{"a": "1", "ab": "2", "ac": "3"},
+			next:          map[string]string{"ab": "2", "ac": "3"},
+			changedKeysLE: [][]byte{[]byte("a")},
+		},
+		"key_added_in_partial_key": {
+			previous:      map[string]string{"abc": "1", "abd": "2"},
+			next:          map[string]string{"a": "3", "abc": "1", "abd": "2"},
+			changedKeysLE: [][]byte{[]byte("a")},
+		},
+		"key_added_after_leaf": {
+			previous:      map[string]string{"a": "1", "b": "2"},
+			next:          map[string]string{"a": "1", "abcd": "3", "b": "2"},
+			changedKeysLE: [][]byte{[]byte("abcd")},
+		},
+		"diverging_partial_keys": {
+			previous:      map[string]string{"ab": "1", "ac": "2"},
+			next:          map[string]string{"a": "1", "b": "2"},
+			changedKeysLE: [][]byte{[]byte("a"), []byte("ab"), []byte("ac"), []byte("b")},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			previous := newTrieFromEntries(t, testCase.previous)
+			next := newTrieFromEntries(t, testCase.next)
+
+			changedKeysLE, err := Diff(previous, next)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.changedKeysLE, changedKeysLE)
+
+			changedKeysLE, err = Diff(next, previous)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.changedKeysLE, changedKeysLE)
+		})
+	}
+}
+
+func Test_Diff_lazyTries(t *testing.T) {
+	t.Parallel()
+
+	previous, keyValues, db := makeStoredTrie(t, 1000)
+	previousRootHash := previous.MustHash()
+
+	next := previous.Snapshot()
+	generator := newGenerator()
+	changedKeyValues := generateKeyValues(t, generator, 10)
+	i := 0
+	for keyString := range keyValues {
+		if i == 5 {
+			break
+		}
+		changedKeyValues[keyString] = nil
+		i++
+	}
+
+	for keyString, value := range changedKeyValues {
+		if value == nil {
+			err := next.Delete([]byte(keyString))
+			require.NoError(t, err)
+			continue
+		}
+		err := next.Put([]byte(keyString), value)
+		require.NoError(t, err)
+	}
+
+	err := next.WriteDirty(db)
+	require.NoError(t, err)
+	nextRootHash := next.MustHash()
+
+	expectedChangedKeys := make([][]byte, 0, len(changedKeyValues))
+	for keyString, value := range changedKeyValues {
+		if !bytes.Equal(previous.Get([]byte(keyString)), value) {
+			expectedChangedKeys = append(expectedChangedKeys, []byte(keyString))
+		}
+	}
+	sort.Slice(expectedChangedKeys, func(i, j int) bool {
+		return bytes.Compare(expectedChangedKeys[i], expectedChangedKeys[j]) < 0
+	})
+
+	changedKeysLE, err := Diff(previous, next)
+	require.NoError(t, err)
+	assert.Equal(t, expectedChangedKeys, changedKeysLE)
+
+	getter := &countingGetter{Getter: db}
+	lazyPrevious, err := NewLazyTrie(getter, previousRootHash, nil)
+	require.NoError(t, err)
+	lazyNext, err := NewLazyTrie(getter, nextRootHash, nil)
+	require.NoError(t, err)
+	getter.gets = 0
+
+	changedKeysLE, err = Diff(lazyPrevious, lazyNext)
+	require.NoError(t, err)
+	assert.Equal(t, expectedChangedKeys, changedKeysLE)
+
+	// only the nodes on the paths of the changed keys are loaded
+	assert.Less(t, getter.gets, len(keyValues)/2)
+}
@@ -0,0 +1,168 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/internal/trie/node"
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// NewLazyTrie creates a trie for the given root hash where nodes are
+// loaded from the database only once they are traversed, instead of
+// loading all the trie nodes in memory as `Load` does.
+// Nodes loaded from the database are stored in the node cache given,
+// which can be shared between lazy tries and can be left to nil.
+// Note reading from a lazy trie panics if a node cannot be loaded
+// from the database, for example if it got pruned, and callers reading
+// from a lazy trie should recover it as an error with CatchLoadNodePanic.
+// A lazy trie must only be read from, since the descendants count of its
+// branches loaded from the database only accounts for their loaded
+// descendants, and modifying it would corrupt these counts. Use `Load`
+// to obtain a trie to modify.
+func NewLazyTrie(db Getter, rootHash common.Hash, cache *NodeCache) (_ *Trie, err error) {
+	if rootHash == EmptyHash {
+		// there is no node to load from the database.
+		return NewEmptyTrie(), nil
+	}
+
+	t := NewEmptyTrie()
+	t.db = db
+	t.nodeCache = cache
+	// Nodes loaded from the database have their generation set to zero
+	// and may be shared with other lazy tries through the node cache, so
+	// the lazy trie generation starts at one such that these nodes are
+	// always copied on write instead of being modified in place.
+	t.generation = 1
+
+	root, err := t.loadNodeFromDB(rootHash.ToBytes())
+	if err != nil {
+		return nil, fmt.Errorf("loading root node: %w", err)
+	}
+	t.root = root
+
+	defer CatchLoadNodePanic(&err)
+	for _, key := range t.GetKeysWithPrefix(ChildStorageKeyPrefix) {
+		childRootHash := common.BytesToHash(t.Get(key))
+		childTrie, err := NewLazyTrie(db, childRootHash, cache)
+		if err != nil {
+			return nil, fmt.Errorf("loading child trie at key 0x%x: %w", key, err)
+		}
+		t.childTries[childRootHash] = childTrie
+	}
+
+	return t, nil
+}
+
+// ResolveNode returns the node loaded from the database if the node
+// given is only a reference to its Merkle value, which is the case
+// for the nodes of a lazy trie not traversed yet.
+// Otherwise, it returns the node given as is.
+func (t *Trie) ResolveNode(n *Node) (resolved *Node, err error) {
+	if t.db == nil || n == nil || !isNodeReference(n) {
+		return n, nil
+	}
+
+	resolved, err = t.loadNodeFromDB(n.MerkleValue)
+	if err != nil {
+		return nil, fmt.Errorf("loading node with Merkle value 0x%x: %w",
+			n.MerkleValue, err)
+	}
+	return resolved, nil
+}
+
+// ErrLoadNode is wrapped by the error recovered by CatchLoadNodePanic
+// when a read operation on a lazy trie fails to load a node.
+var ErrLoadNode = errors.New("cannot load trie node")
+
+// loadNodePanic is the value a read operation on a lazy trie
+// panics with if it fails to load a node from the database.
+type loadNodePanic struct {
+	err error
+}
+
+// mustResolveNode is like ResolveNode but panics on error.
+// It is used in read operations of the trie which cannot return an error,
+// and the panic can be recovered as an error with CatchLoadNodePanic.
+func (t *Trie) mustResolveNode(n *Node) (resolved *Node) {
+	resolved, err := t.ResolveNode(n)
+	if err != nil {
+		panic(loadNodePanic{err: fmt.Errorf("%w: %s", ErrLoadNode, err)})
+	}
+	return resolved
+}
+
+// CatchLoadNodePanic recovers the panic of a read operation on a lazy
+// trie failing to load a node from the database, and sets the error
+// pointed to with an error wrapping ErrLoadNode. Other panics are
+// propagated. It must be deferred directly, for example with
+// `defer trie.CatchLoadNodePanic(&err)`.
+func CatchLoadNodePanic(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	loadPanic, ok := r.(loadNodePanic)
+	if !ok {
+		panic(r)
+	}
+	*err = loadPanic.err
+}
+
+// isNodeReference returns true if the node is only a reference
+// to a node stored in the database, as decoded from the encoding
+// of its parent branch.
+func isNodeReference(n *Node) bool {
+	const hashLength = 32
+	return !n.Dirty && n.Children == nil &&
+		len(n.PartialKey) == 0 && len(n.StorageValue) == 0 &&
+		len(n.MerkleValue) == hashLength
+}
+
+// loadNodeFromDB returns the node with the given Merkle value from
+// the node cache, or loads it from the database and adds it to the cache.
+// Its inlined children are fully decoded with their Merkle value set, and
+// its other children are references to be resolved with ResolveNode.
+func (t *Trie) loadNodeFromDB(merkleValue []byte) (n *Node, err error) {
+	n, ok := t.nodeCache.get(merkleValue)
+	if ok {
+		return n, nil
+	}
+
+	encodedNode, err := t.db.Get(merkleValue)
+	if err != nil {
+		return nil, fmt.Errorf("getting node from database: %w", err)
+	}
+
+	n, err = node.Decode(bytes.NewReader(encodedNode))
+	if err != nil {
+		return nil, fmt.Errorf("decoding node: %w", err)
+	}
+
+	err = resolveHashedValue(t.db, n)
+	if err != nil {
+		return nil, fmt.Errorf("resolving hashed value: %w", err)
+	}
+	n.MerkleValue = merkleValue
+
+	for i, child := range n.Children {
+		if child == nil || isNodeReference(child) {
+			continue
+		}
+
+		// inlined child node, set its Merkle value so the node
+		// can be safely shared without being modified later on.
+		_, err = child.CalculateMerkleValue()
+		if err != nil {
+			return nil, fmt.Errorf("calculating Merkle value of inlined child at index %d: %w", i, err)
+		}
+	}
+
+	t.nodeCache.add(merkleValue, n)
+	return n, nil
+}
@@ -0,0 +1,228 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingGetter struct {
+	Getter
+	gets int
+}
+
+func (c *countingGetter) Get(key []byte) (value []byte, err error) {
+	c.gets++
+	return c.Getter.Get(key)
+}
+
+func makeStoredTrie(t *testing.T, size int) (
+	trie *Trie, keyValues map[string][]byte, db chaindb.Database) {
+	t.Helper()
+
+	generator := newGenerator()
+	keyValues = generateKeyValues(t, generator, size)
+
+	trie = NewEmptyTrie()
+	trie.SetVersion(V1)
+	for keyString, value := range keyValues {
+		err := trie.Put([]byte(keyString), value)
+		require.NoError(t, err)
+	}
+
+	child := NewEmptyTrie()
+	err := child.Put([]byte("child_key"), bytes.Repeat([]byte{1}, 40))
+	require.NoError(t, err)
+	err = trie.SetChild([]byte("child"), child)
+	require.NoError(t, err)
+
+	db = newTestDB(t)
+	err = trie.WriteDirty(db)
+	require.NoError(t, err)
+
+	return trie, keyValues, db
+}
+
+func Test_NewLazyTrie(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_root", func(t *testing.T) {
+		t.Parallel()
+
+		lazyTrie, err := NewLazyTrie(newTestDB(t), EmptyHash, nil)
+		require.NoError(t, err)
+		assert.Equal(t, EmptyHash, lazyTrie.MustHash())
+	})
+
+	t.Run("root_not_found", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewLazyTrie(newTestDB(t), common.Hash{1}, nil)
+		assert.ErrorIs(t, err, chaindb.ErrKeyNotFound)
+	})
+
+	t.Run("only_root_and_child_tries_loaded", func(t *testing.T) {
+		t.Parallel()
+
+		trie, _, db := makeStoredTrie(t, 100)
+		rootHash := trie.MustHash()
+
+		getter := &countingGetter{Getter: db}
+		lazyTrie, err := NewLazyTrie(getter, rootHash, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, rootHash, lazyTrie.MustHash())
+		assert.Less(t, getter.gets, 10)
+
+		child, err := lazyTrie.GetChild([]byte("child"))
+		require.NoError(t, err)
+		value := child.Get([]byte("child_key"))
+		assert.Equal(t, bytes.Repeat([]byte{1}, 40), value)
+	})
+}
+
+func Test_LazyTrie_reads(t *testing.T) {
+	t.Parallel()
+
+	trie, keyValues, db := makeStoredTrie(t, 1000)
+	rootHash := trie.MustHash()
+
+	lazyTrie, err := NewLazyTrie(db, rootHash, NewNodeCache(100))
+	require.NoError(t, err)
+
+	assert.Equal(t, rootHash, lazyTrie.MustHash())
+	assert.Equal(t, trie.Entries(), lazyTrie.Entries())
+
+	for keyString, value := range keyValues {
+		key := []byte(keyString)
+		assert.Equalf(t, value, lazyTrie.Get(key), "for key 0x%x", key)
+		assert.Equalf(t, trie.NextKey(key), lazyTrie.NextKey(key), "for key 0x%x", key)
+	}
+
+	for _, prefix := range [][]byte{nil, {1}, {0xff}, ChildStorageKeyPrefix} {
+		assert.Equalf(t, trie.GetKeysWithPrefix(prefix),
+			lazyTrie.GetKeysWithPrefix(prefix), "for prefix 0x%x", prefix)
+	}
+}
+
+func Test_LazyTrie_Get_loadsTraversedNodesOnly(t *testing.T) {
+	t.Parallel()
+
+	trie, keyValues, db := makeStoredTrie(t, 1000)
+	rootHash := trie.MustHash()
+
+	getter := &countingGetter{Getter: db}
+	lazyTrie, err := NewLazyTrie(getter, rootHash, NewNodeCache(1000))
+	require.NoError(t, err)
+
+	var key []byte
+	for keyString := range keyValues {
+		key = []byte(keyString)
+		break
+	}
+
+	getter.gets = 0
+	value := lazyTrie.Get(key)
+	assert.Equal(t, keyValues[string(key)], value)
+	// the trie has more than 1000 nodes, and one node
+	// is loaded for each level traversed, plus the
+	// eventual hashed storage value.
+	assert.Less(t, getter.gets, 20)
+
+	getter.gets = 0
+	value = lazyTrie.Get(key)
+	assert.Equal(t, keyValues[string(key)], value)
+	assert.Zero(t, getter.gets)
+}
+
+type failingGetter struct {
+	Getter
+	fail bool
+}
+
+func (f *failingGetter) Get(key []byte) (value []byte, err error) {
+	if f.fail {
+		return nil, chaindb.ErrKeyNotFound
+	}
+	return f.Getter.Get(key)
+}
+
+func Test_CatchLoadNodePanic(t *testing.T) {
+	t.Parallel()
+
+	trie, _, db := makeStoredTrie(t, 100)
+
+	getter := &failingGetter{Getter: db}
+	lazyTrie, err := NewLazyTrie(getter, trie.MustHash(), nil)
+	require.NoError(t, err)
+	getter.fail = true
+
+	readEntries := func() (entries map[string][]byte, err error) {
+		defer CatchLoadNodePanic(&err)
+		return lazyTrie.Entries(), nil
+	}
+
+	entries, err := readEntries()
+	assert.ErrorIs(t, err, ErrLoadNode)
+	assert.ErrorContains(t, err, chaindb.ErrKeyNotFound.Error())
+	assert.Nil(t, entries)
+
+	otherPanic := func() (err error) {
+		defer CatchLoadNodePanic(&err)
+		panic("other panic")
+	}
+	assert.PanicsWithValue(t, "other panic", func() { _ = otherPanic() })
+}
+
+func Test_LazyTrie_mutations(t *testing.T) {
+	t.Parallel()
+
+	trie, keyValues, db := makeStoredTrie(t, 1000)
+	rootHash := trie.MustHash()
+
+	lazyTrie, err := NewLazyTrie(db, rootHash, NewNodeCache(100))
+	require.NoError(t, err)
+	lazyTrie.SetVersion(V1)
+
+	generator := newGenerator()
+	keys := pickKeys(keyValues, generator, 5)
+
+	for _, tr := range []*Trie{trie, lazyTrie} {
+		err = tr.Put([]byte("new_key"), bytes.Repeat([]byte{2}, 50))
+		require.NoError(t, err)
+		for _, key := range keys {
+			err = tr.Delete(key)
+			require.NoError(t, err)
+		}
+		err = tr.ClearPrefix([]byte{1})
+		require.NoError(t, err)
+		_, _, err = tr.ClearPrefixLimit([]byte{2}, 3)
+		require.NoError(t, err)
+		err = tr.PutIntoChild([]byte("child"), []byte("other_key"), []byte{3})
+		require.NoError(t, err)
+	}
+
+	expectedRootHash := trie.MustHash()
+	assert.Equal(t, expectedRootHash, lazyTrie.MustHash())
+	assert.Equal(t, trie.Entries(), lazyTrie.Entries())
+
+	err = lazyTrie.WriteDirty(db)
+	require.NoError(t, err)
+
+	reloadedTrie, err := NewLazyTrie(db, expectedRootHash, nil)
+	require.NoError(t, err)
+	assert.Equal(t, trie.Entries(), reloadedTrie.Entries())
+
+	// the original root is not modified by the mutations
+	originalTrie, err := NewLazyTrie(db, rootHash, nil)
+	require.NoError(t, err)
+	assert.Equal(t, rootHash, originalTrie.MustHash())
+	assert.Equal(t, len(keyValues)+1, len(originalTrie.Entries()))
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"container/list"
+	"sync"
+)
+
+// NodeCache is a thread safe least recently used cache of
+// trie nodes decoded from the database, keyed by their Merkle value.
+// It is meant to be shared by lazy tries using the same database.
+// Nodes stored in the cache must not be modified.
+type NodeCache struct {
+	mutex sync.Mutex
+	// mapping is a map of node Merkle value to linked list element.
+	mapping map[string]*list.Element
+	// linkedList is a double linked list of node cache entries
+	// to track the order nodes were used in.
+	linkedList *list.List
+	capacity   int
+}
+
+type nodeCacheEntry struct {
+	merkleValue string
+	node        *Node
+}
+
+// NewNodeCache creates a new node cache with the capacity specified,
+// which is the maximum number of nodes kept in the cache.
+func NewNodeCache(capacity int) *NodeCache {
+	return &NodeCache{
+		mapping:    make(map[string]*list.Element, capacity),
+		linkedList: list.New(),
+		capacity:   capacity,
+	}
+}
+
+// get returns the node for the given Merkle value and true if it is
+// in the cache, and marks it as the most recently used node.
+// It returns nil and false if the cache is nil or the node is not found.
+func (nc *NodeCache) get(merkleValue []byte) (n *Node, ok bool) {
+	if nc == nil {
+		return nil, false
+	}
+
+	nc.mutex.Lock()
+	defer nc.mutex.Unlock()
+
+	listElement, ok := nc.mapping[string(merkleValue)]
+	if !ok {
+		return nil, false
+	}
+
+	nc.linkedList.MoveToFront(listElement)
+	return listElement.Value.(*nodeCacheEntry).node, true
+}
+
+// add adds the node at the given Merkle value to the cache.
+// If the cache capacity is reached, the least recently used node is removed.
+// It is a no-op if the cache is nil.
+func (nc *NodeCache) add(merkleValue []byte, n *Node) {
+	if nc == nil || nc.capacity <= 0 {
+		return
+	}
+
+	nc.mutex.Lock()
+	defer nc.mutex.Unlock()
+
+	key := string(merkleValue)
+	listElement, ok := nc.mapping[key]
+	if ok {
+		listElement.Value.(*nodeCacheEntry).node = n
+		nc.linkedList.MoveToFront(listElement)
+		return
+	}
+
+	if nc.linkedList.Len() >= nc.capacity {
+		oldestElement := nc.linkedList.Back()
+		nc.linkedList.Remove(oldestElement)
+		oldestEntry := oldestElement.Value.(*nodeCacheEntry)
+		delete(nc.mapping, oldestEntry.merkleValue)
+	}
+
+	entry := &nodeCacheEntry{
+		merkleValue: key,
+		node:        n,
+	}
+	nc.mapping[key] = nc.linkedList.PushFront(entry)
+}
+
+// len returns the number of nodes in the cache.
+func (nc *NodeCache) len() int {
+	nc.mutex.Lock()
+	defer nc.mutex.Unlock()
+	return nc.linkedList.Len()
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NodeCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil_cache", func(t *testing.T) {
+		t.Parallel()
+
+		var cache *NodeCache
+		cache.add([]byte{1}, &Node{})
+		n, ok := cache.get([]byte{1})
+		assert.False(t, ok)
+		assert.Nil(t, n)
+	})
+
+	t.Run("least_recently_used_evicted", func(t *testing.T) {
+		t.Parallel()
+
+		nodeA := &Node{PartialKey: []byte{1}}
+		nodeB := &Node{PartialKey: []byte{2}}
+		nodeC := &Node{PartialKey: []byte{3}}
+
+		cache := NewNodeCache(2)
+		cache.add([]byte{1}, nodeA)
+		cache.add([]byte{2}, nodeB)
+
+		// mark A as most recently used
+		n, ok := cache.get([]byte{1})
+		assert.True(t, ok)
+		assert.Equal(t, nodeA, n)
+
+		cache.add([]byte{3}, nodeC)
+		assert.Equal(t, 2, cache.len())
+
+		_, ok = cache.get([]byte{2})
+		assert.False(t, ok)
+
+		n, ok = cache.get([]byte{1})
+		assert.True(t, ok)
+		assert.Equal(t, nodeA, n)
+
+		n, ok = cache.get([]byte{3})
+		assert.True(t, ok)
+		assert.Equal(t, nodeC, n)
+	})
+
+	t.Run("add_existing", func(t *testing.T) {
+		t.Parallel()
+
+		nodeA := &Node{PartialKey: []byte{1}}
+		otherNodeA := &Node{PartialKey: []byte{1}, StorageValue: []byte{1}}
+
+		cache := NewNodeCache(2)
+		cache.add([]byte{1}, nodeA)
+		cache.add([]byte{1}, otherNodeA)
+		assert.Equal(t, 1, cache.len())
+
+		n, ok := cache.get([]byte{1})
+		assert.True(t, ok)
+		assert.Equal(t, otherNodeA, n)
+	})
+}
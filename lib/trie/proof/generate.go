@@ -28,14 +28,28 @@ type Database interface {
 // Generate generates and deduplicates the encoded proof nodes
 // for the trie corresponding to the root hash given, and for
 // the slice of (Little Endian) full keys given. The database given
-// is used to load the trie using the root hash given.
+// is used to load the trie nodes traversed using the root hash given.
+// It returns an error wrapping ErrKeyNotFound if a key is not in the trie.
 func Generate(rootHash []byte, fullKeys [][]byte, database Database) (
 	encodedProofNodes [][]byte, err error) {
-	trie := trie.NewEmptyTrie()
-	if err := trie.Load(database, common.BytesToHash(rootHash)); err != nil {
+	return generate(rootHash, fullKeys, database, false)
+}
+
+// GenerateWithAbsentKeys is like Generate, except keys absent from the trie
+// are proven absent with the encoded nodes on their path in the trie,
+// instead of returning an error.
+func GenerateWithAbsentKeys(rootHash []byte, fullKeys [][]byte, database Database) (
+	encodedProofNodes [][]byte, err error) {
+	return generate(rootHash, fullKeys, database, true)
+}
+
+func generate(rootHash []byte, fullKeys [][]byte, database Database,
+	allowAbsentKeys bool) (encodedProofNodes [][]byte, err error) {
+	lazyTrie, err := trie.NewLazyTrie(database, common.BytesToHash(rootHash), nil)
+	if err != nil {
 		return nil, fmt.Errorf("loading trie: %w", err)
 	}
-	rootNode := trie.RootNode()
+	rootNode := lazyTrie.RootNode()
 
 	buffer := pools.DigestBuffers.Get().(*bytes.Buffer)
 	defer pools.DigestBuffers.Put(buffer)
@@ -43,7 +57,10 @@ func Generate(rootHash []byte, fullKeys [][]byte, database Database) (
 	merkleValuesSeen := make(map[string]struct{})
 	for _, fullKey := range fullKeys {
 		fullKeyNibbles := codec.KeyLEToNibbles(fullKey)
-		newEncodedProofNodes, err := walkRoot(rootNode, fullKeyNibbles)
+		newEncodedProofNodes, err := walkRoot(lazyTrie, rootNode, fullKeyNibbles)
+		if allowAbsentKeys && errors.Is(err, ErrKeyNotFound) {
+			err = nil
+		}
 		if err != nil {
 			// Note we wrap the full key context here since walk is recursive and
 			// may not be aware of the initial full key.
@@ -71,7 +88,12 @@ func Generate(rootHash []byte, fullKeys [][]byte, database Database) (
 	return encodedProofNodes, nil
 }
 
-func walkRoot(root *node.Node, fullKey []byte) (
+// walkRoot walks the trie from the root node to the node at the full key
+// given, and returns the encoded proof nodes on the path. The trie given
+// is used to load the nodes traversed if it is a lazy trie.
+// If the key is not found, the encoded proof nodes on the path to where
+// the key would be are returned together with ErrKeyNotFound.
+func walkRoot(tr *trie.Trie, root *node.Node, fullKey []byte) (
 	encodedProofNodes [][]byte, err error) {
 	if root == nil {
 		if len(fullKey) == 0 {
@@ -91,32 +113,41 @@ func walkRoot(root *node.Node, fullKey []byte) (
 
 	nodeFound := len(fullKey) == 0 || bytes.Equal(root.PartialKey, fullKey)
 	if nodeFound {
+		if root.IsHashedValue {
+			// The node encoding only contains the storage value hash,
+			// so the storage value is added to the proof as well.
+			encodedProofNodes = append(encodedProofNodes, root.StorageValue)
+		}
 		return encodedProofNodes, nil
 	}
 
 	if root.Kind() == node.Leaf && !nodeFound {
-		return nil, ErrKeyNotFound
+		return encodedProofNodes, ErrKeyNotFound
 	}
 
 	nodeIsDeeper := len(fullKey) > len(root.PartialKey)
 	if !nodeIsDeeper {
-		return nil, ErrKeyNotFound
+		return encodedProofNodes, ErrKeyNotFound
 	}
 
 	commonLength := lenCommonPrefix(root.PartialKey, fullKey)
+	if commonLength < len(root.PartialKey) {
+		return encodedProofNodes, ErrKeyNotFound
+	}
+
 	childIndex := fullKey[commonLength]
 	nextChild := root.Children[childIndex]
 	nextFullKey := fullKey[commonLength+1:]
-	deeperEncodedProofNodes, err := walk(nextChild, nextFullKey)
+	deeperEncodedProofNodes, err := walk(tr, nextChild, nextFullKey)
+	encodedProofNodes = append(encodedProofNodes, deeperEncodedProofNodes...)
 	if err != nil {
-		return nil, err // note: do not wrap since this is recursive
+		return encodedProofNodes, err // note: do not wrap since this is recursive
 	}
 
-	encodedProofNodes = append(encodedProofNodes, deeperEncodedProofNodes...)
 	return encodedProofNodes, nil
 }
 
-func walk(parent *node.Node, fullKey []byte) (
+func walk(tr *trie.Trie, parent *node.Node, fullKey []byte) (
 	encodedProofNodes [][]byte, err error) {
 	if parent == nil {
 		if len(fullKey) == 0 {
@@ -125,6 +156,11 @@ func walk(parent *node.Node, fullKey []byte) (
 		return nil, ErrKeyNotFound
 	}
 
+	parent, err = tr.ResolveNode(parent)
+	if err != nil {
+		return nil, err // note: do not wrap since this is recursive
+	}
+
 	// Note we do not use sync.Pool buffers since we would have
 	// to copy it so it persists in encodedProofNodes.
 	encodingBuffer := bytes.NewBuffer(nil)
@@ -143,28 +179,37 @@ func walk(parent *node.Node, fullKey []byte) (
 
 	nodeFound := len(fullKey) == 0 || bytes.Equal(parent.PartialKey, fullKey)
 	if nodeFound {
+		if parent.IsHashedValue {
+			// The node encoding only contains the storage value hash,
+			// so the storage value is added to the proof as well.
+			encodedProofNodes = append(encodedProofNodes, parent.StorageValue)
+		}
 		return encodedProofNodes, nil
 	}
 
 	if parent.Kind() == node.Leaf && !nodeFound {
-		return nil, ErrKeyNotFound
+		return encodedProofNodes, ErrKeyNotFound
 	}
 
 	nodeIsDeeper := len(fullKey) > len(parent.PartialKey)
 	if !nodeIsDeeper {
-		return nil, ErrKeyNotFound
+		return encodedProofNodes, ErrKeyNotFound
 	}
 
 	commonLength := lenCommonPrefix(parent.PartialKey, fullKey)
+	if commonLength < len(parent.PartialKey) {
+		return encodedProofNodes, ErrKeyNotFound
+	}
+
 	childIndex := fullKey[commonLength]
 	nextChild := parent.Children[childIndex]
 	nextFullKey := fullKey[commonLength+1:]
-	deeperEncodedProofNodes, err := walk(nextChild, nextFullKey)
+	deeperEncodedProofNodes, err := walk(tr, nextChild, nextFullKey)
+	encodedProofNodes = append(encodedProofNodes, deeperEncodedProofNodes...)
 	if err != nil {
-		return nil, err // note: do not wrap since this is recursive
+		return encodedProofNodes, err // note: do not wrap since this is recursive
 	}
 
-	encodedProofNodes = append(encodedProofNodes, deeperEncodedProofNodes...)
 	return encodedProofNodes, nil
 }
 
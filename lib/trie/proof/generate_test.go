@@ -46,8 +46,8 @@ func Test_Generate(t *testing.T) {
 			},
 			errWrapped: errTest,
 			errMessage: "loading trie: " +
-				"failed to find root key " +
-				"0x000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f: " +
+				"loading root node: " +
+				"getting node from database: " +
 				"test error",
 		},
 		"walk_error": {
@@ -302,7 +302,13 @@ func Test_walkRoot(t *testing.T) {
 				PartialKey:   []byte{1, 2},
 				StorageValue: []byte{1},
 			},
-			fullKey:    []byte{1},
+			fullKey: []byte{1},
+			encodedProofNodes: [][]byte{
+				encodeNode(t, node.Node{
+					PartialKey:   []byte{1, 2},
+					StorageValue: []byte{1},
+				}),
+			},
 			errWrapped: ErrKeyNotFound,
 			errMessage: "key not found",
 		},
@@ -311,7 +317,13 @@ func Test_walkRoot(t *testing.T) {
 				PartialKey:   []byte{1, 2},
 				StorageValue: []byte{1},
 			},
-			fullKey:    []byte{1, 3},
+			fullKey: []byte{1, 3},
+			encodedProofNodes: [][]byte{
+				encodeNode(t, node.Node{
+					PartialKey:   []byte{1, 2},
+					StorageValue: []byte{1},
+				}),
+			},
 			errWrapped: ErrKeyNotFound,
 			errMessage: "key not found",
 		},
@@ -320,7 +332,13 @@ func Test_walkRoot(t *testing.T) {
 				PartialKey:   []byte{1, 2},
 				StorageValue: []byte{1},
 			},
-			fullKey:    []byte{1, 2, 3},
+			fullKey: []byte{1, 2, 3},
+			encodedProofNodes: [][]byte{
+				encodeNode(t, node.Node{
+					PartialKey:   []byte{1, 2},
+					StorageValue: []byte{1},
+				}),
+			},
 			errWrapped: ErrKeyNotFound,
 			errMessage: "key not found",
 		},
@@ -359,7 +377,19 @@ func Test_walkRoot(t *testing.T) {
 					},
 				}),
 			},
-			fullKey:    []byte{1},
+			fullKey: []byte{1},
+			encodedProofNodes: [][]byte{
+				encodeNode(t, node.Node{
+					PartialKey:   []byte{1, 2},
+					StorageValue: []byte{3},
+					Children: padRightChildren([]*node.Node{
+						{
+							PartialKey:   []byte{4},
+							StorageValue: []byte{5},
+						},
+					}),
+				}),
+			},
 			errWrapped: ErrKeyNotFound,
 			errMessage: "key not found",
 		},
@@ -374,7 +404,19 @@ func Test_walkRoot(t *testing.T) {
 					},
 				}),
 			},
-			fullKey:    []byte{1, 3},
+			fullKey: []byte{1, 3},
+			encodedProofNodes: [][]byte{
+				encodeNode(t, node.Node{
+					PartialKey:   []byte{1, 2},
+					StorageValue: []byte{3},
+					Children: padRightChildren([]*node.Node{
+						{
+							PartialKey:   []byte{4},
+							StorageValue: []byte{5},
+						},
+					}),
+				}),
+			},
 			errWrapped: ErrKeyNotFound,
 			errMessage: "key not found",
 		},
@@ -470,7 +512,19 @@ func Test_walkRoot(t *testing.T) {
 					},
 				}),
 			},
-			fullKey:    []byte{1, 2, 0x04, 4},
+			fullKey: []byte{1, 2, 0x04, 4},
+			encodedProofNodes: [][]byte{
+				encodeNode(t, node.Node{
+					PartialKey:   []byte{1, 2},
+					StorageValue: []byte{3},
+					Children: padRightChildren([]*node.Node{
+						{
+							PartialKey:   []byte{4, 5},
+							StorageValue: []byte{5},
+						},
+					}),
+				}),
+			},
 			errWrapped: ErrKeyNotFound,
 			errMessage: "key not found",
 		},
@@ -506,7 +560,7 @@ func Test_walkRoot(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			encodedProofNodes, err := walkRoot(testCase.parent, testCase.fullKey)
+			encodedProofNodes, err := walkRoot(trie.NewEmptyTrie(), testCase.parent, testCase.fullKey)
 
 			assert.ErrorIs(t, err, testCase.errWrapped)
 			if testCase.errWrapped != nil {
@@ -757,7 +811,7 @@ func Test_walk(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			encodedProofNodes, err := walk(testCase.parent, testCase.fullKey)
+			encodedProofNodes, err := walk(trie.NewEmptyTrie(), testCase.parent, testCase.fullKey)
 
 			assert.ErrorIs(t, err, testCase.errWrapped)
 			if testCase.errWrapped != nil {
@@ -845,12 +899,12 @@ func Benchmark_walkRoot(b *testing.B) {
 	longestKeyNibbles := codec.KeyLEToNibbles(longestKeyLE)
 
 	rootNode := trie.RootNode()
-	encodedProofNodes, err := walkRoot(rootNode, longestKeyNibbles)
+	encodedProofNodes, err := walkRoot(trie, rootNode, longestKeyNibbles)
 	require.NoError(b, err)
 	require.Equal(b, len(encodedProofNodes), trieDepth)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = walkRoot(rootNode, longestKeyNibbles)
+		_, _ = walkRoot(trie, rootNode, longestKeyNibbles)
 	}
 }
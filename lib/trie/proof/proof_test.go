@@ -4,6 +4,7 @@
 package proof
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 
@@ -50,3 +51,74 @@ func Test_Generate_Verify(t *testing.T) {
 		require.NoError(t, err)
 	}
 }
+
+func Test_GenerateWithAbsentKeys_Verify(t *testing.T) {
+	t.Parallel()
+
+	trie := trie.NewEmptyTrie()
+	trie.Put([]byte("cat"), []byte("cat-value"))
+	trie.Put([]byte("catapulta"), []byte("catapulta-value"))
+	trie.Put([]byte("dog"), []byte("dog-value"))
+
+	rootHash, err := trie.Hash()
+	require.NoError(t, err)
+
+	database, err := chaindb.NewBadgerDB(&chaindb.Config{
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	err = trie.WriteDirty(database)
+	require.NoError(t, err)
+
+	fullKeys := [][]byte{[]byte("cat"), []byte("catz"), []byte("cow")}
+	_, err = Generate(rootHash.ToBytes(), fullKeys, database)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	proof, err := GenerateWithAbsentKeys(rootHash.ToBytes(), fullKeys, database)
+	require.NoError(t, err)
+
+	err = Verify(proof, rootHash.ToBytes(), []byte("cat"), []byte("cat-value"))
+	require.NoError(t, err)
+
+	proofTrie, err := buildTrie(proof, rootHash.ToBytes())
+	require.NoError(t, err)
+	require.Nil(t, proofTrie.Get([]byte("catz")))
+	require.Nil(t, proofTrie.Get([]byte("cow")))
+}
+
+func Test_Generate_Verify_V1(t *testing.T) {
+	t.Parallel()
+
+	keyValues := map[string][]byte{
+		"cat":       []byte("short"),
+		"catapulta": bytes.Repeat([]byte{1}, trie.MaxInlineValue+1),
+		"dog":       bytes.Repeat([]byte{2}, 100),
+	}
+
+	trieV1 := trie.NewEmptyTrie()
+	trieV1.SetVersion(trie.V1)
+
+	for key, value := range keyValues {
+		err := trieV1.Put([]byte(key), value)
+		require.NoError(t, err)
+	}
+
+	rootHash, err := trieV1.Hash()
+	require.NoError(t, err)
+
+	database, err := chaindb.NewBadgerDB(&chaindb.Config{
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	err = trieV1.WriteDirty(database)
+	require.NoError(t, err)
+
+	for key, expectedValue := range keyValues {
+		fullKeys := [][]byte{[]byte(key)}
+		proof, err := Generate(rootHash.ToBytes(), fullKeys, database)
+		require.NoError(t, err)
+
+		err = Verify(proof, rootHash.ToBytes(), []byte(key), expectedValue)
+		require.NoError(t, err)
+	}
+}
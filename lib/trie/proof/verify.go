@@ -16,8 +16,9 @@
 )
 
 var (
-	ErrKeyNotFoundInProofTrie = errors.New("key not found in proof trie")
-	ErrValueMismatchProofTrie = errors.New("value found in proof trie does not match")
+	ErrKeyNotFoundInProofTrie   = errors.New("key not found in proof trie")
+	ErrValueMismatchProofTrie   = errors.New("value found in proof trie does not match")
+	ErrKeysValuesLengthMismatch = errors.New("number of keys and values do not match")
 )
 
 // Verify verifies a given key and value belongs to the trie by creating
@@ -31,6 +32,36 @@ func Verify(encodedProofNodes [][]byte, rootHash, key, value []byte) (err error)
 		return fmt.Errorf("building trie from proof encoded nodes: %w", err)
 	}
 
+	return verifyValue(proofTrie, rootHash, key, value)
+}
+
+// VerifyEntries verifies the given keys and values all belong to the trie,
+// building the proof trie from the encoded proof nodes only once.
+// The keys and values slices must have the same length, and each value is
+// compared with the value found in the proof trie the same way as in Verify.
+// A nil error is returned on success.
+func VerifyEntries(encodedProofNodes [][]byte, rootHash []byte, keys, values [][]byte) (err error) {
+	if len(keys) != len(values) {
+		return fmt.Errorf("%w: %d keys and %d values",
+			ErrKeysValuesLengthMismatch, len(keys), len(values))
+	}
+
+	proofTrie, err := buildTrie(encodedProofNodes, rootHash)
+	if err != nil {
+		return fmt.Errorf("building trie from proof encoded nodes: %w", err)
+	}
+
+	for i, key := range keys {
+		err = verifyValue(proofTrie, rootHash, key, values[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func verifyValue(proofTrie *trie.Trie, rootHash, key, value []byte) (err error) {
 	proofTrieValue := proofTrie.Get(key)
 	if proofTrieValue == nil {
 		return fmt.Errorf("%w: %s in proof trie for root hash 0x%x",
@@ -102,6 +133,7 @@ func buildTrie(encodedProofNodes [][]byte, rootHash []byte) (t *trie.Trie, err e
 		// it becomes used with a database in the future, we set the dirty flag
 		// to true.
 		root.Dirty = true
+		resolveHashedValue(digestToEncoding, root)
 	}
 
 	if root == nil {
@@ -167,6 +199,7 @@ func loadProof(digestToEncoding map[string][]byte, n *node.Node) (err error) {
 		// it becomes used with a database in the future, we set the dirty flag
 		// to true.
 		child.Dirty = true
+		resolveHashedValue(digestToEncoding, child)
 
 		branch.Children[i] = child
 		branch.Descendants += child.Descendants
@@ -179,6 +212,21 @@ func loadProof(digestToEncoding map[string][]byte, n *node.Node) (err error) {
 	return nil
 }
 
+// resolveHashedValue sets the storage value of a node having a hashed
+// storage value to the storage value found in the proof, if any.
+// If the storage value is not part of the proof, the node storage value
+// is left as its storage value hash.
+func resolveHashedValue(digestToEncoding map[string][]byte, n *node.Node) {
+	if !n.IsHashedValue {
+		return
+	}
+
+	storageValue, ok := digestToEncoding[string(n.StorageValue)]
+	if ok {
+		n.StorageValue = storageValue
+	}
+}
+
 func bytesToString(b []byte) (s string) {
 	switch {
 	case b == nil:
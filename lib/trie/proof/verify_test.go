@@ -115,6 +115,104 @@ func Test_Verify(t *testing.T) {
 	}
 }
 
+func Test_VerifyEntries(t *testing.T) {
+	t.Parallel()
+
+	leafA := node.Node{
+		PartialKey:   []byte{1},
+		StorageValue: []byte{1},
+	}
+
+	// leafB is a leaf encoding to more than 32 bytes
+	leafB := node.Node{
+		PartialKey:   []byte{2},
+		StorageValue: generateBytes(t, 40),
+	}
+	assertLongEncoding(t, leafB)
+
+	branch := node.Node{
+		PartialKey:   []byte{3, 4},
+		StorageValue: []byte{1},
+		Children: padRightChildren([]*node.Node{
+			&leafB,
+			nil,
+			&leafA,
+			&leafB,
+		}),
+	}
+	assertLongEncoding(t, branch)
+
+	encodedProofNodes := [][]byte{
+		encodeNode(t, branch),
+		encodeNode(t, leafB),
+	}
+	rootHash := blake2bNode(t, branch)
+
+	testCases := map[string]struct {
+		keysLE     [][]byte
+		values     [][]byte
+		errWrapped error
+		errMessage string
+	}{
+		"keys_values_length_mismatch": {
+			keysLE:     [][]byte{{0x34, 0x21}},
+			errWrapped: ErrKeysValuesLengthMismatch,
+			errMessage: "number of keys and values do not match: 1 keys and 0 values",
+		},
+		"all_entries_found": {
+			keysLE: [][]byte{
+				{0x34, 0x21}, // inlined short leaf of branch
+				{0x34, 0x32}, // large hash-referenced leaf of branch
+			},
+			values: [][]byte{
+				{1},
+				generateBytes(t, 40),
+			},
+		},
+		"second_entry_value_mismatch": {
+			keysLE: [][]byte{
+				{0x34, 0x32},
+				{0x34, 0x21},
+			},
+			values: [][]byte{
+				generateBytes(t, 40),
+				{2},
+			},
+			errWrapped: ErrValueMismatchProofTrie,
+			errMessage: "value found in proof trie does not match: " +
+				"expected value 0x02 but got value 0x01 from proof trie",
+		},
+		"second_entry_not_found": {
+			keysLE: [][]byte{
+				{0x34, 0x21},
+				{0x01, 0x01},
+			},
+			values: [][]byte{
+				{1},
+				nil,
+			},
+			errWrapped: ErrKeyNotFoundInProofTrie,
+			errMessage: "key not found in proof trie: " +
+				"0x0101 in proof trie for root hash " +
+				"0xec4bb0acfcf778ae8746d3ac3325fc73c3d9b376eb5f8d638dbf5eb462f5e703",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := VerifyEntries(encodedProofNodes, rootHash, testCase.keysLE, testCase.values)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
+
 func Test_buildTrie(t *testing.T) {
 	t.Parallel()
 
@@ -27,6 +27,15 @@ type Trie struct {
 	// pruner to detect with database keys (trie node hashes) can
 	// be deleted.
 	deltas Deltas
+	// version is the state trie version used to encode
+	// storage values of nodes inserted or modified.
+	version Version
+	// db is the database nodes are loaded from for lazy tries,
+	// and is nil for tries fully loaded in memory.
+	db Getter
+	// nodeCache is the cache of nodes loaded from the database
+	// for lazy tries, and can be nil.
+	nodeCache *NodeCache
 }
 
 // NewEmptyTrie creates a trie with a nil root
@@ -58,6 +67,9 @@ func (t *Trie) Snapshot() (newTrie *Trie) {
 			generation: childTrie.generation + 1,
 			root:       childTrie.root.Copy(rootCopySettings),
 			deltas:     tracking.New(),
+			version:    childTrie.version,
+			db:         childTrie.db,
+			nodeCache:  childTrie.nodeCache,
 		}
 	}
 
@@ -66,9 +78,29 @@ func (t *Trie) Snapshot() (newTrie *Trie) {
 		root:       t.root,
 		childTries: childTries,
 		deltas:     tracking.New(),
+		version:    t.version,
+		db:         t.db,
+		nodeCache:  t.nodeCache,
 	}
 }
 
+// SetVersion sets the state trie version used to encode
+// the storage values inserted or modified from now on,
+// for the trie and all its child tries.
+// Note nodes already in the trie keep their encoding until
+// their storage value is modified.
+func (t *Trie) SetVersion(version Version) {
+	t.version = version
+	for _, childTrie := range t.childTries {
+		childTrie.SetVersion(version)
+	}
+}
+
+// Version returns the state trie version of the trie.
+func (t *Trie) Version() Version {
+	return t.version
+}
+
 // handleTrackedDeltas sets the pending deleted Merkle values in
 // the trie deleted merkle values set if and only if success is true.
 func (t *Trie) handleTrackedDeltas(success bool, pendingDeltas DeltaDeletedGetter) {
@@ -120,6 +152,12 @@ func (t *Trie) registerDeletedMerkleValue(node *Node,
 		// since the last trie snapshot.
 		nodeHash := common.NewHash(node.MerkleValue)
 		pendingDeltas.RecordDeleted(nodeHash)
+
+		if storageValueHash, ok := hashedValueKey(node); ok {
+			// The storage value is stored in the database at its hash
+			// and is deleted along with the node.
+			pendingDeltas.RecordDeleted(storageValueHash)
+		}
 	}
 
 	return nil
@@ -137,6 +175,9 @@ func (t *Trie) DeepCopy() (trieCopy *Trie) {
 
 	trieCopy = &Trie{
 		generation: t.generation,
+		version:    t.version,
+		db:         t.db,
+		nodeCache:  t.nodeCache,
 	}
 
 	if t.deltas != nil {
@@ -196,14 +237,15 @@ func (t *Trie) Hash() (rootHash common.Hash, err error) {
 // where the keys are encoded in Little Endian.
 func (t *Trie) Entries() (keyValueMap map[string][]byte) {
 	keyValueMap = make(map[string][]byte)
-	entries(t.root, nil, keyValueMap)
+	t.entries(t.root, nil, keyValueMap)
 	return keyValueMap
 }
 
-func entries(parent *Node, prefix []byte, kv map[string][]byte) {
+func (t *Trie) entries(parent *Node, prefix []byte, kv map[string][]byte) {
 	if parent == nil {
 		return
 	}
+	parent = t.mustResolveNode(parent)
 
 	if parent.Kind() == node.Leaf {
 		parentKey := parent.PartialKey
@@ -222,7 +264,7 @@ func entries(parent *Node, prefix []byte, kv map[string][]byte) {
 
 	for i, child := range branch.Children {
 		childPrefix := concatenateSlices(prefix, branch.PartialKey, intToByteSlice(i))
-		entries(child, childPrefix, kv)
+		t.entries(child, childPrefix, kv)
 	}
 }
 
@@ -232,7 +274,7 @@ func (t *Trie) NextKey(keyLE []byte) (nextKeyLE []byte) {
 	prefix := []byte(nil)
 	key := codec.KeyLEToNibbles(keyLE)
 
-	nextKey := findNextKey(t.root, prefix, key)
+	nextKey := t.findNextKey(t.root, prefix, key)
 	if nextKey == nil {
 		return nil
 	}
@@ -241,15 +283,16 @@ func (t *Trie) NextKey(keyLE []byte) (nextKeyLE []byte) {
 	return nextKeyLE
 }
 
-func findNextKey(parent *Node, prefix, searchKey []byte) (nextKey []byte) {
+func (t *Trie) findNextKey(parent *Node, prefix, searchKey []byte) (nextKey []byte) {
 	if parent == nil {
 		return nil
 	}
+	parent = t.mustResolveNode(parent)
 
 	if parent.Kind() == node.Leaf {
 		return findNextKeyLeaf(parent, prefix, searchKey)
 	}
-	return findNextKeyBranch(parent, prefix, searchKey)
+	return t.findNextKeyBranch(parent, prefix, searchKey)
 }
 
 func findNextKeyLeaf(leaf *Node, prefix, searchKey []byte) (nextKey []byte) {
@@ -263,12 +306,12 @@ func findNextKeyLeaf(leaf *Node, prefix, searchKey []byte) (nextKey []byte) {
 	return fullKey
 }
 
-func findNextKeyBranch(parentBranch *Node, prefix, searchKey []byte) (nextKey []byte) {
+func (t *Trie) findNextKeyBranch(parentBranch *Node, prefix, searchKey []byte) (nextKey []byte) {
 	fullKey := concatenateSlices(prefix, parentBranch.PartialKey)
 
 	if bytes.Equal(searchKey, fullKey) {
 		const startChildIndex = 0
-		return findNextKeyChild(parentBranch.Children, startChildIndex, fullKey, searchKey)
+		return t.findNextKeyChild(parentBranch.Children, startChildIndex, fullKey, searchKey)
 	}
 
 	if keyIsLexicographicallyBigger(searchKey, fullKey) {
@@ -276,7 +319,7 @@ func findNextKeyBranch(parentBranch *Node, prefix, searchKey []byte) (nextKey []
 			return nil
 		} else if len(searchKey) > len(fullKey) {
 			startChildIndex := searchKey[len(fullKey)]
-			return findNextKeyChild(parentBranch.Children,
+			return t.findNextKeyChild(parentBranch.Children,
 				startChildIndex, fullKey, searchKey)
 		}
 	}
@@ -286,7 +329,7 @@ func findNextKeyBranch(parentBranch *Node, prefix, searchKey []byte) (nextKey []
 		return fullKey
 	}
 	const startChildIndex = 0
-	return findNextKeyChild(parentBranch.Children, startChildIndex,
+	return t.findNextKeyChild(parentBranch.Children, startChildIndex,
 		fullKey, searchKey)
 }
 
@@ -299,7 +342,7 @@ func keyIsLexicographicallyBigger(key, key2 []byte) (bigger bool) {
 
 // findNextKeyChild searches for a next key in the children
 // given and returns a next key or nil if no next key is found.
-func findNextKeyChild(children []*Node, startIndex byte,
+func (t *Trie) findNextKeyChild(children []*Node, startIndex byte,
 	fullKey, key []byte) (nextKey []byte) {
 	for i := startIndex; i < node.ChildrenCapacity; i++ {
 		child := children[i]
@@ -308,7 +351,7 @@ func findNextKeyChild(children []*Node, startIndex byte,
 		}
 
 		childFullKey := concatenateSlices(fullKey, []byte{i})
-		next := findNextKey(child, childFullKey, key)
+		next := t.findNextKey(child, childFullKey, key)
 		if len(next) > 0 {
 			return next
 		}
@@ -353,13 +396,19 @@ func (t *Trie) insert(parent *Node, key, value []byte,
 		mutated = true
 		nodesCreated = 1
 		return &Node{
-			PartialKey:   key,
-			StorageValue: value,
-			Generation:   t.generation,
-			Dirty:        true,
+			PartialKey:    key,
+			StorageValue:  value,
+			IsHashedValue: t.version.ShouldHashValue(value),
+			Generation:    t.generation,
+			Dirty:         true,
 		}, mutated, nodesCreated, nil
 	}
 
+	parent, err = t.ResolveNode(parent)
+	if err != nil {
+		return nil, false, 0, err
+	}
+
 	if parent.Kind() == node.Branch {
 		newParent, mutated, nodesCreated, err = t.insertInBranch(
 			parent, key, value, pendingDeltas)
@@ -398,6 +447,7 @@ func (t *Trie) insertInLeaf(parentLeaf *Node, key, value []byte,
 		}
 
 		parentLeaf.StorageValue = value
+		parentLeaf.IsHashedValue = t.version.ShouldHashValue(value)
 		mutated = true
 		return parentLeaf, mutated, nodesCreated, nil
 	}
@@ -417,6 +467,7 @@ func (t *Trie) insertInLeaf(parentLeaf *Node, key, value []byte,
 	if len(key) == commonPrefixLength {
 		// key is included in parent leaf key
 		newBranchParent.StorageValue = value
+		newBranchParent.IsHashedValue = t.version.ShouldHashValue(value)
 
 		if len(key) < len(parentLeafKey) {
 			// Move the current leaf parent as a child to the new branch.
@@ -441,6 +492,7 @@ func (t *Trie) insertInLeaf(parentLeaf *Node, key, value []byte,
 	if len(parentLeaf.PartialKey) == commonPrefixLength {
 		// the key of the parent leaf is at this new branch
 		newBranchParent.StorageValue = parentLeaf.StorageValue
+		newBranchParent.IsHashedValue = parentLeaf.IsHashedValue
 	} else {
 		// make the leaf a child of the new branch
 		copySettings := node.DefaultCopySettings
@@ -459,10 +511,11 @@ func (t *Trie) insertInLeaf(parentLeaf *Node, key, value []byte,
 	}
 	childIndex := key[commonPrefixLength]
 	newBranchParent.Children[childIndex] = &Node{
-		PartialKey:   key[commonPrefixLength+1:],
-		StorageValue: value,
-		Generation:   t.generation,
-		Dirty:        true,
+		PartialKey:    key[commonPrefixLength+1:],
+		StorageValue:  value,
+		IsHashedValue: t.version.ShouldHashValue(value),
+		Generation:    t.generation,
+		Dirty:         true,
 	}
 	newBranchParent.Descendants++
 	nodesCreated++
@@ -485,6 +538,7 @@ func (t *Trie) insertInBranch(parentBranch *Node, key, value []byte,
 			return nil, false, 0, fmt.Errorf("preparing branch for mutation: %w", err)
 		}
 		parentBranch.StorageValue = value
+		parentBranch.IsHashedValue = t.version.ShouldHashValue(value)
 		mutated = true
 		return parentBranch, mutated, 0, nil
 	}
@@ -498,10 +552,11 @@ func (t *Trie) insertInBranch(parentBranch *Node, key, value []byte,
 
 		if child == nil {
 			child = &Node{
-				PartialKey:   remainingKey,
-				StorageValue: value,
-				Generation:   t.generation,
-				Dirty:        true,
+				PartialKey:    remainingKey,
+				StorageValue:  value,
+				IsHashedValue: t.version.ShouldHashValue(value),
+				Generation:    t.generation,
+				Dirty:         true,
 			}
 			nodesCreated = 1
 			parentBranch, err = t.prepForMutation(parentBranch, copySettings, pendingDeltas)
@@ -559,6 +614,7 @@ func (t *Trie) insertInBranch(parentBranch *Node, key, value []byte,
 
 	if len(key) <= commonPrefixLength {
 		newParentBranch.StorageValue = value
+		newParentBranch.IsHashedValue = t.version.ShouldHashValue(value)
 	} else {
 		childIndex := key[commonPrefixLength]
 		remainingKey := key[commonPrefixLength+1:]
@@ -577,11 +633,13 @@ func (t *Trie) insertInBranch(parentBranch *Node, key, value []byte,
 	return newParentBranch, mutated, nodesCreated, nil
 }
 
-// LoadFromMap loads the given data mapping of key to value into a new empty trie.
+// LoadFromMap loads the given data mapping of key to value into a new empty trie
+// using the given state trie version.
 // The keys are in hexadecimal little Endian encoding and the values
 // are hexadecimal encoded.
-func LoadFromMap(data map[string]string) (trie Trie, err error) {
+func LoadFromMap(data map[string]string, version Version) (trie Trie, err error) {
 	trie = *NewEmptyTrie()
+	trie.SetVersion(version)
 
 	pendingDeltas := tracking.New()
 	defer func() {
@@ -620,24 +678,25 @@ func (t *Trie) GetKeysWithPrefix(prefixLE []byte) (keysLE [][]byte) {
 
 	prefix := []byte(nil)
 	key := prefixNibbles
-	return getKeysWithPrefix(t.root, prefix, key, keysLE)
+	return t.getKeysWithPrefix(t.root, prefix, key, keysLE)
 }
 
 // getKeysWithPrefix returns all keys in little Endian format that have the
 // prefix given. The prefix and key byte slices are in nibbles format.
 // TODO pass in map of keysLE if order is not needed.
 // TODO do all processing on nibbles keys and then convert to LE.
-func getKeysWithPrefix(parent *Node, prefix, key []byte,
+func (t *Trie) getKeysWithPrefix(parent *Node, prefix, key []byte,
 	keysLE [][]byte) (newKeysLE [][]byte) {
 	if parent == nil {
 		return keysLE
 	}
+	parent = t.mustResolveNode(parent)
 
 	if parent.Kind() == node.Leaf {
 		return getKeysWithPrefixFromLeaf(parent, prefix, key, keysLE)
 	}
 
-	return getKeysWithPrefixFromBranch(parent, prefix, key, keysLE)
+	return t.getKeysWithPrefixFromBranch(parent, prefix, key, keysLE)
 }
 
 func getKeysWithPrefixFromLeaf(parent *Node, prefix, key []byte,
@@ -649,10 +708,10 @@ func getKeysWithPrefixFromLeaf(parent *Node, prefix, key []byte,
 	return keysLE
 }
 
-func getKeysWithPrefixFromBranch(parent *Node, prefix, key []byte,
+func (t *Trie) getKeysWithPrefixFromBranch(parent *Node, prefix, key []byte,
 	keysLE [][]byte) (newKeysLE [][]byte) {
 	if len(key) == 0 || bytes.HasPrefix(parent.PartialKey, key) {
-		return addAllKeys(parent, prefix, keysLE)
+		return t.addAllKeys(parent, prefix, keysLE)
 	}
 
 	noPossiblePrefixedKeys :=
@@ -667,17 +726,18 @@ func getKeysWithPrefixFromBranch(parent *Node, prefix, key []byte,
 	child := parent.Children[childIndex]
 	childPrefix := makeChildPrefix(prefix, parent.PartialKey, int(childIndex))
 	childKey := key[1:]
-	return getKeysWithPrefix(child, childPrefix, childKey, keysLE)
+	return t.getKeysWithPrefix(child, childPrefix, childKey, keysLE)
 }
 
 // addAllKeys appends all keys of descendant nodes of the parent node
 // to the slice of keys given and returns this slice.
 // It uses the prefix in nibbles format to determine the full key.
 // The slice of keys has its keys formatted in little Endian.
-func addAllKeys(parent *Node, prefix []byte, keysLE [][]byte) (newKeysLE [][]byte) {
+func (t *Trie) addAllKeys(parent *Node, prefix []byte, keysLE [][]byte) (newKeysLE [][]byte) {
 	if parent == nil {
 		return keysLE
 	}
+	parent = t.mustResolveNode(parent)
 
 	if parent.Kind() == node.Leaf {
 		keyLE := makeFullKeyLE(prefix, parent.PartialKey)
@@ -692,7 +752,7 @@ func addAllKeys(parent *Node, prefix []byte, keysLE [][]byte) (newKeysLE [][]byt
 
 	for i, child := range parent.Children {
 		childPrefix := makeChildPrefix(prefix, parent.PartialKey, i)
-		keysLE = addAllKeys(child, childPrefix, keysLE)
+		keysLE = t.addAllKeys(child, childPrefix, keysLE)
 	}
 
 	return keysLE
@@ -715,18 +775,19 @@ func makeChildPrefix(branchPrefix, branchKey []byte,
 // Note the key argument is given in little Endian format.
 func (t *Trie) Get(keyLE []byte) (value []byte) {
 	keyNibbles := codec.KeyLEToNibbles(keyLE)
-	return retrieve(t.root, keyNibbles)
+	return t.retrieve(t.root, keyNibbles)
 }
 
-func retrieve(parent *Node, key []byte) (value []byte) {
+func (t *Trie) retrieve(parent *Node, key []byte) (value []byte) {
 	if parent == nil {
 		return nil
 	}
+	parent = t.mustResolveNode(parent)
 
 	if parent.Kind() == node.Leaf {
 		return retrieveFromLeaf(parent, key)
 	}
-	return retrieveFromBranch(parent, key)
+	return t.retrieveFromBranch(parent, key)
 }
 
 func retrieveFromLeaf(leaf *Node, key []byte) (value []byte) {
@@ -736,7 +797,7 @@ func retrieveFromLeaf(leaf *Node, key []byte) (value []byte) {
 	return nil
 }
 
-func retrieveFromBranch(branch *Node, key []byte) (value []byte) {
+func (t *Trie) retrieveFromBranch(branch *Node, key []byte) (value []byte) {
 	if len(key) == 0 || bytes.Equal(branch.PartialKey, key) {
 		return branch.StorageValue
 	}
@@ -749,7 +810,7 @@ func retrieveFromBranch(branch *Node, key []byte) (value []byte) {
 	childIndex := key[commonPrefixLength]
 	childKey := key[commonPrefixLength+1:]
 	child := branch.Children[childIndex]
-	return retrieve(child, childKey)
+	return t.retrieve(child, childKey)
 }
 
 // ClearPrefixLimit deletes the keys having the prefix given in little
@@ -791,6 +852,11 @@ func (t *Trie) clearPrefixLimitAtNode(parent *Node, prefix []byte,
 		return nil, 0, 0, true, nil
 	}
 
+	parent, err = t.ResolveNode(parent)
+	if err != nil {
+		return nil, 0, 0, false, err
+	}
+
 	if parent.Kind() == node.Leaf {
 		// if prefix is not found, it's also all deleted.
 		// TODO check this is the same behaviour as in substrate
@@ -937,6 +1003,11 @@ func (t *Trie) deleteNodesLimit(parent *Node, limit uint32,
 		return nil, valuesDeleted, nodesRemoved, nil
 	}
 
+	parent, err = t.ResolveNode(parent)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
 	if parent.Kind() == node.Leaf {
 		err = t.registerDeletedMerkleValue(parent, pendingDeltas)
 		if err != nil {
@@ -1025,7 +1096,7 @@ func (t *Trie) ClearPrefix(prefixLE []byte) (err error) {
 			return fmt.Errorf("ensuring Merkle values are calculated: %w", err)
 		}
 
-		recordAllDeleted(t.root, pendingDeltas)
+		t.recordAllDeleted(t.root, pendingDeltas)
 		t.root = nil
 		return nil
 	}
@@ -1050,6 +1121,11 @@ func (t *Trie) clearPrefixAtNode(parent *Node, prefix []byte,
 		return nil, nodesRemoved, nil
 	}
 
+	parent, err = t.ResolveNode(parent)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	if bytes.HasPrefix(parent.PartialKey, prefix) {
 		err = t.ensureMerkleValueIsCalculated(parent)
 		if err != nil {
@@ -1057,7 +1133,7 @@ func (t *Trie) clearPrefixAtNode(parent *Node, prefix []byte,
 			return parent, nodesRemoved, fmt.Errorf("ensuring Merkle values are calculated: %w", err)
 		}
 
-		recordAllDeleted(parent, pendingDeltas)
+		t.recordAllDeleted(parent, pendingDeltas)
 		nodesRemoved = 1 + parent.Descendants
 		return nil, nodesRemoved, nil
 	}
@@ -1172,6 +1248,11 @@ func (t *Trie) deleteAtNode(parent *Node, key []byte,
 		return nil, false, nodesRemoved, nil
 	}
 
+	parent, err = t.ResolveNode(parent)
+	if err != nil {
+		return nil, false, 0, err
+	}
+
 	if parent.Kind() == node.Leaf {
 		newParent, err = t.deleteLeaf(parent, key, pendingDeltas)
 		if err != nil {
@@ -1225,6 +1306,7 @@ func (t *Trie) deleteBranch(branch *Node, key []byte,
 		// we need to set to nil if the branch has the same generation
 		// as the current trie.
 		branch.StorageValue = nil
+		branch.IsHashedValue = false
 		deleted = true
 		var branchChildMerged bool
 		newParent, branchChildMerged, err = t.handleDeletion(branch, key, pendingDeltas)
@@ -1312,10 +1394,11 @@ func (t *Trie) handleDeletion(branch *Node, key []byte,
 		const branchChildMerged = false
 		commonPrefixLength := lenCommonPrefix(branch.PartialKey, key)
 		return &Node{
-			PartialKey:   key[:commonPrefixLength],
-			StorageValue: branch.StorageValue,
-			Dirty:        true,
-			Generation:   branch.Generation,
+			PartialKey:    key[:commonPrefixLength],
+			StorageValue:  branch.StorageValue,
+			IsHashedValue: branch.IsHashedValue,
+			Dirty:         true,
+			Generation:    branch.Generation,
 		}, branchChildMerged, nil
 	case childrenCount == 1 && branch.StorageValue == nil:
 		// The branch passed to handleDeletion is always a modified branch
@@ -1324,6 +1407,11 @@ func (t *Trie) handleDeletion(branch *Node, key []byte,
 		const branchChildMerged = true
 		childIndex := firstChildIndex
 		child := branch.Children[firstChildIndex]
+		child, err = t.ResolveNode(child)
+		if err != nil {
+			return nil, false, fmt.Errorf("resolving child: %w", err)
+		}
+
 		err = t.registerDeletedMerkleValue(child, pendingDeltas)
 		if err != nil {
 			return nil, false, fmt.Errorf("registering deleted merkle value: %w", err)
@@ -1332,21 +1420,23 @@ func (t *Trie) handleDeletion(branch *Node, key []byte,
 		if child.Kind() == node.Leaf {
 			newLeafKey := concatenateSlices(branch.PartialKey, intToByteSlice(childIndex), child.PartialKey)
 			return &Node{
-				PartialKey:   newLeafKey,
-				StorageValue: child.StorageValue,
-				Dirty:        true,
-				Generation:   branch.Generation,
+				PartialKey:    newLeafKey,
+				StorageValue:  child.StorageValue,
+				IsHashedValue: child.IsHashedValue,
+				Dirty:         true,
+				Generation:    branch.Generation,
 			}, branchChildMerged, nil
 		}
 
 		childBranch := child
 		newBranchKey := concatenateSlices(branch.PartialKey, intToByteSlice(childIndex), childBranch.PartialKey)
 		newBranch := &Node{
-			PartialKey:   newBranchKey,
-			StorageValue: childBranch.StorageValue,
-			Generation:   branch.Generation,
-			Children:     make([]*node.Node, node.ChildrenCapacity),
-			Dirty:        true,
+			PartialKey:    newBranchKey,
+			StorageValue:  childBranch.StorageValue,
+			IsHashedValue: childBranch.IsHashedValue,
+			Generation:    branch.Generation,
+			Children:      make([]*node.Node, node.ChildrenCapacity),
+			Dirty:         true,
 			// this is the descendants of the original branch minus one
 			Descendants: childBranch.Descendants,
 		}
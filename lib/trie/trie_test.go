@@ -1038,7 +1038,7 @@ func Test_nextKey(t *testing.T) {
 
 			originalTrie := testCase.trie.DeepCopy()
 
-			nextKey := findNextKey(testCase.trie.root, nil, testCase.key)
+			nextKey := testCase.trie.findNextKey(testCase.trie.root, nil, testCase.key)
 
 			assert.Equal(t, testCase.nextKey, nextKey)
 			assert.Equal(t, *originalTrie, testCase.trie) // ensure no mutation
@@ -1733,7 +1733,7 @@ func Test_LoadFromMap(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			trie, err := LoadFromMap(testCase.data)
+			trie, err := LoadFromMap(testCase.data, V0)
 
 			assert.ErrorIs(t, err, testCase.errWrapped)
 			if testCase.errWrapped != nil {
@@ -1948,7 +1948,8 @@ func Test_getKeysWithPrefix(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			keys := getKeysWithPrefix(testCase.parent,
+			trie := &Trie{}
+			keys := trie.getKeysWithPrefix(testCase.parent,
 				testCase.prefix, testCase.key, testCase.keys)
 
 			assert.Equal(t, testCase.expectedKeys, keys)
@@ -2018,7 +2019,8 @@ func Test_addAllKeys(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			keys := addAllKeys(testCase.parent,
+			trie := &Trie{}
+			keys := trie.addAllKeys(testCase.parent,
 				testCase.prefix, testCase.keys)
 
 			assert.Equal(t, testCase.expectedKeys, keys)
@@ -2171,7 +2173,8 @@ func Test_retrieve(t *testing.T) {
 				expectedParent = testCase.parent.Copy(copySettings)
 			}
 
-			value := retrieve(testCase.parent, testCase.key)
+			trie := &Trie{}
+			value := trie.retrieve(testCase.parent, testCase.key)
 
 			assert.Equal(t, testCase.value, value)
 			assert.Equal(t, expectedParent, testCase.parent)
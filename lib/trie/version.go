@@ -17,14 +17,23 @@
 const (
 	// V0 is the state trie version 0 where the values of the keys are
 	// inserted into the trie directly.
-	// TODO set to iota once CI passes
-	V0 Version = 1
+	V0 Version = iota
+	// V1 is the state trie version 1 where the values of the keys
+	// longer than 32 bytes are hashed, and only their hash is
+	// inserted in the trie node encoding.
+	V1
 )
 
+// MaxInlineValue is the maximum length of a storage value
+// which is not hashed in the state trie version 1.
+const MaxInlineValue = 32
+
 func (v Version) String() string {
 	switch v {
 	case V0:
 		return "v0"
+	case V1:
+		return "v1"
 	default:
 		panic(fmt.Sprintf("unknown version %d", v))
 	}
@@ -32,13 +41,45 @@ func (v Version) String() string {
 
 var ErrParseVersion = errors.New("parsing version failed")
 
+// ShouldHashValue returns true if the value should be hashed
+// in the trie node encoding for this state trie version.
+func (v Version) ShouldHashValue(value []byte) bool {
+	switch v {
+	case V0:
+		return false
+	case V1:
+		return len(value) > MaxInlineValue
+	default:
+		panic(fmt.Sprintf("unknown version %d", v))
+	}
+}
+
 // ParseVersion parses a state trie version string.
 func ParseVersion(s string) (version Version, err error) {
 	switch {
 	case strings.EqualFold(s, V0.String()):
 		return V0, nil
+	case strings.EqualFold(s, V1.String()):
+		return V1, nil
+	default:
+		return version, fmt.Errorf("%w: %q must be one of %s, %s",
+			ErrParseVersion, s, V0, V1)
+	}
+}
+
+// ErrVersionUnknown is returned when a state trie version number is unknown.
+var ErrVersionUnknown = errors.New("state trie version unknown")
+
+// VersionFromUint32 returns the state trie version corresponding
+// to the state version number given, as found in the runtime
+// version or in host function arguments.
+func VersionFromUint32(n uint32) (version Version, err error) {
+	switch n {
+	case uint32(V0):
+		return V0, nil
+	case uint32(V1):
+		return V1, nil
 	default:
-		return version, fmt.Errorf("%w: %q must be %s",
-			ErrParseVersion, s, V0)
+		return version, fmt.Errorf("%w: %d", ErrVersionUnknown, n)
 	}
 }
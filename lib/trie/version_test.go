@@ -21,6 +21,10 @@ func Test_Version_String(t *testing.T) {
 			version:       V0,
 			versionString: "v0",
 		},
+		"v1": {
+			version:       V1,
+			versionString: "v1",
+		},
 		"invalid": {
 			version:      Version(99),
 			panicMessage: "unknown version 99",
@@ -62,10 +66,14 @@ func Test_ParseVersion(t *testing.T) {
 			s:       "V0",
 			version: V0,
 		},
+		"v1": {
+			s:       "v1",
+			version: V1,
+		},
 		"invalid": {
 			s:          "xyz",
 			errWrapped: ErrParseVersion,
-			errMessage: "parsing version failed: \"xyz\" must be v0",
+			errMessage: "parsing version failed: \"xyz\" must be one of v0, v1",
 		},
 	}
 
@@ -84,3 +92,77 @@ func Test_ParseVersion(t *testing.T) {
 		})
 	}
 }
+
+func Test_Version_ShouldHashValue(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		version    Version
+		value      []byte
+		shouldHash bool
+	}{
+		"v0_long_value": {
+			version: V0,
+			value:   make([]byte, MaxInlineValue+1),
+		},
+		"v1_short_value": {
+			version: V1,
+			value:   make([]byte, MaxInlineValue),
+		},
+		"v1_long_value": {
+			version:    V1,
+			value:      make([]byte, MaxInlineValue+1),
+			shouldHash: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			shouldHash := testCase.version.ShouldHashValue(testCase.value)
+			assert.Equal(t, testCase.shouldHash, shouldHash)
+		})
+	}
+}
+
+func Test_VersionFromUint32(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		n          uint32
+		version    Version
+		errWrapped error
+		errMessage string
+	}{
+		"v0": {
+			n:       0,
+			version: V0,
+		},
+		"v1": {
+			n:       1,
+			version: V1,
+		},
+		"unknown": {
+			n:          2,
+			errWrapped: ErrVersionUnknown,
+			errMessage: "state trie version unknown: 2",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			version, err := VersionFromUint32(testCase.n)
+
+			assert.Equal(t, testCase.version, version)
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
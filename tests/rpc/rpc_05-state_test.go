@@ -35,12 +35,12 @@ func TestStateRPCResponseValidation(t *testing.T) { //nolint:tparallel
 	t.Run("state_call", func(t *testing.T) {
 		t.Parallel()
 
-		const params = `["", "","0x580d77a9136035a0bc3c3cd86286172f7f81291164c5914266073a30466fba21"]`
+		params := fmt.Sprintf(`["Core_version", "0x", "%s"]`, blockHash)
 		var response modules.StateCallResponse
 
 		fetchWithTimeout(ctx, t, "state_call", params, &response)
 
-		// TODO assert stateCallResponse
+		require.NotEmpty(t, response)
 	})
 
 	t.Run("state_getKeysPaged", func(t *testing.T) {
@@ -67,6 +67,20 @@ func TestStateRPCResponseValidation(t *testing.T) { //nolint:tparallel
 		// TODO assert response
 	})
 
+	t.Run("state_queryStorageAt", func(t *testing.T) {
+		t.Parallel()
+
+		params := fmt.Sprintf(
+			`[["0xf2794c22e353e9a839f12faab03a911bf68967d635641a7087e53f2bff1ecad3c6756fee45ec79ead60347fffb770bcdf0ec74da701ab3d6495986fe1ecc3027"], "%s"]`, //nolint:lll
+			blockHash)
+		var response []modules.StorageChangeSetResponse
+
+		fetchWithTimeout(ctx, t, "state_queryStorageAt", params, &response)
+
+		require.Len(t, response, 1)
+		require.Equal(t, blockHash, *response[0].Block)
+	})
+
 	t.Run("state_getRuntimeVersion", func(t *testing.T) {
 		t.Parallel()
 